@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// backfillReason 回填条目的开平仓理由
+const backfillReason = "交易所成交记录回填"
+
+// runBackfillCommand 从交易所成交记录回填交易日志，返回进程退出码
+// 用法: dsbot backfill [--days 30] [--config config.json]
+// 请在机器人停止运行时执行，避免与运行中的机器人同时写交易日志
+func runBackfillCommand(args []string) int {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	days := fs.Int("days", 30, "回填天数（OKX 成交记录最多保留约3个月）")
+	configPath := fs.String("config", "config.json", "配置文件")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *days <= 0 {
+		fmt.Println("--days 必须大于0")
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+	if !cfg.IsFuturesMode() {
+		fmt.Println("交易日志仅记录合约交易，现货模式无需回填")
+		return 2
+	}
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		return 1
+	}
+
+	client, err := exchange.NewExchange(&cfg.API, cfg.GetTradingMode())
+	if err != nil {
+		fmt.Printf("创建交易所客户端失败: %v\n", err)
+		return 1
+	}
+	symbol := client.ParseSymbols(cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+
+	// 合约成交数量为张数，按合约面值换算为基础币种
+	contractValue := 1.0
+	if info, err := client.GetInstrumentInfo(symbol); err != nil {
+		fmt.Printf("获取交易对信息失败: %v\n", err)
+		return 1
+	} else if info.ContractValue > 0 {
+		contractValue = info.ContractValue
+	}
+
+	since := time.Now().AddDate(0, 0, -*days)
+	trades, err := client.FetchMyTrades(symbol, since)
+	if err != nil {
+		fmt.Printf("获取成交记录失败: %v\n", err)
+		return 1
+	}
+
+	tradingPair := fmt.Sprintf("%s-%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	entries, open, exchangePnL := rebuildRoundTrips(trades, tradingPair, contractValue, cfg.Trading.SymbolB)
+
+	tradeJournal, err := journal.NewJournal(cfg.Trading.Journal.File)
+	if err != nil {
+		fmt.Printf("加载交易日志失败: %v\n", err)
+		return 1
+	}
+	imported := tradeJournal.Import(entries)
+
+	var netPnL float64
+	for _, e := range entries {
+		direction := 1.0
+		if e.Side == "short" {
+			direction = -1.0
+		}
+		netPnL += direction*(e.ExitPrice-e.EntryPrice)*e.Size - e.EntryFee - e.ExitFee
+	}
+
+	fmt.Printf("成交记录: %d 笔 (%s 起)\n", len(trades), since.Format("2006-01-02"))
+	fmt.Printf("完整开平仓: %d 笔, 新导入: %d 笔, 已存在跳过: %d 笔\n", len(entries), imported, len(entries)-imported)
+	if open > 0 {
+		fmt.Printf("未平仓: %d 笔（不导入，由运行中的机器人记录）\n", open)
+	}
+	fmt.Printf("重新计算的已实现净盈亏: %.4f %s (交易所记录的平仓盈亏: %.4f，未扣手续费)\n",
+		netPnL, cfg.Trading.SymbolB, exchangePnL)
+
+	return 0
+}
+
+// roundTrip 回填中的一次开平仓
+type roundTrip struct {
+	entry     journal.Entry
+	openCost  float64 // 开仓成交额（计算开仓均价）
+	exitSize  float64
+	exitValue float64 // 平仓成交额（计算平仓均价）
+	size      float64 // 当前持仓数量（基础币种）
+}
+
+// rebuildRoundTrips 按时间顺序将成交记录还原为开平仓，返回已平仓条目、未平仓数量和交易所记录的平仓盈亏合计
+// 双向持仓按 posSide 分别跟踪；单向持仓（net）按持仓方向判断开平，反手成交拆分为平仓和开仓
+func rebuildRoundTrips(trades []models.Trade, tradingPair string, contractValue float64, quoteCurrency string) ([]journal.Entry, int, float64) {
+	const epsilon = 1e-12
+
+	var entries []journal.Entry
+	var exchangePnL float64
+	books := make(map[string]*roundTrip) // key: posSide
+
+	for _, t := range trades {
+		exchangePnL += t.RealizedPnL
+		size := t.Size * contractValue
+		fee := -t.Fee // 交易所手续费负数为支出，日志中正数为支出
+		if t.FeeCurrency != "" && !strings.EqualFold(t.FeeCurrency, quoteCurrency) {
+			fee *= t.Price // 以基础币种收取的手续费换算为计价币种
+		}
+
+		key := t.PosSide
+		if key == "" {
+			key = "net"
+		}
+
+		for size > epsilon {
+			book := books[key]
+
+			// 判断该笔成交是开仓还是平仓
+			var openSide string
+			switch {
+			case key == "long" || key == "short":
+				if (key == "long") == (t.Side == "buy") {
+					openSide = key
+				}
+			case book == nil:
+				openSide = "long"
+				if t.Side == "sell" {
+					openSide = "short"
+				}
+			case (book.entry.Side == "long") != (t.Side == "buy"):
+				openSide = "" // 与持仓方向相反，平仓
+			default:
+				openSide = book.entry.Side
+			}
+
+			if openSide != "" {
+				if book == nil {
+					book = &roundTrip{entry: journal.Entry{
+						TradingPair:  tradingPair,
+						Side:         openSide,
+						Reason:       backfillReason,
+						OpenedAt:     t.Timestamp,
+						EntryOrderID: t.OrderID,
+					}}
+					books[key] = book
+				}
+				book.size += size
+				book.entry.Size += size
+				book.openCost += size * t.Price
+				book.entry.EntryFee += fee
+				break
+			}
+
+			if book == nil {
+				// 回填区间之前开的仓，无法还原开仓信息
+				break
+			}
+
+			// 平仓（反手时超出持仓的部分继续作为新开仓处理）
+			closeSize := math.Min(size, book.size)
+			closeFee := fee * closeSize / size
+			book.exitSize += closeSize
+			book.exitValue += closeSize * t.Price
+			book.entry.ExitFee += closeFee
+			book.size -= closeSize
+			size -= closeSize
+			fee -= closeFee
+
+			if book.size <= epsilon*math.Max(1, book.entry.Size) {
+				book.entry.EntryPrice = book.openCost / book.entry.Size
+				book.entry.Closed = true
+				book.entry.ExitPrice = book.exitValue / book.exitSize
+				book.entry.ExitReason = backfillReason
+				book.entry.ClosedAt = t.Timestamp
+				entries = append(entries, book.entry)
+				delete(books, key)
+			}
+		}
+	}
+
+	return entries, len(books), exchangePnL
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/strategy"
+)
+
+// newCarryTrades 创建资金费率套利和基差交易（每个交易对一个，现货和永续合约分别使用下单交易所的现货、合约客户端）
+// 测试模式启用模拟撮合时两边均在本地模拟成交，行情和资金费率来自真实交易所
+func newCarryTrades(cfg *config.Config) ([]*strategy.CarryTrade, error) {
+	fundingPairs, basisPairs := carryPairs(cfg)
+	if len(fundingPairs)+len(basisPairs) == 0 {
+		return nil, nil
+	}
+
+	spot, err := exchange.NewExchange(&cfg.API, config.TradingModeSpot)
+	if err != nil {
+		return nil, fmt.Errorf("创建现货客户端失败: %w", err)
+	}
+	perp, err := exchange.NewExchange(&cfg.API, config.TradingModeFutures)
+	if err != nil {
+		return nil, fmt.Errorf("创建合约客户端失败: %w", err)
+	}
+	if caps := perp.Capabilities(); !caps.Spot || !caps.Futures {
+		return nil, fmt.Errorf("交易所 %s 不同时支持现货和永续合约", cfg.API.ExchangeType)
+	}
+
+	if cfg.Trading.TestMode && cfg.Trading.PaperTrading.Enable {
+		spot, perp = newPaperExchange(cfg, spot, config.TradingModeSpot), newPaperExchange(cfg, perp, config.TradingModeFutures)
+	}
+
+	var trades []*strategy.CarryTrade
+	for _, pair := range fundingPairs {
+		trades = append(trades, strategy.NewFundingArbitrage(cfg, spot, perp, pair))
+	}
+	for _, pair := range basisPairs {
+		trades = append(trades, strategy.NewBasisTrade(cfg, spot, perp, pair))
+	}
+	return trades, nil
+}
+
+// carryPairs 启用的资金费率套利和基差交易的交易对
+func carryPairs(cfg *config.Config) (fundingPairs, basisPairs []string) {
+	if cfg.Trading.FundingArb.Enable {
+		fundingPairs = cfg.Trading.FundingArb.Pairs
+	}
+	if cfg.Trading.Basis.Trade {
+		basisPairs = cfg.Trading.Basis.TradePairs
+	}
+	return fundingPairs, basisPairs
+}
+
+// newPaperExchange 创建资金费率套利和基差交易使用的模拟撮合交易所（各交易对的计价币种按模拟初始余额入账）
+func newPaperExchange(cfg *config.Config, market exchange.Exchange, mode config.TradingMode) *exchange.MockExchange {
+	paperCfg := cfg.Trading.PaperTrading
+	paper := exchange.NewMockExchange(mode)
+	paper.SetMarketData(market)
+	if paperCfg.TakerFeePercent > 0 {
+		paper.SetTakerFeeRate(paperCfg.TakerFeePercent / 100)
+	}
+	paper.SetSlippage(paperCfg.SlippageBps)
+
+	initialBalance := paperInitialBalance(cfg)
+	fundingPairs, basisPairs := carryPairs(cfg)
+	for _, pair := range append(append([]string{}, fundingPairs...), basisPairs...) {
+		paper.SetBalance(pair[strings.Index(pair, "-")+1:], initialBalance)
+	}
+	account := "合约"
+	if mode == config.TradingModeSpot {
+		account = "现货"
+	}
+	logger.Printf("模拟撮合: 现货-合约组合%s账户已启用 (初始余额 %.2f)", account, initialBalance)
+	return paper
+}
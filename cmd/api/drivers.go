@@ -0,0 +1,7 @@
+package main
+
+// 持久化存储的 database/sql 驱动（store.Open 按驱动名 sqlite / pgx 打开）
+import (
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
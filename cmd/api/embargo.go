@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"dsbot/internal/embargo"
+	"dsbot/internal/logger"
+)
+
+// runEmbargoCommand 管理临时禁止交易名单，返回进程退出码
+// 运行中的机器人检测到文件变化后自动生效
+// 用法:
+//
+//	dsbot embargo list [--file data/embargo.json]
+//	dsbot embargo add <交易对或币种> [--hours 24] [--reason 下架公告]
+//	dsbot embargo lift <交易对或币种>
+func runEmbargoCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("用法: dsbot embargo <list|add|lift> [交易对或币种] [选项]")
+		return 2
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("embargo "+action, flag.ContinueOnError)
+	file := fs.String("file", embargo.DefaultFile, "临时禁令文件（与配置 trading.embargo.file 一致）")
+	hours := fs.Float64("hours", 0, "禁止时长（小时，0 表示直到手动解除）")
+	reason := fs.String("reason", "", "禁止原因")
+
+	// 交易对参数在选项之前或之后均可
+	var symbol string
+	rest := args[1:]
+	if len(rest) > 0 && len(rest[0]) > 0 && rest[0][0] != '-' {
+		symbol, rest = rest[0], rest[1:]
+	}
+	if err := fs.Parse(rest); err != nil {
+		return 2
+	}
+	if symbol == "" && fs.NArg() > 0 {
+		symbol = fs.Arg(0)
+	}
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		return 1
+	}
+
+	list, err := embargo.NewList(nil, *file)
+	if err != nil {
+		fmt.Printf("加载禁止交易名单失败: %v\n", err)
+		return 1
+	}
+
+	switch action {
+	case "list":
+		entries := list.Entries()
+		for _, e := range entries {
+			until := "手动解除"
+			if !e.Until.IsZero() {
+				until = e.Until.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-16s 至 %-20s %s\n", e.Symbol, until, e.Reason)
+		}
+		fmt.Printf("共 %d 条临时禁令\n", len(entries))
+
+	case "add":
+		if symbol == "" {
+			fmt.Println("请指定交易对或币种")
+			return 2
+		}
+		var until time.Time
+		if *hours > 0 {
+			until = time.Now().Add(time.Duration(*hours * float64(time.Hour)))
+		}
+		if err := list.Embargo(symbol, until, *reason); err != nil {
+			fmt.Printf("添加禁令失败: %v\n", err)
+			return 1
+		}
+		fmt.Printf("已禁止交易: %s\n", symbol)
+
+	case "lift":
+		if symbol == "" {
+			fmt.Println("请指定交易对或币种")
+			return 2
+		}
+		found, err := list.Lift(symbol)
+		if err != nil {
+			fmt.Printf("解除禁令失败: %v\n", err)
+			return 1
+		}
+		if !found {
+			fmt.Printf("%s 没有临时禁令（黑名单需在配置中修改）\n", symbol)
+			return 1
+		}
+		fmt.Printf("已解除禁令: %s\n", symbol)
+
+	default:
+		fmt.Printf("不支持的操作: %s (支持: list, add, lift)\n", action)
+		return 2
+	}
+
+	return 0
+}
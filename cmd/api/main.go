@@ -1,23 +1,54 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"dsbot/internal/ai"
+	"dsbot/internal/calendar"
 	"dsbot/internal/config"
-	"dsbot/internal/exchange"
+	"dsbot/internal/controlapi"
+	"dsbot/internal/datafeeds"
+	"dsbot/internal/embargo"
+	"dsbot/internal/journal"
 	"dsbot/internal/logger"
+	"dsbot/internal/nets"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
 	"dsbot/internal/strategy"
 	"dsbot/internal/timedschedulers"
+	"dsbot/internal/tracing"
+	"dsbot/internal/watchdog"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "symbols":
+			os.Exit(runSymbolsCommand(os.Args[2:]))
+		case "embargo":
+			os.Exit(runEmbargoCommand(os.Args[2:]))
+		case "backfill":
+			os.Exit(runBackfillCommand(os.Args[2:]))
+		case "pnl":
+			os.Exit(runPnLCommand(os.Args[2:]))
+		case "snapshot":
+			os.Exit(runSnapshotCommand(os.Args[2:]))
+		case "restore":
+			os.Exit(runRestoreCommand(os.Args[2:]))
+		case "prompt-backtest":
+			os.Exit(runPromptBacktestCommand(os.Args[2:]))
+		}
+	}
+
 	// 加载环境变量
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("未找到 .env 文件，将使用配置文件和系统环境变量")
@@ -49,66 +80,234 @@ func main() {
 		}
 	}
 
-	// 初始化客户端
-	tradingMode := cfg.GetTradingMode()
-	exchangeClient, err := exchange.NewExchange(&cfg.API, tradingMode)
+	// 初始化链路追踪（OTLP 导出到 Jaeger/Tempo）
+	if err := tracing.Init(&cfg.Tracing); err != nil {
+		logger.Printf("初始化链路追踪失败: %v", err)
+	}
+	defer tracing.Shutdown()
+
+	// 初始化持久化存储
+	dataStore, err := store.Open(&cfg.Storage)
 	if err != nil {
-		logger.Printf("创建交易所客户端失败: %v", err)
+		logger.Printf("打开持久化存储失败: %v", err)
 		os.Exit(1)
 	}
-	deepseekClient := ai.NewDeepSeekClient(&cfg.API)
+	defer dataStore.Close()
+	logger.Printf("持久化存储: %s", dataStore.Backend())
+	if cfg.Storage.AuditAI {
+		ai.SetAuditStore(dataStore)
+	}
+
+	// 多进程分片：认领交易对租约（需在创建机器人前确定交易对）
+	var pairLease *strategy.PairLease
+	if cfg.Sharding.Enable {
+		pairLease, err = newPairLease(cfg, dataStore)
+		if err != nil {
+			logger.Printf("初始化多进程分片失败: %v", err)
+			os.Exit(1)
+		}
+	}
 
-	// 创建交易机器人
-	bot := strategy.NewTradingBot(cfg, exchangeClient, deepseekClient)
+	// 初始化客户端（每个交易对按路由使用各自的下单交易所和行情来源）
+	router := newVenueRouter(cfg)
+	aiClient, err := ai.NewProvider(&cfg.API)
+	if err != nil {
+		logger.Printf("创建AI客户端失败: %v", err)
+		os.Exit(1)
+	}
 
-	// 打印启动信息
-	printStartupInfo(cfg)
+	// 初始化交易日历（统一每日统计的日界线）
+	tradingCalendar, err := calendar.NewCalendar(&cfg.Trading.Calendar)
+	if err != nil {
+		logger.Printf("创建交易日历失败: %v", err)
+		os.Exit(1)
+	}
+	tradingCalendar.CheckRollover()
 
-	// 设置交易所参数
-	if err := bot.SetupExchange(); err != nil {
-		logger.Printf("交易所设置失败: %v", err)
+	// 初始化交易时段（允许的时段外和禁止开仓时间段内不开仓）
+	var sessions *calendar.Sessions
+	if cfg.Trading.TradingSessions.Enable {
+		if sessions, err = calendar.NewSessions(&cfg.Trading.TradingSessions, cfg.Trading.Calendar.Timezone); err != nil {
+			logger.Printf("创建交易时段失败: %v", err)
+			os.Exit(1)
+		}
 	}
 
-	// 【修复】启动风险管理器前先获取当前持仓
-	if cfg.IsFuturesMode() {
-		symbol := exchangeClient.ParseSymbols(cfg.Trading.SymbolA, cfg.Trading.SymbolB)
-		currentPos, err := exchangeClient.FetchPosition(symbol)
+	// 初始化交易日志（记录开平仓，用于期望值过滤等统计）
+	tradeJournal, err := journal.NewJournal(cfg.Trading.Journal.File)
+	if err != nil {
+		logger.Printf("加载交易日志失败: %v", err)
+		os.Exit(1)
+	}
+
+	// 初始化禁止交易名单（黑名单 + 临时禁令，运行中可通过 dsbot embargo 命令修改）
+	embargoList, err := embargo.NewList(cfg.Trading.Embargo.Blacklist, cfg.Trading.Embargo.File)
+	if err != nil {
+		logger.Printf("加载禁止交易名单失败: %v", err)
+		os.Exit(1)
+	}
+
+	// 初始化外部市场数据源（各交易对共用，按刷新间隔缓存）
+	var newsFeed *datafeeds.NewsFeed
+	if cfg.DataFeeds.News.Enable {
+		if newsFeed, err = datafeeds.NewNewsFeed(cfg.DataFeeds.News); err != nil {
+			logger.Printf("创建新闻标题源失败: %v", err)
+			os.Exit(1)
+		}
+	}
+	var fearGreedFeed *datafeeds.FearGreedFeed
+	if cfg.DataFeeds.FearGreed.Enable {
+		if fearGreedFeed, err = datafeeds.NewFearGreedFeed(cfg.DataFeeds.FearGreed); err != nil {
+			logger.Printf("创建恐惧贪婪指数数据源失败: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// 初始化通知（持久化发件队列，渠道故障或重启不丢失事件）
+	notifier := newNotifier(cfg)
+	if notifier != nil {
+		notifier.Start()
+		defer notifier.Stop()
+	}
+
+	// 创建交易机器人（每个交易对一个，共用跨交易对的全局风险限制）
+	portfolio := strategy.NewPortfolioLimits(cfg.Trading.RiskManagement)
+	var runtimes []*pairRuntime
+	for _, pair := range configuredPairs(cfg) {
+		route, err := router.route(pair)
 		if err != nil {
-			logger.Printf("获取初始持仓失败: %v", err)
-		} else if currentPos != nil {
-			logger.Printf("[风险管理] 检测到已有持仓 - 方向:%s, 数量:%.8f, 开仓价:%.2f",
-				currentPos.Side, currentPos.Size, currentPos.EntryPrice)
+			logger.Printf("交易对 %s 创建交易所客户端失败: %v", pair.TradingPair(), err)
+			os.Exit(1)
+		}
+
+		pairCfg := *cfg
+		pairCfg.Trading.SymbolA, pairCfg.Trading.SymbolB = pair.SymbolA, pair.SymbolB
+		pairCfg.API = *route.api
+
+		bot := strategy.NewTradingBot(&pairCfg, route.exchange, aiClient)
+		bot.SetCalendar(tradingCalendar)
+		bot.SetIntentStore(dataStore)
+		bot.SetEventStore(dataStore)
+		bot.SetSignalCache(dataStore)
+		bot.SetRiskStateStore(dataStore)
+		if cfg.Storage.ArchiveMarketData {
+			bot.SetAnalysisArchive(dataStore)
+		}
+		bot.SetJournal(tradeJournal)
+		bot.SetEmbargo(embargoList)
+		if sessions != nil {
+			bot.SetSessions(sessions)
+		}
+		if newsFeed != nil {
+			bot.SetNewsFeed(newsFeed)
+		}
+		if fearGreedFeed != nil {
+			bot.SetFearGreedFeed(fearGreedFeed)
 		}
+		if notifier != nil {
+			bot.SetNotifier(notifier)
+		}
+		if portfolio != nil {
+			bot.SetPortfolioLimits(portfolio)
+		}
+		if route.spot != nil {
+			bot.SetSpotMarket(route.spot)
+		}
+		runtimes = append(runtimes, &pairRuntime{pair: pair, cfg: &pairCfg, route: route, bot: bot})
+	}
+	if pairLease != nil {
+		if err := pairLease.Start(); err != nil {
+			logger.Printf("启动交易对租约失败: %v", err)
+			os.Exit(1)
+		}
+		defer pairLease.Stop()
+		runtimes[0].bot.SetLease(pairLease)
+	}
+
+	// 主备切换：主实例写入心跳，备用实例不开仓，主实例失联时接管风控
+	var failover *strategy.Failover
+	if cfg.Failover.Enable {
+		failover = strategy.NewFailover(dataStore, cfg.Failover, workerID(cfg))
+		if notifier != nil {
+			failover.SetNotifier(notifier)
+		}
+		for _, rt := range runtimes {
+			rt.bot.SetFailover(failover)
+		}
+		if err := failover.Start(); err != nil {
+			logger.Printf("启动主备切换失败: %v", err)
+			os.Exit(1)
+		}
+		defer failover.Stop()
+	}
+
+	// 生成启动记录（输出到日志和 /status，版本标记写入交易日志）
+	startup := newStartupRecord(cfg, runtimes)
+	printStartupInfo(cfg, startup)
+	tradeJournal.SetBuild(startup.Stamp)
+
+	// 启动HTTP控制接口
+	if cfg.ControlAPI.Listen != "" {
+		controlServer, err := controlapi.NewServer(&cfg.ControlAPI)
+		if err != nil {
+			logger.Printf("创建控制接口失败: %v", err)
+			os.Exit(1)
+		}
+		controlServer.Handle("/status", controlapi.ScopeRead, statusHandler(startup))
+		if cfg.Trading.Basis.Enable {
+			controlServer.Handle("/basis", controlapi.ScopeRead, basisHandler(runtimes))
+		}
+		if err := controlServer.Start(); err != nil {
+			logger.Printf("启动控制接口失败: %v", err)
+			os.Exit(1)
+		}
+		defer controlServer.Stop()
 	}
 
-	// 启动风险管理器（如果已启用）
-	if err := bot.StartRiskManager(); err != nil {
+	for _, rt := range runtimes {
+		// 确认交易对在下单交易所存在且可交易
+		if err := validatePair(rt); err != nil {
+			logger.Printf("[%s] 交易对检查失败: %v", rt.pair.TradingPair(), err)
+			os.Exit(1)
+		}
+
+		// 设置交易所参数
+		if err := rt.bot.SetupExchange(); err != nil {
+			logger.Printf("[%s] 交易所设置失败: %v", rt.pair.TradingPair(), err)
+		}
+
+		// 启动风险管理器前核对交易所持仓和委托，接管已有持仓
+		rt.bot.Reconcile()
+	}
+
+	// 启动统一风控循环（所有交易对共用一个循环，同一路由的交易对共用行情总线）
+	riskMonitor := strategy.NewRiskMonitor(runtimes[0].route.priceBus)
+	for _, rt := range runtimes {
+		for _, rm := range rt.bot.GetRiskManagers() {
+			riskMonitor.AddWithPriceBus(rm, rt.route.priceBus)
+		}
+	}
+	if err := riskMonitor.Start(); err != nil {
 		logger.Printf("启动风险管理器失败: %v", err)
 	}
-	defer bot.StopRiskManager()
+	defer riskMonitor.Stop()
 
-	// 创建交易任务调度器
+	// 创建交易任务调度器（每个交易对一个）
 	// 模式：config配置的时间+延迟3秒执行，立即执行一次
-	var tradingScheduler *timedschedulers.Scheduler
-	tradingScheduler = timedschedulers.NewScheduler(
-		bot.Run,
-		time.Duration(cfg.Trading.ScheduleIntervalMinutes)*time.Minute,
-		timedschedulers.WithAlignedSchedule(3*time.Second),
-		timedschedulers.WithRunImmediately(true),
-		timedschedulers.WithErrorHandler(func(err error) {
-			logger.Printf("执行交易失败: %v", err)
-		}),
-		timedschedulers.WithCompleteHandler(func() {
-			nextRun := tradingScheduler.GetNextRunTime()
-			logger.Printf("下次执行时间: %s", nextRun.Format("2006-01-02 15:04:05"))
-		}),
-	)
+	for _, rt := range runtimes {
+		rt.scheduler = newTradingScheduler(rt, len(runtimes) > 1)
+
+		// 自适应执行频率：按波动状态调整交易调度间隔
+		if cfg.Trading.AdaptiveCadence.Enable {
+			rt.bot.SetCadenceHandler(rt.scheduler.SetInterval)
+		}
+	}
 
 	// 创建日志轮转调度器（每小时执行一次）
 	var logScheduler *timedschedulers.Scheduler
 	if cfg.Logging.EnableFileLogging {
 		logScheduler = timedschedulers.NewScheduler(
-			func() error {
+			func(context.Context) error {
 				return logger.RotateLog(cfg.Logging.LogDir)
 			},
 			time.Hour,
@@ -119,12 +318,82 @@ func main() {
 		)
 	}
 
+	// 创建日切检查调度器（每分钟检查一次）
+	calendarScheduler := timedschedulers.NewScheduler(
+		func(context.Context) error {
+			tradingCalendar.CheckRollover()
+			return nil
+		},
+		time.Minute,
+		timedschedulers.WithRunImmediately(false),
+	)
+
 	// 启动调度器
-	if err := tradingScheduler.Start(); err != nil {
-		logger.Printf("启动交易调度器失败: %v", err)
+	for _, rt := range runtimes {
+		if err := rt.scheduler.Start(); err != nil {
+			logger.Printf("启动交易调度器 %s 失败: %v", rt.pair.TradingPair(), err)
+			os.Exit(1)
+		}
+		defer rt.scheduler.Stop()
+	}
+
+	if err := calendarScheduler.Start(); err != nil {
+		logger.Printf("启动日切检查调度器失败: %v", err)
+	}
+	defer calendarScheduler.Stop()
+
+	// 突破入场：定期检查等待中的开仓信号是否触发或过期
+	if cfg.Trading.StopEntry.Enable {
+		for _, rt := range runtimes {
+			pair := rt.pair.TradingPair()
+			stopEntryScheduler := timedschedulers.NewScheduler(
+				rt.bot.CheckStopEntry,
+				strategy.StopEntryCheckInterval(cfg.Trading.StopEntry),
+				timedschedulers.WithRunImmediately(false),
+				timedschedulers.WithErrorHandler(func(err error) {
+					logger.Printf("[突破入场] %s 检查失败: %v", pair, err)
+				}),
+			)
+			if err := stopEntryScheduler.Start(); err != nil {
+				logger.Printf("启动突破入场调度器 %s 失败: %v", pair, err)
+				continue
+			}
+			defer stopEntryScheduler.Stop()
+		}
+	}
+
+	// 资金费率套利和基差交易：定期检查资金费率或合约溢价，达到阈值时持有现货多头 + 合约空头，回落后平仓
+	carryTrades, err := newCarryTrades(cfg)
+	if err != nil {
+		logger.Printf("创建资金费率套利/基差交易失败: %v", err)
 		os.Exit(1)
 	}
-	defer tradingScheduler.Stop()
+	for _, ct := range carryTrades {
+		pair, label := ct.TradingPair(), ct.Label()
+		ct.SetEventStore(dataStore)
+		ct.SetStateStore(dataStore)
+		if notifier != nil {
+			ct.SetNotifier(notifier)
+		}
+		if failover != nil {
+			ct.SetFailover(failover)
+		}
+		if err := ct.Setup(); err != nil {
+			logger.Printf("[%s] %s 设置失败: %v", label, pair, err)
+		}
+		carryScheduler := timedschedulers.NewScheduler(
+			ct.Run,
+			ct.CheckInterval(),
+			timedschedulers.WithErrorHandler(func(err error) {
+				logger.Printf("[%s] %s 检查失败: %v", label, pair, err)
+			}),
+		)
+		if err := carryScheduler.Start(); err != nil {
+			logger.Printf("启动%s调度器 %s 失败: %v", label, pair, err)
+			continue
+		}
+		defer carryScheduler.Stop()
+	}
 
 	if logScheduler != nil {
 		if err := logScheduler.Start(); err != nil {
@@ -133,6 +402,15 @@ func main() {
 		defer logScheduler.Stop()
 	}
 
+	// 启动看门狗（监控交易任务和风控循环是否卡死）
+	if cfg.Watchdog.Enable {
+		wd := newWatchdog(cfg, runtimes, riskMonitor, notifier)
+		if err := wd.Start(); err != nil {
+			logger.Printf("启动看门狗失败: %v", err)
+		}
+		defer wd.Stop()
+	}
+
 	// 显示调度信息
 	intervalMinutes := cfg.Trading.ScheduleIntervalMinutes
 	alignPoints := calculateAlignPoints(intervalMinutes)
@@ -150,6 +428,183 @@ func main() {
 	logger.Println("正在停止调度器...")
 }
 
+// newNotifier 根据配置创建通知分发器（未启用或未配置渠道时返回nil）
+func newNotifier(cfg *config.Config) *notify.Dispatcher {
+	if !cfg.Notification.Enable {
+		return nil
+	}
+
+	var notifiers []notify.Notifier
+	if cfg.Notification.WebhookURL != "" {
+		n, err := notify.NewWebhookNotifier(cfg.Notification.WebhookURL)
+		if err != nil {
+			logger.Printf("创建Webhook通知失败: %v", err)
+		} else {
+			notifiers = append(notifiers, n)
+		}
+	}
+	if cfg.Notification.TelegramBotToken != "" && cfg.Notification.TelegramChatID != "" {
+		n, err := notify.NewTelegramNotifier(cfg.Notification.TelegramBotToken, cfg.Notification.TelegramChatID)
+		if err != nil {
+			logger.Printf("创建Telegram通知失败: %v", err)
+		} else {
+			notifiers = append(notifiers, n)
+		}
+	}
+	if len(notifiers) == 0 {
+		logger.Println("[通知] 已启用但未配置任何通知渠道")
+		return nil
+	}
+
+	outboxDir := cfg.Notification.OutboxDir
+	if outboxDir == "" {
+		outboxDir = notify.DefaultOutboxDir
+	}
+	maxQueueSize := cfg.Notification.MaxQueueSize
+	if maxQueueSize <= 0 {
+		maxQueueSize = 1000
+	}
+
+	return notify.NewDispatcher(outboxDir, maxQueueSize, notifiers...)
+}
+
+// workerID 工作进程标识（默认 主机名-进程号）
+func workerID(cfg *config.Config) string {
+	if cfg.Sharding.WorkerID != "" {
+		return cfg.Sharding.WorkerID
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// newPairLease 创建交易对租约
+// 配置了交易对池时阻塞等待认领一个空闲交易对，并用其覆盖 symbolA/symbolB
+func newPairLease(cfg *config.Config, dataStore store.Store) (*strategy.PairLease, error) {
+	leaser, ok := dataStore.(store.Leaser)
+	if !ok {
+		return nil, fmt.Errorf("存储后端 %s 不支持租约", dataStore.Backend())
+	}
+
+	owner := workerID(cfg)
+	ttl := time.Duration(cfg.Sharding.LeaseSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = 60 * time.Second
+	}
+
+	tradingPair := fmt.Sprintf("%s-%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	if len(cfg.Sharding.Pairs) > 0 {
+		for _, pair := range cfg.Sharding.Pairs {
+			if parts := strings.Split(pair, "-"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("无效的交易对: %q (格式如 BTC-USDT)", pair)
+			}
+		}
+
+		waiting := false
+		for {
+			pair, err := strategy.ClaimPair(leaser, cfg.Sharding.Pairs, owner, ttl)
+			if err != nil {
+				logger.Warnf("[租约] 认领交易对失败: %v", err)
+			}
+			if pair != "" {
+				tradingPair = pair
+				break
+			}
+			if !waiting {
+				logger.Printf("[租约] 交易对池 %v 均由其他进程持有，等待空闲交易对...", cfg.Sharding.Pairs)
+				waiting = true
+			}
+			time.Sleep(ttl / 3)
+		}
+
+		parts := strings.Split(tradingPair, "-")
+		cfg.Trading.SymbolA, cfg.Trading.SymbolB = parts[0], parts[1]
+		logger.Printf("[租约] 本进程 (%s) 认领交易对 %s", owner, tradingPair)
+	}
+
+	return strategy.NewPairLease(leaser, tradingPair, owner, ttl), nil
+}
+
+// newTradingScheduler 创建交易对的交易任务调度器（多交易对时日志带交易对前缀）
+func newTradingScheduler(rt *pairRuntime, multiPair bool) *timedschedulers.Scheduler {
+	prefix := ""
+	if multiPair {
+		prefix = fmt.Sprintf("[%s] ", rt.pair.TradingPair())
+	}
+
+	var scheduler *timedschedulers.Scheduler
+	scheduler = timedschedulers.NewScheduler(
+		rt.bot.Run,
+		time.Duration(rt.cfg.Trading.ScheduleIntervalMinutes)*time.Minute,
+		timedschedulers.WithAlignedSchedule(3*time.Second),
+		timedschedulers.WithRunImmediately(true),
+		timedschedulers.WithErrorHandler(func(err error) {
+			logger.Printf("%s执行交易失败: %v", prefix, err)
+		}),
+		timedschedulers.WithCompleteHandler(func() {
+			logPaperAccount(rt, prefix)
+			nextRun := scheduler.GetNextRunTime()
+			logger.Printf("%s下次执行时间: %s", prefix, nextRun.Format("2006-01-02 15:04:05"))
+		}),
+	)
+	return scheduler
+}
+
+// newWatchdog 创建看门狗并注册需要监控的组件
+func newWatchdog(cfg *config.Config, runtimes []*pairRuntime, riskMonitor *strategy.RiskMonitor, notifier *notify.Dispatcher) *watchdog.Watchdog {
+	checkInterval := time.Duration(cfg.Watchdog.CheckIntervalSeconds) * time.Second
+	if checkInterval <= 0 {
+		checkInterval = 30 * time.Second
+	}
+
+	options := []watchdog.Option{
+		watchdog.WithAlertHandler(func(component string, err error) {
+			logger.Errorf("⚠️ 看门狗告警 [%s]: %v，请人工检查", component, err)
+			if notifier != nil {
+				notifier.Publish(notify.LevelError, "看门狗告警",
+					fmt.Sprintf("组件 %s 异常: %v，请人工检查", component, err))
+			}
+		}),
+	}
+	if cfg.Watchdog.MaxRestarts > 0 {
+		options = append(options, watchdog.WithMaxRestarts(cfg.Watchdog.MaxRestarts))
+	}
+
+	wd := watchdog.NewWatchdog(checkInterval, options...)
+
+	// 交易任务：允许两个调度周期加1分钟的余量
+	tradingInterval := time.Duration(cfg.Trading.ScheduleIntervalMinutes) * time.Minute
+	if cfg.Trading.AdaptiveCadence.Enable {
+		// 自适应执行频率下按最长间隔计算
+		if _, maxInterval := strategy.CadenceBounds(cfg.Trading.AdaptiveCadence); maxInterval > tradingInterval {
+			tradingInterval = maxInterval
+		}
+	}
+	for _, rt := range runtimes {
+		name := "trading"
+		if len(runtimes) > 1 {
+			name = "trading:" + rt.pair.TradingPair()
+		}
+		wd.Register(name, 2*tradingInterval+time.Minute,
+			rt.scheduler.LastActivity, rt.scheduler.Restart)
+	}
+
+	// 风控循环：允许若干个检查周期（单次检查可能包含多个HTTP请求），按最长的检查间隔计算
+	var riskInterval time.Duration
+	for _, rt := range runtimes {
+		for _, rm := range rt.bot.GetRiskManagers() {
+			if rm.CheckInterval() > riskInterval {
+				riskInterval = rm.CheckInterval()
+			}
+		}
+	}
+	if riskInterval > 0 {
+		wd.Register("risk", 10*riskInterval+nets.DefaultTimeout,
+			riskMonitor.LastActivity, riskMonitor.Restart)
+	}
+
+	return wd
+}
+
 // calculateAlignPoints 计算对齐点（用于显示）
 func calculateAlignPoints(intervalMinutes int) []int {
 	var points []int
@@ -158,28 +613,3 @@ func calculateAlignPoints(intervalMinutes int) []int {
 	}
 	return points
 }
-
-func printStartupInfo(cfg *config.Config) {
-	exchangeType := cfg.API.ExchangeType
-
-	logger.Println("============================================================")
-	logger.Printf("%s/%s %s 自动交易机器人启动成功！", cfg.Trading.SymbolA, cfg.Trading.SymbolB, exchangeType)
-	logger.Println("Go语言版本 - 融合技术指标策略 + 多交易所支持")
-	logger.Println("============================================================")
-
-	if cfg.Trading.TestMode {
-		logger.Println("⚠️  当前为模拟模式，不会真实下单")
-	} else {
-		logger.Println("🔴 实盘交易模式，请谨慎操作！")
-	}
-
-	logger.Printf("交易所: %s", exchangeType)
-	logger.Printf("交易对: %s/%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
-	logger.Printf("交易类型: %s", cfg.GetTradingMode())
-	logger.Printf("交易周期: %s", cfg.Trading.Timeframe)
-	logger.Printf("杠杆倍数: %dx", cfg.Trading.Leverage)
-	logger.Printf("交易数量: %.8f %s", cfg.Trading.Amount, cfg.Trading.SymbolB)
-	logger.Printf("执行频率: 每 %d 分钟", cfg.Trading.ScheduleIntervalMinutes)
-	logger.Println("已启用完整技术指标分析和持仓跟踪功能")
-	logger.Println("============================================================")
-}
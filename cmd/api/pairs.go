@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/strategy"
+	"dsbot/internal/timedschedulers"
+)
+
+// pairRuntime 单个交易对的运行组件
+type pairRuntime struct {
+	pair      config.PairConfig
+	cfg       *config.Config // 交易对专属配置（symbolA/symbolB 和 api 按路由覆盖）
+	route     *venueRoute
+	bot       *strategy.TradingBot
+	scheduler *timedschedulers.Scheduler
+}
+
+// configuredPairs 获取本进程运行的交易对及其交易所路由
+// 未配置 trading.pairs 时只运行 symbolA/symbolB；启用分片时只运行本进程认领的交易对
+func configuredPairs(cfg *config.Config) []config.PairConfig {
+	current := config.PairConfig{SymbolA: cfg.Trading.SymbolA, SymbolB: cfg.Trading.SymbolB}
+	if len(cfg.Trading.Pairs) == 0 {
+		return []config.PairConfig{current}
+	}
+	if cfg.Sharding.Enable {
+		for _, pair := range cfg.Trading.Pairs {
+			if pair.TradingPair() == current.TradingPair() {
+				return []config.PairConfig{pair}
+			}
+		}
+		return []config.PairConfig{current}
+	}
+	return cfg.Trading.Pairs
+}
+
+// venueRoute 一条交易所路由（下单交易所 + 行情来源）
+type venueRoute struct {
+	name     string            // 路由名称（如 okx、okx (行情: binance)）
+	api      *config.APIConfig // 下单交易所配置
+	exchange exchange.Exchange
+	client   exchange.Exchange // 下单交易所客户端（未经行情路由和模拟撮合包装）
+	priceBus *strategy.PriceBus
+	paper    *exchange.MockExchange // 模拟撮合交易所（未启用时为nil）
+	spot     exchange.Exchange      // 同一交易所的现货客户端（仅获取行情计算基差，未启用基差监控时为nil）
+}
+
+// venueRouter 按交易所路由创建客户端，相同路由的交易对共用客户端和行情总线
+type venueRouter struct {
+	cfg         *config.Config
+	tradingMode config.TradingMode
+	clients     map[string]exchange.Exchange         // 下单交易所客户端（按 venue）
+	spotClients map[string]exchange.Exchange         // 基差监控的现货客户端（按 venue）
+	sources     map[string]exchange.MarketDataSource // 行情数据源（按 data_venue）
+	routes      map[string]*venueRoute
+}
+
+func newVenueRouter(cfg *config.Config) *venueRouter {
+	return &venueRouter{
+		cfg:         cfg,
+		tradingMode: cfg.GetTradingMode(),
+		clients:     make(map[string]exchange.Exchange),
+		spotClients: make(map[string]exchange.Exchange),
+		sources:     make(map[string]exchange.MarketDataSource),
+		routes:      make(map[string]*venueRoute),
+	}
+}
+
+// route 获取交易对的交易所路由
+func (r *venueRouter) route(pair config.PairConfig) (*venueRoute, error) {
+	dataVenue := pair.DataVenue
+	if dataVenue == pair.Venue {
+		dataVenue = ""
+	}
+	key := pair.Venue + "|" + dataVenue
+	if route, ok := r.routes[key]; ok {
+		if route.paper != nil {
+			r.setPaperBalance(route, pair.SymbolB)
+		}
+		return route, nil
+	}
+
+	api, err := r.cfg.API.VenueConfig(pair.Venue)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := r.clients[pair.Venue]
+	if !ok {
+		if client, err = exchange.NewExchange(api, r.tradingMode); err != nil {
+			return nil, fmt.Errorf("创建交易所客户端 %s 失败: %w", api.ExchangeType, err)
+		}
+		r.clients[pair.Venue] = client
+	}
+
+	route := &venueRoute{name: api.ExchangeType, api: api, exchange: client, client: client}
+	if route.spot, err = r.spotClient(pair.Venue, api, client); err != nil {
+		return nil, err
+	}
+	if dataVenue != "" {
+		source, ok := r.sources[dataVenue]
+		if !ok {
+			dataAPI, err := r.cfg.API.VenueConfig(dataVenue)
+			if err != nil {
+				return nil, err
+			}
+			if source, err = exchange.NewVenueDataSource(dataAPI, r.tradingMode); err != nil {
+				return nil, fmt.Errorf("创建行情数据源 %s 失败: %w", dataVenue, err)
+			}
+			r.sources[dataVenue] = source
+		}
+		route.exchange = exchange.NewRoutedExchange(client, source)
+		route.name = fmt.Sprintf("%s (行情: %s)", api.ExchangeType, source.Name())
+	}
+
+	// 测试模式模拟撮合：行情使用真实交易所，下单、持仓和余额在本地模拟
+	if r.cfg.Trading.TestMode && r.cfg.Trading.PaperTrading.Enable {
+		paperCfg := r.cfg.Trading.PaperTrading
+		route.paper = exchange.NewMockExchange(r.tradingMode)
+		route.paper.SetMarketData(route.exchange)
+		if paperCfg.TakerFeePercent > 0 {
+			route.paper.SetTakerFeeRate(paperCfg.TakerFeePercent / 100)
+		}
+		route.paper.SetSlippage(paperCfg.SlippageBps)
+		route.paper.SetHedgeMode(r.cfg.Trading.HedgeMode.Enable)
+		route.exchange = route.paper
+		r.setPaperBalance(route, pair.SymbolB)
+	}
+
+	route.priceBus = strategy.NewPriceBus(route.exchange, time.Second)
+	r.routes[key] = route
+	return route, nil
+}
+
+// spotClient 基差监控使用的同一交易所现货客户端（未启用基差监控、现货模式或交易所不支持现货时为nil）
+func (r *venueRouter) spotClient(venue string, api *config.APIConfig, client exchange.Exchange) (exchange.Exchange, error) {
+	if !r.cfg.Trading.Basis.Enable || r.tradingMode == config.TradingModeSpot {
+		return nil, nil
+	}
+	if spot, ok := r.spotClients[venue]; ok {
+		return spot, nil
+	}
+	if !client.Capabilities().Spot {
+		logger.Warnf("[基差] ⚠️ 交易所 %s 不支持现货，跳过基差监控", api.ExchangeType)
+		r.spotClients[venue] = nil
+		return nil, nil
+	}
+	spot, err := exchange.NewExchange(api, config.TradingModeSpot)
+	if err != nil {
+		return nil, fmt.Errorf("创建现货客户端 %s 失败: %w", api.ExchangeType, err)
+	}
+	r.spotClients[venue] = spot
+	return spot, nil
+}
+
+// setPaperBalance 设置模拟撮合的计价币种初始余额
+func (r *venueRouter) setPaperBalance(route *venueRoute, currency string) {
+	initialBalance := paperInitialBalance(r.cfg)
+	route.paper.SetBalance(currency, initialBalance)
+	logger.Printf("模拟撮合: 已启用 %s (初始余额 %.2f %s)", route.name, initialBalance, currency)
+}
+
+// paperInitialBalance 模拟撮合的计价币种初始余额
+func paperInitialBalance(cfg *config.Config) float64 {
+	if initialBalance := cfg.Trading.PaperTrading.InitialBalance; initialBalance > 0 {
+		return initialBalance
+	}
+	return 10000
+}
+
+// logPaperAccount 输出模拟账户的权益和相对初始余额的累计盈亏（含手续费和滑点）
+func logPaperAccount(rt *pairRuntime, prefix string) {
+	if rt.route.paper == nil {
+		return
+	}
+	currency := rt.pair.SymbolB
+	initialBalance := paperInitialBalance(rt.cfg)
+	account := rt.route.paper.Account(currency)
+	pnl := account.Equity - initialBalance
+	logger.Printf("%s模拟账户: 权益 %.2f %s，累计盈亏 %+.2f (%+.2f%%)，成交 %d 笔，手续费 %.2f",
+		prefix, account.Equity, currency, pnl, pnl/initialBalance*100, account.Trades, account.Fees)
+}
+
+// validatePair 启动时确认下单交易所支持当前交易模式、交易对存在且可交易，避免到下单时才失败
+// 不支持或交易对不存在、不可交易时返回错误；获取可交易列表失败（如网络问题）时仅记录警告
+func validatePair(rt *pairRuntime) error {
+	if mode := rt.cfg.GetTradingMode(); !rt.route.client.Capabilities().SupportsMode(mode) {
+		return fmt.Errorf("%s 不支持 %s 交易模式", rt.route.client.GetExchangeName(), mode)
+	}
+
+	info, err := exchange.FindInstrument(rt.route.client, rt.pair.SymbolA, rt.pair.SymbolB)
+	if errors.Is(err, exchange.ErrInstrumentUnavailable) {
+		return err
+	}
+	if err != nil {
+		logger.Printf("[WARNING] [%s] 无法确认交易对是否可交易: %v", rt.pair.TradingPair(), err)
+		return nil
+	}
+	logger.Printf("[%s] 交易对已确认: %s", rt.pair.TradingPair(), info.InstID)
+	return nil
+}
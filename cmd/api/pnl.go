@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// runPnLCommand 汇总交易日志和交易所资金流水，输出扣除手续费和资金费用后的净盈亏，返回进程退出码
+// 用法: dsbot pnl [--days 30] [--config config.json]
+func runPnLCommand(args []string) int {
+	fs := flag.NewFlagSet("pnl", flag.ContinueOnError)
+	days := fs.Int("days", 30, "统计天数")
+	configPath := fs.String("config", "config.json", "配置文件")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *days <= 0 {
+		fmt.Println("--days 必须大于0")
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		return 1
+	}
+
+	client, err := exchange.NewExchange(&cfg.API, cfg.GetTradingMode())
+	if err != nil {
+		fmt.Printf("创建交易所客户端失败: %v\n", err)
+		return 1
+	}
+	symbol := client.ParseSymbols(cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	tradingPair := fmt.Sprintf("%s-%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	quote := cfg.Trading.SymbolB
+	since := time.Now().AddDate(0, 0, -*days)
+
+	tradeJournal, err := journal.NewJournal(cfg.Trading.Journal.File)
+	if err != nil {
+		fmt.Printf("加载交易日志失败: %v\n", err)
+		return 1
+	}
+
+	// 交易盈亏（交易日志中区间内平仓的条目，已扣除开平仓手续费）
+	var closed int
+	var tradePnL, tradeFees float64
+	for _, e := range tradeJournal.Entries() {
+		if e.TradingPair != tradingPair || !e.Closed || e.ClosedAt.Before(since) {
+			continue
+		}
+		closed++
+		tradePnL += e.NetPnL
+		tradeFees += e.EntryFee + e.ExitFee
+	}
+
+	// 资金费用（合约）
+	var fundingPnL float64
+	var fundings []models.FundingFee
+	if cfg.IsFuturesMode() {
+		fundings, err = client.FetchFundingFees(symbol, since)
+		if err != nil {
+			fmt.Printf("获取资金费用记录失败: %v\n", err)
+			return 1
+		}
+		for _, f := range fundings {
+			fundingPnL += f.Amount
+		}
+	}
+
+	// 资金流水（充值、提现、划转，不计入盈亏）
+	transfers, err := client.FetchTransfers(quote, since)
+	if err != nil {
+		fmt.Printf("获取资金流水失败: %v\n", err)
+		return 1
+	}
+	flows := make(map[string]float64)
+	counts := make(map[string]int)
+	var withdrawalFees float64
+	for _, t := range transfers {
+		flows[t.Type] += t.Amount
+		counts[t.Type]++
+		withdrawalFees += t.Fee
+	}
+
+	fmt.Printf("%s 统计区间: %s 起 (%d 天)\n", tradingPair, since.Format("2006-01-02"), *days)
+	fmt.Printf("已平仓交易: %d 笔, 净盈亏 %.4f %s (已扣手续费 %.4f)\n", closed, tradePnL, quote, tradeFees)
+	if cfg.IsFuturesMode() {
+		fmt.Printf("资金费用: %d 笔, 合计 %.4f %s\n", len(fundings), fundingPnL, quote)
+	}
+	fmt.Printf("净盈亏 (交易 + 资金费用): %.4f %s\n", tradePnL+fundingPnL, quote)
+
+	fmt.Println("资金流水 (不计入盈亏):")
+	for _, flow := range []struct{ kind, label string }{
+		{models.TransferDeposit, "充值"},
+		{models.TransferWithdrawal, "提现"},
+		{models.TransferInternal, "划转"},
+	} {
+		fmt.Printf("  %s: %d 笔, 合计 %.4f %s\n", flow.label, counts[flow.kind], flows[flow.kind], quote)
+	}
+	if withdrawalFees != 0 {
+		fmt.Printf("  提现手续费: %.4f %s\n", withdrawalFees, quote)
+	}
+	return 0
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"dsbot/internal/ai"
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+	"dsbot/internal/strategy"
+)
+
+// promptBacktestHistory 重放时保留的历史信号数（与AI会话上下文一致）
+const promptBacktestHistory = 30
+
+// signalScore 一组信号相对实际行情的统计
+type signalScore struct {
+	counts    map[string]int // 按信号计数
+	evaluated int            // 有后续行情可评估的信号数
+	correct   int            // 判断正确的信号数
+	trades    int            // 可评估的 BUY/SELL 信号数
+	returnSum float64        // BUY/SELL 信号按方向计算的收益率之和（%）
+}
+
+func newSignalScore() *signalScore {
+	return &signalScore{counts: make(map[string]int)}
+}
+
+// add 计入一个信号（ret 为 NaN 表示没有后续行情）
+// BUY 在收益率超过 band 时正确，SELL 在低于 -band 时正确，HOLD 在 ±band 以内时正确
+func (s *signalScore) add(signal string, ret, band float64) {
+	s.counts[signal]++
+	if math.IsNaN(ret) {
+		return
+	}
+	s.evaluated++
+	switch signal {
+	case "BUY":
+		s.trades++
+		s.returnSum += ret
+		if ret > band {
+			s.correct++
+		}
+	case "SELL":
+		s.trades++
+		s.returnSum -= ret
+		if ret < -band {
+			s.correct++
+		}
+	default:
+		if math.Abs(ret) <= band {
+			s.correct++
+		}
+	}
+}
+
+func (s *signalScore) print(name string) {
+	fmt.Printf("%-6s BUY:%d SELL:%d HOLD:%d", name, s.counts["BUY"], s.counts["SELL"], s.counts["HOLD"])
+	if s.evaluated > 0 {
+		fmt.Printf("  命中率: %.1f%% (%d/%d)", float64(s.correct)/float64(s.evaluated)*100, s.correct, s.evaluated)
+	}
+	if s.trades > 0 {
+		fmt.Printf("  BUY/SELL平均方向收益: %+.3f%%", s.returnSum/float64(s.trades))
+	}
+	fmt.Println()
+}
+
+// runPromptBacktestCommand 以新的提示词模板或模型重放已记录的AI分析快照，返回进程退出码
+// 用法: dsbot prompt-backtest [--config config.json] [--template prompt.tmpl] [--provider openai] [--model m] [--base-url url]
+//
+//	[--pair BTC-USDT] [--since 2006-01-02] [--limit 50] [--horizon 4] [--band 0.3] [--out report.csv]
+//
+// 需要在配置中开启 storage.archive_market_data 记录分析快照；每个快照调用一次AI接口
+func runPromptBacktestCommand(args []string) int {
+	fs := flag.NewFlagSet("prompt-backtest", flag.ContinueOnError)
+	configPath := fs.String("config", "config.json", "配置文件")
+	templatePath := fs.String("template", "", "新的提示词模板文件（默认使用配置中的模板或内置提示词）")
+	provider := fs.String("provider", "", "AI服务（deepseek/openai，默认使用配置）")
+	model := fs.String("model", "", "模型名称（默认使用配置）")
+	baseURL := fs.String("base-url", "", "AI接口地址（默认使用配置，兼容 OpenAI 接口）")
+	apiKey := fs.String("api-key", "", "AI接口密钥（默认使用配置）")
+	pair := fs.String("pair", "", "交易对（如 BTC-USDT，默认使用配置的交易对）")
+	sinceStr := fs.String("since", "", "只重放该日期之后的快照（2006-01-02）")
+	limit := fs.Int("limit", 50, "最多重放最近的快照数（0 表示全部）")
+	horizon := fs.Int("horizon", 4, "评估信号的K线根数（信号后第N根K线的收盘价）")
+	band := fs.Float64("band", 0.3, "HOLD 判定区间（收益率绝对值不超过该百分比视为横盘）")
+	out := fs.String("out", "", "逐条结果的 CSV 输出文件（可选）")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *horizon <= 0 {
+		fmt.Println("--horizon 必须大于0")
+		return 2
+	}
+
+	var since time.Time
+	if *sinceStr != "" {
+		var err error
+		if since, err = time.ParseInLocation("2006-01-02", *sinceStr, time.Local); err != nil {
+			fmt.Printf("--since 格式错误: %v\n", err)
+			return 2
+		}
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		return 1
+	}
+	if *pair == "" {
+		*pair = fmt.Sprintf("%s-%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	}
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		return 1
+	}
+
+	apiCfg := cfg.API
+	if *provider != "" {
+		apiCfg.AIProvider = *provider
+		apiCfg.AIConsensus.Enable = false // 指定服务时只回测该服务
+	}
+	baseURLField, apiKeyField := &apiCfg.DeepSeekBaseURL, &apiCfg.DeepSeekAPIKey
+	if apiCfg.AIProvider == ai.ProviderOpenAI {
+		baseURLField, apiKeyField = &apiCfg.OpenAIBaseURL, &apiCfg.OpenAIAPIKey
+	}
+	if *model != "" {
+		apiCfg.AI.Model = *model
+	}
+	if *baseURL != "" {
+		*baseURLField = *baseURL
+	}
+	if *apiKey != "" {
+		*apiKeyField = *apiKey
+	}
+	if *templatePath != "" {
+		apiCfg.PromptTemplate = *templatePath
+		apiCfg.PromptVariants = nil
+	}
+	client, err := ai.NewProvider(&apiCfg)
+	if err != nil {
+		fmt.Printf("创建AI客户端失败: %v\n", err)
+		return 1
+	}
+
+	dataStore, err := store.Open(&cfg.Storage)
+	if err != nil {
+		fmt.Printf("打开持久化存储失败: %v\n", err)
+		return 1
+	}
+	defer dataStore.Close()
+
+	records, closes, err := loadAnalysisRecords(dataStore, *pair, since)
+	if err != nil {
+		fmt.Printf("读取分析快照失败: %v\n", err)
+		return 1
+	}
+	if len(records) == 0 {
+		fmt.Printf("没有 %s 的分析快照（需开启 storage.archive_market_data）\n", *pair)
+		return 1
+	}
+	if *limit > 0 && len(records) > *limit {
+		records = records[len(records)-*limit:]
+	}
+
+	var writer *csv.Writer
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Printf("创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		writer = csv.NewWriter(f)
+		defer writer.Flush()
+		writer.Write([]string{"time", "price", "original", "original_confidence", "new", "new_confidence", "return_pct", "new_reason"})
+	}
+
+	fmt.Printf("重放 %d 个 %s 分析快照（模型 %s，评估周期 %d 根K线）\n", len(records), *pair, client.Model(), *horizon)
+
+	original, replayed := newSignalScore(), newSignalScore()
+	agree := 0
+	transitions := make(map[string]int) // 原信号->新信号
+	var history []models.TradeSignal
+	for i, r := range records {
+		signal, err := client.AnalyzeSnapshot(context.Background(), r.TradingPair, r.MarketData, r.Position, history, r.SymbolA, r.Balance)
+		if err != nil {
+			fmt.Printf("[%d/%d] %s AI分析失败: %v\n", i+1, len(records), r.CreatedAt.Format("2006-01-02 15:04"), err)
+			continue
+		}
+		if !signal.IsFallback {
+			history = append(history, *signal)
+			if len(history) > promptBacktestHistory {
+				history = history[1:]
+			}
+		}
+
+		ret := forwardReturn(r.MarketData, closes, *horizon)
+		original.add(r.Signal.Signal, ret, *band)
+		replayed.add(signal.Signal, ret, *band)
+		if signal.Signal == r.Signal.Signal {
+			agree++
+		}
+		transitions[r.Signal.Signal+"->"+signal.Signal]++
+
+		retText := "-"
+		if !math.IsNaN(ret) {
+			retText = fmt.Sprintf("%+.2f%%", ret)
+		}
+		fmt.Printf("[%d/%d] %s 价格:%.2f 原信号:%s/%s 新信号:%s/%s 后续:%s\n",
+			i+1, len(records), r.CreatedAt.Format("2006-01-02 15:04"), r.MarketData.Price,
+			r.Signal.Signal, r.Signal.Confidence, signal.Signal, signal.Confidence, retText)
+
+		if writer != nil {
+			retField := ""
+			if !math.IsNaN(ret) {
+				retField = strconv.FormatFloat(ret, 'f', 4, 64)
+			}
+			writer.Write([]string{
+				r.CreatedAt.Format(time.RFC3339), strconv.FormatFloat(r.MarketData.Price, 'f', -1, 64),
+				r.Signal.Signal, r.Signal.Confidence, signal.Signal, signal.Confidence, retField, signal.Reason,
+			})
+		}
+	}
+
+	total := original.counts["BUY"] + original.counts["SELL"] + original.counts["HOLD"]
+	fmt.Println()
+	fmt.Println("========== 提示词回测结果 ==========")
+	original.print("原信号")
+	replayed.print("新信号")
+	if total > 0 {
+		fmt.Printf("信号一致率: %.1f%% (%d/%d)\n", float64(agree)/float64(total)*100, agree, total)
+	}
+	for _, from := range []string{"BUY", "SELL", "HOLD"} {
+		for _, to := range []string{"BUY", "SELL", "HOLD"} {
+			if from != to && transitions[from+"->"+to] > 0 {
+				fmt.Printf("  %s -> %s: %d\n", from, to, transitions[from+"->"+to])
+			}
+		}
+	}
+	return 0
+}
+
+// loadAnalysisRecords 读取指定交易对的分析快照（按时间升序），并汇总快照中出现过的K线收盘价
+// 同一根K线以最后写入的快照为准（未完结K线的收盘价会被后续快照覆盖为最终值）
+func loadAnalysisRecords(s store.Store, pair string, since time.Time) ([]strategy.AnalysisRecord, map[int64]float64, error) {
+	var records []strategy.AnalysisRecord
+	closes := make(map[int64]float64)
+	err := s.Scan(strategy.AnalysisCollection, func(raw []byte) error {
+		var r strategy.AnalysisRecord
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil // 跳过损坏的记录
+		}
+		if r.TradingPair != pair || r.MarketData == nil || r.Signal == nil {
+			return nil
+		}
+		for _, k := range r.MarketData.KlineData {
+			closes[k.Timestamp.Unix()] = k.Close
+		}
+		if !r.CreatedAt.Before(since) {
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, closes, err
+}
+
+// forwardReturn 快照价格到其后第 horizon 根K线收盘价的收益率（%），没有后续行情时返回 NaN
+func forwardReturn(md *models.MarketData, closes map[int64]float64, horizon int) float64 {
+	n := len(md.KlineData)
+	if n < 2 || md.Price <= 0 {
+		return math.NaN()
+	}
+	last := md.KlineData[n-1].Timestamp
+	step := last.Sub(md.KlineData[n-2].Timestamp)
+	if step <= 0 {
+		return math.NaN()
+	}
+	exit, ok := closes[last.Add(time.Duration(horizon)*step).Unix()]
+	if !ok || exit <= 0 {
+		return math.NaN()
+	}
+	return (exit - md.Price) / md.Price * 100
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/snapshot"
+)
+
+// runSnapshotCommand 打包脱敏配置和状态文件，返回进程退出码
+// 用法: dsbot snapshot [--config config.json] [--out dsbot-snapshot-20060102-150405.tar.gz]
+func runSnapshotCommand(args []string) int {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	configPath := fs.String("config", "config.json", "配置文件")
+	out := fs.String("out", "", "快照文件（默认 dsbot-snapshot-<时间>.tar.gz）")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	raw, cfg, err := readConfigFile(*configPath)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *out == "" {
+		*out = fmt.Sprintf("dsbot-snapshot-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		fmt.Printf("创建快照文件失败: %v\n", err)
+		return 1
+	}
+
+	manifest, err := snapshot.Create(f, cfg, raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*out)
+		fmt.Printf("创建快照失败: %v\n", err)
+		return 1
+	}
+
+	for _, item := range manifest.Items {
+		fmt.Printf("  %-10s %s\n", item.Name, item.Path)
+	}
+	fmt.Printf("已创建快照: %s (%d 个文件，配置已脱敏)\n", *out, len(manifest.Files))
+	return 0
+}
+
+// runRestoreCommand 从快照恢复状态文件，返回进程退出码
+// 用法: dsbot restore <快照文件> [--config config.json] [--config-out config.restored.json] [--force]
+// 请在机器人停止运行时执行
+func runRestoreCommand(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	configPath := fs.String("config", "config.json", "目标主机的配置文件（决定状态文件恢复路径，不存在时使用快照中的配置）")
+	configOut := fs.String("config-out", "config.restored.json", "脱敏配置的输出文件")
+	force := fs.Bool("force", false, "覆盖已存在的文件")
+
+	var archive string
+	rest := args
+	if len(rest) > 0 && len(rest[0]) > 0 && rest[0][0] != '-' {
+		archive, rest = rest[0], rest[1:]
+	}
+	if err := fs.Parse(rest); err != nil {
+		return 2
+	}
+	if archive == "" && fs.NArg() > 0 {
+		archive = fs.Arg(0)
+	}
+	if archive == "" {
+		fmt.Println("用法: dsbot restore <快照文件> [--config config.json] [--force]")
+		return 2
+	}
+
+	// 目标主机尚无配置时按默认路径恢复
+	cfg := &config.Config{}
+	if _, err := os.Stat(*configPath); err == nil {
+		if _, cfg, err = readConfigFile(*configPath); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	} else {
+		fmt.Printf("未找到 %s，状态文件将恢复到默认路径\n", *configPath)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		fmt.Printf("打开快照失败: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	manifest, err := snapshot.Restore(f, cfg, *configOut, *force)
+	if err != nil {
+		fmt.Printf("恢复失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("已恢复快照 (创建于 %s, %d 个文件)\n", manifest.CreatedAt.Format("2006-01-02 15:04:05"), len(manifest.Files))
+	fmt.Printf("脱敏配置已写入 %s，请填写 API 密钥后使用\n", *configOut)
+	return 0
+}
+
+// readConfigFile 读取配置文件（不做必填项校验，快照/恢复不需要 API 密钥）
+func readConfigFile(configPath string) ([]byte, *config.Config, error) {
+	raw, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	var cfg config.Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return raw, &cfg, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"dsbot/internal/buildinfo"
+	"dsbot/internal/config"
+	"dsbot/internal/controlapi"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/strategy"
+)
+
+// newStartupRecord 生成启动记录（版本、配置哈希、交易所路由和已启用功能）
+func newStartupRecord(cfg *config.Config, runtimes []*pairRuntime) *buildinfo.StartupRecord {
+	record := buildinfo.NewStartupRecord(cfg)
+	record.TestMode = cfg.Trading.TestMode
+	record.TradingMode = string(cfg.GetTradingMode())
+	record.Timeframe = cfg.Trading.Timeframe
+	record.Leverage = cfg.Trading.Leverage
+	record.Amount = cfg.Trading.Amount
+	record.Interval = cfg.Trading.ScheduleIntervalMinutes
+	for _, rt := range runtimes {
+		record.Pairs = append(record.Pairs, buildinfo.PairInfo{
+			TradingPair: rt.pair.TradingPair(),
+			Exchange:    rt.route.exchange.GetExchangeName(),
+			DataSource:  exchange.PrimaryDataSourceOf(rt.route.exchange),
+			Testnet:     rt.route.api.UseTestnet,
+			SubAccount:  rt.route.api.OKXSubAccount,
+		})
+	}
+	record.Features = enabledFeatures(cfg)
+	return record
+}
+
+// enabledFeatures 已启用的功能列表（按配置项名称）
+func enabledFeatures(cfg *config.Config) []string {
+	rm := cfg.Trading.RiskManagement
+	flags := []struct {
+		name    string
+		enabled bool
+	}{
+		{"paper_trading", cfg.Trading.TestMode && cfg.Trading.PaperTrading.Enable},
+		{"stop_loss", rm.EnableStopLoss},
+		{"take_profit", rm.EnableTakeProfit},
+		{"trailing_stop", rm.EnableTrailingStop},
+		{"exchange_trailing_stop", rm.EnableTrailingStop && rm.TrailingStopMode == strategy.TrailingStopModeExchange},
+		{"exchange_bracket", rm.ExchangeBracket},
+		{"volatility_scaling", rm.VolatilityScaling.Enable},
+		{"ai_exit_check", rm.AIExitCheck.Enable},
+		{"margin_top_up", rm.MarginTopUp.Enable},
+		{"max_loss_per_trade", rm.MaxLossPerTrade > 0},
+		{"max_margin_ratio", rm.MaxMarginRatio > 0},
+		{"max_open_positions", rm.MaxOpenPositions > 0},
+		{"max_total_exposure", rm.MaxTotalExposure > 0},
+		{"min_risk_reward", rm.MinRiskReward > 0},
+		{"daily_loss_limit", rm.DailyLossLimit.MaxLoss > 0},
+		{"amount_equity_percent", cfg.Trading.AmountEquityPercent > 0},
+		{"position_sizing", cfg.Trading.PositionSizing.Mode != "" && cfg.Trading.PositionSizing.Mode != "fixed"},
+		{"expectancy_gate", cfg.Trading.ExpectancyGate.Enable},
+		{"loss_cooldown", cfg.Trading.LossCooldown.Enable},
+		{"signal_confirmation", cfg.Trading.SignalConfirmation.Enable},
+		{"volatility_filter", cfg.Trading.VolatilityFilter.Enable},
+		{"trading_sessions", cfg.Trading.TradingSessions.Enable},
+		{"limit_execution", cfg.Trading.Execution.Mode == "limit"},
+		{"cancel_orphan_orders", cfg.Trading.Reconcile.CancelOrphanOrders},
+		{"hedge_mode", cfg.Trading.HedgeMode.Enable},
+		{"funding_arb", cfg.Trading.FundingArb.Enable},
+		{"basis", cfg.Trading.Basis.Enable},
+		{"basis_trade", cfg.Trading.Basis.Trade},
+		{"liquidity_gate", cfg.Trading.LiquidityGate.Enable},
+		{"adaptive_cadence", cfg.Trading.AdaptiveCadence.Enable},
+		{"stop_entry", cfg.Trading.StopEntry.Enable},
+		{"reuse_candle_signal", cfg.Trading.ReuseCandleSignal},
+		{"ai_suggestions", cfg.Trading.AISuggestions.Enable},
+		{"post_mortem", cfg.Trading.Journal.PostMortem},
+		{"multi_timeframe", cfg.Trading.MultiTimeframe.Enable},
+		{"coach", cfg.Trading.Coach.Enable},
+		{"coach_auto_apply", cfg.Trading.Coach.Enable && cfg.Trading.Coach.AutoApply},
+		{"market_data_fallback", cfg.API.MarketDataFallback.Enable},
+		{"ai_fallback_provider", cfg.API.AIFallbackProvider != ""},
+		{"ai_consensus", cfg.API.AIConsensus.Enable},
+		{"prompt_ab_test", len(cfg.API.PromptVariants) > 0},
+		{"news_feed", cfg.DataFeeds.News.Enable},
+		{"fear_greed", cfg.DataFeeds.FearGreed.Enable},
+		{"ohlcv_cache", cfg.API.OHLCVCache.Enable},
+		{"ai_audit", cfg.Storage.AuditAI},
+		{"watchdog", cfg.Watchdog.Enable},
+		{"notification", cfg.Notification.Enable},
+		{"sharding", cfg.Sharding.Enable},
+		{"failover", cfg.Failover.Enable},
+		{"tracing", cfg.Tracing.Enable},
+		{"control_api", cfg.ControlAPI.Listen != ""},
+	}
+
+	var features []string
+	for _, flag := range flags {
+		if flag.enabled {
+			features = append(features, flag.name)
+		}
+	}
+	return features
+}
+
+// printStartupInfo 输出启动记录和运行模式提示
+func printStartupInfo(cfg *config.Config, record *buildinfo.StartupRecord) {
+	record.Log()
+
+	if cfg.Trading.TestMode {
+		logger.Println("⚠️  当前为模拟模式，不会真实下单")
+	} else {
+		logger.Println("🔴 实盘交易模式，请谨慎操作！")
+	}
+	if ac := cfg.Trading.AdaptiveCadence; ac.Enable {
+		minInterval, maxInterval := strategy.CadenceBounds(ac)
+		logger.Printf("自适应执行频率: 已启用 (按波动状态调整，范围 %v ~ %v)", minInterval, maxInterval)
+	}
+	if vf := cfg.Trading.VolatilityFilter; vf.Enable {
+		minATR, maxATR := strategy.VolatilityFilterBounds(vf)
+		logger.Printf("波动率过滤: 已启用 (ATR %.2f%% ~ %.2f%% 之外不开仓)", minATR, maxATR)
+	}
+	if se := cfg.Trading.StopEntry; se.Enable {
+		mode := se.Mode
+		if mode == "" {
+			mode = strategy.StopEntryModeStop
+		}
+		logger.Printf("突破入场: 已启用 (模式 %s，每 %v 检查一次)", mode, strategy.StopEntryCheckInterval(se))
+	}
+}
+
+// statusHandler /status 接口：返回启动记录和运行时长
+func statusHandler(record *buildinfo.StartupRecord) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controlapi.WriteJSON(w, struct {
+			Startup       *buildinfo.StartupRecord `json:"startup"`
+			UptimeSeconds int64                    `json:"uptime_seconds"`
+		}{
+			Startup:       record,
+			UptimeSeconds: int64(time.Since(record.StartedAt).Seconds()),
+		})
+	})
+}
+
+// basisHandler /basis 接口：返回各交易对最近一轮分析的合约相对现货溢价
+func basisHandler(runtimes []*pairRuntime) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		basis := make(map[string]*models.SpotBasis, len(runtimes))
+		for _, rt := range runtimes {
+			basis[rt.pair.TradingPair()] = rt.bot.LatestBasis()
+		}
+		controlapi.WriteJSON(w, basis)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+)
+
+// runSymbolsCommand 列出交易所可交易的交易对，返回进程退出码
+// 用法: dsbot symbols --exchange okx --type swap [--quote USDT] [--base BTC]
+func runSymbolsCommand(args []string) int {
+	fs := flag.NewFlagSet("symbols", flag.ContinueOnError)
+	exchangeType := fs.String("exchange", string(config.ExchangeOKX), "交易所类型")
+	instType := fs.String("type", "swap", "产品类型 (spot/swap)")
+	quote := fs.String("quote", "", "按计价/结算币种过滤 (如 USDT)")
+	base := fs.String("base", "", "按基础币种过滤 (如 BTC)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var tradingMode config.TradingMode
+	switch strings.ToLower(*instType) {
+	case "spot":
+		tradingMode = config.TradingModeSpot
+	case "swap":
+		tradingMode = config.TradingModeFutures
+	default:
+		fmt.Printf("不支持的产品类型: %s (支持: spot, swap)\n", *instType)
+		return 2
+	}
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		return 1
+	}
+
+	client, err := exchange.NewExchange(&config.APIConfig{ExchangeType: *exchangeType}, tradingMode)
+	if err != nil {
+		fmt.Printf("创建交易所客户端失败: %v\n", err)
+		return 1
+	}
+
+	instruments, err := client.ListInstruments(strings.ToLower(*instType))
+	if err != nil {
+		fmt.Printf("获取交易对列表失败: %v\n", err)
+		return 1
+	}
+
+	filtered := instruments[:0]
+	for _, inst := range instruments {
+		if *quote != "" && !strings.EqualFold(inst.QuoteCurrency, *quote) {
+			continue
+		}
+		if *base != "" && !strings.EqualFold(inst.BaseCurrency, *base) {
+			continue
+		}
+		filtered = append(filtered, inst)
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].InstID < filtered[j].InstID })
+
+	fmt.Printf("%-24s %-8s %-8s %14s %14s %14s %14s %8s\n",
+		"交易对", "symbolA", "symbolB", "最小数量", "数量精度", "价格精度", "合约面值", "最大杠杆")
+	for _, inst := range filtered {
+		// 合约的数量单位为张，最小数量换算为基础币种便于估算最小下单金额（amount）
+		minSize := inst.MinSize
+		if inst.ContractValue > 0 {
+			minSize *= inst.ContractValue
+		}
+		leverage := "-"
+		if inst.MaxLeverage > 0 {
+			leverage = fmt.Sprintf("%gx", inst.MaxLeverage)
+		}
+		fmt.Printf("%-24s %-8s %-8s %14g %14g %14g %14g %8s\n",
+			inst.InstID, inst.BaseCurrency, inst.QuoteCurrency,
+			minSize, inst.LotSize, inst.TickSize, inst.ContractValue, leverage)
+	}
+	fmt.Printf("共 %d 个交易对\n", len(filtered))
+
+	return 0
+}
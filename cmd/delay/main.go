@@ -0,0 +1,159 @@
+// delay 执行延迟分析工具 - 读取交易日志中记录的信号/决策/成交时间，
+// 量化K线收盘后的调度对齐延迟与AI响应延迟相对于收盘时理想执行的滑点成本，用于调整执行节奏
+//
+// 用法: go run ./cmd/delay [-journal data/journal.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+)
+
+// delayBucket 按总延迟分组
+type delayBucket struct {
+	label string
+	max   time.Duration
+}
+
+var delayBuckets = []delayBucket{
+	{"< 30s", 30 * time.Second},
+	{"30s-1m", time.Minute},
+	{"1m-2m", 2 * time.Minute},
+	{"2m-5m", 5 * time.Minute},
+	{">= 5m", 0},
+}
+
+// sample 单笔开仓的延迟与滑点
+type sample struct {
+	decisionDelay  time.Duration // K线收盘 -> AI决策
+	executionDelay time.Duration // AI决策 -> 成交
+	totalDelay     time.Duration // K线收盘 -> 成交
+	costBps        float64       // 相对收盘价的不利滑点（基点，正数表示成本）
+	entry          journal.Entry
+}
+
+func main() {
+	file := flag.String("journal", journal.DefaultFile, "交易日志文件")
+	flag.Parse()
+
+	if err := logger.Init("", "WARN", "WARN"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	j, err := journal.NewJournal(*file)
+	if err != nil {
+		fmt.Printf("读取交易日志失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	samples := collectSamples(j.Entries())
+	if len(samples) == 0 {
+		fmt.Println("交易日志中没有包含信号时间和成交时间的开仓记录")
+		return
+	}
+
+	printSummary(samples)
+	printBuckets(samples)
+}
+
+// collectSamples 提取具备完整时间戳的开仓记录
+func collectSamples(entries []journal.Entry) []sample {
+	var samples []sample
+	for _, e := range entries {
+		if e.SignalTime.IsZero() || e.SignalPrice <= 0 || e.EntryPrice <= 0 {
+			continue
+		}
+
+		cost := (e.EntryPrice - e.SignalPrice) / e.SignalPrice * 10000
+		if e.Side == "short" {
+			cost = -cost
+		}
+
+		s := sample{
+			totalDelay: e.OpenedAt.Sub(e.SignalTime),
+			costBps:    cost,
+			entry:      e,
+		}
+		if !e.DecidedAt.IsZero() {
+			s.decisionDelay = e.DecidedAt.Sub(e.SignalTime)
+			s.executionDelay = e.OpenedAt.Sub(e.DecidedAt)
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// printSummary 输出总体统计
+func printSummary(samples []sample) {
+	var decision, execution, total time.Duration
+	var costSum, idealReturnSum, actualReturnSum float64
+	closed := 0
+	costs := make([]float64, 0, len(samples))
+
+	for _, s := range samples {
+		decision += s.decisionDelay
+		execution += s.executionDelay
+		total += s.totalDelay
+		costSum += s.costBps
+		costs = append(costs, s.costBps)
+
+		if s.entry.Closed {
+			closed++
+			actualReturnSum += s.entry.ReturnPct
+			idealReturnSum += s.entry.ReturnPct + s.costBps/100
+		}
+	}
+
+	n := time.Duration(len(samples))
+	sort.Float64s(costs)
+
+	fmt.Println("============================================================")
+	fmt.Printf("执行延迟分析 - 样本: %d 笔开仓 (已平仓 %d 笔)\n", len(samples), closed)
+	fmt.Println("============================================================")
+	fmt.Printf("平均决策延迟(收盘->AI决策): %s\n", (decision / n).Round(time.Second))
+	fmt.Printf("平均执行延迟(AI决策->成交): %s\n", (execution / n).Round(time.Second))
+	fmt.Printf("平均总延迟(收盘->成交):     %s\n", (total / n).Round(time.Second))
+	fmt.Printf("平均滑点成本: %+.2f bps, 中位数: %+.2f bps\n", costSum/float64(len(samples)), costs[len(costs)/2])
+	if closed > 0 {
+		fmt.Printf("已平仓平均收益率: 实际 %+.3f%%, 收盘理想执行 %+.3f%%, 延迟成本 %.3f%%/笔\n",
+			actualReturnSum/float64(closed), idealReturnSum/float64(closed),
+			(idealReturnSum-actualReturnSum)/float64(closed))
+	}
+}
+
+// printBuckets 按总延迟分组输出滑点成本
+func printBuckets(samples []sample) {
+	type bucketStats struct {
+		count   int
+		costSum float64
+	}
+	stats := make([]bucketStats, len(delayBuckets))
+
+	for _, s := range samples {
+		for i, b := range delayBuckets {
+			if b.max == 0 || s.totalDelay < b.max {
+				stats[i].count++
+				stats[i].costSum += s.costBps
+				break
+			}
+		}
+	}
+
+	fmt.Println("------------------------------------------------------------")
+	fmt.Printf("%-10s %8s %16s\n", "总延迟", "笔数", "平均成本(bps)")
+	for i, b := range delayBuckets {
+		if stats[i].count == 0 {
+			fmt.Printf("%-10s %8d %16s\n", b.label, 0, "-")
+			continue
+		}
+		fmt.Printf("%-10s %8d %+16.2f\n", b.label, stats[i].count, stats[i].costSum/float64(stats[i].count))
+	}
+	fmt.Println("============================================================")
+}
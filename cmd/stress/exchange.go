@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/exchange"
+	"dsbot/internal/models"
+)
+
+// placedOrder 记录的下单请求
+type placedOrder struct {
+	side       string
+	amount     float64
+	reduceOnly bool
+	posSide    string
+}
+
+// scriptedExchange 可编排的模拟交易所，用于压力测试场景
+type scriptedExchange struct {
+	mu        sync.Mutex
+	price     float64
+	position  *models.Position
+	balance   float64
+	fillRatio float64 // 每次下单的成交比例（1表示完全成交）
+	failErr   error   // 非nil时所有请求都返回该错误（模拟5xx风暴）
+	orders    []placedOrder
+	nextID    int
+}
+
+func newScriptedExchange(price float64) *scriptedExchange {
+	return &scriptedExchange{
+		price:     price,
+		balance:   10000,
+		fillRatio: 1,
+	}
+}
+
+// setPrice 设置当前价格（可模拟跳空）
+func (e *scriptedExchange) setPrice(price float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.price = price
+}
+
+// setFailure 设置请求失败（nil表示恢复）
+func (e *scriptedExchange) setFailure(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failErr = err
+}
+
+// setFillRatio 设置成交比例（模拟部分成交）
+func (e *scriptedExchange) setFillRatio(ratio float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fillRatio = ratio
+}
+
+// openPosition 直接设置持仓
+func (e *scriptedExchange) openPosition(side string, size, entryPrice float64, leverage int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.position = &models.Position{
+		Side:       side,
+		Size:       size,
+		EntryPrice: entryPrice,
+		Leverage:   leverage,
+		Symbol:     e.ParseSymbols("BTC", "USDT"),
+	}
+}
+
+// positionSize 当前持仓数量（无持仓为0）
+func (e *scriptedExchange) positionSize() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.position == nil {
+		return 0
+	}
+	return e.position.Size
+}
+
+// orderCount 已成功提交的订单数量
+func (e *scriptedExchange) orderCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.orders)
+}
+
+func (e *scriptedExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return nil, e.failErr
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	ohlcvList := make([]models.OHLCV, 0, limit)
+	for i := limit - 1; i >= 0; i-- {
+		ohlcvList = append(ohlcvList, models.OHLCV{
+			Timestamp: now.Add(-time.Duration(i) * 15 * time.Minute),
+			Open:      e.price,
+			High:      e.price * 1.001,
+			Low:       e.price * 0.999,
+			Close:     e.price,
+			Volume:    100,
+		})
+	}
+	return ohlcvList, nil
+}
+
+func (e *scriptedExchange) FetchTicker(symbol string) (*models.Ticker, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return nil, e.failErr
+	}
+	return &models.Ticker{Symbol: symbol, Last: e.price, Bid: e.price, Ask: e.price, Mark: e.price, Index: e.price}, nil
+}
+
+func (e *scriptedExchange) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return nil, e.failErr
+	}
+	return &models.OrderBook{
+		Symbol: symbol,
+		Bids:   []models.OrderBookLevel{{Price: e.price, Size: 100}},
+		Asks:   []models.OrderBookLevel{{Price: e.price, Size: 100}},
+	}, nil
+}
+
+func (e *scriptedExchange) FetchPosition(symbol string) (*models.Position, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return nil, e.failErr
+	}
+	if e.position == nil {
+		return nil, nil
+	}
+	pos := *e.position
+	return &pos, nil
+}
+
+func (e *scriptedExchange) FetchPositions(symbol string) ([]models.Position, error) {
+	pos, err := e.FetchPosition(symbol)
+	if err != nil || pos == nil {
+		return nil, err
+	}
+	return []models.Position{*pos}, nil
+}
+
+func (e *scriptedExchange) FetchBalance(currency string) (float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return 0, e.failErr
+	}
+	return e.balance, nil
+}
+
+func (e *scriptedExchange) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	balance, err := e.FetchBalance(currency)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AccountSummary{Currency: currency, TotalEquity: balance, AvailableMargin: balance}, nil
+}
+
+func (e *scriptedExchange) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	return nil, nil
+}
+
+func (e *scriptedExchange) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	return nil, nil
+}
+
+func (e *scriptedExchange) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	return nil, nil
+}
+
+func (e *scriptedExchange) Transfer(currency string, amount float64, from, to string) error {
+	return fmt.Errorf("压测交易所不支持资金划转")
+}
+
+func (e *scriptedExchange) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return "", e.failErr
+	}
+
+	reduceOnly, _ := params["reduceOnly"].(bool)
+	posSide, _ := params["posSide"].(string)
+	filled := amount * e.fillRatio
+
+	if reduceOnly {
+		if e.position == nil {
+			return "", fmt.Errorf("无持仓可平")
+		}
+		e.position.Size -= filled
+		if e.position.Size <= 1e-12 {
+			e.position = nil
+		}
+	} else if e.position == nil {
+		e.position = &models.Position{Side: posSide, Size: filled, EntryPrice: e.price, Leverage: 10, Symbol: symbol}
+	} else {
+		e.position.Size += filled
+	}
+
+	e.nextID++
+	e.orders = append(e.orders, placedOrder{side: side, amount: amount, reduceOnly: reduceOnly, posSide: posSide})
+	return fmt.Sprintf("mock-%d", e.nextID), nil
+}
+
+func (e *scriptedExchange) PlaceOrders(requests []exchange.OrderRequest) ([]exchange.OrderResult, error) {
+	results := make([]exchange.OrderResult, len(requests))
+	for i, req := range requests {
+		results[i].OrderID, results[i].Err = e.PlaceOrder(req.Symbol, req.Side, req.Amount, req.Params)
+	}
+	return results, nil
+}
+
+func (e *scriptedExchange) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failErr != nil {
+		return nil, e.failErr
+	}
+	return &models.Order{OrderID: orderID, Symbol: symbol, State: models.OrderStateFilled}, nil
+}
+
+func (e *scriptedExchange) CancelOrder(symbol, orderID string) error {
+	return nil
+}
+
+func (e *scriptedExchange) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	return nil, nil
+}
+
+func (e *scriptedExchange) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	return nil, nil
+}
+
+func (e *scriptedExchange) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	return &models.FeeRate{Symbol: symbol, Maker: 0.0002, Taker: 0.0005}, nil
+}
+
+func (e *scriptedExchange) SetLeverage(symbol string, leverage int) error {
+	return nil
+}
+
+func (e *scriptedExchange) GetInstrumentInfo(symbol string) (*exchange.InstrumentInfo, error) {
+	return &exchange.InstrumentInfo{InstID: "BTC-USDT-SWAP", ContractValue: 1, LotSize: 0.0001, MinSize: 0.0001}, nil
+}
+
+func (e *scriptedExchange) CancelAlgoOrder(symbol, clientID string) error {
+	return nil
+}
+
+func (e *scriptedExchange) ListInstruments(instType string) ([]exchange.InstrumentInfo, error) {
+	info, _ := e.GetInstrumentInfo("")
+	return []exchange.InstrumentInfo{*info}, nil
+}
+
+func (e *scriptedExchange) ParseSymbols(symbolA, symbolB string) string {
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+func (e *scriptedExchange) GetExchangeName() string {
+	return "scripted"
+}
+
+func (e *scriptedExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{Futures: true}
+}
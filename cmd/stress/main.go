@@ -0,0 +1,174 @@
+// stress 压力测试工具 - 使用模拟交易所和模拟AI服务驱动真实的策略/风控组件，
+// 编排跳空穿越止损、交易所5xx风暴、AI超时、部分成交等异常场景，并断言机器人最终处于安全状态
+//
+// 用法: go run ./cmd/stress
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"dsbot/internal/ai"
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/strategy"
+)
+
+// scenario 压力测试场景
+type scenario struct {
+	name string
+	run  func(h *harness) error
+}
+
+// harness 单个场景的运行环境
+type harness struct {
+	cfg      *config.Config
+	exchange *scriptedExchange
+	aiServer *fakeAIServer
+	bot      *strategy.TradingBot
+}
+
+func main() {
+	if err := logger.Init("", "WARN", "DEBUG"); err != nil {
+		fmt.Printf("初始化日志系统失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	scenarios := []scenario{
+		{"跳空穿越止损", scenarioGapThroughStop},
+		{"交易所5xx风暴", scenarioServerErrorStorm},
+		{"AI请求超时", scenarioAITimeout},
+		{"平仓部分成交", scenarioPartialFill},
+	}
+
+	failed := 0
+	for _, sc := range scenarios {
+		h := newHarness()
+		err := sc.run(h)
+		h.aiServer.Close()
+
+		if err != nil {
+			failed++
+			fmt.Printf("❌ %s: %v\n", sc.name, err)
+		} else {
+			fmt.Printf("✅ %s\n", sc.name)
+		}
+	}
+
+	fmt.Printf("\n共 %d 个场景, 失败 %d 个\n", len(scenarios), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// newHarness 创建场景运行环境
+func newHarness() *harness {
+	aiServer := newFakeAIServer()
+
+	cfg := &config.Config{
+		Trading: config.TradingConfig{
+			SymbolA:                 "BTC",
+			SymbolB:                 "USDT",
+			Amount:                  100,
+			Leverage:                10,
+			Timeframe:               "15m",
+			DataPoints:              60,
+			ScheduleIntervalMinutes: 15,
+			TradingMode:             string(config.TradingModeFutures),
+			RiskManagement: config.RiskManagementConfig{
+				EnableStopLoss:       true,
+				EnableTakeProfit:     true,
+				StopLossPercent:      1.5,
+				TakeProfitPercent:    3.0,
+				CheckIntervalSeconds: 1,
+			},
+		},
+		API: config.APIConfig{
+			DeepSeekAPIKey:  "stress",
+			DeepSeekBaseURL: aiServer.URL,
+		},
+	}
+
+	exch := newScriptedExchange(100)
+	aiClient, err := ai.NewProvider(&cfg.API)
+	if err != nil {
+		panic(err)
+	}
+
+	return &harness{
+		cfg:      cfg,
+		exchange: exch,
+		aiServer: aiServer,
+		bot:      strategy.NewTradingBot(cfg, exch, aiClient),
+	}
+}
+
+// syncRiskManager 将交易所持仓同步给风险管理器（模拟一次交易周期的持仓同步）
+func (h *harness) syncRiskManager() error {
+	pos, err := h.exchange.FetchPosition(h.exchange.ParseSymbols("BTC", "USDT"))
+	if err != nil {
+		return err
+	}
+	h.bot.GetRiskManager().UpdatePosition(pos)
+	return nil
+}
+
+// expectFlat 断言交易所无持仓
+func (h *harness) expectFlat() error {
+	if size := h.exchange.positionSize(); size > 0 {
+		return fmt.Errorf("期望无持仓，实际仍持有 %.8f", size)
+	}
+	return nil
+}
+
+// fakeAIServer 模拟 DeepSeek 接口
+type fakeAIServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	fail bool // true 时直接断开连接（模拟超时/网络故障）
+}
+
+func newFakeAIServer() *fakeAIServer {
+	s := &fakeAIServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// setFail 设置AI服务是否失败
+func (s *fakeAIServer) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+func (s *fakeAIServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	fail := s.fail
+	s.mu.Unlock()
+
+	if fail {
+		if hj, ok := w.(http.Hijacker); ok {
+			conn, _, err := hj.Hijack()
+			if err == nil {
+				conn.Close()
+				return
+			}
+		}
+		w.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+
+	content := `{"signal": "HOLD", "reason": "压力测试", "confidence": "MEDIUM"}`
+	response := map[string]interface{}{
+		"choices": []map[string]interface{}{
+			{"message": map[string]string{"content": content}},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
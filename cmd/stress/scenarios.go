@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// scenarioGapThroughStop 价格跳空穿越止损价，风控应立即平仓
+func scenarioGapThroughStop(h *harness) error {
+	h.exchange.openPosition("long", 1, 100, 10)
+	if err := h.syncRiskManager(); err != nil {
+		return err
+	}
+
+	// 止损1.5%（98.5），价格直接跳空到90
+	h.exchange.setPrice(90)
+	h.bot.GetRiskManager().CheckNow()
+
+	return h.expectFlat()
+}
+
+// scenarioServerErrorStorm 交易所持续返回5xx：交易周期应报错且不下单；
+// 风暴期间触发止损的平仓失败后，恢复后应能再次平仓
+func scenarioServerErrorStorm(h *harness) error {
+	h.exchange.openPosition("short", 1, 100, 10)
+	if err := h.syncRiskManager(); err != nil {
+		return err
+	}
+
+	h.exchange.setFailure(fmt.Errorf("HTTP 503: Service Unavailable"))
+
+	if err := h.bot.Run(context.Background()); err == nil {
+		return fmt.Errorf("交易所不可用时交易周期应返回错误")
+	}
+	if n := h.exchange.orderCount(); n != 0 {
+		return fmt.Errorf("交易所不可用时不应下单，实际下单 %d 次", n)
+	}
+
+	// 风暴期间价格穿越止损，风控检查应安全失败
+	h.exchange.setPrice(110)
+	h.bot.GetRiskManager().CheckNow()
+	if h.exchange.positionSize() == 0 {
+		return fmt.Errorf("交易所不可用时持仓不应变化")
+	}
+
+	// 交易所恢复后，风控应继续监控并平仓
+	h.exchange.setFailure(nil)
+	h.bot.GetRiskManager().CheckNow()
+
+	return h.expectFlat()
+}
+
+// scenarioAITimeout AI请求超时：交易周期应报错且不下单，已有持仓保持不变
+func scenarioAITimeout(h *harness) error {
+	h.exchange.openPosition("long", 1, 100, 10)
+	h.aiServer.setFail(true)
+
+	if err := h.bot.Run(context.Background()); err == nil {
+		return fmt.Errorf("AI不可用时交易周期应返回错误")
+	}
+	if n := h.exchange.orderCount(); n != 0 {
+		return fmt.Errorf("AI不可用时不应下单，实际下单 %d 次", n)
+	}
+	if size := h.exchange.positionSize(); size != 1 {
+		return fmt.Errorf("AI不可用时持仓不应变化，实际持仓 %.8f", size)
+	}
+
+	// 交易周期虽失败，风控仍应接管已有持仓
+	h.exchange.setPrice(90)
+	h.bot.GetRiskManager().CheckNow()
+
+	return h.expectFlat()
+}
+
+// scenarioPartialFill 平仓单部分成交：下一个交易周期同步持仓后，风控应平掉剩余仓位
+func scenarioPartialFill(h *harness) error {
+	h.exchange.openPosition("long", 1, 100, 10)
+	if err := h.syncRiskManager(); err != nil {
+		return err
+	}
+
+	h.exchange.setFillRatio(0.4)
+	h.exchange.setPrice(90)
+	h.bot.GetRiskManager().CheckNow()
+
+	if size := h.exchange.positionSize(); size <= 0 || size >= 1 {
+		return fmt.Errorf("期望部分平仓，实际持仓 %.8f", size)
+	}
+
+	// 恢复完全成交，下一个交易周期同步剩余持仓
+	h.exchange.setFillRatio(1)
+	if err := h.bot.Run(context.Background()); err != nil {
+		return fmt.Errorf("交易周期失败: %w", err)
+	}
+	h.bot.GetRiskManager().CheckNow()
+
+	return h.expectFlat()
+}
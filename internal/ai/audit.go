@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+)
+
+// AuditCollectionPrefix AI审计记录在持久化存储中的集合名前缀（后接交易对，如 ai_audit_BTC-USDT）
+const AuditCollectionPrefix = "ai_audit_"
+
+// 审计记录类型
+const (
+	AuditKindAnalysis    = "analysis"     // 市场分析
+	AuditKindExitOpinion = "exit_opinion" // 提前离场询问
+	AuditKindPostMortem  = "post_mortem"  // 交易复盘
+	AuditKindTuning      = "tuning"       // 参数调优建议
+)
+
+// AuditRecord 一次AI请求的完整记录（提示词、原始回复、解析结果或错误），独立于运行日志，便于重放和排查信号决策
+type AuditRecord struct {
+	TradingPair  string      `json:"trading_pair"`
+	Kind         string      `json:"kind"`
+	Provider     string      `json:"provider"` // 服务和模型
+	SystemPrompt string      `json:"system_prompt"`
+	Prompt       string      `json:"prompt"`
+	Response     string      `json:"response,omitempty"`  // 原始回复（请求失败时为空）
+	Reasoning    string      `json:"reasoning,omitempty"` // 推理模型的推理过程
+	Parsed       interface{} `json:"parsed,omitempty"`    // 解析结果（信号、离场意见或复盘）
+	Error        string      `json:"error,omitempty"`     // 请求或解析错误
+	IsFallback   bool        `json:"is_fallback,omitempty"`
+	DurationMs   int64       `json:"duration_ms"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+var (
+	auditMu    sync.RWMutex
+	auditStore store.Store
+)
+
+// SetAuditStore 设置AI审计记录的持久化存储（nil 表示不记录）
+func SetAuditStore(s store.Store) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	auditStore = s
+}
+
+// AuditCollection 交易对的审计记录集合名
+func AuditCollection(tradingPair string) string {
+	return AuditCollectionPrefix + strings.ReplaceAll(tradingPair, "/", "-")
+}
+
+// audit 写入一条审计记录（未设置存储时不做任何事，写入失败只记录日志）
+func (c *DeepSeekClient) audit(kind, tradingPair string, messages []Message, response, reasoning string, parsed interface{}, err error, start time.Time) {
+	auditMu.RLock()
+	s := auditStore
+	auditMu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	record := AuditRecord{
+		TradingPair: tradingPair,
+		Kind:        kind,
+		Provider:    c.providerName(),
+		Response:    response,
+		Reasoning:   reasoning,
+		Parsed:      parsed,
+		DurationMs:  time.Since(start).Milliseconds(),
+		CreatedAt:   start,
+	}
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			record.SystemPrompt = m.Content
+		case "user":
+			record.Prompt = m.Content
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if signal, ok := parsed.(*models.TradeSignal); ok && signal.IsFallback {
+		record.IsFallback = true
+	}
+
+	data, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		logger.Warnf("[AI审计] 序列化失败: %v", marshalErr)
+		return
+	}
+	if writeErr := s.Append(AuditCollection(tradingPair), data); writeErr != nil {
+		logger.Warnf("[AI审计] 写入存储失败: %v", writeErr)
+	}
+}
@@ -0,0 +1,290 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 多模型共识默认配置
+const (
+	DefaultConsensusMinAgree      = 2
+	DefaultConsensusMinConfidence = "MEDIUM"
+)
+
+// ConsensusProvider 多模型共识 - 并行询问多个AI服务/模型，按投票规则生成最终信号
+// 同一方向（BUY/SELL）信心不低于最低等级的票数达到 minAgree 且多于反方向时采用该方向，否则观望；
+// 各成员的回复均记录日志，会话上下文由各成员分别维护，GetSessionInfo 返回第一个成员的信息
+type ConsensusProvider struct {
+	members       []Provider
+	minAgree      int
+	minConfidence string
+}
+
+// NewConsensusProvider 创建多模型共识客户端
+func NewConsensusProvider(members []Provider, minAgree int, minConfidence string) *ConsensusProvider {
+	if minAgree <= 0 {
+		minAgree = DefaultConsensusMinAgree
+	}
+	if minConfidence == "" {
+		minConfidence = DefaultConsensusMinConfidence
+	}
+	return &ConsensusProvider{members: members, minAgree: minAgree, minConfidence: minConfidence}
+}
+
+// newConsensusProvider 按 ai_consensus 配置创建各成员客户端
+func newConsensusProvider(cfg *config.APIConfig) (Provider, error) {
+	consensus := cfg.AIConsensus
+	members := make([]Provider, 0, len(consensus.Members))
+	for i, member := range consensus.Members {
+		provider, err := newProvider(withModel(cfg, member.Provider, member.Model), member.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("创建共识成员 %d 失败: %w", i+1, err)
+		}
+		members = append(members, provider)
+	}
+	return NewConsensusProvider(members, consensus.MinAgree, consensus.MinConfidence), nil
+}
+
+// Model 参与共识的全部模型
+func (p *ConsensusProvider) Model() string {
+	names := make([]string, len(p.members))
+	for i, member := range p.members {
+		names[i] = member.Model()
+	}
+	return "consensus(" + strings.Join(names, ",") + ")"
+}
+
+// GetSessionInfo 获取第一个成员的会话上下文
+func (p *ConsensusProvider) GetSessionInfo(tradingPair string) *models.SessionContext {
+	return p.members[0].GetSessionInfo(tradingPair)
+}
+
+// AnalyzeMarket 并行询问全部成员并投票生成交易信号
+func (p *ConsensusProvider) AnalyzeMarket(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	return p.vote(tradingPair, func(member Provider) (*models.TradeSignal, error) {
+		return member.AnalyzeMarket(ctx, tradingPair, marketData, currentPosition, symbolA, usdtBalance)
+	})
+}
+
+// AnalyzeSnapshot 并行询问全部成员分析市场数据快照并投票生成交易信号
+func (p *ConsensusProvider) AnalyzeSnapshot(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	return p.vote(tradingPair, func(member Provider) (*models.TradeSignal, error) {
+		return member.AnalyzeSnapshot(ctx, tradingPair, marketData, currentPosition, signalHistory, symbolA, usdtBalance)
+	})
+}
+
+// AskExitOpinion 并行询问全部成员，信心达标的 EXIT 票数达到 minAgree 时提前离场，否则继续持有
+func (p *ConsensusProvider) AskExitOpinion(ctx context.Context, tradingPair string, pos *models.Position, currentPrice, stopPrice float64) (*models.ExitOpinion, error) {
+	opinions := make([]*models.ExitOpinion, len(p.members))
+	errs := make([]error, len(p.members))
+	var wg sync.WaitGroup
+	for i, member := range p.members {
+		wg.Add(1)
+		go func(i int, member Provider) {
+			defer wg.Done()
+			opinions[i], errs[i] = member.AskExitOpinion(ctx, tradingPair, pos, currentPrice, stopPrice)
+		}(i, member)
+	}
+	wg.Wait()
+
+	var answered int
+	var exits []string
+	var lastErr error
+	for i, member := range p.members {
+		if errs[i] != nil {
+			logger.Warnf("[%s] [共识] %s 离场询问失败: %v", tradingPair, member.Model(), errs[i])
+			lastErr = errs[i]
+			continue
+		}
+		answered++
+		opinion := opinions[i]
+		logger.Printf("[%s] [共识] %s 离场意见: %s (%s) %s", tradingPair, member.Model(), opinion.Action, opinion.Confidence, opinion.Reason)
+		if opinion.Action == "EXIT" && p.counts(opinion.Confidence) {
+			exits = append(exits, member.Model()+": "+opinion.Reason)
+		}
+	}
+	if answered == 0 {
+		return nil, fmt.Errorf("共识成员离场询问全部失败: %w", lastErr)
+	}
+
+	if len(exits) >= p.minAgree {
+		return &models.ExitOpinion{
+			Action:     "EXIT",
+			Reason:     fmt.Sprintf("共识 EXIT %d/%d: %s", len(exits), len(p.members), strings.Join(exits, "; ")),
+			Confidence: p.minConfidence,
+		}, nil
+	}
+	return &models.ExitOpinion{
+		Action:     "HOLD",
+		Reason:     fmt.Sprintf("EXIT %d票，未达到 %d票", len(exits), p.minAgree),
+		Confidence: "LOW",
+	}, nil
+}
+
+// WritePostMortem 依次请求成员撰写交易复盘，使用第一份成功的复盘（复盘无需投票）
+func (p *ConsensusProvider) WritePostMortem(ctx context.Context, review *models.TradeReview) (*models.PostMortem, error) {
+	var lastErr error
+	for _, member := range p.members {
+		postMortem, err := member.WritePostMortem(ctx, review)
+		if err == nil {
+			return postMortem, nil
+		}
+		logger.Warnf("[%s] [共识] %s 撰写复盘失败: %v", review.TradingPair, member.Model(), err)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("共识成员撰写复盘全部失败: %w", lastErr)
+}
+
+// SuggestTuning 依次请求成员建议参数调整，使用第一份成功的建议（建议只记录或在限定范围内应用，无需投票）
+func (p *ConsensusProvider) SuggestTuning(ctx context.Context, review *models.TuningReview) (*models.TuningAdvice, error) {
+	var lastErr error
+	for _, member := range p.members {
+		advice, err := member.SuggestTuning(ctx, review)
+		if err == nil {
+			return advice, nil
+		}
+		logger.Warnf("[%s] [共识] %s 参数调优建议失败: %v", review.TradingPair, member.Model(), err)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("共识成员参数调优建议全部失败: %w", lastErr)
+}
+
+// vote 并行询问全部成员并统计票数
+func (p *ConsensusProvider) vote(tradingPair string, ask func(member Provider) (*models.TradeSignal, error)) (*models.TradeSignal, error) {
+	signals := make([]*models.TradeSignal, len(p.members))
+	errs := make([]error, len(p.members))
+	var wg sync.WaitGroup
+	for i, member := range p.members {
+		wg.Add(1)
+		go func(i int, member Provider) {
+			defer wg.Done()
+			signals[i], errs[i] = ask(member)
+		}(i, member)
+	}
+	wg.Wait()
+
+	var answered int
+	var fallback *models.TradeSignal
+	var lastErr error
+	votes := make(map[string][]*models.TradeSignal)
+	for i, member := range p.members {
+		signal := signals[i]
+		switch {
+		case errs[i] != nil:
+			logger.Warnf("[%s] [共识] %s 请求失败，不计票: %v", tradingPair, member.Model(), errs[i])
+			lastErr = errs[i]
+		case signal.IsFallback:
+			logger.Warnf("[%s] [共识] %s 回复无法解析，不计票", tradingPair, member.Model())
+			fallback = signal
+		default:
+			answered++
+			logger.Printf("[%s] [共识] %s: %s (%s) %s", tradingPair, signal.Provider, signal.Signal, signal.Confidence, signal.Reason)
+			if signal.Signal != "HOLD" && p.counts(signal.Confidence) {
+				votes[signal.Signal] = append(votes[signal.Signal], signal)
+			}
+		}
+	}
+	if answered == 0 {
+		if fallback != nil {
+			fallback.Provider = p.Model()
+			return fallback, nil
+		}
+		return nil, fmt.Errorf("共识成员请求全部失败: %w", lastErr)
+	}
+
+	buys, sells := votes["BUY"], votes["SELL"]
+	var direction string
+	var agreed []*models.TradeSignal
+	switch {
+	case len(buys) >= p.minAgree && len(buys) > len(sells):
+		direction, agreed = "BUY", buys
+	case len(sells) >= p.minAgree && len(sells) > len(buys):
+		direction, agreed = "SELL", sells
+	}
+
+	result := &models.TradeSignal{
+		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
+		TradingPair: tradingPair,
+		Provider:    p.Model(),
+	}
+	if agreed == nil {
+		result.Signal = "HOLD"
+		result.Confidence = "MEDIUM"
+		result.Reason = fmt.Sprintf("模型未达成一致 (BUY %d票, SELL %d票, 需要 %d票)", len(buys), len(sells), p.minAgree)
+	} else {
+		mergeAgreed(result, direction, agreed, len(p.members))
+	}
+	logger.Printf("[%s] [共识] 最终信号: %s (%s) %s", tradingPair, result.Signal, result.Confidence, result.Reason)
+	return result, nil
+}
+
+// counts 信心等级是否达到计票要求
+func (p *ConsensusProvider) counts(confidence string) bool {
+	return confidenceRank(confidence) >= confidenceRank(p.minConfidence)
+}
+
+// mergeAgreed 合并同一方向的信号：信心取最低等级，信心分数和建议止盈止损、仓位取已给出值的平均
+func mergeAgreed(result *models.TradeSignal, direction string, agreed []*models.TradeSignal, total int) {
+	result.Signal = direction
+	result.Confidence = agreed[0].Confidence
+	reasons := make([]string, len(agreed))
+	var scores, stopLosses, takeProfits, fractions []float64
+	for i, signal := range agreed {
+		if confidenceRank(signal.Confidence) < confidenceRank(result.Confidence) {
+			result.Confidence = signal.Confidence
+		}
+		reasons[i] = signal.Provider + ": " + signal.Reason
+		scores = append(scores, float64(signal.ConfidenceScore))
+		stopLosses = append(stopLosses, signal.StopLoss)
+		takeProfits = append(takeProfits, signal.TakeProfit)
+		fractions = append(fractions, signal.SizeFraction)
+	}
+	result.Reason = fmt.Sprintf("共识 %s %d/%d: %s", direction, len(agreed), total, strings.Join(reasons, "; "))
+	result.ConfidenceScore = int(averagePositive(scores) + 0.5)
+	result.StopLoss = averagePositive(stopLosses)
+	result.TakeProfit = averagePositive(takeProfits)
+	result.SizeFraction = averagePositive(fractions)
+}
+
+// confidenceRank 信心等级序号（HIGH > MEDIUM > LOW）
+func confidenceRank(confidence string) int {
+	switch confidence {
+	case "HIGH":
+		return 3
+	case "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// averagePositive 大于0的值的平均值（均未给出时返回0）
+func averagePositive(values []float64) float64 {
+	var sum float64
+	var n int
+	for _, v := range values {
+		if v > 0 {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
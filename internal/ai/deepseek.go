@@ -1,10 +1,15 @@
 package ai
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"math/rand"
+	"os"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"dsbot/internal/config"
@@ -13,36 +18,238 @@ import (
 	"dsbot/internal/nets"
 )
 
-// DeepSeekClient DeepSeek客户端
+// DefaultModel 默认模型
+const DefaultModel = "deepseek-chat"
+
+// DefaultTemperature 默认采样温度（偏低以保证信号稳定）
+const DefaultTemperature = 0.1
+
+// DefaultReasoningTimeout 推理模型的默认请求超时（推理过程通常需要数十秒到数分钟）
+const DefaultReasoningTimeout = 180 * time.Second
+
+// DeepSeekClient DeepSeek客户端（兼容 OpenAI Chat Completions 接口的服务均可通过 base_url/model 接入）
 type DeepSeekClient struct {
-	apiKey     string
-	baseURL    string
-	httpClient *nets.HttpClient
-	sessions   map[string]*models.SessionContext // 多交易对会话上下文管理
+	name           string // 服务名称（用于日志）
+	apiKey         string
+	baseURL        string
+	model          string
+	jsonMode       bool          // 请求时要求JSON模式（response_format: json_object），模型只能输出JSON对象
+	temperature    float64       // 采样温度
+	maxTokens      int           // 单次回复最大 token 数（0表示使用服务默认值）
+	topP           float64       // 核采样概率（0表示使用服务默认值）
+	stream         bool          // 是否使用流式回复
+	reasoning      bool          // 推理模型（推理过程单独返回，不发送采样参数和JSON模式）
+	stallTimeout   time.Duration // 流式回复的停顿超时
+	httpClient     *nets.HttpClient
+	sessions       map[string]*models.SessionContext // 多交易对会话上下文管理（各会话自带锁，可并发分析不同交易对）
+	sessionsMu     sync.RWMutex                      // 多交易对并发运行时保护 sessions
+	promptTemplate *template.Template                // 自定义分析提示词模板（nil 使用内置提示词）
+	promptVariants []promptVariant                   // 提示词A/B实验变体（为空时不启用）
+	historySignals int                               // 提示词中逐条列出的最近信号数
+	summarize      bool                              // 更早的信号汇总为摘要
+}
+
+// promptVariant 提示词A/B实验变体
+type promptVariant struct {
+	name     string
+	template *template.Template // nil 使用内置提示词
+	weight   int
+}
+
+// PromptData 提示词模板数据
+// 模板可直接引用 {{.DefaultPrompt}} 在内置提示词基础上增删内容
+type PromptData struct {
+	TradingPair   string
+	SymbolA       string
+	Balance       float64
+	MarketData    *models.MarketData
+	Position      *models.Position
+	SignalHistory []models.TradeSignal
+	RecentSignals []models.TradeSignal // 逐条列出的最近信号（signal_history.recent 条）
+	SignalSummary string               // 更早信号的摘要（未启用 signal_history.summarize 时为空）
+	DefaultPrompt string               // 内置提示词
 }
 
 // NewDeepSeekClient 创建DeepSeek客户端
 func NewDeepSeekClient(cfg *config.APIConfig) *DeepSeekClient {
-	_httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
+	model := cfg.DeepSeekModel
+	if model == "" {
+		model = DefaultModel
+	}
+	return newChatClient("DeepSeek", cfg.DeepSeekAPIKey, cfg.DeepSeekBaseURL, model, cfg)
+}
+
+// newChatClient 创建 Chat Completions 接口客户端（提示词模板取自 cfg.PromptTemplate，失败时返回nil）
+func newChatClient(name, apiKey, baseURL, model string, cfg *config.APIConfig) *DeepSeekClient {
+	reasoning := cfg.AI.Reasoning || isReasoningModel(model)
+	timeout := nets.DefaultTimeout
+	if reasoning {
+		timeout = DefaultReasoningTimeout
+	}
+	if cfg.AI.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.AI.TimeoutSeconds) * time.Second
+	}
+	_httpClient, err := nets.NewHttpClient(timeout, nets.DefaultProxyURL)
 	if err != nil {
 		fmt.Println("创建HTTP客户端失败:", err)
 		return nil
 	}
 
-	return &DeepSeekClient{
-		apiKey:     cfg.DeepSeekAPIKey,
-		baseURL:    cfg.DeepSeekBaseURL,
-		httpClient: _httpClient,
-		sessions:   make(map[string]*models.SessionContext), // 初始化会话上下文映射
+	c := &DeepSeekClient{
+		name:         name,
+		apiKey:       apiKey,
+		baseURL:      baseURL,
+		model:        model,
+		jsonMode:     cfg.AIResponseFormat != ResponseFormatText,
+		temperature:  DefaultTemperature,
+		maxTokens:    cfg.AI.MaxTokens,
+		topP:         cfg.AI.TopP,
+		stream:       cfg.AI.Stream,
+		reasoning:    reasoning,
+		stallTimeout: DefaultStallTimeout,
+		httpClient:   _httpClient,
+		sessions:     make(map[string]*models.SessionContext), // 初始化会话上下文映射
+		summarize:    cfg.SignalHistory.Summarize,
 	}
+	c.historySignals = DefaultHistorySignals
+	if cfg.SignalHistory.Recent > 0 {
+		c.historySignals = cfg.SignalHistory.Recent
+	}
+	if cfg.AI.Temperature != nil {
+		c.temperature = *cfg.AI.Temperature
+	}
+	if cfg.AI.StallSeconds > 0 {
+		c.stallTimeout = time.Duration(cfg.AI.StallSeconds) * time.Second
+	}
+	if len(cfg.PromptVariants) > 0 {
+		if err := c.SetPromptVariants(cfg.PromptVariants); err != nil {
+			fmt.Println("加载提示词变体失败:", err)
+			return nil
+		}
+	} else if cfg.PromptTemplate != "" {
+		if err := c.SetPromptTemplate(cfg.PromptTemplate); err != nil {
+			fmt.Println("加载提示词模板失败:", err)
+			return nil
+		}
+	}
+	return c
+}
+
+// SetPromptTemplate 从文件加载分析提示词模板（text/template 语法，数据为 PromptData）
+func (c *DeepSeekClient) SetPromptTemplate(path string) error {
+	tmpl, err := loadPromptTemplate(path)
+	if err != nil {
+		return err
+	}
+	c.promptTemplate = tmpl
+	return nil
 }
 
-// ChatRequest DeepSeek聊天请求
+// SetPromptVariants 加载提示词A/B实验变体，之后每次分析按权重随机选择一个变体，并在信号中记录变体名称
+func (c *DeepSeekClient) SetPromptVariants(variants []config.PromptVariant) error {
+	loaded := make([]promptVariant, 0, len(variants))
+	seen := make(map[string]bool)
+	for i, v := range variants {
+		variant := promptVariant{name: v.Name, weight: v.Weight}
+		if variant.name == "" {
+			variant.name = fmt.Sprintf("v%d", i+1)
+		}
+		if seen[variant.name] {
+			return fmt.Errorf("提示词变体名称重复: %s", variant.name)
+		}
+		seen[variant.name] = true
+		if variant.weight <= 0 {
+			variant.weight = 1
+		}
+		if v.Template != "" {
+			tmpl, err := loadPromptTemplate(v.Template)
+			if err != nil {
+				return fmt.Errorf("变体 %s: %w", variant.name, err)
+			}
+			variant.template = tmpl
+		}
+		loaded = append(loaded, variant)
+	}
+	c.promptVariants = loaded
+	return nil
+}
+
+// loadPromptTemplate 从文件加载提示词模板
+func loadPromptTemplate(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("prompt").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("解析提示词模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+// pickPromptVariant 按流量权重随机选择提示词变体（未配置变体时返回 nil）
+func (c *DeepSeekClient) pickPromptVariant() *promptVariant {
+	if len(c.promptVariants) == 0 {
+		return nil
+	}
+	total := 0
+	for _, v := range c.promptVariants {
+		total += v.weight
+	}
+	n := rand.Intn(total)
+	for i := range c.promptVariants {
+		if n < c.promptVariants[i].weight {
+			return &c.promptVariants[i]
+		}
+		n -= c.promptVariants[i].weight
+	}
+	return &c.promptVariants[len(c.promptVariants)-1]
+}
+
+// Model 当前使用的模型
+func (c *DeepSeekClient) Model() string {
+	return c.model
+}
+
+// providerName 服务和模型名称（记录在信号中）
+func (c *DeepSeekClient) providerName() string {
+	return c.name + "/" + c.model
+}
+
+// isReasoningModel 按模型名称识别推理模型（deepseek-reasoner、DeepSeek-R1、OpenAI o1/o3/o4 系列）
+// 兼容网关的 "厂商/模型" 格式只看最后一段
+func isReasoningModel(model string) bool {
+	model = strings.ToLower(model)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	if strings.Contains(model, "reasoner") || strings.Contains(model, "deepseek-r1") {
+		return true
+	}
+	return len(model) >= 2 && model[0] == 'o' && model[1] >= '1' && model[1] <= '9'
+}
+
+// AI回复格式
+const (
+	ResponseFormatJSON = "json" // JSON模式（默认）
+	ResponseFormatText = "text" // 自由文本，从回复中提取JSON（用于不支持JSON模式的兼容服务）
+)
+
+// ChatRequest 聊天请求（OpenAI Chat Completions 格式）
 type ChatRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature"`
-	Stream      bool      `json:"stream"`
+	Model               string          `json:"model"`
+	Messages            []Message       `json:"messages"`
+	Temperature         *float64        `json:"temperature,omitempty"` // 推理模型不发送
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"` // OpenAI 推理模型使用该字段代替 max_tokens（含推理 token）
+	TopP                float64         `json:"top_p,omitempty"`
+	Stream              bool            `json:"stream"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat 回复格式约束（DeepSeek、OpenAI 均支持 json_object，提示词中需包含 "JSON" 字样）
+type ResponseFormat struct {
+	Type string `json:"type"`
 }
 
 // Message 消息结构
@@ -51,86 +258,374 @@ type Message struct {
 	Content string `json:"content"`
 }
 
-// ChatResponse DeepSeek响应
+// ChatResponse 聊天响应
 type ChatResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // 推理过程（DeepSeek 推理模型）
+			Reasoning        string `json:"reasoning"`         // 推理过程（部分兼容网关使用该字段）
 		} `json:"message"`
 	} `json:"choices"`
 }
 
-// AnalyzeMarket 分析市场并生成交易信号
-func (c *DeepSeekClient) AnalyzeMarket(tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+// AnalyzeMarket 分析市场并生成交易信号（可并发调用）
+func (c *DeepSeekClient) AnalyzeMarket(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
 	// 获取或创建该交易对的会话上下文
 	session := c.getOrCreateSession(tradingPair)
 
-	// 构建分析提示词 (使用该交易对的历史信号)
-	prompt := c.buildAnalysisPrompt(tradingPair, marketData, currentPosition, session.SignalHistory, symbolA, usdtBalance)
+	// 使用该交易对的历史信号分析
+	signal, err := c.analyze(ctx, tradingPair, marketData, currentPosition, session.History(), symbolA, usdtBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新该交易对的会话上下文（备用信号不计入）
+	if !signal.IsFallback {
+		n := session.AddSignal(*signal, c.sessionLimit())
+		logger.Debugf("[%s] 会话上下文已更新，历史信号数: %d", tradingPair, n)
+	}
+
+	return signal, nil
+}
+
+// AnalyzeSnapshot 按给定的历史信号分析一份市场数据快照，不读写会话上下文（用于提示词回测）
+func (c *DeepSeekClient) AnalyzeSnapshot(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	return c.analyze(ctx, tradingPair, marketData, currentPosition, signalHistory, symbolA, usdtBalance)
+}
+
+// analyze 构建提示词并调用AI生成交易信号，回复无法解析时返回备用信号
+func (c *DeepSeekClient) analyze(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	tmpl, variantName := c.promptTemplate, ""
+	if variant := c.pickPromptVariant(); variant != nil {
+		tmpl, variantName = variant.template, variant.name
+		logger.Debugf("[%s] 提示词变体: %s", tradingPair, variantName)
+	}
+	prompt := c.buildAnalysisPrompt(tmpl, tradingPair, marketData, currentPosition, signalHistory, symbolA, usdtBalance)
 	logger.Debugf("[%s] prompt: %s", tradingPair, prompt)
 
-	// 调用DeepSeek API
-	request := ChatRequest{
-		Model: "deepseek-chat",
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: fmt.Sprintf("您是一位专业的加密货币交易员，专注于%s交易对的%s周期趋势分析。请结合K线形态和技术指标做出判断，并严格遵循JSON格式要求。注意：这是%s交易对的独立分析，不要混淆其他交易对的信息。", tradingPair, marketData.Timeframe, tradingPair),
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
+	// 调用AI接口
+	start := time.Now()
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: fmt.Sprintf("您是一位专业的加密货币交易员，专注于%s交易对的%s周期趋势分析。请结合K线形态和技术指标做出判断，并严格遵循JSON格式要求。注意：这是%s交易对的独立分析，不要混淆其他交易对的信息。", tradingPair, marketData.Timeframe, tradingPair),
+		},
+		{
+			Role:    "user",
+			Content: prompt,
 		},
-		Temperature: 0.1,
-		Stream:      false,
 	}
+	content, reasoning, err := c.chat(ctx, messages)
+	if err != nil {
+		c.audit(AuditKindAnalysis, tradingPair, messages, "", "", nil, err, start)
+		return nil, err
+	}
+	logger.Infof("[%s] %s原始回复: %s", tradingPair, c.name, content)
 
-	requestBody, err := json.Marshal(request)
+	// 解析JSON响应
+	signal, err := c.parseSignal(content, marketData)
+	if err != nil {
+		logger.Errorf("[%s] 解析信号失败，使用备用方案: %v", tradingPair, err)
+		fallback := c.createFallbackSignal(tradingPair, marketData)
+		fallback.PromptVariant = variantName
+		c.audit(AuditKindAnalysis, tradingPair, messages, content, reasoning, fallback, err, start)
+		return fallback, nil
+	}
+
+	signal.Timestamp = time.Now().Format("2006-01-02 15:04:05")
+	signal.TradingPair = tradingPair
+	signal.Provider = c.providerName()
+	signal.PromptVariant = variantName
+	c.audit(AuditKindAnalysis, tradingPair, messages, content, reasoning, signal, nil, start)
+
+	return signal, nil
+}
+
+// AskExitOpinion 询问AI持仓是否应提前离场（简短提示词，不使用会话历史）
+func (c *DeepSeekClient) AskExitOpinion(ctx context.Context, tradingPair string, pos *models.Position, currentPrice, stopPrice float64) (opinion *models.ExitOpinion, err error) {
+	var movePercent float64
+	if pos.EntryPrice > 0 {
+		movePercent = (currentPrice - pos.EntryPrice) / pos.EntryPrice * 100
+	}
+
+	liquidationText := ""
+	if pos.LiquidationPrice > 0 {
+		liquidationText = fmt.Sprintf("，强平价 %.2f（距当前价 %.2f%%）", pos.LiquidationPrice, pos.LiquidationDistance(currentPrice))
+	}
+
+	prompt := fmt.Sprintf(`%s 当前持有%s仓，开仓价 %.2f，当前价 %.2f（%+.2f%%），止损价 %.2f，止盈价 %.2f，杠杆 %dx%s。
+价格正朝止损方向运行。请判断是否应在触发止损前提前离场。
+仅返回JSON：{"action": "EXIT|HOLD", "confidence": "HIGH|MEDIUM|LOW", "reason": "不超过30字"}`,
+		tradingPair, pos.Side, pos.EntryPrice, currentPrice, movePercent, stopPrice, pos.TakeProfit, pos.Leverage, liquidationText)
+	logger.Debugf("[%s] exit prompt: %s", tradingPair, prompt)
+
+	start := time.Now()
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: "您是一位专业的加密货币风控交易员，只回答是否提前离场，严格遵循JSON格式要求。",
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+	var content, reasoning string
+	defer func() { c.audit(AuditKindExitOpinion, tradingPair, messages, content, reasoning, opinion, err, start) }()
+
+	content, reasoning, err = c.chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("[%s] %s离场意见: %s", tradingPair, c.name, content)
+
+	jsonStr := extractJSON(content)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("未找到JSON格式数据")
+	}
+
+	var parsed models.ExitOpinion
+	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+	parsed.Action = strings.ToUpper(strings.TrimSpace(parsed.Action))
+	parsed.Confidence = strings.ToUpper(strings.TrimSpace(parsed.Confidence))
+	if parsed.Action != "EXIT" && parsed.Action != "HOLD" {
+		return nil, fmt.Errorf("无效的离场意见: %s", parsed.Action)
+	}
+
+	return &parsed, nil
+}
+
+// WritePostMortem 按已平仓交易的开平仓信息和持仓期间行情撰写简短复盘（不使用会话历史）
+func (c *DeepSeekClient) WritePostMortem(ctx context.Context, review *models.TradeReview) (postMortem *models.PostMortem, err error) {
+	excursion := "未获取"
+	if review.MaxFavorable != 0 || review.MaxAdverse != 0 {
+		excursion = fmt.Sprintf("最大有利波动 %+.2f%%，最大不利波动 -%.2f%%", review.MaxFavorable, review.MaxAdverse)
+	}
+	exitTrend := review.ExitTrend
+	if exitTrend == "" {
+		exitTrend = "未获取"
+	}
+
+	prompt := fmt.Sprintf(`请复盘 %s 的一笔已平仓交易：
+- 方向: %s，杠杆 %dx，开仓信心 %s，开仓时市场状态: %s
+- 开仓理由: %s
+- 开仓: %s @ %.2f
+- 平仓: %s @ %.2f（%s），持仓 %s
+- 结果: 净收益率 %+.2f%%（不含杠杆，已扣手续费），净盈亏 %.4f
+- 持仓期间: %s；平仓时市场状态: %s
+请结合开仓理由和之后的行情，说明这笔交易的经过、问题所在和可借鉴的经验。
+仅返回JSON：{"summary": "不超过60字", "mistakes": "不超过60字", "lesson": "不超过40字"}`,
+		review.TradingPair, review.Side, review.Leverage, review.Confidence, review.Regime, review.EntryReason,
+		review.OpenedAt.Local().Format("01-02 15:04"), review.EntryPrice,
+		review.ClosedAt.Local().Format("01-02 15:04"), review.ExitPrice, review.ExitReason,
+		review.ClosedAt.Sub(review.OpenedAt).Round(time.Minute),
+		review.ReturnPct, review.NetPnL, excursion, exitTrend)
+	logger.Debugf("[%s] post-mortem prompt: %s", review.TradingPair, prompt)
+
+	start := time.Now()
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: "您是一位专业的加密货币交易复盘分析师，客观评价交易决策而非结果，严格遵循JSON格式要求。",
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+	var content, reasoning string
+	defer func() {
+		c.audit(AuditKindPostMortem, review.TradingPair, messages, content, reasoning, postMortem, err, start)
+	}()
+
+	content, reasoning, err = c.chat(ctx, messages)
 	if err != nil {
 		return nil, err
 	}
 
+	jsonStr := extractJSON(content)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("未找到JSON格式数据")
+	}
+
+	var parsed models.PostMortem
+	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+	if strings.TrimSpace(parsed.Summary) == "" {
+		return nil, fmt.Errorf("复盘内容为空")
+	}
+	parsed.Model = c.Model()
+	parsed.WrittenAt = time.Now()
+	return &parsed, nil
+}
+
+// SuggestTuning 按近期交易表现和市场状态建议技术指标参数调整（不使用会话历史）
+// 只返回允许调整的参数，建议值是否应用及应用幅度由调用方决定
+func (c *DeepSeekClient) SuggestTuning(ctx context.Context, review *models.TuningReview) (advice *models.TuningAdvice, err error) {
+	var params strings.Builder
+	allowed := make(map[string]bool, len(review.Params))
+	for _, p := range review.Params {
+		fmt.Fprintf(&params, "- %s: 当前 %g（允许范围 %g ~ %g）\n", p.Name, p.Value, p.Min, p.Max)
+		allowed[p.Name] = true
+	}
+
+	prompt := fmt.Sprintf(`请评估 %s %s周期交易策略近期的表现，并建议技术指标参数是否需要调整：
+- 近期交易: %d 笔，胜率 %.1f%%，平均净收益率 %+.2f%%（不含杠杆），累计净盈亏 %.4f
+- 平均持仓 %.1f 小时，其中风控平仓 %d 笔
+- 当前市场状态: %s，ATR 占价格 %.2f%%，RSI %.1f
+
+当前技术指标参数：
+%s
+参数调整需有明确依据（如震荡市中延长 RSI 周期减少假信号、波动放大时放宽布林带），无需调整时返回空数组；建议值必须在允许范围内。
+仅返回JSON：{"assessment": "不超过60字", "suggestions": [{"param": "参数名", "value": 建议值, "reason": "不超过30字"}]}`,
+		review.TradingPair, review.Timeframe, review.Trades, review.WinRate, review.AvgReturnPct, review.NetPnL,
+		review.AvgHoldHours, review.StopLossExits, review.Regime, review.ATRPercent, review.RSI, params.String())
+	logger.Debugf("[%s] tuning prompt: %s", review.TradingPair, prompt)
+
+	start := time.Now()
+	messages := []Message{
+		{
+			Role:    "system",
+			Content: "您是一位专业的量化交易策略顾问，根据交易表现和市场状态审慎建议技术指标参数调整，严格遵循JSON格式要求。",
+		},
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+	var content, reasoning string
+	defer func() {
+		c.audit(AuditKindTuning, review.TradingPair, messages, content, reasoning, advice, err, start)
+	}()
+
+	content, reasoning, err = c.chat(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr := extractJSON(content)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("未找到JSON格式数据")
+	}
+
+	var parsed models.TuningAdvice
+	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+		return nil, fmt.Errorf("JSON解析失败: %w", err)
+	}
+	suggestions := parsed.Suggestions[:0]
+	for _, s := range parsed.Suggestions {
+		if s.Param = strings.TrimSpace(s.Param); allowed[s.Param] {
+			suggestions = append(suggestions, s)
+		} else {
+			logger.Warnf("[%s] 忽略未知参数的调整建议: %s", review.TradingPair, s.Param)
+		}
+	}
+	parsed.Suggestions = suggestions
+	return &parsed, nil
+}
+
+// chat 调用聊天接口，返回回复内容和推理过程（非推理模型推理过程为空，ctx 取消时中止请求）
+func (c *DeepSeekClient) chat(ctx context.Context, messages []Message) (content, reasoning string, err error) {
+	request := c.newChatRequest(messages)
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return "", "", err
+	}
+
 	headers := map[string]string{
 		"Content-Type":  "application/json",
 		"Authorization": "Bearer " + c.apiKey,
 	}
 
-	body, err := c.httpClient.QueryPost(c.baseURL+"/v1/chat/completions", headers, requestBody)
+	if c.stream {
+		content, reasoning, err = c.chatStream(ctx, requestBody, headers)
+	} else {
+		content, reasoning, err = c.chatOnce(ctx, requestBody, headers)
+	}
+	if err == nil && reasoning != "" {
+		logger.Debugf("%s推理过程 (%d 字符): %s", c.name, len([]rune(reasoning)), reasoning)
+	}
+	return content, reasoning, err
+}
+
+// newChatRequest 构建聊天请求
+// 推理模型不接受采样参数（OpenAI o 系列传入 temperature 会报错），且不一定支持JSON模式，
+// 回复按文本提取JSON；OpenAI 推理模型的回复长度上限使用 max_completion_tokens
+func (c *DeepSeekClient) newChatRequest(messages []Message) ChatRequest {
+	request := ChatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Stream:   c.stream,
+	}
+	if !c.reasoning {
+		temperature := c.temperature
+		request.Temperature = &temperature
+		request.MaxTokens = c.maxTokens
+		request.TopP = c.topP
+		if c.jsonMode {
+			request.ResponseFormat = &ResponseFormat{Type: "json_object"}
+		}
+		return request
+	}
+
+	if c.name == "OpenAI" {
+		request.MaxCompletionTokens = c.maxTokens
+	} else {
+		request.MaxTokens = c.maxTokens
+	}
+	return request
+}
+
+// chatOnce 非流式调用聊天接口
+func (c *DeepSeekClient) chatOnce(ctx context.Context, requestBody []byte, headers map[string]string) (string, string, error) {
+	body, err := c.httpClient.QueryPostContext(ctx, c.baseURL+"/v1/chat/completions", headers, requestBody)
 	if err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return nil, err
+		return "", "", err
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return nil, fmt.Errorf("DeepSeek返回空响应")
+		return "", "", fmt.Errorf("%s返回空响应", c.name)
 	}
 
-	content := chatResp.Choices[0].Message.Content
-	logger.Infof("[%s] DeepSeek原始回复: %s", tradingPair, content)
-
-	// 解析JSON响应
-	signal, err := c.parseSignal(content, marketData)
-	if err != nil {
-		logger.Errorf("[%s] 解析信号失败，使用备用方案: %v", tradingPair, err)
-		return c.createFallbackSignal(tradingPair, marketData), nil
+	message := chatResp.Choices[0].Message
+	reasoning := message.ReasoningContent
+	if reasoning == "" {
+		reasoning = message.Reasoning
 	}
+	if strings.TrimSpace(message.Content) == "" && reasoning != "" {
+		return "", reasoning, fmt.Errorf("%s只返回了推理过程，没有最终回复（可能达到 max_tokens 上限）", c.name)
+	}
+	return message.Content, reasoning, nil
+}
 
-	signal.Timestamp = time.Now().Format("2006-01-02 15:04:05")
-	signal.TradingPair = tradingPair
-
-	// 更新该交易对的会话上下文
-	c.updateSession(tradingPair, signal)
+// maxSessionHistory 每个交易对会话保留的历史信号条数
+const maxSessionHistory = 30
 
-	return signal, nil
+// sessionLimit 会话保留的历史信号条数（至少覆盖逐条列出的最近信号数）
+func (c *DeepSeekClient) sessionLimit() int {
+	if c.historySignals > maxSessionHistory {
+		return c.historySignals
+	}
+	return maxSessionHistory
 }
 
 // getOrCreateSession 获取或创建交易对的会话上下文
 func (c *DeepSeekClient) getOrCreateSession(tradingPair string) *models.SessionContext {
+	if session := c.GetSessionInfo(tradingPair); session != nil {
+		return session
+	}
+
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
 	if session, exists := c.sessions[tradingPair]; exists {
 		return session
 	}
@@ -146,41 +641,51 @@ func (c *DeepSeekClient) getOrCreateSession(tradingPair string) *models.SessionC
 	return session
 }
 
-// updateSession 更新交易对的会话上下文
-func (c *DeepSeekClient) updateSession(tradingPair string, signal *models.TradeSignal) {
-	session := c.sessions[tradingPair]
-	session.SignalHistory = append(session.SignalHistory, *signal)
-
-	// 更新统计信息
-	session.Stats.Total++
-	switch signal.Signal {
-	case "BUY":
-		session.Stats.BuyCount++
-	case "SELL":
-		session.Stats.SellCount++
-	case "HOLD":
-		session.Stats.HoldCount++
-	}
-
-	// 限制历史记录数量 (每个交易对独立维护30条)
-	if len(session.SignalHistory) > 30 {
-		session.SignalHistory = session.SignalHistory[1:]
-	}
-
-	session.LastUpdate = signal.Timestamp
-	logger.Debugf("[%s] 会话上下文已更新，历史信号数: %d", tradingPair, len(session.SignalHistory))
-}
-
 // GetSessionInfo 获取交易对的会话信息 (用于调试和监控)
 func (c *DeepSeekClient) GetSessionInfo(tradingPair string) *models.SessionContext {
+	c.sessionsMu.RLock()
+	defer c.sessionsMu.RUnlock()
+
 	if session, exists := c.sessions[tradingPair]; exists {
 		return session
 	}
 	return nil
 }
 
-// buildAnalysisPrompt 构建分析提示词
-func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) string {
+// buildAnalysisPrompt 构建分析提示词（tmpl 不为nil时按模板渲染，渲染失败回退到内置提示词）
+func (c *DeepSeekClient) buildAnalysisPrompt(tmpl *template.Template, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) string {
+	older, recent := splitHistory(signalHistory, c.historySignals)
+	var summary string
+	if c.summarize {
+		summary = summarizeSignals(older)
+	}
+	prompt := c.defaultAnalysisPrompt(tradingPair, marketData, currentPosition, historyPromptText(recent, summary), symbolA, usdtBalance)
+	if tmpl == nil {
+		return prompt
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, PromptData{
+		TradingPair:   tradingPair,
+		SymbolA:       symbolA,
+		Balance:       usdtBalance,
+		MarketData:    marketData,
+		Position:      currentPosition,
+		SignalHistory: signalHistory,
+		RecentSignals: recent,
+		SignalSummary: summary,
+		DefaultPrompt: prompt,
+	})
+	if err != nil {
+		logger.Warnf("[%s] 渲染提示词模板失败，使用内置提示词: %v", tradingPair, err)
+		return prompt
+	}
+	return buf.String()
+}
+
+// defaultAnalysisPrompt 构建内置分析提示词
+// historyText: 历史信号段落（含标题，见 historyPromptText）
+func (c *DeepSeekClient) defaultAnalysisPrompt(tradingPair string, marketData *models.MarketData, currentPosition *models.Position, historyText string, symbolA string, usdtBalance float64) string {
 	// K线数据文本
 	klineText := fmt.Sprintf("【最近5根%s K线数据】\n", marketData.Timeframe)
 	if len(marketData.KlineData) > 0 {
@@ -236,6 +741,87 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 		)
 	}
 
+	// 盘口信息
+	if book := marketData.OrderBook; book != nil && len(book.Bids) > 0 && len(book.Asks) > 0 {
+		techText += fmt.Sprintf(`
+📒 盘口深度:
+- 买一: %.2f | 卖一: %.2f | 价差: %.2f bps
+- 前5档买卖盘失衡: %+.2f | 前%d档: %+.2f (正数买盘强, 负数卖盘强)
+`,
+			book.Bids[0].Price, book.Asks[0].Price, book.SpreadBps(),
+			book.Imbalance(5), len(book.Bids), book.Imbalance(0),
+		)
+	}
+
+	// 多周期趋势
+	if len(marketData.HigherTimeframes) > 0 {
+		techText += "\n🕰️ 大周期趋势（以大周期方向为主，避免逆大周期趋势开仓）:\n"
+		for _, tf := range marketData.HigherTimeframes {
+			if tf.Trend == nil || tf.Levels == nil {
+				continue
+			}
+			techText += fmt.Sprintf("- %s: %s (短期%s, 中期%s, MACD %s, RSI %.1f, ATR %.2f%%) | 支撑 %.2f (下方 %.2f%%) 阻力 %.2f (上方 %.2f%%)\n",
+				tf.Timeframe, tf.Trend.Overall, tf.Trend.ShortTerm, tf.Trend.MediumTerm, tf.Trend.MACD, tf.RSI, tf.ATRPercent,
+				tf.Levels.StaticSupport, tf.Levels.PriceVsSupport, tf.Levels.StaticResistance, tf.Levels.PriceVsResistance)
+		}
+	}
+
+	// 合约市场
+	if d := marketData.Derivatives; d != nil {
+		techText += fmt.Sprintf("\n📊 合约市场（资金费率极端或持仓拥挤时，同向追单风险较高）:\n- 资金费率: %+.4f%%", d.FundingRate*100)
+		if d.FundingIntervalHours > 0 {
+			techText += fmt.Sprintf(" / %g小时 (年化 %+.1f%%)", d.FundingIntervalHours, d.AnnualizedFundingPercent())
+		}
+		techText += " (正数多头付费, 负数空头付费)\n"
+		if d.OpenInterest > 0 {
+			techText += fmt.Sprintf("- 全市场持仓量: %.2f %s", d.OpenInterest, symbolA)
+			if d.MarkPrice > 0 {
+				techText += fmt.Sprintf(" (约 %.0f USDT)", d.OpenInterest*d.MarkPrice)
+			}
+			if d.OpenInterestChange != 0 {
+				techText += fmt.Sprintf(", 较上一轮 %+.2f%%", d.OpenInterestChange)
+			}
+			techText += "\n"
+		}
+		if d.MarkPrice > 0 && d.IndexPrice > 0 {
+			techText += fmt.Sprintf("- 基差: %+.3f%% (标记价格 %.2f, 指数价格 %.2f)\n", d.BasisPercent(), d.MarkPrice, d.IndexPrice)
+		}
+	}
+	if b := marketData.SpotBasis; b != nil {
+		if marketData.Derivatives == nil {
+			techText += "\n📊 合约市场:\n"
+		}
+		techText += fmt.Sprintf("- 现货基差: 合约相对现货 %+.3f%% (现货 %.2f, 合约 %.2f, 近%d轮均值 %+.3f%%)\n",
+			b.Percent, b.SpotPrice, b.PerpPrice, b.Samples, b.AveragePercent)
+	}
+
+	// 市场情绪
+	if fg := marketData.FearGreed; fg != nil {
+		change := ""
+		if fg.PreviousValue > 0 {
+			change = fmt.Sprintf("，前一日 %d", fg.PreviousValue)
+		}
+		techText += fmt.Sprintf(`
+😨 市场情绪（恐惧贪婪指数，0 极度恐惧 ~ 100 极度贪婪）:
+- 当前: %d (%s%s)
+`, fg.Value, fg.Classification, change)
+	}
+
+	// 市场新闻
+	if len(marketData.News) > 0 {
+		techText += "\n📰 近期市场新闻（仅作背景参考，以价格行为为主）:\n"
+		for _, news := range marketData.News {
+			techText += fmt.Sprintf("- [%s] %s (%s)\n", news.PublishedAt.Local().Format("01-02 15:04"), news.Title, news.Source)
+		}
+	}
+
+	// 备用数据源提示
+	if marketData.IsFallbackData {
+		techText += fmt.Sprintf(`
+⚠️ 数据来源: 主交易所行情接口暂时不可用，以上K线数据来自备用数据源 %s，价格可能与交易所存在细微差异
+`, marketData.DataSource)
+	}
+
 	// 持仓信息
 	positionText := "无持仓"
 	if currentPosition != nil {
@@ -255,13 +841,10 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 		}
 		positionText = fmt.Sprintf("%s仓, 数量: %.8f, 盈亏: %.2fUSDT (%.2f%%)",
 			currentPosition.Side, currentPosition.Size, currentPosition.UnrealizedPnL, pnlPercentage)
-	}
-
-	// 历史信号 (仅显示该交易对的最近信号)
-	signalText := "- 首次分析, 无历史信号"
-	if len(signalHistory) > 0 {
-		lastSignal := signalHistory[len(signalHistory)-1]
-		signalText = fmt.Sprintf("\n信号: %s\n信心: %s\n理由: %s", lastSignal.Signal, lastSignal.Confidence, lastSignal.Reason)
+		if currentPosition.LiquidationPrice > 0 {
+			positionText += fmt.Sprintf(", 强平价: %.2f (距当前价 %.2f%%)",
+				currentPosition.LiquidationPrice, currentPosition.LiquidationDistance(marketData.Price))
+		}
 	}
 
 	prompt := fmt.Sprintf(`
@@ -276,7 +859,6 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 
 %s
 
-【上次交易信号】
 %s
 
 【当前行情】
@@ -293,7 +875,8 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 【分析要求】
 1. 基于%sK线趋势和技术指标给出交易信号: BUY(买入) / SELL(卖出) / HOLD(观望)
 2. 简要分析理由（考虑趋势连续性、支撑阻力、成交量等因素）
-3. 评估信号信心程度
+3. 评估信号信心程度，并给出0-100的信心分数
+4. BUY/SELL信号给出建议止损价、止盈价（基于支撑阻力和波动幅度）和建议仓位比例（0-1，1为满额交易金额）
 
 【重要提示】
 - 这是%s交易对的独立分析
@@ -311,14 +894,18 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 {
     "signal": "BUY|SELL|HOLD",
     "reason": "分析理由",
-    "confidence": "HIGH|MEDIUM|LOW"
+    "confidence": "HIGH|MEDIUM|LOW",
+    "confidence_score": 0-100的整数,
+    "stop_loss": 建议止损价（HOLD时为0）,
+    "take_profit": 建议止盈价（HOLD时为0）,
+    "size_fraction": 建议仓位比例（0-1，HOLD时为0）
 }
 `,
 		tradingPair,
 		marketData.Timeframe,
 		klineText,
 		techText,
-		signalText,
+		historyText,
 		tradingPair, // 在多处强调交易对
 		marketData.Price,
 		marketData.Timestamp,
@@ -338,15 +925,10 @@ func (c *DeepSeekClient) buildAnalysisPrompt(tradingPair string, marketData *mod
 
 // parseSignal 解析交易信号
 func (c *DeepSeekClient) parseSignal(content string, marketData *models.MarketData) (*models.TradeSignal, error) {
-	// 提取JSON部分 - 支持多行JSON
-	re := regexp.MustCompile(`(?s)\{[^{}]*\}`)
-	matches := re.FindString(content)
-	if matches == "" {
+	jsonStr := extractJSON(content)
+	if jsonStr == "" {
 		return nil, fmt.Errorf("未找到JSON格式数据")
 	}
-
-	// 清理和修复JSON
-	jsonStr := strings.TrimSpace(matches)
 	logger.Debugf("提取的JSON: %s", jsonStr)
 
 	var signal models.TradeSignal
@@ -354,21 +936,58 @@ func (c *DeepSeekClient) parseSignal(content string, marketData *models.MarketDa
 		return nil, fmt.Errorf("JSON解析失败: %w", err)
 	}
 
-	// 验证必需字段
-	if signal.Signal == "" {
-		return nil, fmt.Errorf("信号字段为空")
-	}
-	if signal.Reason == "" {
-		return nil, fmt.Errorf("理由字段为空")
+	if err := validateSignal(&signal); err != nil {
+		return nil, err
 	}
 
 	// 记录解析结果
-	logger.Debugf("解析成功 - 信号:%s, 信心:%s",
-		signal.Signal, signal.Confidence)
+	logger.Debugf("解析成功 - 信号:%s, 信心:%s(%d), 建议止损:%.2f, 建议止盈:%.2f, 仓位比例:%.2f",
+		signal.Signal, signal.Confidence, signal.ConfidenceScore, signal.StopLoss, signal.TakeProfit, signal.SizeFraction)
 
 	return &signal, nil
 }
 
+// validateSignal 按回复格式校验信号字段（signal、confidence 统一为大写）
+func validateSignal(signal *models.TradeSignal) error {
+	signal.Signal = strings.ToUpper(strings.TrimSpace(signal.Signal))
+	signal.Confidence = strings.ToUpper(strings.TrimSpace(signal.Confidence))
+	signal.Reason = strings.TrimSpace(signal.Reason)
+
+	switch signal.Signal {
+	case "BUY", "SELL", "HOLD":
+	case "":
+		return fmt.Errorf("信号字段为空")
+	default:
+		return fmt.Errorf("无效的信号: %s", signal.Signal)
+	}
+	switch signal.Confidence {
+	case "HIGH", "MEDIUM", "LOW":
+	default:
+		return fmt.Errorf("无效的信心等级: %q", signal.Confidence)
+	}
+	if signal.Reason == "" {
+		return fmt.Errorf("理由字段为空")
+	}
+
+	// 信心分数、建议止盈止损和仓位为可选字段，取值不合理时忽略（不影响信号本身）
+	if signal.ConfidenceScore < 0 || signal.ConfidenceScore > 100 {
+		logger.Warnf("忽略超出范围的信心分数: %d", signal.ConfidenceScore)
+		signal.ConfidenceScore = 0
+	}
+	if signal.Signal == "HOLD" {
+		signal.StopLoss, signal.TakeProfit, signal.SizeFraction = 0, 0, 0
+	}
+	if signal.StopLoss < 0 || signal.TakeProfit < 0 {
+		logger.Warnf("忽略无效的建议止盈止损价: 止损 %.2f, 止盈 %.2f", signal.StopLoss, signal.TakeProfit)
+		signal.StopLoss, signal.TakeProfit = 0, 0
+	}
+	if signal.SizeFraction < 0 || signal.SizeFraction > 1 {
+		logger.Warnf("忽略超出范围的仓位比例: %.2f", signal.SizeFraction)
+		signal.SizeFraction = 0
+	}
+	return nil
+}
+
 // createFallbackSignal 创建备用信号
 func (c *DeepSeekClient) createFallbackSignal(tradingPair string, marketData *models.MarketData) *models.TradeSignal {
 	return &models.TradeSignal{
@@ -378,6 +997,7 @@ func (c *DeepSeekClient) createFallbackSignal(tradingPair string, marketData *mo
 		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
 		IsFallback:  true,
 		TradingPair: tradingPair,
+		Provider:    c.providerName(),
 	}
 }
 
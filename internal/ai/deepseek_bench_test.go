@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/indicator"
+	"dsbot/internal/models"
+)
+
+// benchmarkMarketData 构建包含完整技术指标和盘口的市场数据
+func benchmarkMarketData(n int) *models.MarketData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ohlcvList := make([]models.OHLCV, n)
+	price := 50000.0
+	for i := range ohlcvList {
+		open := price
+		price = open * (1 + 0.002*math.Sin(float64(i)/7))
+		ohlcvList[i] = models.OHLCV{
+			Timestamp: start.Add(time.Duration(i) * 15 * time.Minute),
+			Open:      open,
+			High:      math.Max(open, price) * 1.001,
+			Low:       math.Min(open, price) * 0.999,
+			Close:     price,
+			Volume:    100,
+		}
+	}
+
+	calc := indicator.NewCalculatorWithConfig(indicator.AggressiveConfig())
+	tech := calc.Calculate(ohlcvList)
+	book := &models.OrderBook{Symbol: "BTC/USDT:USDT"}
+	for i := 0; i < 20; i++ {
+		book.Bids = append(book.Bids, models.OrderBookLevel{Price: price - float64(i), Size: 1})
+		book.Asks = append(book.Asks, models.OrderBookLevel{Price: price + float64(i+1), Size: 1})
+	}
+
+	return &models.MarketData{
+		Price:          price,
+		Timestamp:      start.Format("2006-01-02 15:04:05"),
+		Timeframe:      "15m",
+		KlineData:      ohlcvList,
+		TechnicalData:  tech,
+		TrendAnalysis:  calc.CalculateTrendAnalysis(ohlcvList, tech),
+		LevelsAnalysis: calc.CalculateLevelsAnalysis(ohlcvList, tech),
+		OrderBook:      book,
+	}
+}
+
+func BenchmarkBuildAnalysisPrompt(b *testing.B) {
+	c := NewDeepSeekClient(&config.APIConfig{})
+	marketData := benchmarkMarketData(100)
+	position := &models.Position{Side: "long", Size: 0.01, EntryPrice: 50000, UnrealizedPnL: 12.5, Leverage: 10}
+	history := []models.TradeSignal{{Signal: "BUY", Confidence: "HIGH", Reason: "趋势向上"}}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.buildAnalysisPrompt(c.promptTemplate, "BTC-USDT", marketData, position, history, "BTC", 1000)
+	}
+}
+
+func BenchmarkParseSignal(b *testing.B) {
+	c := NewDeepSeekClient(&config.APIConfig{})
+	content := "分析如下：\n```json\n{\n    \"signal\": \"BUY\",\n    \"reason\": \"均线多头排列，MACD金叉，成交量放大\",\n    \"confidence\": \"HIGH\"\n}\n```"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.parseSignal(content, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
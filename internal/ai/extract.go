@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// extractJSON 提取回复中的JSON对象：JSON模式下回复本身即为JSON，否则从自由文本中提取
+// 依次去除推理过程（<think>...</think>）和 Markdown 代码块标记，按括号配对查找完整的对象（支持嵌套，
+// 忽略字符串内的括号），并修复常见问题（尾随逗号、// 注释、字符串内未转义的换行）；
+// 返回第一个有效的对象，均无效时返回第一个配对完整的片段（供解析时报错），未找到时返回空字符串。
+// 不补全被截断的对象，流式回复据此判断是否已收到完整的JSON
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if json.Valid([]byte(content)) {
+		return content
+	}
+
+	content = stripThinking(content)
+	content = stripCodeFences(content)
+
+	var first string
+	for start := strings.IndexByte(content, '{'); start >= 0; {
+		end := matchBrace(content, start)
+		if end < 0 {
+			break // 对象不完整（回复被截断）
+		}
+		candidate := content[start : end+1]
+		if json.Valid([]byte(candidate)) {
+			return candidate
+		}
+		if repaired := repairJSON(candidate); json.Valid([]byte(repaired)) {
+			return repaired
+		}
+		if first == "" {
+			first = candidate
+		}
+
+		next := strings.IndexByte(content[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += 1 + next
+	}
+	return first
+}
+
+// stripThinking 去除推理模型在回复前输出的 <think>...</think> 推理过程
+func stripThinking(content string) string {
+	if _, after, ok := strings.Cut(content, "</think>"); ok {
+		return strings.TrimSpace(after)
+	}
+	return content
+}
+
+// stripCodeFences 去除 Markdown 代码块标记（```json ... ```），保留代码块内容和块外文本
+func stripCodeFences(content string) string {
+	if !strings.Contains(content, "```") {
+		return content
+	}
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			// 单行代码块 ```{...}``` 只去掉标记
+			trimmed = strings.TrimPrefix(trimmed, "```")
+			trimmed = strings.TrimPrefix(trimmed, "json")
+			trimmed = strings.TrimPrefix(trimmed, "JSON")
+			trimmed = strings.TrimSuffix(trimmed, "```")
+			if trimmed = strings.TrimSpace(trimmed); trimmed == "" {
+				continue
+			}
+			line = trimmed
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// matchBrace 返回与 start 处 '{' 配对的 '}' 的位置（忽略字符串内的括号），未配对时返回-1
+func matchBrace(content string, start int) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(content); i++ {
+		ch := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// repairJSON 修复常见的格式问题：去除对象/数组末尾多余的逗号和 // 注释，转义字符串内的换行和制表符
+func repairJSON(candidate string) string {
+	var b strings.Builder
+	b.Grow(len(candidate))
+	inString, escaped := false, false
+	for i := 0; i < len(candidate); i++ {
+		ch := candidate[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			case ch == '\n':
+				b.WriteString(`\n`)
+				continue
+			case ch == '\r':
+				continue
+			case ch == '\t':
+				b.WriteString(`\t`)
+				continue
+			}
+			b.WriteByte(ch)
+			continue
+		}
+
+		switch {
+		case ch == '"':
+			inString = true
+		case ch == '/' && i+1 < len(candidate) && candidate[i+1] == '/':
+			// 跳过注释直到行尾
+			for i < len(candidate) && candidate[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case ch == ',':
+			// 后面（跳过空白和注释）紧接 } 或 ] 的逗号为尾随逗号
+			if next := nextToken(candidate[i+1:]); next == '}' || next == ']' {
+				continue
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// nextToken 跳过空白和 // 注释后的第一个字符（没有时返回0）
+func nextToken(s string) byte {
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+		case ch == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		default:
+			return ch
+		}
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// 真实回复中常见的格式问题
+func TestExtractJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string // 期望提取出的对象中 signal 字段的值（为空表示期望提取失败）
+	}{
+		{"纯JSON", `{"signal": "BUY", "confidence": "HIGH", "reason": "突破"}`, "BUY"},
+		{"前后有说明文字", "根据分析，建议如下：\n{\"signal\": \"SELL\", \"confidence\": \"MEDIUM\", \"reason\": \"跌破支撑\"}\n仅供参考。", "SELL"},
+		{"代码块", "```json\n{\n  \"signal\": \"HOLD\",\n  \"confidence\": \"LOW\",\n  \"reason\": \"震荡\"\n}\n```", "HOLD"},
+		{"大写代码块标记", "```JSON\n{\"signal\": \"BUY\", \"confidence\": \"LOW\", \"reason\": \"x\"}\n```", "BUY"},
+		{"单行代码块", "```json {\"signal\": \"SELL\", \"confidence\": \"LOW\", \"reason\": \"x\"}```", "SELL"},
+		{"无语言标记的代码块", "分析结果：\n```\n{\"signal\": \"BUY\", \"confidence\": \"HIGH\", \"reason\": \"x\"}\n```", "BUY"},
+		{"嵌套对象", `{"signal": "BUY", "confidence": "HIGH", "reason": "x", "levels": {"stop_loss": 95000, "take_profit": 99000}}`, "BUY"},
+		{"嵌套对象前后有文字", "结论：{\"signal\": \"SELL\", \"detail\": {\"rsi\": 78, \"macd\": {\"hist\": -1.2}}, \"confidence\": \"MEDIUM\", \"reason\": \"超买\"} 以上。", "SELL"},
+		{"字符串内有括号", `{"signal": "HOLD", "confidence": "LOW", "reason": "区间{93000-95000}内震荡 }"}`, "HOLD"},
+		{"字符串内有转义引号", `{"signal": "BUY", "confidence": "HIGH", "reason": "形成\"双底\"形态"}`, "BUY"},
+		{"尾随逗号", "{\"signal\": \"BUY\", \"confidence\": \"HIGH\", \"reason\": \"突破\",}", "BUY"},
+		{"换行后的尾随逗号", "{\n  \"signal\": \"SELL\",\n  \"confidence\": \"LOW\",\n  \"reason\": \"x\",\n}", "SELL"},
+		{"数组尾随逗号", `{"signal": "BUY", "confidence": "HIGH", "reason": "x", "factors": ["rsi", "macd",]}`, "BUY"},
+		{"注释", "{\n  \"signal\": \"BUY\", // 做多\n  \"confidence\": \"HIGH\",\n  \"reason\": \"x\" // 理由\n}", "BUY"},
+		{"注释后的尾随逗号", "{\n  \"signal\": \"HOLD\",\n  \"confidence\": \"LOW\",\n  \"reason\": \"x\", // 结束\n}", "HOLD"},
+		{"字符串内的链接不是注释", `{"signal": "HOLD", "confidence": "LOW", "reason": "参考 https://example.com"}`, "HOLD"},
+		{"字符串内未转义的换行", "{\"signal\": \"SELL\", \"confidence\": \"MEDIUM\", \"reason\": \"第一行\n第二行\"}", "SELL"},
+		{"推理过程", "<think>\n先看趋势 {不是JSON}，再看RSI\n</think>\n{\"signal\": \"BUY\", \"confidence\": \"MEDIUM\", \"reason\": \"x\"}", "BUY"},
+		{"先出现无效片段", "示例格式 {signal: BUY}，实际结果：{\"signal\": \"SELL\", \"confidence\": \"LOW\", \"reason\": \"x\"}", "SELL"},
+		{"被截断", `{"signal": "BUY", "confidence": "HIGH", "reason": "突破阻力`, ""},
+		{"没有JSON", "抱歉，我无法给出建议。", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := extractJSON(tc.content)
+			var signal struct {
+				Signal string `json:"signal"`
+			}
+			err := json.Unmarshal([]byte(got), &signal)
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("extractJSON(%q) = %q, 期望提取失败", tc.content, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractJSON(%q) = %q, 解析失败: %v", tc.content, got, err)
+			}
+			if signal.Signal != tc.want {
+				t.Fatalf("signal = %q, 期望 %q", signal.Signal, tc.want)
+			}
+		})
+	}
+}
+
+// 流式回复逐段到达时，只有收到完整的对象后才能提取成功
+func TestExtractJSONPartial(t *testing.T) {
+	full := "```json\n{\"signal\": \"BUY\", \"confidence\": \"HIGH\", \"reason\": \"突破{关键}阻力\", \"stop_loss\": 95000}\n```"
+	end := len("```json\n{\"signal\": \"BUY\", \"confidence\": \"HIGH\", \"reason\": \"突破{关键}阻力\", \"stop_loss\": 95000}")
+	for i := 1; i < end; i++ {
+		if got := extractJSON(full[:i]); json.Valid([]byte(got)) {
+			t.Fatalf("前 %d 字节提取出了有效对象: %q", i, got)
+		}
+	}
+	if got := extractJSON(full); !json.Valid([]byte(got)) {
+		t.Fatalf("完整回复提取失败: %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+package ai
+
+import (
+	"context"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// FallbackProvider AI服务备用包装 - 主服务请求失败（错误、超时）或回复无法解析时改用备用服务，
+// 两者都失败时返回主服务的结果（错误或 HOLD 备用信号）
+// 会话上下文由各服务分别维护，GetSessionInfo 和 Model 返回主服务的信息
+type FallbackProvider struct {
+	Provider
+	fallback Provider
+}
+
+// NewFallbackProvider 创建带备用服务的AI客户端
+func NewFallbackProvider(primary, fallback Provider) *FallbackProvider {
+	return &FallbackProvider{Provider: primary, fallback: fallback}
+}
+
+// AnalyzeMarket 分析市场并生成交易信号（主服务失败时使用备用服务）
+func (p *FallbackProvider) AnalyzeMarket(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	signal, err := p.Provider.AnalyzeMarket(ctx, tradingPair, marketData, currentPosition, symbolA, usdtBalance)
+	if !p.failed(ctx, tradingPair, signal, err) {
+		return signal, nil
+	}
+	backup, backupErr := p.fallback.AnalyzeMarket(ctx, tradingPair, marketData, currentPosition, symbolA, usdtBalance)
+	return p.choose(tradingPair, signal, err, backup, backupErr)
+}
+
+// AnalyzeSnapshot 分析市场数据快照（主服务失败时使用备用服务）
+func (p *FallbackProvider) AnalyzeSnapshot(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	signal, err := p.Provider.AnalyzeSnapshot(ctx, tradingPair, marketData, currentPosition, signalHistory, symbolA, usdtBalance)
+	if !p.failed(ctx, tradingPair, signal, err) {
+		return signal, nil
+	}
+	backup, backupErr := p.fallback.AnalyzeSnapshot(ctx, tradingPair, marketData, currentPosition, signalHistory, symbolA, usdtBalance)
+	return p.choose(tradingPair, signal, err, backup, backupErr)
+}
+
+// AskExitOpinion 询问是否提前离场（主服务失败时使用备用服务）
+func (p *FallbackProvider) AskExitOpinion(ctx context.Context, tradingPair string, pos *models.Position, currentPrice, stopPrice float64) (*models.ExitOpinion, error) {
+	opinion, err := p.Provider.AskExitOpinion(ctx, tradingPair, pos, currentPrice, stopPrice)
+	if err == nil || ctx.Err() != nil {
+		return opinion, err
+	}
+	logger.Warnf("[%s] AI服务 %s 离场询问失败，改用备用服务 %s: %v", tradingPair, p.Provider.Model(), p.fallback.Model(), err)
+	return p.fallback.AskExitOpinion(ctx, tradingPair, pos, currentPrice, stopPrice)
+}
+
+// WritePostMortem 撰写交易复盘（主服务失败时使用备用服务）
+func (p *FallbackProvider) WritePostMortem(ctx context.Context, review *models.TradeReview) (*models.PostMortem, error) {
+	postMortem, err := p.Provider.WritePostMortem(ctx, review)
+	if err == nil || ctx.Err() != nil {
+		return postMortem, err
+	}
+	logger.Warnf("[%s] AI服务 %s 撰写复盘失败，改用备用服务 %s: %v", review.TradingPair, p.Provider.Model(), p.fallback.Model(), err)
+	return p.fallback.WritePostMortem(ctx, review)
+}
+
+// SuggestTuning 建议参数调整（主服务失败时使用备用服务）
+func (p *FallbackProvider) SuggestTuning(ctx context.Context, review *models.TuningReview) (*models.TuningAdvice, error) {
+	advice, err := p.Provider.SuggestTuning(ctx, review)
+	if err == nil || ctx.Err() != nil {
+		return advice, err
+	}
+	logger.Warnf("[%s] AI服务 %s 参数调优建议失败，改用备用服务 %s: %v", review.TradingPair, p.Provider.Model(), p.fallback.Model(), err)
+	return p.fallback.SuggestTuning(ctx, review)
+}
+
+// failed 主服务是否失败（请求错误或只得到备用信号）且需要改用备用服务，失败时记录日志（ctx 已取消时不再重试）
+func (p *FallbackProvider) failed(ctx context.Context, tradingPair string, signal *models.TradeSignal, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		logger.Warnf("[%s] AI服务 %s 请求失败，改用备用服务 %s: %v", tradingPair, p.Provider.Model(), p.fallback.Model(), err)
+		return true
+	}
+	if signal.IsFallback {
+		logger.Warnf("[%s] AI服务 %s 回复无法解析，改用备用服务 %s", tradingPair, p.Provider.Model(), p.fallback.Model())
+		return true
+	}
+	return false
+}
+
+// choose 备用服务成功时使用其信号，否则返回主服务的结果
+func (p *FallbackProvider) choose(tradingPair string, signal *models.TradeSignal, err error, backup *models.TradeSignal, backupErr error) (*models.TradeSignal, error) {
+	if backupErr == nil && !backup.IsFallback {
+		logger.Printf("[%s] 信号由备用AI服务 %s 生成", tradingPair, backup.Provider)
+		return backup, nil
+	}
+	if backupErr != nil {
+		logger.Warnf("[%s] 备用AI服务 %s 请求失败: %v", tradingPair, p.fallback.Model(), backupErr)
+	}
+	if err != nil && backupErr == nil {
+		return backup, nil // 主服务请求失败、备用服务回复无法解析：使用 HOLD 备用信号
+	}
+	return signal, err
+}
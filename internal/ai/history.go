@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"dsbot/internal/models"
+)
+
+// DefaultHistorySignals 提示词中逐条列出的最近信号数默认值
+const DefaultHistorySignals = 1
+
+// splitHistory 把信号历史分为逐条列出的最近 window 条和更早的信号（均从旧到新）
+func splitHistory(signalHistory []models.TradeSignal, window int) (older, recent []models.TradeSignal) {
+	if window <= 0 || len(signalHistory) <= window {
+		return nil, signalHistory
+	}
+	split := len(signalHistory) - window
+	return signalHistory[:split], signalHistory[split:]
+}
+
+// historyPromptText 提示词中的历史信号段落（含标题）
+// 只列一条时保持「上次交易信号」格式；summary 为更早信号的摘要（为空不输出）
+func historyPromptText(recent []models.TradeSignal, summary string) string {
+	var b strings.Builder
+	switch len(recent) {
+	case 0:
+		b.WriteString("【上次交易信号】\n- 首次分析, 无历史信号")
+	case 1:
+		last := recent[0]
+		fmt.Fprintf(&b, "【上次交易信号】\n\n信号: %s\n信心: %s\n理由: %s", last.Signal, last.Confidence, last.Reason)
+	default:
+		fmt.Fprintf(&b, "【最近%d次交易信号】（从旧到新）", len(recent))
+		for i, signal := range recent {
+			fmt.Fprintf(&b, "\n%d. %s %s (%s) %s", i+1, signal.Timestamp, signal.Signal, signal.Confidence, signal.Reason)
+		}
+	}
+	if summary != "" {
+		b.WriteString("\n\n【更早信号摘要】\n")
+		b.WriteString(summary)
+	}
+	return b.String()
+}
+
+// summarizeSignals 把更早的信号浓缩为一段摘要（信号分布、信心、方向切换和最近的开仓方向），控制提示词长度
+func summarizeSignals(signals []models.TradeSignal) string {
+	if len(signals) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	var highConfidence, switches int
+	var lastDirection, lastDirectionTime string
+	for _, signal := range signals {
+		counts[signal.Signal]++
+		if signal.Confidence == "HIGH" {
+			highConfidence++
+		}
+		if signal.Signal == "BUY" || signal.Signal == "SELL" {
+			if lastDirection != "" && signal.Signal != lastDirection {
+				switches++
+			}
+			lastDirection, lastDirectionTime = signal.Signal, signal.Timestamp
+		}
+	}
+
+	dominant := "HOLD"
+	for _, s := range []string{"BUY", "SELL"} {
+		if counts[s] > counts[dominant] {
+			dominant = s
+		}
+	}
+
+	summary := fmt.Sprintf("此前 %d 次信号（%s 至 %s）: BUY %d 次、SELL %d 次、HOLD %d 次，以 %s 为主，其中高信心 %d 次",
+		len(signals), signals[0].Timestamp, signals[len(signals)-1].Timestamp,
+		counts["BUY"], counts["SELL"], counts["HOLD"], dominant, highConfidence)
+	if lastDirection == "" {
+		return summary + "；期间未出现开仓方向信号。"
+	}
+	return summary + fmt.Sprintf("；多空方向切换 %d 次，最近一次方向信号为 %s（%s）。", switches, lastDirection, lastDirectionTime)
+}
@@ -0,0 +1,34 @@
+package ai
+
+import (
+	"dsbot/internal/config"
+)
+
+// OpenAI 默认配置
+const (
+	DefaultOpenAIModel   = "gpt-4o"
+	DefaultOpenAIBaseURL = "https://api.openai.com"
+)
+
+// OpenAIClient OpenAI客户端（GPT-4o/GPT-4.1 等）
+// 接口与 DeepSeek 同为 Chat Completions 格式，复用其提示词、会话上下文和信号解析，仅凭证、地址和模型不同
+type OpenAIClient struct {
+	*DeepSeekClient
+}
+
+// NewOpenAIClient 创建OpenAI客户端（失败时返回nil）
+func NewOpenAIClient(cfg *config.APIConfig) *OpenAIClient {
+	model := cfg.OpenAIModel
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
+	baseURL := cfg.OpenAIBaseURL
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	client := newChatClient("OpenAI", cfg.OpenAIAPIKey, baseURL, model, cfg)
+	if client == nil {
+		return nil
+	}
+	return &OpenAIClient{DeepSeekClient: client}
+}
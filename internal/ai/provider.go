@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// AI服务类型
+const (
+	ProviderDeepSeek = "deepseek" // DeepSeek（默认）
+	ProviderOpenAI   = "openai"   // OpenAI（GPT-4o/GPT-4.1 等）
+)
+
+// Provider AI服务接口 - 策略层只依赖该接口，可通过配置切换不同的大模型后端
+// 各方法的 ctx 取消时（如进程退出、调度器重启）中止进行中的请求
+type Provider interface {
+	// AnalyzeMarket 分析市场并生成交易信号（读写该交易对的会话上下文）
+	AnalyzeMarket(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error)
+
+	// AnalyzeSnapshot 按给定的历史信号分析一份市场数据快照，不读写会话上下文（用于提示词回测）
+	AnalyzeSnapshot(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, signalHistory []models.TradeSignal, symbolA string, usdtBalance float64) (*models.TradeSignal, error)
+
+	// AskExitOpinion 持仓接近止损时询问是否提前离场（简短提示词，不使用会话历史）
+	AskExitOpinion(ctx context.Context, tradingPair string, pos *models.Position, currentPrice, stopPrice float64) (*models.ExitOpinion, error)
+
+	// WritePostMortem 按已平仓交易的开平仓信息和持仓期间行情撰写简短复盘（不使用会话历史）
+	WritePostMortem(ctx context.Context, review *models.TradeReview) (*models.PostMortem, error)
+
+	// SuggestTuning 按近期交易表现和市场状态建议技术指标参数调整（不使用会话历史）
+	SuggestTuning(ctx context.Context, review *models.TuningReview) (*models.TuningAdvice, error)
+
+	// GetSessionInfo 获取交易对的会话上下文
+	GetSessionInfo(tradingPair string) *models.SessionContext
+
+	// Model 当前使用的模型
+	Model() string
+}
+
+// NewProvider AI服务工厂函数 - 根据配置创建对应的AI客户端
+// 启用多模型共识时创建 ConsensusProvider，配置了备用服务时包装为 FallbackProvider；
+// ai.model 只覆盖主服务的模型，备用服务使用其自身的模型配置
+func NewProvider(cfg *config.APIConfig) (Provider, error) {
+	if cfg.AIConsensus.Enable {
+		return newConsensusProvider(cfg)
+	}
+
+	primary, err := newProvider(withModel(cfg, cfg.AIProvider, cfg.AI.Model), cfg.AIProvider)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AIFallbackProvider == "" {
+		return primary, nil
+	}
+
+	fallback, err := newProvider(cfg, cfg.AIFallbackProvider)
+	if err != nil {
+		return nil, fmt.Errorf("创建备用AI服务失败: %w", err)
+	}
+	return NewFallbackProvider(primary, fallback), nil
+}
+
+// withModel 返回指定服务的模型被覆盖后的配置副本（model 为空时返回原配置）
+func withModel(cfg *config.APIConfig, provider, model string) *config.APIConfig {
+	if model == "" {
+		return cfg
+	}
+	overridden := *cfg
+	if provider == ProviderOpenAI {
+		overridden.OpenAIModel = model
+	} else {
+		overridden.DeepSeekModel = model
+	}
+	return &overridden
+}
+
+// newProvider 按服务类型创建AI客户端
+func newProvider(cfg *config.APIConfig, provider string) (Provider, error) {
+	switch provider {
+	case "", ProviderDeepSeek:
+		client := NewDeepSeekClient(cfg)
+		if client == nil {
+			return nil, fmt.Errorf("创建DeepSeek客户端失败")
+		}
+		return client, nil
+
+	case ProviderOpenAI:
+		client := NewOpenAIClient(cfg)
+		if client == nil {
+			return nil, fmt.Errorf("创建OpenAI客户端失败")
+		}
+		return client, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的AI服务: %s (支持: %s, %s)", provider, ProviderDeepSeek, ProviderOpenAI)
+	}
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"dsbot/internal/config"
+)
+
+// 多个交易对并发分析时会话上下文互不干扰（配合 go test -race 检查数据竞争）
+func TestAnalyzeMarketConcurrentPairs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices": [{"message": {"content": "{\"signal\": \"BUY\", \"confidence\": \"HIGH\", \"reason\": \"x\"}"}}]}`)
+	}))
+	defer server.Close()
+
+	c := NewDeepSeekClient(&config.APIConfig{DeepSeekBaseURL: server.URL})
+	marketData := benchmarkMarketData(100)
+	pairs := []string{"BTC-USDT", "ETH-USDT", "SOL-USDT", "DOGE-USDT"}
+	const rounds = 40
+
+	var wg sync.WaitGroup
+	for _, pair := range pairs {
+		for i := 0; i < rounds; i++ {
+			wg.Add(2)
+			go func(pair string) {
+				defer wg.Done()
+				if _, err := c.AnalyzeMarket(context.Background(), pair, marketData, nil, "BTC", 1000); err != nil {
+					t.Errorf("[%s] 分析失败: %v", pair, err)
+				}
+			}(pair)
+			go func(pair string) {
+				defer wg.Done()
+				if session := c.GetSessionInfo(pair); session != nil {
+					stats := session.StatsSnapshot()
+					_ = stats.FormatStats()
+					_ = session.History()
+				}
+			}(pair)
+		}
+	}
+	wg.Wait()
+
+	for _, pair := range pairs {
+		session := c.GetSessionInfo(pair)
+		if session == nil {
+			t.Fatalf("[%s] 会话上下文不存在", pair)
+		}
+		if stats := session.StatsSnapshot(); stats.Total != rounds || stats.BuyCount != rounds {
+			t.Errorf("[%s] 信号统计 %+v，期望 %d 次 BUY", pair, stats, rounds)
+		}
+		if n := len(session.History()); n != maxSessionHistory {
+			t.Errorf("[%s] 历史信号数 %d，期望 %d", pair, n, maxSessionHistory)
+		}
+		for _, signal := range session.History() {
+			if signal.TradingPair != pair {
+				t.Errorf("[%s] 会话中混入了 %s 的信号", pair, signal.TradingPair)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+// DefaultStallTimeout 流式回复的默认停顿超时（超过该时间未收到新数据视为服务停滞）
+const DefaultStallTimeout = 15 * time.Second
+
+// ChatStreamChunk 流式响应数据块（SSE 每行 "data: {...}"，以 "data: [DONE]" 结束）
+type ChatStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // 推理过程（DeepSeek 推理模型）
+			Reasoning        string `json:"reasoning"`         // 推理过程（部分兼容网关使用该字段）
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// chatStream 以流式方式调用聊天接口，边接收边检查回复，收到完整的JSON对象后立即返回
+// 总耗时受 timeout_seconds 限制；超过停顿超时未收到新数据时中止请求，并记录已收到的部分回复
+// 推理模型先输出推理过程再输出最终回复，推理过程单独累积，不参与JSON提取
+func (c *DeepSeekClient) chatStream(ctx context.Context, requestBody []byte, headers map[string]string) (string, string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stalled atomic.Bool
+	stallTimer := time.AfterFunc(c.stallTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer stallTimer.Stop()
+
+	var content, reasoning strings.Builder
+	var finished bool
+	start := time.Now()
+	err := c.httpClient.QueryStream(ctx, c.baseURL+"/v1/chat/completions", headers, requestBody, func(line string) bool {
+		stallTimer.Reset(c.stallTimeout)
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		if !ok {
+			return true // 空行和注释行（心跳）
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			finished = true
+			return false
+		}
+
+		var chunk ChatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			logger.Debugf("%s流式数据块解析失败: %v", c.name, err)
+			return true
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+			reasoning.WriteString(choice.Delta.ReasoningContent)
+			reasoning.WriteString(choice.Delta.Reasoning)
+			if choice.FinishReason != "" {
+				finished = true
+			}
+		}
+		// 回复中已有完整的JSON对象时不再等待剩余内容
+		if strings.Contains(content.String(), "}") && json.Valid([]byte(extractJSON(content.String()))) {
+			finished = true
+		}
+		return !finished
+	})
+
+	if stalled.Load() {
+		err = fmt.Errorf("%s流式回复停滞超过 %v", c.name, c.stallTimeout)
+	}
+	if err != nil || !finished {
+		if err == nil {
+			err = fmt.Errorf("%s流式回复提前结束", c.name)
+		}
+		logger.Warnf("%s流式回复中断（耗时 %v，已接收 %d 字符，推理过程 %d 字符）: %v，部分回复: %s",
+			c.name, time.Since(start).Round(time.Millisecond), content.Len(), reasoning.Len(), err, content.String())
+		return "", reasoning.String(), err
+	}
+
+	if content.Len() == 0 {
+		if reasoning.Len() > 0 {
+			return "", reasoning.String(), fmt.Errorf("%s只返回了推理过程，没有最终回复（可能达到 max_tokens 上限）", c.name)
+		}
+		return "", "", fmt.Errorf("%s返回空响应", c.name)
+	}
+	logger.Debugf("%s流式回复完成，耗时 %v", c.name, time.Since(start).Round(time.Millisecond))
+	return content.String(), reasoning.String(), nil
+}
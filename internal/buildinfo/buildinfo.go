@@ -0,0 +1,128 @@
+// Package buildinfo 版本信息与启动记录
+// 启动时生成包含版本、提交、配置哈希和已启用功能的启动记录，输出到日志和 /status 接口，
+// 并将版本标记写入交易日志，使每笔交易都能追溯到具体的构建和配置
+package buildinfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+// Version 发布版本（构建时通过 -ldflags "-X dsbot/internal/buildinfo.Version=v1.0.0" 设置）
+var Version = "dev"
+
+// Commit 构建时的 git 提交（取自 Go 工具链写入的 VCS 信息，工作区有未提交修改时带 -dirty 后缀，无法获取时为 unknown）
+func Commit() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "unknown"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// ConfigHash 生效配置（含环境变量覆盖）的哈希，取 SHA-256 前12位
+func ConfigHash(cfg interface{}) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "unknown"
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Stamp 版本标记（写入每条交易日志）
+type Stamp struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	ConfigHash string `json:"config_hash"`
+}
+
+// PairInfo 交易对及其路由
+type PairInfo struct {
+	TradingPair string `json:"trading_pair"`
+	Exchange    string `json:"exchange"`              // 下单交易所
+	DataSource  string `json:"data_source"`           // 行情来源
+	Testnet     bool   `json:"testnet,omitempty"`     // 是否连接模拟盘/测试网
+	SubAccount  string `json:"sub_account,omitempty"` // 子账户
+}
+
+// StartupRecord 启动记录
+type StartupRecord struct {
+	Stamp
+	GoVersion   string     `json:"go_version"`
+	StartedAt   time.Time  `json:"started_at"`
+	TestMode    bool       `json:"test_mode"`
+	TradingMode string     `json:"trading_mode"`
+	Timeframe   string     `json:"timeframe"`
+	Leverage    int        `json:"leverage"`
+	Amount      float64    `json:"amount"`
+	Interval    int        `json:"schedule_interval_minutes"`
+	Pairs       []PairInfo `json:"pairs"`
+	Features    []string   `json:"features"` // 已启用的功能（按配置项名称）
+}
+
+// NewStartupRecord 创建启动记录（版本、提交、配置哈希和Go版本自动填充）
+func NewStartupRecord(cfg interface{}) *StartupRecord {
+	return &StartupRecord{
+		Stamp: Stamp{
+			Version:    Version,
+			Commit:     Commit(),
+			ConfigHash: ConfigHash(cfg),
+		},
+		GoVersion: runtime.Version(),
+		StartedAt: time.Now(),
+	}
+}
+
+// Log 输出启动记录（每项一行 key=value，另输出一行完整JSON便于日志采集）
+func (r *StartupRecord) Log() {
+	logger.Println("============================================================")
+	logger.Printf("[启动] version=%s commit=%s config_hash=%s go=%s", r.Version, r.Commit, r.ConfigHash, r.GoVersion)
+	logger.Printf("[启动] test_mode=%t trading_mode=%s timeframe=%s leverage=%dx amount=%.8f interval=%dm",
+		r.TestMode, r.TradingMode, r.Timeframe, r.Leverage, r.Amount, r.Interval)
+	for _, pair := range r.Pairs {
+		line := "[启动] pair=" + pair.TradingPair + " exchange=" + pair.Exchange + " data=" + pair.DataSource
+		if pair.Testnet {
+			line += " testnet=true"
+		}
+		if pair.SubAccount != "" {
+			line += " sub_account=" + pair.SubAccount
+		}
+		logger.Println(line)
+	}
+	features := "-"
+	if len(r.Features) > 0 {
+		features = strings.Join(r.Features, ",")
+	}
+	logger.Printf("[启动] features=%s", features)
+	if data, err := json.Marshal(r); err == nil {
+		logger.Printf("[启动] record=%s", data)
+	}
+	logger.Println("============================================================")
+}
@@ -0,0 +1,216 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+const (
+	DefaultTimezone     = "UTC"
+	DefaultRolloverTime = "00:00"
+	DefaultStateFile    = "data/calendar.json"
+
+	dayFormat = "2006-01-02"
+)
+
+// RolloverHandler 日切回调（prevDay: 上一个交易日, currentDay: 新交易日）
+type RolloverHandler func(prevDay, currentDay string)
+
+// State 日历持久化状态
+type State struct {
+	CurrentDay   string    `json:"current_day"`   // 当前交易日 (2006-01-02)
+	DayStart     time.Time `json:"day_start"`     // 当前交易日开始时间
+	LastRollover time.Time `json:"last_rollover"` // 最近一次日切时间
+}
+
+// Calendar 交易日历 - 为所有每日统计（日亏损熔断、日报等）提供统一的日界线
+// 日界线由时区+日切时间决定，状态持久化到文件，重启后可识别跨日
+type Calendar struct {
+	location       *time.Location
+	rolloverHour   int
+	rolloverMinute int
+	stateFile      string
+	state          State
+	handlers       []RolloverHandler
+	mu             sync.Mutex
+}
+
+// NewCalendar 创建交易日历，并从状态文件恢复
+func NewCalendar(cfg *config.CalendarConfig) (*Calendar, error) {
+	timezone := cfg.Timezone
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("加载时区失败: %w", err)
+	}
+
+	rolloverTime := cfg.RolloverTime
+	if rolloverTime == "" {
+		rolloverTime = DefaultRolloverTime
+	}
+	hour, minute, err := parseRolloverTime(rolloverTime)
+	if err != nil {
+		return nil, err
+	}
+
+	stateFile := cfg.StateFile
+	if stateFile == "" {
+		stateFile = DefaultStateFile
+	}
+
+	c := &Calendar{
+		location:       location,
+		rolloverHour:   hour,
+		rolloverMinute: minute,
+		stateFile:      stateFile,
+	}
+
+	if err := c.load(); err != nil {
+		logger.Warnf("[交易日历] 读取状态文件失败，将重新初始化: %v", err)
+	}
+
+	return c, nil
+}
+
+// parseRolloverTime 解析 HH:MM 格式的日切时间
+func parseRolloverTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("日切时间格式错误 (应为 HH:MM): %s", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// OnRollover 注册日切回调
+func (c *Calendar) OnRollover(handler RolloverHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// DayStart 返回时间 t 所属交易日的开始时间
+func (c *Calendar) DayStart(t time.Time) time.Time {
+	local := t.In(c.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(),
+		c.rolloverHour, c.rolloverMinute, 0, 0, c.location)
+	if local.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// TradingDay 返回时间 t 所属的交易日标识 (2006-01-02，以交易日开始的日期为准)
+func (c *Calendar) TradingDay(t time.Time) string {
+	return c.DayStart(t).Format(dayFormat)
+}
+
+// NextRollover 返回时间 t 之后的下一次日切时间
+func (c *Calendar) NextRollover(t time.Time) time.Time {
+	return c.DayStart(t).AddDate(0, 0, 1)
+}
+
+// SameDay 判断两个时间是否属于同一交易日
+func (c *Calendar) SameDay(a, b time.Time) bool {
+	return c.TradingDay(a) == c.TradingDay(b)
+}
+
+// CurrentDay 返回当前交易日标识（会先检查是否需要日切）
+func (c *Calendar) CurrentDay() string {
+	c.CheckRollover()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.CurrentDay
+}
+
+// CheckRollover 检查是否跨越日界线，跨日时持久化状态并触发回调
+// 返回值: 是否发生日切
+func (c *Calendar) CheckRollover() bool {
+	now := time.Now()
+	today := c.TradingDay(now)
+
+	c.mu.Lock()
+	prevDay := c.state.CurrentDay
+	if prevDay == today {
+		c.mu.Unlock()
+		return false
+	}
+
+	c.state.CurrentDay = today
+	c.state.DayStart = c.DayStart(now)
+	c.state.LastRollover = now
+	handlers := append([]RolloverHandler(nil), c.handlers...)
+	if err := c.saveLocked(); err != nil {
+		logger.Warnf("[交易日历] 保存状态失败: %v", err)
+	}
+	c.mu.Unlock()
+
+	if prevDay == "" {
+		logger.Printf("[交易日历] 初始化交易日: %s (日切时间 %02d:%02d %s)",
+			today, c.rolloverHour, c.rolloverMinute, c.location)
+		return false
+	}
+
+	logger.Printf("[交易日历] 日切: %s -> %s", prevDay, today)
+	for _, handler := range handlers {
+		handler(prevDay, today)
+	}
+	return true
+}
+
+// GetState 获取日历状态快照
+func (c *Calendar) GetState() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// load 从状态文件恢复
+func (c *Calendar) load() error {
+	data, err := os.ReadFile(c.stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	c.state = state
+	logger.Printf("[交易日历] 已恢复状态 - 交易日: %s, 最近日切: %s",
+		state.CurrentDay, state.LastRollover.Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// saveLocked 保存状态到文件（调用方需持有锁）
+func (c *Calendar) saveLocked() error {
+	if dir := filepath.Dir(c.stateFile); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(c.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile := c.stateFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, c.stateFile)
+}
@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+)
+
+func TestTradingDay(t *testing.T) {
+	c, err := NewCalendar(&config.CalendarConfig{
+		Timezone: "Asia/Shanghai", RolloverTime: "08:00", StateFile: filepath.Join(t.TempDir(), "calendar.json"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantDay   string
+		wantStart time.Time
+	}{
+		{name: "日切前属于前一交易日", at: time.Date(2026, 3, 10, 7, 59, 0, 0, shanghai),
+			wantDay: "2026-03-09", wantStart: time.Date(2026, 3, 9, 8, 0, 0, 0, shanghai)},
+		{name: "日切时刻属于新交易日", at: time.Date(2026, 3, 10, 8, 0, 0, 0, shanghai),
+			wantDay: "2026-03-10", wantStart: time.Date(2026, 3, 10, 8, 0, 0, 0, shanghai)},
+		{name: "按配置时区换算UTC时间", at: time.Date(2026, 3, 11, 0, 30, 0, 0, time.UTC),
+			wantDay: "2026-03-11", wantStart: time.Date(2026, 3, 11, 8, 0, 0, 0, shanghai)},
+		{name: "跨月", at: time.Date(2026, 3, 1, 0, 30, 0, 0, shanghai),
+			wantDay: "2026-02-28", wantStart: time.Date(2026, 2, 28, 8, 0, 0, 0, shanghai)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.TradingDay(tt.at); got != tt.wantDay {
+				t.Fatalf("交易日 = %s, 期望 %s", got, tt.wantDay)
+			}
+			if got := c.DayStart(tt.at); !got.Equal(tt.wantStart) {
+				t.Fatalf("交易日开始 = %s, 期望 %s", got, tt.wantStart)
+			}
+			if got := c.NextRollover(tt.at); !got.Equal(tt.wantStart.AddDate(0, 0, 1)) {
+				t.Fatalf("下次日切 = %s, 期望 %s", got, tt.wantStart.AddDate(0, 0, 1))
+			}
+		})
+	}
+
+	if !c.SameDay(time.Date(2026, 3, 10, 9, 0, 0, 0, shanghai), time.Date(2026, 3, 11, 7, 0, 0, 0, shanghai)) {
+		t.Fatal("日切时间前后的同一交易日被判断为不同交易日")
+	}
+}
+
+func TestNewCalendarInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.CalendarConfig
+	}{
+		{name: "时区不存在", cfg: config.CalendarConfig{Timezone: "Mars/Olympus"}},
+		{name: "日切时间格式错误", cfg: config.CalendarConfig{RolloverTime: "8点"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.StateFile = filepath.Join(t.TempDir(), "calendar.json")
+			if _, err := NewCalendar(&tt.cfg); err == nil {
+				t.Fatal("创建成功, 期望报错")
+			}
+		})
+	}
+}
+
+// 重启后从状态文件识别跨日：停机期间跨过日界线时触发一次日切回调，状态写回文件
+func TestCheckRolloverAfterRestart(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "calendar.json")
+	data, err := json.Marshal(State{CurrentDay: "2026-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stateFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCalendar(&config.CalendarConfig{StateFile: stateFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rollovers []string
+	c.OnRollover(func(prevDay, currentDay string) { rollovers = append(rollovers, prevDay+"->"+currentDay) })
+
+	today := c.TradingDay(time.Now())
+	if !c.CheckRollover() {
+		t.Fatal("停机期间跨日, 期望发生日切")
+	}
+	if c.CheckRollover() {
+		t.Fatal("同一交易日内重复日切")
+	}
+	if len(rollovers) != 1 || rollovers[0] != "2026-01-01->"+today {
+		t.Fatalf("日切回调 = %v, 期望 2026-01-01->%s", rollovers, today)
+	}
+
+	restarted, err := NewCalendar(&config.CalendarConfig{StateFile: stateFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.GetState().CurrentDay; got != today {
+		t.Fatalf("重启后交易日 = %s, 期望 %s", got, today)
+	}
+	if restarted.CheckRollover() {
+		t.Fatal("重启后同一交易日内发生日切")
+	}
+}
+
+// 首次启动（无状态文件）只初始化交易日，不触发日切回调
+func TestCheckRolloverFirstStart(t *testing.T) {
+	c, err := NewCalendar(&config.CalendarConfig{StateFile: filepath.Join(t.TempDir(), "calendar.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := false
+	c.OnRollover(func(prevDay, currentDay string) { called = true })
+
+	if c.CheckRollover() || called {
+		t.Fatal("首次启动触发了日切")
+	}
+	if got, want := c.CurrentDay(), c.TradingDay(time.Now()); got != want {
+		t.Fatalf("交易日 = %s, 期望 %s", got, want)
+	}
+}
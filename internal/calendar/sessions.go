@@ -0,0 +1,135 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dsbot/internal/config"
+)
+
+// weekdayNames 配置中的星期名称
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// window 每日时段（自零点起的分钟数，end 小于 start 时跨午夜）
+type window struct {
+	start, end int
+	text       string
+}
+
+// contains 判断自零点起的分钟数是否位于时段内（含开始，不含结束）
+func (w window) contains(minute int) bool {
+	if w.start <= w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// Sessions 交易时段 - 允许开仓的每日时段和星期，以及禁止开仓的时间段
+type Sessions struct {
+	location  *time.Location
+	windows   []window
+	weekdays  map[time.Weekday]bool
+	blackouts []config.BlackoutConfig
+}
+
+// NewSessions 创建交易时段（timezone 为空时使用 defaultTimezone，再为空时使用 UTC）
+func NewSessions(cfg *config.TradingSessionsConfig, defaultTimezone string) (*Sessions, error) {
+	timezone := cfg.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("加载时区失败: %w", err)
+	}
+
+	s := &Sessions{location: location, blackouts: cfg.Blackouts}
+	for _, text := range cfg.Windows {
+		w, err := parseWindow(text)
+		if err != nil {
+			return nil, err
+		}
+		s.windows = append(s.windows, w)
+	}
+	if len(cfg.Weekdays) > 0 {
+		s.weekdays = make(map[time.Weekday]bool, len(cfg.Weekdays))
+		for _, name := range cfg.Weekdays {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if len(key) > 3 {
+				key = key[:3] // 兼容 monday 等全称
+			}
+			day, ok := weekdayNames[key]
+			if !ok {
+				return nil, fmt.Errorf("星期格式错误 (应为 mon ~ sun): %s", name)
+			}
+			s.weekdays[day] = true
+		}
+	}
+	return s, nil
+}
+
+// parseWindow 解析 HH:MM-HH:MM 格式的每日时段
+func parseWindow(text string) (window, error) {
+	parts := strings.Split(strings.TrimSpace(text), "-")
+	if len(parts) != 2 {
+		return window{}, fmt.Errorf("时段格式错误 (应为 HH:MM-HH:MM): %s", text)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(parts[0]))
+	if err != nil {
+		return window{}, fmt.Errorf("时段格式错误 (应为 HH:MM-HH:MM): %s", text)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(parts[1]))
+	if err != nil {
+		return window{}, fmt.Errorf("时段格式错误 (应为 HH:MM-HH:MM): %s", text)
+	}
+	w := window{start: start.Hour()*60 + start.Minute(), end: end.Hour()*60 + end.Minute(), text: strings.TrimSpace(text)}
+	if w.start == w.end {
+		return window{}, fmt.Errorf("时段开始和结束时间相同: %s", text)
+	}
+	return w, nil
+}
+
+// Check 判断时间 t 是否允许开仓，不允许时返回原因
+func (s *Sessions) Check(t time.Time) (bool, string) {
+	for _, b := range s.blackouts {
+		if !t.Before(b.Start) && t.Before(b.End) {
+			reason := b.Reason
+			if reason == "" {
+				reason = "禁止开仓时间段"
+			}
+			return false, fmt.Sprintf("%s (%s ~ %s)", reason,
+				b.Start.In(s.location).Format("01-02 15:04"), b.End.In(s.location).Format("01-02 15:04 MST"))
+		}
+	}
+
+	local := t.In(s.location)
+	if s.weekdays != nil && !s.weekdays[local.Weekday()] {
+		return false, fmt.Sprintf("%s 不在允许开仓的星期内", local.Weekday())
+	}
+	if len(s.windows) == 0 {
+		return true, ""
+	}
+	minute := local.Hour()*60 + local.Minute()
+	for _, w := range s.windows {
+		if w.contains(minute) {
+			return true, ""
+		}
+	}
+	texts := make([]string, len(s.windows))
+	for i, w := range s.windows {
+		texts[i] = w.text
+	}
+	return false, fmt.Sprintf("%s 不在允许开仓的时段内 (%s %s)", local.Format("15:04"), strings.Join(texts, ", "), s.location)
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+)
+
+func TestSessionsCheck(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fomc := config.BlackoutConfig{
+		Start:  time.Date(2026, 3, 18, 18, 0, 0, 0, time.UTC),
+		End:    time.Date(2026, 3, 18, 19, 0, 0, 0, time.UTC),
+		Reason: "FOMC 议息会议",
+	}
+	cfg := &config.TradingSessionsConfig{
+		Windows:   []string{"09:30-16:00", "22:00-02:00"},
+		Weekdays:  []string{"mon", "Tuesday", "wed", "thu", "fri"},
+		Blackouts: []config.BlackoutConfig{fomc},
+	}
+	s, err := NewSessions(cfg, "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		at         time.Time
+		want       bool
+		wantReason string
+	}{
+		{name: "时段内", at: time.Date(2026, 3, 17, 10, 0, 0, 0, ny), want: true},
+		{name: "时段开始时刻", at: time.Date(2026, 3, 17, 9, 30, 0, 0, ny), want: true},
+		{name: "时段结束时刻", at: time.Date(2026, 3, 17, 16, 0, 0, 0, ny), wantReason: "不在允许开仓的时段内"},
+		{name: "跨午夜时段午夜前", at: time.Date(2026, 3, 17, 23, 0, 0, 0, ny), want: true},
+		{name: "跨午夜时段午夜后", at: time.Date(2026, 3, 18, 1, 0, 0, 0, ny), want: true},
+		{name: "时段之间", at: time.Date(2026, 3, 17, 18, 0, 0, 0, ny), wantReason: "不在允许开仓的时段内"},
+		{name: "按配置时区判断UTC时间", at: time.Date(2026, 3, 17, 14, 0, 0, 0, time.UTC), want: true},
+		{name: "周末", at: time.Date(2026, 3, 21, 10, 0, 0, 0, ny), wantReason: "不在允许开仓的星期内"},
+		{name: "禁止开仓时间段优先于允许时段", at: time.Date(2026, 3, 18, 18, 30, 0, 0, time.UTC), wantReason: "FOMC 议息会议"},
+		{name: "禁止开仓时间段结束", at: fomc.End, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason := s.Check(tt.at)
+			if allowed != tt.want {
+				t.Fatalf("允许开仓 = %v (%s), 期望 %v", allowed, reason, tt.want)
+			}
+			if !strings.Contains(reason, tt.wantReason) {
+				t.Fatalf("原因 = %q, 期望包含 %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSessionsDefaults(t *testing.T) {
+	s, err := NewSessions(&config.TradingSessionsConfig{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allowed, reason := s.Check(time.Date(2026, 3, 21, 3, 0, 0, 0, time.UTC)); !allowed {
+		t.Fatalf("未配置时段和星期时 = %v (%s), 期望全天允许", allowed, reason)
+	}
+}
+
+func TestNewSessionsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TradingSessionsConfig
+	}{
+		{name: "时段缺少结束时间", cfg: config.TradingSessionsConfig{Windows: []string{"09:30"}}},
+		{name: "时段时间格式错误", cfg: config.TradingSessionsConfig{Windows: []string{"9点-16点"}}},
+		{name: "时段开始和结束相同", cfg: config.TradingSessionsConfig{Windows: []string{"09:30-09:30"}}},
+		{name: "星期格式错误", cfg: config.TradingSessionsConfig{Weekdays: []string{"xyz"}}},
+		{name: "时区不存在", cfg: config.TradingSessionsConfig{Timezone: "Mars/Olympus"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSessions(&tt.cfg, ""); err == nil {
+				t.Fatal("创建成功, 期望报错")
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ExchangeType string
@@ -11,6 +14,10 @@ type ExchangeType string
 const (
 	ExchangeOKX     ExchangeType = "okx" // default
 	ExchangeBinance ExchangeType = "binance"
+	ExchangeKraken  ExchangeType = "kraken"
+	ExchangeGate    ExchangeType = "gate"
+
+	ExchangeHyperliquid ExchangeType = "hyperliquid" // 链上永续合约DEX（钱包私钥签名）
 )
 
 // TradingMode 交易模式
@@ -23,23 +30,288 @@ const (
 
 // Config 全局配置结构
 type Config struct {
-	Trading TradingConfig `json:"trading"`
-	API     APIConfig     `json:"api"`
-	Logging LoggingConfig `json:"logging"`
+	Trading      TradingConfig      `json:"trading"`
+	API          APIConfig          `json:"api"`
+	Logging      LoggingConfig      `json:"logging"`
+	Watchdog     WatchdogConfig     `json:"watchdog"`
+	Notification NotificationConfig `json:"notification"`
+	Storage      StorageConfig      `json:"storage"`
+	Sharding     ShardingConfig     `json:"sharding"`
+	Failover     FailoverConfig     `json:"failover"`
+	Tracing      TracingConfig      `json:"tracing"`
+	ControlAPI   ControlAPIConfig   `json:"control_api"`
+	DataFeeds    DataFeedsConfig    `json:"data_feeds"`
+}
+
+// DataFeedsConfig 外部市场数据源配置（结果缓存后注入AI分析提示词）
+type DataFeedsConfig struct {
+	News      NewsFeedConfig  `json:"news"`       // 新闻标题源
+	FearGreed FearGreedConfig `json:"fear_greed"` // 恐惧贪婪指数
+}
+
+// FearGreedConfig 恐惧贪婪指数配置（alternative.me，每日更新一次）
+type FearGreedConfig struct {
+	Enable         bool `json:"enable"`          // 是否启用
+	RefreshMinutes int  `json:"refresh_minutes"` // 重新获取间隔（分钟，默认60，期间使用缓存）
+}
+
+// NewsFeedConfig 新闻标题源配置
+// 定期拉取 RSS/Atom 订阅，将最近 lookback_hours 小时内的标题作为"市场新闻"加入分析提示词
+type NewsFeedConfig struct {
+	Enable         bool     `json:"enable"`          // 是否启用
+	Feeds          []string `json:"feeds"`           // RSS/Atom 订阅地址
+	Keywords       []string `json:"keywords"`        // 标题关键词（不区分大小写，保留匹配任一关键词或交易对基础币种的标题，为空时保留全部）
+	LookbackHours  int      `json:"lookback_hours"`  // 统计最近多少小时的新闻（默认12）
+	MaxHeadlines   int      `json:"max_headlines"`   // 提示词中最多列出的标题数（默认10）
+	RefreshMinutes int      `json:"refresh_minutes"` // 重新拉取间隔（分钟，默认15，期间使用缓存）
 }
 
 // TradingConfig 交易配置
 type TradingConfig struct {
-	SymbolA                 string               `json:"symbolA"`
-	SymbolB                 string               `json:"symbolB"`
-	Amount                  float64              `json:"amount"` // 交易金额，单位为symbolB（如USDT、USDT）
-	Leverage                int                  `json:"leverage"`
-	Timeframe               string               `json:"timeframe"`
-	TestMode                bool                 `json:"test_mode"`
-	DataPoints              int                  `json:"data_points"`
-	ScheduleIntervalMinutes int                  `json:"schedule_interval_minutes"`
-	TradingMode             string               `json:"trading_mode"`    // "spot" or "futures" (default: futures)
-	RiskManagement          RiskManagementConfig `json:"risk_management"` // 风险管理配置
+	SymbolA                 string                `json:"symbolA"`
+	SymbolB                 string                `json:"symbolB"`
+	Amount                  float64               `json:"amount"`                // 交易金额，单位为symbolB（如USDT、USDT）
+	AmountEquityPercent     float64               `json:"amount_equity_percent"` // 按账户权益百分比计算交易金额（%，>0 时覆盖 amount，获取权益失败时仍使用 amount）
+	Leverage                int                   `json:"leverage"`
+	Timeframe               string                `json:"timeframe"`
+	TestMode                bool                  `json:"test_mode"`
+	DataPoints              int                   `json:"data_points"`
+	ScheduleIntervalMinutes int                   `json:"schedule_interval_minutes"`
+	TradingMode             string                `json:"trading_mode"`        // "spot" or "futures" (default: futures)
+	ReuseCandleSignal       bool                  `json:"reuse_candle_signal"` // 同一根K线内再次执行时复用已有信号，不重复请求AI（重启后同样生效）
+	RiskManagement          RiskManagementConfig  `json:"risk_management"`     // 风险管理配置
+	Calendar                CalendarConfig        `json:"calendar"`            // 交易日历配置
+	Journal                 JournalConfig         `json:"journal"`             // 交易日志配置
+	ExpectancyGate          ExpectancyGateConfig  `json:"expectancy_gate"`     // 期望值过滤配置
+	LiquidityGate           LiquidityGateConfig   `json:"liquidity_gate"`      // 流动性检查配置
+	Embargo                 EmbargoConfig         `json:"embargo"`             // 禁止交易名单配置
+	AdaptiveCadence         AdaptiveCadenceConfig `json:"adaptive_cadence"`    // 自适应执行频率配置
+	PaperTrading            PaperTradingConfig    `json:"paper_trading"`       // 模拟撮合配置
+	StopEntry               StopEntryConfig       `json:"stop_entry"`          // 突破入场配置
+	AISuggestions           AISuggestionsConfig   `json:"ai_suggestions"`      // AI建议止盈止损和仓位配置
+	MultiTimeframe          MultiTimeframeConfig  `json:"multi_timeframe"`     // 多周期分析配置
+	Coach                   CoachConfig           `json:"coach"`               // AI参数调优建议配置
+	PositionSizing          PositionSizingConfig  `json:"position_sizing"`     // 仓位计算配置
+	LossCooldown            LossCooldownConfig    `json:"loss_cooldown"`       // 连续亏损冷却配置
+	Reconcile               ReconcileConfig       `json:"reconcile"`           // 启动核对配置
+	HedgeMode               HedgeModeConfig       `json:"hedge_mode"`          // 双向持仓配置
+	FundingArb              FundingArbConfig      `json:"funding_arb"`         // 资金费率套利配置
+	Basis                   BasisConfig           `json:"basis"`               // 现货-永续合约基差配置
+	Pairs                   []PairConfig          `json:"pairs"`               // 多交易对配置（为空时只交易 symbolA/symbolB）
+
+	SignalConfirmation SignalConfirmationConfig `json:"signal_confirmation"` // 信号确认配置
+	VolatilityFilter   VolatilityFilterConfig   `json:"volatility_filter"`   // 波动率过滤配置
+	TradingSessions    TradingSessionsConfig    `json:"trading_sessions"`    // 交易时段配置
+
+	Execution ExecutionConfig `json:"execution"` // 开仓下单执行方式配置
+}
+
+// PairConfig 交易对及其交易所路由
+// 每个交易对可以在不同的交易所下单，并可从另一个交易所获取行情
+type PairConfig struct {
+	SymbolA   string `json:"symbolA"`
+	SymbolB   string `json:"symbolB"`
+	Venue     string `json:"venue"`      // 下单交易所（api.venues 中的名称或交易所类型，为空使用 api.exchange_type）
+	DataVenue string `json:"data_venue"` // 行情交易所（为空与下单交易所相同，可为 binance 公共行情）
+}
+
+// TradingPair 交易对标识（如 BTC-USDT）
+func (p PairConfig) TradingPair() string {
+	return fmt.Sprintf("%s-%s", p.SymbolA, p.SymbolB)
+}
+
+// PaperTradingConfig 模拟撮合配置（仅测试模式生效）
+// 行情来自真实交易所，下单、持仓和余额由本地模拟交易所撮合，测试模式下也能观察订单对持仓和风控的影响
+type PaperTradingConfig struct {
+	Enable         bool    `json:"enable"`          // 是否启用
+	InitialBalance float64 `json:"initial_balance"` // 初始计价币种余额（默认10000）
+
+	TakerFeePercent float64 `json:"taker_fee_percent"` // 吃单手续费率（%，默认0.05）
+	SlippageBps     float64 `json:"slippage_bps"`      // 市价单滑点（基点，买入按最新价格上浮、卖出下浮成交，0表示无滑点）
+}
+
+// StopEntryConfig 突破入场配置
+// 开仓信号不立即市价入场，等待价格突破近期阻力位（做多）或跌破支撑位（做空）后再入场，超过有效期未触发则放弃
+type StopEntryConfig struct {
+	Enable               bool    `json:"enable"`                 // 是否启用
+	Mode                 string  `json:"mode"`                   // stop: 价格触及触发价即入场（默认） / confirm: K线收盘突破触发价后入场
+	OffsetPercent        float64 `json:"offset_percent"`         // 触发价在阻力/支撑位之外的偏移（%，默认0.1）
+	ExpiryCandles        int     `json:"expiry_candles"`         // 有效期（K线根数，默认3）
+	CheckIntervalSeconds int     `json:"check_interval_seconds"` // 触发检查间隔（秒，默认10）
+}
+
+// AISuggestionsConfig AI建议止盈止损和仓位配置
+// 启用后新开仓按AI信号给出的止损价、止盈价和仓位比例执行（限制在配置范围内），未给出或方向不合理时使用固定百分比
+type AISuggestionsConfig struct {
+	Enable               bool    `json:"enable"`                  // 是否启用
+	MinConfidenceScore   int     `json:"min_confidence_score"`    // 最低信心分数（1-100，低于时不执行，0表示不检查）
+	MinStopLossPercent   float64 `json:"min_stop_loss_percent"`   // 止损距离下限（%，默认 stop_loss_percent 的一半）
+	MaxStopLossPercent   float64 `json:"max_stop_loss_percent"`   // 止损距离上限（%，默认 stop_loss_percent 的2倍）
+	MinTakeProfitPercent float64 `json:"min_take_profit_percent"` // 止盈距离下限（%，默认 take_profit_percent 的一半）
+	MaxTakeProfitPercent float64 `json:"max_take_profit_percent"` // 止盈距离上限（%，默认 take_profit_percent 的2倍）
+	MinSizeFraction      float64 `json:"min_size_fraction"`       // 仓位比例下限（默认0.1，上限为1即满额交易金额）
+}
+
+// CoachConfig AI参数调优建议配置
+// 定期把近期交易表现、市场状态和当前技术指标参数发送给AI，记录参数调整建议，可选在允许范围内自动应用
+type CoachConfig struct {
+	Enable           bool    `json:"enable"`             // 是否启用
+	IntervalHours    int     `json:"interval_hours"`     // 评估间隔（小时，默认24）
+	LookbackTrades   int     `json:"lookback_trades"`    // 参与评估的最近已平仓交易数（默认20）
+	MinTrades        int     `json:"min_trades"`         // 已平仓交易少于该数量时不评估（默认5）
+	AutoApply        bool    `json:"auto_apply"`         // 自动应用建议（限制在参数允许范围内，重启后恢复默认参数）
+	MaxChangePercent float64 `json:"max_change_percent"` // 自动应用时单次调整幅度上限（%，相对当前值，默认25）
+}
+
+// PositionSizingConfig 仓位计算配置
+// 按账户权益计算每次开仓的交易金额（名义价值），代替固定的 amount；账户权益未知或所需数据不足时仍使用 amount
+type PositionSizingConfig struct {
+	Mode             string  `json:"mode"`               // fixed: 使用 amount / amount_equity_percent（默认） / risk: 按止损距离承担固定风险 / atr: 按ATR波动承担固定风险 / kelly: 按历史胜率和盈亏比的凯利公式
+	RiskPercent      float64 `json:"risk_percent"`       // risk、atr 模式每笔承担的风险（%，占账户权益，默认1）
+	ATRMultiple      float64 `json:"atr_multiple"`       // atr 模式以几倍ATR作为风险距离（默认2）
+	KellyFraction    float64 `json:"kelly_fraction"`     // kelly 模式使用的凯利比例（默认0.5，即半凯利）
+	LookbackTrades   int     `json:"lookback_trades"`    // kelly 模式统计的最近已平仓交易数（默认50）
+	MinTrades        int     `json:"min_trades"`         // kelly 模式已平仓交易少于该数量时使用 amount（默认20）
+	MaxEquityPercent float64 `json:"max_equity_percent"` // 交易金额上限（%，占账户权益，默认合约为100×杠杆、现货为100）
+}
+
+// MultiTimeframeConfig 多周期分析配置
+// 每轮分析额外获取大周期K线，将各周期的趋势和支撑阻力摘要加入提示词，避免逆大周期趋势开仓
+type MultiTimeframeConfig struct {
+	Enable     bool     `json:"enable"`      // 是否启用
+	Timeframes []string `json:"timeframes"`  // 大周期（默认 ["1h", "4h"]，与 timeframe 相同的周期忽略）
+	DataPoints int      `json:"data_points"` // 每个周期获取的K线数量（默认100）
+}
+
+// AdaptiveCadenceConfig 自适应执行频率配置
+// 按ATR%划分波动状态：高波动时缩短执行间隔，低波动时放宽，其余使用 schedule_interval_minutes
+type AdaptiveCadenceConfig struct {
+	Enable                 bool    `json:"enable"`                   // 是否启用
+	HighVolatilityATR      float64 `json:"high_volatility_atr"`      // 高波动阈值（ATR%，默认1.5）
+	LowVolatilityATR       float64 `json:"low_volatility_atr"`       // 低波动阈值（ATR%，默认0.3）
+	HighVolatilityInterval int     `json:"high_volatility_interval"` // 高波动时执行间隔（分钟，默认5）
+	LowVolatilityInterval  int     `json:"low_volatility_interval"`  // 低波动时执行间隔（分钟，默认30）
+	MinIntervalMinutes     int     `json:"min_interval_minutes"`     // 执行间隔下限（分钟，默认5）
+	MaxIntervalMinutes     int     `json:"max_interval_minutes"`     // 执行间隔上限（分钟，默认60）
+}
+
+// EmbargoConfig 禁止交易名单配置
+// 名单内的交易对即使已配置或出现交易信号也不会开仓（风控平仓不受影响）
+type EmbargoConfig struct {
+	Blacklist []string `json:"blacklist"` // 永久黑名单（交易对如 BTC-USDT，或币种如 BTC）
+	File      string   `json:"file"`      // 临时禁令文件（默认 data/embargo.json）
+}
+
+// JournalConfig 交易日志配置
+type JournalConfig struct {
+	File       string `json:"file"`        // 交易日志文件（默认 data/journal.json）
+	PostMortem bool   `json:"post_mortem"` // 平仓后请求AI撰写复盘并写入交易日志
+}
+
+// ExpectancyGateConfig 期望值过滤配置
+// 开仓前按交易日志统计同方向、同信心、同市场状态信号的历史平均收益，样本充足且期望为负时跳过
+type ExpectancyGateConfig struct {
+	Enable        bool    `json:"enable"`         // 是否启用
+	MinSamples    int     `json:"min_samples"`    // 最小样本数（默认20，样本不足时放行）
+	MinExpectancy float64 `json:"min_expectancy"` // 最低平均收益率（%，默认0）
+}
+
+// LossCooldownConfig 连续亏损冷却配置
+// 交易对连续亏损后暂停开仓一段时间（或只执行高信心信号），避免在误判的行情中持续亏损
+type LossCooldownConfig struct {
+	Enable            bool    `json:"enable"`             // 是否启用
+	ConsecutiveLosses int     `json:"consecutive_losses"` // 连续亏损笔数（默认3）
+	CooldownHours     float64 `json:"cooldown_hours"`     // 冷却时长（小时，从最后一笔亏损平仓起算，默认12）
+	Mode              string  `json:"mode"`               // 冷却期内: pause(不开仓，默认) / high_confidence(只执行高信心信号)
+}
+
+// SignalConfirmationConfig 信号确认配置
+// 开新仓前要求AI连续多轮给出相同的信号，过滤偶发的一次性信号反复
+type SignalConfirmationConfig struct {
+	Enable bool `json:"enable"` // 是否启用
+	Count  int  `json:"count"`  // 连续相同信号的轮数（含本轮，默认2）
+}
+
+// VolatilityFilterConfig 波动率过滤配置
+// 按ATR占价格的百分比过滤开仓：波动过低的横盘行情中反复开平仓只会消耗手续费，波动过高的消息行情中容易被来回扫损
+type VolatilityFilterConfig struct {
+	Enable        bool    `json:"enable"`          // 是否启用
+	MinATRPercent float64 `json:"min_atr_percent"` // ATR%低于该值时不开仓（默认0.1）
+	MaxATRPercent float64 `json:"max_atr_percent"` // ATR%高于该值时不开仓（默认3.0）
+}
+
+// TradingSessionsConfig 交易时段配置
+// 只在允许的时段内开新仓，并在计划的宏观事件（如议息会议、非农数据公布）前后禁止开仓；已有持仓仍由风控管理
+type TradingSessionsConfig struct {
+	Enable    bool             `json:"enable"`    // 是否启用
+	Timezone  string           `json:"timezone"`  // 时段和星期使用的时区（默认使用 calendar.timezone）
+	Windows   []string         `json:"windows"`   // 允许开仓的每日时段 HH:MM-HH:MM（可跨午夜，如 22:00-02:00；为空时全天）
+	Weekdays  []string         `json:"weekdays"`  // 允许开仓的星期（mon, tue, wed, thu, fri, sat, sun；为空时每天）
+	Blackouts []BlackoutConfig `json:"blackouts"` // 禁止开仓的时间段
+}
+
+// BlackoutConfig 禁止开仓的时间段
+type BlackoutConfig struct {
+	Start  time.Time `json:"start"`  // 开始时间（RFC3339，如 2026-11-04T18:30:00Z）
+	End    time.Time `json:"end"`    // 结束时间（RFC3339）
+	Reason string    `json:"reason"` // 原因（如 FOMC 议息会议）
+}
+
+// ExecutionConfig 开仓下单执行方式配置
+// limit 模式以卖一/买一价加最大滑点提交限价单，避免盘口较薄时大额市价单成交在远离盘口的价格；平仓和风控单始终为市价单
+type ExecutionConfig struct {
+	Mode           string  `json:"mode"`             // 执行方式: market(市价单，默认) / limit(限价单)
+	MaxSlippageBps float64 `json:"max_slippage_bps"` // 限价相对卖一（买入）或买一（卖出）价的最大滑点（基点，默认10）
+	WaitSeconds    int     `json:"wait_seconds"`     // 限价单等待成交的时间（秒，默认5），超时后撤单
+	Fallback       string  `json:"fallback"`         // 超时未全部成交时的处理: market(剩余数量改为市价单，默认) / cancel(撤单，只保留已成交部分)
+}
+
+// HedgeModeConfig 双向持仓配置（仅合约模式）
+// 同一交易对可同时持有多仓和空仓，每个方向由独立的风险管理器监控止盈止损
+type HedgeModeConfig struct {
+	Enable         bool   `json:"enable"`          // 是否启用（需交易所账户开启双向持仓，并将 api.position_mode 设置为 long_short）
+	OppositeSignal string `json:"opposite_signal"` // 持有反向仓位时的开仓信号: flip(平掉反向仓位后开仓，默认) / hedge(保留反向仓位，同时开仓)
+}
+
+// FundingArbConfig 资金费率套利配置
+// 资金费率大幅为正（多头支付空头）时买入现货并做空等量永续合约，持有 Delta 中性组合收取资金费，费率回落后平掉两边
+type FundingArbConfig struct {
+	Enable               bool     `json:"enable"`                 // 是否启用
+	Pairs                []string `json:"pairs"`                  // 套利的交易对（如 ETH-USDT，不能与方向性交易的交易对重复）
+	EntryAnnualPercent   float64  `json:"entry_annual_percent"`   // 开仓的年化资金费率（%，默认30）
+	ExitAnnualPercent    float64  `json:"exit_annual_percent"`    // 平仓的年化资金费率（%，默认为开仓阈值的1/3，须低于开仓阈值）
+	Amount               float64  `json:"amount"`                 // 每个交易对的现货买入金额（计价币种，默认使用 trading.amount）
+	Leverage             int      `json:"leverage"`               // 永续合约杠杆（默认1）
+	CheckIntervalMinutes int      `json:"check_interval_minutes"` // 检查资金费率的间隔（分钟，默认15）
+}
+
+// BasisConfig 现货-永续合约基差配置（仅合约模式）
+// 每轮分析时获取同一交易所的现货价格，计算永续合约相对现货的溢价，加入AI分析提示词并可通过控制接口 /basis 查看；
+// 可选交易基差收敛：合约溢价过高时买入现货并做空等量永续合约，溢价收敛后平掉两边
+type BasisConfig struct {
+	Enable               bool     `json:"enable"`                 // 是否监控基差
+	Trade                bool     `json:"trade"`                  // 是否交易基差收敛
+	TradePairs           []string `json:"trade_pairs"`            // 交易基差收敛的交易对（如 SOL-USDT，不能与方向性交易或资金费率套利的交易对重复）
+	EntryPercent         float64  `json:"entry_percent"`          // 开仓的合约溢价（%，默认0.5）
+	ExitPercent          float64  `json:"exit_percent"`           // 平仓的合约溢价（%，默认为开仓阈值的1/5，须低于开仓阈值）
+	Amount               float64  `json:"amount"`                 // 每个交易对的现货买入金额（计价币种，默认使用 trading.amount）
+	Leverage             int      `json:"leverage"`               // 永续合约杠杆（默认1）
+	CheckIntervalSeconds int      `json:"check_interval_seconds"` // 交易基差收敛时的检查间隔（秒，默认60）
+}
+
+// ReconcileConfig 启动核对配置
+// 启动时核对交易所的持仓和未成交委托与本地记录，接管未记录的持仓并记录差异，避免手动交易或崩溃留下无人监控的敞口
+type ReconcileConfig struct {
+	CancelOrphanOrders bool `json:"cancel_orphan_orders"` // 撤销交易对上未被跟踪的未成交委托（默认只记录）
+}
+
+// LiquidityGateConfig 流动性检查配置
+// 开仓前检查24小时成交额、买卖价差和按下单数量吃单的滑点，流动性不足的小币种交易对跳过开仓，避免市价单大幅滑点
+type LiquidityGateConfig struct {
+	Enable         bool    `json:"enable"`           // 是否启用
+	MinVolume24h   float64 `json:"min_volume_24h"`   // 最低24小时成交额（计价币种，0表示不检查）
+	MaxSpreadBps   float64 `json:"max_spread_bps"`   // 最大买卖价差（基点，0表示不检查）
+	MaxSlippageBps float64 `json:"max_slippage_bps"` // 按下单数量吃单的最大预计滑点（基点，0表示不检查；盘口深度不足下单数量时也不开仓）
 }
 
 // RiskManagementConfig 风险管理配置
@@ -50,19 +322,210 @@ type RiskManagementConfig struct {
 	TakeProfitPercent    float64 `json:"take_profit_percent"`    // 止盈百分比（如4.0表示4%）
 	EnableTrailingStop   bool    `json:"enable_trailing_stop"`   // 是否启用移动止损
 	TrailingStopDistance float64 `json:"trailing_stop_distance"` // 移动止损距离（%）
+	TrailingStopMode     string  `json:"trailing_stop_mode"`     // 移动止损执行方式: local(本地风控跟踪，默认), exchange(交易所端移动止损委托，机器人停机时仍有效，仅OKX合约)
 	CheckIntervalSeconds int     `json:"check_interval_seconds"` // 检查间隔（秒）
+	ExchangeBracket      bool    `json:"exchange_bracket"`       // 开仓时同时提交交易所端止损止盈（括号单，机器人停机时仍有效）
+	PriceSource          string  `json:"price_source"`           // 触发止盈止损的价格来源: last(最新成交价，默认), mark(标记价格), index(指数价格)
+	MaxMarginRatio       float64 `json:"max_margin_ratio"`       // 维持保证金率上限（%，维持保证金/账户权益，超过时暂停开仓，0表示不检查）
+	MaxLossPerTrade      float64 `json:"max_loss_per_trade"`     // 单笔最大亏损（symbolB计价，按止损距离限制交易金额，0表示不限制）
+	MaxOpenPositions     int     `json:"max_open_positions"`     // 全部交易对同时持仓数上限（多交易对共用，0表示不限制）
+	MaxTotalExposure     float64 `json:"max_total_exposure"`     // 全部交易对持仓名义价值合计上限（symbolB计价，多交易对共用，0表示不限制）
+	MinRiskReward        float64 `json:"min_risk_reward"`        // 开仓的最低盈亏比（止盈距离/止损距离，如1.5，0表示不检查）
+
+	DailyLossLimit DailyLossLimitConfig `json:"daily_loss_limit"` // 每日亏损上限
+
+	VolatilityScaling VolatilityScalingConfig `json:"volatility_scaling"` // 按波动率缩放止盈止损
+
+	AIExitCheck AIExitCheckConfig `json:"ai_exit_check"` // AI提前离场检查
+
+	MarginTopUp MarginTopUpConfig `json:"margin_top_up"` // 保证金自动补充
+}
+
+// DailyLossLimitConfig 每日亏损上限配置（每个交易对分别统计，交易日按交易日历划分）
+// 当日已实现盈亏（交易日志中当日平仓的净盈亏）加当前持仓的未实现盈亏达到亏损上限时，暂停开仓至下一个交易日
+type DailyLossLimitConfig struct {
+	MaxLoss float64 `json:"max_loss"` // 当日亏损上限（symbolB计价，0表示不限制）
+	Action  string  `json:"action"`   // 达到上限时对已有持仓的处理: hold(保留，仍由风控管理，默认) / close(立即平仓)
+}
+
+// VolatilityScalingConfig 波动率缩放配置
+// 止盈止损百分比 = 配置百分比 × clamp(当前ATR% / 参考ATR%, 最小倍数, 最大倍数)
+type VolatilityScalingConfig struct {
+	Enable              bool    `json:"enable"`                // 是否启用
+	ReferenceATRPercent float64 `json:"reference_atr_percent"` // 参考ATR%（配置的止盈止损百分比对应的波动水平，默认1.0）
+	MinScale            float64 `json:"min_scale"`             // 最小缩放倍数（默认0.5）
+	MaxScale            float64 `json:"max_scale"`             // 最大缩放倍数（默认3.0）
+}
+
+// AIExitCheckConfig AI提前离场检查配置
+// 持仓不利波动接近止损时，用简短提示词询问AI是否提前离场
+type AIExitCheckConfig struct {
+	Enable              bool    `json:"enable"`                 // 是否启用
+	TriggerRatio        float64 `json:"trigger_ratio"`          // 触发比例：价格已走完到止损距离的比例（如0.6表示60%，默认0.6）
+	MinConfidence       string  `json:"min_confidence"`         // 采纳离场建议的最低信心（HIGH/MEDIUM，默认HIGH）
+	MaxCallsPerPosition int     `json:"max_calls_per_position"` // 每个持仓最多询问次数（默认2）
+	MaxCallsPerDay      int     `json:"max_calls_per_day"`      // 每个交易日最多询问次数（默认10）
+	MinIntervalSeconds  int     `json:"min_interval_seconds"`   // 两次询问最小间隔（秒，默认300）
+}
+
+// MarginTopUpConfig 保证金自动补充配置
+// 交易账户可用保证金低于下限时，从资金账户划转计价币种到交易账户
+type MarginTopUpConfig struct {
+	Enable       bool    `json:"enable"`        // 是否启用
+	MinAvailable float64 `json:"min_available"` // 可用保证金下限（symbolB计价，低于该值时划转）
+	Amount       float64 `json:"amount"`        // 每次划转数量（symbolB计价）
+	MaxDaily     float64 `json:"max_daily"`     // 每个交易日最多划转数量（0表示不限制）
+}
+
+// CalendarConfig 交易日历配置（定义每日统计的日界线）
+type CalendarConfig struct {
+	Timezone     string `json:"timezone"`      // 时区（如 "UTC", "Asia/Shanghai"，默认 UTC）
+	RolloverTime string `json:"rollover_time"` // 日切时间 HH:MM（如 "00:00" 或交易所结算时间 "08:00"，默认 00:00）
+	StateFile    string `json:"state_file"`    // 日历状态持久化文件（默认 data/calendar.json）
 }
 
 // APIConfig API配置
 type APIConfig struct {
-	DeepSeekAPIKey  string `json:"deepseek_api_key"`
-	DeepSeekBaseURL string `json:"deepseek_base_url"`
-	OKXAPIKey       string `json:"okx_api_key"`
-	OKXSecret       string `json:"okx_secret"`
-	OKXPassword     string `json:"okx_password"`
-	BinanceAPIKey   string `json:"binance_api_key"`
-	BinanceSecret   string `json:"binance_secret"`
-	ExchangeType    string `json:"exchange_type"` // "okx" or "binance"
+	AIProvider         string            `json:"ai_provider"`          // AI服务: deepseek(默认), openai
+	AIFallbackProvider string            `json:"ai_fallback_provider"` // 备用AI服务（主服务请求失败、超时或回复无法解析时重试，为空不启用）
+	AIResponseFormat   string            `json:"ai_response_format"`   // AI回复格式: json(默认，JSON模式约束模型只输出JSON对象), text(不支持JSON模式的兼容服务，从文本中提取)
+	DeepSeekAPIKey     string            `json:"deepseek_api_key"`
+	DeepSeekBaseURL    string            `json:"deepseek_base_url"`
+	DeepSeekModel      string            `json:"deepseek_model"` // 模型名称（默认 deepseek-chat，兼容 OpenAI 接口的服务可配合 deepseek_base_url 使用）
+	OpenAIAPIKey       string            `json:"openai_api_key"`
+	OpenAIBaseURL      string            `json:"openai_base_url"` // 接口地址（默认 https://api.openai.com，可填兼容的代理网关）
+	OpenAIModel        string            `json:"openai_model"`    // 模型名称（默认 gpt-4o，如 gpt-4.1）
+	PromptTemplate     string            `json:"prompt_template"` // 分析提示词模板文件（text/template，为空使用内置提示词）
+	PromptVariants     []PromptVariant   `json:"prompt_variants"` // 提示词A/B实验变体（配置后每次分析按权重随机选择变体，忽略 prompt_template）
+	SignalHistory      SignalHistory     `json:"signal_history"`  // 提示词中的历史信号配置
+	AI                 AIConfig          `json:"ai"`              // AI请求参数配置
+	AIConsensus        AIConsensusConfig `json:"ai_consensus"`    // 多模型共识配置（启用时忽略 ai_provider）
+	OKXAPIKey          string            `json:"okx_api_key"`
+	OKXSecret          string            `json:"okx_secret"`
+	OKXPassword        string            `json:"okx_password"`
+	OKXSubAccount      string            `json:"okx_sub_account"` // OKX子账户名（API Key 须为该子账户创建，下单和账户查询前校验不是主账户）
+	BinanceAPIKey      string            `json:"binance_api_key"`
+	BinanceSecret      string            `json:"binance_secret"`
+	KrakenAPIKey       string            `json:"kraken_api_key"`
+	KrakenSecret       string            `json:"kraken_secret"`
+	GateAPIKey         string            `json:"gate_api_key"`
+	GateSecret         string            `json:"gate_secret"`
+	ExchangeType       string            `json:"exchange_type"` // "okx", "binance", "kraken", "gate" or "hyperliquid"
+	UseTestnet         bool              `json:"use_testnet"`   // 使用交易所模拟盘/测试网（OKX模拟交易，Kraken、Gate.io、Hyperliquid测试网）
+	PositionMode       string            `json:"position_mode"` // 合约持仓模式: auto(默认，从账户配置检测), long_short(双向持仓), net(单向持仓)
+
+	HyperliquidPrivateKey     string `json:"hyperliquid_private_key"`     // 签名钱包私钥（建议使用 API 钱包）
+	HyperliquidAccountAddress string `json:"hyperliquid_account_address"` // 主账户地址（使用 API 钱包时必填，为空则使用私钥对应地址）
+
+	RateLimits map[string]RateLimitConfig `json:"rate_limits"` // 按接口分组的限流配置（如 market, account, trade, public），未配置的分组使用交易所默认值
+	Retry      RetryConfig                `json:"retry"`       // 临时性错误重试配置
+
+	MarketDataFallback MarketDataFallbackConfig `json:"market_data_fallback"` // 备用行情源配置
+	OHLCVCache         OHLCVCacheConfig         `json:"ohlcv_cache"`          // K线缓存配置
+	ClockSync          ClockSyncConfig          `json:"clock_sync"`           // 服务器时间同步配置
+
+	Venues map[string]json.RawMessage `json:"venues"` // 命名交易所配置（字段同 api，未配置的字段继承顶层配置），供 trading.pairs 路由使用
+}
+
+// VenueConfig 获取交易所路由对应的API配置
+// name 为空时返回顶层配置；在 venues 中时以其字段覆盖顶层配置；否则视为交易所类型
+func (c *APIConfig) VenueConfig(name string) (*APIConfig, error) {
+	venue := *c
+	venue.Venues = nil
+	if name == "" {
+		return &venue, nil
+	}
+
+	raw, ok := c.Venues[name]
+	if !ok {
+		venue.ExchangeType = name
+		return &venue, nil
+	}
+	// 复制 map 字段，避免覆盖时修改顶层配置
+	venue.RateLimits = make(map[string]RateLimitConfig, len(c.RateLimits))
+	for group, limit := range c.RateLimits {
+		venue.RateLimits[group] = limit
+	}
+	if err := json.Unmarshal(raw, &venue); err != nil {
+		return nil, fmt.Errorf("解析交易所配置 %s 失败: %w", name, err)
+	}
+	venue.Venues = nil
+	return &venue, nil
+}
+
+// AIConfig AI请求参数配置（模型只作用于主服务，其余参数主服务和备用服务共用）
+type AIConfig struct {
+	Model          string   `json:"model"`           // 主服务模型（为空时使用 deepseek_model/openai_model）
+	Temperature    *float64 `json:"temperature"`     // 采样温度（0-2，未配置时默认0.1）
+	MaxTokens      int      `json:"max_tokens"`      // 单次回复最大 token 数（0表示使用服务默认值）
+	TopP           float64  `json:"top_p"`           // 核采样概率（0-1，0表示使用服务默认值）
+	TimeoutSeconds int      `json:"timeout_seconds"` // 请求超时（秒，默认60，流式回复时为总耗时上限）
+	Stream         bool     `json:"stream"`          // 流式回复（边接收边解析，收到完整JSON后立即返回）
+	StallSeconds   int      `json:"stall_seconds"`   // 流式回复停顿超时（秒，超过该时间未收到新数据时中止，默认15）
+	Reasoning      bool     `json:"reasoning"`       // 按推理模型处理（deepseek-reasoner、DeepSeek-R1、OpenAI o 系列按模型名称自动识别，其他推理模型手动开启）
+}
+
+// AIConsensusConfig 多模型共识配置
+// 并行询问多个AI服务/模型，同一方向的有效票数达到 min_agree 时才开仓，否则观望
+type AIConsensusConfig struct {
+	Enable        bool                `json:"enable"`         // 是否启用
+	Members       []AIConsensusMember `json:"members"`        // 参与投票的服务和模型（至少2个）
+	MinAgree      int                 `json:"min_agree"`      // 同一方向所需的最少票数（默认2）
+	MinConfidence string              `json:"min_confidence"` // 计入票数的最低信心等级（HIGH/MEDIUM/LOW，默认MEDIUM）
+}
+
+// AIConsensusMember 多模型共识成员
+type AIConsensusMember struct {
+	Provider string `json:"provider"` // AI服务: deepseek, openai
+	Model    string `json:"model"`    // 模型（为空时使用该服务的模型配置）
+}
+
+// SignalHistory 分析提示词中的历史信号
+// 最近 recent 条逐条列出，启用 summarize 时会话中更早的信号浓缩为一段摘要，在保留上下文的同时控制 token 用量
+type SignalHistory struct {
+	Recent    int  `json:"recent"`    // 逐条列出的最近信号数（默认1，即只列上次信号）
+	Summarize bool `json:"summarize"` // 更早的信号汇总为一段摘要
+}
+
+// PromptVariant 提示词A/B实验变体
+type PromptVariant struct {
+	Name     string `json:"name"`     // 变体名称（记录在信号和交易日志中，为空时为 v1、v2...）
+	Template string `json:"template"` // 分析提示词模板文件（为空使用内置提示词，可作为对照组）
+	Weight   int    `json:"weight"`   // 流量权重（默认1）
+}
+
+// ClockSyncConfig 服务器时间同步配置
+// 定期获取交易所服务器时间计算本地时钟偏差，生成签名时间戳时按偏差校正
+type ClockSyncConfig struct {
+	IntervalSeconds int `json:"interval_seconds"` // 同步间隔（秒，默认300）
+	WarnDriftMs     int `json:"warn_drift_ms"`    // 偏差告警阈值（毫秒，默认1000）
+}
+
+// OHLCVCacheConfig K线缓存配置
+// 按交易对和周期缓存K线，每轮只拉取新K线追加到缓存，检测到断档时全量重新加载
+type OHLCVCacheConfig struct {
+	Enable bool `json:"enable"` // 是否启用
+}
+
+// MarketDataFallbackConfig 备用行情源配置
+// 主交易所行情接口（K线、行情、盘口）失败时改用公共行情源，分析和风控在交易所部分故障期间继续运行
+type MarketDataFallbackConfig struct {
+	Enable bool   `json:"enable"` // 是否启用
+	Source string `json:"source"` // 备用行情源: binance（默认，公共接口无需API Key）
+}
+
+// RetryConfig 重试配置（指数退避）
+type RetryConfig struct {
+	MaxAttempts      int     `json:"max_attempts"`       // 最大尝试次数（含首次，默认3）
+	InitialBackoffMs int     `json:"initial_backoff_ms"` // 首次重试等待（毫秒，默认500）
+	MaxBackoffMs     int     `json:"max_backoff_ms"`     // 最大等待（毫秒，默认5000）
+	Jitter           float64 `json:"jitter"`             // 随机抖动比例 0~1（默认0.2）
+}
+
+// RateLimitConfig 限流配置（令牌桶）
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second"` // 每秒补充的请求数
+	Burst             int     `json:"burst"`               // 桶容量（允许的突发请求数）
 }
 
 // LoggingConfig 日志配置
@@ -73,6 +536,78 @@ type LoggingConfig struct {
 	EnableFileLogging bool   `json:"enable_file_logging"`
 }
 
+// WatchdogConfig 看门狗配置
+type WatchdogConfig struct {
+	Enable               bool `json:"enable"`                 // 是否启用看门狗
+	CheckIntervalSeconds int  `json:"check_interval_seconds"` // 检查间隔（秒，默认30）
+	MaxRestarts          int  `json:"max_restarts"`           // 连续重启次数上限（默认3）
+}
+
+// NotificationConfig 通知配置
+type NotificationConfig struct {
+	Enable           bool   `json:"enable"`             // 是否启用通知
+	WebhookURL       string `json:"webhook_url"`        // Webhook地址（POST JSON）
+	TelegramBotToken string `json:"telegram_bot_token"` // Telegram机器人Token
+	TelegramChatID   string `json:"telegram_chat_id"`   // Telegram聊天ID
+	OutboxDir        string `json:"outbox_dir"`         // 发件队列持久化目录（默认 data/outbox）
+	MaxQueueSize     int    `json:"max_queue_size"`     // 每个渠道的队列上限（默认1000）
+}
+
+// StorageConfig 持久化存储配置
+type StorageConfig struct {
+	Backend string `json:"backend"` // 存储后端: sqlite(默认), postgres(多实例共享), jsonl(仅追加文件，最简部署)
+	Driver  string `json:"driver"`  // database/sql 驱动名（默认 sqlite 为 sqlite，postgres 为 pgx）
+	DSN     string `json:"dsn"`     // 数据库连接串（sqlite 默认 data/dsbot.db）
+	Dir     string `json:"dir"`     // jsonl 数据目录（默认 data/store）
+
+	ArchiveMarketData bool `json:"archive_market_data"` // 每轮AI分析的市场数据快照和信号写入存储（analysis_snapshots 集合，供提示词回测使用）
+	AuditAI           bool `json:"audit_ai"`            // AI请求的提示词、原始回复和解析结果写入存储（按交易对的 ai_audit_<交易对> 集合）
+}
+
+// ShardingConfig 多进程分片配置
+// 多个工作进程共享同一持久化存储（postgres，或同一目录的 sqlite/jsonl），按交易对租约分担交易对，
+// 同一交易对同一时间只由一个进程分析下单和风控
+type ShardingConfig struct {
+	Enable       bool     `json:"enable"`        // 是否启用
+	WorkerID     string   `json:"worker_id"`     // 工作进程标识（默认 主机名-进程号，也可用环境变量 DSBOT_WORKER_ID 覆盖）
+	LeaseSeconds int      `json:"lease_seconds"` // 租约有效期（秒，默认60，持有者每1/3有效期续期一次）
+	Pairs        []string `json:"pairs"`         // 交易对池（如 BTC-USDT），进程启动时认领第一个空闲交易对；为空时只协调 symbolA/symbolB
+}
+
+// FailoverConfig 主备切换配置
+// 两个实例共享同一持久化存储：主实例定期写入心跳，备用实例不开仓，主实例失联时接管风控（止盈止损平仓）
+type FailoverConfig struct {
+	Enable           bool   `json:"enable"`            // 是否启用
+	Role             string `json:"role"`              // primary(主实例，默认) / standby(备用实例)，也可用环境变量 DSBOT_FAILOVER_ROLE 覆盖
+	Group            string `json:"group"`             // 主备组名（同一组的主备实例共享心跳，默认 default）
+	HeartbeatSeconds int    `json:"heartbeat_seconds"` // 心跳间隔（秒，默认10，备用实例按同一间隔检查）
+	TimeoutSeconds   int    `json:"timeout_seconds"`   // 主实例失联判定时间（秒，默认60）
+
+	SameHost bool `json:"same_host"` // 主备实例在同一主机共享 sqlite/jsonl 数据文件（非 postgres 存储时必须显式开启）
+}
+
+// TracingConfig 链路追踪配置（OTLP/HTTP JSON 导出，兼容 Jaeger、Tempo、OpenTelemetry Collector）
+type TracingConfig struct {
+	Enable      bool              `json:"enable"`       // 是否启用
+	Endpoint    string            `json:"endpoint"`     // OTLP traces 接口（默认 http://localhost:4318/v1/traces）
+	ServiceName string            `json:"service_name"` // 服务名（默认 dsbot）
+	Headers     map[string]string `json:"headers"`      // 额外请求头（如托管服务的认证头）
+}
+
+// ControlAPIConfig HTTP控制接口访问控制配置
+type ControlAPIConfig struct {
+	Listen     string           `json:"listen"`      // 监听地址（如 127.0.0.1:8080，为空时不启动HTTP服务）
+	Tokens     []APITokenConfig `json:"tokens"`      // 访问令牌（请求头 Authorization: Bearer <token>）
+	AllowedIPs []string         `json:"allowed_ips"` // 来源IP白名单（IP或CIDR，为空时不限制）
+}
+
+// APITokenConfig 访问令牌及其权限范围
+type APITokenConfig struct {
+	Name   string   `json:"name"`   // 令牌名称（用于日志）
+	Token  string   `json:"token"`  // 令牌内容
+	Scopes []string `json:"scopes"` // 权限范围: read(只读状态查询) / control(暂停、平仓、交易等控制操作，包含 read)
+}
+
 // LoadConfig 从JSON文件和环境变量加载配置
 func LoadConfig(configPath string) (*Config, error) {
 	// 读取配置文件
@@ -90,6 +625,9 @@ func LoadConfig(configPath string) (*Config, error) {
 	if apiKey := os.Getenv("DEEPSEEK_API_KEY"); apiKey != "" {
 		cfg.API.DeepSeekAPIKey = apiKey
 	}
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+		cfg.API.OpenAIAPIKey = apiKey
+	}
 	if apiKey := os.Getenv("OKX_API_KEY"); apiKey != "" {
 		cfg.API.OKXAPIKey = apiKey
 	}
@@ -99,12 +637,48 @@ func LoadConfig(configPath string) (*Config, error) {
 	if password := os.Getenv("OKX_PASSWORD"); password != "" {
 		cfg.API.OKXPassword = password
 	}
+	if subAccount := os.Getenv("OKX_SUB_ACCOUNT"); subAccount != "" {
+		cfg.API.OKXSubAccount = subAccount
+	}
 	if apiKey := os.Getenv("BINANCE_API_KEY"); apiKey != "" {
 		cfg.API.BinanceAPIKey = apiKey
 	}
 	if secret := os.Getenv("BINANCE_SECRET"); secret != "" {
 		cfg.API.BinanceSecret = secret
 	}
+	if apiKey := os.Getenv("KRAKEN_API_KEY"); apiKey != "" {
+		cfg.API.KrakenAPIKey = apiKey
+	}
+	if secret := os.Getenv("KRAKEN_SECRET"); secret != "" {
+		cfg.API.KrakenSecret = secret
+	}
+	if apiKey := os.Getenv("GATE_API_KEY"); apiKey != "" {
+		cfg.API.GateAPIKey = apiKey
+	}
+	if secret := os.Getenv("GATE_SECRET"); secret != "" {
+		cfg.API.GateSecret = secret
+	}
+	if key := os.Getenv("HYPERLIQUID_PRIVATE_KEY"); key != "" {
+		cfg.API.HyperliquidPrivateKey = key
+	}
+	if address := os.Getenv("HYPERLIQUID_ACCOUNT_ADDRESS"); address != "" {
+		cfg.API.HyperliquidAccountAddress = address
+	}
+	if dsn := os.Getenv("DSBOT_STORAGE_DSN"); dsn != "" {
+		cfg.Storage.DSN = dsn
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		cfg.Notification.TelegramBotToken = token
+	}
+	if workerID := os.Getenv("DSBOT_WORKER_ID"); workerID != "" {
+		cfg.Sharding.WorkerID = workerID
+	}
+	if role := os.Getenv("DSBOT_FAILOVER_ROLE"); role != "" {
+		cfg.Failover.Role = role
+	}
+	if err := cfg.API.AI.loadEnv(); err != nil {
+		return nil, err
+	}
 
 	// 验证必需配置
 	if err := cfg.Validate(); err != nil {
@@ -114,32 +688,281 @@ func LoadConfig(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// loadEnv 从环境变量覆盖AI请求参数（DSBOT_AI_MODEL、DSBOT_AI_TEMPERATURE、DSBOT_AI_MAX_TOKENS、DSBOT_AI_TOP_P、DSBOT_AI_TIMEOUT_SECONDS）
+func (c *AIConfig) loadEnv() error {
+	if model := os.Getenv("DSBOT_AI_MODEL"); model != "" {
+		c.Model = model
+	}
+	if value := os.Getenv("DSBOT_AI_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("环境变量 DSBOT_AI_TEMPERATURE 无效: %w", err)
+		}
+		c.Temperature = &temperature
+	}
+	if value := os.Getenv("DSBOT_AI_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 DSBOT_AI_MAX_TOKENS 无效: %w", err)
+		}
+		c.MaxTokens = maxTokens
+	}
+	if value := os.Getenv("DSBOT_AI_TOP_P"); value != "" {
+		topP, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("环境变量 DSBOT_AI_TOP_P 无效: %w", err)
+		}
+		c.TopP = topP
+	}
+	if value := os.Getenv("DSBOT_AI_TIMEOUT_SECONDS"); value != "" {
+		timeout, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("环境变量 DSBOT_AI_TIMEOUT_SECONDS 无效: %w", err)
+		}
+		c.TimeoutSeconds = timeout
+	}
+	return nil
+}
+
 // Validate 验证配置有效性
 func (c *Config) Validate() error {
-	if c.API.DeepSeekAPIKey == "" {
-		return fmt.Errorf("DeepSeek API Key 未配置")
+	// 验证AI服务配置
+	if c.API.AIConsensus.Enable {
+		if err := c.validateAIConsensus(); err != nil {
+			return err
+		}
+	} else if err := c.validateAIProvider(c.API.AIProvider); err != nil {
+		return err
+	}
+	if ai := c.API.AI; ai.Temperature != nil && (*ai.Temperature < 0 || *ai.Temperature > 2) {
+		return fmt.Errorf("AI采样温度必须在0-2之间")
+	}
+	if ai := c.API.AI; ai.TopP < 0 || ai.TopP > 1 {
+		return fmt.Errorf("AI核采样概率 top_p 必须在0-1之间")
+	}
+	if ai := c.API.AI; ai.MaxTokens < 0 || ai.TimeoutSeconds < 0 || ai.StallSeconds < 0 {
+		return fmt.Errorf("AI max_tokens、timeout_seconds 和 stall_seconds 不能为负数")
+	}
+	if fallback := c.API.AIFallbackProvider; fallback != "" {
+		if fallback == c.API.AIProvider || (c.API.AIProvider == "" && fallback == "deepseek") {
+			return fmt.Errorf("备用AI服务不能与主服务相同: %s", fallback)
+		}
+		if err := c.validateAIProvider(fallback); err != nil {
+			return fmt.Errorf("备用AI服务: %w", err)
+		}
+	}
+	if f := c.API.AIResponseFormat; f != "" && f != "json" && f != "text" {
+		return fmt.Errorf("不支持的AI回复格式: %s (支持: json, text)", f)
 	}
 
 	// 验证交易所配置
-	exchangeType := c.API.ExchangeType
+	if err := c.validateExchange(&c.API); err != nil {
+		return err
+	}
 
-	switch exchangeType {
-	case string(ExchangeOKX):
-		if c.API.OKXAPIKey == "" || c.API.OKXSecret == "" || c.API.OKXPassword == "" {
-			return fmt.Errorf("OKX API 凭证未完整配置")
+	// 验证多交易对的交易所路由
+	seen := make(map[string]bool)
+	for _, pair := range c.Trading.Pairs {
+		if pair.SymbolA == "" || pair.SymbolB == "" {
+			return fmt.Errorf("trading.pairs 中的交易对未完整配置")
 		}
-	case string(ExchangeBinance):
-		if c.API.BinanceAPIKey == "" || c.API.BinanceSecret == "" {
-			return fmt.Errorf("Binance API 凭证未完整配置")
+		if seen[pair.TradingPair()] {
+			return fmt.Errorf("trading.pairs 中的交易对重复: %s", pair.TradingPair())
+		}
+		seen[pair.TradingPair()] = true
+
+		venue, err := c.API.VenueConfig(pair.Venue)
+		if err != nil {
+			return err
+		}
+		if err := c.validateExchange(venue); err != nil {
+			return fmt.Errorf("交易对 %s: %w", pair.TradingPair(), err)
+		}
+		if pair.DataVenue != "" && pair.DataVenue != pair.Venue {
+			dataVenue, err := c.API.VenueConfig(pair.DataVenue)
+			if err != nil {
+				return err
+			}
+			// binance 行情使用公共接口，无需凭证
+			if dataVenue.ExchangeType == string(ExchangeBinance) {
+				continue
+			}
+			if err := c.validateExchange(dataVenue); err != nil {
+				return fmt.Errorf("交易对 %s 行情交易所: %w", pair.TradingPair(), err)
+			}
 		}
-	default:
-		return fmt.Errorf("不支持的交易所类型: %s (支持: okx, binance)", exchangeType)
 	}
 
 	if c.Trading.Amount <= 0 {
 		return fmt.Errorf("交易数量必须大于0")
 	}
 
+	if c.Trading.RiskManagement.MaxLossPerTrade < 0 {
+		return fmt.Errorf("单笔最大亏损不能为负数")
+	}
+
+	if tu := c.Trading.RiskManagement.MarginTopUp; tu.Enable && (tu.Amount <= 0 || tu.MinAvailable <= 0) {
+		return fmt.Errorf("保证金自动补充需设置大于0的 min_available 和 amount")
+	}
+	if c.Trading.RiskManagement.MarginTopUp.Enable && c.IsSpotMode() {
+		return fmt.Errorf("保证金自动补充仅支持合约模式")
+	}
+
+	if rm := c.Trading.RiskManagement; rm.TrailingStopMode != "" && rm.TrailingStopMode != "local" && rm.TrailingStopMode != "exchange" {
+		return fmt.Errorf("不支持的移动止损执行方式: %s (支持: local, exchange)", rm.TrailingStopMode)
+	}
+	if rm := c.Trading.RiskManagement; rm.EnableTrailingStop && rm.TrailingStopMode == "exchange" {
+		if rm.TrailingStopDistance <= 0 {
+			return fmt.Errorf("交易所端移动止损需设置大于0的 trailing_stop_distance")
+		}
+		if c.IsSpotMode() {
+			return fmt.Errorf("交易所端移动止损仅支持合约模式")
+		}
+	}
+
+	if vf := c.Trading.VolatilityFilter; vf.Enable {
+		minATR, maxATR := vf.MinATRPercent, vf.MaxATRPercent
+		if minATR <= 0 {
+			minATR = 0.1
+		}
+		if maxATR <= 0 {
+			maxATR = 3.0
+		}
+		if minATR >= maxATR {
+			return fmt.Errorf("波动率过滤的 min_atr_percent (%.2f) 须低于 max_atr_percent (%.2f)", minATR, maxATR)
+		}
+	}
+
+	for _, b := range c.Trading.TradingSessions.Blackouts {
+		if b.Start.IsZero() || !b.End.After(b.Start) {
+			return fmt.Errorf("禁止开仓时间段须配置 start 和晚于 start 的 end: %s", b.Reason)
+		}
+	}
+
+	if ex := c.Trading.Execution; ex.Mode != "" && ex.Mode != "market" && ex.Mode != "limit" {
+		return fmt.Errorf("不支持的下单执行方式: %s (支持: market, limit)", ex.Mode)
+	}
+	if ex := c.Trading.Execution; ex.Fallback != "" && ex.Fallback != "market" && ex.Fallback != "cancel" {
+		return fmt.Errorf("不支持的限价单超时处理方式: %s (支持: market, cancel)", ex.Fallback)
+	}
+
+	if lc := c.Trading.LossCooldown; lc.Enable && lc.Mode != "" && lc.Mode != "pause" && lc.Mode != "high_confidence" {
+		return fmt.Errorf("不支持的亏损冷却模式: %s (支持: pause, high_confidence)", lc.Mode)
+	}
+
+	if hm := c.Trading.HedgeMode; hm.Enable {
+		if c.IsSpotMode() {
+			return fmt.Errorf("双向持仓仅支持合约模式")
+		}
+		if c.API.PositionMode != "long_short" {
+			return fmt.Errorf("双向持仓需将 api.position_mode 设置为 long_short（并在交易所账户中开启双向持仓）")
+		}
+		if hm.OppositeSignal != "" && hm.OppositeSignal != "flip" && hm.OppositeSignal != "hedge" {
+			return fmt.Errorf("不支持的反向信号处理方式: %s (支持: flip, hedge)", hm.OppositeSignal)
+		}
+	}
+
+	if fa := c.Trading.FundingArb; fa.Enable {
+		if len(fa.Pairs) == 0 {
+			return fmt.Errorf("资金费率套利需配置至少一个交易对 pairs")
+		}
+		if c.Sharding.Enable {
+			return fmt.Errorf("资金费率套利不支持多进程分片")
+		}
+		traded := map[string]bool{c.Trading.SymbolA + "-" + c.Trading.SymbolB: true}
+		for _, pair := range c.Trading.Pairs {
+			traded[pair.TradingPair()] = true
+		}
+		for _, pair := range fa.Pairs {
+			if parts := strings.Split(pair, "-"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("无效的资金费率套利交易对: %q (格式如 ETH-USDT)", pair)
+			}
+			if traded[pair] {
+				return fmt.Errorf("资金费率套利交易对 %s 不能同时用于方向性交易", pair)
+			}
+		}
+		entry := fa.EntryAnnualPercent
+		if entry <= 0 {
+			entry = 30
+		}
+		if fa.ExitAnnualPercent >= entry {
+			return fmt.Errorf("资金费率套利的平仓阈值 exit_annual_percent 须低于开仓阈值 entry_annual_percent")
+		}
+	}
+
+	if b := c.Trading.Basis; b.Trade {
+		if len(b.TradePairs) == 0 {
+			return fmt.Errorf("交易基差收敛需配置至少一个交易对 trade_pairs")
+		}
+		if c.Sharding.Enable {
+			return fmt.Errorf("交易基差收敛不支持多进程分片")
+		}
+		traded := map[string]bool{c.Trading.SymbolA + "-" + c.Trading.SymbolB: true}
+		for _, pair := range c.Trading.Pairs {
+			traded[pair.TradingPair()] = true
+		}
+		if c.Trading.FundingArb.Enable {
+			for _, pair := range c.Trading.FundingArb.Pairs {
+				traded[pair] = true
+			}
+		}
+		for _, pair := range b.TradePairs {
+			if parts := strings.Split(pair, "-"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("无效的基差交易对: %q (格式如 SOL-USDT)", pair)
+			}
+			if traded[pair] {
+				return fmt.Errorf("基差交易对 %s 不能同时用于方向性交易或资金费率套利", pair)
+			}
+		}
+		entry := b.EntryPercent
+		if entry <= 0 {
+			entry = 0.5
+		}
+		if b.ExitPercent >= entry {
+			return fmt.Errorf("基差交易的平仓阈值 exit_percent 须低于开仓阈值 entry_percent")
+		}
+	}
+
+	if dl := c.Trading.RiskManagement.DailyLossLimit; dl.Action != "" && dl.Action != "hold" && dl.Action != "close" {
+		return fmt.Errorf("不支持的每日亏损上限处理方式: %s (支持: hold, close)", dl.Action)
+	}
+
+	if fo := c.Failover; fo.Enable && fo.Role != "" && fo.Role != "primary" && fo.Role != "standby" {
+		return fmt.Errorf("不支持的主备角色: %s (支持: primary, standby)", fo.Role)
+	}
+
+	if news := c.DataFeeds.News; news.Enable && len(news.Feeds) == 0 {
+		return fmt.Errorf("新闻标题源需配置至少一个订阅地址 feeds")
+	}
+
+	switch c.Trading.PositionSizing.Mode {
+	case "", "fixed", "risk", "atr", "kelly":
+	default:
+		return fmt.Errorf("不支持的仓位计算方式: %s (支持: fixed, risk, atr, kelly)", c.Trading.PositionSizing.Mode)
+	}
+	if ps := c.Trading.PositionSizing; ps.KellyFraction > 1 {
+		return fmt.Errorf("凯利比例 kelly_fraction 不能大于1")
+	}
+
+	if se := c.Trading.StopEntry; se.Enable && se.Mode != "" && se.Mode != "stop" && se.Mode != "confirm" {
+		return fmt.Errorf("不支持的突破入场模式: %s (支持: stop, confirm)", se.Mode)
+	}
+
+	if as := c.Trading.AISuggestions; as.Enable {
+		if as.MinConfidenceScore < 0 || as.MinConfidenceScore > 100 {
+			return fmt.Errorf("AI建议最低信心分数必须在0-100之间")
+		}
+		if as.MinSizeFraction < 0 || as.MinSizeFraction > 1 {
+			return fmt.Errorf("AI建议仓位比例下限必须在0-1之间")
+		}
+		if as.MaxStopLossPercent > 0 && as.MinStopLossPercent > as.MaxStopLossPercent {
+			return fmt.Errorf("AI建议止损距离下限不能大于上限")
+		}
+		if as.MaxTakeProfitPercent > 0 && as.MinTakeProfitPercent > as.MaxTakeProfitPercent {
+			return fmt.Errorf("AI建议止盈距离下限不能大于上限")
+		}
+	}
+
 	// 验证交易模式和杠杆配置
 	tradingMode := c.Trading.TradingMode
 	if tradingMode == "" {
@@ -164,6 +987,85 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateAIProvider 验证AI服务类型和凭证
+func (c *Config) validateAIProvider(provider string) error {
+	switch provider {
+	case "", "deepseek":
+		if c.API.DeepSeekAPIKey == "" {
+			return fmt.Errorf("DeepSeek API Key 未配置")
+		}
+	case "openai":
+		if c.API.OpenAIAPIKey == "" {
+			return fmt.Errorf("OpenAI API Key 未配置")
+		}
+	default:
+		return fmt.Errorf("不支持的AI服务: %s (支持: deepseek, openai)", provider)
+	}
+	return nil
+}
+
+// validateAIConsensus 验证多模型共识配置
+func (c *Config) validateAIConsensus() error {
+	consensus := c.API.AIConsensus
+	if len(consensus.Members) < 2 {
+		return fmt.Errorf("多模型共识至少需要2个成员")
+	}
+	for i, member := range consensus.Members {
+		if err := c.validateAIProvider(member.Provider); err != nil {
+			return fmt.Errorf("多模型共识成员 %d: %w", i+1, err)
+		}
+	}
+	if consensus.MinAgree < 0 || consensus.MinAgree > len(consensus.Members) {
+		return fmt.Errorf("多模型共识 min_agree 必须在1-%d之间", len(consensus.Members))
+	}
+	switch consensus.MinConfidence {
+	case "", "HIGH", "MEDIUM", "LOW":
+	default:
+		return fmt.Errorf("不支持的共识最低信心等级: %s (支持: HIGH, MEDIUM, LOW)", consensus.MinConfidence)
+	}
+	if c.API.AIFallbackProvider != "" {
+		return fmt.Errorf("多模型共识与 ai_fallback_provider 不能同时启用")
+	}
+	return nil
+}
+
+// validateExchange 验证交易所类型和凭证
+func (c *Config) validateExchange(api *APIConfig) error {
+	exchangeType := api.ExchangeType
+
+	switch exchangeType {
+	case string(ExchangeOKX):
+		if api.OKXAPIKey == "" || api.OKXSecret == "" || api.OKXPassword == "" {
+			return fmt.Errorf("OKX API 凭证未完整配置")
+		}
+	case string(ExchangeBinance):
+		if api.BinanceAPIKey == "" || api.BinanceSecret == "" {
+			return fmt.Errorf("Binance API 凭证未完整配置")
+		}
+	case string(ExchangeKraken):
+		if api.KrakenAPIKey == "" || api.KrakenSecret == "" {
+			return fmt.Errorf("Kraken API 凭证未完整配置")
+		}
+		if c.GetTradingMode() != TradingModeFutures {
+			return fmt.Errorf("Kraken 仅支持合约交易模式")
+		}
+	case string(ExchangeGate):
+		if api.GateAPIKey == "" || api.GateSecret == "" {
+			return fmt.Errorf("Gate.io API 凭证未完整配置")
+		}
+	case string(ExchangeHyperliquid):
+		if api.HyperliquidPrivateKey == "" {
+			return fmt.Errorf("Hyperliquid 钱包私钥未配置")
+		}
+		if c.GetTradingMode() != TradingModeFutures {
+			return fmt.Errorf("Hyperliquid 仅支持合约交易模式")
+		}
+	default:
+		return fmt.Errorf("不支持的交易所类型: %s (支持: okx, binance, kraken, gate, hyperliquid)", exchangeType)
+	}
+	return nil
+}
+
 // GetTradingMode 获取交易模式 (带默认值)
 func (c *Config) GetTradingMode() TradingMode {
 	if c.Trading.TradingMode == "" {
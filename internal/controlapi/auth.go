@@ -0,0 +1,143 @@
+// Package controlapi HTTP控制接口的访问控制
+// 按令牌权限范围区分只读状态查询和控制操作，并支持来源IP白名单，
+// 便于共享看板时不暴露暂停、平仓、交易等控制接口
+package controlapi
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// 权限范围
+const (
+	ScopeRead    = "read"    // 只读状态查询
+	ScopeControl = "control" // 控制操作（包含只读权限）
+)
+
+// token 已解析的访问令牌
+type token struct {
+	name   string
+	value  []byte
+	scopes map[string]bool
+}
+
+// allows 令牌是否具有指定权限
+func (t *token) allows(scope string) bool {
+	if t.scopes[ScopeControl] {
+		return true
+	}
+	return t.scopes[scope]
+}
+
+// Authorizer 控制接口鉴权
+type Authorizer struct {
+	tokens  []token
+	allowed []*net.IPNet
+}
+
+// NewAuthorizer 按配置创建鉴权器（令牌或白名单配置无效时返回错误）
+func NewAuthorizer(cfg *config.ControlAPIConfig) (*Authorizer, error) {
+	a := &Authorizer{}
+	for i, tc := range cfg.Tokens {
+		if tc.Token == "" {
+			return nil, fmt.Errorf("控制接口令牌 #%d 内容为空", i+1)
+		}
+		t := token{name: tc.Name, value: []byte(tc.Token), scopes: make(map[string]bool)}
+		if t.name == "" {
+			t.name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, scope := range tc.Scopes {
+			if scope != ScopeRead && scope != ScopeControl {
+				return nil, fmt.Errorf("控制接口令牌 %s 的权限范围无效: %s (支持: read, control)", t.name, scope)
+			}
+			t.scopes[scope] = true
+		}
+		if len(t.scopes) == 0 {
+			t.scopes[ScopeRead] = true
+		}
+		a.tokens = append(a.tokens, t)
+	}
+
+	for _, entry := range cfg.AllowedIPs {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("控制接口IP白名单格式错误: %s", entry)
+		}
+		a.allowed = append(a.allowed, ipNet)
+	}
+	return a, nil
+}
+
+// Require 包装处理器，要求请求来源在白名单内且令牌具有指定权限
+// 来源IP不在白名单返回 403，缺少或无效令牌返回 401，权限不足返回 403
+func (a *Authorizer) Require(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.ipAllowed(r.RemoteAddr) {
+			logger.Warnf("[控制接口] 拒绝来源 %s 访问 %s", r.RemoteAddr, r.URL.Path)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		t := a.lookup(r.Header.Get("Authorization"))
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dsbot"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !t.allows(scope) {
+			logger.Warnf("[控制接口] 令牌 %s 无 %s 权限，拒绝访问 %s", t.name, scope, r.URL.Path)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ipAllowed 来源IP是否在白名单内（未配置白名单时不限制）
+func (a *Authorizer) ipAllowed(remoteAddr string) bool {
+	if len(a.allowed) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.allowed {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup 按 Authorization 请求头查找令牌（常量时间比较，未找到返回 nil）
+func (a *Authorizer) lookup(header string) *token {
+	value, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || value == "" {
+		return nil
+	}
+	var found *token
+	for i := range a.tokens {
+		if subtle.ConstantTimeCompare(a.tokens[i].value, []byte(value)) == 1 {
+			found = &a.tokens[i]
+		}
+	}
+	return found
+}
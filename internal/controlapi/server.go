@@ -0,0 +1,71 @@
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// Server HTTP控制接口服务（所有接口经过 Authorizer 鉴权）
+type Server struct {
+	listen string
+	auth   *Authorizer
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+// NewServer 按配置创建控制接口服务（鉴权配置无效时返回错误）
+func NewServer(cfg *config.ControlAPIConfig) (*Server, error) {
+	auth, err := NewAuthorizer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{listen: cfg.Listen, auth: auth, mux: http.NewServeMux()}, nil
+}
+
+// Handle 注册接口，请求需具有 scope 权限
+func (s *Server) Handle(path, scope string, handler http.Handler) {
+	s.mux.Handle(path, s.auth.Require(scope, handler))
+}
+
+// Start 开始监听（监听失败时返回错误）
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listen)
+	if err != nil {
+		return fmt.Errorf("控制接口监听 %s 失败: %w", s.listen, err)
+	}
+	s.server = &http.Server{Handler: s.mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Warnf("[控制接口] 服务异常退出: %v", err)
+		}
+	}()
+	logger.Printf("[控制接口] 已监听 %s", listener.Addr())
+	return nil
+}
+
+// Stop 停止服务（等待进行中的请求最多5秒）
+func (s *Server) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+}
+
+// WriteJSON 以JSON格式返回响应
+func WriteJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		logger.Warnf("[控制接口] 写入响应失败: %v", err)
+	}
+}
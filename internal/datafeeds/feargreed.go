@@ -0,0 +1,123 @@
+package datafeeds
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+// 恐惧贪婪指数默认配置
+const (
+	DefaultFearGreedURL            = "https://api.alternative.me/fng/?limit=2"
+	DefaultFearGreedRefreshMinutes = 60
+)
+
+// FearGreedFeed 加密货币恐惧贪婪指数（alternative.me，每日更新一次），按刷新间隔缓存，多个交易对共用
+type FearGreedFeed struct {
+	url        string
+	refresh    time.Duration
+	httpClient *nets.HttpClient
+
+	mu        sync.Mutex
+	index     *models.FearGreedIndex // 最近一次成功获取的指数
+	fetchedAt time.Time
+}
+
+// fearGreedResponse alternative.me 接口响应（数值和时间戳均为字符串，按时间从新到旧）
+type fearGreedResponse struct {
+	Data []struct {
+		Value          string `json:"value"`
+		Classification string `json:"value_classification"`
+		Timestamp      string `json:"timestamp"`
+	} `json:"data"`
+	Metadata struct {
+		Error string `json:"error"`
+	} `json:"metadata"`
+}
+
+// NewFearGreedFeed 创建恐惧贪婪指数数据源
+func NewFearGreedFeed(cfg config.FearGreedConfig) (*FearGreedFeed, error) {
+	httpClient, err := nets.NewHttpClient(15*time.Second, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+	}
+
+	f := &FearGreedFeed{
+		url:        DefaultFearGreedURL,
+		refresh:    DefaultFearGreedRefreshMinutes * time.Minute,
+		httpClient: httpClient,
+	}
+	if cfg.RefreshMinutes > 0 {
+		f.refresh = time.Duration(cfg.RefreshMinutes) * time.Minute
+	}
+	return f, nil
+}
+
+// Current 当前恐惧贪婪指数（从未获取成功时返回nil）
+// 缓存超过刷新间隔时重新获取，获取失败时沿用上次的结果
+func (f *FearGreedFeed) Current() *models.FearGreedIndex {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if now := time.Now(); now.Sub(f.fetchedAt) >= f.refresh {
+		f.fetchedAt = now
+		index, err := f.fetch()
+		if err != nil {
+			logger.Warnf("[恐惧贪婪指数] 获取失败，沿用上次结果: %v", err)
+		} else {
+			f.index = index
+			logger.Debugf("[恐惧贪婪指数] 已更新: %d (%s)", index.Value, index.Classification)
+		}
+	}
+
+	if f.index == nil {
+		return nil
+	}
+	index := *f.index
+	return &index
+}
+
+// fetch 获取最新指数和前一日指数
+func (f *FearGreedFeed) fetch() (*models.FearGreedIndex, error) {
+	resp, err := f.httpClient.QueryRaw("GET", f.url, nets.DefaultHeadersGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var result fearGreedResponse
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Metadata.Error != "" {
+		return nil, fmt.Errorf("接口返回错误: %s", result.Metadata.Error)
+	}
+	if len(result.Data) == 0 {
+		return nil, fmt.Errorf("响应中没有指数数据")
+	}
+
+	latest := result.Data[0]
+	value, err := strconv.Atoi(latest.Value)
+	if err != nil {
+		return nil, fmt.Errorf("解析指数失败: %w", err)
+	}
+	index := &models.FearGreedIndex{Value: value, Classification: latest.Classification}
+	if ts, err := strconv.ParseInt(latest.Timestamp, 10, 64); err == nil {
+		index.UpdatedAt = time.Unix(ts, 0)
+	}
+	if len(result.Data) > 1 {
+		if previous, err := strconv.Atoi(result.Data[1].Value); err == nil {
+			index.PreviousValue = previous
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,233 @@
+// Package datafeeds 外部市场数据源
+// 拉取新闻标题等交易所之外的市场信息，按刷新间隔缓存，供AI分析提示词使用
+package datafeeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+// 新闻标题源默认配置
+const (
+	DefaultNewsLookbackHours  = 12
+	DefaultNewsMaxHeadlines   = 10
+	DefaultNewsRefreshMinutes = 15
+)
+
+// newsTimeLayouts RSS/Atom 常见的发布时间格式
+var newsTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+// NewsFeed 新闻标题源 - 拉取配置的 RSS/Atom 订阅并缓存，多个交易对共用
+type NewsFeed struct {
+	feeds        []string
+	keywords     []string
+	lookback     time.Duration
+	maxHeadlines int
+	refresh      time.Duration
+	httpClient   *nets.HttpClient
+
+	mu        sync.Mutex
+	byFeed    map[string][]models.NewsHeadline // 各订阅源最近一次成功拉取的标题
+	headlines []models.NewsHeadline            // 全部订阅源的标题（去重，按发布时间从新到旧）
+	fetchedAt time.Time
+}
+
+// NewNewsFeed 创建新闻标题源
+func NewNewsFeed(cfg config.NewsFeedConfig) (*NewsFeed, error) {
+	httpClient, err := nets.NewHttpClient(15*time.Second, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+	}
+
+	f := &NewsFeed{
+		feeds:        cfg.Feeds,
+		lookback:     DefaultNewsLookbackHours * time.Hour,
+		maxHeadlines: DefaultNewsMaxHeadlines,
+		refresh:      DefaultNewsRefreshMinutes * time.Minute,
+		httpClient:   httpClient,
+		byFeed:       make(map[string][]models.NewsHeadline),
+	}
+	for _, keyword := range cfg.Keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			f.keywords = append(f.keywords, strings.ToLower(keyword))
+		}
+	}
+	if cfg.LookbackHours > 0 {
+		f.lookback = time.Duration(cfg.LookbackHours) * time.Hour
+	}
+	if cfg.MaxHeadlines > 0 {
+		f.maxHeadlines = cfg.MaxHeadlines
+	}
+	if cfg.RefreshMinutes > 0 {
+		f.refresh = time.Duration(cfg.RefreshMinutes) * time.Minute
+	}
+	return f, nil
+}
+
+// LookbackHours 统计的新闻时间范围（小时）
+func (f *NewsFeed) LookbackHours() int {
+	return int(f.lookback.Hours())
+}
+
+// Recent 最近 lookback 时间内与交易对相关的新闻标题（最多 maxHeadlines 条，从新到旧）
+// 缓存超过刷新间隔时重新拉取，拉取失败的订阅源沿用上次的结果
+// symbolA: 交易对基础币种（配置了关键词时，标题包含该币种也会保留）
+func (f *NewsFeed) Recent(symbolA string) []models.NewsHeadline {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(f.fetchedAt) >= f.refresh {
+		f.refreshLocked()
+		f.fetchedAt = now
+	}
+
+	since := now.Add(-f.lookback)
+	var recent []models.NewsHeadline
+	for _, headline := range f.headlines {
+		if headline.PublishedAt.Before(since) || !f.matches(headline.Title, symbolA) {
+			continue
+		}
+		recent = append(recent, headline)
+		if len(recent) >= f.maxHeadlines {
+			break
+		}
+	}
+	return recent
+}
+
+// matches 标题是否匹配关键词或基础币种（未配置关键词时全部保留）
+func (f *NewsFeed) matches(title, symbolA string) bool {
+	if len(f.keywords) == 0 {
+		return true
+	}
+	title = strings.ToLower(title)
+	if symbolA != "" && strings.Contains(title, strings.ToLower(symbolA)) {
+		return true
+	}
+	for _, keyword := range f.keywords {
+		if strings.Contains(title, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshLocked 重新拉取全部订阅源（调用方需持有锁）
+func (f *NewsFeed) refreshLocked() {
+	var failed int
+	for _, url := range f.feeds {
+		items, err := f.fetch(url)
+		if err != nil {
+			logger.Warnf("[新闻] 拉取订阅源失败 %s: %v", url, err)
+			failed++
+			continue
+		}
+		f.byFeed[url] = items
+	}
+
+	var headlines []models.NewsHeadline
+	seen := make(map[string]bool)
+	for _, url := range f.feeds {
+		for _, item := range f.byFeed[url] {
+			if key := strings.ToLower(item.Title); !seen[key] {
+				seen[key] = true
+				headlines = append(headlines, item)
+			}
+		}
+	}
+	sort.SliceStable(headlines, func(i, j int) bool {
+		return headlines[i].PublishedAt.After(headlines[j].PublishedAt)
+	})
+	f.headlines = headlines
+	logger.Debugf("[新闻] 已拉取 %d 个订阅源 (失败 %d 个)，共 %d 条标题", len(f.feeds), failed, len(headlines))
+}
+
+// feedDocument RSS 2.0 / Atom 文档（两种格式的根元素不同，按字段分别解析）
+type feedDocument struct {
+	Title   string `xml:"title"` // Atom 订阅标题
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+	Entries []struct {
+		Title     string `xml:"title"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// fetch 拉取并解析单个订阅源（无法解析发布时间的条目跳过）
+func (f *NewsFeed) fetch(url string) ([]models.NewsHeadline, error) {
+	resp, err := f.httpClient.QueryRaw("GET", url, nets.DefaultHeadersGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.IsSuccess() {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var doc feedDocument
+	if err := xml.Unmarshal(resp.Body, &doc); err != nil {
+		return nil, fmt.Errorf("解析订阅内容失败: %w", err)
+	}
+
+	source := strings.TrimSpace(doc.Channel.Title)
+	if source == "" {
+		source = strings.TrimSpace(doc.Title)
+	}
+	if source == "" {
+		source = url
+	}
+
+	var headlines []models.NewsHeadline
+	add := func(title, published string) {
+		title = strings.Join(strings.Fields(title), " ")
+		publishedAt, ok := parseNewsTime(published)
+		if title == "" || !ok {
+			return
+		}
+		headlines = append(headlines, models.NewsHeadline{Title: title, Source: source, PublishedAt: publishedAt})
+	}
+	for _, item := range doc.Channel.Items {
+		add(item.Title, item.PubDate)
+	}
+	for _, entry := range doc.Entries {
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+		add(entry.Title, published)
+	}
+	return headlines, nil
+}
+
+// parseNewsTime 解析发布时间
+func parseNewsTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range newsTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,239 @@
+package embargo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+const DefaultFile = "data/embargo.json"
+
+// Entry 临时禁止交易条目
+type Entry struct {
+	Symbol    string    `json:"symbol"`          // 交易对（如 BTC-USDT）或币种（如 BTC，匹配该币种的所有交易对）
+	Reason    string    `json:"reason"`          // 原因（如交易所下架公告）
+	Until     time.Time `json:"until,omitempty"` // 到期时间（零值表示直到手动解除）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// active 判断条目在指定时间是否生效
+func (e *Entry) active(now time.Time) bool {
+	return e.Until.IsZero() || now.Before(e.Until)
+}
+
+// List 禁止交易名单 - 配置中的永久黑名单 + 持久化到文件的临时禁令
+// 文件被外部修改（如 dsbot embargo 命令）时自动重新加载
+type List struct {
+	file      string
+	blacklist map[string]bool
+	entries   map[string]*Entry
+	modTime   time.Time
+	mu        sync.Mutex
+}
+
+// NewList 创建禁止交易名单，并从文件恢复临时禁令
+func NewList(blacklist []string, file string) (*List, error) {
+	if file == "" {
+		file = DefaultFile
+	}
+
+	l := &List{
+		file:      file,
+		blacklist: make(map[string]bool, len(blacklist)),
+		entries:   make(map[string]*Entry),
+	}
+	for _, symbol := range blacklist {
+		if key := normalize(symbol); key != "" {
+			l.blacklist[key] = true
+		}
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Check 检查交易对是否禁止交易，返回是否禁止及原因
+// tradingPair: 交易对（如 BTC-USDT、BTC/USDT），同时匹配交易对和基础币种
+func (l *List) Check(tradingPair string) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reloadLocked()
+
+	pair := normalize(tradingPair)
+	base := pair
+	if i := strings.Index(pair, "-"); i > 0 {
+		base = pair[:i]
+	}
+
+	for _, key := range []string{pair, base} {
+		if l.blacklist[key] {
+			return true, fmt.Sprintf("%s 在黑名单中", key)
+		}
+		if e, ok := l.entries[key]; ok && e.active(time.Now()) {
+			reason := fmt.Sprintf("%s 临时禁止交易", key)
+			if !e.Until.IsZero() {
+				reason += fmt.Sprintf("至 %s", e.Until.Format("2006-01-02 15:04:05"))
+			}
+			if e.Reason != "" {
+				reason += ": " + e.Reason
+			}
+			return true, reason
+		}
+	}
+	return false, ""
+}
+
+// Embargo 临时禁止交易（until 为零值表示直到手动解除）
+func (l *List) Embargo(symbol string, until time.Time, reason string) error {
+	key := normalize(symbol)
+	if key == "" {
+		return fmt.Errorf("交易对不能为空")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reloadLocked()
+	l.entries[key] = &Entry{Symbol: key, Reason: reason, Until: until, CreatedAt: time.Now()}
+	return l.saveLocked()
+}
+
+// Lift 解除临时禁令，返回是否存在该禁令（黑名单只能通过配置修改）
+func (l *List) Lift(symbol string) (bool, error) {
+	key := normalize(symbol)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reloadLocked()
+	if _, ok := l.entries[key]; !ok {
+		return false, nil
+	}
+	delete(l.entries, key)
+	return true, l.saveLocked()
+}
+
+// Entries 返回生效中的临时禁令（按交易对排序）
+func (l *List) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.reloadLocked()
+	now := time.Now()
+	result := make([]Entry, 0, len(l.entries))
+	for _, e := range l.entries {
+		if e.active(now) {
+			result = append(result, *e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+// Blacklist 返回配置的黑名单（按交易对排序）
+func (l *List) Blacklist() []string {
+	result := make([]string, 0, len(l.blacklist))
+	for key := range l.blacklist {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// normalize 统一交易对格式（大写，BTC/USDT、BTC_USDT 均转换为 BTC-USDT）
+func normalize(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	symbol = strings.NewReplacer("/", "-", "_", "-").Replace(symbol)
+	return symbol
+}
+
+// load 从文件加载临时禁令，文件不存在时视为空
+func (l *List) load() error {
+	info, err := os.Stat(l.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			l.entries = make(map[string]*Entry)
+			l.modTime = time.Time{}
+			return nil
+		}
+		return err
+	}
+
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		return err
+	}
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	l.entries = make(map[string]*Entry, len(entries))
+	for _, e := range entries {
+		e.Symbol = normalize(e.Symbol)
+		l.entries[e.Symbol] = e
+	}
+	l.modTime = info.ModTime()
+	return nil
+}
+
+// reloadLocked 文件被外部修改时重新加载（调用方需持有锁，失败时保留原名单）
+func (l *List) reloadLocked() {
+	info, err := os.Stat(l.file)
+	if err == nil && info.ModTime().Equal(l.modTime) {
+		return
+	}
+	if err != nil && os.IsNotExist(err) && l.modTime.IsZero() {
+		return
+	}
+
+	if err := l.load(); err != nil {
+		logger.Warnf("[禁止交易] 重新加载 %s 失败: %v", l.file, err)
+		return
+	}
+	logger.Printf("[禁止交易] 已重新加载临时禁令 (%d 条)", len(l.entries))
+}
+
+// saveLocked 保存到文件，并清理已过期的禁令（调用方需持有锁）
+func (l *List) saveLocked() error {
+	now := time.Now()
+	entries := make([]*Entry, 0, len(l.entries))
+	for key, e := range l.entries {
+		if !e.active(now) {
+			delete(l.entries, key)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Symbol < entries[j].Symbol })
+
+	if err := os.MkdirAll(filepath.Dir(l.file), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile := l.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile, l.file); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(l.file); err == nil {
+		l.modTime = info.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+// BinancePublicSource Binance公共行情数据源（无需API Key，作为备用行情源）
+type BinancePublicSource struct {
+	baseURL     string
+	httpClient  *nets.HttpClient
+	tradingMode config.TradingMode
+}
+
+// NewBinancePublicSource 创建Binance公共行情数据源（始终使用正式网行情）
+func NewBinancePublicSource(tradingMode config.TradingMode) (*BinancePublicSource, error) {
+	httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return &BinancePublicSource{
+		baseURL:     BinanceBaseURL(tradingMode),
+		httpClient:  httpClient,
+		tradingMode: tradingMode,
+	}, nil
+}
+
+// Name 数据源名称
+func (s *BinancePublicSource) Name() string {
+	return "binance-public"
+}
+
+// apiPrefix 接口路径前缀（现货 /api/v3，U本位合约 /fapi/v1）
+func (s *BinancePublicSource) apiPrefix() string {
+	if s.tradingMode == config.TradingModeFutures {
+		return "/fapi/v1"
+	}
+	return "/api/v3"
+}
+
+// get 发送GET请求并解析JSON响应
+func (s *BinancePublicSource) get(path string, out interface{}) error {
+	resp, err := s.httpClient.QueryRaw("GET", s.baseURL+s.apiPrefix()+path, nets.DefaultHeadersGet, nil)
+	if err != nil {
+		return &ExchangeError{Exchange: "Binance", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+	}
+
+	if !resp.IsSuccess() {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		exErr := &ExchangeError{Exchange: "Binance", HTTPStatus: resp.StatusCode, Headers: rateLimitHeaders(resp.Header)}
+		if json.Unmarshal(resp.Body, &apiErr) == nil && apiErr.Msg != "" {
+			exErr.Code = strconv.Itoa(apiErr.Code)
+			exErr.Message = apiErr.Msg
+		} else {
+			exErr.Message = string(resp.Body)
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == 429 || resp.StatusCode == 418 {
+			exErr.Kind = ErrorKindRetryable
+		}
+		return exErr
+	}
+
+	return json.Unmarshal(resp.Body, out)
+}
+
+// binanceKlinesLimit Binance K线接口单次请求上限（现货1000，合约1500，取较小值）
+const binanceKlinesLimit = 1000
+
+// FetchOHLCV 获取K线数据（备用数据源不分页，最多返回 binanceKlinesLimit 根）
+func (s *BinancePublicSource) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	if limit > binanceKlinesLimit {
+		limit = binanceKlinesLimit
+	}
+
+	var rows [][]interface{}
+	path := fmt.Sprintf("/klines?symbol=%s&interval=%s&limit=%d", binanceSymbol(symbol), binanceInterval(timeframe), limit)
+	if err := s.get(path, &rows); err != nil {
+		return nil, err
+	}
+
+	ohlcvList := make([]models.OHLCV, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 6 {
+			continue
+		}
+		openTime, _ := row[0].(float64)
+		ohlcvList = append(ohlcvList, models.OHLCV{
+			Timestamp: time.UnixMilli(int64(openTime)),
+			Open:      parseJSONFloat(row[1]),
+			High:      parseJSONFloat(row[2]),
+			Low:       parseJSONFloat(row[3]),
+			Close:     parseJSONFloat(row[4]),
+			Volume:    parseJSONFloat(row[5]),
+		})
+	}
+	return ohlcvList, nil
+}
+
+// FetchTicker 获取最新行情（合约模式补充标记价格和指数价格）
+func (s *BinancePublicSource) FetchTicker(symbol string) (*models.Ticker, error) {
+	instID := binanceSymbol(symbol)
+
+	var book struct {
+		BidPrice string `json:"bidPrice"`
+		AskPrice string `json:"askPrice"`
+	}
+	if err := s.get("/ticker/bookTicker?symbol="+instID, &book); err != nil {
+		return nil, err
+	}
+	var price struct {
+		Price string `json:"price"`
+	}
+	if err := s.get("/ticker/price?symbol="+instID, &price); err != nil {
+		return nil, err
+	}
+
+	ticker := &models.Ticker{
+		Symbol: symbol,
+		Last:   parseJSONFloat(price.Price),
+		Bid:    parseJSONFloat(book.BidPrice),
+		Ask:    parseJSONFloat(book.AskPrice),
+	}
+
+	if s.tradingMode == config.TradingModeFutures {
+		var premium struct {
+			MarkPrice  string `json:"markPrice"`
+			IndexPrice string `json:"indexPrice"`
+		}
+		if err := s.get("/premiumIndex?symbol="+instID, &premium); err == nil {
+			ticker.Mark = parseJSONFloat(premium.MarkPrice)
+			ticker.Index = parseJSONFloat(premium.IndexPrice)
+		}
+	}
+
+	return ticker, nil
+}
+
+// FetchOrderBook 获取盘口深度（数量为基础币种）
+func (s *BinancePublicSource) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	var response struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
+	}
+	path := fmt.Sprintf("/depth?symbol=%s&limit=%d", binanceSymbol(symbol), binanceDepthLimit(depth))
+	if err := s.get(path, &response); err != nil {
+		return nil, err
+	}
+
+	book := &models.OrderBook{
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+	}
+	book.Bids = binanceLevels(response.Bids, depth)
+	book.Asks = binanceLevels(response.Asks, depth)
+	return book, nil
+}
+
+// binanceSymbol 转换交易对符号: BTC/USDT:USDT 或 BTC/USDT -> BTCUSDT
+func binanceSymbol(symbol string) string {
+	base := strings.SplitN(symbol, ":", 2)[0]
+	return strings.ToUpper(strings.ReplaceAll(base, "/", ""))
+}
+
+// binanceInterval 转换K线周期: 1H -> 1h, 1Dutc -> 1d（月线 1M 保持不变）
+func binanceInterval(timeframe string) string {
+	tf := strings.TrimSuffix(strings.TrimSuffix(timeframe, "utc"), "UTC")
+	if strings.HasSuffix(tf, "M") {
+		return tf
+	}
+	return strings.ToLower(tf)
+}
+
+// binanceDepthLimit 盘口档位取不小于 depth 的合法值
+func binanceDepthLimit(depth int) int {
+	for _, limit := range []int{5, 10, 20, 50, 100, 500, 1000} {
+		if depth <= limit {
+			return limit
+		}
+	}
+	return 1000
+}
+
+// binanceLevels 解析盘口档位（最多 depth 档）
+func binanceLevels(rows [][]string, depth int) []models.OrderBookLevel {
+	levels := make([]models.OrderBookLevel, 0, len(rows))
+	for _, row := range rows {
+		if depth > 0 && len(levels) >= depth {
+			break
+		}
+		if len(row) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(row[0], 64)
+		size, _ := strconv.ParseFloat(row[1], 64)
+		levels = append(levels, models.OrderBookLevel{Price: price, Size: size})
+	}
+	return levels
+}
+
+// parseJSONFloat 解析JSON中以字符串或数字表示的数值
+func parseJSONFloat(v interface{}) float64 {
+	switch val := v.(type) {
+	case string:
+		f, _ := strconv.ParseFloat(val, 64)
+		return f
+	case float64:
+		return val
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,1655 @@
+package exchange
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+const (
+	GateBaseURL           = "https://api.gateio.ws"
+	GateFuturesTestnetURL = "https://fx-api-testnet.gateio.ws"
+)
+
+// gateAPIPrefix Gate.io v4 接口前缀（签名路径包含此前缀）
+const gateAPIPrefix = "/api/v4"
+
+// gateDefaultRateLimits Gate.io默认限流配置（按官方限速留出余量）
+var gateDefaultRateLimits = map[string]config.RateLimitConfig{
+	"public":  {RequestsPerSecond: 10, Burst: 20}, // 公共接口: 200次/10s
+	"account": {RequestsPerSecond: 5, Burst: 10},  // 账户查询
+	"trade":   {RequestsPerSecond: 10, Burst: 20}, // 下单撤单: 100次/s（现货10次/s）
+}
+
+// gateIntervals 通用K线周期 -> Gate.io K线周期
+var gateIntervals = map[string]string{
+	"1m": "1m", "5m": "5m", "15m": "15m", "30m": "30m",
+	"1h": "1h", "4h": "4h", "8h": "8h", "1d": "1d", "1w": "7d", "7d": "7d",
+}
+
+// gateTextMaxLen 自定义订单ID最大长度（不含 t- 前缀）
+const gateTextMaxLen = 28
+
+// GateClient Gate.io 客户端（现货和USDT结算永续合约，合约为单向持仓）
+// 合约下单数量按合约乘数（quanto_multiplier）在基础币种和张数之间换算，持仓、订单和成交的数量统一以基础币种返回
+type GateClient struct {
+	apiKey      string
+	secret      string
+	baseURL     string
+	httpClient  *nets.HttpClient
+	tradingMode config.TradingMode
+	rateLimiter *RateLimiter
+	retry       *retryPolicy
+	clock       *serverClock
+
+	instrumentsMu sync.Mutex
+	instruments   map[string]*InstrumentInfo // Gate交易对/合约 -> 交易对信息
+
+	algoMu     sync.Mutex
+	algoOrders map[string]string // 止损/止盈自定义ID -> Gate条件单ID
+}
+
+// NewGateClient 创建Gate.io客户端
+func NewGateClient(cfg *config.APIConfig, tradingMode config.TradingMode) (*GateClient, error) {
+	baseURL := GateBaseURL
+	if cfg.UseTestnet {
+		if tradingMode == config.TradingModeSpot {
+			return nil, fmt.Errorf("Gate.io 现货没有测试网，请关闭 use_testnet 或使用合约模式")
+		}
+		baseURL = GateFuturesTestnetURL
+	}
+
+	httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+	}
+
+	c := &GateClient{
+		apiKey:      cfg.GateAPIKey,
+		secret:      cfg.GateSecret,
+		baseURL:     baseURL,
+		httpClient:  httpClient,
+		tradingMode: tradingMode,
+		rateLimiter: NewRateLimiter(gateDefaultRateLimits, cfg.RateLimits),
+		retry:       newRetryPolicy(cfg.Retry),
+		instruments: make(map[string]*InstrumentInfo),
+		algoOrders:  make(map[string]string),
+	}
+	c.clock = newServerClock("Gate.io", cfg.ClockSync, c.fetchServerTime)
+	return c, nil
+}
+
+// fetchServerTime 获取Gate.io服务器时间（公共接口，不签名）
+func (c *GateClient) fetchServerTime() (time.Time, error) {
+	c.rateLimiter.Wait("public")
+
+	resp, err := c.httpClient.QueryRaw("GET", c.baseURL+gateAPIPrefix+"/spot/time", nil, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !resp.IsSuccess() {
+		return time.Time{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var response struct {
+		ServerTime int64 `json:"server_time"`
+	}
+	if err := json.Unmarshal(resp.Body, &response); err != nil {
+		return time.Time{}, err
+	}
+	if response.ServerTime == 0 {
+		return time.Time{}, fmt.Errorf("服务器时间为空")
+	}
+	return time.UnixMilli(response.ServerTime), nil
+}
+
+// GetExchangeName 获取交易所名称
+func (c *GateClient) GetExchangeName() string {
+	return string(config.ExchangeGate)
+}
+
+// Capabilities 获取交易所支持的功能（单向持仓，批量下单逐笔提交）
+func (c *GateClient) Capabilities() Capabilities {
+	return Capabilities{
+		Futures:     true,
+		Spot:        true,
+		AlgoOrders:  true,
+		LimitOrders: true,
+	}
+}
+
+// ParseSymbols 解析交易对符号
+func (c *GateClient) ParseSymbols(symbolA, symbolB string) string {
+	if c.tradingMode == config.TradingModeSpot {
+		return fmt.Sprintf("%s/%s", symbolA, symbolB)
+	}
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+// convertSymbol 转换为Gate.io交易对/合约名称（BTC/USDT:USDT -> BTC_USDT）
+func (c *GateClient) convertSymbol(symbol string) string {
+	base, quote := splitSymbol(symbol)
+	return base + "_" + quote
+}
+
+// isSpot 是否为现货模式
+func (c *GateClient) isSpot() bool {
+	return c.tradingMode == config.TradingModeSpot
+}
+
+// gateText 自定义订单ID转换为Gate.io的 text 字段（须以 t- 开头，过长时截断）
+func gateText(clientOrderID string) string {
+	if len(clientOrderID) > gateTextMaxLen {
+		clientOrderID = clientOrderID[:gateTextMaxLen]
+	}
+	return "t-" + clientOrderID
+}
+
+// sign 生成签名: hex(HMAC-SHA512(secret, method\npath\nquery\nhex(SHA512(body))\ntimestamp))
+func (c *GateClient) sign(method, path, query, body, timestamp string) string {
+	bodyHash := sha512.Sum512([]byte(body))
+	payload := strings.Join([]string{method, path, query, hex.EncodeToString(bodyHash[:]), timestamp}, "\n")
+	h := hmac.New(sha512.New, []byte(c.secret))
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// classifyGateLabel 根据Gate.io错误标签分类
+func classifyGateLabel(label string) ErrorKind {
+	switch label {
+	case "TOO_MANY_REQUESTS", "SERVER_ERROR", "TOO_BUSY", "INTERNAL":
+		return ErrorKindRetryable
+	case "INVALID_KEY", "INVALID_SIGNATURE", "MISSING_REQUIRED_HEADER", "REQUEST_EXPIRED",
+		"INVALID_CREDENTIALS", "FORBIDDEN", "READ_ONLY", "IP_FORBIDDEN", "KEY_EXPIRED":
+		return ErrorKindAuthFailed
+	case "BALANCE_NOT_ENOUGH", "INSUFFICIENT_AVAILABLE", "MARGIN_BALANCE_NOT_ENOUGH":
+		return ErrorKindInsufficientBalance
+	case "INVALID_PARAM_VALUE", "INVALID_PRECISION", "INVALID_CURRENCY_PAIR", "INVALID_CONTRACT",
+		"CONTRACT_NOT_FOUND", "ORDER_NOT_FOUND", "ORDER_CLOSED", "ORDER_FINISHED", "ORDER_SIZE_TOO_SMALL",
+		"SIZE_TOO_LARGE", "TOO_FEW_AMOUNT", "TOO_MUCH_AMOUNT", "REDUCE_ONLY_FAILED", "POSITION_EMPTY",
+		"LIQUIDATE_IMMEDIATELY", "AUTO_ORDER_NOT_FOUND", "TRADING_CLOSED":
+		return ErrorKindInvalidOrder
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// endpointGroup 根据接口路径确定限流分组
+func (c *GateClient) endpointGroup(method, path string, private bool) string {
+	if !private {
+		return "public"
+	}
+	if method != "GET" && (strings.Contains(path, "/orders") || strings.Contains(path, "/price_orders")) {
+		return "trade"
+	}
+	return "account"
+}
+
+// request 发送HTTP请求
+// GET请求遇到临时性错误时自动重试；POST/DELETE请求不在此重试，由调用方按幂等方式处理
+// path 为 /api/v4 之后的路径，body 为 nil 时不发送请求体
+func (c *GateClient) request(method, path string, query url.Values, body interface{}, private bool) ([]byte, error) {
+	if method != "GET" {
+		return c.doRequest(method, path, query, body, private)
+	}
+
+	var data []byte
+	err := c.retry.do(method+" "+path, func() error {
+		var err error
+		data, err = c.doRequest(method, path, query, body, private)
+		return err
+	})
+	return data, err
+}
+
+// doRequest 发送单次HTTP请求，网络错误、5xx、限流等临时性错误返回可重试的 ExchangeError
+func (c *GateClient) doRequest(method, path string, query url.Values, body interface{}, private bool) ([]byte, error) {
+	c.rateLimiter.Wait(c.endpointGroup(method, path, private))
+
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	queryString := query.Encode()
+	fullURL := c.baseURL + gateAPIPrefix + path
+	if queryString != "" {
+		fullURL += "?" + queryString
+	}
+
+	headers := map[string]string{
+		"Accept":       "application/json",
+		"Content-Type": "application/json",
+	}
+	if private {
+		if c.apiKey == "" {
+			return nil, &ExchangeError{Exchange: "Gate.io", Message: "未配置API Key", Kind: ErrorKindAuthFailed}
+		}
+		timestamp := strconv.FormatInt(c.clock.Now().Unix(), 10)
+		headers["KEY"] = c.apiKey
+		headers["Timestamp"] = timestamp
+		headers["SIGN"] = c.sign(method, gateAPIPrefix+path, queryString, string(bodyBytes), timestamp)
+	}
+
+	resp, err := c.httpClient.QueryRaw(method, fullURL, headers, bodyBytes)
+	if err != nil {
+		return nil, &ExchangeError{Exchange: "Gate.io", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+	}
+
+	if !resp.IsSuccess() {
+		exErr := &ExchangeError{
+			Exchange:   "Gate.io",
+			HTTPStatus: resp.StatusCode,
+			Headers:    rateLimitHeaders(resp.Header),
+		}
+		var apiErr struct {
+			Label   string `json:"label"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(resp.Body, &apiErr) == nil && apiErr.Label != "" {
+			exErr.Code = apiErr.Label
+			exErr.Message = apiErr.Message
+			exErr.Kind = classifyGateLabel(apiErr.Label)
+			if apiErr.Label == "REQUEST_EXPIRED" {
+				// 时间戳超出允许范围，下次请求前重新同步服务器时间
+				c.clock.Invalidate()
+			}
+		} else {
+			bodyText := string(resp.Body)
+			if len(bodyText) > 512 {
+				bodyText = bodyText[:512] + "..."
+			}
+			exErr.Message = bodyText
+		}
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			exErr.Kind = ErrorKindRetryable
+		}
+		return nil, exErr
+	}
+
+	return resp.Body, nil
+}
+
+// gateFloat 解析数值字符串（空字符串返回0）
+func gateFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// contractValue 合约乘数（每张合约对应的基础币种数量，现货或获取失败时为1）
+func (c *GateClient) contractValue(symbol string) float64 {
+	if c.isSpot() {
+		return 1
+	}
+	info, err := c.GetInstrumentInfo(symbol)
+	if err != nil || info.ContractValue <= 0 {
+		return 1
+	}
+	return info.ContractValue
+}
+
+// FetchOHLCV 获取K线数据
+func (c *GateClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	interval, ok := gateIntervals[strings.ToLower(timeframe)]
+	if !ok {
+		return nil, fmt.Errorf("Gate.io 不支持的K线周期: %s", timeframe)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000 // Gate.io 单次最多返回1000根
+	}
+
+	pair := c.convertSymbol(symbol)
+	query := url.Values{"interval": {interval}, "limit": {strconv.Itoa(limit)}}
+
+	var ohlcvList []models.OHLCV
+	if c.isSpot() {
+		query.Set("currency_pair", pair)
+		data, err := c.request("GET", "/spot/candlesticks", query, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		// [时间(秒), 计价币种成交额, 收盘, 最高, 最低, 开盘, 基础币种成交量, 是否完结]
+		var rows [][]string
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if len(row) < 7 {
+				continue
+			}
+			ts, _ := strconv.ParseInt(row[0], 10, 64)
+			ohlcvList = append(ohlcvList, models.OHLCV{
+				Timestamp: time.Unix(ts, 0),
+				Open:      gateFloat(row[5]),
+				High:      gateFloat(row[3]),
+				Low:       gateFloat(row[4]),
+				Close:     gateFloat(row[2]),
+				Volume:    gateFloat(row[6]),
+			})
+		}
+	} else {
+		query.Set("contract", pair)
+		data, err := c.request("GET", "/futures/usdt/candlesticks", query, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		var rows []struct {
+			T int64   `json:"t"`
+			V float64 `json:"v"` // 成交量（张）
+			C string  `json:"c"`
+			H string  `json:"h"`
+			L string  `json:"l"`
+			O string  `json:"o"`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+		ctVal := c.contractValue(symbol)
+		for _, row := range rows {
+			ohlcvList = append(ohlcvList, models.OHLCV{
+				Timestamp: time.Unix(row.T, 0),
+				Open:      gateFloat(row.O),
+				High:      gateFloat(row.H),
+				Low:       gateFloat(row.L),
+				Close:     gateFloat(row.C),
+				Volume:    row.V * ctVal,
+			})
+		}
+	}
+
+	sort.Slice(ohlcvList, func(i, j int) bool { return ohlcvList[i].Timestamp.Before(ohlcvList[j].Timestamp) })
+	return ohlcvList, nil
+}
+
+// FetchTicker 获取最新行情（合约含标记价格和指数价格）
+func (c *GateClient) FetchTicker(symbol string) (*models.Ticker, error) {
+	pair := c.convertSymbol(symbol)
+
+	path, key := "/spot/tickers", "currency_pair"
+	if !c.isSpot() {
+		path, key = "/futures/usdt/tickers", "contract"
+	}
+	data, err := c.request("GET", path, url.Values{key: {pair}}, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickers []struct {
+		Last       string `json:"last"`
+		LowestAsk  string `json:"lowest_ask"`
+		HighestBid string `json:"highest_bid"`
+		MarkPrice  string `json:"mark_price"`
+		IndexPrice string `json:"index_price"`
+	}
+	if err := json.Unmarshal(data, &tickers); err != nil {
+		return nil, err
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("未获取到行情数据: %s", pair)
+	}
+
+	t := tickers[0]
+	return &models.Ticker{
+		Symbol: symbol,
+		Last:   gateFloat(t.Last),
+		Bid:    gateFloat(t.HighestBid),
+		Ask:    gateFloat(t.LowestAsk),
+		Mark:   gateFloat(t.MarkPrice),
+		Index:  gateFloat(t.IndexPrice),
+	}, nil
+}
+
+// FetchDerivativesStats 获取永续合约的资金费率、持仓量和标记/指数价格（持仓量由张数换算为基础币种）
+func (c *GateClient) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	if c.isSpot() {
+		return nil, nil
+	}
+	data, err := c.request("GET", "/futures/usdt/contracts/"+c.convertSymbol(symbol), nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var contract struct {
+		QuantoMultiplier string  `json:"quanto_multiplier"`
+		FundingRate      string  `json:"funding_rate"`
+		FundingInterval  int64   `json:"funding_interval"`   // 结算周期（秒）
+		FundingNextApply float64 `json:"funding_next_apply"` // 下次结算时间（秒）
+		MarkPrice        string  `json:"mark_price"`
+		IndexPrice       string  `json:"index_price"`
+		PositionSize     int64   `json:"position_size"` // 全市场持仓量（张）
+	}
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, err
+	}
+
+	stats := &models.DerivativesStats{
+		FundingRate:          gateFloat(contract.FundingRate),
+		FundingIntervalHours: float64(contract.FundingInterval) / 3600,
+		OpenInterest:         float64(contract.PositionSize) * gateFloat(contract.QuantoMultiplier),
+		MarkPrice:            gateFloat(contract.MarkPrice),
+		IndexPrice:           gateFloat(contract.IndexPrice),
+	}
+	if contract.FundingNextApply > 0 {
+		stats.NextFundingTime = time.Unix(int64(contract.FundingNextApply), 0)
+	}
+	return stats, nil
+}
+
+// FetchOrderBook 获取盘口深度（合约数量换算为基础币种）
+func (c *GateClient) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	if depth <= 0 {
+		depth = 20
+	}
+	pair := c.convertSymbol(symbol)
+	book := &models.OrderBook{Symbol: symbol}
+
+	if c.isSpot() {
+		query := url.Values{"currency_pair": {pair}, "limit": {strconv.Itoa(depth)}}
+		data, err := c.request("GET", "/spot/order_book", query, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		var response struct {
+			Current int64       `json:"current"` // 毫秒
+			Bids    [][2]string `json:"bids"`
+			Asks    [][2]string `json:"asks"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		book.Timestamp = time.UnixMilli(response.Current)
+		for _, level := range response.Bids {
+			book.Bids = append(book.Bids, models.OrderBookLevel{Price: gateFloat(level[0]), Size: gateFloat(level[1])})
+		}
+		for _, level := range response.Asks {
+			book.Asks = append(book.Asks, models.OrderBookLevel{Price: gateFloat(level[0]), Size: gateFloat(level[1])})
+		}
+		return book, nil
+	}
+
+	query := url.Values{"contract": {pair}, "limit": {strconv.Itoa(depth)}}
+	data, err := c.request("GET", "/futures/usdt/order_book", query, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Current float64 `json:"current"` // 秒（带小数）
+		Bids    []struct {
+			P string  `json:"p"`
+			S float64 `json:"s"`
+		} `json:"bids"`
+		Asks []struct {
+			P string  `json:"p"`
+			S float64 `json:"s"`
+		} `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	ctVal := c.contractValue(symbol)
+	book.Timestamp = time.UnixMilli(int64(response.Current * 1000))
+	for _, level := range response.Bids {
+		book.Bids = append(book.Bids, models.OrderBookLevel{Price: gateFloat(level.P), Size: level.S * ctVal})
+	}
+	for _, level := range response.Asks {
+		book.Asks = append(book.Asks, models.OrderBookLevel{Price: gateFloat(level.P), Size: level.S * ctVal})
+	}
+	return book, nil
+}
+
+// FetchPosition 获取合约持仓（现货没有持仓，返回nil）
+func (c *GateClient) FetchPosition(symbol string) (*models.Position, error) {
+	if c.isSpot() {
+		return nil, nil
+	}
+
+	data, err := c.request("GET", "/futures/usdt/positions/"+c.convertSymbol(symbol), nil, nil, true)
+	if err != nil {
+		if IsKind(err, ErrorKindInvalidOrder) {
+			return nil, nil // POSITION_EMPTY 等
+		}
+		return nil, err
+	}
+
+	var pos struct {
+		Size               float64 `json:"size"` // 张数，负数为空头
+		EntryPrice         string  `json:"entry_price"`
+		Leverage           string  `json:"leverage"` // 0 表示全仓
+		CrossLeverageLimit string  `json:"cross_leverage_limit"`
+		UnrealisedPnl      string  `json:"unrealised_pnl"`
+		LiqPrice           string  `json:"liq_price"`
+		MarkPrice          string  `json:"mark_price"`
+		Margin             string  `json:"margin"`
+	}
+	if err := json.Unmarshal(data, &pos); err != nil {
+		return nil, err
+	}
+	if pos.Size == 0 {
+		return nil, nil
+	}
+
+	side, size := "long", pos.Size
+	if size < 0 {
+		side, size = "short", -size
+	}
+	leverage := gateFloat(pos.Leverage)
+	if leverage == 0 {
+		leverage = gateFloat(pos.CrossLeverageLimit)
+	}
+	size *= c.contractValue(symbol)
+
+	logger.Debugf("[DEBUG] FetchPosition - Side:%s, Size:%.8f, EntryPrice:%s, Upl:%s",
+		side, size, pos.EntryPrice, pos.UnrealisedPnl)
+
+	return &models.Position{
+		Side:             side,
+		Size:             size,
+		EntryPrice:       gateFloat(pos.EntryPrice),
+		UnrealizedPnL:    gateFloat(pos.UnrealisedPnl),
+		Leverage:         int(leverage),
+		Symbol:           symbol,
+		LiquidationPrice: gateFloat(pos.LiqPrice),
+		Margin:           gateFloat(pos.Margin),
+		MarkPrice:        gateFloat(pos.MarkPrice),
+	}, nil
+}
+
+// FetchPositions 获取交易对的全部持仓（Gate.io 为单向持仓，最多一条）
+func (c *GateClient) FetchPositions(symbol string) ([]models.Position, error) {
+	return positionList(c.FetchPosition(symbol))
+}
+
+// FetchBalance 获取可用余额（现货为币种可用余额，合约为USDT合约账户可用保证金）
+func (c *GateClient) FetchBalance(currency string) (float64, error) {
+	if !c.isSpot() && strings.EqualFold(currency, "USDT") {
+		data, err := c.request("GET", "/futures/usdt/accounts", nil, nil, true)
+		if err != nil {
+			return 0, err
+		}
+		var account struct {
+			Available string `json:"available"`
+		}
+		if err := json.Unmarshal(data, &account); err != nil {
+			return 0, err
+		}
+		return gateFloat(account.Available), nil
+	}
+
+	data, err := c.request("GET", "/spot/accounts", url.Values{"currency": {currency}}, nil, true)
+	if err != nil {
+		return 0, err
+	}
+	var accounts []struct {
+		Currency  string `json:"currency"`
+		Available string `json:"available"`
+	}
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return 0, err
+	}
+	for _, account := range accounts {
+		if strings.EqualFold(account.Currency, currency) {
+			return gateFloat(account.Available), nil
+		}
+	}
+	return 0, nil
+}
+
+// FetchAccountSummary 获取账户权益和保证金（合约为USDT合约账户，现货为币种余额）
+func (c *GateClient) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	summary := &models.AccountSummary{Currency: currency}
+	if !c.isSpot() && strings.EqualFold(currency, "USDT") {
+		data, err := c.request("GET", "/futures/usdt/accounts", nil, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		var account struct {
+			Total             string `json:"total"` // 余额（不含未实现盈亏）
+			UnrealisedPnl     string `json:"unrealised_pnl"`
+			Available         string `json:"available"`
+			MaintenanceMargin string `json:"maintenance_margin"`
+		}
+		if err := json.Unmarshal(data, &account); err != nil {
+			return nil, err
+		}
+		summary.TotalEquity = gateFloat(account.Total) + gateFloat(account.UnrealisedPnl)
+		summary.AvailableMargin = gateFloat(account.Available)
+		summary.MaintenanceMargin = gateFloat(account.MaintenanceMargin)
+		return summary, nil
+	}
+
+	data, err := c.request("GET", "/spot/accounts", url.Values{"currency": {currency}}, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []struct {
+		Currency  string `json:"currency"`
+		Available string `json:"available"`
+		Locked    string `json:"locked"`
+	}
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		if strings.EqualFold(account.Currency, currency) {
+			summary.AvailableMargin = gateFloat(account.Available)
+			summary.TotalEquity = summary.AvailableMargin + gateFloat(account.Locked)
+		}
+	}
+	return summary, nil
+}
+
+// gateSpotPair Gate.io现货交易对信息
+type gateSpotPair struct {
+	ID              string `json:"id"`
+	Base            string `json:"base"`
+	Quote           string `json:"quote"`
+	MinBaseAmount   string `json:"min_base_amount"`
+	MinQuoteAmount  string `json:"min_quote_amount"`
+	AmountPrecision int    `json:"amount_precision"`
+	Precision       int    `json:"precision"`
+	TradeStatus     string `json:"trade_status"`
+}
+
+func (p *gateSpotPair) toInstrument() InstrumentInfo {
+	info := InstrumentInfo{
+		InstID:        p.ID,
+		LotSize:       math.Pow(10, -float64(p.AmountPrecision)),
+		MinSize:       gateFloat(p.MinBaseAmount),
+		MinAmount:     gateFloat(p.MinQuoteAmount),
+		TickSize:      math.Pow(10, -float64(p.Precision)),
+		BaseCurrency:  p.Base,
+		QuoteCurrency: p.Quote,
+		State:         "suspend",
+	}
+	if p.TradeStatus == "tradable" {
+		info.State = "live"
+	}
+	return info
+}
+
+// gateContract Gate.io合约信息
+type gateContract struct {
+	Name             string `json:"name"`
+	QuantoMultiplier string `json:"quanto_multiplier"`
+	OrderPriceRound  string `json:"order_price_round"`
+	OrderSizeMin     int64  `json:"order_size_min"`
+	LeverageMax      string `json:"leverage_max"`
+	InDelisting      bool   `json:"in_delisting"`
+}
+
+func (ct *gateContract) toInstrument() InstrumentInfo {
+	base, quote, _ := strings.Cut(ct.Name, "_")
+	info := InstrumentInfo{
+		InstID:        ct.Name,
+		ContractValue: gateFloat(ct.QuantoMultiplier),
+		LotSize:       1,
+		MinSize:       float64(ct.OrderSizeMin),
+		TickSize:      gateFloat(ct.OrderPriceRound),
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		MaxLeverage:   gateFloat(ct.LeverageMax),
+		State:         "live",
+	}
+	if ct.InDelisting {
+		info.State = "delisting"
+	}
+	return info
+}
+
+// GetInstrumentInfo 获取交易对信息（首次查询后缓存；合约的数量单位为张）
+func (c *GateClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
+	pair := c.convertSymbol(symbol)
+
+	c.instrumentsMu.Lock()
+	cached, ok := c.instruments[pair]
+	c.instrumentsMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var info InstrumentInfo
+	if c.isSpot() {
+		data, err := c.request("GET", "/spot/currency_pairs/"+pair, nil, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		var p gateSpotPair
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		info = p.toInstrument()
+	} else {
+		data, err := c.request("GET", "/futures/usdt/contracts/"+pair, nil, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		var ct gateContract
+		if err := json.Unmarshal(data, &ct); err != nil {
+			return nil, err
+		}
+		info = ct.toInstrument()
+	}
+	if info.InstID == "" {
+		return nil, fmt.Errorf("未找到交易对信息: %s", pair)
+	}
+
+	c.instrumentsMu.Lock()
+	c.instruments[pair] = &info
+	c.instrumentsMu.Unlock()
+	return &info, nil
+}
+
+// ListInstruments 获取可交易的交易对列表（instType: spot/swap，为空时按当前交易模式）
+func (c *GateClient) ListInstruments(instType string) ([]InstrumentInfo, error) {
+	spot := c.isSpot()
+	switch strings.ToLower(instType) {
+	case "":
+	case "spot":
+		spot = true
+	case "swap":
+		spot = false
+	default:
+		return nil, fmt.Errorf("Gate.io 不支持的产品类型: %s (支持: spot, swap)", instType)
+	}
+
+	var instruments []InstrumentInfo
+	if spot {
+		data, err := c.request("GET", "/spot/currency_pairs", nil, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		var pairs []gateSpotPair
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return nil, err
+		}
+		for i := range pairs {
+			if info := pairs[i].toInstrument(); info.State == "live" {
+				instruments = append(instruments, info)
+			}
+		}
+		return instruments, nil
+	}
+
+	data, err := c.request("GET", "/futures/usdt/contracts", nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	var contracts []gateContract
+	if err := json.Unmarshal(data, &contracts); err != nil {
+		return nil, err
+	}
+	for i := range contracts {
+		if info := contracts[i].toInstrument(); info.State == "live" {
+			instruments = append(instruments, info)
+		}
+	}
+	return instruments, nil
+}
+
+// PlaceOrder 下市价单（支持现货和合约），返回交易所订单ID
+// 指定 limitPrice 时改为GTC限价单（现货按基础币种数量下单）；
+// 现货市价买单按卖一价把基础币种数量换算为计价币种金额提交（Gate.io 市价买单以金额下单）；
+// 合约数量按合约乘数换算为张数，posSide 忽略（单向持仓），reduceOnly 有效；
+// 附带 stopLossPrice/takeProfitPrice 时在开仓成功后另行提交条件单
+func (c *GateClient) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	info, err := c.GetInstrumentInfo(symbol)
+	if err != nil {
+		return "", fmt.Errorf("获取交易对信息失败: %w", err)
+	}
+
+	clientOrderID, _ := params[ParamClientOrderID].(string)
+	if clientOrderID == "" {
+		clientOrderID = NewClientOrderID()
+	}
+	text := gateText(clientOrderID)
+
+	var path string
+	var orderData map[string]interface{}
+	var size float64 // 提交的数量（现货为基础币种，合约为张数）
+	if c.isSpot() {
+		path = "/spot/orders"
+		orderData, size, err = c.buildSpotOrder(symbol, info, side, amount, params)
+	} else {
+		path = "/futures/usdt/orders"
+		orderData, size, err = c.buildFuturesOrder(info, side, amount, params)
+	}
+	if err != nil {
+		return "", err
+	}
+	orderData["text"] = text
+
+	var orderID string
+	attempt := 0
+	err = c.retry.do("下单", func() error {
+		attempt++
+		if attempt > 1 {
+			// 上次请求结果未知，先按 text 查询，避免重复下单
+			order, err := c.findOrderByText(symbol, text)
+			if err != nil {
+				return err
+			}
+			if order != nil {
+				logger.Printf("[INFO] 订单 %s 已提交成功（order_id: %s），不再重复下单", clientOrderID, order.OrderID)
+				orderID = order.OrderID
+				return nil
+			}
+		}
+		var err error
+		orderID, err = c.submitOrder(path, orderData)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	c.placeBracket(symbol, info, side, size, params)
+	return orderID, nil
+}
+
+// buildSpotOrder 构建现货市价单（指定限价时构建限价单）
+func (c *GateClient) buildSpotOrder(symbol string, info *InstrumentInfo, side string, amount float64, params map[string]interface{}) (map[string]interface{}, float64, error) {
+	size := c.roundToLotSize(amount, info.LotSize)
+	if size < info.MinSize {
+		size = info.MinSize
+	}
+
+	if limit, _ := params[ParamLimitPrice].(float64); limit > 0 {
+		return map[string]interface{}{
+			"currency_pair": info.InstID,
+			"type":          "limit",
+			"account":       "spot",
+			"side":          side,
+			"amount":        strconv.FormatFloat(size, 'f', -1, 64),
+			"price":         strconv.FormatFloat(RoundPrice(limit, info.TickSize), 'f', -1, 64),
+			"time_in_force": "gtc",
+		}, size, nil
+	}
+
+	orderAmount := strconv.FormatFloat(size, 'f', -1, 64)
+	if side == "buy" {
+		ticker, err := c.FetchTicker(symbol)
+		if err != nil {
+			return nil, 0, fmt.Errorf("获取价格失败: %w", err)
+		}
+		price := ticker.Ask
+		if price <= 0 {
+			price = ticker.Last
+		}
+		cost := size * price
+		if info.MinAmount > 0 && cost < info.MinAmount {
+			logger.Printf("[WARNING] 订单金额%.2f不足最小要求%.2f，按最小金额下单", cost, info.MinAmount)
+			cost = info.MinAmount
+		}
+		orderAmount = strconv.FormatFloat(math.Ceil(cost*1e6)/1e6, 'f', -1, 64)
+	}
+
+	logger.Debugf("[DEBUG] 现货下单 - LotSize:%.8f, MinSize:%.8f, MinAmount:%.2f, 数量:%.8f, amount:%s",
+		info.LotSize, info.MinSize, info.MinAmount, size, orderAmount)
+
+	return map[string]interface{}{
+		"currency_pair": info.InstID,
+		"type":          "market",
+		"account":       "spot",
+		"side":          side,
+		"amount":        orderAmount,
+		"time_in_force": "ioc",
+	}, size, nil
+}
+
+// buildFuturesOrder 构建合约市价单（张数为整数，卖出为负数；指定限价时构建GTC限价单）
+func (c *GateClient) buildFuturesOrder(info *InstrumentInfo, side string, amount float64, params map[string]interface{}) (map[string]interface{}, float64, error) {
+	contracts := amount
+	if info.ContractValue > 0 {
+		contracts = amount / info.ContractValue
+	}
+	contracts = math.Floor(contracts + 1e-9)
+	if contracts < info.MinSize {
+		logger.Debugf("[DEBUG] 合约调整 - 张数%.0f < 最小值%.0f, 调整到最小值", contracts, info.MinSize)
+		contracts = info.MinSize
+	}
+
+	logger.Debugf("[DEBUG] 合约下单 - amount:%.8f, 合约乘数:%g, 张数:%.0f", amount, info.ContractValue, contracts)
+
+	signed := int64(contracts)
+	if side == "sell" {
+		signed = -signed
+	}
+	orderData := map[string]interface{}{
+		"contract": info.InstID,
+		"size":     signed,
+		"price":    "0",
+		"tif":      "ioc",
+	}
+	if limit, _ := params[ParamLimitPrice].(float64); limit > 0 {
+		orderData["price"] = strconv.FormatFloat(RoundPrice(limit, info.TickSize), 'f', -1, 64)
+		orderData["tif"] = "gtc"
+	}
+	if reduceOnly, _ := params["reduceOnly"].(bool); reduceOnly {
+		orderData["reduce_only"] = true
+	}
+	return orderData, contracts, nil
+}
+
+// submitOrder 提交订单，返回订单ID
+func (c *GateClient) submitOrder(path string, orderData map[string]interface{}) (string, error) {
+	logger.Debugf("[DEBUG] Gate.io下单请求: %v", orderData)
+
+	data, err := c.request("POST", path, nil, orderData, true)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("[DEBUG] Gate.io响应: %s", string(data))
+
+	var response struct {
+		ID json.Number `json:"id"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+	if response.ID == "" {
+		return "", fmt.Errorf("下单响应缺少订单ID: %s", string(data))
+	}
+	return response.ID.String(), nil
+}
+
+// placeBracket 提交开仓附带的止损/止盈条件单（失败只记录日志，由本地风控兜底）
+// 条件单ID按止损/止盈自定义ID记录在内存中，供 CancelAlgoOrder 撤销
+func (c *GateClient) placeBracket(symbol string, info *InstrumentInfo, side string, size float64, params map[string]interface{}) {
+	legs := []struct {
+		priceKey string
+		idKey    string
+		name     string
+		stopLoss bool
+	}{
+		{ParamStopLossPrice, ParamStopLossClientID, "止损", true},
+		{ParamTakeProfitPrice, ParamTakeProfitClientID, "止盈", false},
+	}
+	for _, leg := range legs {
+		price, _ := params[leg.priceKey].(float64)
+		if price <= 0 {
+			continue
+		}
+		price = RoundPrice(price, info.TickSize)
+		// 多头止损/空头止盈在价格下跌时触发，其余在价格上涨时触发
+		falling := leg.stopLoss == (side == "buy")
+
+		var path string
+		var order map[string]interface{}
+		if c.isSpot() {
+			rule := ">="
+			if falling {
+				rule = "<="
+			}
+			closeSide := "sell"
+			if side == "sell" {
+				closeSide = "buy"
+			}
+			path = "/spot/price_orders"
+			order = map[string]interface{}{
+				"market": info.InstID,
+				"trigger": map[string]interface{}{
+					"price":      strconv.FormatFloat(price, 'f', -1, 64),
+					"rule":       rule,
+					"expiration": 30 * 24 * 3600,
+				},
+				"put": map[string]interface{}{
+					"type":          "market",
+					"side":          closeSide,
+					"price":         strconv.FormatFloat(price, 'f', -1, 64),
+					"amount":        strconv.FormatFloat(size, 'f', -1, 64),
+					"account":       "normal",
+					"time_in_force": "ioc",
+				},
+			}
+		} else {
+			rule := 1 // 价格 >= 触发价
+			if falling {
+				rule = 2 // 价格 <= 触发价
+			}
+			closeSize := -int64(size)
+			if side == "sell" {
+				closeSize = int64(size)
+			}
+			path = "/futures/usdt/price_orders"
+			order = map[string]interface{}{
+				"initial": map[string]interface{}{
+					"contract":    info.InstID,
+					"size":        closeSize,
+					"price":       "0",
+					"tif":         "ioc",
+					"reduce_only": true,
+				},
+				"trigger": map[string]interface{}{
+					"strategy_type": 0,
+					"price_type":    1, // 标记价格
+					"price":         strconv.FormatFloat(price, 'f', -1, 64),
+					"rule":          rule,
+				},
+			}
+		}
+
+		id, err := c.submitOrder(path, order)
+		if err != nil {
+			logger.Warnf("[Gate.io] 提交%s条件单失败: %v", leg.name, err)
+			continue
+		}
+		if clientID, _ := params[leg.idKey].(string); clientID != "" {
+			c.algoMu.Lock()
+			c.algoOrders[clientID] = id
+			c.algoMu.Unlock()
+		}
+	}
+}
+
+// PlaceOrders 批量下单（逐笔提交），返回与请求一一对应的结果
+func (c *GateClient) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	results := make([]OrderResult, len(requests))
+	for i, req := range requests {
+		params := req.Params
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		clientOrderID, _ := params[ParamClientOrderID].(string)
+		if clientOrderID == "" {
+			clientOrderID = NewClientOrderID()
+			params[ParamClientOrderID] = clientOrderID
+		}
+		orderID, err := c.PlaceOrder(req.Symbol, req.Side, req.Amount, params)
+		results[i] = OrderResult{ClientOrderID: clientOrderID, OrderID: orderID, Err: err}
+	}
+	return results, nil
+}
+
+// gateOrder Gate.io订单（现货和合约字段合并）
+type gateOrder struct {
+	ID     json.Number `json:"id"`
+	Text   string      `json:"text"`
+	Status string      `json:"status"` // 现货: open/closed/cancelled；合约: open/finished
+	// 现货字段
+	Type         string `json:"type"`
+	Side         string `json:"side"`
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	FilledAmount string `json:"filled_amount"`
+	AvgDealPrice string `json:"avg_deal_price"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+	CreateTimeMs int64  `json:"create_time_ms"`
+	UpdateTimeMs int64  `json:"update_time_ms"`
+	// 合约字段
+	Size         float64 `json:"size"` // 张数，负数为卖出
+	Left         float64 `json:"left"`
+	FillPrice    string  `json:"fill_price"`
+	FinishAs     string  `json:"finish_as"`
+	IsReduceOnly bool    `json:"is_reduce_only"`
+	CreateTime   float64 `json:"create_time"`
+	FinishTime   float64 `json:"finish_time"`
+}
+
+// toOrder 转换为通用订单结构（合约数量换算为基础币种）
+func (o *gateOrder) toOrder(symbol string, spot bool, ctVal float64) models.Order {
+	order := models.Order{
+		OrderID:       o.ID.String(),
+		ClientOrderID: strings.TrimPrefix(o.Text, "t-"),
+		Symbol:        symbol,
+		Type:          "market",
+	}
+
+	if spot {
+		order.Side = o.Side
+		order.Type = o.Type
+		order.Price = gateFloat(o.Price)
+		order.Size = gateFloat(o.Amount)
+		order.FilledSize = gateFloat(o.FilledAmount)
+		order.AvgPrice = gateFloat(o.AvgDealPrice)
+		order.Fee = -gateFloat(o.Fee) // Gate.io 返回扣除的手续费（正数），统一为负数表示支出
+		order.FeeCurrency = o.FeeCurrency
+		order.CreatedAt = time.UnixMilli(o.CreateTimeMs)
+		order.UpdatedAt = time.UnixMilli(o.UpdateTimeMs)
+		if o.Type == "market" && o.Side == "buy" {
+			// 市价买单的 amount 为计价币种金额
+			order.Size = order.FilledSize
+		}
+		switch o.Status {
+		case "closed":
+			order.State = models.OrderStateFilled
+		case "cancelled":
+			order.State = models.OrderStateCanceled
+		}
+	} else {
+		order.Side = "buy"
+		if o.Size < 0 {
+			order.Side = "sell"
+		}
+		order.Price = gateFloat(o.Price)
+		order.Size = math.Abs(o.Size) * ctVal
+		order.FilledSize = math.Abs(o.Size-o.Left) * ctVal
+		order.AvgPrice = gateFloat(o.FillPrice)
+		order.ReduceOnly = o.IsReduceOnly
+		order.CreatedAt = time.UnixMilli(int64(o.CreateTime * 1000))
+		order.UpdatedAt = order.CreatedAt
+		if o.FinishTime > 0 {
+			order.UpdatedAt = time.UnixMilli(int64(o.FinishTime * 1000))
+		}
+		if o.Status == "finished" {
+			order.State = models.OrderStateCanceled
+			if o.FinishAs == "filled" || o.Left == 0 {
+				order.State = models.OrderStateFilled
+			}
+		}
+	}
+
+	if order.State == "" {
+		order.State = models.OrderStateLive
+		if order.FilledSize > 0 {
+			order.State = models.OrderStatePartiallyFilled
+		}
+	}
+	return order
+}
+
+// orderPath 订单接口路径及交易对查询参数
+func (c *GateClient) orderPath(symbol string) (string, url.Values) {
+	pair := c.convertSymbol(symbol)
+	if c.isSpot() {
+		return "/spot/orders", url.Values{"currency_pair": {pair}}
+	}
+	return "/futures/usdt/orders", url.Values{"contract": {pair}}
+}
+
+// FetchOrder 查询订单
+func (c *GateClient) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	path, query := c.orderPath(symbol)
+	if !c.isSpot() {
+		query = nil
+	}
+
+	data, err := c.request("GET", path+"/"+orderID, query, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var o gateOrder
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	order := o.toOrder(symbol, c.isSpot(), c.contractValue(symbol))
+	return &order, nil
+}
+
+// findOrderByText 在未成交和最近完成的订单中按 text 查找订单，订单不存在时返回 nil, nil
+// （Gate.io 按自定义ID查询只对挂单有效，市价单需在已完成订单中查找）
+func (c *GateClient) findOrderByText(symbol, text string) (*models.Order, error) {
+	path, query := c.orderPath(symbol)
+	for _, status := range []string{"open", "finished"} {
+		query.Set("status", status)
+		query.Set("limit", "100")
+		data, err := c.request("GET", path, query, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		var orders []gateOrder
+		if err := json.Unmarshal(data, &orders); err != nil {
+			return nil, err
+		}
+		for i := range orders {
+			if orders[i].Text == text {
+				order := orders[i].toOrder(symbol, c.isSpot(), c.contractValue(symbol))
+				return &order, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// CancelOrder 撤销订单
+func (c *GateClient) CancelOrder(symbol, orderID string) error {
+	path, query := c.orderPath(symbol)
+	if !c.isSpot() {
+		query = nil
+	}
+	if _, err := c.request("DELETE", path+"/"+orderID, query, nil, true); err != nil {
+		return fmt.Errorf("撤单失败: %w", err)
+	}
+	return nil
+}
+
+// CancelAlgoOrder 撤销止损/止盈条件单（已触发、已撤销或本进程未记录时返回nil）
+func (c *GateClient) CancelAlgoOrder(symbol, clientID string) error {
+	c.algoMu.Lock()
+	id, ok := c.algoOrders[clientID]
+	c.algoMu.Unlock()
+	if !ok {
+		logger.Warnf("[Gate.io] 未找到条件单 %s 的记录（可能已在重启前提交），请在交易所确认", clientID)
+		return nil
+	}
+
+	path := "/futures/usdt/price_orders/" + id
+	if c.isSpot() {
+		path = "/spot/price_orders/" + id
+	}
+	_, err := c.request("DELETE", path, nil, nil, true)
+	if err != nil && !IsKind(err, ErrorKindInvalidOrder) {
+		return err
+	}
+
+	c.algoMu.Lock()
+	delete(c.algoOrders, clientID)
+	c.algoMu.Unlock()
+	return nil
+}
+
+// FetchOpenOrders 获取未成交订单
+func (c *GateClient) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	path, query := c.orderPath(symbol)
+	query.Set("status", "open")
+
+	data, err := c.request("GET", path, query, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []gateOrder
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	ctVal := c.contractValue(symbol)
+	orders := make([]models.Order, 0, len(response))
+	for i := range response {
+		orders = append(orders, response[i].toOrder(symbol, c.isSpot(), ctVal))
+	}
+	return orders, nil
+}
+
+// FetchMyTrades 获取账户成交记录，按时间升序返回
+func (c *GateClient) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	if c.isSpot() {
+		return c.fetchSpotTrades(symbol, since)
+	}
+	return c.fetchFuturesTrades(symbol, since)
+}
+
+// fetchSpotTrades 获取现货成交记录（按页查询）
+func (c *GateClient) fetchSpotTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	const pageSize = 1000
+	var trades []models.Trade
+	for page := 1; ; page++ {
+		query := url.Values{
+			"currency_pair": {c.convertSymbol(symbol)},
+			"limit":         {strconv.Itoa(pageSize)},
+			"page":          {strconv.Itoa(page)},
+		}
+		if !since.IsZero() {
+			query.Set("from", strconv.FormatInt(since.Unix(), 10))
+		}
+		data, err := c.request("GET", "/spot/my_trades", query, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var fills []struct {
+			ID           string `json:"id"`
+			CreateTimeMs string `json:"create_time_ms"`
+			Side         string `json:"side"`
+			Role         string `json:"role"`
+			Amount       string `json:"amount"`
+			Price        string `json:"price"`
+			OrderID      string `json:"order_id"`
+			Fee          string `json:"fee"`
+			FeeCurrency  string `json:"fee_currency"`
+		}
+		if err := json.Unmarshal(data, &fills); err != nil {
+			return nil, err
+		}
+		for _, f := range fills {
+			ms := gateFloat(f.CreateTimeMs)
+			trades = append(trades, models.Trade{
+				TradeID:     f.ID,
+				OrderID:     f.OrderID,
+				Symbol:      symbol,
+				Side:        f.Side,
+				Price:       gateFloat(f.Price),
+				Size:        gateFloat(f.Amount),
+				Fee:         -gateFloat(f.Fee),
+				FeeCurrency: f.FeeCurrency,
+				IsMaker:     f.Role == "maker",
+				Timestamp:   time.UnixMilli(int64(ms)),
+			})
+		}
+		if len(fills) < pageSize {
+			break
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	return trades, nil
+}
+
+// fetchFuturesTrades 获取合约成交记录（按偏移量分页，数量换算为基础币种）
+func (c *GateClient) fetchFuturesTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	const pageSize = 1000
+	ctVal := c.contractValue(symbol)
+	var trades []models.Trade
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"contract": {c.convertSymbol(symbol)},
+			"limit":    {strconv.Itoa(pageSize)},
+			"offset":   {strconv.Itoa(offset)},
+		}
+		if !since.IsZero() {
+			query.Set("from", strconv.FormatInt(since.Unix(), 10))
+		}
+		data, err := c.request("GET", "/futures/usdt/my_trades_timerange", query, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var fills []struct {
+			TradeID    json.Number `json:"trade_id"`
+			CreateTime float64     `json:"create_time"`
+			OrderID    json.Number `json:"order_id"`
+			Size       float64     `json:"size"`
+			Price      string      `json:"price"`
+			Role       string      `json:"role"`
+			Fee        string      `json:"fee"`
+		}
+		if err := json.Unmarshal(data, &fills); err != nil {
+			return nil, err
+		}
+		for _, f := range fills {
+			side := "buy"
+			if f.Size < 0 {
+				side = "sell"
+			}
+			trades = append(trades, models.Trade{
+				TradeID:     f.TradeID.String(),
+				OrderID:     f.OrderID.String(),
+				Symbol:      symbol,
+				Side:        side,
+				Price:       gateFloat(f.Price),
+				Size:        math.Abs(f.Size) * ctVal,
+				Fee:         -gateFloat(f.Fee),
+				FeeCurrency: "USDT",
+				IsMaker:     f.Role == "maker",
+				Timestamp:   time.UnixMilli(int64(f.CreateTime * 1000)),
+			})
+		}
+		if len(fills) < pageSize {
+			break
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	return trades, nil
+}
+
+// gateAccountBookEntry USDT永续合约账户流水
+type gateAccountBookEntry struct {
+	ID       json.Number `json:"id"`
+	Time     float64     `json:"time"`
+	Change   string      `json:"change"` // 余额变动（正数转入/收入，负数转出/支出）
+	Type     string      `json:"type"`   // dnw: 转入转出, fund: 资金费用
+	Contract string      `json:"contract"`
+}
+
+// fetchAccountBook 分页获取USDT永续合约账户流水
+func (c *GateClient) fetchAccountBook(bookType, contract string, since time.Time) ([]gateAccountBookEntry, error) {
+	const pageSize = 1000
+	var entries []gateAccountBookEntry
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"type":   {bookType},
+			"limit":  {strconv.Itoa(pageSize)},
+			"offset": {strconv.Itoa(offset)},
+		}
+		if contract != "" {
+			query.Set("contract", contract)
+		}
+		if !since.IsZero() {
+			query.Set("from", strconv.FormatInt(since.Unix(), 10))
+		}
+		data, err := c.request("GET", "/futures/usdt/account_book", query, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []gateAccountBookEntry
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// FetchFundingFees 获取资金费用记录（合约账户流水中的资金费用）
+func (c *GateClient) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	if c.isSpot() {
+		return nil, nil
+	}
+	entries, err := c.fetchAccountBook("fund", c.convertSymbol(symbol), since)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := make([]models.FundingFee, 0, len(entries))
+	for _, e := range entries {
+		fees = append(fees, models.FundingFee{
+			Symbol:    symbol,
+			Amount:    gateFloat(e.Change),
+			Currency:  "USDT",
+			Timestamp: time.UnixMilli(int64(e.Time * 1000)),
+		})
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i].Timestamp.Before(fees[j].Timestamp) })
+	return fees, nil
+}
+
+// gateWalletWindow 充值、提现记录单次查询的最大时间跨度
+const gateWalletWindow = 30 * 24 * time.Hour
+
+// FetchTransfers 获取充值、提现（现货账户）记录，合约模式下另含合约账户的转入转出
+// 充值、提现接口单次最多查询30天，起始时间较早时按30天分段查询；未指定起始时间时使用交易所默认范围
+func (c *GateClient) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	var transfers []models.AccountTransfer
+
+	windows := [][2]time.Time{{}}
+	if !since.IsZero() {
+		windows = nil
+		for from, now := since, time.Now(); from.Before(now); from = from.Add(gateWalletWindow) {
+			windows = append(windows, [2]time.Time{from, from.Add(gateWalletWindow)})
+		}
+	}
+	for _, window := range windows {
+		deposits, err := c.fetchWalletRecords("/wallet/deposits", currency, window[0], window[1])
+		if err != nil {
+			return nil, fmt.Errorf("获取充值记录失败: %w", err)
+		}
+		for _, record := range deposits {
+			transfers = append(transfers, record.transfer(models.TransferDeposit, 1))
+		}
+
+		withdrawals, err := c.fetchWalletRecords("/wallet/withdrawals", currency, window[0], window[1])
+		if err != nil {
+			return nil, fmt.Errorf("获取提现记录失败: %w", err)
+		}
+		for _, record := range withdrawals {
+			transfers = append(transfers, record.transfer(models.TransferWithdrawal, -1))
+		}
+	}
+
+	if !c.isSpot() {
+		entries, err := c.fetchAccountBook("dnw", "", since)
+		if err != nil {
+			return nil, fmt.Errorf("获取合约账户划转记录失败: %w", err)
+		}
+		for _, e := range entries {
+			transfers = append(transfers, models.AccountTransfer{
+				ID:        e.ID.String(),
+				Type:      models.TransferInternal,
+				Currency:  "USDT",
+				Amount:    gateFloat(e.Change),
+				Timestamp: time.UnixMilli(int64(e.Time * 1000)),
+			})
+		}
+	}
+
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].Timestamp.Before(transfers[j].Timestamp) })
+	return transfers, nil
+}
+
+// gateWalletRecord 充值/提现记录
+type gateWalletRecord struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"` // 秒
+	Amount    string `json:"amount"`
+	Fee       string `json:"fee"` // 提现手续费（正数）
+	Currency  string `json:"currency"`
+	Status    string `json:"status"`
+}
+
+// transfer 转换为资金流水，direction 为 1（转入）或 -1（转出）
+func (r gateWalletRecord) transfer(transferType string, direction float64) models.AccountTransfer {
+	ts, _ := strconv.ParseInt(r.Timestamp, 10, 64)
+	return models.AccountTransfer{
+		ID:        r.ID,
+		Type:      transferType,
+		Currency:  r.Currency,
+		Amount:    direction * gateFloat(r.Amount),
+		Fee:       -gateFloat(r.Fee),
+		Timestamp: time.Unix(ts, 0),
+	}
+}
+
+// fetchWalletRecords 分页获取时间范围内已完成的充值或提现记录（from 为零值时使用交易所默认范围）
+func (c *GateClient) fetchWalletRecords(path, currency string, from, to time.Time) ([]gateWalletRecord, error) {
+	const pageSize = 500
+	var records []gateWalletRecord
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"currency": {currency},
+			"limit":    {strconv.Itoa(pageSize)},
+			"offset":   {strconv.Itoa(offset)},
+		}
+		if !from.IsZero() {
+			query.Set("from", strconv.FormatInt(from.Unix(), 10))
+			query.Set("to", strconv.FormatInt(to.Unix(), 10))
+		}
+		data, err := c.request("GET", path, query, nil, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []gateWalletRecord
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			if record.Status == "DONE" {
+				records = append(records, record)
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	return records, nil
+}
+
+// FetchTradingFees 获取账户手续费率（正数表示支出）
+func (c *GateClient) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	query := url.Values{"currency_pair": {c.convertSymbol(symbol)}}
+	if !c.isSpot() {
+		query.Set("settle", "usdt")
+	}
+	data, err := c.request("GET", "/wallet/fee", query, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var fee struct {
+		MakerFee        string `json:"maker_fee"`
+		TakerFee        string `json:"taker_fee"`
+		FuturesMakerFee string `json:"futures_maker_fee"`
+		FuturesTakerFee string `json:"futures_taker_fee"`
+	}
+	if err := json.Unmarshal(data, &fee); err != nil {
+		return nil, err
+	}
+
+	maker, taker := fee.MakerFee, fee.TakerFee
+	if !c.isSpot() {
+		maker, taker = fee.FuturesMakerFee, fee.FuturesTakerFee
+	}
+	return &models.FeeRate{
+		Symbol: symbol,
+		Maker:  gateFloat(maker),
+		Taker:  gateFloat(taker),
+	}, nil
+}
+
+// SetLeverage 设置合约杠杆（逐仓杠杆，现货无需设置）
+func (c *GateClient) SetLeverage(symbol string, leverage int) error {
+	if c.isSpot() {
+		return nil
+	}
+	path := fmt.Sprintf("/futures/usdt/positions/%s/leverage", c.convertSymbol(symbol))
+	_, err := c.request("POST", path, url.Values{"leverage": {strconv.Itoa(leverage)}}, nil, true)
+	return err
+}
+
+// Transfer 现货账户（资金账户）与USDT永续合约账户（交易账户）之间划转
+// 现货模式下交易账户即现货账户，无需划转
+func (c *GateClient) Transfer(currency string, amount float64, from, to string) error {
+	if c.isSpot() {
+		return fmt.Errorf("Gate.io 现货模式下交易账户即现货账户，无需划转")
+	}
+	accounts := map[string]string{AccountFunding: "spot", AccountTrading: "futures"}
+	fromAccount, ok := accounts[from]
+	if !ok {
+		return fmt.Errorf("Gate.io 不支持的划转账户: %s", from)
+	}
+	toAccount, ok := accounts[to]
+	if !ok {
+		return fmt.Errorf("Gate.io 不支持的划转账户: %s", to)
+	}
+
+	body := map[string]interface{}{
+		"currency": currency,
+		"from":     fromAccount,
+		"to":       toAccount,
+		"amount":   strconv.FormatFloat(amount, 'f', -1, 64),
+		"settle":   "usdt",
+	}
+	_, err := c.request("POST", "/wallet/transfers", nil, body, true)
+	return err
+}
+
+// roundToLotSize 按数量精度向下取整
+func (c *GateClient) roundToLotSize(size, lotSize float64) float64 {
+	return RoundSize(size, lotSize)
+}
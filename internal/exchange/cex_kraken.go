@@ -0,0 +1,1119 @@
+package exchange
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+const (
+	KrakenFuturesBaseURL     = "https://futures.kraken.com"
+	KrakenFuturesDemoBaseURL = "https://demo-futures.kraken.com"
+)
+
+// krakenAPIPrefix Kraken Futures REST 接口前缀（签名时去掉 /derivatives）
+const krakenAPIPrefix = "/derivatives/api/v3"
+
+// krakenDefaultRateLimits Kraken默认限流配置（按官方限速留出余量）
+var krakenDefaultRateLimits = map[string]config.RateLimitConfig{
+	"public":  {RequestsPerSecond: 10, Burst: 20},
+	"private": {RequestsPerSecond: 5, Burst: 10},
+	"charts":  {RequestsPerSecond: 5, Burst: 10},
+}
+
+// krakenResolutions Kraken K线周期
+var krakenResolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// KrakenClient Kraken Futures 客户端（多抵押永续合约 PF_*，单向持仓）
+// 数量单位为基础币种（PF 合约面值为1个基础币种）
+type KrakenClient struct {
+	apiKey     string
+	secret     []byte // base64 解码后的API密钥
+	baseURL    string
+	httpClient *nets.HttpClient
+	rateLimit  *RateLimiter
+	retry      *retryPolicy
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+
+	instrumentsMu sync.Mutex
+	instruments   map[string]*InstrumentInfo // Kraken合约代码 -> 合约信息
+}
+
+// NewKrakenClient 创建Kraken Futures客户端（仅支持合约模式）
+func NewKrakenClient(cfg *config.APIConfig, tradingMode config.TradingMode) (*KrakenClient, error) {
+	if tradingMode != config.TradingModeFutures {
+		return nil, fmt.Errorf("Kraken 仅支持合约交易模式")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(cfg.KrakenSecret)
+	if err != nil {
+		return nil, fmt.Errorf("Kraken API密钥格式错误（应为base64）: %w", err)
+	}
+
+	httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+	}
+
+	baseURL := KrakenFuturesBaseURL
+	if cfg.UseTestnet {
+		baseURL = KrakenFuturesDemoBaseURL
+	}
+
+	return &KrakenClient{
+		apiKey:      cfg.KrakenAPIKey,
+		secret:      secret,
+		baseURL:     baseURL,
+		httpClient:  httpClient,
+		rateLimit:   NewRateLimiter(krakenDefaultRateLimits, cfg.RateLimits),
+		retry:       newRetryPolicy(cfg.Retry),
+		instruments: make(map[string]*InstrumentInfo),
+	}, nil
+}
+
+// GetExchangeName 获取交易所名称
+func (c *KrakenClient) GetExchangeName() string {
+	return string(config.ExchangeKraken)
+}
+
+// Capabilities 获取交易所支持的功能（仅合约，单向持仓，批量下单逐笔提交）
+func (c *KrakenClient) Capabilities() Capabilities {
+	return Capabilities{
+		Futures:     true,
+		AlgoOrders:  true,
+		LimitOrders: true,
+	}
+}
+
+// ParseSymbols 解析交易对符号（BTC, USD -> BTC/USD:USD）
+func (c *KrakenClient) ParseSymbols(symbolA, symbolB string) string {
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+// convertSymbol 转换为Kraken合约代码（BTC/USD:USD -> PF_XBTUSD）
+// Kraken 永续合约以美元计价，USDT/USDC 计价的交易对映射到对应的USD合约
+func (c *KrakenClient) convertSymbol(symbol string) string {
+	base, quote := splitSymbol(symbol)
+	if base == "BTC" {
+		base = "XBT"
+	}
+	switch quote {
+	case "", "USDT", "USDC":
+		quote = "USD"
+	}
+	return "PF_" + base + quote
+}
+
+// fromKrakenSymbol Kraken合约代码转换为通用符号（PF_XBTUSD -> BTC/USD:USD）
+func fromKrakenSymbol(instID string) (string, string) {
+	pair := strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(instID, "PF_"), "pf_"))
+	if !strings.HasSuffix(pair, "USD") {
+		return pair, ""
+	}
+	base := strings.TrimSuffix(pair, "USD")
+	if base == "XBT" {
+		base = "BTC"
+	}
+	return base, "USD"
+}
+
+// nonce 生成单调递增的请求序号
+func (c *KrakenClient) nonce() string {
+	c.nonceMu.Lock()
+	defer c.nonceMu.Unlock()
+	n := time.Now().UnixMilli()
+	if n <= c.lastNonce {
+		n = c.lastNonce + 1
+	}
+	c.lastNonce = n
+	return strconv.FormatInt(n, 10)
+}
+
+// sign 生成签名: base64(HMAC-SHA512(secret, SHA256(postData + nonce + endpointPath)))
+func (c *KrakenClient) sign(postData, nonce, endpointPath string) string {
+	digest := sha256.Sum256([]byte(postData + nonce + endpointPath))
+	h := hmac.New(sha512.New, c.secret)
+	h.Write(digest[:])
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// krakenErrorKind 根据Kraken错误信息分类
+func krakenErrorKind(msg string) ErrorKind {
+	switch msg {
+	case "apiLimitExceeded", "Server Error", "Unavailable", "requestTimeout":
+		return ErrorKindRetryable
+	case "authenticationError", "nonceBelowThreshold", "nonceDuplicate", "accountInactive":
+		return ErrorKindAuthFailed
+	case "insufficientAvailableFunds", "insufficientFunds":
+		return ErrorKindInsufficientBalance
+	case "invalidSize", "tooSmall", "invalidPrice", "invalidArgument", "marketSuspended",
+		"wouldNotReducePosition", "contractNotFound", "invalidUnit", "orderForEditNotFound":
+		return ErrorKindInvalidOrder
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// apiError 构造 ExchangeError
+func (c *KrakenClient) apiError(msg string) *ExchangeError {
+	return &ExchangeError{Exchange: "Kraken", Code: msg, Message: msg, Kind: krakenErrorKind(msg)}
+}
+
+// request 发送HTTP请求
+// GET请求遇到临时性错误时自动重试；POST/PUT请求不在此重试，由调用方按幂等方式处理
+// endpoint 为 /derivatives/api/v3 之后的路径（如 /tickers），private 表示需要签名
+func (c *KrakenClient) request(method, endpoint string, params url.Values, private bool) ([]byte, error) {
+	if method != "GET" {
+		return c.doRequest(method, endpoint, params, private)
+	}
+
+	var data []byte
+	err := c.retry.do(method+" "+endpoint, func() error {
+		var err error
+		data, err = c.doRequest(method, endpoint, params, private)
+		return err
+	})
+	return data, err
+}
+
+// doRequest 发送单次HTTP请求，网络错误、5xx、限流等临时性错误返回可重试的 ExchangeError
+func (c *KrakenClient) doRequest(method, endpoint string, params url.Values, private bool) ([]byte, error) {
+	group := "public"
+	if private {
+		group = "private"
+	}
+	c.rateLimit.Wait(group)
+
+	postData := params.Encode()
+	fullURL := c.baseURL + krakenAPIPrefix + endpoint
+	var body []byte
+	if postData != "" {
+		if method == "POST" {
+			body = []byte(postData)
+		} else {
+			fullURL += "?" + postData
+		}
+	}
+
+	headers := map[string]string{"Accept": "application/json"}
+	if method == "POST" {
+		headers["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+	if private {
+		if c.apiKey == "" {
+			return nil, &ExchangeError{Exchange: "Kraken", Message: "未配置API Key", Kind: ErrorKindAuthFailed}
+		}
+		nonce := c.nonce()
+		headers["APIKey"] = c.apiKey
+		headers["Nonce"] = nonce
+		headers["Authent"] = c.sign(postData, nonce, "/api/v3"+endpoint)
+	}
+
+	resp, err := c.httpClient.QueryRaw(method, fullURL, headers, body)
+	if err != nil {
+		return nil, &ExchangeError{Exchange: "Kraken", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+	}
+
+	var envelope struct {
+		Result string `json:"result"`
+		Error  string `json:"error"`
+	}
+	isJSON := json.Unmarshal(resp.Body, &envelope) == nil
+
+	if !resp.IsSuccess() {
+		exErr := &ExchangeError{
+			Exchange:   "Kraken",
+			HTTPStatus: resp.StatusCode,
+			Headers:    rateLimitHeaders(resp.Header),
+		}
+		if isJSON && envelope.Error != "" {
+			exErr.Code = envelope.Error
+			exErr.Message = envelope.Error
+			exErr.Kind = krakenErrorKind(envelope.Error)
+		} else {
+			bodyText := string(resp.Body)
+			if len(bodyText) > 512 {
+				bodyText = bodyText[:512] + "..."
+			}
+			exErr.Message = bodyText
+		}
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			exErr.Kind = ErrorKindRetryable
+		} else if resp.StatusCode == 401 || resp.StatusCode == 403 {
+			exErr.Kind = ErrorKindAuthFailed
+		}
+		return nil, exErr
+	}
+
+	if isJSON && envelope.Result == "error" {
+		return nil, c.apiError(envelope.Error)
+	}
+
+	return resp.Body, nil
+}
+
+// FetchOHLCV 获取K线数据（charts 接口按时间范围查询）
+func (c *KrakenClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	resolution, ok := krakenResolutions[timeframe]
+	if !ok {
+		return nil, fmt.Errorf("Kraken 不支持的K线周期: %s", timeframe)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	to := time.Now()
+	from := to.Add(-time.Duration(limit+1) * resolution)
+	path := fmt.Sprintf("%s/api/charts/v1/trade/%s/%s?from=%d&to=%d",
+		c.baseURL, c.convertSymbol(symbol), timeframe, from.Unix(), to.Unix())
+
+	var data []byte
+	err := c.retry.do("GET charts", func() error {
+		c.rateLimit.Wait("charts")
+		resp, err := c.httpClient.QueryRaw("GET", path, nets.DefaultHeadersGet, nil)
+		if err != nil {
+			return &ExchangeError{Exchange: "Kraken", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+		}
+		if !resp.IsSuccess() {
+			kind := ErrorKindUnknown
+			if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+				kind = ErrorKindRetryable
+			}
+			return &ExchangeError{Exchange: "Kraken", HTTPStatus: resp.StatusCode, Message: string(resp.Body), Kind: kind}
+		}
+		data = resp.Body
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Candles []struct {
+			Time   int64       `json:"time"`
+			Open   json.Number `json:"open"`
+			High   json.Number `json:"high"`
+			Low    json.Number `json:"low"`
+			Close  json.Number `json:"close"`
+			Volume json.Number `json:"volume"`
+		} `json:"candles"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	ohlcvList := make([]models.OHLCV, 0, len(response.Candles))
+	for _, candle := range response.Candles {
+		open, _ := candle.Open.Float64()
+		high, _ := candle.High.Float64()
+		low, _ := candle.Low.Float64()
+		closePrice, _ := candle.Close.Float64()
+		volume, _ := candle.Volume.Float64()
+		ohlcvList = append(ohlcvList, models.OHLCV{
+			Timestamp: time.UnixMilli(candle.Time),
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+		})
+	}
+	sort.Slice(ohlcvList, func(i, j int) bool { return ohlcvList[i].Timestamp.Before(ohlcvList[j].Timestamp) })
+	if len(ohlcvList) > limit {
+		ohlcvList = ohlcvList[len(ohlcvList)-limit:]
+	}
+	return ohlcvList, nil
+}
+
+// FetchTicker 获取最新行情（含标记价格和指数价格）
+func (c *KrakenClient) FetchTicker(symbol string) (*models.Ticker, error) {
+	data, err := c.request("GET", "/tickers/"+c.convertSymbol(symbol), nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Ticker struct {
+			Symbol     string  `json:"symbol"`
+			Last       float64 `json:"last"`
+			Bid        float64 `json:"bid"`
+			Ask        float64 `json:"ask"`
+			MarkPrice  float64 `json:"markPrice"`
+			IndexPrice float64 `json:"indexPrice"`
+		} `json:"ticker"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Ticker.Symbol == "" {
+		return nil, c.apiError("contractNotFound")
+	}
+
+	return &models.Ticker{
+		Symbol: symbol,
+		Last:   response.Ticker.Last,
+		Bid:    response.Ticker.Bid,
+		Ask:    response.Ticker.Ask,
+		Mark:   response.Ticker.MarkPrice,
+		Index:  response.Ticker.IndexPrice,
+	}, nil
+}
+
+// FetchDerivativesStats 获取永续合约的资金费率、持仓量和标记/指数价格
+// Kraken 的资金费率为每小时每张合约的绝对金额，按标记价格换算为相对费率
+func (c *KrakenClient) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	data, err := c.request("GET", "/tickers/"+c.convertSymbol(symbol), nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Ticker struct {
+			Symbol       string  `json:"symbol"`
+			FundingRate  float64 `json:"fundingRate"`
+			OpenInterest float64 `json:"openInterest"`
+			MarkPrice    float64 `json:"markPrice"`
+			IndexPrice   float64 `json:"indexPrice"`
+		} `json:"ticker"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Ticker.Symbol == "" {
+		return nil, c.apiError("contractNotFound")
+	}
+
+	t := response.Ticker
+	stats := &models.DerivativesStats{
+		FundingIntervalHours: 1,
+		OpenInterest:         t.OpenInterest,
+		MarkPrice:            t.MarkPrice,
+		IndexPrice:           t.IndexPrice,
+	}
+	if t.MarkPrice > 0 {
+		stats.FundingRate = t.FundingRate / t.MarkPrice
+	}
+	return stats, nil
+}
+
+// FetchOrderBook 获取盘口深度
+func (c *KrakenClient) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	params := url.Values{"symbol": {c.convertSymbol(symbol)}}
+	data, err := c.request("GET", "/orderbook", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		ServerTime time.Time `json:"serverTime"`
+		OrderBook  struct {
+			Bids [][2]float64 `json:"bids"`
+			Asks [][2]float64 `json:"asks"`
+		} `json:"orderBook"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	book := &models.OrderBook{Symbol: symbol, Timestamp: response.ServerTime}
+	for i, level := range response.OrderBook.Bids {
+		if depth > 0 && i >= depth {
+			break
+		}
+		book.Bids = append(book.Bids, models.OrderBookLevel{Price: level[0], Size: level[1]})
+	}
+	for i, level := range response.OrderBook.Asks {
+		if depth > 0 && i >= depth {
+			break
+		}
+		book.Asks = append(book.Asks, models.OrderBookLevel{Price: level[0], Size: level[1]})
+	}
+	if book.Timestamp.IsZero() {
+		book.Timestamp = time.Now()
+	}
+	return book, nil
+}
+
+// FetchPosition 获取持仓信息
+func (c *KrakenClient) FetchPosition(symbol string) (*models.Position, error) {
+	data, err := c.request("GET", "/openpositions", nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		OpenPositions []struct {
+			Side             string   `json:"side"`
+			Symbol           string   `json:"symbol"`
+			Price            float64  `json:"price"`
+			Size             float64  `json:"size"`
+			UnrealizedPnL    float64  `json:"pnl"`
+			MaxFixedLeverage *float64 `json:"maxFixedLeverage"`
+		} `json:"openPositions"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	instID := c.convertSymbol(symbol)
+	for _, pos := range response.OpenPositions {
+		if !strings.EqualFold(pos.Symbol, instID) || pos.Size <= 0 {
+			continue
+		}
+		logger.Debugf("[DEBUG] FetchPosition - Side:%s, Size:%.8f, Price:%.2f", pos.Side, pos.Size, pos.Price)
+
+		position := &models.Position{
+			Side:          pos.Side,
+			Size:          pos.Size,
+			EntryPrice:    pos.Price,
+			UnrealizedPnL: pos.UnrealizedPnL,
+			Symbol:        symbol,
+		}
+		if pos.MaxFixedLeverage != nil {
+			position.Leverage = int(*pos.MaxFixedLeverage)
+		}
+		if position.UnrealizedPnL == 0 {
+			// 部分账户类型不返回盈亏，按标记价格估算
+			if ticker, err := c.FetchTicker(symbol); err == nil {
+				price := ticker.Price(models.PriceSourceMark)
+				if pos.Side == "short" {
+					position.UnrealizedPnL = (pos.Price - price) * pos.Size
+				} else {
+					position.UnrealizedPnL = (price - pos.Price) * pos.Size
+				}
+			}
+		}
+		return position, nil
+	}
+
+	return nil, nil
+}
+
+// FetchPositions 获取交易对的全部持仓（Kraken 为单向持仓，最多一条）
+func (c *KrakenClient) FetchPositions(symbol string) ([]models.Position, error) {
+	return positionList(c.FetchPosition(symbol))
+}
+
+// FetchBalance 获取可用余额
+// 计价币种（USD/USDT/USDC）返回多抵押账户的可用保证金，其他币种返回该抵押币种的可用数量
+func (c *KrakenClient) FetchBalance(currency string) (float64, error) {
+	data, err := c.request("GET", "/accounts", nil, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Accounts map[string]struct {
+			Type            string  `json:"type"`
+			AvailableMargin float64 `json:"availableMargin"`
+			Currencies      map[string]struct {
+				Quantity  float64  `json:"quantity"`
+				Available *float64 `json:"available"`
+			} `json:"currencies"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, err
+	}
+
+	flex, ok := response.Accounts["flex"]
+	if !ok {
+		return 0, nil
+	}
+	switch strings.ToUpper(currency) {
+	case "USD", "USDT", "USDC":
+		return flex.AvailableMargin, nil
+	}
+	if currency == "BTC" {
+		currency = "XBT"
+	}
+	for ccy, balance := range flex.Currencies {
+		if strings.EqualFold(ccy, currency) {
+			if balance.Available != nil {
+				return *balance.Available, nil
+			}
+			return balance.Quantity, nil
+		}
+	}
+	return 0, nil
+}
+
+// FetchAccountSummary 获取多抵押账户权益和保证金（以美元计，currency 仅用于标注）
+func (c *KrakenClient) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	data, err := c.request("GET", "/accounts", nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Accounts map[string]struct {
+			MarginEquity      float64 `json:"marginEquity"` // 保证金权益（抵押物折算 + 未实现盈亏）
+			AvailableMargin   float64 `json:"availableMargin"`
+			MaintenanceMargin float64 `json:"maintenanceMargin"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	flex := response.Accounts["flex"]
+	return &models.AccountSummary{
+		Currency:          currency,
+		TotalEquity:       flex.MarginEquity,
+		AvailableMargin:   flex.AvailableMargin,
+		MaintenanceMargin: flex.MaintenanceMargin,
+	}, nil
+}
+
+// GetInstrumentInfo 获取合约信息（首次查询后缓存）
+func (c *KrakenClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
+	instID := c.convertSymbol(symbol)
+
+	c.instrumentsMu.Lock()
+	info, ok := c.instruments[instID]
+	c.instrumentsMu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	if _, err := c.ListInstruments(""); err != nil {
+		return nil, err
+	}
+
+	c.instrumentsMu.Lock()
+	defer c.instrumentsMu.Unlock()
+	info, ok = c.instruments[instID]
+	if !ok {
+		return nil, fmt.Errorf("Kraken 不存在合约 %s", instID)
+	}
+	return info, nil
+}
+
+// ListInstruments 获取可交易的永续合约列表（Kraken Futures 无现货，instType 仅支持 swap 或空）
+func (c *KrakenClient) ListInstruments(instType string) ([]InstrumentInfo, error) {
+	if instType != "" && instType != "swap" {
+		return nil, fmt.Errorf("Kraken Futures 不支持产品类型: %s", instType)
+	}
+
+	data, err := c.request("GET", "/instruments", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Instruments []struct {
+			Symbol                      string  `json:"symbol"`
+			Type                        string  `json:"type"`
+			Tradeable                   bool    `json:"tradeable"`
+			TickSize                    float64 `json:"tickSize"`
+			ContractSize                float64 `json:"contractSize"`
+			ContractValueTradePrecision float64 `json:"contractValueTradePrecision"`
+			MarginLevels                []struct {
+				InitialMargin float64 `json:"initialMargin"`
+			} `json:"marginLevels"`
+		} `json:"instruments"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	var instruments []InstrumentInfo
+	cache := make(map[string]*InstrumentInfo)
+	for _, inst := range response.Instruments {
+		if inst.Type != "flexible_futures" || !strings.HasPrefix(strings.ToUpper(inst.Symbol), "PF_") {
+			continue
+		}
+		base, quote := fromKrakenSymbol(inst.Symbol)
+		lotSize := math.Pow(10, -inst.ContractValueTradePrecision)
+		contractSize := inst.ContractSize
+		if contractSize <= 0 {
+			contractSize = 1
+		}
+		info := InstrumentInfo{
+			InstID:        strings.ToUpper(inst.Symbol),
+			ContractValue: contractSize,
+			LotSize:       lotSize,
+			MinSize:       lotSize,
+			TickSize:      inst.TickSize,
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			State:         "suspend",
+		}
+		if inst.Tradeable {
+			info.State = "live"
+		}
+		if len(inst.MarginLevels) > 0 && inst.MarginLevels[0].InitialMargin > 0 {
+			info.MaxLeverage = math.Round(1 / inst.MarginLevels[0].InitialMargin)
+		}
+		infoCopy := info
+		cache[info.InstID] = &infoCopy
+		if inst.Tradeable {
+			instruments = append(instruments, info)
+		}
+	}
+
+	c.instrumentsMu.Lock()
+	c.instruments = cache
+	c.instrumentsMu.Unlock()
+
+	return instruments, nil
+}
+
+// PlaceOrder 下市价单
+// 支持参数: clientOrderID、reduceOnly、limitPrice（改为GTC限价单）；posSide 忽略（Kraken 为单向持仓）；
+// 附带 stopLossPrice/takeProfitPrice 时在开仓成交后另行提交只减仓的止损/止盈触发单
+func (c *KrakenClient) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	info, err := c.GetInstrumentInfo(symbol)
+	if err != nil {
+		return "", fmt.Errorf("获取合约信息失败: %w", err)
+	}
+	size := c.roundSize(amount, info.LotSize)
+	if size < info.MinSize {
+		return "", &ExchangeError{Exchange: "Kraken", Message: fmt.Sprintf("下单数量 %.8f 小于最小数量 %.8f", amount, info.MinSize), Kind: ErrorKindInvalidOrder}
+	}
+
+	clientOrderID, _ := params[ParamClientOrderID].(string)
+	if clientOrderID == "" {
+		clientOrderID = NewClientOrderID()
+	}
+	form := url.Values{
+		"orderType": {"mkt"},
+		"symbol":    {info.InstID},
+		"side":      {side},
+		"size":      {strconv.FormatFloat(size, 'f', -1, 64)},
+		"cliOrdId":  {clientOrderID},
+	}
+	if limit, _ := params[ParamLimitPrice].(float64); limit > 0 {
+		form.Set("orderType", "lmt")
+		form.Set("limitPrice", strconv.FormatFloat(RoundPrice(limit, info.TickSize), 'f', -1, 64))
+	}
+	if reduceOnly, _ := params["reduceOnly"].(bool); reduceOnly {
+		form.Set("reduceOnly", "true")
+	}
+
+	var orderID string
+	attempt := 0
+	err = c.retry.do("下单", func() error {
+		attempt++
+		if attempt > 1 {
+			// 上次请求结果未知，先按 cliOrdId 查询，避免重复下单
+			order, err := c.fetchOrderStatus(symbol, "cliOrdIds", clientOrderID)
+			if err != nil {
+				return err
+			}
+			if order != nil {
+				logger.Printf("[INFO] 订单 %s 已提交成功（order_id: %s），不再重复下单", clientOrderID, order.OrderID)
+				orderID = order.OrderID
+				return nil
+			}
+		}
+		var err error
+		orderID, err = c.sendOrder(form)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	c.placeBracket(info, side, size, params)
+	return orderID, nil
+}
+
+// placeBracket 提交开仓附带的止损/止盈触发单（触发价按 tickSize 取整，失败只记录日志，由本地风控兜底）
+func (c *KrakenClient) placeBracket(info *InstrumentInfo, side string, size float64, params map[string]interface{}) {
+	closeSide := "sell"
+	if side == "sell" {
+		closeSide = "buy"
+	}
+	triggers := []struct {
+		orderType string
+		priceKey  string
+		idKey     string
+		name      string
+	}{
+		{"stp", ParamStopLossPrice, ParamStopLossClientID, "止损"},
+		{"take_profit", ParamTakeProfitPrice, ParamTakeProfitClientID, "止盈"},
+	}
+	for _, t := range triggers {
+		price, _ := params[t.priceKey].(float64)
+		if price <= 0 {
+			continue
+		}
+		price = RoundPrice(price, info.TickSize)
+		form := url.Values{
+			"orderType":     {t.orderType},
+			"symbol":        {info.InstID},
+			"side":          {closeSide},
+			"size":          {strconv.FormatFloat(size, 'f', -1, 64)},
+			"stopPrice":     {strconv.FormatFloat(price, 'f', -1, 64)},
+			"triggerSignal": {"mark"},
+			"reduceOnly":    {"true"},
+		}
+		if clientID, _ := params[t.idKey].(string); clientID != "" {
+			form.Set("cliOrdId", clientID)
+		}
+		if _, err := c.sendOrder(form); err != nil {
+			logger.Warnf("[Kraken] 提交%s触发单失败: %v", t.name, err)
+		}
+	}
+}
+
+// sendOrder 提交订单，返回订单ID
+func (c *KrakenClient) sendOrder(form url.Values) (string, error) {
+	logger.Debugf("[DEBUG] Kraken下单请求: %s", form.Encode())
+
+	data, err := c.request("POST", "/sendorder", form, true)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("[DEBUG] Kraken响应: %s", string(data))
+
+	var response struct {
+		SendStatus struct {
+			OrderID string `json:"order_id"`
+			Status  string `json:"status"`
+		} `json:"sendStatus"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+
+	switch response.SendStatus.Status {
+	case "placed", "attempted", "untouched", "partiallyFilled", "filled":
+		return response.SendStatus.OrderID, nil
+	default:
+		return "", c.apiError(response.SendStatus.Status)
+	}
+}
+
+// PlaceOrders 批量下单（逐笔提交），返回与请求一一对应的结果
+func (c *KrakenClient) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	results := make([]OrderResult, len(requests))
+	for i, req := range requests {
+		params := req.Params
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		clientOrderID, _ := params[ParamClientOrderID].(string)
+		if clientOrderID == "" {
+			clientOrderID = NewClientOrderID()
+			params[ParamClientOrderID] = clientOrderID
+		}
+		orderID, err := c.PlaceOrder(req.Symbol, req.Side, req.Amount, params)
+		results[i] = OrderResult{ClientOrderID: clientOrderID, OrderID: orderID, Err: err}
+	}
+	return results, nil
+}
+
+// krakenOrderStates Kraken订单状态 -> 通用订单状态
+var krakenOrderStates = map[string]string{
+	"ENTERED_BOOK":     models.OrderStateLive,
+	"UNTOUCHED":        models.OrderStateLive,
+	"PARTIALLY_FILLED": models.OrderStatePartiallyFilled,
+	"FULLY_EXECUTED":   models.OrderStateFilled,
+	"CANCELLED":        models.OrderStateCanceled,
+	"REJECTED":         models.OrderStateCanceled,
+}
+
+// fetchOrderStatus 按订单ID或自定义ID查询订单（key 为 orderIds 或 cliOrdIds），不存在时返回 nil
+func (c *KrakenClient) fetchOrderStatus(symbol, key, id string) (*models.Order, error) {
+	data, err := c.request("POST", "/orders/status", url.Values{key: {id}}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Orders []struct {
+			Order struct {
+				OrderID             string  `json:"orderId"`
+				CliOrdID            string  `json:"cliOrdId"`
+				Type                string  `json:"type"`
+				Symbol              string  `json:"symbol"`
+				Side                string  `json:"side"`
+				Quantity            float64 `json:"quantity"`
+				Filled              float64 `json:"filled"`
+				LimitPrice          float64 `json:"limitPrice"`
+				ReduceOnly          bool    `json:"reduceOnly"`
+				Timestamp           string  `json:"timestamp"`
+				LastUpdateTimestamp string  `json:"lastUpdateTimestamp"`
+			} `json:"order"`
+			Status string `json:"status"`
+		} `json:"orders"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Orders) == 0 {
+		return nil, nil
+	}
+
+	o := response.Orders[0]
+	state, ok := krakenOrderStates[o.Status]
+	if !ok {
+		state = models.OrderStateLive
+	}
+	createdAt, _ := time.Parse(time.RFC3339Nano, o.Order.Timestamp)
+	updatedAt, _ := time.Parse(time.RFC3339Nano, o.Order.LastUpdateTimestamp)
+	order := &models.Order{
+		OrderID:       o.Order.OrderID,
+		ClientOrderID: o.Order.CliOrdID,
+		Symbol:        symbol,
+		Side:          o.Order.Side,
+		Type:          o.Order.Type,
+		Price:         o.Order.LimitPrice,
+		Size:          o.Order.Quantity,
+		FilledSize:    o.Order.Filled,
+		State:         state,
+		ReduceOnly:    o.Order.ReduceOnly,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updatedAt,
+	}
+	if state == models.OrderStateFilled && order.FilledSize == 0 {
+		order.FilledSize = order.Size
+	}
+	return order, nil
+}
+
+// FetchOrder 查询订单（成交均价由成交记录计算）
+func (c *KrakenClient) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	order, err := c.fetchOrderStatus(symbol, "orderIds", orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, &ExchangeError{Exchange: "Kraken", Message: "订单不存在: " + orderID, Kind: ErrorKindInvalidOrder}
+	}
+
+	if order.FilledSize > 0 {
+		if fills, err := c.fetchFills(); err == nil {
+			var cost, size float64
+			for _, f := range fills {
+				if f.OrderID == orderID {
+					cost += f.Price * f.Size
+					size += f.Size
+				}
+			}
+			if size > 0 {
+				order.AvgPrice = cost / size
+			}
+		}
+	}
+	return order, nil
+}
+
+// CancelOrder 撤销订单
+func (c *KrakenClient) CancelOrder(symbol, orderID string) error {
+	return c.cancel(url.Values{"order_id": {orderID}})
+}
+
+// CancelAlgoOrder 按自定义ID撤销止损/止盈触发单（已触发、已撤销或不存在时返回nil）
+func (c *KrakenClient) CancelAlgoOrder(symbol, clientID string) error {
+	err := c.cancel(url.Values{"cliOrdId": {clientID}})
+	if IsKind(err, ErrorKindInvalidOrder) {
+		return nil
+	}
+	return err
+}
+
+// cancel 提交撤单请求
+func (c *KrakenClient) cancel(form url.Values) error {
+	data, err := c.request("POST", "/cancelorder", form, true)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		CancelStatus struct {
+			Status string `json:"status"`
+		} `json:"cancelStatus"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	switch response.CancelStatus.Status {
+	case "cancelled":
+		return nil
+	case "filled", "notFound":
+		return &ExchangeError{Exchange: "Kraken", Code: response.CancelStatus.Status,
+			Message: "撤单失败: " + response.CancelStatus.Status, Kind: ErrorKindInvalidOrder}
+	default:
+		return c.apiError(response.CancelStatus.Status)
+	}
+}
+
+// FetchOpenOrders 获取未成交订单
+func (c *KrakenClient) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	data, err := c.request("GET", "/openorders", nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		OpenOrders []struct {
+			OrderID      string  `json:"order_id"`
+			CliOrdID     string  `json:"cliOrdId"`
+			Symbol       string  `json:"symbol"`
+			Side         string  `json:"side"`
+			OrderType    string  `json:"orderType"`
+			LimitPrice   float64 `json:"limitPrice"`
+			UnfilledSize float64 `json:"unfilledSize"`
+			FilledSize   float64 `json:"filledSize"`
+			ReduceOnly   bool    `json:"reduceOnly"`
+			ReceivedTime string  `json:"receivedTime"`
+			LastUpdate   string  `json:"lastUpdateTime"`
+		} `json:"openOrders"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	instID := c.convertSymbol(symbol)
+	var orders []models.Order
+	for _, o := range response.OpenOrders {
+		if !strings.EqualFold(o.Symbol, instID) {
+			continue
+		}
+		state := models.OrderStateLive
+		if o.FilledSize > 0 {
+			state = models.OrderStatePartiallyFilled
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, o.ReceivedTime)
+		updatedAt, _ := time.Parse(time.RFC3339Nano, o.LastUpdate)
+		orders = append(orders, models.Order{
+			OrderID:       o.OrderID,
+			ClientOrderID: o.CliOrdID,
+			Symbol:        symbol,
+			Side:          o.Side,
+			Type:          o.OrderType,
+			Price:         o.LimitPrice,
+			Size:          o.FilledSize + o.UnfilledSize,
+			FilledSize:    o.FilledSize,
+			State:         state,
+			ReduceOnly:    o.ReduceOnly,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+		})
+	}
+	return orders, nil
+}
+
+// krakenFill Kraken成交记录
+type krakenFill struct {
+	FillID   string  `json:"fill_id"`
+	OrderID  string  `json:"order_id"`
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Size     float64 `json:"size"`
+	Price    float64 `json:"price"`
+	FillTime string  `json:"fillTime"`
+	FillType string  `json:"fillType"`
+}
+
+// fetchFills 获取最近的成交记录
+func (c *KrakenClient) fetchFills() ([]krakenFill, error) {
+	data, err := c.request("GET", "/fills", nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Fills []krakenFill `json:"fills"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	return response.Fills, nil
+}
+
+// FetchMyTrades 获取账户成交记录（按时间升序，Kraken 只返回最近的成交，手续费不在成交记录中提供）
+func (c *KrakenClient) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	fills, err := c.fetchFills()
+	if err != nil {
+		return nil, err
+	}
+
+	instID := c.convertSymbol(symbol)
+	var trades []models.Trade
+	for _, f := range fills {
+		if !strings.EqualFold(f.Symbol, instID) {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, f.FillTime)
+		if ts.Before(since) {
+			continue
+		}
+		trades = append(trades, models.Trade{
+			TradeID:   f.FillID,
+			OrderID:   f.OrderID,
+			Symbol:    symbol,
+			Side:      f.Side,
+			Price:     f.Price,
+			Size:      f.Size,
+			IsMaker:   f.FillType == "maker",
+			Timestamp: ts,
+		})
+	}
+	sort.Slice(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	return trades, nil
+}
+
+// FetchTradingFees 获取手续费率（Kraken 未提供按交易对查询账户费率的接口）
+func (c *KrakenClient) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	return nil, fmt.Errorf("Kraken 暂不支持查询账户手续费率")
+}
+
+// Transfer 资金划转（Kraken 现货与合约钱包之间的划转需使用现货账户 API Key，暂不支持）
+func (c *KrakenClient) Transfer(currency string, amount float64, from, to string) error {
+	return fmt.Errorf("Kraken 暂不支持资金划转")
+}
+
+// FetchFundingFees 获取资金费用记录（暂不支持）
+func (c *KrakenClient) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	return nil, fmt.Errorf("Kraken 暂不支持查询资金费用记录")
+}
+
+// FetchTransfers 获取充值、提现和划转记录（暂不支持）
+func (c *KrakenClient) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	return nil, fmt.Errorf("Kraken 暂不支持查询资金流水")
+}
+
+// SetLeverage 设置杠杆（多抵押合约的最大杠杆偏好）
+func (c *KrakenClient) SetLeverage(symbol string, leverage int) error {
+	params := url.Values{
+		"symbol":      {c.convertSymbol(symbol)},
+		"maxLeverage": {strconv.Itoa(leverage)},
+	}
+	_, err := c.request("PUT", "/leveragepreferences", params, true)
+	return err
+}
+
+// roundSize 按数量精度向下取整
+func (c *KrakenClient) roundSize(size, lotSize float64) float64 {
+	return RoundSize(size, lotSize)
+}
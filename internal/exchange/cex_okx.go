@@ -5,9 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"dsbot/internal/config"
@@ -20,6 +24,15 @@ const (
 	OKXBaseURL = "https://www.okx.com"
 )
 
+// okxDefaultRateLimits OKX默认限流配置（按官方限速留出余量）
+var okxDefaultRateLimits = map[string]config.RateLimitConfig{
+	"market":  {RequestsPerSecond: 10, Burst: 20}, // 行情接口: 20次/2s
+	"public":  {RequestsPerSecond: 10, Burst: 20}, // 公共数据: 20次/2s
+	"account": {RequestsPerSecond: 5, Burst: 10},  // 账户接口: 10次/2s
+	"asset":   {RequestsPerSecond: 1, Burst: 1},   // 资金接口: 划转 2次/s
+	"trade":   {RequestsPerSecond: 30, Burst: 60}, // 交易接口: 60次/2s
+}
+
 // OKXClient OKX交易所客户端
 type OKXClient struct {
 	apiKey      string
@@ -27,8 +40,25 @@ type OKXClient struct {
 	password    string
 	httpClient  *nets.HttpClient
 	tradingMode config.TradingMode // 交易模式
+	rateLimiter *RateLimiter       // 请求限流器
+	retry       *retryPolicy       // 临时性错误重试策略
+	simulated   bool               // 是否为模拟交易（x-simulated-trading）
+	clock       *serverClock       // 服务器时钟（校正签名时间戳）
+
+	posMode   string     // 持仓模式（long_short_mode / net_mode，空表示尚未检测）
+	posModeMu sync.Mutex // 保护 posMode
+
+	subAccount         string     // 子账户名（为空表示不校验）
+	subAccountVerified bool       // 已确认 API Key 属于子账户
+	subAccountMu       sync.Mutex // 保护 subAccountVerified
 }
 
+// OKX 持仓模式
+const (
+	okxPosModeLongShort = "long_short_mode" // 双向持仓：下单需指定 posSide
+	okxPosModeNet       = "net_mode"        // 单向持仓：不传 posSide，平仓依赖 reduceOnly
+)
+
 // NewOKXClient 创建OKX客户端
 func NewOKXClient(cfg *config.APIConfig, tradingMode config.TradingMode) *OKXClient {
 	_httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
@@ -37,12 +67,66 @@ func NewOKXClient(cfg *config.APIConfig, tradingMode config.TradingMode) *OKXCli
 		return nil
 	}
 
-	return &OKXClient{
+	c := &OKXClient{
 		apiKey:      cfg.OKXAPIKey,
 		secret:      cfg.OKXSecret,
 		password:    cfg.OKXPassword,
 		httpClient:  _httpClient,
 		tradingMode: tradingMode,
+		rateLimiter: NewRateLimiter(okxDefaultRateLimits, cfg.RateLimits),
+		retry:       newRetryPolicy(cfg.Retry),
+		simulated:   cfg.UseTestnet,
+		posMode:     okxConfiguredPosMode(cfg.PositionMode),
+		subAccount:  cfg.OKXSubAccount,
+	}
+	c.clock = newServerClock("OKX", cfg.ClockSync, c.fetchServerTime)
+	return c
+}
+
+// fetchServerTime 获取OKX服务器时间（公共接口，不签名）
+func (c *OKXClient) fetchServerTime() (time.Time, error) {
+	c.rateLimiter.Wait("public")
+
+	resp, err := c.httpClient.QueryRaw("GET", OKXBaseURL+"/api/v5/public/time", nil, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !resp.IsSuccess() {
+		return time.Time{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Ts string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &response); err != nil {
+		return time.Time{}, err
+	}
+	if response.Code != "0" {
+		return time.Time{}, c.apiError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return time.Time{}, fmt.Errorf("服务器时间为空")
+	}
+	ts, err := strconv.ParseInt(response.Data[0].Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ts), nil
+}
+
+// okxConfiguredPosMode 将配置的持仓模式转换为OKX取值（auto或未配置时返回空，运行时检测）
+func okxConfiguredPosMode(mode string) string {
+	switch strings.ToLower(mode) {
+	case "long_short", okxPosModeLongShort:
+		return okxPosModeLongShort
+	case "net", okxPosModeNet:
+		return okxPosModeNet
+	default:
+		return ""
 	}
 }
 
@@ -51,6 +135,19 @@ func (c *OKXClient) GetExchangeName() string {
 	return string(config.ExchangeOKX)
 }
 
+// Capabilities 获取交易所支持的功能
+func (c *OKXClient) Capabilities() Capabilities {
+	return Capabilities{
+		Futures:      true,
+		Spot:         true,
+		HedgeMode:    true,
+		AlgoOrders:   true,
+		TrailingStop: true,
+		BatchOrders:  true,
+		LimitOrders:  true,
+	}
+}
+
 func (c *OKXClient) ParseSymbols(symbolA, symbolB string) string {
 	// BTC, USDT -> BTC/USDT:USDT
 	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
@@ -64,37 +161,260 @@ func (c *OKXClient) sign(timestamp, method, requestPath, body string) string {
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
 
+// endpointGroup 根据请求路径返回限流分组
+// 例如: /api/v5/market/candles -> market
+func (c *OKXClient) endpointGroup(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/api/v5/"), "/", 2)
+	return parts[0]
+}
+
+// okxTransientCodes OKX临时性错误码（可安全重试）
+var okxTransientCodes = map[string]bool{
+	"50001": true, // 服务暂时不可用
+	"50004": true, // 接口请求超时
+	"50011": true, // 请求频率过高
+	"50013": true, // 系统繁忙
+	"50026": true, // 系统错误
+}
+
+// okxAuthCodes OKX认证相关错误码
+var okxAuthCodes = map[string]bool{
+	"50100": true, // API被冻结
+	"50101": true, // APIKey与当前环境不匹配
+	"50102": true, // 请求时间戳过期
+	"50103": true, // 缺少 OK-ACCESS-KEY
+	"50104": true, // 缺少 OK-ACCESS-PASSPHRASE
+	"50105": true, // OK-ACCESS-PASSPHRASE 错误
+	"50106": true, // OK-ACCESS-SIGN 缺失
+	"50107": true, // OK-ACCESS-TIMESTAMP 缺失
+	"50110": true, // IP不在白名单
+	"50111": true, // 无效的 OK-ACCESS-KEY
+	"50113": true, // 无效的签名
+	"50114": true, // 无效的授权
+	"50120": true, // API Key 无此权限
+}
+
+// okxInsufficientBalanceCodes OKX余额/保证金不足错误码
+var okxInsufficientBalanceCodes = map[string]bool{
+	"51008": true, // 余额不足
+	"51119": true, // 保证金不足
+	"51127": true, // 可用余额为0
+	"51131": true, // 余额不足
+	"59200": true, // 账户余额不足
+}
+
+// classifyOKXCode 根据OKX错误码分类
+func classifyOKXCode(code string) ErrorKind {
+	switch {
+	case okxTransientCodes[code]:
+		return ErrorKindRetryable
+	case okxAuthCodes[code]:
+		return ErrorKindAuthFailed
+	case okxInsufficientBalanceCodes[code]:
+		return ErrorKindInsufficientBalance
+	case len(code) == 5 && code[:2] == "51" && code < "51600":
+		// 51000-51599: 下单参数类错误（数量、价格、交易对等）
+		return ErrorKindInvalidOrder
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// apiError 根据OKX错误码构造 ExchangeError
+func (c *OKXClient) apiError(code, msg string) *ExchangeError {
+	return &ExchangeError{
+		Exchange: "OKX",
+		Code:     code,
+		Message:  msg,
+		Kind:     classifyOKXCode(code),
+	}
+}
+
 // request 发送HTTP请求
+// GET请求遇到临时性错误时自动重试；POST请求（下单、撤单等）不在此重试，由调用方按幂等方式处理
 func (c *OKXClient) request(method, path string, body string) ([]byte, error) {
+	if err := c.verifySubAccount(path); err != nil {
+		return nil, err
+	}
+	if method != "GET" {
+		return c.doRequest(method, path, body)
+	}
+
+	var data []byte
+	err := c.retry.do(method+" "+path, func() error {
+		var err error
+		data, err = c.doRequest(method, path, body)
+		return err
+	})
+	return data, err
+}
+
+// doRequest 发送单次HTTP请求，网络错误、5xx、限流等临时性错误返回可重试的 ExchangeError
+func (c *OKXClient) doRequest(method, path string, body string) ([]byte, error) {
+	c.rateLimiter.Wait(c.endpointGroup(path))
+
 	url := OKXBaseURL + path
-	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
-	sign := c.sign(timestamp, method, path, body)
 
 	headers := map[string]string{
-		"OK-ACCESS-KEY":        c.apiKey,
-		"OK-ACCESS-SIGN":       sign,
-		"OK-ACCESS-TIMESTAMP":  timestamp,
-		"OK-ACCESS-PASSPHRASE": c.password,
-		"Content-Type":         "application/json",
+		"Content-Type": "application/json",
+	}
+	// 未配置API Key时仅能访问公共接口（如 symbols 命令）
+	if c.apiKey != "" {
+		timestamp := c.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		sign := c.sign(timestamp, method, path, body)
+		headers["OK-ACCESS-KEY"] = c.apiKey
+		headers["OK-ACCESS-SIGN"] = sign
+		headers["OK-ACCESS-TIMESTAMP"] = timestamp
+		headers["OK-ACCESS-PASSPHRASE"] = c.password
+	}
+	if c.simulated {
+		// 模拟交易需使用模拟盘API Key
+		headers["x-simulated-trading"] = "1"
+	}
+
+	var bodyBytes []byte
+	if method == "POST" {
+		bodyBytes = []byte(body)
+	}
+
+	resp, err := c.httpClient.QueryRaw(method, url, headers, bodyBytes)
+	if err != nil {
+		return nil, &ExchangeError{Exchange: "OKX", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+	}
+
+	var envelope struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	isJSON := json.Unmarshal(resp.Body, &envelope) == nil
+
+	// 时间戳过期：下次请求前重新同步服务器时间
+	if isJSON && envelope.Code == "50102" {
+		c.clock.Invalidate()
+	}
+
+	if !resp.IsSuccess() {
+		return nil, c.httpError(resp, envelope.Code, envelope.Msg, isJSON)
+	}
+
+	if isJSON && okxTransientCodes[envelope.Code] {
+		return nil, c.apiError(envelope.Code, envelope.Msg)
+	}
+
+	return resp.Body, nil
+}
+
+// httpError 根据非2xx响应构造 ExchangeError（包含响应体和限流相关响应头）
+func (c *OKXClient) httpError(resp *nets.RawResponse, code, msg string, isJSON bool) *ExchangeError {
+	exErr := &ExchangeError{
+		Exchange:   "OKX",
+		HTTPStatus: resp.StatusCode,
+		Headers:    rateLimitHeaders(resp.Header),
+	}
+
+	if isJSON && code != "" {
+		// 响应体为OKX标准错误格式，按错误码分类
+		exErr.Code = code
+		exErr.Message = msg
+		exErr.Kind = classifyOKXCode(code)
+	} else {
+		// 非JSON响应（如网关返回的HTML页面），截断后原样返回
+		body := string(resp.Body)
+		if len(body) > 512 {
+			body = body[:512] + "..."
+		}
+		exErr.Message = body
 	}
 
-	switch method {
-	case "GET":
-		return c.httpClient.QueryGet(url, headers)
-	case "POST":
-		return c.httpClient.QueryPost(url, headers, []byte(body))
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		exErr.Kind = ErrorKindRetryable
+	case exErr.Kind == ErrorKindUnknown && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+		exErr.Kind = ErrorKindAuthFailed
 	}
 
-	return nil, nil
+	return exErr
 }
 
+// rateLimitHeaders 提取限流相关的响应头
+func rateLimitHeaders(header http.Header) map[string]string {
+	result := make(map[string]string)
+	for key, values := range header {
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "ratelimit") || strings.Contains(lower, "rate-limit") || lower == "retry-after" {
+			result[key] = strings.Join(values, ",")
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// OKX K线接口单次请求上限
+const (
+	okxCandlesPageLimit        = 300 // /market/candles（最近约1440根）
+	okxHistoryCandlesPageLimit = 100 // /market/history-candles（更早的历史数据）
+)
+
 // FetchOHLCV 获取K线数据
+// limit 超过单次请求上限时按 after 游标向前分页，最近的数据取自 /market/candles，
+// 更早的数据取自 /market/history-candles，直到取满 limit 根或没有更多数据
 func (c *OKXClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
 	// 转换symbol格式: BTC/USDT:USDT -> BTC-USDT-SWAP
 	instID := c.convertSymbol(symbol)
 
-	path := fmt.Sprintf("/api/v5/market/candles?instId=%s&bar=%s&limit=%d", instID, timeframe, limit)
+	var ohlcvList []models.OHLCV // 倒序（从新到旧）
+	endpoint, pageLimit := "candles", okxCandlesPageLimit
+	var after int64
+
+	for len(ohlcvList) < limit {
+		size := limit - len(ohlcvList)
+		if size > pageLimit {
+			size = pageLimit
+		}
+
+		path := fmt.Sprintf("/api/v5/market/%s?instId=%s&bar=%s&limit=%d", endpoint, instID, timeframe, size)
+		if after > 0 {
+			path += fmt.Sprintf("&after=%d", after)
+		}
+
+		page, err := c.fetchCandlePage(path)
+		if err != nil {
+			if len(ohlcvList) > 0 {
+				// 已获取部分数据时不因更早的分页失败而丢弃
+				logger.Warnf("[WARNING] K线分页获取失败，仅返回 %d/%d 根: %v", len(ohlcvList), limit, err)
+				break
+			}
+			return nil, err
+		}
+
+		if len(page) == 0 {
+			// 近期接口已无更早数据时切换到历史接口
+			if endpoint == "candles" && after > 0 {
+				endpoint, pageLimit = "history-candles", okxHistoryCandlesPageLimit
+				continue
+			}
+			break
+		}
+
+		ohlcvList = append(ohlcvList, page...)
+		after = page[len(page)-1].Timestamp.UnixMilli()
+
+		// 首页不足请求数量说明没有更多数据
+		if len(page) < size && endpoint == "history-candles" {
+			break
+		}
+	}
+
+	// OKX返回的数据是倒序的，需要反转
+	c.reverseOHLCV(ohlcvList)
+
+	return ohlcvList, nil
+}
 
+// fetchCandlePage 获取一页K线数据（按时间倒序）
+func (c *OKXClient) fetchCandlePage(path string) ([]models.OHLCV, error) {
 	data, err := c.request("GET", path, "")
 	if err != nil {
 		return nil, err
@@ -111,16 +431,16 @@ func (c *OKXClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OH
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("OKX API错误: %s", response.Msg)
+		return nil, c.apiError(response.Code, response.Msg)
 	}
 
-	var ohlcvList []models.OHLCV
+	ohlcvList := make([]models.OHLCV, 0, len(response.Data))
 	for _, item := range response.Data {
 		if len(item) < 6 {
 			continue
 		}
 
-		timestamp, _ := strconv.ParseFloat(item[0], 64)
+		timestamp, _ := strconv.ParseInt(item[0], 10, 64)
 		open, _ := strconv.ParseFloat(item[1], 64)
 		high, _ := strconv.ParseFloat(item[2], 64)
 		low, _ := strconv.ParseFloat(item[3], 64)
@@ -128,7 +448,7 @@ func (c *OKXClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OH
 		volume, _ := strconv.ParseFloat(item[5], 64)
 
 		ohlcvList = append(ohlcvList, models.OHLCV{
-			Timestamp: time.Unix(int64(timestamp)/1000, 0),
+			Timestamp: time.UnixMilli(timestamp),
 			Open:      open,
 			High:      high,
 			Low:       low,
@@ -137,9 +457,6 @@ func (c *OKXClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OH
 		})
 	}
 
-	// OKX返回的数据是倒序的，需要反转
-	c.reverseOHLCV(ohlcvList)
-
 	return ohlcvList, nil
 }
 
@@ -170,7 +487,7 @@ func (c *OKXClient) FetchTicker(symbol string) (*models.Ticker, error) {
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("OKX API错误: %s", response.Msg)
+		return nil, c.apiError(response.Code, response.Msg)
 	}
 
 	if len(response.Data) == 0 {
@@ -182,16 +499,240 @@ func (c *OKXClient) FetchTicker(symbol string) (*models.Ticker, error) {
 	bid, _ := strconv.ParseFloat(ticker.BidPx, 64)
 	ask, _ := strconv.ParseFloat(ticker.AskPx, 64)
 
-	return &models.Ticker{
+	result := &models.Ticker{
 		Symbol: symbol,
 		Last:   last,
 		Bid:    bid,
 		Ask:    ask,
+	}
+
+	// 合约模式补充标记价格和指数价格（获取失败不影响最新成交价）
+	if c.tradingMode != config.TradingModeSpot {
+		if mark, err := c.fetchMarkPrice(instID); err != nil {
+			logger.Debugf("[DEBUG] 获取标记价格失败: %v", err)
+		} else {
+			result.Mark = mark
+		}
+		if index, err := c.fetchIndexPrice(strings.TrimSuffix(instID, "-SWAP")); err != nil {
+			logger.Debugf("[DEBUG] 获取指数价格失败: %v", err)
+		} else {
+			result.Index = index
+		}
+	}
+
+	return result, nil
+}
+
+// FetchOrderBook 获取盘口深度（合约数量由张数换算为基础币种）
+func (c *OKXClient) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	instID := c.convertSymbol(symbol)
+	path := fmt.Sprintf("/api/v5/market/books?instId=%s&sz=%d", instID, depth)
+
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Asks [][]string `json:"asks"` // [价格, 数量, 废弃字段, 订单数]
+			Bids [][]string `json:"bids"`
+			Ts   string     `json:"ts"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("未获取到盘口数据")
+	}
+
+	// 合约盘口数量单位为张
+	multiplier := 1.0
+	if c.tradingMode != config.TradingModeSpot {
+		instInfo, err := c.GetInstrumentInfo(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取交易对信息失败: %w", err)
+		}
+		if instInfo.ContractValue > 0 {
+			multiplier = instInfo.ContractValue
+		}
+	}
+
+	parseLevels := func(raw [][]string) []models.OrderBookLevel {
+		levels := make([]models.OrderBookLevel, 0, len(raw))
+		for _, item := range raw {
+			if len(item) < 2 {
+				continue
+			}
+			price, _ := strconv.ParseFloat(item[0], 64)
+			size, _ := strconv.ParseFloat(item[1], 64)
+			levels = append(levels, models.OrderBookLevel{Price: price, Size: size * multiplier})
+		}
+		return levels
+	}
+
+	book := response.Data[0]
+	ts, _ := strconv.ParseInt(book.Ts, 10, 64)
+
+	return &models.OrderBook{
+		Symbol:    symbol,
+		Bids:      parseLevels(book.Bids),
+		Asks:      parseLevels(book.Asks),
+		Timestamp: time.UnixMilli(ts),
 	}, nil
 }
 
-// FetchPosition 获取持仓信息（仅用于合约模式）
+// fetchMarkPrice 获取合约标记价格
+func (c *OKXClient) fetchMarkPrice(instID string) (float64, error) {
+	path := fmt.Sprintf("/api/v5/public/mark-price?instType=%s&instId=%s", c.instType(), instID)
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			MarkPx string `json:"markPx"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, err
+	}
+	if response.Code != "0" {
+		return 0, c.apiError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return 0, fmt.Errorf("未获取到标记价格")
+	}
+	return strconv.ParseFloat(response.Data[0].MarkPx, 64)
+}
+
+// fetchIndexPrice 获取指数价格
+// indexID: 指数ID (如 BTC-USDT)
+func (c *OKXClient) fetchIndexPrice(indexID string) (float64, error) {
+	path := fmt.Sprintf("/api/v5/market/index-tickers?instId=%s", indexID)
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			IdxPx string `json:"idxPx"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return 0, err
+	}
+	if response.Code != "0" {
+		return 0, c.apiError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return 0, fmt.Errorf("未获取到指数价格")
+	}
+	return strconv.ParseFloat(response.Data[0].IdxPx, 64)
+}
+
+// FetchDerivativesStats 获取永续合约的资金费率、持仓量和标记/指数价格
+func (c *OKXClient) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	if c.tradingMode == config.TradingModeSpot {
+		return nil, nil
+	}
+	instID := c.convertSymbol(symbol)
+
+	data, err := c.request("GET", fmt.Sprintf("/api/v5/public/funding-rate?instId=%s", instID), "")
+	if err != nil {
+		return nil, err
+	}
+	var funding struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			FundingRate     string `json:"fundingRate"`
+			FundingTime     string `json:"fundingTime"`     // 本期结算时间（毫秒）
+			NextFundingTime string `json:"nextFundingTime"` // 下一期结算时间（毫秒）
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &funding); err != nil {
+		return nil, err
+	}
+	if funding.Code != "0" {
+		return nil, c.apiError(funding.Code, funding.Msg)
+	}
+	if len(funding.Data) == 0 {
+		return nil, fmt.Errorf("未获取到资金费率")
+	}
+
+	stats := &models.DerivativesStats{}
+	stats.FundingRate, _ = strconv.ParseFloat(funding.Data[0].FundingRate, 64)
+	fundingTime, _ := strconv.ParseInt(funding.Data[0].FundingTime, 10, 64)
+	nextFundingTime, _ := strconv.ParseInt(funding.Data[0].NextFundingTime, 10, 64)
+	if fundingTime > 0 {
+		stats.NextFundingTime = time.UnixMilli(fundingTime)
+		if nextFundingTime > fundingTime {
+			stats.FundingIntervalHours = float64(nextFundingTime-fundingTime) / float64(time.Hour.Milliseconds())
+		}
+	}
+
+	data, err = c.request("GET", fmt.Sprintf("/api/v5/public/open-interest?instType=%s&instId=%s", c.instType(), instID), "")
+	if err != nil {
+		return nil, err
+	}
+	var openInterest struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OiCcy string `json:"oiCcy"` // 持仓量（币）
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &openInterest); err != nil {
+		return nil, err
+	}
+	if openInterest.Code != "0" {
+		return nil, c.apiError(openInterest.Code, openInterest.Msg)
+	}
+	if len(openInterest.Data) > 0 {
+		stats.OpenInterest, _ = strconv.ParseFloat(openInterest.Data[0].OiCcy, 64)
+	}
+
+	// 标记/指数价格获取失败不影响资金费率和持仓量
+	if mark, err := c.fetchMarkPrice(instID); err != nil {
+		logger.Debugf("[DEBUG] 获取标记价格失败: %v", err)
+	} else {
+		stats.MarkPrice = mark
+	}
+	if index, err := c.fetchIndexPrice(strings.TrimSuffix(instID, "-SWAP")); err != nil {
+		logger.Debugf("[DEBUG] 获取指数价格失败: %v", err)
+	} else {
+		stats.IndexPrice = index
+	}
+	return stats, nil
+}
+
+// FetchPosition 获取持仓信息（仅用于合约模式，双向持仓同时持有多空时返回第一条）
 func (c *OKXClient) FetchPosition(symbol string) (*models.Position, error) {
+	positions, err := c.FetchPositions(symbol)
+	if err != nil || len(positions) == 0 {
+		return nil, err
+	}
+	return &positions[0], nil
+}
+
+// FetchPositions 获取交易对的全部持仓（仅用于合约模式，双向持仓时多空各一条，数量为基础币种数量）
+func (c *OKXClient) FetchPositions(symbol string) ([]models.Position, error) {
 	instID := c.convertSymbol(symbol)
 	path := fmt.Sprintf("/api/v5/account/positions?instId=%s", instID)
 
@@ -210,6 +751,11 @@ func (c *OKXClient) FetchPosition(symbol string) (*models.Position, error) {
 			AvgPx   string `json:"avgPx"`
 			Upl     string `json:"upl"`
 			Lever   string `json:"lever"`
+			LiqPx   string `json:"liqPx"`
+			MarkPx  string `json:"markPx"`
+			MgnMode string `json:"mgnMode"`
+			Margin  string `json:"margin"` // 逐仓保证金
+			Imr     string `json:"imr"`    // 全仓初始保证金
 		} `json:"data"`
 	}
 
@@ -218,31 +764,63 @@ func (c *OKXClient) FetchPosition(symbol string) (*models.Position, error) {
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("OKX API错误: %s", response.Msg)
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	// 合约持仓数量单位为张，换算为基础币种数量（与下单数量单位一致）
+	multiplier := 1.0
+	if len(response.Data) > 0 {
+		instInfo, err := c.GetInstrumentInfo(symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取交易对信息失败: %w", err)
+		}
+		if instInfo.ContractValue > 0 {
+			multiplier = instInfo.ContractValue
+		}
 	}
 
+	var positions []models.Position
 	for _, pos := range response.Data {
 		size, _ := strconv.ParseFloat(pos.Pos, 64)
+		size *= multiplier
+		if pos.PosSide == "net" {
+			// 单向持仓：持仓数量带符号，负数为空头
+			if size < 0 {
+				pos.PosSide, size = "short", -size
+			} else {
+				pos.PosSide = "long"
+			}
+		}
 		if size > 0 {
 			entryPrice, _ := strconv.ParseFloat(pos.AvgPx, 64)
 			upl, _ := strconv.ParseFloat(pos.Upl, 64)
 			leverage, _ := strconv.ParseInt(pos.Lever, 10, 64)
-
-			logger.Debugf("[DEBUG] FetchPosition - PosSide:%s, Size:%.8f, AvgPx:%.2f, Upl:%.2f",
-				pos.PosSide, size, entryPrice, upl)
-
-			return &models.Position{
-				Side:          pos.PosSide,
-				Size:          size,
-				EntryPrice:    entryPrice,
-				UnrealizedPnL: upl,
-				Leverage:      int(leverage),
-				Symbol:        symbol,
-			}, nil
+			liqPx, _ := strconv.ParseFloat(pos.LiqPx, 64)
+			markPx, _ := strconv.ParseFloat(pos.MarkPx, 64)
+			margin := pos.Margin
+			if pos.MgnMode == "cross" {
+				margin = pos.Imr
+			}
+			marginValue, _ := strconv.ParseFloat(margin, 64)
+
+			logger.Debugf("[DEBUG] FetchPositions - PosSide:%s, Size:%.8f, AvgPx:%.2f, Upl:%.2f, LiqPx:%.2f",
+				pos.PosSide, size, entryPrice, upl, liqPx)
+
+			positions = append(positions, models.Position{
+				Side:             pos.PosSide,
+				Size:             size,
+				EntryPrice:       entryPrice,
+				UnrealizedPnL:    upl,
+				Leverage:         int(leverage),
+				Symbol:           symbol,
+				LiquidationPrice: liqPx,
+				Margin:           marginValue,
+				MarkPrice:        markPx,
+			})
 		}
 	}
 
-	return nil, nil
+	return positions, nil
 }
 
 // FetchBalance 获取账户余额（用于现货模式）
@@ -272,7 +850,7 @@ func (c *OKXClient) FetchBalance(currency string) (float64, error) {
 	}
 
 	if response.Code != "0" {
-		return 0, fmt.Errorf("OKX API错误: %s", response.Msg)
+		return 0, c.apiError(response.Code, response.Msg)
 	}
 
 	if len(response.Data) == 0 {
@@ -290,17 +868,59 @@ func (c *OKXClient) FetchBalance(currency string) (float64, error) {
 	return 0, nil
 }
 
-// GetInstrumentInfo 获取交易对信息（现货或合约）
-func (c *OKXClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
-	instID := c.convertSymbol(symbol)
-
-	// 根据交易模式选择不同的 instType
-	instType := "SWAP" // 默认合约
-	if c.tradingMode == config.TradingModeSpot {
-		instType = "SPOT"
+// FetchAccountSummary 获取账户权益和保证金（按币种统计，适用于单币种保证金账户）
+func (c *OKXClient) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	data, err := c.request("GET", "/api/v5/account/balance?ccy="+currency, "")
+	if err != nil {
+		return nil, err
 	}
 
-	path := fmt.Sprintf("/api/v5/public/instruments?instType=%s&instId=%s", instType, instID)
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Details []struct {
+				Ccy      string `json:"ccy"`
+				Eq       string `json:"eq"`       // 币种权益（含未实现盈亏）
+				AvailEq  string `json:"availEq"`  // 可用保证金（保证金账户）
+				AvailBal string `json:"availBal"` // 可用余额（现货账户）
+				Mmr      string `json:"mmr"`      // 全仓维持保证金
+				IsoEq    string `json:"isoEq"`    // 逐仓仓位权益
+			} `json:"details"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	summary := &models.AccountSummary{Currency: currency}
+	if len(response.Data) == 0 {
+		return summary, nil
+	}
+	for _, detail := range response.Data[0].Details {
+		if detail.Ccy != currency {
+			continue
+		}
+		summary.TotalEquity, _ = strconv.ParseFloat(detail.Eq, 64)
+		summary.MaintenanceMargin, _ = strconv.ParseFloat(detail.Mmr, 64)
+		available := detail.AvailEq
+		if available == "" {
+			available = detail.AvailBal
+		}
+		summary.AvailableMargin, _ = strconv.ParseFloat(available, 64)
+		break
+	}
+	return summary, nil
+}
+
+// GetInstrumentInfo 获取交易对信息（现货或合约）
+func (c *OKXClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
+	instID := c.convertSymbol(symbol)
+
+	path := fmt.Sprintf("/api/v5/public/instruments?instType=%s&instId=%s", c.instType(), instID)
 
 	data, err := c.request("GET", path, "")
 	if err != nil {
@@ -331,7 +951,7 @@ func (c *OKXClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("OKX API错误: %s", response.Msg)
+		return nil, c.apiError(response.Code, response.Msg)
 	}
 
 	if len(response.Data) == 0 {
@@ -343,10 +963,11 @@ func (c *OKXClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
 	lotSz, _ := strconv.ParseFloat(info.LotSz, 64)
 	minSz, _ := strconv.ParseFloat(info.MinSz, 64)
 	minAmt, _ := strconv.ParseFloat(info.MinAmt, 64)
+	tickSz, _ := strconv.ParseFloat(info.TickSz, 64)
 
 	// 添加调试日志：查看解析结果
-	logger.Debugf("[DEBUG] GetInstrumentInfo解析 - InstID:%s, LotSz:%s, MinSz:%s, MinAmt:'%s'(len=%d, parsed=%.2f)",
-		info.InstID, info.LotSz, info.MinSz, info.MinAmt, len(info.MinAmt), minAmt)
+	logger.Debugf("[DEBUG] GetInstrumentInfo解析 - InstID:%s, LotSz:%s, MinSz:%s, TickSz:%s, MinAmt:'%s'(len=%d, parsed=%.2f)",
+		info.InstID, info.LotSz, info.MinSz, info.TickSz, info.MinAmt, len(info.MinAmt), minAmt)
 
 	// ✅ 重要：OKX现货API不返回minAmt字段，需要使用默认值
 	// 根据OKX实际要求和测试经验，现货交易的最小订单金额如下：
@@ -370,18 +991,299 @@ func (c *OKXClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
 		LotSize:       lotSz,
 		MinSize:       minSz,
 		MinAmount:     minAmt, // 现货最小订单金额（使用默认值）
-		TickSize:      0,
+		TickSize:      tickSz,
 	}, nil
 }
 
-// PlaceOrder 下单（支持现货和合约）
-func (c *OKXClient) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) error {
+// okxAttachedAlgoOrders 将止损止盈参数转换为OKX附带策略委托（止损、止盈各为独立的一条，触发价按 tickSize 取整）
+func okxAttachedAlgoOrders(params map[string]interface{}, tickSize float64) []map[string]interface{} {
+	var attached []map[string]interface{}
+
+	if price, _ := params[ParamStopLossPrice].(float64); price > 0 {
+		algo := map[string]interface{}{
+			"slTriggerPx": strconv.FormatFloat(RoundPrice(price, tickSize), 'f', -1, 64),
+			"slOrdPx":     "-1", // 触发后市价平仓
+		}
+		if id, _ := params[ParamStopLossClientID].(string); id != "" {
+			algo["attachAlgoClOrdId"] = id
+		}
+		attached = append(attached, algo)
+	}
+
+	if price, _ := params[ParamTakeProfitPrice].(float64); price > 0 {
+		algo := map[string]interface{}{
+			"tpTriggerPx": strconv.FormatFloat(RoundPrice(price, tickSize), 'f', -1, 64),
+			"tpOrdPx":     "-1",
+		}
+		if id, _ := params[ParamTakeProfitClientID].(string); id != "" {
+			algo["attachAlgoClOrdId"] = id
+		}
+		attached = append(attached, algo)
+	}
+
+	return attached
+}
+
+// CancelAlgoOrder 按自定义ID撤销策略委托（已触发、已撤销或不存在时返回nil）
+func (c *OKXClient) CancelAlgoOrder(symbol, clientID string) error {
+	instID := c.convertSymbol(symbol)
+
+	data, err := c.request("GET", "/api/v5/trade/order-algo?algoClOrdId="+clientID, "")
+	if err != nil {
+		return err
+	}
+
+	var query struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			AlgoID string `json:"algoId"`
+			State  string `json:"state"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &query); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+	// 51603: 委托不存在（如开仓未成交，策略委托尚未生成）
+	if query.Code == "51603" || (query.Code == "0" && len(query.Data) == 0) {
+		return nil
+	}
+	if query.Code != "0" {
+		return c.apiError(query.Code, "查询策略委托失败: "+query.Msg)
+	}
+
+	algo := query.Data[0]
+	if algo.State != "live" && algo.State != "partially_effective" {
+		logger.Debugf("[DEBUG] 策略委托 %s 状态为 %s，无需撤销", clientID, algo.State)
+		return nil
+	}
+
+	bodyBytes, err := json.Marshal([]map[string]string{{"instId": instID, "algoId": algo.AlgoID}})
+	if err != nil {
+		return err
+	}
+
+	data, err = c.request("POST", "/api/v5/trade/cancel-algos", string(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+	if response.Code != "0" {
+		if len(response.Data) > 0 && response.Data[0].SCode != "" && response.Data[0].SCode != "0" {
+			return c.apiError(response.Data[0].SCode, fmt.Sprintf("撤销策略委托失败: %s (详情: %s)", response.Msg, response.Data[0].SMsg))
+		}
+		return c.apiError(response.Code, "撤销策略委托失败: "+response.Msg)
+	}
+
+	return nil
+}
+
+// ListInstruments 获取可交易的交易对列表（仅返回交易中的产品）
+func (c *OKXClient) ListInstruments(instType string) ([]InstrumentInfo, error) {
+	okxInstType := c.instType()
+	if instType != "" {
+		okxInstType = strings.ToUpper(instType)
+	}
+
+	path := fmt.Sprintf("/api/v5/public/instruments?instType=%s", okxInstType)
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			InstID    string `json:"instId"`
+			BaseCcy   string `json:"baseCcy"`   // 基础币种（现货）
+			QuoteCcy  string `json:"quoteCcy"`  // 计价币种（现货）
+			CtVal     string `json:"ctVal"`     // 合约面值
+			CtValCcy  string `json:"ctValCcy"`  // 合约面值币种
+			SettleCcy string `json:"settleCcy"` // 结算币种
+			LotSz     string `json:"lotSz"`
+			MinSz     string `json:"minSz"`
+			TickSz    string `json:"tickSz"`
+			Lever     string `json:"lever"` // 最大杠杆（现货为空）
+			State     string `json:"state"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	instruments := make([]InstrumentInfo, 0, len(response.Data))
+	for _, item := range response.Data {
+		if item.State != "live" {
+			continue
+		}
+
+		info := InstrumentInfo{
+			InstID:        item.InstID,
+			BaseCurrency:  item.BaseCcy,
+			QuoteCurrency: item.QuoteCcy,
+			State:         item.State,
+		}
+		if okxInstType != "SPOT" {
+			info.BaseCurrency = item.CtValCcy
+			info.QuoteCurrency = item.SettleCcy
+		}
+		info.ContractValue, _ = strconv.ParseFloat(item.CtVal, 64)
+		info.LotSize, _ = strconv.ParseFloat(item.LotSz, 64)
+		info.MinSize, _ = strconv.ParseFloat(item.MinSz, 64)
+		info.TickSize, _ = strconv.ParseFloat(item.TickSz, 64)
+		info.MaxLeverage, _ = strconv.ParseFloat(item.Lever, 64)
+
+		instruments = append(instruments, info)
+	}
+
+	return instruments, nil
+}
+
+// PlaceOrder 下单（支持现货和合约），返回交易所订单ID
+func (c *OKXClient) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	orderData, clOrdID, err := c.buildOrderData(symbol, side, amount, params)
+	if err != nil {
+		return "", err
+	}
+
+	bodyBytes, err := json.Marshal(orderData)
+	if err != nil {
+		return "", err
+	}
+
+	var orderID string
+	attempt := 0
+	err = c.retry.do("下单", func() error {
+		attempt++
+		if attempt > 1 {
+			// 上次请求结果未知，先按 clOrdId 查询，避免重复下单
+			order, err := c.fetchOrderByClientID(symbol, clOrdID)
+			if err != nil {
+				return err
+			}
+			if order != nil {
+				logger.Printf("[INFO] 订单 %s 已提交成功（ordId: %s），不再重复下单", clOrdID, order.OrderID)
+				orderID = order.OrderID
+				return nil
+			}
+		}
+
+		var err error
+		orderID, err = c.submitOrder(bodyBytes)
+		if err != nil && isOKXDuplicateClientID(err) {
+			// 同一下单意图已提交过：本次调用的前一次请求已成功时返回已有订单；首次请求即重复时订单来自之前的下单尝试，
+			// 仅接受仍在委托中且方向、数量一致的订单，已成交或已撤销的旧订单不能当作本次下单成功
+			order, fetchErr := c.fetchOrderByClientID(symbol, clOrdID)
+			if fetchErr == nil && order != nil {
+				if attempt > 1 || sameOKXAttempt(order, orderData) {
+					logger.Printf("[INFO] 订单 %s 已存在（ordId: %s），不再重复下单", clOrdID, order.OrderID)
+					orderID = order.OrderID
+					return nil
+				}
+				return fmt.Errorf("自定义订单ID %s 已被之前的订单 %s（%s）使用: %w", clOrdID, order.OrderID, order.State, err)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// 开仓成交后提交移动止损（OKX附带策略委托不支持移动止损，需单独提交）
+	if ratio, _ := params[ParamTrailingCallbackRatio].(float64); ratio > 0 {
+		clientID, _ := params[ParamTrailingClientID].(string)
+		if err := c.placeTrailingStop(orderData, ratio, clientID); err != nil {
+			logger.Warnf("[WARNING] 开仓单 %s 已提交，但移动止损委托提交失败，请人工检查: %v", orderID, err)
+		}
+	}
+
+	return orderID, nil
+}
+
+// placeTrailingStop 按开仓单参数提交反向只减仓的移动止损策略委托（move_order_stop）
+// ratio: 回调幅度（%），价格从最高（空仓为最低）点回调该幅度时市价平仓
+func (c *OKXClient) placeTrailingStop(orderData map[string]interface{}, ratio float64, clientID string) error {
+	side := "sell"
+	if orderData["side"] == "sell" {
+		side = "buy"
+	}
+	algo := map[string]interface{}{
+		"instId":        orderData["instId"],
+		"tdMode":        orderData["tdMode"],
+		"side":          side,
+		"ordType":       "move_order_stop",
+		"sz":            orderData["sz"],
+		"callbackRatio": strconv.FormatFloat(ratio/100, 'f', -1, 64),
+		"reduceOnly":    true,
+	}
+	if posSide, ok := orderData["posSide"]; ok {
+		algo["posSide"] = posSide
+	}
+	if clientID != "" {
+		algo["algoClOrdId"] = clientID
+	}
+
+	bodyBytes, err := json.Marshal(algo)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("[DEBUG] OKX移动止损请求: %s", string(bodyBytes))
+
+	data, err := c.request("POST", "/api/v5/trade/order-algo", string(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			AlgoID string `json:"algoId"`
+			SCode  string `json:"sCode"`
+			SMsg   string `json:"sMsg"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+	if response.Code != "0" {
+		if len(response.Data) > 0 && response.Data[0].SCode != "" && response.Data[0].SCode != "0" {
+			return c.apiError(response.Data[0].SCode, fmt.Sprintf("移动止损委托失败: %s (详情: %s)", response.Msg, response.Data[0].SMsg))
+		}
+		return c.apiError(response.Code, "移动止损委托失败: "+response.Msg)
+	}
+
+	if len(response.Data) > 0 {
+		logger.Printf("[INFO] 移动止损委托已提交 (algoId: %s, 回调幅度: %.2f%%)", response.Data[0].AlgoID, ratio)
+	}
+	return nil
+}
+
+// buildOrderData 构建下单参数（按交易对精度换算数量，并生成自定义订单ID），返回订单参数和自定义订单ID
+func (c *OKXClient) buildOrderData(symbol, side string, amount float64, params map[string]interface{}) (map[string]interface{}, string, error) {
 	instID := c.convertSymbol(symbol)
 
 	// 获取交易对信息以确定正确的下单数量
 	instInfo, err := c.GetInstrumentInfo(symbol)
 	if err != nil {
-		return fmt.Errorf("获取交易对信息失败: %w", err)
+		return nil, "", fmt.Errorf("获取交易对信息失败: %w", err)
 	}
 
 	var orderSize float64
@@ -492,20 +1394,66 @@ func (c *OKXClient) PlaceOrder(symbol, side string, amount float64, params map[s
 
 	// 合并额外参数（如 posSide, reduceOnly 等，仅合约有效）
 	for k, v := range params {
+		switch k {
+		case ParamStopLossPrice, ParamStopLossClientID, ParamTakeProfitPrice, ParamTakeProfitClientID,
+			ParamTrailingCallbackRatio, ParamTrailingClientID:
+			continue
+		case ParamClientOrderID:
+			orderData["clOrdId"] = v
+			continue
+		case ParamLimitPrice:
+			if px, ok := v.(float64); ok && px > 0 {
+				orderData["ordType"] = "limit"
+				orderData["px"] = strconv.FormatFloat(RoundPrice(px, instInfo.TickSize), 'f', -1, 64)
+			}
+			continue
+		}
 		orderData[k] = v
 	}
 
-	bodyBytes, err := json.Marshal(orderData)
-	if err != nil {
-		return err
+	// 单向持仓模式下不传 posSide（平仓由 reduceOnly 保证只减仓）
+	if _, ok := orderData["posSide"]; ok && c.tradingMode != config.TradingModeSpot && c.positionMode() == okxPosModeNet {
+		delete(orderData, "posSide")
 	}
 
+	// 附带止损止盈（交易所端策略委托，开仓成交后生效）
+	if attached := okxAttachedAlgoOrders(params, instInfo.TickSize); len(attached) > 0 {
+		orderData["attachAlgoOrds"] = attached
+	}
+
+	// 自定义订单ID作为幂等键，重试前据此确认订单是否已提交
+	clOrdID, _ := orderData["clOrdId"].(string)
+	if clOrdID == "" {
+		clOrdID = NewClientOrderID()
+		orderData["clOrdId"] = clOrdID
+	}
+
+	return orderData, clOrdID, nil
+}
+
+// sameOKXAttempt 按 clOrdId 查到的已有订单是否属于本次下单：仍在委托中，且方向和数量与本次下单一致
+func sameOKXAttempt(order *models.Order, orderData map[string]interface{}) bool {
+	if order.IsFinal() {
+		return false
+	}
+	size, err := strconv.ParseFloat(fmt.Sprint(orderData["sz"]), 64)
+	return err == nil && order.Side == orderData["side"] && order.Size == size
+}
+
+// isOKXDuplicateClientID 判断是否为自定义订单ID重复错误
+func isOKXDuplicateClientID(err error) bool {
+	var exErr *ExchangeError
+	return errors.As(err, &exErr) && exErr.Code == "51016"
+}
+
+// submitOrder 提交下单请求，返回交易所订单ID
+func (c *OKXClient) submitOrder(bodyBytes []byte) (string, error) {
 	// 记录请求详情
 	logger.Debugf("[DEBUG] OKX下单请求: %s", string(bodyBytes))
 
 	data, err := c.request("POST", "/api/v5/trade/order", string(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("请求失败: %w", err)
+		return "", fmt.Errorf("请求失败: %w", err)
 	}
 
 	// 记录响应详情
@@ -523,74 +1471,890 @@ func (c *OKXClient) PlaceOrder(symbol, side string, amount float64, params map[s
 	}
 
 	if err := json.Unmarshal(data, &response); err != nil {
-		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+		return "", fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
 	}
 
 	if response.Code != "0" {
-		// 如果有详细错误信息，显示出来
-		if len(response.Data) > 0 && response.Data[0].SMsg != "" {
-			return fmt.Errorf("OKX下单失败 [%s]: %s (详情: %s)", response.Code, response.Msg, response.Data[0].SMsg)
+		// 如果有详细错误信息，以明细错误码为准
+		if len(response.Data) > 0 && response.Data[0].SCode != "" && response.Data[0].SCode != "0" {
+			return "", c.apiError(response.Data[0].SCode, fmt.Sprintf("下单失败: %s (详情: %s)", response.Msg, response.Data[0].SMsg))
 		}
-		return fmt.Errorf("OKX下单失败 [%s]: %s", response.Code, response.Msg)
+		return "", c.apiError(response.Code, "下单失败: "+response.Msg)
 	}
 
-	return nil
-}
+	if len(response.Data) == 0 {
+		return "", fmt.Errorf("OKX下单响应缺少订单数据")
+	}
 
-// SetLeverage 设置杠杆
-func (c *OKXClient) SetLeverage(symbol string, leverage int) error {
-	instID := c.convertSymbol(symbol)
+	return response.Data[0].OrdId, nil
+}
 
-	leverageData := map[string]interface{}{
-		"instId":  instID,
-		"lever":   fmt.Sprintf("%d", leverage),
-		"mgnMode": "cross",
+// PlaceOrders 批量下单，超过 MaxBatchOrders 笔时分批提交，返回与请求一一对应的结果
+func (c *OKXClient) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	results := make([]OrderResult, len(requests))
+	for start := 0; start < len(requests); start += MaxBatchOrders {
+		end := start + MaxBatchOrders
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := c.placeOrderBatch(requests[start:end], results[start:end]); err != nil {
+			return results, err
+		}
 	}
+	return results, nil
+}
 
-	bodyBytes, err := json.Marshal(leverageData)
-	if err != nil {
-		return err
+// placeOrderBatch 提交一批订单（不超过 MaxBatchOrders 笔），结果写入 results
+func (c *OKXClient) placeOrderBatch(requests []OrderRequest, results []OrderResult) error {
+	orders := make(map[int]map[string]interface{}, len(requests))
+	var pending []int // 待提交订单在 requests 中的下标
+	for i, req := range requests {
+		orderData, clOrdID, err := c.buildOrderData(req.Symbol, req.Side, req.Amount, req.Params)
+		results[i].ClientOrderID = clOrdID
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		orders[i] = orderData
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return nil
 	}
 
-	data, err := c.request("POST", "/api/v5/account/set-leverage", string(bodyBytes))
+	attempt := 0
+	return c.retry.do("批量下单", func() error {
+		attempt++
+		if attempt > 1 {
+			// 上次请求结果未知，先按 clOrdId 逐笔确认，已提交成功的不再重复下单
+			var unconfirmed []int
+			for _, i := range pending {
+				order, err := c.fetchOrderByClientID(requests[i].Symbol, results[i].ClientOrderID)
+				if err != nil {
+					return err
+				}
+				if order == nil {
+					unconfirmed = append(unconfirmed, i)
+					continue
+				}
+				logger.Printf("[INFO] 订单 %s 已提交成功（ordId: %s），不再重复下单", results[i].ClientOrderID, order.OrderID)
+				results[i].OrderID = order.OrderID
+				results[i].Err = nil
+			}
+			pending = unconfirmed
+			if len(pending) == 0 {
+				return nil
+			}
+		}
+
+		batch := make([]map[string]interface{}, 0, len(pending))
+		for _, i := range pending {
+			batch = append(batch, orders[i])
+		}
+		bodyBytes, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		return c.submitOrderBatch(bodyBytes, pending, results)
+	})
+}
+
+// submitOrderBatch 提交批量下单请求，逐笔解析结果
+// 整批被拒绝（无明细结果）时返回错误；部分成功时各笔错误记录在 results 中
+func (c *OKXClient) submitOrderBatch(bodyBytes []byte, index []int, results []OrderResult) error {
+	logger.Debugf("[DEBUG] OKX批量下单请求: %s", string(bodyBytes))
+
+	data, err := c.request("POST", "/api/v5/trade/batch-orders", string(bodyBytes))
 	if err != nil {
-		return err
+		return fmt.Errorf("请求失败: %w", err)
 	}
 
+	logger.Debugf("[DEBUG] OKX响应: %s", string(data))
+
 	var response struct {
 		Code string `json:"code"`
 		Msg  string `json:"msg"`
+		Data []struct {
+			OrdId   string `json:"ordId"`
+			ClOrdId string `json:"clOrdId"`
+			SCode   string `json:"sCode"`
+			SMsg    string `json:"sMsg"`
+		} `json:"data"`
 	}
 
 	if err := json.Unmarshal(data, &response); err != nil {
-		return err
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
 	}
 
-	if response.Code != "0" {
-		return fmt.Errorf("设置杠杆失败: %s", response.Msg)
+	// code: 0 全部成功, 1 全部失败, 2 部分成功；失败明细以 sCode 为准
+	if len(response.Data) == 0 {
+		if response.Code != "0" {
+			return c.apiError(response.Code, "批量下单失败: "+response.Msg)
+		}
+		return fmt.Errorf("OKX批量下单响应缺少订单数据")
+	}
+
+	byClientID := make(map[string]int, len(index))
+	for _, i := range index {
+		byClientID[results[i].ClientOrderID] = i
+	}
+	for _, d := range response.Data {
+		i, ok := byClientID[d.ClOrdId]
+		if !ok {
+			continue
+		}
+		delete(byClientID, d.ClOrdId)
+		if d.SCode != "" && d.SCode != "0" {
+			results[i].Err = c.apiError(d.SCode, "下单失败: "+d.SMsg)
+			continue
+		}
+		results[i].OrderID = d.OrdId
+		results[i].Err = nil
+	}
+	for clOrdID, i := range byClientID {
+		results[i].Err = fmt.Errorf("OKX批量下单响应缺少订单 %s 的结果", clOrdID)
 	}
 
 	return nil
 }
 
-// 辅助函数
+// okxOrder OKX订单原始数据
+type okxOrder struct {
+	InstID     string `json:"instId"`
+	OrdID      string `json:"ordId"`
+	ClOrdID    string `json:"clOrdId"`
+	Side       string `json:"side"`
+	PosSide    string `json:"posSide"`
+	OrdType    string `json:"ordType"`
+	Px         string `json:"px"`
+	Sz         string `json:"sz"`
+	AccFillSz  string `json:"accFillSz"`
+	AvgPx      string `json:"avgPx"`
+	State      string `json:"state"`
+	Fee        string `json:"fee"`
+	FeeCcy     string `json:"feeCcy"`
+	ReduceOnly string `json:"reduceOnly"`
+	CTime      string `json:"cTime"`
+	UTime      string `json:"uTime"`
+}
 
-// roundToLotSize 将数量四舍五入到lotSize的整数倍
-func (c *OKXClient) roundToLotSize(size, lotSize float64) float64 {
-	if lotSize <= 0 {
-		return size
+// toOrder 转换为通用订单结构
+func (o *okxOrder) toOrder(symbol string) models.Order {
+	px, _ := strconv.ParseFloat(o.Px, 64)
+	sz, _ := strconv.ParseFloat(o.Sz, 64)
+	fillSz, _ := strconv.ParseFloat(o.AccFillSz, 64)
+	avgPx, _ := strconv.ParseFloat(o.AvgPx, 64)
+	fee, _ := strconv.ParseFloat(o.Fee, 64)
+	cTime, _ := strconv.ParseInt(o.CTime, 10, 64)
+	uTime, _ := strconv.ParseInt(o.UTime, 10, 64)
+
+	return models.Order{
+		OrderID:       o.OrdID,
+		ClientOrderID: o.ClOrdID,
+		Symbol:        symbol,
+		Side:          o.Side,
+		PosSide:       o.PosSide,
+		Type:          o.OrdType,
+		Price:         px,
+		Size:          sz,
+		FilledSize:    fillSz,
+		AvgPrice:      avgPx,
+		State:         o.State,
+		Fee:           fee,
+		FeeCurrency:   o.FeeCcy,
+		ReduceOnly:    o.ReduceOnly == "true",
+		CreatedAt:     time.UnixMilli(cTime),
+		UpdatedAt:     time.UnixMilli(uTime),
 	}
-	return float64(int(size/lotSize+0.5)) * lotSize
 }
 
-// roundUpToLotSize 向上取整到lotSize的整数倍
-func (c *OKXClient) roundUpToLotSize(size, lotSize float64) float64 {
-	if lotSize <= 0 {
+// FetchOrder 查询订单
+func (c *OKXClient) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	instID := c.convertSymbol(symbol)
+	path := fmt.Sprintf("/api/v5/trade/order?instId=%s&ordId=%s", instID, orderID)
+
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string     `json:"code"`
+		Msg  string     `json:"msg"`
+		Data []okxOrder `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("未找到订单: %s", orderID)
+	}
+
+	order := response.Data[0].toOrder(symbol)
+	return &order, nil
+}
+
+// fetchOrderByClientID 按自定义订单ID查询订单，订单不存在时返回 nil, nil
+func (c *OKXClient) fetchOrderByClientID(symbol, clOrdID string) (*models.Order, error) {
+	instID := c.convertSymbol(symbol)
+	path := fmt.Sprintf("/api/v5/trade/order?instId=%s&clOrdId=%s", instID, clOrdID)
+
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string     `json:"code"`
+		Msg  string     `json:"msg"`
+		Data []okxOrder `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	// 51603: 订单不存在
+	if response.Code == "51603" || (response.Code == "0" && len(response.Data) == 0) {
+		return nil, nil
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	order := response.Data[0].toOrder(symbol)
+	return &order, nil
+}
+
+// CancelOrder 撤销订单
+func (c *OKXClient) CancelOrder(symbol, orderID string) error {
+	instID := c.convertSymbol(symbol)
+
+	cancelData := map[string]interface{}{
+		"instId": instID,
+		"ordId":  orderID,
+	}
+
+	bodyBytes, err := json.Marshal(cancelData)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.request("POST", "/api/v5/trade/cancel-order", string(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("请求失败: %w", err)
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OrdId string `json:"ordId"`
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(data))
+	}
+
+	if response.Code != "0" {
+		if len(response.Data) > 0 && response.Data[0].SCode != "" && response.Data[0].SCode != "0" {
+			return c.apiError(response.Data[0].SCode, fmt.Sprintf("撤单失败: %s (详情: %s)", response.Msg, response.Data[0].SMsg))
+		}
+		return c.apiError(response.Code, "撤单失败: "+response.Msg)
+	}
+
+	return nil
+}
+
+// FetchOpenOrders 获取未成交订单
+func (c *OKXClient) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	instID := c.convertSymbol(symbol)
+	path := fmt.Sprintf("/api/v5/trade/orders-pending?instType=%s&instId=%s", c.instType(), instID)
+
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string     `json:"code"`
+		Msg  string     `json:"msg"`
+		Data []okxOrder `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	orders := make([]models.Order, 0, len(response.Data))
+	for i := range response.Data {
+		orders = append(orders, response.Data[i].toOrder(symbol))
+	}
+
+	return orders, nil
+}
+
+// FetchMyTrades 获取账户成交记录（近三个月），按时间升序返回
+func (c *OKXClient) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	instID := c.convertSymbol(symbol)
+
+	var trades []models.Trade
+	after := "" // 分页游标（billId），返回比该ID更早的记录
+
+	for {
+		path := fmt.Sprintf("/api/v5/trade/fills-history?instType=%s&instId=%s&limit=100", c.instType(), instID)
+		if !since.IsZero() {
+			path += fmt.Sprintf("&begin=%d", since.UnixMilli())
+		}
+		if after != "" {
+			path += "&after=" + after
+		}
+
+		data, err := c.request("GET", path, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+			Data []struct {
+				InstID   string `json:"instId"`
+				TradeID  string `json:"tradeId"`
+				OrdID    string `json:"ordId"`
+				BillID   string `json:"billId"`
+				Side     string `json:"side"`
+				PosSide  string `json:"posSide"`
+				FillPx   string `json:"fillPx"`
+				FillSz   string `json:"fillSz"`
+				FillPnl  string `json:"fillPnl"`
+				Fee      string `json:"fee"`
+				FeeCcy   string `json:"feeCcy"`
+				ExecType string `json:"execType"` // T: taker, M: maker
+				Ts       string `json:"ts"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+
+		if response.Code != "0" {
+			return nil, c.apiError(response.Code, response.Msg)
+		}
+
+		for _, fill := range response.Data {
+			price, _ := strconv.ParseFloat(fill.FillPx, 64)
+			size, _ := strconv.ParseFloat(fill.FillSz, 64)
+			pnl, _ := strconv.ParseFloat(fill.FillPnl, 64)
+			fee, _ := strconv.ParseFloat(fill.Fee, 64)
+			ts, _ := strconv.ParseInt(fill.Ts, 10, 64)
+
+			trades = append(trades, models.Trade{
+				TradeID:     fill.TradeID,
+				OrderID:     fill.OrdID,
+				Symbol:      symbol,
+				Side:        fill.Side,
+				PosSide:     fill.PosSide,
+				Price:       price,
+				Size:        size,
+				Fee:         fee,
+				FeeCurrency: fill.FeeCcy,
+				RealizedPnL: pnl,
+				IsMaker:     fill.ExecType == "M",
+				Timestamp:   time.UnixMilli(ts),
+			})
+		}
+
+		// 不足一页说明已取完
+		if len(response.Data) < 100 {
+			break
+		}
+		after = response.Data[len(response.Data)-1].BillID
+	}
+
+	// OKX返回的数据是倒序的，反转为时间升序
+	for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+		trades[i], trades[j] = trades[j], trades[i]
+	}
+
+	return trades, nil
+}
+
+// okxBill 账单流水
+type okxBill struct {
+	BillID  string `json:"billId"`
+	InstID  string `json:"instId"`
+	Ccy     string `json:"ccy"`
+	BalChg  string `json:"balChg"` // 余额变动（正数转入/收入，负数转出/支出）
+	Type    string `json:"type"`
+	SubType string `json:"subType"`
+	Ts      string `json:"ts"`
+}
+
+// OKX 账单类型
+const (
+	okxBillTypeTransfer   = "1" // 划转
+	okxBillTypeFundingFee = "8" // 资金费
+)
+
+// fetchBills 分页获取交易账户账单（近三个月，按时间倒序），query 为额外的筛选参数
+func (c *OKXClient) fetchBills(query string, since time.Time) ([]okxBill, error) {
+	var bills []okxBill
+	after := "" // 分页游标（billId），返回比该ID更早的记录
+
+	for {
+		path := "/api/v5/account/bills-archive?limit=100&" + query
+		if !since.IsZero() {
+			path += fmt.Sprintf("&begin=%d", since.UnixMilli())
+		}
+		if after != "" {
+			path += "&after=" + after
+		}
+
+		data, err := c.request("GET", path, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Code string    `json:"code"`
+			Msg  string    `json:"msg"`
+			Data []okxBill `json:"data"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		if response.Code != "0" {
+			return nil, c.apiError(response.Code, response.Msg)
+		}
+
+		bills = append(bills, response.Data...)
+		if len(response.Data) < 100 {
+			break
+		}
+		after = response.Data[len(response.Data)-1].BillID
+	}
+	return bills, nil
+}
+
+// FetchFundingFees 获取资金费用记录（交易账户账单中的资金费，近三个月）
+func (c *OKXClient) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	if c.tradingMode == config.TradingModeSpot {
+		return nil, nil
+	}
+
+	bills, err := c.fetchBills(fmt.Sprintf("instType=SWAP&type=%s", okxBillTypeFundingFee), since)
+	if err != nil {
+		return nil, err
+	}
+
+	instID := c.convertSymbol(symbol)
+	var fees []models.FundingFee
+	for i := len(bills) - 1; i >= 0; i-- { // 反转为时间升序
+		bill := bills[i]
+		if bill.InstID != instID {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(bill.BalChg, 64)
+		ts, _ := strconv.ParseInt(bill.Ts, 10, 64)
+		fees = append(fees, models.FundingFee{
+			Symbol:    symbol,
+			Amount:    amount,
+			Currency:  bill.Ccy,
+			Timestamp: time.UnixMilli(ts),
+		})
+	}
+	return fees, nil
+}
+
+// FetchTransfers 获取充值、提现（资金账户）和交易账户划转记录
+func (c *OKXClient) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	var transfers []models.AccountTransfer
+
+	deposits, err := c.fetchAssetHistory("/api/v5/asset/deposit-history", currency, since)
+	if err != nil {
+		return nil, fmt.Errorf("获取充值记录失败: %w", err)
+	}
+	for _, record := range deposits {
+		transfers = append(transfers, record.transfer(models.TransferDeposit, record.DepID, 1))
+	}
+
+	withdrawals, err := c.fetchAssetHistory("/api/v5/asset/withdrawal-history", currency, since)
+	if err != nil {
+		return nil, fmt.Errorf("获取提现记录失败: %w", err)
+	}
+	for _, record := range withdrawals {
+		transfers = append(transfers, record.transfer(models.TransferWithdrawal, record.WdID, -1))
+	}
+
+	bills, err := c.fetchBills(fmt.Sprintf("ccy=%s&type=%s", currency, okxBillTypeTransfer), since)
+	if err != nil {
+		return nil, fmt.Errorf("获取划转记录失败: %w", err)
+	}
+	for _, bill := range bills {
+		amount, _ := strconv.ParseFloat(bill.BalChg, 64)
+		ts, _ := strconv.ParseInt(bill.Ts, 10, 64)
+		transfers = append(transfers, models.AccountTransfer{
+			ID:        bill.BillID,
+			Type:      models.TransferInternal,
+			Currency:  bill.Ccy,
+			Amount:    amount,
+			Timestamp: time.UnixMilli(ts),
+		})
+	}
+
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].Timestamp.Before(transfers[j].Timestamp) })
+	return transfers, nil
+}
+
+// okxAssetRecord 充值/提现记录
+type okxAssetRecord struct {
+	DepID string `json:"depId"`
+	WdID  string `json:"wdId"`
+	Ccy   string `json:"ccy"`
+	Amt   string `json:"amt"`
+	Fee   string `json:"fee"` // 提现手续费（正数）
+	State string `json:"state"`
+	Ts    string `json:"ts"`
+}
+
+// transfer 转换为资金流水，direction 为 1（转入）或 -1（转出）
+func (r okxAssetRecord) transfer(transferType, id string, direction float64) models.AccountTransfer {
+	amount, _ := strconv.ParseFloat(r.Amt, 64)
+	fee, _ := strconv.ParseFloat(r.Fee, 64)
+	ts, _ := strconv.ParseInt(r.Ts, 10, 64)
+	return models.AccountTransfer{
+		ID:        id,
+		Type:      transferType,
+		Currency:  r.Ccy,
+		Amount:    direction * amount,
+		Fee:       -fee,
+		Timestamp: time.UnixMilli(ts),
+	}
+}
+
+// fetchAssetHistory 分页获取已完成的充值或提现记录（按时间倒序，after 为时间戳游标）
+func (c *OKXClient) fetchAssetHistory(endpoint, currency string, since time.Time) ([]okxAssetRecord, error) {
+	var records []okxAssetRecord
+	after := ""
+
+	for {
+		path := fmt.Sprintf("%s?ccy=%s&limit=100", endpoint, currency)
+		if after != "" {
+			path += "&after=" + after
+		}
+
+		data, err := c.request("GET", path, "")
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Code string           `json:"code"`
+			Msg  string           `json:"msg"`
+			Data []okxAssetRecord `json:"data"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		if response.Code != "0" {
+			return nil, c.apiError(response.Code, response.Msg)
+		}
+
+		reachedSince := false
+		for _, record := range response.Data {
+			ts, _ := strconv.ParseInt(record.Ts, 10, 64)
+			if !since.IsZero() && ts < since.UnixMilli() {
+				reachedSince = true
+				break
+			}
+			if record.State == "2" { // 2: 充值到账 / 提现成功
+				records = append(records, record)
+			}
+		}
+		if reachedSince || len(response.Data) < 100 {
+			break
+		}
+		after = response.Data[len(response.Data)-1].Ts
+	}
+	return records, nil
+}
+
+// FetchTradingFees 获取账户手续费率
+func (c *OKXClient) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	instID := c.convertSymbol(symbol)
+
+	// 现货按 instId 查询，合约按 instFamily 查询
+	path := fmt.Sprintf("/api/v5/account/trade-fee?instType=%s&instId=%s", c.instType(), instID)
+	if c.tradingMode != config.TradingModeSpot {
+		path = fmt.Sprintf("/api/v5/account/trade-fee?instType=%s&instFamily=%s", c.instType(), strings.TrimSuffix(instID, "-SWAP"))
+	}
+
+	data, err := c.request("GET", path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Maker  string `json:"maker"` // 现货/币本位费率（负数表示支出）
+			Taker  string `json:"taker"`
+			MakerU string `json:"makerU"` // U本位合约费率
+			TakerU string `json:"takerU"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("未获取到手续费率")
+	}
+
+	fee := response.Data[0]
+	makerStr, takerStr := fee.Maker, fee.Taker
+	if c.tradingMode != config.TradingModeSpot && fee.MakerU != "" {
+		makerStr, takerStr = fee.MakerU, fee.TakerU
+	}
+	maker, _ := strconv.ParseFloat(makerStr, 64)
+	taker, _ := strconv.ParseFloat(takerStr, 64)
+
+	// OKX费率以负数表示支出，统一转换为正数表示支出
+	return &models.FeeRate{
+		Symbol: symbol,
+		Maker:  -maker,
+		Taker:  -taker,
+	}, nil
+}
+
+// positionMode 获取账户持仓模式（未配置时通过 /account/config 检测并缓存，检测失败按双向持仓处理）
+func (c *OKXClient) positionMode() string {
+	c.posModeMu.Lock()
+	defer c.posModeMu.Unlock()
+
+	if c.posMode != "" {
+		return c.posMode
+	}
+
+	mode, err := c.fetchPositionMode()
+	if err != nil {
+		logger.Warnf("[WARNING] 检测持仓模式失败: %v，按双向持仓处理", err)
+		return okxPosModeLongShort
+	}
+	logger.Printf("[INFO] 账户持仓模式: %s", mode)
+	c.posMode = mode
+	return mode
+}
+
+// fetchPositionMode 查询账户配置中的持仓模式
+func (c *OKXClient) fetchPositionMode() (string, error) {
+	accountConfig, err := c.fetchAccountConfig()
+	if err != nil {
+		return "", err
+	}
+	if accountConfig.PosMode == "" {
+		return "", fmt.Errorf("账户配置缺少持仓模式")
+	}
+	return accountConfig.PosMode, nil
+}
+
+// okxAccountConfigPath 账户配置接口（子账户校验本身使用，不再校验）
+const okxAccountConfigPath = "/api/v5/account/config"
+
+// okxAccountConfig 账户配置
+type okxAccountConfig struct {
+	UID     string `json:"uid"`     // 当前账户UID
+	MainUID string `json:"mainUid"` // 主账户UID（与 uid 相同表示当前为主账户）
+	PosMode string `json:"posMode"` // 持仓模式
+}
+
+// fetchAccountConfig 查询API Key所属账户的配置
+func (c *OKXClient) fetchAccountConfig() (*okxAccountConfig, error) {
+	data, err := c.request("GET", okxAccountConfigPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Code string             `json:"code"`
+		Msg  string             `json:"msg"`
+		Data []okxAccountConfig `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, c.apiError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("账户配置为空")
+	}
+
+	return &response.Data[0], nil
+}
+
+// verifySubAccount 配置子账户时，首次访问交易和账户接口前确认 API Key 属于子账户
+// OKX 的下单、余额和持仓均作用于 API Key 所属账户，使用主账户 API Key 会动用主账户资金，因此校验不通过时拒绝请求；
+// 校验请求失败时下次重试，确认后不再重复查询
+func (c *OKXClient) verifySubAccount(path string) error {
+	if c.subAccount == "" || c.apiKey == "" || path == okxAccountConfigPath {
+		return nil
+	}
+	if group := c.endpointGroup(path); group != "trade" && group != "account" {
+		return nil
+	}
+
+	c.subAccountMu.Lock()
+	defer c.subAccountMu.Unlock()
+	if c.subAccountVerified {
+		return nil
+	}
+
+	accountConfig, err := c.fetchAccountConfig()
+	if err != nil {
+		return fmt.Errorf("校验OKX子账户 %s 失败: %w", c.subAccount, err)
+	}
+	if accountConfig.UID == "" || accountConfig.UID == accountConfig.MainUID {
+		return fmt.Errorf("OKX API Key 属于主账户 (UID %s)，而非子账户 %s，请使用子账户创建的 API Key", accountConfig.UID, c.subAccount)
+	}
+
+	logger.Printf("[INFO] OKX 使用子账户 %s (UID %s，主账户 UID %s)", c.subAccount, accountConfig.UID, accountConfig.MainUID)
+	c.subAccountVerified = true
+	return nil
+}
+
+// okxAccountTypes 划转账户类型 -> OKX 账户代码
+var okxAccountTypes = map[string]string{
+	AccountFunding: "6",  // 资金账户
+	AccountTrading: "18", // 交易账户
+}
+
+// Transfer 资金账户与交易账户之间划转（同一账户内划转，type=0）
+func (c *OKXClient) Transfer(currency string, amount float64, from, to string) error {
+	fromType, ok := okxAccountTypes[from]
+	if !ok {
+		return fmt.Errorf("OKX 不支持的划转账户: %s", from)
+	}
+	toType, ok := okxAccountTypes[to]
+	if !ok {
+		return fmt.Errorf("OKX 不支持的划转账户: %s", to)
+	}
+
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"ccy":  currency,
+		"amt":  strconv.FormatFloat(amount, 'f', -1, 64),
+		"from": fromType,
+		"to":   toType,
+		"type": "0",
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := c.request("POST", "/api/v5/asset/transfer", string(bodyBytes))
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+	if response.Code != "0" {
+		return c.apiError(response.Code, "资金划转失败: "+response.Msg)
+	}
+	return nil
+}
+
+// SetLeverage 设置杠杆
+func (c *OKXClient) SetLeverage(symbol string, leverage int) error {
+	instID := c.convertSymbol(symbol)
+
+	leverageData := map[string]interface{}{
+		"instId":  instID,
+		"lever":   fmt.Sprintf("%d", leverage),
+		"mgnMode": "cross",
+	}
+
+	bodyBytes, err := json.Marshal(leverageData)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.request("POST", "/api/v5/account/set-leverage", string(bodyBytes))
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+
+	if response.Code != "0" {
+		return c.apiError(response.Code, "设置杠杆失败: "+response.Msg)
+	}
+
+	return nil
+}
+
+// 辅助函数
+
+// roundToLotSize 将数量四舍五入到lotSize的整数倍
+func (c *OKXClient) roundToLotSize(size, lotSize float64) float64 {
+	if lotSize <= 0 {
+		return size
+	}
+	return float64(int(size/lotSize+0.5)) * lotSize
+}
+
+// roundUpToLotSize 向上取整到lotSize的整数倍
+func (c *OKXClient) roundUpToLotSize(size, lotSize float64) float64 {
+	if lotSize <= 0 {
 		return size
 	}
 	return float64(int(size/lotSize)+1) * lotSize
 }
 
+// instType 根据交易模式返回 OKX 产品类型
+func (c *OKXClient) instType() string {
+	if c.tradingMode == config.TradingModeSpot {
+		return "SPOT"
+	}
+	return "SWAP" // 默认合约
+}
+
 func (c *OKXClient) convertSymbol(symbol string) string {
 	// BTC/USDT:USDT -> BTC-USDT (spot) or BTC-USDT-SWAP (futures)
 	parts := strings.Split(symbol, "/")
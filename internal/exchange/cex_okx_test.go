@@ -0,0 +1,32 @@
+package exchange
+
+import (
+	"testing"
+
+	"dsbot/internal/models"
+)
+
+// 自定义订单ID重复时只接受仍在委托中、方向和数量与本次下单一致的已有订单
+func TestSameOKXAttempt(t *testing.T) {
+	orderData := map[string]interface{}{"side": "buy", "sz": "0.50"}
+	tests := []struct {
+		name  string
+		order models.Order
+		want  bool
+	}{
+		{name: "委托中", order: models.Order{Side: "buy", Size: 0.5, State: models.OrderStateLive}, want: true},
+		{name: "部分成交", order: models.Order{Side: "buy", Size: 0.5, State: models.OrderStatePartiallyFilled}, want: true},
+		{name: "已成交的旧订单", order: models.Order{Side: "buy", Size: 0.5, State: models.OrderStateFilled}},
+		{name: "已撤销的旧订单", order: models.Order{Side: "buy", Size: 0.5, State: models.OrderStateCanceled}},
+		{name: "方向不同", order: models.Order{Side: "sell", Size: 0.5, State: models.OrderStateLive}},
+		{name: "数量不同", order: models.Order{Side: "buy", Size: 0.3, State: models.OrderStateLive}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameOKXAttempt(&tt.order, orderData); got != tt.want {
+				t.Fatalf("接受已有订单 = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// clockRetryInterval 服务器时间获取失败后的重试间隔
+const clockRetryInterval = time.Minute
+
+// serverClock 交易所服务器时钟 - 定期获取服务器时间计算本地时钟偏差，
+// 生成签名时间戳时按偏差校正，避免本地时钟漂移导致签名请求被拒绝
+type serverClock struct {
+	exchange  string
+	fetch     func() (time.Time, error) // 获取服务器时间
+	interval  time.Duration             // 同步间隔
+	warnDrift time.Duration             // 偏差告警阈值
+
+	mu       sync.Mutex
+	offset   time.Duration // 服务器时间 - 本地时间
+	nextSync time.Time     // 下次同步时间（零值表示尚未同步）
+}
+
+// newServerClock 根据配置创建服务器时钟（未配置项使用默认值）
+func newServerClock(exchange string, cfg config.ClockSyncConfig, fetch func() (time.Time, error)) *serverClock {
+	c := &serverClock{
+		exchange:  exchange,
+		fetch:     fetch,
+		interval:  5 * time.Minute,
+		warnDrift: time.Second,
+	}
+	if cfg.IntervalSeconds > 0 {
+		c.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	if cfg.WarnDriftMs > 0 {
+		c.warnDrift = time.Duration(cfg.WarnDriftMs) * time.Millisecond
+	}
+	return c
+}
+
+// Now 校正后的当前时间（首次调用及同步间隔到期时先同步服务器时间）
+func (c *serverClock) Now() time.Time {
+	c.mu.Lock()
+	due := !time.Now().Before(c.nextSync)
+	if due {
+		// 先推迟下次同步时间，避免并发请求重复同步
+		c.nextSync = time.Now().Add(clockRetryInterval)
+	}
+	c.mu.Unlock()
+
+	if due {
+		c.sync()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Add(c.offset)
+}
+
+// Invalidate 使下次获取时间时立即重新同步（如收到时间戳过期错误）
+func (c *serverClock) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextSync = time.Time{}
+}
+
+// sync 获取服务器时间并更新偏差（以请求往返的中点作为服务器时间对应的本地时间）
+func (c *serverClock) sync() {
+	start := time.Now()
+	serverTime, err := c.fetch()
+	if err != nil {
+		logger.Warnf("[时钟] 获取%s服务器时间失败，%v后重试: %v", c.exchange, clockRetryInterval, err)
+		return
+	}
+	rtt := time.Since(start)
+	offset := serverTime.Sub(start.Add(rtt / 2))
+
+	c.mu.Lock()
+	c.offset = offset
+	c.nextSync = time.Now().Add(c.interval)
+	c.mu.Unlock()
+
+	drift := offset
+	if drift < 0 {
+		drift = -drift
+	}
+	if drift > c.warnDrift {
+		logger.Warnf("[时钟] ⚠️ 本地时钟与%s服务器偏差 %v (阈值 %v)，签名时间戳已按偏差校正，请检查系统时间同步(NTP)",
+			c.exchange, offset.Round(time.Millisecond), c.warnDrift)
+	} else {
+		logger.Debugf("[DEBUG] %s服务器时钟偏差: %v (往返 %v)", c.exchange, offset.Round(time.Millisecond), rtt.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,1323 @@
+package exchange
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/nets"
+)
+
+const (
+	HyperliquidBaseURL        = "https://api.hyperliquid.xyz"
+	HyperliquidTestnetBaseURL = "https://api.hyperliquid-testnet.xyz"
+)
+
+// hyperliquidDefaultRateLimits Hyperliquid默认限流配置（按IP每分钟1200权重留出余量）
+var hyperliquidDefaultRateLimits = map[string]config.RateLimitConfig{
+	"public":  {RequestsPerSecond: 5, Burst: 10},
+	"account": {RequestsPerSecond: 5, Burst: 10},
+	"trade":   {RequestsPerSecond: 5, Burst: 10},
+}
+
+// hyperliquidIntervals Hyperliquid K线周期
+var hyperliquidIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+const (
+	hyperliquidSlippage    = 0.05 // 市价单（IOC限价单）的最大滑点
+	hyperliquidMinNotional = 10.0 // 最小订单金额（USDC）
+	hyperliquidMaxFills    = 2000 // 成交记录单次最多返回条数
+)
+
+// hyperliquidAsset 永续合约资产信息
+type hyperliquidAsset struct {
+	index       int // 资产编号（下单时使用）
+	name        string
+	szDecimals  int
+	maxLeverage float64
+	delisted    bool
+}
+
+// HyperliquidClient Hyperliquid 永续合约客户端（链上订单簿DEX，单向持仓，USDC结算）
+// 交易操作使用钱包私钥按 EIP-712 签名（建议使用在 Hyperliquid 上授权的 API 钱包私钥），
+// 账户查询使用主账户地址；数量单位为基础币种
+type HyperliquidClient struct {
+	wallet     *walletKey
+	account    string // 主账户地址（查询持仓、余额和订单）
+	baseURL    string
+	mainnet    bool
+	httpClient *nets.HttpClient
+	rateLimit  *RateLimiter
+	retry      *retryPolicy
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+
+	metaMu sync.Mutex
+	assets map[string]*hyperliquidAsset // 币种 -> 资产信息
+}
+
+// NewHyperliquidClient 创建Hyperliquid客户端（仅支持合约模式）
+func NewHyperliquidClient(cfg *config.APIConfig, tradingMode config.TradingMode) (*HyperliquidClient, error) {
+	if tradingMode != config.TradingModeFutures {
+		return nil, fmt.Errorf("Hyperliquid 仅支持合约交易模式")
+	}
+
+	wallet, err := parseWalletKey(cfg.HyperliquidPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("Hyperliquid 钱包私钥无效: %w", err)
+	}
+	account := strings.ToLower(strings.TrimSpace(cfg.HyperliquidAccountAddress))
+	if account == "" {
+		account = wallet.address
+	}
+	if len(account) != 42 || !strings.HasPrefix(account, "0x") {
+		return nil, fmt.Errorf("Hyperliquid 账户地址格式错误: %s", account)
+	}
+
+	httpClient, err := nets.NewHttpClient(nets.DefaultTimeout, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %w", err)
+	}
+
+	baseURL := HyperliquidBaseURL
+	if cfg.UseTestnet {
+		baseURL = HyperliquidTestnetBaseURL
+	}
+
+	logger.Printf("[Hyperliquid] 账户: %s, 签名钱包: %s", account, wallet.address)
+	return &HyperliquidClient{
+		wallet:     wallet,
+		account:    account,
+		baseURL:    baseURL,
+		mainnet:    !cfg.UseTestnet,
+		httpClient: httpClient,
+		rateLimit:  NewRateLimiter(hyperliquidDefaultRateLimits, cfg.RateLimits),
+		retry:      newRetryPolicy(cfg.Retry),
+		assets:     make(map[string]*hyperliquidAsset),
+	}, nil
+}
+
+// GetExchangeName 获取交易所名称
+func (c *HyperliquidClient) GetExchangeName() string {
+	return string(config.ExchangeHyperliquid)
+}
+
+// Capabilities 获取交易所支持的功能（仅永续合约，单向持仓，批量下单逐笔提交）
+func (c *HyperliquidClient) Capabilities() Capabilities {
+	return Capabilities{
+		Futures:     true,
+		AlgoOrders:  true,
+		LimitOrders: true,
+	}
+}
+
+// ParseSymbols 解析交易对符号（永续合约格式）
+func (c *HyperliquidClient) ParseSymbols(symbolA, symbolB string) string {
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+// convertSymbol 转换为Hyperliquid币种名称（BTC/USDC:USDC -> BTC，计价币种统一为USDC）
+func (c *HyperliquidClient) convertSymbol(symbol string) string {
+	base, _ := splitSymbol(symbol)
+	return base
+}
+
+// hyperliquidCloid 自定义订单ID转换为Hyperliquid的 cloid（16字节十六进制，由自定义ID哈希得到，同一ID总是相同）
+func hyperliquidCloid(clientOrderID string) string {
+	sum := sha256.Sum256([]byte(clientOrderID))
+	return "0x" + hex.EncodeToString(sum[:16])
+}
+
+// hyperliquidWire 数值转换为接口格式（最多8位小数，去掉末尾的0）
+func hyperliquidWire(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 8, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// hyperliquidPrice 按价格规则取整（最多5位有效数字，且小数位不超过 6-szDecimals）
+func hyperliquidPrice(px float64, szDecimals int) float64 {
+	if px <= 0 {
+		return 0
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(px, 'g', 5, 64), 64)
+	if rounded >= 1e5 {
+		rounded = math.Round(px) // 整数价格不受有效数字限制
+	}
+	scale := math.Pow(10, float64(6-szDecimals))
+	return math.Round(rounded*scale) / scale
+}
+
+// hlFloat 解析数值字符串（空字符串返回0）
+func hlFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// classifyHyperliquidError 根据错误信息分类
+func classifyHyperliquidError(message string) ErrorKind {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "insufficient margin"), strings.Contains(msg, "insufficient balance"):
+		return ErrorKindInsufficientBalance
+	case strings.Contains(msg, "does not exist"), strings.Contains(msg, "signature"),
+		strings.Contains(msg, "not authorized"), strings.Contains(msg, "api wallet"):
+		return ErrorKindAuthFailed
+	case strings.Contains(msg, "minimum value"), strings.Contains(msg, "invalid size"),
+		strings.Contains(msg, "tick size"), strings.Contains(msg, "divisible"),
+		strings.Contains(msg, "reduce only"), strings.Contains(msg, "could not immediately match"),
+		strings.Contains(msg, "never placed"), strings.Contains(msg, "already canceled"),
+		strings.Contains(msg, "leverage"), strings.Contains(msg, "asset"):
+		return ErrorKindInvalidOrder
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// post 发送单次请求，网络错误、5xx、限流等临时性错误返回可重试的 ExchangeError
+func (c *HyperliquidClient) post(path, group string, body interface{}) ([]byte, error) {
+	c.rateLimit.Wait(group)
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	resp, err := c.httpClient.QueryRaw("POST", c.baseURL+path, headers, bodyBytes)
+	if err != nil {
+		return nil, &ExchangeError{Exchange: "Hyperliquid", Message: "网络请求失败", Kind: ErrorKindRetryable, Err: err}
+	}
+	if !resp.IsSuccess() {
+		bodyText := string(resp.Body)
+		if len(bodyText) > 512 {
+			bodyText = bodyText[:512] + "..."
+		}
+		exErr := &ExchangeError{
+			Exchange:   "Hyperliquid",
+			HTTPStatus: resp.StatusCode,
+			Message:    bodyText,
+			Kind:       classifyHyperliquidError(bodyText),
+			Headers:    rateLimitHeaders(resp.Header),
+		}
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			exErr.Kind = ErrorKindRetryable
+		}
+		return nil, exErr
+	}
+	return resp.Body, nil
+}
+
+// info 查询信息接口（只读，临时性错误自动重试）
+func (c *HyperliquidClient) info(group string, body map[string]interface{}, out interface{}) error {
+	var data []byte
+	err := c.retry.do("info "+fmt.Sprint(body["type"]), func() error {
+		var err error
+		data, err = c.post("/info", group, body)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, truncate(string(data), 512))
+	}
+	return nil
+}
+
+// nextNonce 生成签名用的 nonce（毫秒时间戳，保证严格递增）
+func (c *HyperliquidClient) nextNonce() int64 {
+	c.nonceMu.Lock()
+	defer c.nonceMu.Unlock()
+	nonce := time.Now().UnixMilli()
+	if nonce <= c.lastNonce {
+		nonce = c.lastNonce + 1
+	}
+	c.lastNonce = nonce
+	return nonce
+}
+
+// hyperliquidResponse 交易接口响应（成功时 response 为对象，失败时为错误信息字符串）
+type hyperliquidResponse struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// exchange 签名并提交交易操作（不自动重试，由调用方按幂等方式处理），返回各子操作的状态
+func (c *HyperliquidClient) exchange(action hlMap) ([]json.RawMessage, error) {
+	nonce := c.nextNonce()
+	signature, err := c.wallet.signL1Action(action, nonce, "", c.mainnet)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+
+	body := hlMap{
+		{"action", action},
+		{"nonce", nonce},
+		{"signature", signature},
+		{"vaultAddress", nil},
+	}
+	logger.Debugf("[DEBUG] Hyperliquid请求: %s", mustJSON(action))
+
+	data, err := c.post("/exchange", "trade", body)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("[DEBUG] Hyperliquid响应: %s", string(data))
+
+	var resp hyperliquidResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, truncate(string(data), 512))
+	}
+	if resp.Status != "ok" {
+		var message string
+		if json.Unmarshal(resp.Response, &message) != nil {
+			message = string(resp.Response)
+		}
+		return nil, &ExchangeError{Exchange: "Hyperliquid", Message: message, Kind: classifyHyperliquidError(message)}
+	}
+
+	var result struct {
+		Data struct {
+			Statuses []json.RawMessage `json:"statuses"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Response, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, truncate(string(data), 512))
+	}
+	return result.Data.Statuses, nil
+}
+
+// hyperliquidStatusError 解析子操作状态中的错误（{"error": "..."}），成功时返回nil
+func hyperliquidStatusError(status json.RawMessage) error {
+	var e struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(status, &e) == nil && e.Error != "" {
+		return &ExchangeError{Exchange: "Hyperliquid", Message: e.Error, Kind: classifyHyperliquidError(e.Error)}
+	}
+	return nil
+}
+
+// mustJSON 序列化为JSON字符串（用于日志）
+func mustJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// truncate 截断过长的字符串（用于日志和错误信息）
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
+
+// loadAssets 加载永续合约资产列表
+func (c *HyperliquidClient) loadAssets() error {
+	var meta struct {
+		Universe []struct {
+			Name        string  `json:"name"`
+			SzDecimals  int     `json:"szDecimals"`
+			MaxLeverage float64 `json:"maxLeverage"`
+			IsDelisted  bool    `json:"isDelisted"`
+		} `json:"universe"`
+	}
+	if err := c.info("public", map[string]interface{}{"type": "meta"}, &meta); err != nil {
+		return err
+	}
+
+	assets := make(map[string]*hyperliquidAsset, len(meta.Universe))
+	for i, u := range meta.Universe {
+		assets[u.Name] = &hyperliquidAsset{
+			index:       i,
+			name:        u.Name,
+			szDecimals:  u.SzDecimals,
+			maxLeverage: u.MaxLeverage,
+			delisted:    u.IsDelisted,
+		}
+	}
+
+	c.metaMu.Lock()
+	c.assets = assets
+	c.metaMu.Unlock()
+	return nil
+}
+
+// asset 获取币种的资产信息（未缓存时重新加载资产列表）
+func (c *HyperliquidClient) asset(symbol string) (*hyperliquidAsset, error) {
+	coin := c.convertSymbol(symbol)
+
+	c.metaMu.Lock()
+	a, ok := c.assets[coin]
+	c.metaMu.Unlock()
+	if ok {
+		return a, nil
+	}
+
+	if err := c.loadAssets(); err != nil {
+		return nil, err
+	}
+	c.metaMu.Lock()
+	a, ok = c.assets[coin]
+	c.metaMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("Hyperliquid 未找到永续合约: %s", coin)
+	}
+	return a, nil
+}
+
+func (a *hyperliquidAsset) toInstrument() InstrumentInfo {
+	lot := math.Pow(10, -float64(a.szDecimals))
+	info := InstrumentInfo{
+		InstID:        a.name,
+		ContractValue: 1,
+		LotSize:       lot,
+		MinSize:       lot,
+		MinAmount:     hyperliquidMinNotional,
+		TickSize:      math.Pow(10, -float64(6-a.szDecimals)),
+		BaseCurrency:  a.name,
+		QuoteCurrency: "USDC",
+		MaxLeverage:   a.maxLeverage,
+		State:         "live",
+	}
+	if a.delisted {
+		info.State = "delisting"
+	}
+	return info
+}
+
+// GetInstrumentInfo 获取合约信息（价格最多5位有效数字，TickSize 为允许的最小小数位）
+func (c *HyperliquidClient) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return nil, err
+	}
+	info := a.toInstrument()
+	return &info, nil
+}
+
+// ListInstruments 获取可交易的永续合约列表（instType 仅支持 swap）
+func (c *HyperliquidClient) ListInstruments(instType string) ([]InstrumentInfo, error) {
+	if instType != "" && !strings.EqualFold(instType, "swap") {
+		return nil, fmt.Errorf("Hyperliquid 不支持的产品类型: %s (支持: swap)", instType)
+	}
+	if err := c.loadAssets(); err != nil {
+		return nil, err
+	}
+
+	c.metaMu.Lock()
+	defer c.metaMu.Unlock()
+	instruments := make([]InstrumentInfo, 0, len(c.assets))
+	for _, a := range c.assets {
+		if !a.delisted {
+			instruments = append(instruments, a.toInstrument())
+		}
+	}
+	sort.Slice(instruments, func(i, j int) bool { return instruments[i].InstID < instruments[j].InstID })
+	return instruments, nil
+}
+
+// FetchOHLCV 获取K线数据
+func (c *HyperliquidClient) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	interval := strings.ToLower(timeframe)
+	step, ok := hyperliquidIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("Hyperliquid 不支持的K线周期: %s", timeframe)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 5000 {
+		limit = 5000 // Hyperliquid 最多返回最近5000根
+	}
+
+	end := time.Now()
+	start := end.Add(-time.Duration(limit) * step)
+	var candles []struct {
+		T int64  `json:"t"` // 开盘时间（毫秒）
+		O string `json:"o"`
+		H string `json:"h"`
+		L string `json:"l"`
+		C string `json:"c"`
+		V string `json:"v"` // 成交量（基础币种）
+	}
+	err := c.info("public", map[string]interface{}{
+		"type": "candleSnapshot",
+		"req": map[string]interface{}{
+			"coin":      c.convertSymbol(symbol),
+			"interval":  interval,
+			"startTime": start.UnixMilli(),
+			"endTime":   end.UnixMilli(),
+		},
+	}, &candles)
+	if err != nil {
+		return nil, err
+	}
+
+	ohlcvList := make([]models.OHLCV, 0, len(candles))
+	for _, k := range candles {
+		ohlcvList = append(ohlcvList, models.OHLCV{
+			Timestamp: time.UnixMilli(k.T),
+			Open:      hlFloat(k.O),
+			High:      hlFloat(k.H),
+			Low:       hlFloat(k.L),
+			Close:     hlFloat(k.C),
+			Volume:    hlFloat(k.V),
+		})
+	}
+	sort.Slice(ohlcvList, func(i, j int) bool { return ohlcvList[i].Timestamp.Before(ohlcvList[j].Timestamp) })
+	if len(ohlcvList) > limit {
+		ohlcvList = ohlcvList[len(ohlcvList)-limit:]
+	}
+	return ohlcvList, nil
+}
+
+// FetchTicker 获取最新行情（最新价取盘口中间价，含标记价格和预言机价格）
+func (c *HyperliquidClient) FetchTicker(symbol string) (*models.Ticker, error) {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []json.RawMessage // [meta, assetCtxs]
+	if err := c.info("public", map[string]interface{}{"type": "metaAndAssetCtxs"}, &response); err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, fmt.Errorf("未获取到行情数据: %s", a.name)
+	}
+	var ctxs []struct {
+		MarkPx   string `json:"markPx"`
+		MidPx    string `json:"midPx"`
+		OraclePx string `json:"oraclePx"`
+	}
+	if err := json.Unmarshal(response[1], &ctxs); err != nil {
+		return nil, err
+	}
+	if a.index >= len(ctxs) {
+		return nil, fmt.Errorf("未获取到行情数据: %s", a.name)
+	}
+	ctx := ctxs[a.index]
+
+	ticker := &models.Ticker{
+		Symbol: symbol,
+		Last:   hlFloat(ctx.MidPx),
+		Mark:   hlFloat(ctx.MarkPx),
+		Index:  hlFloat(ctx.OraclePx),
+	}
+	if ticker.Last <= 0 {
+		ticker.Last = ticker.Mark
+	}
+	if book, err := c.FetchOrderBook(symbol, 1); err == nil {
+		if len(book.Bids) > 0 {
+			ticker.Bid = book.Bids[0].Price
+		}
+		if len(book.Asks) > 0 {
+			ticker.Ask = book.Asks[0].Price
+		}
+	}
+	return ticker, nil
+}
+
+// FetchDerivativesStats 获取永续合约的资金费率（每小时结算）、持仓量和标记/预言机价格
+func (c *HyperliquidClient) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []json.RawMessage // [meta, assetCtxs]
+	if err := c.info("public", map[string]interface{}{"type": "metaAndAssetCtxs"}, &response); err != nil {
+		return nil, err
+	}
+	if len(response) < 2 {
+		return nil, fmt.Errorf("未获取到合约数据: %s", a.name)
+	}
+	var ctxs []struct {
+		Funding      string `json:"funding"`
+		OpenInterest string `json:"openInterest"`
+		MarkPx       string `json:"markPx"`
+		OraclePx     string `json:"oraclePx"`
+	}
+	if err := json.Unmarshal(response[1], &ctxs); err != nil {
+		return nil, err
+	}
+	if a.index >= len(ctxs) {
+		return nil, fmt.Errorf("未获取到合约数据: %s", a.name)
+	}
+	ctx := ctxs[a.index]
+
+	return &models.DerivativesStats{
+		FundingRate:          hlFloat(ctx.Funding),
+		FundingIntervalHours: 1,
+		NextFundingTime:      time.Now().Truncate(time.Hour).Add(time.Hour),
+		OpenInterest:         hlFloat(ctx.OpenInterest),
+		MarkPrice:            hlFloat(ctx.MarkPx),
+		IndexPrice:           hlFloat(ctx.OraclePx),
+	}, nil
+}
+
+// FetchOrderBook 获取盘口深度（每侧最多20档）
+func (c *HyperliquidClient) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	if depth <= 0 || depth > 20 {
+		depth = 20
+	}
+
+	var response struct {
+		Time   int64 `json:"time"`
+		Levels [][]struct {
+			Px string `json:"px"`
+			Sz string `json:"sz"`
+		} `json:"levels"` // [买盘, 卖盘]
+	}
+	err := c.info("public", map[string]interface{}{"type": "l2Book", "coin": c.convertSymbol(symbol)}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &models.OrderBook{Symbol: symbol, Timestamp: time.UnixMilli(response.Time)}
+	for side, levels := range response.Levels {
+		if side > 1 {
+			break
+		}
+		for i, level := range levels {
+			if i >= depth {
+				break
+			}
+			l := models.OrderBookLevel{Price: hlFloat(level.Px), Size: hlFloat(level.Sz)}
+			if side == 0 {
+				book.Bids = append(book.Bids, l)
+			} else {
+				book.Asks = append(book.Asks, l)
+			}
+		}
+	}
+	return book, nil
+}
+
+// hyperliquidAccountState 账户状态
+type hyperliquidAccountState struct {
+	AssetPositions []struct {
+		Position struct {
+			Coin          string `json:"coin"`
+			Szi           string `json:"szi"` // 持仓数量，负数为空头
+			EntryPx       string `json:"entryPx"`
+			UnrealizedPnl string `json:"unrealizedPnl"`
+			LiquidationPx string `json:"liquidationPx"` // 无强平风险时为null
+			MarginUsed    string `json:"marginUsed"`
+			PositionValue string `json:"positionValue"` // 按标记价格计算的持仓价值
+			Leverage      struct {
+				Value float64 `json:"value"`
+			} `json:"leverage"`
+		} `json:"position"`
+	} `json:"assetPositions"`
+	MarginSummary struct {
+		AccountValue string `json:"accountValue"` // 账户权益（含未实现盈亏）
+	} `json:"marginSummary"`
+	CrossMaintenanceMarginUsed string `json:"crossMaintenanceMarginUsed"`
+	Withdrawable               string `json:"withdrawable"`
+}
+
+// accountState 查询账户状态（持仓和可用保证金）
+func (c *HyperliquidClient) accountState() (*hyperliquidAccountState, error) {
+	var state hyperliquidAccountState
+	err := c.info("account", map[string]interface{}{"type": "clearinghouseState", "user": c.account}, &state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FetchPosition 获取持仓
+func (c *HyperliquidClient) FetchPosition(symbol string) (*models.Position, error) {
+	state, err := c.accountState()
+	if err != nil {
+		return nil, err
+	}
+
+	coin := c.convertSymbol(symbol)
+	for _, ap := range state.AssetPositions {
+		pos := ap.Position
+		if pos.Coin != coin {
+			continue
+		}
+		size := hlFloat(pos.Szi)
+		if size == 0 {
+			return nil, nil
+		}
+		side := "long"
+		if size < 0 {
+			side, size = "short", -size
+		}
+
+		logger.Debugf("[DEBUG] FetchPosition - Side:%s, Size:%.8f, EntryPrice:%s, Upl:%s",
+			side, size, pos.EntryPx, pos.UnrealizedPnl)
+
+		return &models.Position{
+			Side:             side,
+			Size:             size,
+			EntryPrice:       hlFloat(pos.EntryPx),
+			UnrealizedPnL:    hlFloat(pos.UnrealizedPnl),
+			Leverage:         int(pos.Leverage.Value),
+			Symbol:           symbol,
+			LiquidationPrice: hlFloat(pos.LiquidationPx),
+			Margin:           hlFloat(pos.MarginUsed),
+			MarkPrice:        hlFloat(pos.PositionValue) / size,
+		}, nil
+	}
+	return nil, nil
+}
+
+// FetchPositions 获取交易对的全部持仓（Hyperliquid 为单向持仓，最多一条）
+func (c *HyperliquidClient) FetchPositions(symbol string) ([]models.Position, error) {
+	return positionList(c.FetchPosition(symbol))
+}
+
+// FetchBalance 获取可用保证金（永续合约账户以USDC结算，计价币种 USDC/USDT/USD 均返回可提取余额）
+func (c *HyperliquidClient) FetchBalance(currency string) (float64, error) {
+	switch strings.ToUpper(currency) {
+	case "USDC", "USDT", "USD":
+	default:
+		return 0, nil
+	}
+	state, err := c.accountState()
+	if err != nil {
+		return 0, err
+	}
+	return hlFloat(state.Withdrawable), nil
+}
+
+// FetchAccountSummary 获取永续合约账户权益和保证金（USDC）
+func (c *HyperliquidClient) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	state, err := c.accountState()
+	if err != nil {
+		return nil, err
+	}
+	return &models.AccountSummary{
+		Currency:          currency,
+		TotalEquity:       hlFloat(state.MarginSummary.AccountValue),
+		AvailableMargin:   hlFloat(state.Withdrawable),
+		MaintenanceMargin: hlFloat(state.CrossMaintenanceMarginUsed),
+	}, nil
+}
+
+// PlaceOrder 下市价单（以偏离中间价5%的IOC限价单实现），返回交易所订单ID；指定 limitPrice 时按该价格提交GTC限价单
+// posSide 忽略（单向持仓），reduceOnly 有效；附带 stopLossPrice/takeProfitPrice 时
+// 与开仓单在同一操作中提交（normalTpsl 分组，开仓成交后生效），止损止盈的 cloid 由其自定义ID生成，重启后仍可撤销
+func (c *HyperliquidClient) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return "", fmt.Errorf("获取合约信息失败: %w", err)
+	}
+
+	ticker, err := c.FetchTicker(symbol)
+	if err != nil {
+		return "", fmt.Errorf("获取价格失败: %w", err)
+	}
+	mid := ticker.Last
+	if mid <= 0 {
+		return "", fmt.Errorf("价格无效: %s", a.name)
+	}
+
+	isBuy := side == "buy"
+	reduceOnly, _ := params["reduceOnly"].(bool)
+	size := c.roundSize(amount, a.szDecimals)
+	if !reduceOnly && size*mid < hyperliquidMinNotional {
+		minSize := math.Ceil(hyperliquidMinNotional/mid*math.Pow(10, float64(a.szDecimals))) / math.Pow(10, float64(a.szDecimals))
+		logger.Printf("[WARNING] 订单金额%.2f不足最小要求%.0f USDC，按最小数量%.8f下单", size*mid, hyperliquidMinNotional, minSize)
+		size = minSize
+	}
+	if size <= 0 {
+		return "", &ExchangeError{Exchange: "Hyperliquid", Message: fmt.Sprintf("下单数量过小: %.8f", amount), Kind: ErrorKindInvalidOrder}
+	}
+
+	px := mid * (1 - hyperliquidSlippage)
+	if isBuy {
+		px = mid * (1 + hyperliquidSlippage)
+	}
+	tif := "Ioc"
+	if limit, _ := params[ParamLimitPrice].(float64); limit > 0 {
+		px, tif = limit, "Gtc"
+	}
+
+	clientOrderID, _ := params[ParamClientOrderID].(string)
+	if clientOrderID == "" {
+		clientOrderID = NewClientOrderID()
+	}
+	cloid := hyperliquidCloid(clientOrderID)
+
+	orders := []hlMap{c.orderWire(a, isBuy, hyperliquidPrice(px, a.szDecimals), size, reduceOnly,
+		hlMap{{"limit", hlMap{{"tif", tif}}}}, cloid)}
+	orders = append(orders, c.bracketWires(a, isBuy, size, params)...)
+	grouping := "na"
+	if len(orders) > 1 {
+		grouping = "normalTpsl"
+	}
+	action := hlMap{{"type", "order"}, {"orders", orders}, {"grouping", grouping}}
+
+	logger.Debugf("[DEBUG] 合约下单 - 币种:%s, 方向:%s, 数量:%.8f, 限价:%.6f, reduceOnly:%v",
+		a.name, side, size, px, reduceOnly)
+
+	var orderID string
+	attempt := 0
+	err = c.retry.do("下单", func() error {
+		attempt++
+		if attempt > 1 {
+			// 上次请求结果未知，先按 cloid 查询，避免重复下单
+			order, err := c.fetchOrderStatus(symbol, cloid)
+			if err != nil {
+				return err
+			}
+			if order != nil {
+				logger.Printf("[INFO] 订单 %s 已提交成功（oid: %s），不再重复下单", clientOrderID, order.OrderID)
+				orderID = order.OrderID
+				return nil
+			}
+		}
+
+		statuses, err := c.exchange(action)
+		if err != nil {
+			return err
+		}
+		if len(statuses) == 0 {
+			return fmt.Errorf("下单响应缺少订单状态")
+		}
+		if err := hyperliquidStatusError(statuses[0]); err != nil {
+			return err
+		}
+		for i, status := range statuses[1:] {
+			if err := hyperliquidStatusError(status); err != nil {
+				logger.Warnf("[Hyperliquid] 提交第%d条止损/止盈单失败: %v", i+1, err)
+			}
+		}
+
+		var placed struct {
+			Resting *struct {
+				Oid int64 `json:"oid"`
+			} `json:"resting"`
+			Filled *struct {
+				Oid int64 `json:"oid"`
+			} `json:"filled"`
+		}
+		if err := json.Unmarshal(statuses[0], &placed); err != nil {
+			return fmt.Errorf("解析下单状态失败: %w", err)
+		}
+		switch {
+		case placed.Filled != nil:
+			orderID = strconv.FormatInt(placed.Filled.Oid, 10)
+		case placed.Resting != nil:
+			orderID = strconv.FormatInt(placed.Resting.Oid, 10)
+		default:
+			return fmt.Errorf("下单响应缺少订单ID: %s", string(statuses[0]))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return orderID, nil
+}
+
+// orderWire 构建订单（字段顺序与签名一致）
+func (c *HyperliquidClient) orderWire(a *hyperliquidAsset, isBuy bool, px, size float64, reduceOnly bool, orderType hlMap, cloid string) hlMap {
+	order := hlMap{
+		{"a", a.index},
+		{"b", isBuy},
+		{"p", hyperliquidWire(px)},
+		{"s", hyperliquidWire(size)},
+		{"r", reduceOnly},
+		{"t", orderType},
+	}
+	if cloid != "" {
+		order = append(order, hlField{"c", cloid})
+	}
+	return order
+}
+
+// bracketWires 构建开仓附带的止损/止盈触发单（只减仓，触发后按市价成交）
+func (c *HyperliquidClient) bracketWires(a *hyperliquidAsset, isBuy bool, size float64, params map[string]interface{}) []hlMap {
+	legs := []struct {
+		priceKey string
+		idKey    string
+		tpsl     string
+	}{
+		{ParamStopLossPrice, ParamStopLossClientID, "sl"},
+		{ParamTakeProfitPrice, ParamTakeProfitClientID, "tp"},
+	}
+
+	var wires []hlMap
+	for _, leg := range legs {
+		triggerPx, _ := params[leg.priceKey].(float64)
+		if triggerPx <= 0 {
+			continue
+		}
+		// 平仓方向与开仓相反，限价按触发价留出滑点
+		px := triggerPx * (1 + hyperliquidSlippage)
+		if isBuy {
+			px = triggerPx * (1 - hyperliquidSlippage)
+		}
+		cloid := ""
+		if clientID, _ := params[leg.idKey].(string); clientID != "" {
+			cloid = hyperliquidCloid(clientID)
+		}
+		orderType := hlMap{{"trigger", hlMap{
+			{"isMarket", true},
+			{"triggerPx", hyperliquidWire(hyperliquidPrice(triggerPx, a.szDecimals))},
+			{"tpsl", leg.tpsl},
+		}}}
+		wires = append(wires, c.orderWire(a, !isBuy, hyperliquidPrice(px, a.szDecimals), size, true, orderType, cloid))
+	}
+	return wires
+}
+
+// PlaceOrders 批量下单（逐笔提交），返回与请求一一对应的结果
+func (c *HyperliquidClient) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	results := make([]OrderResult, len(requests))
+	for i, req := range requests {
+		params := req.Params
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		clientOrderID, _ := params[ParamClientOrderID].(string)
+		if clientOrderID == "" {
+			clientOrderID = NewClientOrderID()
+			params[ParamClientOrderID] = clientOrderID
+		}
+		orderID, err := c.PlaceOrder(req.Symbol, req.Side, req.Amount, params)
+		results[i] = OrderResult{ClientOrderID: clientOrderID, OrderID: orderID, Err: err}
+	}
+	return results, nil
+}
+
+// hyperliquidOrder Hyperliquid订单
+type hyperliquidOrder struct {
+	Coin       string  `json:"coin"`
+	Side       string  `json:"side"` // B: 买入, A: 卖出
+	LimitPx    string  `json:"limitPx"`
+	Sz         string  `json:"sz"` // 剩余数量
+	OrigSz     string  `json:"origSz"`
+	Oid        int64   `json:"oid"`
+	Timestamp  int64   `json:"timestamp"`
+	OrderType  string  `json:"orderType"`
+	ReduceOnly bool    `json:"reduceOnly"`
+	Cloid      *string `json:"cloid"`
+}
+
+// toOrder 转换为通用订单结构（ClientOrderID 为 cloid）
+func (o *hyperliquidOrder) toOrder(symbol, status string, updatedAt int64) models.Order {
+	side := "buy"
+	if o.Side == "A" {
+		side = "sell"
+	}
+	origSz := hlFloat(o.OrigSz)
+	order := models.Order{
+		OrderID:    strconv.FormatInt(o.Oid, 10),
+		Symbol:     symbol,
+		Side:       side,
+		Type:       strings.ToLower(o.OrderType),
+		Price:      hlFloat(o.LimitPx),
+		Size:       origSz,
+		FilledSize: origSz - hlFloat(o.Sz),
+		ReduceOnly: o.ReduceOnly,
+		CreatedAt:  time.UnixMilli(o.Timestamp),
+		UpdatedAt:  time.UnixMilli(o.Timestamp),
+	}
+	if o.Cloid != nil {
+		order.ClientOrderID = *o.Cloid
+	}
+	if updatedAt > 0 {
+		order.UpdatedAt = time.UnixMilli(updatedAt)
+	}
+
+	switch status {
+	case "filled":
+		order.State = models.OrderStateFilled
+		order.FilledSize = origSz
+	case "open", "triggered", "":
+		order.State = models.OrderStateLive
+		if order.FilledSize > 0 {
+			order.State = models.OrderStatePartiallyFilled
+		}
+	default: // canceled, rejected, marginCanceled 等
+		order.State = models.OrderStateCanceled
+	}
+	return order
+}
+
+// fetchOrderStatus 按订单ID或 cloid 查询订单，订单不存在时返回 nil, nil
+func (c *HyperliquidClient) fetchOrderStatus(symbol string, oid interface{}) (*models.Order, error) {
+	var response struct {
+		Status string `json:"status"` // order / unknownOid
+		Order  struct {
+			Order           hyperliquidOrder `json:"order"`
+			Status          string           `json:"status"`
+			StatusTimestamp int64            `json:"statusTimestamp"`
+		} `json:"order"`
+	}
+	err := c.info("account", map[string]interface{}{"type": "orderStatus", "user": c.account, "oid": oid}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Status != "order" {
+		return nil, nil
+	}
+	order := response.Order.Order.toOrder(symbol, response.Order.Status, response.Order.StatusTimestamp)
+	return &order, nil
+}
+
+// FetchOrder 查询订单（有成交时按成交记录汇总成交均价和手续费）
+func (c *HyperliquidClient) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	oid, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("订单ID格式错误: %s", orderID)
+	}
+	order, err := c.fetchOrderStatus(symbol, oid)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, &ExchangeError{Exchange: "Hyperliquid", Message: "订单不存在: " + orderID, Kind: ErrorKindInvalidOrder}
+	}
+
+	if order.FilledSize > 0 {
+		trades, err := c.FetchMyTrades(symbol, order.CreatedAt.Add(-time.Second))
+		if err != nil {
+			logger.Warnf("[Hyperliquid] 获取订单 %s 成交记录失败: %v", orderID, err)
+			return order, nil
+		}
+		var filled, notional, fee float64
+		for _, t := range trades {
+			if t.OrderID == orderID {
+				filled += t.Size
+				notional += t.Size * t.Price
+				fee += t.Fee
+			}
+		}
+		if filled > 0 {
+			order.AvgPrice = notional / filled
+			order.Fee = fee
+			order.FeeCurrency = "USDC"
+		}
+	}
+	return order, nil
+}
+
+// CancelOrder 撤销订单
+func (c *HyperliquidClient) CancelOrder(symbol, orderID string) error {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return err
+	}
+	oid, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("订单ID格式错误: %s", orderID)
+	}
+
+	action := hlMap{{"type", "cancel"}, {"cancels", []hlMap{{{"a", a.index}, {"o", oid}}}}}
+	statuses, err := c.exchange(action)
+	if err == nil && len(statuses) > 0 {
+		err = hyperliquidStatusError(statuses[0])
+	}
+	if err != nil {
+		return fmt.Errorf("撤单失败: %w", err)
+	}
+	return nil
+}
+
+// CancelAlgoOrder 按自定义ID撤销止损/止盈触发单（已触发、已撤销或不存在时返回nil）
+func (c *HyperliquidClient) CancelAlgoOrder(symbol, clientID string) error {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return err
+	}
+
+	action := hlMap{{"type", "cancelByCloid"}, {"cancels", []hlMap{{{"asset", a.index}, {"cloid", hyperliquidCloid(clientID)}}}}}
+	statuses, err := c.exchange(action)
+	if err == nil && len(statuses) > 0 {
+		err = hyperliquidStatusError(statuses[0])
+	}
+	if err != nil && !IsKind(err, ErrorKindInvalidOrder) {
+		return err
+	}
+	return nil
+}
+
+// FetchOpenOrders 获取未成交订单（含未触发的止损/止盈单）
+func (c *HyperliquidClient) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	var response []hyperliquidOrder
+	if err := c.info("account", map[string]interface{}{"type": "frontendOpenOrders", "user": c.account}, &response); err != nil {
+		return nil, err
+	}
+
+	coin := c.convertSymbol(symbol)
+	var orders []models.Order
+	for i := range response {
+		if response[i].Coin == coin {
+			orders = append(orders, response[i].toOrder(symbol, "open", 0))
+		}
+	}
+	return orders, nil
+}
+
+// FetchMyTrades 获取账户成交记录（按时间分页），按时间升序返回
+func (c *HyperliquidClient) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	coin := c.convertSymbol(symbol)
+	start := since.UnixMilli()
+	if since.IsZero() {
+		start = 0
+	}
+
+	var trades []models.Trade
+	for {
+		var fills []struct {
+			Coin      string `json:"coin"`
+			Px        string `json:"px"`
+			Sz        string `json:"sz"`
+			Side      string `json:"side"`
+			Time      int64  `json:"time"`
+			Dir       string `json:"dir"` // Open Long / Close Short 等
+			ClosedPnl string `json:"closedPnl"`
+			Oid       int64  `json:"oid"`
+			Tid       int64  `json:"tid"`
+			Crossed   bool   `json:"crossed"` // 是否为吃单
+			Fee       string `json:"fee"`     // 正数表示支出
+			FeeToken  string `json:"feeToken"`
+		}
+		err := c.info("account", map[string]interface{}{
+			"type":      "userFillsByTime",
+			"user":      c.account,
+			"startTime": start,
+		}, &fills)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range fills {
+			if f.Time >= start {
+				start = f.Time + 1
+			}
+			if f.Coin != coin {
+				continue
+			}
+			side := "buy"
+			if f.Side == "A" {
+				side = "sell"
+			}
+			posSide := ""
+			switch {
+			case strings.HasSuffix(f.Dir, "Long"):
+				posSide = "long"
+			case strings.HasSuffix(f.Dir, "Short"):
+				posSide = "short"
+			}
+			trades = append(trades, models.Trade{
+				TradeID:     strconv.FormatInt(f.Tid, 10),
+				OrderID:     strconv.FormatInt(f.Oid, 10),
+				Symbol:      symbol,
+				Side:        side,
+				PosSide:     posSide,
+				Price:       hlFloat(f.Px),
+				Size:        hlFloat(f.Sz),
+				Fee:         -hlFloat(f.Fee),
+				FeeCurrency: f.FeeToken,
+				RealizedPnL: hlFloat(f.ClosedPnl),
+				IsMaker:     !f.Crossed,
+				Timestamp:   time.UnixMilli(f.Time),
+			})
+		}
+		if len(fills) < hyperliquidMaxFills {
+			break
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].Timestamp.Before(trades[j].Timestamp) })
+	return trades, nil
+}
+
+// FetchFundingFees 获取资金费用记录（按 startTime 分页，每页最多500条）
+func (c *HyperliquidClient) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	coin := c.convertSymbol(symbol)
+	start := since.UnixMilli()
+	if since.IsZero() {
+		start = 0
+	}
+
+	var fees []models.FundingFee
+	for {
+		var updates []struct {
+			Time  int64 `json:"time"`
+			Delta struct {
+				Coin string `json:"coin"`
+				USDC string `json:"usdc"` // 正数为收入，负数为支出
+			} `json:"delta"`
+		}
+		err := c.info("account", map[string]interface{}{
+			"type":      "userFunding",
+			"user":      c.account,
+			"startTime": start,
+		}, &updates)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range updates {
+			if u.Time >= start {
+				start = u.Time + 1
+			}
+			if u.Delta.Coin != coin {
+				continue
+			}
+			fees = append(fees, models.FundingFee{
+				Symbol:    symbol,
+				Amount:    hlFloat(u.Delta.USDC),
+				Currency:  "USDC",
+				Timestamp: time.UnixMilli(u.Time),
+			})
+		}
+		if len(updates) < hyperliquidMaxLedgerUpdates {
+			break
+		}
+	}
+
+	sort.Slice(fees, func(i, j int) bool { return fees[i].Timestamp.Before(fees[j].Timestamp) })
+	return fees, nil
+}
+
+// hyperliquidMaxLedgerUpdates 资金费用和资金流水接口单次返回的最大条数
+const hyperliquidMaxLedgerUpdates = 500
+
+// FetchTransfers 获取充值、提现和账户间划转记录（永续账户的 USDC 流水）
+func (c *HyperliquidClient) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	start := since.UnixMilli()
+	if since.IsZero() {
+		start = 0
+	}
+
+	var transfers []models.AccountTransfer
+	for {
+		var updates []struct {
+			Time  int64  `json:"time"`
+			Hash  string `json:"hash"`
+			Delta struct {
+				Type        string `json:"type"`
+				USDC        string `json:"usdc"`
+				Fee         string `json:"fee"`
+				ToPerp      bool   `json:"toPerp"`      // accountClassTransfer: 是否转入永续账户
+				Destination string `json:"destination"` // internalTransfer/subAccountTransfer: 转入地址
+			} `json:"delta"`
+		}
+		err := c.info("account", map[string]interface{}{
+			"type":      "userNonFundingLedgerUpdates",
+			"user":      c.account,
+			"startTime": start,
+		}, &updates)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, u := range updates {
+			if u.Time >= start {
+				start = u.Time + 1
+			}
+			amount := hlFloat(u.Delta.USDC)
+			transfer := models.AccountTransfer{ID: u.Hash, Currency: "USDC", Timestamp: time.UnixMilli(u.Time)}
+			switch u.Delta.Type {
+			case "deposit":
+				transfer.Type, transfer.Amount = models.TransferDeposit, amount
+			case "withdraw":
+				transfer.Type, transfer.Amount, transfer.Fee = models.TransferWithdrawal, -amount, -hlFloat(u.Delta.Fee)
+			case "accountClassTransfer":
+				transfer.Type, transfer.Amount = models.TransferInternal, amount
+				if !u.Delta.ToPerp {
+					transfer.Amount = -amount
+				}
+			case "internalTransfer", "subAccountTransfer":
+				transfer.Type, transfer.Amount = models.TransferInternal, amount
+				if !strings.EqualFold(u.Delta.Destination, c.account) {
+					transfer.Amount = -amount
+				}
+			default:
+				continue // 清算、金库等其他流水不计入
+			}
+			transfers = append(transfers, transfer)
+		}
+		if len(updates) < hyperliquidMaxLedgerUpdates {
+			break
+		}
+	}
+
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].Timestamp.Before(transfers[j].Timestamp) })
+	return transfers, nil
+}
+
+// FetchTradingFees 获取账户手续费率（正数表示支出）
+func (c *HyperliquidClient) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	var fees struct {
+		UserCrossRate string `json:"userCrossRate"` // 吃单费率
+		UserAddRate   string `json:"userAddRate"`   // 挂单费率
+	}
+	if err := c.info("account", map[string]interface{}{"type": "userFees", "user": c.account}, &fees); err != nil {
+		return nil, err
+	}
+	return &models.FeeRate{
+		Symbol: symbol,
+		Maker:  hlFloat(fees.UserAddRate),
+		Taker:  hlFloat(fees.UserCrossRate),
+	}, nil
+}
+
+// Transfer 资金划转（永续与现货账户之间的划转需主钱包签名，API 钱包无权限，暂不支持）
+func (c *HyperliquidClient) Transfer(currency string, amount float64, from, to string) error {
+	return fmt.Errorf("Hyperliquid 暂不支持资金划转")
+}
+
+// SetLeverage 设置全仓杠杆
+func (c *HyperliquidClient) SetLeverage(symbol string, leverage int) error {
+	a, err := c.asset(symbol)
+	if err != nil {
+		return err
+	}
+	if a.maxLeverage > 0 && float64(leverage) > a.maxLeverage {
+		logger.Printf("[WARNING] 杠杆%dx超过%s最大杠杆%.0fx，按最大杠杆设置", leverage, a.name, a.maxLeverage)
+		leverage = int(a.maxLeverage)
+	}
+
+	action := hlMap{{"type", "updateLeverage"}, {"asset", a.index}, {"isCross", true}, {"leverage", leverage}}
+	_, err = c.exchange(action)
+	return err
+}
+
+// roundSize 按数量精度向下取整
+func (c *HyperliquidClient) roundSize(size float64, szDecimals int) float64 {
+	scale := math.Pow(10, float64(szDecimals))
+	return math.Floor(size*scale+1e-9) / scale
+}
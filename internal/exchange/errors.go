@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ErrorKind 交易所错误分类
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown 未分类错误
+	ErrorKindUnknown ErrorKind = iota
+	// ErrorKindRetryable 临时性错误（网络超时、5xx、限流等），可安全重试
+	ErrorKindRetryable
+	// ErrorKindInsufficientBalance 余额/保证金不足
+	ErrorKindInsufficientBalance
+	// ErrorKindInvalidOrder 订单参数无效（数量、价格、交易对等）
+	ErrorKindInvalidOrder
+	// ErrorKindAuthFailed 认证失败（API Key、签名、权限等）
+	ErrorKindAuthFailed
+)
+
+// String 返回错误分类的字符串表示
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindRetryable:
+		return "Retryable"
+	case ErrorKindInsufficientBalance:
+		return "InsufficientBalance"
+	case ErrorKindInvalidOrder:
+		return "InvalidOrder"
+	case ErrorKindAuthFailed:
+		return "AuthFailed"
+	default:
+		return "Unknown"
+	}
+}
+
+// ExchangeError 统一的交易所错误
+type ExchangeError struct {
+	Exchange   string            // 交易所名称
+	HTTPStatus int               // HTTP状态码（0表示未收到响应）
+	Code       string            // 交易所API错误码
+	Message    string            // 错误信息
+	Kind       ErrorKind         // 错误分类
+	Headers    map[string]string // 相关响应头（如限流头 Retry-After、X-RateLimit-*）
+	Err        error             // 底层错误（如网络错误）
+}
+
+// Error 实现 error 接口
+func (e *ExchangeError) Error() string {
+	msg := fmt.Sprintf("%s API错误", e.Exchange)
+	if e.HTTPStatus != 0 {
+		msg += fmt.Sprintf(" HTTP %d", e.HTTPStatus)
+	}
+	if e.Code != "" {
+		msg += fmt.Sprintf(" [%s]", e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if len(e.Headers) > 0 {
+		msg += fmt.Sprintf(" (响应头: %v)", e.Headers)
+	}
+	return msg
+}
+
+// Unwrap 返回底层错误
+func (e *ExchangeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorKindOf 获取错误分类（非交易所错误时，网络超时视为可重试，其余为未分类）
+func ErrorKindOf(err error) ErrorKind {
+	var exErr *ExchangeError
+	if errors.As(err, &exErr) {
+		return exErr.Kind
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorKindRetryable
+	}
+	return ErrorKindUnknown
+}
+
+// IsKind 判断错误是否属于指定分类
+func IsKind(err error, kind ErrorKind) bool {
+	return err != nil && ErrorKindOf(err) == kind
+}
+
+// IsRetryable 判断错误是否为可重试的临时性错误
+func IsRetryable(err error) bool {
+	return IsKind(err, ErrorKindRetryable)
+}
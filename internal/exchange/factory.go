@@ -10,20 +10,66 @@ import (
 func NewExchange(cfg *config.APIConfig, tradingMode config.TradingMode) (Exchange, error) {
 	exchangeType := cfg.ExchangeType
 
+	var client Exchange
 	switch exchangeType {
 	case string(config.ExchangeOKX):
-		client := NewOKXClient(cfg, tradingMode)
-		if client == nil {
+		okx := NewOKXClient(cfg, tradingMode)
+		if okx == nil {
 			return nil, fmt.Errorf("创建OKX客户端失败")
 		}
-		return client, nil
+		client = okx
+
+	case string(config.ExchangeKraken):
+		kraken, err := NewKrakenClient(cfg, tradingMode)
+		if err != nil {
+			return nil, fmt.Errorf("创建Kraken客户端失败: %w", err)
+		}
+		client = kraken
+
+	case string(config.ExchangeGate):
+		gate, err := NewGateClient(cfg, tradingMode)
+		if err != nil {
+			return nil, fmt.Errorf("创建Gate.io客户端失败: %w", err)
+		}
+		client = gate
+
+	case string(config.ExchangeHyperliquid):
+		hyperliquid, err := NewHyperliquidClient(cfg, tradingMode)
+		if err != nil {
+			return nil, fmt.Errorf("创建Hyperliquid客户端失败: %w", err)
+		}
+		client = hyperliquid
 
 	default:
-		return nil, fmt.Errorf("不支持的交易所类型: %s (支持: okx, binance)", exchangeType)
+		return nil, fmt.Errorf("不支持的交易所类型: %s (支持: okx, binance, kraken, gate, hyperliquid)", exchangeType)
+	}
+
+	// 启用备用行情源时包装行情接口
+	if cfg.MarketDataFallback.Enable {
+		source, err := NewMarketDataSource(cfg.MarketDataFallback.Source, tradingMode)
+		if err != nil {
+			return nil, fmt.Errorf("创建备用行情源失败: %w", err)
+		}
+		client = NewFallbackExchange(client, source)
+	}
+
+	// 启用K线缓存时包装在最外层（数据来源切换时缓存全量重新加载）
+	if cfg.OHLCVCache.Enable {
+		client = NewCachedExchange(client)
+	}
+
+	return client, nil
+}
+
+// BinanceBaseURL 获取Binance公共行情接口地址（现货和U本位合约）
+func BinanceBaseURL(tradingMode config.TradingMode) string {
+	if tradingMode == config.TradingModeFutures {
+		return "https://fapi.binance.com"
 	}
+	return "https://api.binance.com"
 }
 
 // GetSupportedExchanges 获取支持的交易所列表
 func GetSupportedExchanges() []string {
-	return []string{"okx", "binance"}
+	return []string{"okx", "binance", "kraken", "gate", "hyperliquid"}
 }
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"errors"
+
+	"dsbot/internal/logger"
+)
+
+// ErrTradingDisabled 下单通道已关闭（测试模式 dry-run），订单未提交到交易所
+var ErrTradingDisabled = errors.New("测试模式禁止真实下单")
+
+// ExecutionGateway 下单通道 - 所有会改变交易所状态的操作（下单、撤单、设置杠杆、资金划转）都经过此处，
+// 关闭时直接拒绝并返回 ErrTradingDisabled，行情和账户查询不受影响
+// 策略、风控及后续新增的下单组件只持有该通道，保证测试模式下不会有真实订单漏出
+type ExecutionGateway struct {
+	Exchange
+	enabled bool
+}
+
+// NewExecutionGateway 创建下单通道
+func NewExecutionGateway(exch Exchange, enabled bool) *ExecutionGateway {
+	return &ExecutionGateway{Exchange: exch, enabled: enabled}
+}
+
+// TradingEnabled 是否允许下单
+func (g *ExecutionGateway) TradingEnabled() bool {
+	return g.enabled
+}
+
+// blocked 下单通道关闭时记录被拦截的操作
+func (g *ExecutionGateway) blocked(operation string) bool {
+	if g.enabled {
+		return false
+	}
+	logger.Debugf("[DEBUG] 下单通道已关闭，拦截%s", operation)
+	return true
+}
+
+// PlaceOrder 下单
+func (g *ExecutionGateway) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	if g.blocked("下单 " + symbol + " " + side) {
+		return "", ErrTradingDisabled
+	}
+	return g.Exchange.PlaceOrder(symbol, side, amount, params)
+}
+
+// PlaceOrders 批量下单
+func (g *ExecutionGateway) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	if g.blocked("批量下单") {
+		return nil, ErrTradingDisabled
+	}
+	return g.Exchange.PlaceOrders(requests)
+}
+
+// CancelOrder 撤单
+func (g *ExecutionGateway) CancelOrder(symbol, orderID string) error {
+	if g.blocked("撤单 " + orderID) {
+		return ErrTradingDisabled
+	}
+	return g.Exchange.CancelOrder(symbol, orderID)
+}
+
+// CancelAlgoOrder 撤销条件单
+func (g *ExecutionGateway) CancelAlgoOrder(symbol, clientID string) error {
+	if g.blocked("撤销条件单 " + clientID) {
+		return ErrTradingDisabled
+	}
+	return g.Exchange.CancelAlgoOrder(symbol, clientID)
+}
+
+// SetLeverage 设置杠杆
+func (g *ExecutionGateway) SetLeverage(symbol string, leverage int) error {
+	if g.blocked("设置杠杆") {
+		return ErrTradingDisabled
+	}
+	return g.Exchange.SetLeverage(symbol, leverage)
+}
+
+// Transfer 资金划转
+func (g *ExecutionGateway) Transfer(currency string, amount float64, from, to string) error {
+	if g.blocked("资金划转 " + currency) {
+		return ErrTradingDisabled
+	}
+	return g.Exchange.Transfer(currency, amount, from, to)
+}
+
+// OHLCVSource 透传K线数据来源
+func (g *ExecutionGateway) OHLCVSource(symbol string) string {
+	return OHLCVSourceOf(g.Exchange, symbol)
+}
+
+// PrimaryDataSource 透传主行情来源
+func (g *ExecutionGateway) PrimaryDataSource() string {
+	return PrimaryDataSourceOf(g.Exchange)
+}
@@ -1 +1,65 @@
 package exchange
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"dsbot/internal/models"
+)
+
+// NewClientOrderID 生成自定义订单ID（字母数字，不超过32位，满足OKX等交易所要求）
+func NewClientOrderID() string {
+	return fmt.Sprintf("ds%d%04d", time.Now().UnixMilli(), rand.Intn(10000))
+}
+
+// IntentClientOrderID 按下单意图生成确定性的自定义订单ID（同一意图总是得到相同ID）
+// intent 应唯一标识一次下单意图（如 交易对|操作|信号K线时间），重启或重复执行同一意图时
+// 交易所会拒绝重复的ID，调用方据此按ID查询已有订单，避免重复开仓
+func IntentClientOrderID(intent string) string {
+	sum := sha256.Sum256([]byte(intent))
+	return "ds" + hex.EncodeToString(sum[:])[:30]
+}
+
+// positionList 将单向持仓交易所的持仓转换为持仓列表（无持仓时为空）
+func positionList(pos *models.Position, err error) ([]models.Position, error) {
+	if err != nil || pos == nil {
+		return nil, err
+	}
+	return []models.Position{*pos}, nil
+}
+
+// RoundPrice 将价格取整到最小变动价位 tickSize 的整数倍（四舍五入，tickSize<=0 时原样返回）
+// 限价单、止损止盈触发价需符合交易所价格精度，否则会被拒绝
+func RoundPrice(price, tickSize float64) float64 {
+	if tickSize <= 0 || price <= 0 {
+		return price
+	}
+	return trimPrecision(math.Round(price/tickSize)*tickSize, tickSize)
+}
+
+// RoundSize 将数量向下取整到下单精度 lotSize 的整数倍（不超过原数量，lotSize<=0 时原样返回）
+func RoundSize(size, lotSize float64) float64 {
+	if lotSize <= 0 || size <= 0 {
+		return size
+	}
+	return trimPrecision(math.Floor(size/lotSize+1e-9)*lotSize, lotSize)
+}
+
+// trimPrecision 按步长的小数位数截断浮点误差（如 0.1*3 得到 0.30000000000000004）
+func trimPrecision(v, step float64) float64 {
+	decimals := 0
+	for decimals < 12 && math.Abs(step-math.Round(step)) > 1e-12 {
+		step *= 10
+		decimals++
+	}
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'f', decimals, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
+}
@@ -0,0 +1,249 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// Hyperliquid 钱包签名：L1 操作按 msgpack 序列化后与 nonce 拼接计算 keccak256 得到 connectionId，
+// 再以 EIP-712 结构 Agent{source, connectionId} 用 secp256k1 私钥签名（与以太坊钱包签名一致）
+
+// hlField 有序键值对（msgpack 哈希与字段顺序相关）
+type hlField struct {
+	Key   string
+	Value interface{}
+}
+
+// hlMap 保持字段顺序的对象
+type hlMap []hlField
+
+// MarshalJSON 按字段顺序序列化
+func (m hlMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// msgpackEncode 按 msgpack 规范序列化（仅支持签名所需的类型，整数按无符号最小长度编码）
+func msgpackEncode(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		return msgpackInt(buf, int64(val))
+	case int64:
+		return msgpackInt(buf, val)
+	case uint64:
+		msgpackUint(buf, val)
+	case string:
+		n := len(val)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n < 1<<8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n < 1<<16:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(val)
+	case hlMap:
+		msgpackHeader(buf, len(val), 0x80, 0xde, 0xdf)
+		for _, f := range val {
+			if err := msgpackEncode(buf, f.Key); err != nil {
+				return err
+			}
+			if err := msgpackEncode(buf, f.Value); err != nil {
+				return err
+			}
+		}
+	case []hlMap:
+		msgpackHeader(buf, len(val), 0x90, 0xdc, 0xdd)
+		for _, item := range val {
+			if err := msgpackEncode(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack 不支持的类型: %T", v)
+	}
+	return nil
+}
+
+// msgpackHeader 写入 map/array 长度头
+func msgpackHeader(buf *bytes.Buffer, n int, fix, code16, code32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n < 1<<16:
+		buf.WriteByte(code16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(code32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func msgpackInt(buf *bytes.Buffer, v int64) error {
+	if v < 0 {
+		return fmt.Errorf("msgpack 不支持负整数: %d", v)
+	}
+	msgpackUint(buf, uint64(v))
+	return nil
+}
+
+func msgpackUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 128:
+		buf.WriteByte(byte(v))
+	case v < 1<<8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(v))
+	case v < 1<<16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(v))
+	case v < 1<<32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(v))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+// keccak256 以太坊使用的 Keccak-256（填充为 0x01，与 SHA3-256 不同）
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// hlSignature 以太坊格式签名（r、s 为 0x 开头的十六进制，v 为 27/28）
+type hlSignature struct {
+	R string `json:"r"`
+	S string `json:"s"`
+	V int    `json:"v"`
+}
+
+// walletKey secp256k1 钱包私钥
+type walletKey struct {
+	priv    *secp256k1.PrivateKey
+	address string // 0x 开头的小写地址
+}
+
+// parseWalletKey 解析十六进制私钥（可带 0x 前缀）并计算钱包地址
+func parseWalletKey(hexKey string) (*walletKey, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("私钥格式错误（需为32字节十六进制）")
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(raw); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("私钥超出有效范围")
+	}
+	priv := secp256k1.NewPrivateKey(&scalar)
+
+	// 地址为未压缩公钥（去掉 0x04 前缀）keccak256 的后20字节
+	pub := priv.PubKey().SerializeUncompressed()
+	hash := keccak256(pub[1:])
+	return &walletKey{priv: priv, address: "0x" + hex.EncodeToString(hash[12:])}, nil
+}
+
+// sign 对32字节哈希签名（RFC 6979 确定性随机数，低 s 值，v = 27 + recovery id）
+func (w *walletKey) sign(hash []byte) hlSignature {
+	// 紧凑签名格式: [27 + recovery id][r 32字节][s 32字节]（未压缩公钥）
+	sig := ecdsa.SignCompact(w.priv, hash, false)
+	return hlSignature{
+		R: "0x" + hex.EncodeToString(sig[1:33]),
+		S: "0x" + hex.EncodeToString(sig[33:65]),
+		V: int(sig[0]),
+	}
+}
+
+// hlActionHash L1 操作的 connectionId: keccak256(msgpack(action) || nonce(8字节大端) || vault标记)
+func hlActionHash(action hlMap, nonce int64, vaultAddress string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := msgpackEncode(&buf, action); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.BigEndian, uint64(nonce))
+	if vaultAddress == "" {
+		buf.WriteByte(0x00)
+	} else {
+		addr, err := hex.DecodeString(strings.TrimPrefix(vaultAddress, "0x"))
+		if err != nil || len(addr) != 20 {
+			return nil, fmt.Errorf("vault 地址格式错误: %s", vaultAddress)
+		}
+		buf.WriteByte(0x01)
+		buf.Write(addr)
+	}
+	return keccak256(buf.Bytes()), nil
+}
+
+// hlAgentDigest EIP-712 待签名摘要（domain: Exchange/1/chainId 1337/零地址，primaryType: Agent）
+// source 主网为 "a"，测试网为 "b"
+func hlAgentDigest(connectionID []byte, mainnet bool) []byte {
+	source := "b"
+	if mainnet {
+		source = "a"
+	}
+
+	chainID := make([]byte, 32)
+	binary.BigEndian.PutUint64(chainID[24:], 1337)
+	domainType := keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	domainSeparator := keccak256(
+		domainType,
+		keccak256([]byte("Exchange")),
+		keccak256([]byte("1")),
+		chainID,
+		make([]byte, 32), // verifyingContract: 0x0000000000000000000000000000000000000000
+	)
+
+	agentType := keccak256([]byte("Agent(string source,bytes32 connectionId)"))
+	structHash := keccak256(agentType, keccak256([]byte(source)), connectionID)
+
+	return keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+// signL1Action 对 L1 操作签名
+func (w *walletKey) signL1Action(action hlMap, nonce int64, vaultAddress string, mainnet bool) (hlSignature, error) {
+	connectionID, err := hlActionHash(action, nonce, vaultAddress)
+	if err != nil {
+		return hlSignature{}, err
+	}
+	return w.sign(hlAgentDigest(connectionID, mainnet)), nil
+}
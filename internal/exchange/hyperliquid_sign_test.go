@@ -0,0 +1,86 @@
+package exchange
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// 期望值与 go-hyperliquid SDK（go-ethereum 签名）对同一私钥、操作和 nonce 的输出一致
+func TestHyperliquidSignL1Action(t *testing.T) {
+	w, err := parseWalletKey("0x0123456789012345678901234567890123456789012345678901234567890123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.address != "0x14791697260e4c9a71f18484c9f997b308e59325" {
+		t.Fatalf("地址 = %s", w.address)
+	}
+
+	tests := []struct {
+		name     string
+		action   hlMap
+		nonce    int64
+		vault    string
+		mainnet  bool
+		wantHash string
+		want     hlSignature
+	}{
+		{
+			name: "主网下单",
+			action: hlMap{{"type", "order"}, {"orders", []hlMap{{
+				{"a", 0}, {"b", true}, {"p", "1670.1"}, {"s", "0.0147"}, {"r", false},
+				{"t", hlMap{{"limit", hlMap{{"tif", "Ioc"}}}}},
+			}}}, {"grouping", "na"}},
+			nonce:    1677777606040,
+			mainnet:  true,
+			wantHash: "370b7c4eddc7cb68441900e7b820dcd0ccdfbb8d717f67ca4507d36daff3656b",
+			want: hlSignature{
+				R: "0x906bb6fd7ce8ff29975bf7df3ffd2b33f7f0ddd6074fbe10855df25368a6e509",
+				S: "0x3c11ccfb713c99c2690f4782da405a0310f82db2f039e8cf0a8b777094b0085e",
+				V: 28,
+			},
+		},
+		{
+			name:     "测试网 vault 撤单",
+			action:   hlMap{{"type", "cancel"}, {"cancels", []hlMap{{{"a", 3}, {"o", int64(361731063972)}}}}},
+			nonce:    1700000000000,
+			vault:    "0x1719884eb866cb12b2287399b15f7db5e7d775ea",
+			wantHash: "feee2ff0b6cde798207a11fff20ddd463605d84f2020866d66443de2fab1de53",
+			want: hlSignature{
+				R: "0xfdfd7b30551da46bbbf5cb4b94fae13f52d26b84ef36c8d61a004efdfa1e4a13",
+				S: "0x2f8182df44188ee9de4937cbd4f6a8b8f2d0228629a12b9a467518701d79815b",
+				V: 27,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := hlActionHash(tt.action, tt.nonce, tt.vault)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(hash); got != tt.wantHash {
+				t.Fatalf("connectionId = %s, 期望 %s", got, tt.wantHash)
+			}
+			sig, err := w.signL1Action(tt.action, tt.nonce, tt.vault, tt.mainnet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig != tt.want {
+				t.Fatalf("签名 = %+v, 期望 %+v", sig, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWalletKeyInvalid(t *testing.T) {
+	for _, key := range []string{
+		"0x1234",
+		"0x0000000000000000000000000000000000000000000000000000000000000000",
+		"0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", // 曲线阶 n
+	} {
+		if _, err := parseWalletKey(key); err == nil {
+			t.Fatalf("私钥 %s 解析成功, 期望报错", key)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package exchange
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dsbot/internal/config"
 	"dsbot/internal/models"
 )
 
@@ -16,20 +22,88 @@ type Exchange interface {
 	// symbol: 交易对符号
 	FetchTicker(symbol string) (*models.Ticker, error)
 
+	// FetchOrderBook 获取盘口深度
+	// symbol: 交易对符号
+	// depth: 档位数量
+	FetchOrderBook(symbol string, depth int) (*models.OrderBook, error)
+
 	// FetchPosition 获取持仓信息（合约模式）
 	// symbol: 交易对符号
 	FetchPosition(symbol string) (*models.Position, error)
 
+	// FetchPositions 获取交易对的全部持仓（合约模式，双向持仓时多空各一条，无持仓时为空）
+	// symbol: 交易对符号；持仓数量为基础币种数量（按张计价的交易所需按合约面值换算），与 PlaceOrder 的 amount 单位一致
+	FetchPositions(symbol string) ([]models.Position, error)
+
 	// FetchBalance 获取账户余额（现货模式）
 	// currency: 币种 (如 "BTC", "USDT")
 	FetchBalance(currency string) (float64, error)
 
+	// FetchAccountSummary 获取账户权益、可用保证金和维持保证金
+	// currency: 计价币种 (如 "USDT")
+	FetchAccountSummary(currency string) (*models.AccountSummary, error)
+
+	// Transfer 账户间划转资金（如资金账户与交易账户之间）
+	// currency: 币种 (如 "USDT")
+	// amount: 划转数量
+	// from/to: 账户类型 (AccountFunding 或 AccountTrading)
+	Transfer(currency string, amount float64, from, to string) error
+
 	// PlaceOrder 下单
 	// symbol: 交易对符号
 	// side: 买卖方向 ("buy" or "sell")
 	// amount: 数量
 	// params: 额外参数 (如 reduceOnly, posSide 等)
-	PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) error
+	// 返回: 交易所订单ID
+	PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error)
+
+	// PlaceOrders 批量下单（如止盈阶梯、网格挂单），每批最多 MaxBatchOrders 笔
+	// requests: 订单请求列表
+	// 返回: 与请求一一对应的下单结果；仅在整批无法提交时返回 error
+	PlaceOrders(requests []OrderRequest) ([]OrderResult, error)
+
+	// FetchOrder 查询订单
+	// symbol: 交易对符号
+	// orderID: 交易所订单ID
+	FetchOrder(symbol, orderID string) (*models.Order, error)
+
+	// CancelOrder 撤销订单
+	// symbol: 交易对符号
+	// orderID: 交易所订单ID
+	CancelOrder(symbol, orderID string) error
+
+	// CancelAlgoOrder 按自定义ID撤销策略委托（如开仓附带的止损/止盈单）
+	// 委托已触发、已撤销或不存在时返回nil
+	// symbol: 交易对符号
+	// clientID: 下单时指定的策略委托自定义ID
+	CancelAlgoOrder(symbol, clientID string) error
+
+	// FetchOpenOrders 获取未成交订单
+	// symbol: 交易对符号
+	FetchOpenOrders(symbol string) ([]models.Order, error)
+
+	// FetchMyTrades 获取账户成交记录（按时间升序）
+	// symbol: 交易对符号
+	// since: 起始时间（零值表示不限制）
+	FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error)
+
+	// FetchFundingFees 获取账户在该交易对的资金费用记录（按时间升序，现货返回空）
+	// symbol: 交易对符号
+	// since: 起始时间（零值表示交易所允许的最早时间）
+	FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error)
+
+	// FetchDerivativesStats 获取永续合约的资金费率、全市场持仓量和标记/指数价格（公共行情，现货返回nil）
+	// symbol: 交易对符号
+	FetchDerivativesStats(symbol string) (*models.DerivativesStats, error)
+
+	// FetchTransfers 获取充值、提现和账户间划转记录（按时间升序，仅包含已完成的记录；划转以交易账户视角记正负）
+	// currency: 币种 (如 "USDT")
+	// since: 起始时间（零值表示交易所允许的最早时间）
+	FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error)
+
+	// FetchTradingFees 获取账户在该交易对的手续费率
+	// symbol: 交易对符号
+	FetchTradingFees(symbol string) (*models.FeeRate, error)
 
 	// SetLeverage 设置杠杆
 	// symbol: 交易对符号
@@ -40,6 +114,10 @@ type Exchange interface {
 	// symbol: 交易对符号
 	GetInstrumentInfo(symbol string) (*InstrumentInfo, error)
 
+	// ListInstruments 获取可交易的交易对列表
+	// instType: 产品类型 ("spot" or "swap"，为空时使用当前交易模式)
+	ListInstruments(instType string) ([]InstrumentInfo, error)
+
 	// ParseSymbols 解析交易对符号
 	// symbolA: 基础币种 (如 "BTC")
 	// symbolB: 计价币种 (如 "USDT")
@@ -48,6 +126,72 @@ type Exchange interface {
 
 	// GetExchangeName 获取交易所名称
 	GetExchangeName() string
+
+	// Capabilities 获取交易所支持的功能（策略层据此降级，而不是到运行时才失败）
+	Capabilities() Capabilities
+}
+
+// Capabilities 交易所功能支持情况
+type Capabilities struct {
+	Futures      bool // 永续合约交易
+	Spot         bool // 现货交易
+	HedgeMode    bool // 双向持仓（同一交易对可同时持有多空仓位）
+	AlgoOrders   bool // 开仓附带交易所端止损止盈（括号单）
+	TrailingStop bool // 交易所端移动止损委托
+	WebSocket    bool // WebSocket 行情/订单推送
+	BatchOrders  bool // 原生批量下单（不支持时 PlaceOrders 逐笔提交）
+	LimitOrders  bool // 限价单（ParamLimitPrice）
+}
+
+// SupportsMode 是否支持该交易模式
+func (c Capabilities) SupportsMode(mode config.TradingMode) bool {
+	if mode == config.TradingModeSpot {
+		return c.Spot
+	}
+	return c.Futures
+}
+
+// PlaceOrder 通用参数
+const (
+	// ParamClientOrderID 自定义订单ID (string)，作为幂等键；未指定时自动生成
+	ParamClientOrderID = "clientOrderID"
+
+	// 开仓时附带交易所端止损止盈（括号单）
+	ParamStopLossPrice      = "stopLossPrice"      // 止损触发价 (float64)
+	ParamStopLossClientID   = "stopLossClientID"   // 止损委托自定义ID (string)
+	ParamTakeProfitPrice    = "takeProfitPrice"    // 止盈触发价 (float64)
+	ParamTakeProfitClientID = "takeProfitClientID" // 止盈委托自定义ID (string)
+
+	// 开仓成交后提交交易所端移动止损委托（目前仅OKX合约支持）
+	ParamTrailingCallbackRatio = "trailingCallbackRatio" // 回调幅度 (float64，%)
+	ParamTrailingClientID      = "trailingClientID"      // 移动止损委托自定义ID (string)
+
+	// ParamLimitPrice 限价 (float64)，指定时提交GTC限价单，未成交部分保持挂单直至撤单
+	ParamLimitPrice = "limitPrice"
+)
+
+// Transfer 账户类型
+const (
+	AccountFunding = "funding" // 资金账户（OKX 资金账户、Gate.io 现货账户）
+	AccountTrading = "trading" // 交易账户（OKX 交易账户、Gate.io 合约账户）
+)
+
+// MaxBatchOrders 单次批量下单的最大订单数
+const MaxBatchOrders = 20
+
+// OrderRequest 批量下单中的单笔订单请求（参数含义同 PlaceOrder）
+type OrderRequest struct {
+	Symbol string
+	Side   string
+	Amount float64
+	Params map[string]interface{}
+}
+
+// OrderResult 批量下单中的单笔订单结果
+type OrderResult struct {
+	ClientOrderID string // 自定义订单ID
+	OrderID       string // 交易所订单ID（失败时为空）
+	Err           error  // 该笔订单的错误（成功时为nil）
 }
 
 // InstrumentInfo 合约信息 (通用结构)
@@ -58,4 +202,58 @@ type InstrumentInfo struct {
 	MinSize       float64 // 最小下单数量
 	MinAmount     float64 // 最小订单金额（现货专用，以计价货币计）
 	TickSize      float64 // 价格精度
+	BaseCurrency  string  // 基础币种（合约为面值币种）
+	QuoteCurrency string  // 计价币种（合约为结算币种）
+	MaxLeverage   float64 // 最大杠杆（现货为0）
+	State         string  // 交易状态（如 live）
+}
+
+// ErrInstrumentUnavailable 交易对在交易所不存在或当前不可交易
+var ErrInstrumentUnavailable = errors.New("交易对不存在或不可交易")
+
+// FindInstrument 在交易所可交易列表中查找交易对，确认其存在且处于可交易状态（否则返回 ErrInstrumentUnavailable）
+// 先按基础币种和计价币种匹配；交易所对币种有别名时（如 Kraken 的 XBT、USDT 映射到 USD 合约），
+// 再按 GetInstrumentInfo 解析出的产品ID匹配
+func FindInstrument(exch Exchange, symbolA, symbolB string) (*InstrumentInfo, error) {
+	instruments, err := exch.ListInstruments("")
+	if err != nil {
+		return nil, fmt.Errorf("获取可交易列表失败: %w", err)
+	}
+
+	var found *InstrumentInfo
+	for i := range instruments {
+		inst := &instruments[i]
+		if strings.EqualFold(inst.BaseCurrency, symbolA) && strings.EqualFold(inst.QuoteCurrency, symbolB) {
+			found = inst
+			break
+		}
+	}
+	if found == nil {
+		if info, err := exch.GetInstrumentInfo(exch.ParseSymbols(symbolA, symbolB)); err == nil {
+			for i := range instruments {
+				if strings.EqualFold(instruments[i].InstID, info.InstID) {
+					found = &instruments[i]
+					break
+				}
+			}
+		}
+	}
+
+	if found == nil {
+		var similar []string
+		for _, inst := range instruments {
+			if strings.EqualFold(inst.BaseCurrency, symbolA) && len(similar) < 5 {
+				similar = append(similar, inst.InstID)
+			}
+		}
+		if len(similar) > 0 {
+			return nil, fmt.Errorf("%w: %s 上没有 %s/%s (同基础币种可交易: %s)", ErrInstrumentUnavailable,
+				exch.GetExchangeName(), symbolA, symbolB, strings.Join(similar, ", "))
+		}
+		return nil, fmt.Errorf("%w: %s 上没有 %s/%s", ErrInstrumentUnavailable, exch.GetExchangeName(), symbolA, symbolB)
+	}
+	if found.State != "" && found.State != "live" {
+		return nil, fmt.Errorf("%w: %s 上的 %s 状态为 %s", ErrInstrumentUnavailable, exch.GetExchangeName(), found.InstID, found.State)
+	}
+	return found, nil
 }
@@ -0,0 +1,124 @@
+package exchange
+
+import (
+	"fmt"
+	"sync"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// MarketDataSource 行情数据源（仅行情接口，用于主交易所行情接口故障时的备用数据）
+type MarketDataSource interface {
+	// Name 数据源名称
+	Name() string
+	FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error)
+	FetchTicker(symbol string) (*models.Ticker, error)
+	FetchOrderBook(symbol string, depth int) (*models.OrderBook, error)
+}
+
+// DataSourceReporter 可选接口：报告交易对最近一次K线数据的来源
+type DataSourceReporter interface {
+	OHLCVSource(symbol string) string
+}
+
+// OHLCVSourceOf 获取交易对最近一次K线数据的来源（交易所未实现 DataSourceReporter 时为交易所名称）
+func OHLCVSourceOf(exch Exchange, symbol string) string {
+	if r, ok := exch.(DataSourceReporter); ok {
+		return r.OHLCVSource(symbol)
+	}
+	return exch.GetExchangeName()
+}
+
+// FallbackExchange 行情备用包装 - 主交易所的行情接口失败时改用备用数据源，
+// 账户和交易接口始终使用主交易所
+type FallbackExchange struct {
+	Exchange
+	fallback MarketDataSource
+
+	mu      sync.Mutex
+	sources map[string]string // 交易对 -> 最近一次K线数据来源
+}
+
+// NewFallbackExchange 创建带备用行情源的交易所
+func NewFallbackExchange(primary Exchange, fallback MarketDataSource) *FallbackExchange {
+	return &FallbackExchange{
+		Exchange: primary,
+		fallback: fallback,
+		sources:  make(map[string]string),
+	}
+}
+
+// NewMarketDataSource 根据配置创建备用行情源
+func NewMarketDataSource(source string, tradingMode config.TradingMode) (MarketDataSource, error) {
+	switch source {
+	case "", "binance":
+		return NewBinancePublicSource(tradingMode)
+	default:
+		return nil, fmt.Errorf("不支持的备用行情源: %s (支持: binance)", source)
+	}
+}
+
+// FetchOHLCV 获取K线数据（主交易所失败时使用备用数据源）
+func (e *FallbackExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	data, err := e.Exchange.FetchOHLCV(symbol, timeframe, limit)
+	if err == nil {
+		e.setSource(symbol, e.Exchange.GetExchangeName())
+		return data, nil
+	}
+
+	logger.Warnf("[行情] %s K线获取失败，改用备用数据源 %s: %v", e.Exchange.GetExchangeName(), e.fallback.Name(), err)
+	data, fbErr := e.fallback.FetchOHLCV(symbol, timeframe, limit)
+	if fbErr != nil {
+		return nil, fmt.Errorf("%w (备用数据源 %s 也失败: %v)", err, e.fallback.Name(), fbErr)
+	}
+	e.setSource(symbol, e.fallback.Name())
+	return data, nil
+}
+
+// FetchTicker 获取最新行情（主交易所失败时使用备用数据源）
+func (e *FallbackExchange) FetchTicker(symbol string) (*models.Ticker, error) {
+	ticker, err := e.Exchange.FetchTicker(symbol)
+	if err == nil {
+		return ticker, nil
+	}
+
+	logger.Warnf("[行情] %s 行情获取失败，改用备用数据源 %s: %v", e.Exchange.GetExchangeName(), e.fallback.Name(), err)
+	ticker, fbErr := e.fallback.FetchTicker(symbol)
+	if fbErr != nil {
+		return nil, fmt.Errorf("%w (备用数据源 %s 也失败: %v)", err, e.fallback.Name(), fbErr)
+	}
+	return ticker, nil
+}
+
+// FetchOrderBook 获取盘口深度（主交易所失败时使用备用数据源）
+func (e *FallbackExchange) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	book, err := e.Exchange.FetchOrderBook(symbol, depth)
+	if err == nil {
+		return book, nil
+	}
+
+	logger.Debugf("[DEBUG] %s 盘口获取失败，改用备用数据源 %s: %v", e.Exchange.GetExchangeName(), e.fallback.Name(), err)
+	book, fbErr := e.fallback.FetchOrderBook(symbol, depth)
+	if fbErr != nil {
+		return nil, fmt.Errorf("%w (备用数据源 %s 也失败: %v)", err, e.fallback.Name(), fbErr)
+	}
+	return book, nil
+}
+
+// OHLCVSource 交易对最近一次K线数据的来源
+func (e *FallbackExchange) OHLCVSource(symbol string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if source, ok := e.sources[symbol]; ok {
+		return source
+	}
+	return e.Exchange.GetExchangeName()
+}
+
+func (e *FallbackExchange) setSource(symbol, source string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sources[symbol] = source
+}
@@ -0,0 +1,1001 @@
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// mockBookSize 预设行情时生成的盘口每档数量（足够大，市价单总能全部成交）
+const mockBookSize = 1e9
+
+// marketDataProvider 行情数据提供者（交易所或 MarketDataSource 均可）
+type marketDataProvider interface {
+	FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error)
+	FetchTicker(symbol string) (*models.Ticker, error)
+	FetchOrderBook(symbol string, depth int) (*models.OrderBook, error)
+}
+
+// MockExchange 模拟交易所 - 用于单元测试和测试模式下的模拟交易
+// 行情可预设（K线、价格）或来自真实交易所；市价单按当前价格立即全部成交，
+// 现货更新币种余额，合约按杠杆冻结保证金并维护持仓；可为任意方法注入错误
+// 下单和持仓数量单位与真实交易所客户端一致（基础币种）；合约面值默认为1，
+// 设置合约面值后订单和成交数量按张返回（与OKX、Gate.io等按张计价的交易所一致）
+type MockExchange struct {
+	tradingMode config.TradingMode
+	market      marketDataProvider // 行情来源（可选，为nil时使用预设行情）
+
+	mu        sync.Mutex
+	candles   map[string][]models.OHLCV   // 交易对|周期 -> K线
+	prices    map[string]float64          // 交易对 -> 最新价格
+	balances  map[string]float64          // 币种 -> 可用余额
+	funding   map[string]float64          // 币种 -> 资金账户余额
+	positions map[string]*models.Position // 持仓键 -> 持仓（合约，见 positionKey）
+	margins   map[string]float64          // 持仓键 -> 持仓占用保证金（合约）
+	leverage  map[string]int              // 交易对 -> 杠杆
+	orders    map[string]*models.Order    // 订单ID -> 订单
+	clientIDs map[string]string           // 自定义订单ID -> 订单ID
+	trades    []models.Trade
+	fundings  []models.FundingFee                 // 预设的资金费用记录
+	derivs    map[string]*models.DerivativesStats // 交易对 -> 预设的合约市场数据
+	transfers []models.AccountTransfer            // 划转记录
+	feeRate   models.FeeRate
+	slippage  float64 // 市价单滑点（比例，买入按最新价格上浮、卖出下浮成交）
+	hedgeMode bool    // 双向持仓（同一交易对多空分别持仓）
+	seq       int
+
+	contractValues map[string]float64 // 交易对 -> 合约面值（合约模式，未设置时为1）
+
+	limitFill float64 // 可立即成交的限价单的成交比例（默认1；小于1时剩余部分挂单等待，挂单不会被撮合）
+
+	failNext   map[string][]error // 方法名 -> 依次返回的一次性错误
+	failAlways map[string]error   // 方法名 -> 持续返回的错误
+}
+
+// NewMockExchange 创建模拟交易所（默认吃单费率0.05%、挂单费率0.02%，杠杆1倍）
+func NewMockExchange(tradingMode config.TradingMode) *MockExchange {
+	return &MockExchange{
+		tradingMode: tradingMode,
+		candles:     make(map[string][]models.OHLCV),
+		prices:      make(map[string]float64),
+		balances:    make(map[string]float64),
+		funding:     make(map[string]float64),
+		derivs:      make(map[string]*models.DerivativesStats),
+		positions:   make(map[string]*models.Position),
+		margins:     make(map[string]float64),
+		leverage:    make(map[string]int),
+		orders:      make(map[string]*models.Order),
+		clientIDs:   make(map[string]string),
+		feeRate:     models.FeeRate{Maker: 0.0002, Taker: 0.0005},
+		failNext:    make(map[string][]error),
+		failAlways:  make(map[string]error),
+
+		contractValues: make(map[string]float64),
+
+		limitFill: 1,
+	}
+}
+
+// SetMarketData 使用真实行情（K线、行情、盘口来自 market，预设行情不再生效）
+func (m *MockExchange) SetMarketData(market marketDataProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.market = market
+}
+
+// SetCandles 预设K线（按时间升序），最后一根收盘价同时作为最新价格
+func (m *MockExchange) SetCandles(symbol, timeframe string, candles []models.OHLCV) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.candles[symbol+"|"+timeframe] = append([]models.OHLCV(nil), candles...)
+	if n := len(candles); n > 0 {
+		m.prices[symbol] = candles[n-1].Close
+	}
+}
+
+// SetPrice 设置最新价格（用于模拟行情变化触发止盈止损）
+func (m *MockExchange) SetPrice(symbol string, price float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices[symbol] = price
+}
+
+// SetBalance 设置币种可用余额
+func (m *MockExchange) SetBalance(currency string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balances[currency] = amount
+}
+
+// SetFundingBalance 设置资金账户余额（可通过 Transfer 划转到交易账户）
+func (m *MockExchange) SetFundingBalance(currency string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.funding[currency] = amount
+}
+
+// SetPosition 设置持仓（nil 表示清空交易对的全部持仓，保证金按开仓价和杠杆计算，不从余额扣除）
+// 双向持仓时按持仓方向分别设置
+func (m *MockExchange) SetPosition(symbol string, pos *models.Position) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pos == nil {
+		for _, side := range []string{"long", "short"} {
+			key := m.positionKey(symbol, side)
+			delete(m.positions, key)
+			delete(m.margins, key)
+		}
+		return
+	}
+	p := *pos
+	p.Symbol = symbol
+	if p.Leverage <= 0 {
+		p.Leverage = m.leverageOf(symbol)
+	}
+	key := m.positionKey(symbol, p.Side)
+	m.positions[key] = &p
+	m.margins[key] = p.EntryPrice * p.Size / float64(p.Leverage)
+}
+
+// SetHedgeMode 设置双向持仓（同一交易对可同时持有多空仓位，开平仓按 posSide 区分）
+func (m *MockExchange) SetHedgeMode(enable bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hedgeMode = enable
+}
+
+// positionKey 持仓键（单向持仓为交易对，双向持仓为 交易对|方向）
+func (m *MockExchange) positionKey(symbol, side string) string {
+	if m.hedgeMode {
+		return symbol + "|" + side
+	}
+	return symbol
+}
+
+// SetContractValue 设置合约面值（每张合约对应的基础币种数量，仅合约模式生效）
+func (m *MockExchange) SetContractValue(symbol string, ctVal float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contractValues[symbol] = ctVal
+}
+
+// contractValueLocked 合约面值（现货和未设置时为1，调用方需持有锁）
+func (m *MockExchange) contractValueLocked(symbol string) float64 {
+	if ctVal := m.contractValues[symbol]; ctVal > 0 && m.tradingMode == config.TradingModeFutures {
+		return ctVal
+	}
+	return 1
+}
+
+// SetFeeRate 设置手续费率
+func (m *MockExchange) SetFeeRate(maker, taker float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feeRate = models.FeeRate{Maker: maker, Taker: taker}
+}
+
+// SetTakerFeeRate 设置吃单手续费率（市价单成交使用）
+func (m *MockExchange) SetTakerFeeRate(taker float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.feeRate.Taker = taker
+}
+
+// SetSlippage 设置市价单滑点（基点，如5表示买入按最新价格上浮0.05%成交、卖出下浮0.05%）
+func (m *MockExchange) SetSlippage(bps float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slippage = bps / 10000
+}
+
+// PaperAccount 模拟账户汇总（按计价币种）
+type PaperAccount struct {
+	Equity float64 // 权益（可用余额 + 持仓保证金 + 未实现盈亏，现货持仓按最新价格折算）
+	Fees   float64 // 累计手续费
+	Trades int     // 成交笔数
+}
+
+// Account 获取模拟账户汇总（用于测试模式下观察策略的累计盈亏）
+func (m *MockExchange) Account(currency string) PaperAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account := PaperAccount{Equity: m.balances[currency]}
+	for key, pos := range m.positions {
+		if _, quote := splitSymbol(pos.Symbol); quote != currency {
+			continue
+		}
+		price, ok := m.prices[pos.Symbol]
+		if !ok {
+			price = pos.EntryPrice
+		}
+		account.Equity += m.margins[key] + positionPnL(pos, price, pos.Size)
+	}
+	if m.tradingMode == config.TradingModeSpot {
+		for symbol, price := range m.prices {
+			if base, quote := splitSymbol(symbol); quote == currency {
+				account.Equity += m.balances[base] * price
+			}
+		}
+	}
+	for _, trade := range m.trades {
+		if trade.FeeCurrency == currency {
+			account.Fees -= trade.Fee
+			account.Trades++
+		}
+	}
+	return account
+}
+
+// SetLimitFill 设置可立即成交的限价单的成交比例（0~1，模拟盘口深度不足时限价单部分成交或不成交）
+func (m *MockExchange) SetLimitFill(ratio float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limitFill = math.Max(0, math.Min(ratio, 1))
+}
+
+// FailNext 注入一次性错误：下一次调用 method（如 "PlaceOrder"）时返回 err，多次调用按顺序依次返回
+func (m *MockExchange) FailNext(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failNext[method] = append(m.failNext[method], err)
+}
+
+// SetError 注入持续错误：之后每次调用 method 都返回 err（err 为 nil 时清除）
+func (m *MockExchange) SetError(method string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		delete(m.failAlways, method)
+		return
+	}
+	m.failAlways[method] = err
+}
+
+// Orders 获取全部订单（按创建顺序）
+func (m *MockExchange) Orders() []models.Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	orders := make([]models.Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderID < orders[j].OrderID })
+	return orders
+}
+
+// injectedError 取出 method 的注入错误（调用方需持有锁）
+func (m *MockExchange) injectedError(method string) error {
+	if queue := m.failNext[method]; len(queue) > 0 {
+		m.failNext[method] = queue[1:]
+		return queue[0]
+	}
+	return m.failAlways[method]
+}
+
+// checkError 检查 method 的注入错误
+func (m *MockExchange) checkError(method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.injectedError(method)
+}
+
+// FetchOHLCV 获取K线数据
+func (m *MockExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	if err := m.checkError("FetchOHLCV"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	market := m.market
+	candles := m.candles[symbol+"|"+timeframe]
+	m.mu.Unlock()
+
+	if market != nil {
+		data, err := market.FetchOHLCV(symbol, timeframe, limit)
+		if err == nil && len(data) > 0 {
+			m.SetPrice(symbol, data[len(data)-1].Close)
+		}
+		return data, err
+	}
+
+	if len(candles) == 0 {
+		return nil, m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("未预设 %s %s K线", symbol, timeframe))
+	}
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return append([]models.OHLCV(nil), candles...), nil
+}
+
+// FetchTicker 获取最新行情
+func (m *MockExchange) FetchTicker(symbol string) (*models.Ticker, error) {
+	if err := m.checkError("FetchTicker"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	market := m.market
+	m.mu.Unlock()
+
+	if market != nil {
+		ticker, err := market.FetchTicker(symbol)
+		if err == nil && ticker.Last > 0 {
+			m.SetPrice(symbol, ticker.Last)
+		}
+		return ticker, err
+	}
+
+	price, err := m.price(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Ticker{Symbol: symbol, Last: price, Bid: price, Ask: price, Mark: price, Index: price}, nil
+}
+
+// FetchOrderBook 获取盘口深度（预设行情时以最新价格生成单档盘口）
+func (m *MockExchange) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	if err := m.checkError("FetchOrderBook"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	market := m.market
+	m.mu.Unlock()
+
+	if market != nil {
+		return market.FetchOrderBook(symbol, depth)
+	}
+
+	price, err := m.price(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OrderBook{
+		Symbol:    symbol,
+		Bids:      []models.OrderBookLevel{{Price: price, Size: mockBookSize}},
+		Asks:      []models.OrderBookLevel{{Price: price, Size: mockBookSize}},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// FetchPosition 获取持仓（未实现盈亏按最新价格计算，双向持仓同时持有多空时返回多仓）
+func (m *MockExchange) FetchPosition(symbol string) (*models.Position, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchPosition"); err != nil {
+		return nil, err
+	}
+
+	positions := m.positionsLocked(symbol)
+	if len(positions) == 0 {
+		return nil, nil
+	}
+	return &positions[0], nil
+}
+
+// FetchPositions 获取交易对的全部持仓（双向持仓时多仓在前）
+func (m *MockExchange) FetchPositions(symbol string) ([]models.Position, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchPositions"); err != nil {
+		return nil, err
+	}
+	return m.positionsLocked(symbol), nil
+}
+
+// positionsLocked 交易对的持仓副本（未实现盈亏按最新价格计算，调用方需持有 m.mu）
+func (m *MockExchange) positionsLocked(symbol string) []models.Position {
+	keys := []string{symbol}
+	if m.hedgeMode {
+		keys = []string{m.positionKey(symbol, "long"), m.positionKey(symbol, "short")}
+	}
+	var positions []models.Position
+	for _, key := range keys {
+		pos, ok := m.positions[key]
+		if !ok {
+			continue
+		}
+		p := *pos
+		if price, ok := m.prices[symbol]; ok {
+			p.UnrealizedPnL = positionPnL(&p, price, p.Size)
+		}
+		positions = append(positions, p)
+	}
+	return positions
+}
+
+// FetchBalance 获取币种可用余额
+func (m *MockExchange) FetchBalance(currency string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchBalance"); err != nil {
+		return 0, err
+	}
+	return m.balances[currency], nil
+}
+
+// Transfer 在资金账户和交易账户（可用余额）之间划转
+func (m *MockExchange) Transfer(currency string, amount float64, from, to string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("Transfer"); err != nil {
+		return err
+	}
+
+	accounts := map[string]map[string]float64{AccountFunding: m.funding, AccountTrading: m.balances}
+	source, ok := accounts[from]
+	if !ok {
+		return fmt.Errorf("不支持的划转账户: %s", from)
+	}
+	target, ok := accounts[to]
+	if !ok {
+		return fmt.Errorf("不支持的划转账户: %s", to)
+	}
+	if amount <= 0 || source[currency] < amount {
+		return fmt.Errorf("%s 余额不足: %.8f < %.8f", from, source[currency], amount)
+	}
+	source[currency] -= amount
+	target[currency] += amount
+
+	// 按交易账户视角记录（转入为正，转出为负）
+	change := amount
+	if from == AccountTrading {
+		change = -amount
+	}
+	if from != to {
+		m.transfers = append(m.transfers, models.AccountTransfer{
+			ID:        fmt.Sprintf("mock-transfer-%d", len(m.transfers)+1),
+			Type:      models.TransferInternal,
+			Currency:  currency,
+			Amount:    change,
+			Timestamp: time.Now(),
+		})
+	}
+	return nil
+}
+
+// AddFundingFee 预设一条资金费用记录
+func (m *MockExchange) AddFundingFee(fee models.FundingFee) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fundings = append(m.fundings, fee)
+}
+
+// FetchFundingFees 获取预设的资金费用记录
+func (m *MockExchange) FetchFundingFees(symbol string, since time.Time) ([]models.FundingFee, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchFundingFees"); err != nil {
+		return nil, err
+	}
+	var fees []models.FundingFee
+	for _, fee := range m.fundings {
+		if fee.Symbol == symbol && !fee.Timestamp.Before(since) {
+			fees = append(fees, fee)
+		}
+	}
+	return fees, nil
+}
+
+// SetDerivativesStats 预设交易对的资金费率、持仓量等合约市场数据
+func (m *MockExchange) SetDerivativesStats(symbol string, stats models.DerivativesStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.derivs[symbol] = &stats
+}
+
+// derivativesProvider 提供合约市场数据的行情来源（真实交易所客户端）
+type derivativesProvider interface {
+	FetchDerivativesStats(symbol string) (*models.DerivativesStats, error)
+}
+
+// FetchDerivativesStats 获取预设的合约市场数据，未预设时使用行情来源的数据（现货或均无时返回nil）
+func (m *MockExchange) FetchDerivativesStats(symbol string) (*models.DerivativesStats, error) {
+	m.mu.Lock()
+	if err := m.injectedError("FetchDerivativesStats"); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	stats, ok := m.derivs[symbol]
+	market := m.market
+	spot := m.tradingMode == config.TradingModeSpot
+	m.mu.Unlock()
+
+	if spot {
+		return nil, nil
+	}
+	if !ok {
+		if provider, isProvider := market.(derivativesProvider); isProvider {
+			return provider.FetchDerivativesStats(symbol)
+		}
+		return nil, nil
+	}
+	copied := *stats
+	return &copied, nil
+}
+
+// FetchTransfers 获取交易账户的划转记录
+func (m *MockExchange) FetchTransfers(currency string, since time.Time) ([]models.AccountTransfer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchTransfers"); err != nil {
+		return nil, err
+	}
+	var transfers []models.AccountTransfer
+	for _, transfer := range m.transfers {
+		if transfer.Currency == currency && !transfer.Timestamp.Before(since) {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+// FetchAccountSummary 获取账户权益（可用余额 + 持仓保证金 + 未实现盈亏），维持保证金按持仓价值的0.5%估算
+func (m *MockExchange) FetchAccountSummary(currency string) (*models.AccountSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchAccountSummary"); err != nil {
+		return nil, err
+	}
+
+	summary := &models.AccountSummary{
+		Currency:        currency,
+		TotalEquity:     m.balances[currency],
+		AvailableMargin: m.balances[currency],
+	}
+	for key, pos := range m.positions {
+		if _, quote := splitSymbol(pos.Symbol); quote != currency {
+			continue
+		}
+		price, ok := m.prices[pos.Symbol]
+		if !ok {
+			price = pos.EntryPrice
+		}
+		summary.TotalEquity += m.margins[key] + positionPnL(pos, price, pos.Size)
+		summary.MaintenanceMargin += pos.Size * price * 0.005
+	}
+	return summary, nil
+}
+
+// PlaceOrder 下市价单（按最新价格加滑点立即全部成交）
+// 支持参数: clientOrderID（重复ID返回已有订单）、posSide、reduceOnly、
+// limitPrice（限价可立即成交时按不劣于限价的价格成交 SetLimitFill 设置的比例（默认全部），
+// 否则挂单等待，挂单不会被撮合）
+func (m *MockExchange) PlaceOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("PlaceOrder"); err != nil {
+		return "", err
+	}
+
+	clientOrderID, _ := params[ParamClientOrderID].(string)
+	if clientOrderID == "" {
+		clientOrderID = NewClientOrderID()
+	}
+	if orderID, ok := m.clientIDs[clientOrderID]; ok {
+		return orderID, nil
+	}
+
+	if side != "buy" && side != "sell" {
+		return "", m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("无效的买卖方向: %s", side))
+	}
+	if amount <= 0 {
+		return "", m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("无效的下单数量: %.8f", amount))
+	}
+	price, ok := m.prices[symbol]
+	if !ok || price <= 0 {
+		return "", m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("%s 无可用价格", symbol))
+	}
+	limit, _ := params[ParamLimitPrice].(float64)
+	marketable := limit <= 0 || (side == "buy" && limit >= price) || (side == "sell" && limit <= price)
+	if side == "buy" {
+		price *= 1 + m.slippage
+	} else {
+		price *= 1 - m.slippage
+	}
+
+	order := &models.Order{
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Size:          amount / m.contractValueLocked(symbol),
+		CreatedAt:     time.Now(),
+	}
+	if limit > 0 {
+		order.Type = "limit"
+		order.Price = limit
+		if side == "buy" {
+			price = math.Min(price, limit)
+		} else {
+			price = math.Max(price, limit)
+		}
+	}
+	fill := 1.0
+	if limit > 0 {
+		fill = m.limitFill
+	}
+	if !marketable || fill <= 0 {
+		m.seq++
+		order.OrderID = fmt.Sprintf("mock%08d", m.seq)
+		order.State = models.OrderStateLive
+		order.UpdatedAt = order.CreatedAt
+		m.orders[order.OrderID] = order
+		m.clientIDs[clientOrderID] = order.OrderID
+		return order.OrderID, nil
+	}
+
+	var realizedPnL float64
+	var err error
+	if m.tradingMode == config.TradingModeSpot {
+		err = m.fillSpot(symbol, side, amount*fill, price, order)
+	} else {
+		realizedPnL, err = m.fillFutures(symbol, side, amount*fill, price, params, order)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	m.seq++
+	order.OrderID = fmt.Sprintf("mock%08d", m.seq)
+	order.FilledSize = order.Size * fill
+	order.AvgPrice = price
+	order.State = models.OrderStateFilled
+	if fill < 1 {
+		order.State = models.OrderStatePartiallyFilled
+	}
+	order.UpdatedAt = order.CreatedAt
+	m.orders[order.OrderID] = order
+	m.clientIDs[clientOrderID] = order.OrderID
+
+	m.trades = append(m.trades, models.Trade{
+		TradeID:     fmt.Sprintf("mocktrade%08d", m.seq),
+		OrderID:     order.OrderID,
+		Symbol:      symbol,
+		Side:        side,
+		PosSide:     order.PosSide,
+		Price:       price,
+		Size:        order.FilledSize,
+		Fee:         order.Fee,
+		FeeCurrency: order.FeeCurrency,
+		RealizedPnL: realizedPnL,
+		Timestamp:   order.CreatedAt,
+	})
+	return order.OrderID, nil
+}
+
+// fillSpot 现货成交：更新基础币种和计价币种余额（手续费以计价币种扣除）
+func (m *MockExchange) fillSpot(symbol, side string, amount, price float64, order *models.Order) error {
+	base, quote := splitSymbol(symbol)
+	notional := amount * price
+	fee := notional * m.feeRate.Taker
+
+	if side == "buy" {
+		if m.balances[quote] < notional+fee {
+			return m.mockError(ErrorKindInsufficientBalance,
+				fmt.Sprintf("%s余额不足: 需要%.2f，可用%.2f", quote, notional+fee, m.balances[quote]))
+		}
+		m.balances[quote] -= notional + fee
+		m.balances[base] += amount
+	} else {
+		if m.balances[base] < amount {
+			return m.mockError(ErrorKindInsufficientBalance,
+				fmt.Sprintf("%s余额不足: 需要%.8f，可用%.8f", base, amount, m.balances[base]))
+		}
+		m.balances[base] -= amount
+		m.balances[quote] += notional - fee
+	}
+
+	order.Fee = -fee
+	order.FeeCurrency = quote
+	return nil
+}
+
+// fillFutures 合约成交：开仓按杠杆冻结保证金，平仓释放保证金并结算盈亏（手续费以结算币种扣除）
+func (m *MockExchange) fillFutures(symbol, side string, amount, price float64, params map[string]interface{}, order *models.Order) (float64, error) {
+	_, quote := splitSymbol(symbol)
+	fee := amount * price * m.feeRate.Taker
+	reduceOnly, _ := params["reduceOnly"].(bool)
+	posSide, _ := params["posSide"].(string)
+	if posSide == "" {
+		posSide = "long"
+		if side == "sell" {
+			posSide = "short"
+		}
+	}
+
+	order.PosSide = posSide
+	order.ReduceOnly = reduceOnly
+	order.Fee = -fee
+	order.FeeCurrency = quote
+
+	key := m.positionKey(symbol, posSide)
+	pos := m.positions[key]
+	if reduceOnly {
+		if pos == nil || pos.Side != posSide {
+			return 0, m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("没有可平的%s持仓", posSide))
+		}
+		if amount > pos.Size {
+			amount = pos.Size
+		}
+		pnl := positionPnL(pos, price, amount)
+		released := m.margins[key] * amount / pos.Size
+		m.balances[quote] += released + pnl - fee
+		m.margins[key] -= released
+		pos.Size -= amount
+		if pos.Size <= 1e-12 {
+			delete(m.positions, key)
+			delete(m.margins, key)
+		}
+		return pnl, nil
+	}
+
+	if pos != nil && pos.Side != posSide {
+		return 0, m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("已有%s持仓，需先平仓", pos.Side))
+	}
+	leverage := m.leverageOf(symbol)
+	margin := amount * price / float64(leverage)
+	if m.balances[quote] < margin+fee {
+		return 0, m.mockError(ErrorKindInsufficientBalance,
+			fmt.Sprintf("保证金不足: 需要%.2f，可用%.2f", margin+fee, m.balances[quote]))
+	}
+	m.balances[quote] -= margin + fee
+	m.margins[key] += margin
+
+	if pos == nil {
+		m.positions[key] = &models.Position{
+			Side:       posSide,
+			Size:       amount,
+			EntryPrice: price,
+			Leverage:   leverage,
+			Symbol:     symbol,
+		}
+		return 0, nil
+	}
+	pos.EntryPrice = (pos.EntryPrice*pos.Size + price*amount) / (pos.Size + amount)
+	pos.Size += amount
+	return 0, nil
+}
+
+// PlaceOrders 批量下单（逐笔调用 PlaceOrder）
+func (m *MockExchange) PlaceOrders(requests []OrderRequest) ([]OrderResult, error) {
+	if err := m.checkError("PlaceOrders"); err != nil {
+		return nil, err
+	}
+	if len(requests) > MaxBatchOrders {
+		return nil, fmt.Errorf("批量下单最多 %d 笔，当前 %d 笔", MaxBatchOrders, len(requests))
+	}
+
+	results := make([]OrderResult, len(requests))
+	for i, req := range requests {
+		params := req.Params
+		if params == nil {
+			params = make(map[string]interface{})
+		}
+		clientOrderID, _ := params[ParamClientOrderID].(string)
+		if clientOrderID == "" {
+			clientOrderID = NewClientOrderID()
+			params[ParamClientOrderID] = clientOrderID
+		}
+		orderID, err := m.PlaceOrder(req.Symbol, req.Side, req.Amount, params)
+		results[i] = OrderResult{ClientOrderID: clientOrderID, OrderID: orderID, Err: err}
+	}
+	return results, nil
+}
+
+// FetchOrder 查询订单
+func (m *MockExchange) FetchOrder(symbol, orderID string) (*models.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchOrder"); err != nil {
+		return nil, err
+	}
+	order, ok := m.orders[orderID]
+	if !ok || order.Symbol != symbol {
+		return nil, m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("订单不存在: %s", orderID))
+	}
+	o := *order
+	return &o, nil
+}
+
+// CancelOrder 撤销订单（市价单已成交，未成交的限价单可撤销，撤销已终态订单返回错误）
+func (m *MockExchange) CancelOrder(symbol, orderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("CancelOrder"); err != nil {
+		return err
+	}
+	order, ok := m.orders[orderID]
+	if !ok || order.Symbol != symbol {
+		return m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("订单不存在: %s", orderID))
+	}
+	if order.IsFinal() {
+		return m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("订单已%s: %s", order.State, orderID))
+	}
+	order.State = models.OrderStateCanceled
+	order.UpdatedAt = time.Now()
+	return nil
+}
+
+// CancelAlgoOrder 撤销策略委托（不模拟交易所端止损止盈，总是成功）
+func (m *MockExchange) CancelAlgoOrder(symbol, clientID string) error {
+	return m.checkError("CancelAlgoOrder")
+}
+
+// FetchOpenOrders 获取未成交订单
+func (m *MockExchange) FetchOpenOrders(symbol string) ([]models.Order, error) {
+	if err := m.checkError("FetchOpenOrders"); err != nil {
+		return nil, err
+	}
+	var open []models.Order
+	for _, o := range m.Orders() {
+		if o.Symbol == symbol && !o.IsFinal() {
+			open = append(open, o)
+		}
+	}
+	return open, nil
+}
+
+// FetchMyTrades 获取成交记录（按时间升序）
+func (m *MockExchange) FetchMyTrades(symbol string, since time.Time) ([]models.Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchMyTrades"); err != nil {
+		return nil, err
+	}
+	var trades []models.Trade
+	for _, t := range m.trades {
+		if t.Symbol == symbol && !t.Timestamp.Before(since) {
+			trades = append(trades, t)
+		}
+	}
+	return trades, nil
+}
+
+// FetchTradingFees 获取手续费率
+func (m *MockExchange) FetchTradingFees(symbol string) (*models.FeeRate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("FetchTradingFees"); err != nil {
+		return nil, err
+	}
+	fee := m.feeRate
+	fee.Symbol = symbol
+	return &fee, nil
+}
+
+// SetLeverage 设置杠杆（对之后的开仓生效）
+func (m *MockExchange) SetLeverage(symbol string, leverage int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.injectedError("SetLeverage"); err != nil {
+		return err
+	}
+	if leverage <= 0 {
+		return m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("无效的杠杆倍数: %d", leverage))
+	}
+	m.leverage[symbol] = leverage
+	return nil
+}
+
+// GetInstrumentInfo 获取交易对信息（面值默认为1，精度1e-8）
+func (m *MockExchange) GetInstrumentInfo(symbol string) (*InstrumentInfo, error) {
+	if err := m.checkError("GetInstrumentInfo"); err != nil {
+		return nil, err
+	}
+	base, quote := splitSymbol(symbol)
+	info := &InstrumentInfo{
+		InstID:        base + "-" + quote,
+		ContractValue: 1,
+		LotSize:       1e-8,
+		MinSize:       1e-8,
+		TickSize:      1e-8,
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		State:         "live",
+	}
+	if m.tradingMode == config.TradingModeFutures {
+		info.InstID += "-SWAP"
+		info.MaxLeverage = 125
+		m.mu.Lock()
+		info.ContractValue = m.contractValueLocked(symbol)
+		m.mu.Unlock()
+	}
+	return info, nil
+}
+
+// ListInstruments 获取可交易的交易对列表（已预设行情的交易对）
+func (m *MockExchange) ListInstruments(instType string) ([]InstrumentInfo, error) {
+	if err := m.checkError("ListInstruments"); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	symbols := make([]string, 0, len(m.prices))
+	for symbol := range m.prices {
+		symbols = append(symbols, symbol)
+	}
+	m.mu.Unlock()
+	sort.Strings(symbols)
+
+	instruments := make([]InstrumentInfo, 0, len(symbols))
+	for _, symbol := range symbols {
+		info, _ := m.GetInstrumentInfo(symbol)
+		instruments = append(instruments, *info)
+	}
+	return instruments, nil
+}
+
+// ParseSymbols 解析交易对符号（与OKX格式一致：合约 BTC/USDT:USDT，现货 BTC/USDT）
+func (m *MockExchange) ParseSymbols(symbolA, symbolB string) string {
+	if m.tradingMode == config.TradingModeSpot {
+		return fmt.Sprintf("%s/%s", symbolA, symbolB)
+	}
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+// GetExchangeName 获取交易所名称
+func (m *MockExchange) GetExchangeName() string {
+	return "mock"
+}
+
+// Capabilities 获取交易所支持的功能（不模拟交易所端止损止盈委托）
+func (m *MockExchange) Capabilities() Capabilities {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Capabilities{
+		Futures:     true,
+		Spot:        true,
+		HedgeMode:   m.hedgeMode,
+		LimitOrders: true,
+	}
+}
+
+// price 获取最新价格
+func (m *MockExchange) price(symbol string) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, ok := m.prices[symbol]
+	if !ok {
+		return 0, m.mockError(ErrorKindInvalidOrder, fmt.Sprintf("%s 无可用价格", symbol))
+	}
+	return price, nil
+}
+
+// leverageOf 交易对杠杆（未设置时为1，调用方需持有锁）
+func (m *MockExchange) leverageOf(symbol string) int {
+	if leverage := m.leverage[symbol]; leverage > 0 {
+		return leverage
+	}
+	return 1
+}
+
+// mockError 构造模拟交易所错误
+func (m *MockExchange) mockError(kind ErrorKind, msg string) *ExchangeError {
+	return &ExchangeError{Exchange: "Mock", Message: msg, Kind: kind}
+}
+
+// positionPnL 按价格计算持仓中 size 部分的盈亏
+func positionPnL(pos *models.Position, price, size float64) float64 {
+	if pos.Side == "short" {
+		return (pos.EntryPrice - price) * size
+	}
+	return (price - pos.EntryPrice) * size
+}
+
+// splitSymbol 拆分交易对符号（BTC/USDT:USDT -> BTC, USDT）
+func splitSymbol(symbol string) (base, quote string) {
+	symbol = strings.SplitN(symbol, ":", 2)[0]
+	parts := strings.SplitN(symbol, "/", 2)
+	if len(parts) != 2 {
+		return symbol, ""
+	}
+	return parts[0], parts[1]
+}
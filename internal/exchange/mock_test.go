@@ -0,0 +1,175 @@
+package exchange
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"dsbot/internal/config"
+)
+
+func TestMockExchangeFuturesRoundTrip(t *testing.T) {
+	m := NewMockExchange(config.TradingModeFutures)
+	m.SetFeeRate(0, 0)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 1000)
+	m.SetPrice(symbol, 100)
+	if err := m.SetLeverage(symbol, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.PlaceOrder(symbol, "buy", 5, map[string]interface{}{"posSide": "long"}); err != nil {
+		t.Fatalf("开仓失败: %v", err)
+	}
+	if balance, _ := m.FetchBalance("USDT"); balance != 950 {
+		t.Fatalf("开仓后余额 = %.2f, 期望 950", balance)
+	}
+
+	m.SetPrice(symbol, 110)
+	pos, _ := m.FetchPosition(symbol)
+	if pos == nil || pos.Side != "long" || pos.UnrealizedPnL != 50 {
+		t.Fatalf("持仓 = %+v, 期望多头未实现盈亏 50", pos)
+	}
+
+	if _, err := m.PlaceOrder(symbol, "sell", 5, map[string]interface{}{"posSide": "long", "reduceOnly": true}); err != nil {
+		t.Fatalf("平仓失败: %v", err)
+	}
+	if pos, _ := m.FetchPosition(symbol); pos != nil {
+		t.Fatalf("平仓后仍有持仓: %+v", pos)
+	}
+	if balance, _ := m.FetchBalance("USDT"); math.Abs(balance-1050) > 1e-9 {
+		t.Fatalf("平仓后余额 = %.2f, 期望 1050", balance)
+	}
+}
+
+func TestMockExchangeSpotInsufficientBalance(t *testing.T) {
+	m := NewMockExchange(config.TradingModeSpot)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 10)
+	m.SetPrice(symbol, 100)
+
+	_, err := m.PlaceOrder(symbol, "buy", 1, nil)
+	if ErrorKindOf(err) != ErrorKindInsufficientBalance {
+		t.Fatalf("错误分类 = %v, 期望余额不足", ErrorKindOf(err))
+	}
+}
+
+func TestMockExchangeIdempotentClientOrderID(t *testing.T) {
+	m := NewMockExchange(config.TradingModeSpot)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 1000)
+	m.SetPrice(symbol, 100)
+
+	params := map[string]interface{}{ParamClientOrderID: "ds1"}
+	first, _ := m.PlaceOrder(symbol, "buy", 1, params)
+	second, _ := m.PlaceOrder(symbol, "buy", 1, params)
+	if first != second || len(m.Orders()) != 1 {
+		t.Fatalf("重复自定义订单ID产生了多笔订单: %s, %s", first, second)
+	}
+}
+
+func TestMockExchangeInjectedErrors(t *testing.T) {
+	m := NewMockExchange(config.TradingModeFutures)
+	errDown := errors.New("down")
+
+	m.FailNext("FetchBalance", errDown)
+	if _, err := m.FetchBalance("USDT"); !errors.Is(err, errDown) {
+		t.Fatalf("第一次调用错误 = %v, 期望注入错误", err)
+	}
+	if _, err := m.FetchBalance("USDT"); err != nil {
+		t.Fatalf("一次性错误未清除: %v", err)
+	}
+
+	m.SetError("FetchPosition", errDown)
+	for i := 0; i < 2; i++ {
+		if _, err := m.FetchPosition("BTC/USDT:USDT"); !errors.Is(err, errDown) {
+			t.Fatalf("持续错误第%d次 = %v", i+1, err)
+		}
+	}
+	m.SetError("FetchPosition", nil)
+	if _, err := m.FetchPosition("BTC/USDT:USDT"); err != nil {
+		t.Fatalf("持续错误未清除: %v", err)
+	}
+}
+
+func TestMockExchangeSlippageAndAccount(t *testing.T) {
+	m := NewMockExchange(config.TradingModeSpot)
+	m.SetTakerFeeRate(0.001)
+	m.SetSlippage(10)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 1000)
+	m.SetPrice(symbol, 100)
+
+	orderID, err := m.PlaceOrder(symbol, "buy", 1, nil)
+	if err != nil {
+		t.Fatalf("买入失败: %v", err)
+	}
+	if order, _ := m.FetchOrder(symbol, orderID); math.Abs(order.AvgPrice-100.1) > 1e-9 {
+		t.Fatalf("买入成交价 = %.4f, 期望 100.1", order.AvgPrice)
+	}
+
+	// 权益 = 余额 (1000 - 100.1 - 0.1001) + 1 BTC × 100
+	account := m.Account("USDT")
+	if math.Abs(account.Equity-999.7999) > 1e-9 || account.Trades != 1 || math.Abs(account.Fees-0.1001) > 1e-9 {
+		t.Fatalf("模拟账户 = %+v", account)
+	}
+}
+
+func TestMockExchangeHedgeMode(t *testing.T) {
+	m := NewMockExchange(config.TradingModeFutures)
+	m.SetFeeRate(0, 0)
+	m.SetHedgeMode(true)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 1000)
+	m.SetPrice(symbol, 100)
+
+	if _, err := m.PlaceOrder(symbol, "buy", 2, map[string]interface{}{"posSide": "long"}); err != nil {
+		t.Fatalf("开多仓失败: %v", err)
+	}
+	if _, err := m.PlaceOrder(symbol, "sell", 1, map[string]interface{}{"posSide": "short"}); err != nil {
+		t.Fatalf("双向持仓开空仓失败: %v", err)
+	}
+	positions, _ := m.FetchPositions(symbol)
+	if len(positions) != 2 || positions[0].Side != "long" || positions[0].Size != 2 || positions[1].Side != "short" || positions[1].Size != 1 {
+		t.Fatalf("持仓 = %+v, 期望多仓2、空仓1", positions)
+	}
+
+	m.SetPrice(symbol, 110)
+	if _, err := m.PlaceOrder(symbol, "buy", 1, map[string]interface{}{"posSide": "short", "reduceOnly": true}); err != nil {
+		t.Fatalf("平空仓失败: %v", err)
+	}
+	positions, _ = m.FetchPositions(symbol)
+	if len(positions) != 1 || positions[0].Side != "long" || positions[0].UnrealizedPnL != 20 {
+		t.Fatalf("平空仓后持仓 = %+v, 期望仅多仓且未实现盈亏 20", positions)
+	}
+	// 余额 = 1000 - 保证金 200 - 100 + 空仓保证金 100 - 亏损 10
+	if balance, _ := m.FetchBalance("USDT"); math.Abs(balance-790) > 1e-9 {
+		t.Fatalf("平空仓后余额 = %.2f, 期望 790", balance)
+	}
+}
+
+func TestMockExchangeLimitFill(t *testing.T) {
+	m := NewMockExchange(config.TradingModeFutures)
+	symbol := m.ParseSymbols("BTC", "USDT")
+	m.SetBalance("USDT", 1000)
+	m.SetPrice(symbol, 100)
+	m.SetLimitFill(0.4)
+
+	orderID, err := m.PlaceOrder(symbol, "buy", 5, map[string]interface{}{ParamLimitPrice: 101.0})
+	if err != nil {
+		t.Fatalf("限价开仓失败: %v", err)
+	}
+	order, _ := m.FetchOrder(symbol, orderID)
+	if order.State != "partially_filled" || math.Abs(order.FilledSize-2) > 1e-9 {
+		t.Fatalf("订单 = %+v, 期望部分成交 2", order)
+	}
+	if pos, _ := m.FetchPosition(symbol); pos == nil || math.Abs(pos.Size-2) > 1e-9 {
+		t.Fatalf("持仓 = %+v, 期望 2", pos)
+	}
+	if err := m.CancelOrder(symbol, orderID); err != nil {
+		t.Fatalf("撤销部分成交的限价单失败: %v", err)
+	}
+	if order, _ := m.FetchOrder(symbol, orderID); order.State != "canceled" || math.Abs(order.FilledSize-2) > 1e-9 {
+		t.Fatalf("撤单后订单 = %+v, 期望已撤销且保留成交 2", order)
+	}
+}
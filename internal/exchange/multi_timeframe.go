@@ -0,0 +1,50 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"dsbot/internal/models"
+)
+
+// FetchOHLCVMulti 并发获取同一交易对多个周期的K线数据（用于多周期共振分析）
+// 返回按周期索引的K线数据；部分周期失败时仍返回成功的周期，并返回汇总错误
+func FetchOHLCVMulti(exch Exchange, symbol string, timeframes []string, limit int) (map[string][]models.OHLCV, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string][]models.OHLCV, len(timeframes))
+		errs   []error
+	)
+
+	for _, tf := range timeframes {
+		mu.Lock()
+		_, seen := result[tf]
+		if !seen {
+			result[tf] = nil // 占位，避免重复周期重复请求
+		}
+		mu.Unlock()
+		if seen {
+			continue
+		}
+
+		wg.Add(1)
+		go func(timeframe string) {
+			defer wg.Done()
+			data, err := exch.FetchOHLCV(symbol, timeframe, limit)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				delete(result, timeframe)
+				errs = append(errs, fmt.Errorf("获取%s K线失败: %w", timeframe, err))
+				return
+			}
+			result[timeframe] = data
+		}(tf)
+	}
+
+	wg.Wait()
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,154 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// ohlcvCacheKey K线缓存键（交易对+周期）
+type ohlcvCacheKey struct {
+	symbol    string
+	timeframe string
+}
+
+// ohlcvCacheEntry 单个交易对/周期的K线缓存
+type ohlcvCacheEntry struct {
+	candles []models.OHLCV // 按时间升序
+	limit   int            // 全量加载时请求的数量
+	source  string         // 数据来源（来源变化时全量重新加载）
+}
+
+// CachedExchange K线增量缓存包装 - 按交易对和周期缓存K线，
+// 每次只拉取上次最后一根K线之后的新数据并追加，检测到断档时全量重新加载
+type CachedExchange struct {
+	Exchange
+
+	mu      sync.Mutex
+	entries map[ohlcvCacheKey]*ohlcvCacheEntry
+}
+
+// NewCachedExchange 创建带K线增量缓存的交易所
+func NewCachedExchange(inner Exchange) *CachedExchange {
+	return &CachedExchange{
+		Exchange: inner,
+		entries:  make(map[ohlcvCacheKey]*ohlcvCacheEntry),
+	}
+}
+
+// FetchOHLCV 获取K线数据（优先增量更新缓存）
+func (e *CachedExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	key := ohlcvCacheKey{symbol: symbol, timeframe: timeframe}
+
+	e.mu.Lock()
+	entry := e.entries[key]
+	e.mu.Unlock()
+
+	if entry != nil && entry.limit >= limit {
+		if candles, ok := e.fetchIncremental(key, entry, limit); ok {
+			return candles, nil
+		}
+	}
+
+	return e.fetchFull(key, limit)
+}
+
+// fetchIncremental 增量拉取新K线并合并到缓存，无法安全合并时返回false
+func (e *CachedExchange) fetchIncremental(key ohlcvCacheKey, entry *ohlcvCacheEntry, limit int) ([]models.OHLCV, bool) {
+	cached := entry.candles
+	if len(cached) < 2 {
+		return nil, false
+	}
+
+	// 用缓存中最后两根K线的间隔推算周期，估算需要拉取的数量（多取一根用于重叠校验）
+	last := cached[len(cached)-1]
+	step := last.Timestamp.Sub(cached[len(cached)-2].Timestamp)
+	if step <= 0 {
+		return nil, false
+	}
+	missing := int(time.Since(last.Timestamp)/step) + 2
+	if missing >= limit {
+		return nil, false
+	}
+
+	fresh, err := e.Exchange.FetchOHLCV(key.symbol, key.timeframe, missing)
+	if err != nil || len(fresh) == 0 {
+		return nil, false
+	}
+	if OHLCVSourceOf(e.Exchange, key.symbol) != entry.source {
+		logger.Debugf("[DEBUG] %s %s K线数据来源变化，全量重新加载", key.symbol, key.timeframe)
+		return nil, false
+	}
+
+	// 新数据必须与缓存重叠（首根不晚于缓存最后一根），否则中间存在缺失
+	if fresh[0].Timestamp.After(last.Timestamp) {
+		logger.Debugf("[DEBUG] %s %s K线缓存与新数据之间存在断档，全量重新加载", key.symbol, key.timeframe)
+		return nil, false
+	}
+
+	// 保留早于新数据的缓存部分，用新数据覆盖重叠部分（最后一根可能是未收盘K线）
+	cut := len(cached)
+	for cut > 0 && !cached[cut-1].Timestamp.Before(fresh[0].Timestamp) {
+		cut--
+	}
+	if cut == 0 {
+		return nil, false
+	}
+	merged := make([]models.OHLCV, 0, cut+len(fresh))
+	merged = append(merged, cached[:cut]...)
+	merged = append(merged, fresh...)
+
+	// 校验相邻K线间隔一致
+	for i := cut; i < len(merged); i++ {
+		if merged[i].Timestamp.Sub(merged[i-1].Timestamp) != step {
+			logger.Debugf("[DEBUG] %s %s K线间隔异常 (%s)，全量重新加载",
+				key.symbol, key.timeframe, merged[i].Timestamp.Format("2006-01-02 15:04"))
+			return nil, false
+		}
+	}
+
+	if len(merged) > entry.limit {
+		merged = merged[len(merged)-entry.limit:]
+	}
+	e.store(key, &ohlcvCacheEntry{candles: merged, limit: entry.limit, source: entry.source})
+
+	logger.Debugf("[DEBUG] %s %s K线增量更新: 拉取 %d 根", key.symbol, key.timeframe, len(fresh))
+	return lastCandles(merged, limit), true
+}
+
+// fetchFull 全量拉取K线并重建缓存
+func (e *CachedExchange) fetchFull(key ohlcvCacheKey, limit int) ([]models.OHLCV, error) {
+	candles, err := e.Exchange.FetchOHLCV(key.symbol, key.timeframe, limit)
+	if err != nil {
+		return nil, err
+	}
+	e.store(key, &ohlcvCacheEntry{
+		candles: candles,
+		limit:   limit,
+		source:  OHLCVSourceOf(e.Exchange, key.symbol),
+	})
+	return lastCandles(candles, limit), nil
+}
+
+func (e *CachedExchange) store(key ohlcvCacheKey, entry *ohlcvCacheEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries[key] = entry
+}
+
+// OHLCVSource 交易对最近一次K线数据的来源（透传内层交易所）
+func (e *CachedExchange) OHLCVSource(symbol string) string {
+	return OHLCVSourceOf(e.Exchange, symbol)
+}
+
+// lastCandles 复制最后n根K线（调用方修改返回值不影响缓存）
+func lastCandles(candles []models.OHLCV, n int) []models.OHLCV {
+	if n > 0 && len(candles) > n {
+		candles = candles[len(candles)-n:]
+	}
+	out := make([]models.OHLCV, len(candles))
+	copy(out, candles)
+	return out
+}
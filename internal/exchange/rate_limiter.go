@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	rate   float64   // 每秒补充的令牌数
+	burst  float64   // 桶容量
+	tokens float64   // 当前令牌数
+	last   time.Time // 上次补充时间
+	mu     sync.Mutex
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve 尝试取一个令牌，返回需要等待的时间（0表示已取到）
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// RateLimiter 按接口分组的请求限流器
+type RateLimiter struct {
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter 创建限流器
+// defaults: 交易所默认限流配置，overrides: 用户配置（按分组覆盖默认值）
+func NewRateLimiter(defaults, overrides map[string]config.RateLimitConfig) *RateLimiter {
+	limits := make(map[string]config.RateLimitConfig, len(defaults))
+	for group, limit := range defaults {
+		limits[group] = limit
+	}
+	for group, limit := range overrides {
+		limits[group] = limit
+	}
+
+	buckets := make(map[string]*tokenBucket, len(limits))
+	for group, limit := range limits {
+		if limit.RequestsPerSecond <= 0 {
+			continue // 不限流
+		}
+		buckets[group] = newTokenBucket(limit.RequestsPerSecond, limit.Burst)
+	}
+
+	return &RateLimiter{buckets: buckets}
+}
+
+// Wait 阻塞直到该分组允许发起请求
+func (l *RateLimiter) Wait(group string) {
+	bucket, ok := l.buckets[group]
+	if !ok {
+		return
+	}
+
+	for {
+		wait := bucket.reserve()
+		if wait == 0 {
+			return
+		}
+		logger.Debugf("[限流] 接口分组 %s 达到限流，等待 %s", group, wait)
+		time.Sleep(wait)
+	}
+}
@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// retryPolicy 指数退避重试策略
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	jitter         float64
+}
+
+// newRetryPolicy 根据配置创建重试策略（未配置项使用默认值）
+func newRetryPolicy(cfg config.RetryConfig) *retryPolicy {
+	p := &retryPolicy{
+		maxAttempts:    3,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     5 * time.Second,
+		jitter:         0.2,
+	}
+	if cfg.MaxAttempts > 0 {
+		p.maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoffMs > 0 {
+		p.initialBackoff = time.Duration(cfg.InitialBackoffMs) * time.Millisecond
+	}
+	if cfg.MaxBackoffMs > 0 {
+		p.maxBackoff = time.Duration(cfg.MaxBackoffMs) * time.Millisecond
+	}
+	if cfg.Jitter > 0 && cfg.Jitter <= 1 {
+		p.jitter = cfg.Jitter
+	}
+	return p
+}
+
+// backoff 计算第 attempt 次重试前的等待时间（attempt 从1开始）
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	wait := p.initialBackoff << (attempt - 1)
+	if wait > p.maxBackoff || wait <= 0 {
+		wait = p.maxBackoff
+	}
+	if p.jitter > 0 {
+		delta := float64(wait) * p.jitter
+		wait += time.Duration((rand.Float64()*2 - 1) * delta)
+	}
+	return wait
+}
+
+// do 执行操作，遇到临时性错误时按退避策略重试
+func (p *retryPolicy) do(name string, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt == p.maxAttempts {
+			break
+		}
+
+		wait := p.backoff(attempt)
+		logger.Warnf("[重试] %s 失败 (第%d/%d次): %v，%s 后重试", name, attempt, p.maxAttempts, err, wait.Round(time.Millisecond))
+		time.Sleep(wait)
+	}
+	return fmt.Errorf("重试%d次后仍失败: %w", p.maxAttempts, err)
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// PrimaryDataSourceReporter 可选接口：报告交易对的主行情来源（行情与下单不在同一交易所时实现）
+type PrimaryDataSourceReporter interface {
+	PrimaryDataSource() string
+}
+
+// PrimaryDataSourceOf 获取主行情来源名称（K线来源与之不同时视为备用数据）
+func PrimaryDataSourceOf(exch Exchange) string {
+	if r, ok := exch.(PrimaryDataSourceReporter); ok {
+		return r.PrimaryDataSource()
+	}
+	return exch.GetExchangeName()
+}
+
+// RoutedExchange 行情路由包装 - 行情接口（K线、行情、盘口）使用指定的数据源，
+// 账户和交易接口使用下单交易所
+type RoutedExchange struct {
+	Exchange
+	data MarketDataSource
+}
+
+// NewRoutedExchange 创建行情与下单分离的交易所
+func NewRoutedExchange(execution Exchange, data MarketDataSource) *RoutedExchange {
+	return &RoutedExchange{Exchange: execution, data: data}
+}
+
+// FetchOHLCV 从行情数据源获取K线
+func (e *RoutedExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	return e.data.FetchOHLCV(symbol, timeframe, limit)
+}
+
+// FetchTicker 从行情数据源获取最新行情
+func (e *RoutedExchange) FetchTicker(symbol string) (*models.Ticker, error) {
+	return e.data.FetchTicker(symbol)
+}
+
+// FetchOrderBook 从行情数据源获取盘口深度
+func (e *RoutedExchange) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	return e.data.FetchOrderBook(symbol, depth)
+}
+
+// OHLCVSource 交易对最近一次K线数据的来源（数据源为交易所时透传其备用行情来源）
+func (e *RoutedExchange) OHLCVSource(symbol string) string {
+	if src, ok := e.data.(*exchangeDataSource); ok {
+		return OHLCVSourceOf(src.Exchange, symbol)
+	}
+	return e.data.Name()
+}
+
+// PrimaryDataSource 主行情来源
+func (e *RoutedExchange) PrimaryDataSource() string {
+	return e.data.Name()
+}
+
+// NewVenueDataSource 根据交易所配置创建行情数据源
+// binance 使用公共行情接口（无需API Key），其他交易所使用完整客户端的行情接口
+func NewVenueDataSource(cfg *config.APIConfig, tradingMode config.TradingMode) (MarketDataSource, error) {
+	if cfg.ExchangeType == string(config.ExchangeBinance) {
+		return NewMarketDataSource(cfg.ExchangeType, tradingMode)
+	}
+	exch, err := NewExchange(cfg, tradingMode)
+	if err != nil {
+		return nil, err
+	}
+	return NewExchangeDataSource(exch), nil
+}
+
+// exchangeDataSource 以交易所的行情接口作为行情数据源
+type exchangeDataSource struct {
+	Exchange
+}
+
+// NewExchangeDataSource 将交易所包装为行情数据源
+func NewExchangeDataSource(exch Exchange) MarketDataSource {
+	return &exchangeDataSource{Exchange: exch}
+}
+
+// Name 数据源名称
+func (s *exchangeDataSource) Name() string {
+	return s.GetExchangeName()
+}
@@ -29,6 +29,11 @@ func NewCalculatorWithConfig(config *IndicatorConfig) *Calculator {
 	}
 }
 
+// Config 当前技术指标参数配置（副本）
+func (c *Calculator) Config() IndicatorConfig {
+	return *c.config
+}
+
 // Calculate 计算所有技术指标
 func (c *Calculator) Calculate(ohlcvList []models.OHLCV) *models.TechnicalData {
 	if len(ohlcvList) == 0 {
@@ -79,6 +84,12 @@ func (c *Calculator) Calculate(ohlcvList []models.OHLCV) *models.TechnicalData {
 		data.VolumeRatio = c.config.DefaultVolumeRatio
 	}
 
+	// ATR - 平均真实波幅及其占价格的百分比
+	data.ATR = c.calculateATR(ohlcvList, c.config.ATRPeriod)
+	if currentPrice := closes[len(closes)-1]; currentPrice > 0 {
+		data.ATRPercent = data.ATR / currentPrice * 100
+	}
+
 	// 支撑阻力位 - 使用最近N个周期的最高最低点
 	lookbackPeriod := c.config.SupportResistanceLookback
 	if len(highs) < lookbackPeriod {
@@ -190,6 +201,21 @@ func (c *Calculator) CalculateLevelsAnalysis(ohlcvList []models.OHLCV, tech *mod
 	}
 }
 
+// CalculateTimeframeContext 计算大周期的趋势和支撑阻力摘要（多周期分析）
+func (c *Calculator) CalculateTimeframeContext(timeframe string, ohlcvList []models.OHLCV) *models.TimeframeContext {
+	tech := c.Calculate(ohlcvList)
+	if tech == nil {
+		return nil
+	}
+	return &models.TimeframeContext{
+		Timeframe:  timeframe,
+		Trend:      c.CalculateTrendAnalysis(ohlcvList, tech),
+		Levels:     c.CalculateLevelsAnalysis(ohlcvList, tech),
+		RSI:        tech.RSI,
+		ATRPercent: tech.ATRPercent,
+	}
+}
+
 // SMA 简单移动平均线
 func (c *Calculator) calculateSMA(values []float64, period int) float64 {
 	if len(values) == 0 {
@@ -337,6 +363,33 @@ func (c *Calculator) calculateRSI(values []float64, period int) float64 {
 	return rsi
 }
 
+// calculateATR 计算平均真实波幅（Wilder平滑），数据不足时返回0
+func (c *Calculator) calculateATR(ohlcvList []models.OHLCV, period int) float64 {
+	if period <= 0 || len(ohlcvList) < period+1 {
+		return 0
+	}
+
+	// 真实波幅 = max(最高-最低, |最高-前收|, |最低-前收|)
+	trueRanges := make([]float64, len(ohlcvList)-1)
+	for i := 1; i < len(ohlcvList); i++ {
+		prevClose := ohlcvList[i-1].Close
+		trueRanges[i-1] = math.Max(ohlcvList[i].High-ohlcvList[i].Low,
+			math.Max(math.Abs(ohlcvList[i].High-prevClose), math.Abs(ohlcvList[i].Low-prevClose)))
+	}
+
+	// 第一个周期使用 SMA，后续使用平滑方法
+	atr := 0.0
+	for i := 0; i < period; i++ {
+		atr += trueRanges[i]
+	}
+	atr /= float64(period)
+	for i := period; i < len(trueRanges); i++ {
+		atr = (atr*float64(period-1) + trueRanges[i]) / float64(period)
+	}
+
+	return atr
+}
+
 // BollingerBands 布林带
 func (c *Calculator) calculateBollingerBands(values []float64, period int, stdDev float64) (middle, upper, lower float64) {
 	if len(values) == 0 {
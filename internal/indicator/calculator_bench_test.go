@@ -0,0 +1,61 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"dsbot/internal/models"
+)
+
+// benchmarkDataPoints 基准测试的K线数量（覆盖默认值到分页获取的上限）
+var benchmarkDataPoints = []int{100, 500, 1000, 5000}
+
+// syntheticOHLCV 生成确定性的模拟K线（正弦波动叠加缓慢趋势）
+func syntheticOHLCV(n int) []models.OHLCV {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ohlcvList := make([]models.OHLCV, n)
+	price := 50000.0
+	for i := range ohlcvList {
+		open := price
+		price = open * (1 + 0.002*math.Sin(float64(i)/7) + 0.0001)
+		ohlcvList[i] = models.OHLCV{
+			Timestamp: start.Add(time.Duration(i) * 15 * time.Minute),
+			Open:      open,
+			High:      math.Max(open, price) * 1.001,
+			Low:       math.Min(open, price) * 0.999,
+			Close:     price,
+			Volume:    100 + 50*math.Cos(float64(i)/5),
+		}
+	}
+	return ohlcvList
+}
+
+func BenchmarkCalculate(b *testing.B) {
+	calc := NewCalculatorWithConfig(AggressiveConfig())
+	for _, n := range benchmarkDataPoints {
+		ohlcvList := syntheticOHLCV(n)
+		b.Run(fmt.Sprintf("points=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				calc.Calculate(ohlcvList)
+			}
+		})
+	}
+}
+
+func BenchmarkCalculateAll(b *testing.B) {
+	calc := NewCalculatorWithConfig(AggressiveConfig())
+	for _, n := range benchmarkDataPoints {
+		ohlcvList := syntheticOHLCV(n)
+		b.Run(fmt.Sprintf("points=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tech := calc.Calculate(ohlcvList)
+				calc.CalculateTrendAnalysis(ohlcvList, tech)
+				calc.CalculateLevelsAnalysis(ohlcvList, tech)
+			}
+		})
+	}
+}
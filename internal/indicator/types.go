@@ -36,6 +36,9 @@ type IndicatorConfig struct {
 
 	// 支撑阻力参数
 	SupportResistanceLookback int // 支撑阻力位回溯周期
+
+	// ATR 参数
+	ATRPeriod int // 平均真实波幅周期
 }
 
 // DefaultConfig 返回默认的技术指标配置
@@ -75,6 +78,9 @@ func DefaultConfig() *IndicatorConfig {
 
 		// 支撑阻力参数
 		SupportResistanceLookback: 20,
+
+		// ATR 周期
+		ATRPeriod: 14,
 	}
 }
 
@@ -115,6 +121,9 @@ func AggressiveConfig() *IndicatorConfig {
 
 		// 更短的支撑阻力回溯
 		SupportResistanceLookback: 15,
+
+		// ATR 周期
+		ATRPeriod: 10,
 	}
 }
 
@@ -155,5 +164,8 @@ func ConservativeConfig() *IndicatorConfig {
 
 		// 更长的支撑阻力回溯
 		SupportResistanceLookback: 30,
+
+		// ATR 周期
+		ATRPeriod: 21,
 	}
 }
@@ -0,0 +1,368 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"dsbot/internal/buildinfo"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+const DefaultFile = "data/journal.json"
+
+// Entry 交易日志条目（一次开仓到平仓）
+type Entry struct {
+	ID          uint64    `json:"id"`
+	TradingPair string    `json:"trading_pair"`
+	Side        string    `json:"side"`        // "long" or "short"
+	Confidence  string    `json:"confidence"`  // 开仓信号信心 "HIGH", "MEDIUM", "LOW"
+	Regime      string    `json:"regime"`      // 开仓时的市场状态（整体趋势）
+	Reason      string    `json:"reason"`      // 开仓理由
+	EntryPrice  float64   `json:"entry_price"` // 开仓成交均价
+	Size        float64   `json:"size"`
+	OpenedAt    time.Time `json:"opened_at"` // 开仓成交时间
+
+	// 订单ID（括号单包含交易所端止损、止盈、移动止损委托）
+	EntryOrderID      string `json:"entry_order_id,omitempty"`
+	StopLossOrderID   string `json:"stop_loss_order_id,omitempty"`   // 止损委托自定义ID
+	TakeProfitOrderID string `json:"take_profit_order_id,omitempty"` // 止盈委托自定义ID
+	TrailingOrderID   string `json:"trailing_order_id,omitempty"`    // 交易所端移动止损委托自定义ID
+
+	// 执行延迟分析（对比在K线收盘时理想执行）
+	SignalTime  time.Time `json:"signal_time,omitempty"`  // 信号对应的K线收盘时间
+	SignalPrice float64   `json:"signal_price,omitempty"` // K线收盘价（理想执行价）
+	DecidedAt   time.Time `json:"decided_at,omitempty"`   // AI给出决策的时间
+
+	Closed     bool      `json:"closed"`
+	ExitPrice  float64   `json:"exit_price,omitempty"`
+	ExitReason string    `json:"exit_reason,omitempty"`
+	ClosedAt   time.Time `json:"closed_at,omitempty"`
+
+	// 手续费（计价币种，正数表示支出）与收益
+	EntryFee       float64 `json:"entry_fee"`
+	ExitFee        float64 `json:"exit_fee"`
+	GrossReturnPct float64 `json:"gross_return_pct"` // 按交易方向计算的价格收益率（%，不含杠杆和手续费）
+	ReturnPct      float64 `json:"return_pct"`       // 扣除开平仓手续费后的收益率（%，不含杠杆）
+	NetPnL         float64 `json:"net_pnl"`          // 扣除手续费后的盈亏（计价币种）
+
+	Build         *buildinfo.Stamp `json:"build,omitempty"`          // 开仓时运行的版本和配置
+	PromptVariant string           `json:"prompt_variant,omitempty"` // 开仓信号的提示词变体（启用提示词A/B实验时）
+
+	PostMortem *models.PostMortem `json:"post_mortem,omitempty"` // AI撰写的平仓复盘（可选）
+}
+
+// Filter 条目筛选条件（空字段表示不限制）
+type Filter struct {
+	TradingPair string
+	Side        string
+	Confidence  string
+	Regime      string
+}
+
+// match 判断条目是否满足筛选条件
+func (f Filter) match(e *Entry) bool {
+	return (f.TradingPair == "" || f.TradingPair == e.TradingPair) &&
+		(f.Side == "" || f.Side == e.Side) &&
+		(f.Confidence == "" || f.Confidence == e.Confidence) &&
+		(f.Regime == "" || f.Regime == e.Regime)
+}
+
+// Stats 已平仓条目统计
+type Stats struct {
+	Count      int     // 样本数
+	Wins       int     // 盈利次数
+	WinRate    float64 // 胜率（%）
+	Expectancy float64 // 平均收益率（%），即经验期望值
+}
+
+// journalState 持久化状态
+type journalState struct {
+	NextID  uint64   `json:"next_id"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Journal 交易日志 - 记录每笔交易的开平仓信息，持久化到文件
+type Journal struct {
+	file  string
+	state journalState
+	build *buildinfo.Stamp // 新开仓条目的版本标记（可选）
+	mu    sync.Mutex
+}
+
+// NewJournal 创建交易日志，并从文件恢复
+func NewJournal(file string) (*Journal, error) {
+	if file == "" {
+		file = DefaultFile
+	}
+
+	j := &Journal{file: file}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// SetBuild 设置版本标记，之后开仓的条目都记录该标记
+func (j *Journal) SetBuild(stamp buildinfo.Stamp) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.build = &stamp
+}
+
+// Open 记录开仓，返回条目ID
+func (j *Journal) Open(entry Entry) uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.state.NextID++
+	entry.ID = j.state.NextID
+	if entry.OpenedAt.IsZero() {
+		entry.OpenedAt = time.Now()
+	}
+	if entry.Build == nil && j.build != nil {
+		stamp := *j.build
+		entry.Build = &stamp
+	}
+	j.state.Entries = append(j.state.Entries, &entry)
+	j.saveLocked()
+
+	logger.Debugf("[交易日志] 开仓记录 #%d - %s %s @ %.2f", entry.ID, entry.TradingPair, entry.Side, entry.EntryPrice)
+	return entry.ID
+}
+
+// Close 记录交易对最近一笔未平仓条目的平仓，没有未平仓条目时返回nil
+// exitFee: 平仓手续费（计价币种，正数表示支出）
+func (j *Journal) Close(tradingPair string, exitPrice, exitFee float64, reason string) *Entry {
+	return j.CloseLeg(tradingPair, "", exitPrice, exitFee, reason)
+}
+
+// CloseLeg 记录交易对指定方向最近一笔未平仓条目的平仓（双向持仓多空分别记录，side 为空时不区分方向）
+func (j *Journal) CloseLeg(tradingPair, side string, exitPrice, exitFee float64, reason string) *Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry := j.lastOpenLocked(tradingPair, side)
+	if entry == nil {
+		return nil
+	}
+
+	entry.settle(exitPrice, exitFee, reason, time.Now())
+	j.saveLocked()
+
+	logger.Printf("[交易日志] 平仓记录 #%d - %s %s, 开仓价:%.2f, 平仓价:%.2f, 价格收益率:%+.2f%%, 手续费:%.4f, 净收益率:%+.2f%%, 净盈亏:%.4f (%s)",
+		entry.ID, entry.TradingPair, entry.Side, entry.EntryPrice, exitPrice, entry.GrossReturnPct,
+		entry.EntryFee+entry.ExitFee, entry.ReturnPct, entry.NetPnL, reason)
+
+	copied := *entry
+	return &copied
+}
+
+// settle 记录平仓并计算收益（扣除开平仓手续费）
+func (e *Entry) settle(exitPrice, exitFee float64, reason string, closedAt time.Time) {
+	e.Closed = true
+	e.ExitPrice = exitPrice
+	e.ExitReason = reason
+	e.ExitFee = exitFee
+	e.ClosedAt = closedAt
+	if e.EntryPrice > 0 {
+		direction := 1.0
+		if e.Side == "short" {
+			direction = -1.0
+		}
+		fees := e.EntryFee + e.ExitFee
+		e.GrossReturnPct = direction * (exitPrice - e.EntryPrice) / e.EntryPrice * 100
+		e.NetPnL = direction*(exitPrice-e.EntryPrice)*e.Size - fees
+		e.ReturnPct = e.GrossReturnPct
+		if notional := e.EntryPrice * e.Size; notional > 0 {
+			e.ReturnPct -= fees / notional * 100
+		}
+	}
+}
+
+// Import 导入历史条目（如交易所成交记录回填），按开仓单ID去重，返回实际导入数量
+// 已平仓条目按 ExitPrice/ExitFee 重新计算收益；导入后按开仓时间排序
+func (j *Journal) Import(entries []Entry) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	existing := make(map[string]bool, len(j.state.Entries))
+	for _, e := range j.state.Entries {
+		if e.EntryOrderID != "" {
+			existing[e.EntryOrderID] = true
+		}
+	}
+
+	imported := 0
+	for _, entry := range entries {
+		if entry.EntryOrderID != "" && existing[entry.EntryOrderID] {
+			continue
+		}
+		if entry.Closed {
+			entry.settle(entry.ExitPrice, entry.ExitFee, entry.ExitReason, entry.ClosedAt)
+		}
+		j.state.NextID++
+		entry.ID = j.state.NextID
+		j.state.Entries = append(j.state.Entries, &entry)
+		existing[entry.EntryOrderID] = true
+		imported++
+	}
+	if imported == 0 {
+		return 0
+	}
+
+	sort.SliceStable(j.state.Entries, func(a, b int) bool {
+		return j.state.Entries[a].OpenedAt.Before(j.state.Entries[b].OpenedAt)
+	})
+	j.saveLocked()
+
+	logger.Printf("[交易日志] 已导入 %d 条历史记录", imported)
+	return imported
+}
+
+// SetPostMortem 为已平仓条目写入复盘，条目不存在时返回false
+func (j *Journal) SetPostMortem(id uint64, postMortem *models.PostMortem) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, e := range j.state.Entries {
+		if e.ID == id {
+			e.PostMortem = postMortem
+			j.saveLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// OpenEntry 获取交易对最近一笔未平仓条目
+func (j *Journal) OpenEntry(tradingPair string) *Entry {
+	return j.OpenLeg(tradingPair, "")
+}
+
+// OpenLeg 获取交易对指定方向最近一笔未平仓条目（side 为空时不区分方向）
+func (j *Journal) OpenLeg(tradingPair, side string) *Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry := j.lastOpenLocked(tradingPair, side)
+	if entry == nil {
+		return nil
+	}
+	copied := *entry
+	return &copied
+}
+
+// Entries 获取全部条目快照
+func (j *Journal) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]Entry, 0, len(j.state.Entries))
+	for _, e := range j.state.Entries {
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// Stats 统计满足条件的已平仓条目
+func (j *Journal) Stats(filter Filter) Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var stats Stats
+	var total float64
+	for _, e := range j.state.Entries {
+		if !e.Closed || !filter.match(e) {
+			continue
+		}
+		stats.Count++
+		total += e.ReturnPct
+		if e.ReturnPct > 0 {
+			stats.Wins++
+		}
+	}
+	if stats.Count > 0 {
+		stats.WinRate = float64(stats.Wins) / float64(stats.Count) * 100
+		stats.Expectancy = total / float64(stats.Count)
+	}
+	return stats
+}
+
+// lastOpenLocked 查找最近一笔未平仓条目，side 为空时不区分方向（调用方需持有锁）
+func (j *Journal) lastOpenLocked(tradingPair, side string) *Entry {
+	for i := len(j.state.Entries) - 1; i >= 0; i-- {
+		e := j.state.Entries[i]
+		if !e.Closed && e.TradingPair == tradingPair && (side == "" || e.Side == side) {
+			return e
+		}
+	}
+	return nil
+}
+
+// load 从文件恢复
+func (j *Journal) load() error {
+	data, err := os.ReadFile(j.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, &j.state); err != nil {
+		return err
+	}
+	logger.Printf("[交易日志] 已恢复 %d 条记录", len(j.state.Entries))
+	return nil
+}
+
+// saveLocked 保存到文件（调用方需持有锁，失败仅记录日志）
+func (j *Journal) saveLocked() {
+	if err := os.MkdirAll(filepath.Dir(j.file), 0755); err != nil {
+		logger.Warnf("[交易日志] 创建目录失败: %v", err)
+		return
+	}
+
+	data, err := json.MarshalIndent(j.state, "", "  ")
+	if err != nil {
+		logger.Warnf("[交易日志] 序列化失败: %v", err)
+		return
+	}
+
+	tmpFile := j.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		logger.Warnf("[交易日志] 保存失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmpFile, j.file); err != nil {
+		logger.Warnf("[交易日志] 保存失败: %v", err)
+	}
+}
+
+// Totals 已实现盈亏汇总
+type Totals struct {
+	Trades int     // 已平仓笔数
+	Fees   float64 // 开平仓手续费合计
+	NetPnL float64 // 扣除手续费后的净盈亏合计
+}
+
+// Realized 汇总交易对（为空表示全部交易对）在 since 及之后平仓的已实现盈亏，since 为零值时不限时间
+func (j *Journal) Realized(tradingPair string, since time.Time) Totals {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var totals Totals
+	for _, e := range j.state.Entries {
+		if !e.Closed || (tradingPair != "" && e.TradingPair != tradingPair) || e.ClosedAt.Before(since) {
+			continue
+		}
+		totals.Trades++
+		totals.Fees += e.EntryFee + e.ExitFee
+		totals.NetPnL += e.NetPnL
+	}
+	return totals
+}
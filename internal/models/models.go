@@ -2,6 +2,9 @@ package models
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +24,132 @@ type Ticker struct {
 	Last   float64 // 最新成交价
 	Bid    float64 // 买一价
 	Ask    float64 // 卖一价
+	Mark   float64 // 标记价格（合约，交易所以此计算强平和未实现盈亏，未获取时为0）
+	Index  float64 // 指数价格（合约，未获取时为0）
+}
+
+// 价格来源
+const (
+	PriceSourceLast  = "last"
+	PriceSourceMark  = "mark"
+	PriceSourceIndex = "index"
+)
+
+// Price 按价格来源获取价格（标记/指数价格不可用时回退到最新成交价）
+func (t *Ticker) Price(source string) float64 {
+	switch source {
+	case PriceSourceMark:
+		if t.Mark > 0 {
+			return t.Mark
+		}
+	case PriceSourceIndex:
+		if t.Index > 0 {
+			return t.Index
+		}
+	}
+	return t.Last
+}
+
+// AccountSummary 账户权益与保证金概况（以计价币种计）
+type AccountSummary struct {
+	Currency          string
+	TotalEquity       float64 // 账户权益（余额 + 未实现盈亏）
+	AvailableMargin   float64 // 可用保证金
+	MaintenanceMargin float64 // 维持保证金（现货为0）
+}
+
+// MarginRatio 维持保证金率（%，维持保证金 / 账户权益，越高越危险，达到100%时触发强平；权益未知时返回0）
+func (a *AccountSummary) MarginRatio() float64 {
+	if a.TotalEquity <= 0 {
+		return 0
+	}
+	return a.MaintenanceMargin / a.TotalEquity * 100
+}
+
+// FeeRate 交易手续费率（正数表示支出，如 0.0005 表示 0.05%，负数表示返佣）
+type FeeRate struct {
+	Symbol string
+	Maker  float64 // 挂单费率
+	Taker  float64 // 吃单费率
+}
+
+// OrderBookLevel 盘口档位
+type OrderBookLevel struct {
+	Price float64
+	Size  float64 // 数量（基础币种）
+}
+
+// OrderBook 盘口深度
+type OrderBook struct {
+	Symbol    string
+	Bids      []OrderBookLevel // 买盘（价格从高到低）
+	Asks      []OrderBookLevel // 卖盘（价格从低到高）
+	Timestamp time.Time
+}
+
+// MidPrice 中间价（盘口为空时返回0）
+func (b *OrderBook) MidPrice() float64 {
+	if len(b.Bids) == 0 || len(b.Asks) == 0 {
+		return 0
+	}
+	return (b.Bids[0].Price + b.Asks[0].Price) / 2
+}
+
+// SpreadBps 买卖价差（基点，盘口为空时返回0）
+func (b *OrderBook) SpreadBps() float64 {
+	mid := b.MidPrice()
+	if mid == 0 {
+		return 0
+	}
+	return (b.Asks[0].Price - b.Bids[0].Price) / mid * 10000
+}
+
+// Imbalance 前 levels 档买卖盘失衡度，范围 -1~1（正数表示买盘更强，levels<=0 表示全部档位）
+func (b *OrderBook) Imbalance(levels int) float64 {
+	var bidVol, askVol float64
+	for i, l := range b.Bids {
+		if levels > 0 && i >= levels {
+			break
+		}
+		bidVol += l.Size
+	}
+	for i, l := range b.Asks {
+		if levels > 0 && i >= levels {
+			break
+		}
+		askVol += l.Size
+	}
+	if bidVol+askVol == 0 {
+		return 0
+	}
+	return (bidVol - askVol) / (bidVol + askVol)
+}
+
+// EstimateFill 估算市价单吃单成交均价
+// side: "buy" 吃卖盘, "sell" 吃买盘; size: 数量（基础币种）
+// 返回成交均价和可成交数量（盘口深度不足时可成交数量小于 size）
+func (b *OrderBook) EstimateFill(side string, size float64) (avgPrice, filled float64) {
+	levels := b.Asks
+	if side == "sell" {
+		levels = b.Bids
+	}
+
+	var cost float64
+	for _, l := range levels {
+		take := l.Size
+		if remaining := size - filled; take > remaining {
+			take = remaining
+		}
+		cost += take * l.Price
+		filled += take
+		if filled >= size {
+			break
+		}
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return cost / filled, filled
 }
 
 // TechnicalData 技术指标数据
@@ -42,6 +171,8 @@ type TechnicalData struct {
 	VolumeRatio   float64
 	Resistance    float64
 	Support       float64
+	ATR           float64 // 平均真实波幅
+	ATRPercent    float64 // ATR占当前价格的百分比
 }
 
 // TrendAnalysis 趋势分析
@@ -65,50 +196,313 @@ type LevelsAnalysis struct {
 
 // MarketData 市场数据
 type MarketData struct {
-	Price          float64
-	Timestamp      string
-	High           float64
-	Low            float64
-	Volume         float64
-	Timeframe      string
-	PriceChange    float64
-	KlineData      []OHLCV
-	TechnicalData  *TechnicalData
-	TrendAnalysis  *TrendAnalysis
-	LevelsAnalysis *LevelsAnalysis
+	Price            float64
+	Timestamp        string
+	High             float64
+	Low              float64
+	Volume           float64
+	Timeframe        string
+	PriceChange      float64
+	KlineData        []OHLCV
+	TechnicalData    *TechnicalData
+	TrendAnalysis    *TrendAnalysis
+	LevelsAnalysis   *LevelsAnalysis
+	OrderBook        *OrderBook         // 盘口深度（获取失败时为nil）
+	DataSource       string             // K线数据来源（交易所名称，或主交易所故障时的备用数据源名称）
+	IsFallbackData   bool               // K线数据是否来自备用数据源
+	News             []NewsHeadline     // 近期新闻标题（按发布时间从新到旧，未启用新闻源时为nil）
+	FearGreed        *FearGreedIndex    // 恐惧贪婪指数（未启用或获取失败时为nil）
+	Derivatives      *DerivativesStats  // 永续合约资金费率、持仓量和基差（现货模式或获取失败时为nil）
+	HigherTimeframes []TimeframeContext // 大周期趋势和支撑阻力（未启用多周期分析时为nil）
+
+	SpotBasis *SpotBasis // 合约相对现货的溢价（未启用基差监控或获取失败时为nil）
+}
+
+// TimeframeContext 单个大周期的趋势和支撑阻力摘要
+type TimeframeContext struct {
+	Timeframe  string
+	Trend      *TrendAnalysis
+	Levels     *LevelsAnalysis
+	RSI        float64
+	ATRPercent float64
+}
+
+// DerivativesStats 永续合约市场数据（公共行情）
+type DerivativesStats struct {
+	FundingRate          float64   `json:"funding_rate"`                     // 当前资金费率（每个结算周期，0.0001 即 0.01%，正数表示多头支付空头）
+	FundingIntervalHours float64   `json:"funding_interval_hours,omitempty"` // 资金费结算周期（小时，未知时为0）
+	NextFundingTime      time.Time `json:"next_funding_time,omitempty"`      // 下次结算时间（未知时为零值）
+	OpenInterest         float64   `json:"open_interest"`                    // 全市场持仓量（基础币种）
+	OpenInterestChange   float64   `json:"open_interest_change,omitempty"`   // 持仓量相对上一轮分析的变化（%，首轮为0）
+	MarkPrice            float64   `json:"mark_price"`
+	IndexPrice           float64   `json:"index_price"`
+}
+
+// BasisPercent 基差（标记价格相对指数价格的溢价，%，价格缺失时为0）
+func (d *DerivativesStats) BasisPercent() float64 {
+	if d.MarkPrice <= 0 || d.IndexPrice <= 0 {
+		return 0
+	}
+	return (d.MarkPrice - d.IndexPrice) / d.IndexPrice * 100
+}
+
+// AnnualizedFundingPercent 按当前资金费率折算的年化费率（%，结算周期未知时为0）
+func (d *DerivativesStats) AnnualizedFundingPercent() float64 {
+	if d.FundingIntervalHours <= 0 {
+		return 0
+	}
+	return d.FundingRate * 100 * 365 * 24 / d.FundingIntervalHours
+}
+
+// SpotBasis 永续合约相对同一交易所现货的溢价（基差）
+type SpotBasis struct {
+	SpotPrice      float64   `json:"spot_price"`
+	PerpPrice      float64   `json:"perp_price"`
+	Percent        float64   `json:"percent"`         // 合约相对现货的溢价（%，正数表示合约升水）
+	AveragePercent float64   `json:"average_percent"` // 近期采样的平均溢价（%）
+	Samples        int       `json:"samples"`         // 计算平均溢价的采样数
+	Time           time.Time `json:"time"`
+}
+
+// FearGreedIndex 加密货币恐惧贪婪指数（0 极度恐惧 ~ 100 极度贪婪）
+type FearGreedIndex struct {
+	Value          int       `json:"value"`
+	Classification string    `json:"classification"`           // 等级（Extreme Fear / Fear / Neutral / Greed / Extreme Greed）
+	PreviousValue  int       `json:"previous_value,omitempty"` // 前一日指数（未知时为0）
+	UpdatedAt      time.Time `json:"updated_at"`               // 指数日期
+}
+
+// NewsHeadline 新闻标题
+type NewsHeadline struct {
+	Title       string    `json:"title"`
+	Source      string    `json:"source"`       // 来源（订阅源标题）
+	PublishedAt time.Time `json:"published_at"` // 发布时间
 }
 
 // Position 持仓信息
 type Position struct {
-	Side          string // "long" or "short"
-	Size          float64
-	EntryPrice    float64
-	UnrealizedPnL float64
-	Leverage      int
-	Symbol        string
-	StopLoss      float64 // 止损价格
-	TakeProfit    float64 // 止盈价格
-	TrailingStop  float64 // 移动止损价格（动态更新）
-	HighestPrice  float64 // 开仓后的最高价（用于移动止损）
-	LowestPrice   float64 // 开仓后的最低价（用于移动止损）
+	Side             string  // "long" or "short"
+	Size             float64 // 持仓数量（基础币种）
+	EntryPrice       float64
+	UnrealizedPnL    float64
+	Leverage         int
+	Symbol           string
+	LiquidationPrice float64 // 预估强平价格（交易所返回，未获取时为0）
+	Margin           float64 // 持仓占用保证金（未获取时为0）
+	MarkPrice        float64 // 标记价格（未获取时为0）
+	StopLoss         float64 // 止损价格
+	TakeProfit       float64 // 止盈价格
+	TrailingStop     float64 // 移动止损价格（动态更新）
+	HighestPrice     float64 // 开仓后的最高价（用于移动止损）
+	LowestPrice      float64 // 开仓后的最低价（用于移动止损）
+}
+
+// LiquidationDistance 当前价格距强平价格的百分比（朝不利方向，强平价未知时返回0）
+func (p *Position) LiquidationDistance(price float64) float64 {
+	if p.LiquidationPrice <= 0 || price <= 0 {
+		return 0
+	}
+	if p.Side == "short" {
+		return (p.LiquidationPrice - price) / price * 100
+	}
+	return (price - p.LiquidationPrice) / price * 100
+}
+
+// 订单状态
+const (
+	OrderStateLive            = "live"             // 等待成交
+	OrderStatePartiallyFilled = "partially_filled" // 部分成交
+	OrderStateFilled          = "filled"           // 完全成交
+	OrderStateCanceled        = "canceled"         // 已撤销
+)
+
+// Order 订单信息
+type Order struct {
+	OrderID       string    // 交易所订单ID
+	ClientOrderID string    // 自定义订单ID
+	Symbol        string    // 交易对符号
+	Side          string    // "buy" or "sell"
+	PosSide       string    // 持仓方向（合约）
+	Type          string    // 订单类型 ("market", "limit" 等)
+	Price         float64   // 委托价格（市价单为0）
+	Size          float64   // 委托数量（交易所原始单位，合约为张数）
+	FilledSize    float64   // 已成交数量
+	AvgPrice      float64   // 成交均价
+	State         string    // 订单状态
+	Fee           float64   // 手续费（负数表示支出）
+	FeeCurrency   string    // 手续费币种
+	ReduceOnly    bool      // 是否只减仓
+	CreatedAt     time.Time // 创建时间
+	UpdatedAt     time.Time // 更新时间
+}
+
+// IsFinal 订单是否已处于终态（完全成交或已撤销）
+func (o *Order) IsFinal() bool {
+	return o.State == OrderStateFilled || o.State == OrderStateCanceled
+}
+
+// Trade 成交记录
+type Trade struct {
+	TradeID     string    // 成交ID
+	OrderID     string    // 订单ID
+	Symbol      string    // 交易对符号
+	Side        string    // "buy" or "sell"
+	PosSide     string    // 持仓方向（合约）
+	Price       float64   // 成交价格
+	Size        float64   // 成交数量（交易所原始单位，合约为张数）
+	Fee         float64   // 手续费（负数表示支出，正数表示返佣）
+	FeeCurrency string    // 手续费币种
+	RealizedPnL float64   // 该笔成交的已实现盈亏（平仓成交）
+	IsMaker     bool      // 是否为挂单成交
+	Timestamp   time.Time // 成交时间
+}
+
+// FundingFee 资金费用（永续合约）
+type FundingFee struct {
+	Symbol    string    // 交易对符号
+	Amount    float64   // 资金费用（负数表示支出，正数表示收入）
+	Currency  string    // 结算币种
+	Timestamp time.Time // 结算时间
+}
+
+// 资金流水类型
+const (
+	TransferDeposit    = "deposit"    // 充值
+	TransferWithdrawal = "withdrawal" // 提现
+	TransferInternal   = "transfer"   // 账户间划转
+)
+
+// AccountTransfer 充值、提现和账户间划转记录
+type AccountTransfer struct {
+	ID        string    // 交易所流水ID
+	Type      string    // 流水类型 (TransferDeposit / TransferWithdrawal / TransferInternal)
+	Currency  string    // 币种
+	Amount    float64   // 数量（正数表示转入，负数表示转出）
+	Fee       float64   // 提现手续费（负数表示支出）
+	Timestamp time.Time // 到账时间
 }
 
 // TradeSignal 交易信号
 type TradeSignal struct {
-	Signal      string `json:"signal"`       // "BUY", "SELL", "HOLD"
-	Reason      string `json:"reason"`       // 交易理由
-	Confidence  string `json:"confidence"`   // "HIGH", "MEDIUM", "LOW"
-	Timestamp   string `json:"timestamp"`    // 时间戳
-	IsFallback  bool   `json:"is_fallback"`  // 是否为备用信号
-	TradingPair string `json:"trading_pair"` // 交易对标识 (如 "BTC-USDT")
+	Signal          string  `json:"signal"`                     // "BUY", "SELL", "HOLD"
+	Reason          string  `json:"reason"`                     // 交易理由
+	Confidence      string  `json:"confidence"`                 // "HIGH", "MEDIUM", "LOW"
+	ConfidenceScore int     `json:"confidence_score,omitempty"` // 信心分数 (1-100，0表示未给出)
+	StopLoss        float64 `json:"stop_loss,omitempty"`        // 建议止损价 (0表示未给出)
+	TakeProfit      float64 `json:"take_profit,omitempty"`      // 建议止盈价 (0表示未给出)
+	SizeFraction    float64 `json:"size_fraction,omitempty"`    // 建议仓位比例 (相对于配置的交易金额，0-1，0表示未给出)
+	Timestamp       string  `json:"timestamp"`                  // 时间戳
+	IsFallback      bool    `json:"is_fallback"`                // 是否为备用信号
+	TradingPair     string  `json:"trading_pair"`               // 交易对标识 (如 "BTC-USDT")
+	Provider        string  `json:"provider,omitempty"`         // 生成信号的AI服务和模型 (如 "DeepSeek/deepseek-chat")
+	PromptVariant   string  `json:"prompt_variant,omitempty"`   // 生成信号的提示词变体 (启用提示词A/B实验时)
+}
+
+// ExitOpinion AI对持仓是否提前离场的意见
+type ExitOpinion struct {
+	Action     string `json:"action"`     // "EXIT", "HOLD"
+	Reason     string `json:"reason"`     // 理由
+	Confidence string `json:"confidence"` // "HIGH", "MEDIUM", "LOW"
+}
+
+// TradeReview 已平仓交易的复盘资料（开平仓信息和持仓期间的行情）
+type TradeReview struct {
+	TradingPair  string
+	Side         string // "long" or "short"
+	Confidence   string // 开仓信号信心
+	Regime       string // 开仓时的市场状态（整体趋势）
+	EntryReason  string // 开仓理由
+	ExitReason   string // 平仓原因
+	EntryPrice   float64
+	ExitPrice    float64
+	Leverage     int
+	OpenedAt     time.Time
+	ClosedAt     time.Time
+	ReturnPct    float64 // 扣除手续费后的收益率（%，不含杠杆）
+	NetPnL       float64 // 扣除手续费后的盈亏（计价币种）
+	MaxFavorable float64 // 持仓期间最大有利波动（%，按K线最高/最低价计算，未获取K线时为0）
+	MaxAdverse   float64 // 持仓期间最大不利波动（%，正数）
+	ExitTrend    string  // 平仓时的整体趋势（未获取时为空）
+}
+
+// PostMortem AI撰写的交易复盘
+type PostMortem struct {
+	Summary   string    `json:"summary"`    // 交易经过和结果
+	Mistakes  string    `json:"mistakes"`   // 问题所在（盈利交易也可能有不足）
+	Lesson    string    `json:"lesson"`     // 可借鉴的经验
+	Model     string    `json:"model"`      // 撰写复盘的模型
+	WrittenAt time.Time `json:"written_at"` // 撰写时间
+}
+
+// TuningReview 参数调优资料（近期交易表现、市场状态和当前技术指标参数）
+type TuningReview struct {
+	TradingPair   string
+	Timeframe     string
+	Trades        int     // 近期已平仓交易数
+	WinRate       float64 // 胜率（%）
+	AvgReturnPct  float64 // 平均净收益率（%，不含杠杆）
+	NetPnL        float64 // 累计净盈亏（计价币种）
+	AvgHoldHours  float64 // 平均持仓时长（小时）
+	StopLossExits int     // 风控平仓次数
+	Regime        string  // 当前市场状态（整体趋势）
+	ATRPercent    float64 // 当前ATR占价格的百分比
+	RSI           float64 // 当前RSI
+	Params        []TuningParam
+}
+
+// TuningParam 可调整的技术指标参数及其允许范围
+type TuningParam struct {
+	Name  string
+	Value float64
+	Min   float64
+	Max   float64
+}
+
+// TuningAdvice AI给出的参数调整建议
+type TuningAdvice struct {
+	Assessment  string             `json:"assessment"`  // 近期表现和市场状态评估
+	Suggestions []TuningSuggestion `json:"suggestions"` // 参数调整建议（无需调整时为空）
+}
+
+// TuningSuggestion 单个参数的调整建议
+type TuningSuggestion struct {
+	Param  string  `json:"param"`  // 参数名
+	Value  float64 `json:"value"`  // 建议值
+	Reason string  `json:"reason"` // 理由
 }
 
 // SignalStats 信号统计
 type SignalStats struct {
-	BuyCount  int // BUY信号次数
-	SellCount int // SELL信号次数
-	HoldCount int // HOLD信号次数
-	Total     int // 总信号次数
+	BuyCount  int                      // BUY信号次数
+	SellCount int                      // SELL信号次数
+	HoldCount int                      // HOLD信号次数
+	Total     int                      // 总信号次数
+	Variants  map[string]*VariantStats // 各提示词变体的信号和交易统计 (启用提示词A/B实验时)
+}
+
+// VariantStats 提示词变体统计
+type VariantStats struct {
+	Signals   int     // 信号次数
+	Trades    int     // 已平仓交易次数
+	Wins      int     // 盈利交易次数
+	NetPnL    float64 // 累计净盈亏（计价币种）
+	ReturnPct float64 // 累计净收益率（%，不含杠杆）
+}
+
+// WinRate 胜率（%，没有已平仓交易时为0）
+func (v *VariantStats) WinRate() float64 {
+	if v.Trades == 0 {
+		return 0
+	}
+	return float64(v.Wins) / float64(v.Trades) * 100
+}
+
+// AvgReturnPct 每笔交易的平均净收益率（%，没有已平仓交易时为0）
+func (v *VariantStats) AvgReturnPct() float64 {
+	if v.Trades == 0 {
+		return 0
+	}
+	return v.ReturnPct / float64(v.Trades)
 }
 
 // FormatStats 格式化统计信息
@@ -123,9 +517,133 @@ func (s *SignalStats) FormatStats() string {
 }
 
 // SessionContext AI会话上下文 (用于隔离不同交易对的对话历史)
+// 分析协程写入信号、风控协程记录交易结果、监控读取统计可能同时发生，创建后请通过方法访问
 type SessionContext struct {
 	TradingPair   string        // 交易对标识
 	SignalHistory []TradeSignal // 该交易对的信号历史
 	LastUpdate    string        // 最后更新时间
 	Stats         SignalStats   // 信号统计
+
+	mu sync.RWMutex // 保护以上字段
+}
+
+// AddSignal 记录信号并更新统计，历史信号超过 limit 条时丢弃最早的（limit ≤0 不限制），返回保留的历史信号数
+func (s *SessionContext) AddSignal(signal TradeSignal, limit int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.SignalHistory = append(s.SignalHistory, signal)
+	if limit > 0 && len(s.SignalHistory) > limit {
+		s.SignalHistory = append([]TradeSignal(nil), s.SignalHistory[len(s.SignalHistory)-limit:]...)
+	}
+
+	s.Stats.Total++
+	switch signal.Signal {
+	case "BUY":
+		s.Stats.BuyCount++
+	case "SELL":
+		s.Stats.SellCount++
+	case "HOLD":
+		s.Stats.HoldCount++
+	}
+	if signal.PromptVariant != "" {
+		s.variantLocked(signal.PromptVariant).Signals++
+	}
+	s.LastUpdate = signal.Timestamp
+	return len(s.SignalHistory)
+}
+
+// History 信号历史的副本（从旧到新）
+func (s *SessionContext) History() []TradeSignal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]TradeSignal(nil), s.SignalHistory...)
+}
+
+// StatsSnapshot 信号统计的副本
+func (s *SessionContext) StatsSnapshot() SignalStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats := s.Stats
+	if s.Stats.Variants != nil {
+		stats.Variants = make(map[string]*VariantStats, len(s.Stats.Variants))
+		for name, v := range s.Stats.Variants {
+			copied := *v
+			stats.Variants[name] = &copied
+		}
+	}
+	return stats
+}
+
+// RecordVariantTrade 记录提示词变体开仓的交易平仓结果
+func (s *SessionContext) RecordVariantTrade(variant string, returnPct, netPnL float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.variantLocked(variant)
+	stats.Trades++
+	if returnPct > 0 {
+		stats.Wins++
+	}
+	stats.NetPnL += netPnL
+	stats.ReturnPct += returnPct
+}
+
+// VariantSnapshot 各提示词变体统计的快照（按变体名称排序）
+func (s *SessionContext) VariantSnapshot() ([]string, []VariantStats) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.Stats.Variants))
+	for name := range s.Stats.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stats := make([]VariantStats, len(names))
+	for i, name := range names {
+		stats[i] = *s.Stats.Variants[name]
+	}
+	return names, stats
+}
+
+// BestVariant 已平仓交易中平均净收益率最高的提示词变体（相同时胜率高者优先，没有已平仓交易时返回空）
+func (s *SessionContext) BestVariant() string {
+	names, stats := s.VariantSnapshot()
+	best := -1
+	for i := range stats {
+		if stats[i].Trades == 0 {
+			continue
+		}
+		if best < 0 || stats[i].AvgReturnPct() > stats[best].AvgReturnPct() ||
+			(stats[i].AvgReturnPct() == stats[best].AvgReturnPct() && stats[i].WinRate() > stats[best].WinRate()) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return names[best]
+}
+
+// FormatVariantStats 格式化各提示词变体统计
+func (s *SessionContext) FormatVariantStats() string {
+	names, stats := s.VariantSnapshot()
+	parts := make([]string, len(names))
+	for i, name := range names {
+		v := stats[i]
+		parts[i] = fmt.Sprintf("%s: 信号%d, 交易%d, 胜率%.1f%%, 平均收益率%+.2f%%, 净盈亏%.4f",
+			name, v.Signals, v.Trades, v.WinRate(), v.AvgReturnPct(), v.NetPnL)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// variantLocked 获取或创建提示词变体统计（调用方需持有写锁）
+func (s *SessionContext) variantLocked(variant string) *VariantStats {
+	if s.Stats.Variants == nil {
+		s.Stats.Variants = make(map[string]*VariantStats)
+	}
+	stats, ok := s.Stats.Variants[variant]
+	if !ok {
+		stats = &VariantStats{}
+		s.Stats.Variants[variant] = stats
+	}
+	return stats
 }
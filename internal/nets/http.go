@@ -1,6 +1,7 @@
 package nets
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -103,7 +104,12 @@ func (c *HttpClient) QueryGet(url string, headers map[string]string) ([]byte, er
 
 // HttpPost sends a POST request to the specified URL.
 func (c *HttpClient) QueryPost(url string, headers map[string]string, body []byte) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.httpTimeout)
+	return c.QueryPostContext(context.Background(), url, headers, body)
+}
+
+// QueryPostContext 发送POST请求（ctx 取消或超过客户端超时时中止）
+func (c *HttpClient) QueryPostContext(ctx context.Context, url string, headers map[string]string, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.httpTimeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
@@ -131,6 +137,88 @@ func (c *HttpClient) QueryPost(url string, headers map[string]string, body []byt
 	return responseBody, nil
 }
 
+// QueryStream 发送POST请求并逐行读取响应体（用于SSE流式响应），每读到一行调用 onLine，onLine 返回 false 时停止读取
+// ctx 取消或超过客户端超时（包括读取响应体的时间）时中止，非2xx状态码时返回错误
+func (c *HttpClient) QueryStream(ctx context.Context, url string, headers map[string]string, body []byte, onLine func(line string) bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, responseBody)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !onLine(scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// RawResponse 原始HTTP响应
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// QueryRaw 发送请求并返回原始响应（状态码、响应头、响应体），由调用方校验状态码
+func (c *HttpClient) QueryRaw(method, url string, headers map[string]string, body []byte) (*RawResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.httpTimeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewBuffer(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       responseBody,
+	}, nil
+}
+
+// IsSuccess 状态码是否为2xx
+func (r *RawResponse) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
 // 发送POST请求，data为map数据
 func (c *HttpClient) QueryPostEx(url string, headers map[string]string, data map[string]interface{}) ([]byte, error) {
 	bytes, err := json.Marshal(data)
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"dsbot/internal/nets"
+)
+
+// Level 通知级别
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Event 通知事件
+type Event struct {
+	ID          uint64    `json:"id"`
+	Level       Level     `json:"level"`
+	Title       string    `json:"title"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+	Attempts    int       `json:"attempts"`     // 已尝试发送次数
+	NextAttempt time.Time `json:"next_attempt"` // 下次尝试时间
+}
+
+// Text 格式化为纯文本
+func (e *Event) Text() string {
+	return fmt.Sprintf("[%s] %s\n%s\n%s", e.Level, e.Title, e.Message, e.CreatedAt.Format("2006-01-02 15:04:05"))
+}
+
+// Notifier 通知发送渠道
+type Notifier interface {
+	// Name 渠道名称（用于区分持久化队列）
+	Name() string
+	// Send 发送通知，返回错误时由队列负责重试
+	Send(event *Event) error
+}
+
+// Publisher 通知发布接口（供业务组件使用）
+type Publisher interface {
+	Publish(level Level, title, message string)
+}
+
+// WebhookNotifier Webhook通知（POST JSON）
+type WebhookNotifier struct {
+	url        string
+	httpClient *nets.HttpClient
+}
+
+// NewWebhookNotifier 创建Webhook通知
+func NewWebhookNotifier(url string) (*WebhookNotifier, error) {
+	httpClient, err := nets.NewHttpClient(10*time.Second, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookNotifier{url: url, httpClient: httpClient}, nil
+}
+
+// Name 渠道名称
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Send 发送通知
+func (n *WebhookNotifier) Send(event *Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":         event.ID,
+		"level":      event.Level,
+		"title":      event.Title,
+		"message":    event.Message,
+		"created_at": event.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.httpClient.QueryRaw("POST", n.url, nets.DefaultHeadersPost, body)
+	if err != nil {
+		return requestError("Webhook", err)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("Webhook返回 HTTP %d: %s", resp.StatusCode, string(resp.Body))
+	}
+	return nil
+}
+
+// TelegramNotifier Telegram机器人通知
+type TelegramNotifier struct {
+	token      string
+	chatID     string
+	httpClient *nets.HttpClient
+}
+
+// NewTelegramNotifier 创建Telegram通知
+func NewTelegramNotifier(token, chatID string) (*TelegramNotifier, error) {
+	httpClient, err := nets.NewHttpClient(10*time.Second, nets.DefaultProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramNotifier{token: token, chatID: chatID, httpClient: httpClient}, nil
+}
+
+// Name 渠道名称
+func (n *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Send 发送通知
+func (n *TelegramNotifier) Send(event *Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": n.chatID,
+		"text":    event.Text(),
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.token)
+	resp, err := n.httpClient.QueryRaw("POST", endpoint, nets.DefaultHeadersPost, body)
+	if err != nil {
+		return requestError("Telegram", err)
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("Telegram返回 HTTP %d: %s", resp.StatusCode, string(resp.Body))
+	}
+	return nil
+}
+
+// requestError 去掉请求错误中的URL（Telegram令牌和Webhook密钥在URL中，错误会被写入日志）
+func requestError(channel string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return fmt.Errorf("%s请求失败: %v", channel, err)
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// 请求失败时错误中不含URL里的令牌和密钥
+func TestRequestErrorRedactsURL(t *testing.T) {
+	const secret = "123456:telegram-secret"
+	err := requestError("Telegram", &url.Error{
+		Op:  "Post",
+		URL: "https://api.telegram.org/bot" + secret + "/sendMessage",
+		Err: errors.New("dial tcp: lookup api.telegram.org: no such host"),
+	})
+	if strings.Contains(err.Error(), secret) || !strings.Contains(err.Error(), "no such host") {
+		t.Fatalf("错误 = %v, 期望只保留底层原因", err)
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL + "/hooks/webhook-secret"
+	server.Close()
+	n, err := NewWebhookNotifier(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Send(&Event{ID: 1, Title: "测试"})
+	if err == nil || strings.Contains(err.Error(), "webhook-secret") {
+		t.Fatalf("错误 = %v, 期望请求失败且不含密钥", err)
+	}
+}
@@ -0,0 +1,226 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+// DefaultOutboxDir 默认发件队列持久化目录
+const DefaultOutboxDir = "data/outbox"
+
+const (
+	outboxInitialBackoff = 5 * time.Second
+	outboxMaxBackoff     = 10 * time.Minute
+)
+
+// outboxState 持久化的队列状态
+type outboxState struct {
+	NextID uint64   `json:"next_id"`
+	Events []*Event `json:"events"`
+}
+
+// Outbox 单个通知渠道的持久化发件队列
+// 按入队顺序发送（队首发送成功前不会发送后续事件），失败按指数退避重试，队列持久化到文件，重启后继续发送
+type Outbox struct {
+	notifier Notifier
+	file     string
+	maxSize  int
+	state    outboxState
+	wake     chan struct{}
+	mu       sync.Mutex
+}
+
+// newOutbox 创建发件队列并从文件恢复
+func newOutbox(notifier Notifier, dir string, maxSize int) *Outbox {
+	o := &Outbox{
+		notifier: notifier,
+		file:     filepath.Join(dir, fmt.Sprintf("outbox_%s.json", notifier.Name())),
+		maxSize:  maxSize,
+		wake:     make(chan struct{}, 1),
+	}
+
+	if err := o.load(); err != nil {
+		logger.Warnf("[通知] 读取 %s 发件队列失败: %v", notifier.Name(), err)
+	} else if len(o.state.Events) > 0 {
+		logger.Printf("[通知] 恢复 %s 发件队列，待发送 %d 条", notifier.Name(), len(o.state.Events))
+	}
+
+	return o
+}
+
+// enqueue 入队并持久化
+func (o *Outbox) enqueue(level Level, title, message string) {
+	o.mu.Lock()
+	o.state.NextID++
+	now := time.Now()
+	o.state.Events = append(o.state.Events, &Event{
+		ID:          o.state.NextID,
+		Level:       level,
+		Title:       title,
+		Message:     message,
+		CreatedAt:   now,
+		NextAttempt: now,
+	})
+
+	// 队列过长时丢弃最旧的事件，避免长时间故障导致无限增长
+	if o.maxSize > 0 && len(o.state.Events) > o.maxSize {
+		dropped := len(o.state.Events) - o.maxSize
+		o.state.Events = o.state.Events[dropped:]
+		logger.Warnf("[通知] %s 发件队列已满，丢弃最旧的 %d 条", o.notifier.Name(), dropped)
+	}
+
+	if err := o.saveLocked(); err != nil {
+		logger.Warnf("[通知] 保存 %s 发件队列失败: %v", o.notifier.Name(), err)
+	}
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 发送循环
+func (o *Outbox) run(ctx context.Context) {
+	for {
+		wait := o.deliver()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// deliver 按顺序发送到期的事件，返回下次需要检查的等待时间
+func (o *Outbox) deliver() time.Duration {
+	for {
+		o.mu.Lock()
+		if len(o.state.Events) == 0 {
+			o.mu.Unlock()
+			return time.Minute
+		}
+		event := o.state.Events[0]
+		if wait := time.Until(event.NextAttempt); wait > 0 {
+			o.mu.Unlock()
+			return wait
+		}
+		o.mu.Unlock()
+
+		err := o.notifier.Send(event)
+
+		o.mu.Lock()
+		if err != nil {
+			event.Attempts++
+			backoff := outboxInitialBackoff << (event.Attempts - 1)
+			if backoff > outboxMaxBackoff || backoff <= 0 {
+				backoff = outboxMaxBackoff
+			}
+			event.NextAttempt = time.Now().Add(backoff)
+			logger.Warnf("[通知] %s 发送失败 (第%d次): %v，%s 后重试", o.notifier.Name(), event.Attempts, err, backoff)
+		} else {
+			// 发送期间队列可能已丢弃最旧的事件，按ID移除已发送的事件
+			o.removeLocked(event.ID)
+		}
+		if saveErr := o.saveLocked(); saveErr != nil {
+			logger.Warnf("[通知] 保存 %s 发件队列失败: %v", o.notifier.Name(), saveErr)
+		}
+		o.mu.Unlock()
+
+		if err != nil {
+			return time.Until(event.NextAttempt)
+		}
+	}
+}
+
+// removeLocked 从队列中移除指定ID的事件（调用方需持有锁，事件已被丢弃时忽略）
+func (o *Outbox) removeLocked(id uint64) {
+	for i, event := range o.state.Events {
+		if event.ID == id {
+			o.state.Events = append(o.state.Events[:i], o.state.Events[i+1:]...)
+			return
+		}
+	}
+}
+
+// load 从文件恢复队列
+func (o *Outbox) load() error {
+	data, err := os.ReadFile(o.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, &o.state)
+}
+
+// saveLocked 保存队列到文件（调用方需持有锁）
+func (o *Outbox) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(o.file), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(o.state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile := o.file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, o.file)
+}
+
+// Dispatcher 通知分发器 - 将事件写入每个渠道各自的持久化发件队列
+type Dispatcher struct {
+	outboxes []*Outbox
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher 创建通知分发器
+// dir: 发件队列持久化目录, maxQueueSize: 每个渠道队列上限（0表示不限制）
+func NewDispatcher(dir string, maxQueueSize int, notifiers ...Notifier) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{ctx: ctx, cancel: cancel}
+	for _, n := range notifiers {
+		d.outboxes = append(d.outboxes, newOutbox(n, dir, maxQueueSize))
+	}
+	return d
+}
+
+// Start 启动发送循环
+func (d *Dispatcher) Start() {
+	for _, o := range d.outboxes {
+		d.wg.Add(1)
+		go func(o *Outbox) {
+			defer d.wg.Done()
+			o.run(d.ctx)
+		}(o)
+	}
+}
+
+// Stop 停止发送循环（未发送的事件保留在文件中，重启后继续发送）
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Publish 发布通知
+func (d *Dispatcher) Publish(level Level, title, message string) {
+	for _, o := range d.outboxes {
+		o.enqueue(level, title, message)
+	}
+}
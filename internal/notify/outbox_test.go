@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"sync"
+	"testing"
+)
+
+// blockingNotifier 第一次发送阻塞到 release 关闭，记录发送成功的事件标题
+type blockingNotifier struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	sent    []string
+}
+
+func (n *blockingNotifier) Name() string {
+	return "blocking"
+}
+
+func (n *blockingNotifier) Send(event *Event) error {
+	n.once.Do(func() {
+		close(n.started)
+		<-n.release
+	})
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, event.Title)
+	return nil
+}
+
+// 发送期间队列已满丢弃了正在发送的事件时，不误删其他未发送的事件
+func TestOutboxDropDuringSend(t *testing.T) {
+	n := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{})}
+	o := newOutbox(n, t.TempDir(), 2)
+
+	o.enqueue(LevelInfo, "e1", "")
+	done := make(chan struct{})
+	go func() {
+		o.deliver()
+		close(done)
+	}()
+	<-n.started
+	o.enqueue(LevelInfo, "e2", "")
+	o.enqueue(LevelInfo, "e3", "")
+	close(n.release)
+	<-done
+
+	if len(n.sent) != 3 || n.sent[1] != "e2" || n.sent[2] != "e3" {
+		t.Fatalf("已发送 = %v, 期望 [e1 e2 e3]", n.sent)
+	}
+	if len(o.state.Events) != 0 {
+		t.Fatalf("队列剩余 %d 条, 期望全部发送", len(o.state.Events))
+	}
+}
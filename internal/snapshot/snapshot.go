@@ -0,0 +1,401 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"dsbot/internal/calendar"
+	"dsbot/internal/config"
+	"dsbot/internal/embargo"
+	"dsbot/internal/journal"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
+)
+
+// FormatVersion 快照格式版本
+const FormatVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	configName   = "config.redacted.json"
+	filesPrefix  = "files/"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "REDACTED"
+
+// Item 快照中的一项状态（文件或目录），按逻辑名称记录，恢复时映射到目标配置中的路径
+type Item struct {
+	Name  string `json:"name"` // 逻辑名称（journal, calendar, embargo, outbox, store, sqlite）
+	Path  string `json:"path"` // 快照时的路径（仅供参考）
+	IsDir bool   `json:"is_dir"`
+}
+
+// File 快照中的单个文件
+type File struct {
+	Item   string `json:"item"`   // 所属状态项
+	Rel    string `json:"rel"`    // 相对状态项路径（单文件为空）
+	Size   int64  `json:"size"`   // 字节数
+	SHA256 string `json:"sha256"` // 内容校验和
+}
+
+// Manifest 快照清单
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Items     []Item    `json:"items"`
+	Files     []File    `json:"files"`
+}
+
+// Items 按配置列出需要快照的状态项
+// sqlite 数据库按一致性副本打包；postgres 不在快照范围内，应使用数据库自身的备份工具
+func Items(cfg *config.Config) []Item {
+	items := []Item{
+		{Name: "journal", Path: orDefault(cfg.Trading.Journal.File, journal.DefaultFile)},
+		{Name: "calendar", Path: orDefault(cfg.Trading.Calendar.StateFile, calendar.DefaultStateFile)},
+		{Name: "embargo", Path: orDefault(cfg.Trading.Embargo.File, embargo.DefaultFile)},
+		{Name: "outbox", Path: orDefault(cfg.Notification.OutboxDir, notify.DefaultOutboxDir), IsDir: true},
+	}
+	switch strings.ToLower(orDefault(cfg.Storage.Backend, store.BackendSQLite)) {
+	case store.BackendJSONL:
+		items = append(items, Item{Name: "store", Path: orDefault(cfg.Storage.Dir, store.DefaultDir), IsDir: true})
+	case store.BackendSQLite:
+		items = append(items, Item{Name: "sqlite", Path: orDefault(cfg.Storage.DSN, store.DefaultSQLiteDSN)})
+	}
+	return items
+}
+
+// Create 将脱敏后的配置和状态文件打包为 tar.gz 写入 w，返回清单
+// rawConfig: 配置文件原始内容（密钥类字段在写入前脱敏）
+func Create(w io.Writer, cfg *config.Config, rawConfig []byte) (*Manifest, error) {
+	redacted, err := RedactConfig(rawConfig)
+	if err != nil {
+		return nil, fmt.Errorf("配置脱敏失败: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest := &Manifest{Version: FormatVersion, CreatedAt: time.Now()}
+	for _, item := range Items(cfg) {
+		files, err := collectFiles(item)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		manifest.Items = append(manifest.Items, item)
+
+		for _, rel := range files {
+			src := item.Path
+			if rel != "" {
+				src = filepath.Join(item.Path, filepath.FromSlash(rel))
+			}
+			data, err := readItemFile(cfg, item, src)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s 失败: %w", src, err)
+			}
+			sum := sha256.Sum256(data)
+			manifest.Files = append(manifest.Files, File{
+				Item: item.Name, Rel: rel, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:]),
+			})
+			if err := writeEntry(tw, archiveName(item.Name, rel), data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writeEntry(tw, configName, redacted); err != nil {
+		return nil, err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, manifestData); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore 从 tar.gz 快照恢复状态文件到 cfg 指定的路径，脱敏配置写入 configOut
+// 目标文件已存在且 force 为 false 时拒绝恢复（不做任何写入）；所有文件校验通过后才开始写入
+func Restore(r io.Reader, cfg *config.Config, configOut string, force bool) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %w", err)
+	}
+	defer gz.Close()
+
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取快照失败: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("读取快照失败: %w", err)
+		}
+		contents[hdr.Name] = data
+	}
+
+	var manifest Manifest
+	manifestData, ok := contents[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("快照缺少清单 %s", manifestName)
+	}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("解析快照清单失败: %w", err)
+	}
+	if manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("快照格式版本 %d 高于当前支持的版本 %d", manifest.Version, FormatVersion)
+	}
+
+	// 按当前配置确定恢复路径
+	targets := make(map[string]Item)
+	for _, item := range Items(cfg) {
+		targets[item.Name] = item
+	}
+
+	type pending struct {
+		path string
+		data []byte
+	}
+	var writes []pending
+	var database string // 恢复的 sqlite 数据库路径
+	for _, f := range manifest.Files {
+		target, ok := targets[f.Item]
+		if !ok {
+			return nil, fmt.Errorf("当前配置不包含状态项 %s（如存储后端不同），无法恢复", f.Item)
+		}
+		data, ok := contents[archiveName(f.Item, f.Rel)]
+		if !ok {
+			return nil, fmt.Errorf("快照缺少文件 %s", archiveName(f.Item, f.Rel))
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("文件 %s 校验失败，快照可能已损坏", archiveName(f.Item, f.Rel))
+		}
+
+		dst := target.Path
+		if f.Rel != "" {
+			rel := path.Clean(f.Rel)
+			if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+				return nil, fmt.Errorf("快照包含非法路径: %s", f.Rel)
+			}
+			dst = filepath.Join(target.Path, filepath.FromSlash(rel))
+		}
+		if !force {
+			if _, err := os.Stat(dst); err == nil {
+				return nil, fmt.Errorf("%s 已存在（使用 --force 覆盖）", dst)
+			}
+		}
+		writes = append(writes, pending{path: dst, data: data})
+		if f.Item == "sqlite" {
+			database = dst
+		}
+	}
+
+	if configOut != "" {
+		if data, ok := contents[configName]; ok {
+			if !force {
+				if _, err := os.Stat(configOut); err == nil {
+					return nil, fmt.Errorf("%s 已存在（使用 --force 覆盖）", configOut)
+				}
+			}
+			writes = append(writes, pending{path: configOut, data: data})
+		}
+	}
+
+	// 旧数据库残留的 WAL 日志不属于恢复后的数据库，先删除
+	if database != "" {
+		for _, suffix := range []string{"-wal", "-shm"} {
+			if err := os.Remove(database + suffix); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("删除 %s 失败: %w", database+suffix, err)
+			}
+		}
+	}
+	for _, w := range writes {
+		if err := writeFileAtomic(w.path, w.data); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", w.path, err)
+		}
+	}
+	return &manifest, nil
+}
+
+// RedactConfig 将配置中的密钥类字段（key/secret/password/token/dsn）替换为占位值
+func RedactConfig(raw []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(redact(doc), "", "    ")
+}
+
+// redact 递归脱敏
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if s, ok := child.(string); ok && s != "" && isSecretKey(k) {
+				val[k] = redactedValue
+				continue
+			}
+			val[k] = redact(child)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redact(child)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// isSecretKey 判断配置字段是否为密钥类字段
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"key", "secret", "password", "token", "dsn"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectFiles 列出状态项包含的文件（单文件返回 [""]，目录返回相对路径，不存在时返回空）
+func collectFiles(item Item) ([]string, error) {
+	info, err := os.Stat(item.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !item.IsDir {
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s 应为文件", item.Path)
+		}
+		return []string{""}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(item.Path, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 跳过目录和写入中的临时文件
+		if fi.IsDir() || strings.HasSuffix(p, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(item.Path, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// readItemFile 读取状态文件内容
+// sqlite 数据库通过 VACUUM INTO 生成一致性副本后读取，避免打包写入中的数据库文件和遗漏 WAL 中的数据
+func readItemFile(cfg *config.Config, item Item, file string) ([]byte, error) {
+	if item.Name != "sqlite" {
+		return os.ReadFile(file)
+	}
+
+	s, err := store.Open(&cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	db, ok := s.(*store.SQLStore)
+	if !ok {
+		return nil, fmt.Errorf("%s 存储不支持备份", s.Backend())
+	}
+
+	dir, err := os.MkdirTemp("", "dsbot-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	backup := filepath.Join(dir, "dsbot.db")
+	if err := db.Backup(backup); err != nil {
+		return nil, fmt.Errorf("备份数据库失败: %w", err)
+	}
+	return os.ReadFile(backup)
+}
+
+// archiveName 状态文件在快照中的路径
+func archiveName(item, rel string) string {
+	if rel == "" {
+		return filesPrefix + item
+	}
+	return filesPrefix + item + "/" + rel
+}
+
+// writeEntry 写入一个 tar 条目
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeFileAtomic 原子写入文件（先写临时文件再重命名）
+func writeFileAtomic(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// orDefault 返回配置值，未配置时返回默认值
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/store"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqlite 存储（默认后端）中的持仓风控状态随快照恢复
+func TestSnapshotSQLiteStore(t *testing.T) {
+	newConfig := func(dir string) *config.Config {
+		cfg := &config.Config{}
+		cfg.Storage.DSN = filepath.Join(dir, "dsbot.db")
+		cfg.Trading.Journal.File = filepath.Join(dir, "journal.json")
+		cfg.Trading.Calendar.StateFile = filepath.Join(dir, "calendar.json")
+		cfg.Trading.Embargo.File = filepath.Join(dir, "embargo.json")
+		cfg.Notification.OutboxDir = filepath.Join(dir, "outbox")
+		return cfg
+	}
+	const key, value = "risk_state:BTC/USDT", `{"trailing_high":105}`
+
+	src := newConfig(t.TempDir())
+	s, err := store.Open(&src.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.Put(key, []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 源数据库保持打开（模拟运行中的机器人）
+	var archive bytes.Buffer
+	if _, err := Create(&archive, src, []byte(`{}`)); err != nil {
+		t.Fatalf("创建快照失败: %v", err)
+	}
+
+	dst := newConfig(t.TempDir())
+	manifest, err := Restore(&archive, dst, "", false)
+	if err != nil {
+		t.Fatalf("恢复快照失败: %v", err)
+	}
+	if len(manifest.Items) != 1 || manifest.Items[0].Name != "sqlite" {
+		t.Fatalf("状态项 = %+v, 期望只有 sqlite", manifest.Items)
+	}
+
+	restored, err := store.Open(&dst.Storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if got, err := restored.Get(key); err != nil || string(got) != value {
+		t.Fatalf("恢复后 %s = %q (%v), 期望 %s", key, got, err, value)
+	}
+}
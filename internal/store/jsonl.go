@@ -0,0 +1,140 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// validName 集合名和键名只允许字母、数字、下划线、短横线和点（直接用作文件名）
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validKey 键名另外允许冒号（如 risk_state:BTC-USDT），文件名中替换为 %3A
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// JSONLStore 仅追加文件存储 - 每个集合一个 .jsonl 文件，每个键一个 .json 文件
+type JSONLStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJSONLStore 创建 jsonl 存储
+func NewJSONLStore(dir string) (*JSONLStore, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "kv"), 0755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &JSONLStore{dir: dir}, nil
+}
+
+// Append 向集合文件追加一行（写入后同步到磁盘）
+func (s *JSONLStore) Append(collection string, record []byte) error {
+	if !validName.MatchString(collection) {
+		return fmt.Errorf("无效的集合名: %q", collection)
+	}
+	if bytes.ContainsAny(record, "\r\n") {
+		return fmt.Errorf("记录必须为单行 JSON")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(s.dir, collection+".jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(record, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Scan 逐行遍历集合文件（跳过崩溃时写入不完整的末行）
+func (s *JSONLStore) Scan(collection string, fn func(record []byte) error) error {
+	if !validName.MatchString(collection) {
+		return fmt.Errorf("无效的集合名: %q", collection)
+	}
+
+	s.mu.Lock()
+	data, err := os.ReadFile(filepath.Join(s.dir, collection+".jsonl"))
+	s.mu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Put 原子写入键值文件
+func (s *JSONLStore) Put(key string, value []byte) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("无效的键名: %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := s.keyFile(key)
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, value, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// Get 读取键值文件
+func (s *JSONLStore) Get(key string) ([]byte, error) {
+	if !validKey.MatchString(key) {
+		return nil, fmt.Errorf("无效的键名: %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.keyFile(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// keyFile 键值文件路径（冒号在部分文件系统上不能用于文件名）
+func (s *JSONLStore) keyFile(key string) string {
+	return filepath.Join(s.dir, "kv", strings.ReplaceAll(key, ":", "%3A")+".json")
+}
+
+// Backend 后端名称
+func (s *JSONLStore) Backend() string {
+	return BackendJSONL
+}
+
+// Close 关闭存储（文件按次打开，无需释放）
+func (s *JSONLStore) Close() error {
+	return nil
+}
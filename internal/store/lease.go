@@ -0,0 +1,115 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Leaser 可选接口：跨进程租约（用于多个工作进程分担交易对时避免重复交易）
+// 租约到期前由持有者续期，持有者退出或失联超过 ttl 后其他进程可以接手
+type Leaser interface {
+	// AcquireLease 获取或续期租约：租约空闲、已过期或已由 owner 持有时成功
+	AcquireLease(name, owner string, ttl time.Duration) (bool, error)
+
+	// ReleaseLease 释放 owner 持有的租约（未持有时忽略）
+	ReleaseLease(name, owner string) error
+}
+
+// lease 租约记录
+type lease struct {
+	Owner     string    `json:"owner"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// leaseLockStale 租约锁文件的最长存活时间（进程在持锁期间崩溃时由其他进程清理）
+const leaseLockStale = 10 * time.Second
+
+// AcquireLease 获取或续期租约（通过独占创建锁文件保证同一目录下的多个进程互斥）
+func (s *JSONLStore) AcquireLease(name, owner string, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := s.withLeaseLock(name, func(file string) error {
+		current, err := readLease(file)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if current != nil && current.Owner != owner && now.Before(current.ExpiresAt) {
+			return nil
+		}
+
+		data, err := json.Marshal(lease{Owner: owner, ExpiresAt: now.Add(ttl)})
+		if err != nil {
+			return err
+		}
+		tmpFile := file + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpFile, file); err != nil {
+			return err
+		}
+		acquired = true
+		return nil
+	})
+	return acquired, err
+}
+
+// ReleaseLease 释放租约
+func (s *JSONLStore) ReleaseLease(name, owner string) error {
+	return s.withLeaseLock(name, func(file string) error {
+		current, err := readLease(file)
+		if err != nil || current == nil || current.Owner != owner {
+			return err
+		}
+		return os.Remove(file)
+	})
+}
+
+// withLeaseLock 持有租约锁文件执行 fn
+func (s *JSONLStore) withLeaseLock(name string, fn func(file string) error) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("无效的租约名: %q", name)
+	}
+
+	dir := filepath.Join(s.dir, "leases")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(dir, name+".json")
+	lockFile := file + ".lock"
+
+	f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if !os.IsExist(err) {
+			return err
+		}
+		// 锁文件超时视为持锁进程已崩溃，清理后由下次调用重试
+		if info, statErr := os.Stat(lockFile); statErr == nil && time.Since(info.ModTime()) > leaseLockStale {
+			os.Remove(lockFile)
+		}
+		return fmt.Errorf("租约 %s 正被其他进程更新", name)
+	}
+	f.Close()
+	defer os.Remove(lockFile)
+
+	return fn(file)
+}
+
+// readLease 读取租约文件，不存在时返回 nil
+func readLease(file string) (*lease, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var l lease
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("解析租约失败: %w", err)
+	}
+	return &l, nil
+}
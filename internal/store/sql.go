@@ -0,0 +1,179 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SQLStore 基于 database/sql 的存储（SQLite / Postgres）
+type SQLStore struct {
+	backend string
+	db      *sql.DB
+}
+
+// NewSQLStore 打开数据库并创建表
+func NewSQLStore(backend, driver, dsn string) (*SQLStore, error) {
+	if backend == BackendSQLite {
+		if dir := filepath.Dir(dsn); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("创建数据库目录失败: %w", err)
+			}
+		}
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	if backend == BackendSQLite {
+		// SQLite 同一时间只允许一个写连接
+		db.SetMaxOpenConns(1)
+	}
+
+	s := &SQLStore{backend: backend, db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate 创建记录表和键值表
+func (s *SQLStore) migrate() error {
+	idColumn := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.backend == BackendPostgres {
+		idColumn = "BIGSERIAL PRIMARY KEY"
+	}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS records (
+			id %s,
+			collection TEXT NOT NULL,
+			data TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`, idColumn),
+		`CREATE INDEX IF NOT EXISTS idx_records_collection ON records (collection, id)`,
+		`CREATE TABLE IF NOT EXISTS kv (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS leases (
+			name TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("初始化数据表失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// placeholder 返回第 n 个参数占位符（SQLite 为 ?，Postgres 为 $n）
+func (s *SQLStore) placeholder(n int) string {
+	if s.backend == BackendPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Append 插入一条记录
+func (s *SQLStore) Append(collection string, record []byte) error {
+	query := fmt.Sprintf("INSERT INTO records (collection, data, created_at) VALUES (%s, %s, %s)",
+		s.placeholder(1), s.placeholder(2), s.placeholder(3))
+	_, err := s.db.Exec(query, collection, string(record), time.Now().UTC())
+	return err
+}
+
+// Scan 按插入顺序遍历集合中的记录
+func (s *SQLStore) Scan(collection string, fn func(record []byte) error) error {
+	query := fmt.Sprintf("SELECT data FROM records WHERE collection = %s ORDER BY id", s.placeholder(1))
+	rows, err := s.db.Query(query, collection)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := fn([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Put 写入键值（存在则覆盖）
+func (s *SQLStore) Put(key string, value []byte) error {
+	query := fmt.Sprintf(`INSERT INTO kv (key, value, updated_at) VALUES (%s, %s, %s)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3))
+	_, err := s.db.Exec(query, key, string(value), time.Now().UTC())
+	return err
+}
+
+// Get 读取键值
+func (s *SQLStore) Get(key string) ([]byte, error) {
+	var value string
+	err := s.db.QueryRow(fmt.Sprintf("SELECT value FROM kv WHERE key = %s", s.placeholder(1)), key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// AcquireLease 获取或续期租约（条件 upsert，仅在租约空闲、过期或已由 owner 持有时写入）
+func (s *SQLStore) AcquireLease(name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	query := fmt.Sprintf(`INSERT INTO leases (name, owner, expires_at) VALUES (%s, %s, %s)
+		ON CONFLICT (name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE leases.owner = excluded.owner OR leases.expires_at < %s`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4))
+	result, err := s.db.Exec(query, name, owner, now.Add(ttl), now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ReleaseLease 释放租约
+func (s *SQLStore) ReleaseLease(name, owner string) error {
+	query := fmt.Sprintf("DELETE FROM leases WHERE name = %s AND owner = %s", s.placeholder(1), s.placeholder(2))
+	_, err := s.db.Exec(query, name, owner)
+	return err
+}
+
+// Backup 将 SQLite 数据库一致地复制到 file（VACUUM INTO，运行中也可执行，file 不能已存在）
+func (s *SQLStore) Backup(file string) error {
+	if s.backend != BackendSQLite {
+		return fmt.Errorf("%s 存储不支持备份，请使用数据库自身的备份工具", s.backend)
+	}
+	_, err := s.db.Exec("VACUUM INTO ?", file)
+	return err
+}
+
+// Backend 后端名称
+func (s *SQLStore) Backend() string {
+	return s.backend
+}
+
+// Close 关闭数据库连接
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
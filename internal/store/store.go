@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"dsbot/internal/config"
+)
+
+// 存储后端类型
+const (
+	BackendSQLite   = "sqlite"   // 单机默认，单文件数据库
+	BackendPostgres = "postgres" // 多实例共享
+	BackendJSONL    = "jsonl"    // 仅追加文件，无需数据库
+)
+
+const (
+	DefaultSQLiteDSN = "data/dsbot.db"
+	DefaultDir       = "data/store"
+)
+
+// Store 持久化存储接口
+// 记录（Append/Scan）用于仅追加的事件流，如交易日志、AI调用记录；
+// 键值（Put/Get）用于覆盖写入的状态快照
+type Store interface {
+	// Append 向集合追加一条记录（JSON）
+	Append(collection string, record []byte) error
+
+	// Scan 按写入顺序遍历集合中的记录，fn 返回错误时停止
+	Scan(collection string, fn func(record []byte) error) error
+
+	// Put 写入键值（覆盖）
+	Put(key string, value []byte) error
+
+	// Get 读取键值，不存在时返回 nil, nil
+	Get(key string) ([]byte, error)
+
+	// Backend 后端名称
+	Backend() string
+
+	// Close 关闭存储
+	Close() error
+}
+
+// Open 按配置打开存储后端
+// sqlite/postgres 通过 database/sql 访问，驱动由程序入口 blank import 引入
+// （默认 modernc.org/sqlite 和 github.com/jackc/pgx/v5/stdlib）；驱动未注册时返回错误，不会静默降级
+func Open(cfg *config.StorageConfig) (Store, error) {
+	backend := strings.ToLower(cfg.Backend)
+	if backend == "" {
+		backend = BackendSQLite
+	}
+
+	switch backend {
+	case BackendJSONL:
+		return NewJSONLStore(cfg.Dir)
+
+	case BackendSQLite:
+		driver := cfg.Driver
+		if driver == "" {
+			driver = "sqlite"
+		}
+		dsn := cfg.DSN
+		if dsn == "" {
+			dsn = DefaultSQLiteDSN
+		}
+		if !driverRegistered(driver) {
+			return nil, fmt.Errorf("未编译 SQLite 驱动 %q（已注册: %s），如需无数据库部署请配置 backend 为 jsonl",
+				driver, strings.Join(sql.Drivers(), ", "))
+		}
+		return NewSQLStore(BackendSQLite, driver, dsn)
+
+	case BackendPostgres:
+		driver := cfg.Driver
+		if driver == "" {
+			driver = "pgx"
+		}
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("postgres 存储需要配置 dsn")
+		}
+		if !driverRegistered(driver) {
+			return nil, fmt.Errorf("未编译 Postgres 驱动 %q（已注册: %s）", driver, strings.Join(sql.Drivers(), ", "))
+		}
+		return NewSQLStore(BackendPostgres, driver, cfg.DSN)
+
+	default:
+		return nil, fmt.Errorf("不支持的存储后端: %s (支持: sqlite, postgres, jsonl)", cfg.Backend)
+	}
+}
+
+// driverRegistered 判断 database/sql 驱动是否已注册
+func driverRegistered(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
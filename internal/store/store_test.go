@@ -0,0 +1,166 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+// openTestStores 在临时目录打开各后端（postgres 需设置环境变量 DSBOT_TEST_POSTGRES_DSN）
+func openTestStores(t *testing.T) map[string]Store {
+	t.Helper()
+	dir := t.TempDir()
+	cfgs := map[string]config.StorageConfig{
+		BackendSQLite: {Backend: BackendSQLite, DSN: filepath.Join(dir, "dsbot.db")},
+		BackendJSONL:  {Backend: BackendJSONL, Dir: filepath.Join(dir, "store")},
+	}
+	if dsn := os.Getenv("DSBOT_TEST_POSTGRES_DSN"); dsn != "" {
+		cfgs[BackendPostgres] = config.StorageConfig{Backend: BackendPostgres, DSN: dsn}
+	}
+
+	stores := make(map[string]Store, len(cfgs))
+	for name, cfg := range cfgs {
+		cfg := cfg
+		s, err := Open(&cfg)
+		if err != nil {
+			if name == BackendPostgres {
+				t.Logf("跳过 postgres: %v", err)
+				continue
+			}
+			t.Fatalf("打开 %s 存储失败: %v", name, err)
+		}
+		if s.Backend() != name {
+			t.Fatalf("后端 = %s, 期望 %s", s.Backend(), name)
+		}
+		t.Cleanup(func() { s.Close() })
+		stores[name] = s
+	}
+	return stores
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	for name, s := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// 唯一集合名，避免共享的 postgres 中残留旧数据
+			collection := "events_" + strings.ReplaceAll(time.Now().Format("150405.000000000"), ".", "")
+			records := []string{`{"n":1}`, `{"n":2}`, `{"n":3}`}
+			for _, r := range records {
+				if err := s.Append(collection, []byte(r)); err != nil {
+					t.Fatalf("追加记录失败: %v", err)
+				}
+			}
+			if err := s.Append(collection+"_other", []byte(`{"n":0}`)); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			if err := s.Scan(collection, func(record []byte) error {
+				got = append(got, string(record))
+				return nil
+			}); err != nil {
+				t.Fatalf("遍历记录失败: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(records, ",") {
+				t.Fatalf("记录 = %v, 期望 %v（按写入顺序，且不含其他集合）", got, records)
+			}
+
+			key := "risk_state:" + collection
+			if value, err := s.Get(key); err != nil || value != nil {
+				t.Fatalf("不存在的键 = %q (%v), 期望 nil", value, err)
+			}
+			for _, v := range []string{`{"v":1}`, `{"v":2}`} {
+				if err := s.Put(key, []byte(v)); err != nil {
+					t.Fatalf("写入键值失败: %v", err)
+				}
+			}
+			if value, err := s.Get(key); err != nil || string(value) != `{"v":2}` {
+				t.Fatalf("键值 = %q (%v), 期望覆盖为 {\"v\":2}", value, err)
+			}
+		})
+	}
+}
+
+func TestStoreLease(t *testing.T) {
+	for name, s := range openTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			leaser, ok := s.(Leaser)
+			if !ok {
+				t.Fatalf("%s 存储未实现租约", name)
+			}
+			lease := "pair-" + strings.ReplaceAll(time.Now().Format("150405.000000000"), ".", "")
+			t.Cleanup(func() {
+				leaser.ReleaseLease(lease, "a")
+				leaser.ReleaseLease(lease, "b")
+			})
+
+			steps := []struct {
+				name  string
+				owner string
+				ttl   time.Duration
+				want  bool
+			}{
+				{name: "空闲时获取", owner: "a", ttl: time.Hour, want: true},
+				{name: "持有者续期", owner: "a", ttl: 200 * time.Millisecond, want: true},
+				{name: "未过期时其他进程获取失败", owner: "b", ttl: time.Hour, want: false},
+			}
+			for _, step := range steps {
+				got, err := leaser.AcquireLease(lease, step.owner, step.ttl)
+				if err != nil || got != step.want {
+					t.Fatalf("%s: 获取结果 = %v (%v), 期望 %v", step.name, got, err, step.want)
+				}
+			}
+
+			// 续期缩短了有效期，过期后由其他进程接手，原持有者不能再续期
+			time.Sleep(300 * time.Millisecond)
+			if got, err := leaser.AcquireLease(lease, "b", time.Hour); err != nil || !got {
+				t.Fatalf("过期后接手 = %v (%v), 期望成功", got, err)
+			}
+			if got, err := leaser.AcquireLease(lease, "a", time.Hour); err != nil || got {
+				t.Fatalf("被接手后原持有者续期 = %v (%v), 期望失败", got, err)
+			}
+
+			// 非持有者释放无效，持有者释放后立即可被获取
+			if err := leaser.ReleaseLease(lease, "a"); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := leaser.AcquireLease(lease, "a", time.Hour); got {
+				t.Fatal("非持有者释放后租约被其他进程获取")
+			}
+			if err := leaser.ReleaseLease(lease, "b"); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := leaser.AcquireLease(lease, "a", time.Hour); err != nil || !got {
+				t.Fatalf("释放后获取 = %v (%v), 期望成功", got, err)
+			}
+		})
+	}
+}
+
+func TestOpenUnregisteredDriver(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.StorageConfig
+		want string
+	}{
+		{name: "sqlite", cfg: config.StorageConfig{Backend: BackendSQLite, Driver: "sqlite3", DSN: filepath.Join(t.TempDir(), "x.db")}, want: "未编译 SQLite 驱动"},
+		{name: "postgres", cfg: config.StorageConfig{Backend: BackendPostgres, Driver: "postgres", DSN: "postgres://localhost/dsbot"}, want: "未编译 Postgres 驱动"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Open(&tt.cfg)
+			if err == nil {
+				s.Close()
+				t.Fatalf("后端 = %s, 期望驱动未注册时报错而不是降级", s.Backend())
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 = %v, 期望包含 %q", err, tt.want)
+			}
+		})
+	}
+}
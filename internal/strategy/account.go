@@ -0,0 +1,173 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// marginTopUpState 保证金自动补充的当日划转统计
+type marginTopUpState struct {
+	day    string  // 交易日
+	amount float64 // 当日已划转数量
+}
+
+// needsAccountSummary 是否需要查询账户权益（按权益比例下单、按权益计算仓位、启用维持保证金率检查或保证金自动补充时）
+func (bot *TradingBot) needsAccountSummary() bool {
+	rm := bot.config.Trading.RiskManagement
+	return bot.config.Trading.AmountEquityPercent > 0 || bot.sizingEnabled() || rm.MaxMarginRatio > 0 || rm.MarginTopUp.Enable
+}
+
+// refreshAccount 刷新账户权益和保证金概况（查询失败时为nil）
+func (bot *TradingBot) refreshAccount() {
+	bot.account = nil
+	if !bot.needsAccountSummary() {
+		return
+	}
+	account, err := bot.exchange.FetchAccountSummary(bot.config.Trading.SymbolB)
+	if err != nil {
+		logger.Printf("[WARNING] 获取账户权益失败: %v", err)
+		return
+	}
+	bot.account = account
+	logger.Printf("[INFO] 账户权益: %.2f %s, 可用保证金: %.2f, 维持保证金: %.2f (维持保证金率 %.2f%%)",
+		account.TotalEquity, account.Currency, account.AvailableMargin, account.MaintenanceMargin, account.MarginRatio())
+}
+
+// orderAmount 本次交易金额（计价币种）
+// 配置 position_sizing 时按其计算方式计算，否则配置 amount_equity_percent 时按账户权益的百分比计算，权益未知时使用 amount；有AI建议的仓位比例时按比例缩减；
+// 配置 max_loss_per_trade 时不超过按止损距离计算的最大交易金额
+func (bot *TradingBot) orderAmount() float64 {
+	amount := bot.baseOrderAmount()
+	if maxAmount, _ := bot.maxLossAmount(); maxAmount > 0 && amount > maxAmount {
+		return maxAmount
+	}
+	return amount
+}
+
+// baseOrderAmount 未经单笔最大亏损限制的交易金额
+func (bot *TradingBot) baseOrderAmount() float64 {
+	amount := bot.config.Trading.Amount
+	if sized, _, ok := bot.sizingAmount(); ok {
+		amount = sized
+	} else if percent := bot.config.Trading.AmountEquityPercent; percent > 0 && bot.account != nil && bot.account.TotalEquity > 0 {
+		amount = bot.account.TotalEquity * percent / 100
+	}
+	if bot.sizeFraction > 0 {
+		amount *= bot.sizeFraction
+	}
+	return amount
+}
+
+// maxLossAmount 按单笔最大亏损和止损距离计算的最大交易金额及止损百分比
+// 止损触发时亏损 = 交易金额 × 止损百分比，因此最大交易金额 = 单笔最大亏损 / 止损百分比；未配置或未启用止损时返回0（不限制）
+func (bot *TradingBot) maxLossAmount() (float64, float64) {
+	maxLoss := bot.config.Trading.RiskManagement.MaxLossPerTrade
+	if maxLoss <= 0 || bot.riskManager == nil {
+		return 0, 0
+	}
+	stopLossPercent := bot.riskManager.StopLossPercent()
+	if stopLossPercent <= 0 {
+		return 0, 0
+	}
+	return maxLoss / (stopLossPercent / 100), stopLossPercent
+}
+
+// logOrderSizing 记录单笔最大亏损的仓位计算过程
+func (bot *TradingBot) logOrderSizing() {
+	maxLoss := bot.config.Trading.RiskManagement.MaxLossPerTrade
+	if maxLoss <= 0 {
+		return
+	}
+	symbolB := bot.config.Trading.SymbolB
+	maxAmount, stopLossPercent := bot.maxLossAmount()
+	if maxAmount <= 0 {
+		logger.Printf("[仓位计算] 未启用止损，无法按单笔最大亏损 %.2f %s 限制交易金额", maxLoss, symbolB)
+		return
+	}
+	amount := bot.baseOrderAmount()
+	if amount > maxAmount {
+		logger.Printf("[仓位计算] 单笔最大亏损 %.2f %s / 止损距离 %.2f%% = 最大交易金额 %.2f %s，交易金额由 %.2f 调整为 %.2f",
+			maxLoss, symbolB, stopLossPercent, maxAmount, symbolB, amount, maxAmount)
+		return
+	}
+	logger.Printf("[仓位计算] 交易金额 %.2f %s，止损距离 %.2f%%，预计最大亏损 %.2f %s (上限 %.2f)",
+		amount, symbolB, stopLossPercent, amount*stopLossPercent/100, symbolB, maxLoss)
+}
+
+// passMarginRatioGate 维持保证金率超过上限时暂停开仓（平仓不受影响），权益未知时放行
+func (bot *TradingBot) passMarginRatioGate(signal *models.TradeSignal) (bool, string) {
+	maxRatio := bot.config.Trading.RiskManagement.MaxMarginRatio
+	if maxRatio <= 0 || bot.account == nil {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	ratio := bot.account.MarginRatio()
+	detail := fmt.Sprintf("维持保证金率 %.2f%% (上限 %.2f%%)", ratio, maxRatio)
+	if ratio > maxRatio {
+		logger.Warnf("[风险管理] ⚠️ %s，暂停开仓", detail)
+		return false, detail
+	}
+	return true, detail
+}
+
+// topUpMargin 交易账户可用保证金低于下限时从资金账户划转补充，每个交易日划转总额不超过 max_daily
+func (bot *TradingBot) topUpMargin() {
+	cfg := bot.config.Trading.RiskManagement.MarginTopUp
+	if !cfg.Enable || bot.account == nil || bot.account.AvailableMargin >= cfg.MinAvailable {
+		return
+	}
+
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if bot.calendar != nil {
+		day = bot.calendar.TradingDay(now)
+	}
+	if bot.topUp.day != day {
+		bot.topUp = marginTopUpState{day: day}
+	}
+
+	amount := cfg.Amount
+	if cfg.MaxDaily > 0 {
+		amount = math.Min(amount, cfg.MaxDaily-bot.topUp.amount)
+		if amount <= 0 {
+			logger.Printf("[保证金补充] 可用保证金 %.2f 低于下限 %.2f，但今日划转已达上限 %.2f",
+				bot.account.AvailableMargin, cfg.MinAvailable, cfg.MaxDaily)
+			return
+		}
+	}
+
+	currency := bot.config.Trading.SymbolB
+	err := bot.exchange.Transfer(currency, amount, exchange.AccountFunding, exchange.AccountTrading)
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("[保证金补充] 测试模式 - 跳过划转 %.2f %s", amount, currency)
+		return
+	}
+	if err != nil {
+		logger.Warnf("[保证金补充] ⚠️ 从资金账户划转 %.2f %s 失败: %v", amount, currency, err)
+		bot.publish(notify.LevelWarning, "保证金补充失败", fmt.Sprintf("%s 划转 %.2f %s 失败: %v", bot.tradingPair, amount, currency, err))
+		return
+	}
+
+	bot.topUp.amount += amount
+	logger.Printf("[保证金补充] 可用保证金 %.2f 低于下限 %.2f，已从资金账户划转 %.2f %s (今日累计 %.2f)",
+		bot.account.AvailableMargin, cfg.MinAvailable, amount, currency, bot.topUp.amount)
+	bot.publish(notify.LevelInfo, "保证金已补充", fmt.Sprintf("%s 可用保证金 %.2f，已从资金账户划转 %.2f %s",
+		bot.tradingPair, bot.account.AvailableMargin, amount, currency))
+	bot.refreshAccount()
+}
+
+func (bot *TradingBot) publish(level notify.Level, title, message string) {
+	if bot.notifier != nil {
+		bot.notifier.Publish(level, title, message)
+	}
+}
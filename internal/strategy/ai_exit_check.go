@@ -0,0 +1,188 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// aiExitTimeout 单次AI离场询问的超时时间（询问在后台进行，超时后放弃本次意见）
+const aiExitTimeout = 20 * time.Second
+
+// aiExitBudget AI离场询问的调用预算
+type aiExitBudget struct {
+	positionCalls int       // 当前持仓已询问次数
+	lastCall      time.Time // 最近一次询问时间
+	day           string    // 当前统计的交易日
+	dayCalls      int       // 当前交易日已询问次数
+
+	asking  bool           // 后台询问进行中
+	verdict *aiExitVerdict // 已返回、尚未采纳的离场意见
+}
+
+// aiExitVerdict 后台询问得到的离场意见（仅对发起询问时的持仓有效）
+type aiExitVerdict struct {
+	side       string
+	entryPrice float64
+	reason     string
+}
+
+// aiExitSettings 获取AI离场检查参数（未配置项使用默认值）
+func (rm *RiskManager) aiExitSettings() config.AIExitCheckConfig {
+	cfg := rm.config.Trading.RiskManagement.AIExitCheck
+	if cfg.TriggerRatio <= 0 || cfg.TriggerRatio >= 1 {
+		cfg.TriggerRatio = 0.6
+	}
+	if cfg.MinConfidence != "MEDIUM" {
+		cfg.MinConfidence = "HIGH"
+	}
+	if cfg.MaxCallsPerPosition <= 0 {
+		cfg.MaxCallsPerPosition = 2
+	}
+	if cfg.MaxCallsPerDay <= 0 {
+		cfg.MaxCallsPerDay = 10
+	}
+	if cfg.MinIntervalSeconds <= 0 {
+		cfg.MinIntervalSeconds = 300
+	}
+	return cfg
+}
+
+// effectiveStop 获取当前生效的止损价（移动止损优先，0表示未设置）
+func (rm *RiskManager) effectiveStop(pos *models.Position) float64 {
+	cfg := rm.config.Trading.RiskManagement
+	if cfg.EnableTrailingStop && pos.TrailingStop > 0 {
+		return pos.TrailingStop
+	}
+	if cfg.EnableStopLoss && pos.StopLoss > 0 {
+		return pos.StopLoss
+	}
+	return 0
+}
+
+// shouldExitEarly 不利波动接近止损时询问AI是否提前离场
+// 硬性规则：仅在亏损方向、止损距离走完触发比例、预算未用尽时询问；仅采纳达到最低信心的EXIT意见
+// 询问在后台进行，风控检查不等待AI响应，只读取上次询问返回的意见，避免慢请求拖住所有交易对的止盈止损
+func (rm *RiskManager) shouldExitEarly(ctx context.Context, pos *models.Position, currentPrice float64) bool {
+	cfg := rm.aiExitSettings()
+	if !cfg.Enable || rm.aiClient == nil {
+		return false
+	}
+
+	rm.mu.Lock()
+	stop := rm.effectiveStop(pos)
+	snapshot := *pos
+	rm.mu.Unlock()
+	if stop == 0 || pos.EntryPrice == 0 {
+		return false
+	}
+
+	// 计算已走完的止损距离比例（止损价已移动到盈利区时不询问）
+	var progress float64
+	if pos.Side == "long" {
+		if stop >= pos.EntryPrice {
+			return false
+		}
+		progress = (pos.EntryPrice - currentPrice) / (pos.EntryPrice - stop)
+	} else {
+		if stop <= pos.EntryPrice {
+			return false
+		}
+		progress = (currentPrice - pos.EntryPrice) / (stop - pos.EntryPrice)
+	}
+
+	// 取出上次询问返回的意见（价格已回到触发比例以内时作废）
+	rm.mu.Lock()
+	verdict := rm.aiExit.verdict
+	rm.aiExit.verdict = nil
+	rm.mu.Unlock()
+	if progress < cfg.TriggerRatio {
+		return false
+	}
+	if verdict != nil && verdict.side == pos.Side && verdict.entryPrice == pos.EntryPrice {
+		logger.Printf("[风险管理] ⚠️ 采纳AI建议提前离场 - 当前价:%.2f, 止损价:%.2f", currentPrice, stop)
+		rm.publish(notify.LevelWarning, "AI建议提前离场",
+			fmt.Sprintf("%s %s仓 当前价:%.2f, 止损价:%.2f, 理由: %s", rm.tradingPair, pos.Side, currentPrice, stop, verdict.reason))
+		return true
+	}
+
+	if !rm.reserveAIExitCall(cfg) {
+		return false
+	}
+
+	logger.Printf("[风险管理] [%s] 不利波动已达止损距离的 %.0f%%，询问AI是否提前离场",
+		rm.tradingPair, progress*100)
+	go rm.askExitOpinion(ctx, cfg, &snapshot, currentPrice, stop)
+	return false
+}
+
+// askExitOpinion 后台询问AI是否提前离场，达到最低信心的EXIT意见留待下次检查采纳
+func (rm *RiskManager) askExitOpinion(ctx context.Context, cfg config.AIExitCheckConfig, pos *models.Position, currentPrice, stop float64) {
+	defer func() {
+		rm.mu.Lock()
+		rm.aiExit.asking = false
+		rm.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, aiExitTimeout)
+	defer cancel()
+	opinion, err := rm.aiClient.AskExitOpinion(ctx, rm.tradingPair, pos, currentPrice, stop)
+	if err != nil {
+		logger.Warnf("[风险管理] [%s] AI离场询问失败，继续按止损规则执行: %v", rm.tradingPair, err)
+		return
+	}
+
+	logger.Printf("[风险管理] [%s] AI离场意见: %s (信心:%s) - %s",
+		rm.tradingPair, opinion.Action, opinion.Confidence, opinion.Reason)
+
+	if opinion.Action != "EXIT" {
+		return
+	}
+	if opinion.Confidence != "HIGH" && !(cfg.MinConfidence == "MEDIUM" && opinion.Confidence == "MEDIUM") {
+		logger.Printf("[风险管理] [%s] AI离场信心不足(%s < %s)，继续持有", rm.tradingPair, opinion.Confidence, cfg.MinConfidence)
+		return
+	}
+
+	rm.mu.Lock()
+	rm.aiExit.verdict = &aiExitVerdict{side: pos.Side, entryPrice: pos.EntryPrice, reason: opinion.Reason}
+	rm.mu.Unlock()
+}
+
+// reserveAIExitCall 检查并占用一次询问预算
+func (rm *RiskManager) reserveAIExitCall(cfg config.AIExitCheckConfig) bool {
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if rm.calendar != nil {
+		day = rm.calendar.TradingDay(now)
+	}
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.aiExit.day != day {
+		rm.aiExit.day = day
+		rm.aiExit.dayCalls = 0
+	}
+
+	if rm.aiExit.asking || rm.aiExit.positionCalls >= cfg.MaxCallsPerPosition {
+		return false
+	}
+	if rm.aiExit.dayCalls >= cfg.MaxCallsPerDay {
+		logger.Debugf("[风险管理] [%s] 今日AI离场询问次数已用尽 (%d)", rm.tradingPair, cfg.MaxCallsPerDay)
+		return false
+	}
+	if now.Sub(rm.aiExit.lastCall) < time.Duration(cfg.MinIntervalSeconds)*time.Second {
+		return false
+	}
+
+	rm.aiExit.positionCalls++
+	rm.aiExit.dayCalls++
+	rm.aiExit.lastCall = now
+	rm.aiExit.asking = true
+	return true
+}
@@ -0,0 +1,138 @@
+package strategy
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// slowExitProvider 离场询问阻塞到 release 关闭或 ctx 取消时返回
+type slowExitProvider struct {
+	stubProvider
+	release chan struct{}
+	opinion models.ExitOpinion
+	calls   atomic.Int32
+}
+
+func (p *slowExitProvider) AskExitOpinion(ctx context.Context, tradingPair string, pos *models.Position, currentPrice, stopPrice float64) (*models.ExitOpinion, error) {
+	p.calls.Add(1)
+	select {
+	case <-p.release:
+		opinion := p.opinion
+		return &opinion, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newAIExitRisk 创建开仓价100、止损90的多头持仓风险管理器
+func newAIExitRisk(t *testing.T, p *slowExitProvider) (*RiskManager, *models.Position) {
+	t.Helper()
+	cfg := newTestConfig(config.TradingModeFutures)
+	cfg.Trading.RiskManagement.EnableStopLoss = true
+	cfg.Trading.RiskManagement.StopLossPercent = 10
+	cfg.Trading.RiskManagement.AIExitCheck = config.AIExitCheckConfig{Enable: true}
+	m, symbol := newTestExchange(cfg)
+	if _, err := m.PlaceOrder(symbol, "buy", 1, map[string]interface{}{"posSide": "long"}); err != nil {
+		t.Fatal(err)
+	}
+	pos, _ := m.FetchPosition(symbol)
+	rm := NewRiskManager(cfg, m, testPair)
+	rm.aiClient = p
+	rm.UpdatePosition(pos)
+	return rm, pos
+}
+
+// waitAIExitIdle 等待后台询问返回
+func waitAIExitIdle(t *testing.T, rm *RiskManager) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		rm.mu.Lock()
+		asking := rm.aiExit.asking
+		rm.mu.Unlock()
+		if !asking {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("后台AI离场询问未返回")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// AI响应慢时风控检查不等待，下次检查采纳返回的离场意见
+func TestAIExitCheckAsync(t *testing.T) {
+	p := &slowExitProvider{
+		release: make(chan struct{}),
+		opinion: models.ExitOpinion{Action: "EXIT", Confidence: "HIGH", Reason: "跌破支撑"},
+	}
+	rm, pos := newAIExitRisk(t, p)
+
+	start := time.Now()
+	if rm.shouldExitEarly(context.Background(), pos, 92) {
+		t.Fatal("询问未返回时提前离场")
+	}
+	if rm.shouldExitEarly(context.Background(), pos, 92) {
+		t.Fatal("询问未返回时提前离场")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("风控检查等待AI响应 %s", elapsed)
+	}
+	close(p.release)
+	waitAIExitIdle(t, rm)
+	if n := p.calls.Load(); n != 1 {
+		t.Fatalf("询问次数 = %d, 期望询问进行中不重复询问", n)
+	}
+
+	if !rm.shouldExitEarly(context.Background(), pos, 92) {
+		t.Fatal("未采纳已返回的离场意见")
+	}
+	if rm.shouldExitEarly(context.Background(), pos, 92) {
+		t.Fatal("离场意见被重复采纳")
+	}
+}
+
+func TestAIExitCheckVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		opinion  models.ExitOpinion
+		cancel   bool    // 询问返回前取消风控循环
+		price    float64 // 采纳时的价格
+		wantExit bool
+	}{
+		{name: "高信心离场", opinion: models.ExitOpinion{Action: "EXIT", Confidence: "HIGH"}, price: 92, wantExit: true},
+		{name: "信心不足", opinion: models.ExitOpinion{Action: "EXIT", Confidence: "MEDIUM"}, price: 92},
+		{name: "继续持有", opinion: models.ExitOpinion{Action: "HOLD", Confidence: "HIGH"}, price: 92},
+		{name: "价格回到触发比例以内时作废", opinion: models.ExitOpinion{Action: "EXIT", Confidence: "HIGH"}, price: 99},
+		{name: "风控循环取消", opinion: models.ExitOpinion{Action: "EXIT", Confidence: "HIGH"}, cancel: true, price: 92},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &slowExitProvider{release: make(chan struct{}), opinion: tt.opinion}
+			rm, pos := newAIExitRisk(t, p)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rm.shouldExitEarly(ctx, pos, 92)
+			if tt.cancel {
+				cancel()
+			} else {
+				close(p.release)
+			}
+			waitAIExitIdle(t, rm)
+
+			if got := rm.shouldExitEarly(context.Background(), pos, tt.price); got != tt.wantExit {
+				t.Fatalf("提前离场 = %v, 期望 %v", got, tt.wantExit)
+			}
+			if rm.shouldExitEarly(context.Background(), pos, 92) {
+				t.Fatal("意见作废后仍提前离场")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// defaultMinSizeFraction AI建议仓位比例的默认下限
+const defaultMinSizeFraction = 0.1
+
+// applySuggestion 按AI信号的建议设置本轮新开仓的止盈止损和仓位比例（未启用、非开仓信号或未给出时使用固定值）
+func (bot *TradingBot) applySuggestion(signal *models.TradeSignal, marketData *models.MarketData) {
+	bot.sizeFraction = 0
+	cfg := bot.config.Trading.AISuggestions
+	opening := signalSide(signal.Signal) != "" && !(bot.config.IsSpotMode() && signal.Signal == "SELL")
+
+	// 风险管理器仅在合约模式下创建，BUY/SELL 均为开仓信号（双向持仓时仅设置开仓方向的风险管理器）
+	if bot.hedge {
+		if rm := bot.legRisk(signalSide(signal.Signal)); rm != nil {
+			rm.ApplySuggestion(signal, marketData.Price)
+		}
+	} else if bot.riskManager != nil {
+		bot.riskManager.ApplySuggestion(signal, marketData.Price)
+	}
+
+	if !cfg.Enable || !opening || signal.SizeFraction <= 0 {
+		return
+	}
+	minFraction := cfg.MinSizeFraction
+	if minFraction <= 0 {
+		minFraction = defaultMinSizeFraction
+	}
+	bot.sizeFraction = math.Min(math.Max(signal.SizeFraction, minFraction), 1)
+	logger.Printf("[AI建议] 仓位比例: %.2f (建议 %.2f，下限 %.2f)", bot.sizeFraction, signal.SizeFraction, minFraction)
+}
+
+// passConfidenceScore 启用AI建议且配置最低信心分数时，给出的信心分数不低于下限（未给出分数时放行）
+func (bot *TradingBot) passConfidenceScore(signal *models.TradeSignal) (bool, string) {
+	cfg := bot.config.Trading.AISuggestions
+	if !cfg.Enable || cfg.MinConfidenceScore <= 0 || signal.ConfidenceScore <= 0 {
+		return true, ""
+	}
+	detail := fmt.Sprintf("信心分数 %d (下限 %d)", signal.ConfidenceScore, cfg.MinConfidenceScore)
+	return signal.ConfidenceScore >= cfg.MinConfidenceScore, detail
+}
+
+// ApplySuggestion 按AI信号建议的止损价、止盈价设置新开仓的止盈止损百分比（限制在配置范围内）
+// 未启用、未给出或价格位于开仓价错误一侧时恢复使用固定百分比；price: 参考开仓价
+func (rm *RiskManager) ApplySuggestion(signal *models.TradeSignal, price float64) {
+	var stopLoss, takeProfit float64
+	cfg := rm.config.Trading.AISuggestions
+	rmCfg := rm.config.Trading.RiskManagement
+	side := signalSide(signal.Signal)
+	if cfg.Enable && side != "" && price > 0 {
+		direction := 1.0
+		if side == "short" {
+			direction = -1.0
+		}
+		if signal.StopLoss > 0 {
+			stopLoss = rm.suggestedPercent("止损", direction*(price-signal.StopLoss)/price*100,
+				boundOrDefault(cfg.MinStopLossPercent, rmCfg.StopLossPercent/2),
+				boundOrDefault(cfg.MaxStopLossPercent, rmCfg.StopLossPercent*2))
+		}
+		if signal.TakeProfit > 0 {
+			takeProfit = rm.suggestedPercent("止盈", direction*(signal.TakeProfit-price)/price*100,
+				boundOrDefault(cfg.MinTakeProfitPercent, rmCfg.TakeProfitPercent/2),
+				boundOrDefault(cfg.MaxTakeProfitPercent, rmCfg.TakeProfitPercent*2))
+		}
+	}
+
+	rm.mu.Lock()
+	rm.suggestedStopLoss = stopLoss
+	rm.suggestedTakeProfit = takeProfit
+	rm.mu.Unlock()
+}
+
+// suggestedPercent 将建议的止盈/止损距离限制在 [minPercent, maxPercent] 内（maxPercent 为0时不限上限），方向错误时返回0
+func (rm *RiskManager) suggestedPercent(label string, percent, minPercent, maxPercent float64) float64 {
+	if percent <= 0 {
+		logger.Warnf("[风险管理] [%s] AI建议%s价位于开仓价错误一侧，使用固定百分比", rm.tradingPair, label)
+		return 0
+	}
+	bounded := math.Max(percent, minPercent)
+	if maxPercent > 0 {
+		bounded = math.Min(bounded, maxPercent)
+	}
+	logger.Printf("[风险管理] [%s] AI建议%s距离: %.2f%% (建议 %.2f%%，范围 %.2f%% ~ %.2f%%)",
+		rm.tradingPair, label, bounded, percent, minPercent, maxPercent)
+	return bounded
+}
+
+// boundOrDefault 配置的范围值（未配置时使用默认值）
+func boundOrDefault(value, fallback float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+)
+
+// AnalysisCollection AI分析快照在持久化存储中的集合名
+const AnalysisCollection = "analysis_snapshots"
+
+// AnalysisRecord 一轮AI分析的输入和输出，用于以新的提示词或模型重放历史行情
+type AnalysisRecord struct {
+	ID          string              `json:"id"`
+	TradingPair string              `json:"trading_pair"`
+	SymbolA     string              `json:"symbol_a"`
+	Model       string              `json:"model"`
+	Balance     float64             `json:"balance"`
+	Position    *models.Position    `json:"position,omitempty"`
+	MarketData  *models.MarketData  `json:"market_data"`
+	Signal      *models.TradeSignal `json:"signal"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// SetAnalysisArchive 设置AI分析快照的持久化存储（nil 表示不记录）
+func (bot *TradingBot) SetAnalysisArchive(s store.Store) {
+	bot.analysisArchive = s
+}
+
+// archiveAnalysis 记录本轮AI分析的市场数据和信号（写入失败不影响交易流程）
+func (bot *TradingBot) archiveAnalysis(marketData *models.MarketData, signal *models.TradeSignal, balance float64) {
+	if bot.analysisArchive == nil {
+		return
+	}
+
+	now := time.Now()
+	record, err := json.Marshal(AnalysisRecord{
+		ID:          fmt.Sprintf("%s-%d", bot.tradingPair, now.UnixNano()),
+		TradingPair: bot.tradingPair,
+		SymbolA:     bot.config.Trading.SymbolA,
+		Model:       bot.aiClient.Model(),
+		Balance:     balance,
+		Position:    bot.currentPosition,
+		MarketData:  marketData,
+		Signal:      signal,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		logger.Warnf("[分析快照] 序列化失败: %v", err)
+		return
+	}
+	if err := bot.analysisArchive.Append(AnalysisCollection, record); err != nil {
+		logger.Warnf("[分析快照] 写入存储失败: %v", err)
+	}
+}
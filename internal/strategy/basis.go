@@ -0,0 +1,89 @@
+package strategy
+
+import (
+	"sync"
+	"time"
+
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// basisSamples 计算近期平均溢价的采样数（每轮分析采样一次）
+const basisSamples = 96
+
+// basisTracker 记录每轮分析时合约相对现货的溢价，供提示词和控制接口读取
+type basisTracker struct {
+	spot    exchange.Exchange // 同一交易所的现货客户端（仅获取行情）
+	symbol  string            // 现货交易对符号
+	mu      sync.Mutex
+	samples []float64
+	latest  *models.SpotBasis
+}
+
+// SetSpotMarket 设置同一交易所的现货客户端，每轮分析计算合约相对现货的溢价（仅合约模式）
+func (bot *TradingBot) SetSpotMarket(spot exchange.Exchange) {
+	if bot.config.IsSpotMode() {
+		return
+	}
+	bot.basis = &basisTracker{
+		spot:   spot,
+		symbol: spot.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
+	}
+}
+
+// LatestBasis 最近一轮分析的合约相对现货溢价（未启用基差监控或尚未获取时为nil）
+func (bot *TradingBot) LatestBasis() *models.SpotBasis {
+	if bot.basis == nil {
+		return nil
+	}
+	bot.basis.mu.Lock()
+	defer bot.basis.mu.Unlock()
+	if bot.basis.latest == nil {
+		return nil
+	}
+	latest := *bot.basis.latest
+	return &latest
+}
+
+// fetchSpotBasis 获取现货价格并计算合约（perpPrice）相对现货的溢价（失败不影响分析）
+func (bot *TradingBot) fetchSpotBasis(perpPrice float64) *models.SpotBasis {
+	if bot.basis == nil || perpPrice <= 0 {
+		return nil
+	}
+	ticker, err := bot.basis.spot.FetchTicker(bot.basis.symbol)
+	if err != nil {
+		logger.Debugf("[DEBUG] 获取现货价格失败: %v", err)
+		return nil
+	}
+	if ticker.Last <= 0 {
+		return nil
+	}
+	return bot.basis.add(ticker.Last, perpPrice)
+}
+
+// add 记录一次采样，返回包含近期平均溢价的结果
+func (t *basisTracker) add(spotPrice, perpPrice float64) *models.SpotBasis {
+	percent := basisPercent(spotPrice, perpPrice)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, percent)
+	if len(t.samples) > basisSamples {
+		t.samples = t.samples[len(t.samples)-basisSamples:]
+	}
+	var sum float64
+	for _, s := range t.samples {
+		sum += s
+	}
+	t.latest = &models.SpotBasis{
+		SpotPrice:      spotPrice,
+		PerpPrice:      perpPrice,
+		Percent:        percent,
+		AveragePercent: sum / float64(len(t.samples)),
+		Samples:        len(t.samples),
+		Time:           time.Now(),
+	}
+	latest := *t.latest
+	return &latest
+}
@@ -1,56 +1,181 @@
 package strategy
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"dsbot/internal/ai"
+	"dsbot/internal/calendar"
 	"dsbot/internal/config"
+	"dsbot/internal/datafeeds"
+	"dsbot/internal/embargo"
 	"dsbot/internal/exchange"
 	"dsbot/internal/indicator"
+	"dsbot/internal/journal"
 	"dsbot/internal/logger"
 	"dsbot/internal/models"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
+	"dsbot/internal/tracing"
 )
 
+// orderBookDepth 盘口深度档位
+const orderBookDepth = 20
+
 // TradingBot 交易机器人
 type TradingBot struct {
 	config          *config.Config
-	exchange        exchange.Exchange // 使用接口而不是具体实现
-	aiClient        *ai.DeepSeekClient
+	exchange        exchange.Exchange          // 使用接口而不是具体实现（经过下单通道）
+	gateway         *exchange.ExecutionGateway // 下单通道（测试模式下拦截真实下单）
+	aiClient        ai.Provider
 	calculator      *indicator.Calculator
 	currentPosition *models.Position
-	tradingPair     string       // 交易对标识 (如 "BTC-USDT")
-	riskManager     *RiskManager // 风险管理器
+	tradingPair     string                   // 交易对标识 (如 "BTC-USDT")
+	riskManager     *RiskManager             // 风险管理器
+	journal         *journal.Journal         // 交易日志（可选）
+	embargo         *embargo.List            // 禁止交易名单（可选）
+	news            *datafeeds.NewsFeed      // 新闻标题源（可选，加入AI分析提示词）
+	fearGreed       *datafeeds.FearGreedFeed // 恐惧贪婪指数（可选，加入AI分析提示词）
+	decidedAt       time.Time                // 本轮AI给出决策的时间
+	feeRate         *models.FeeRate          // 手续费率（获取失败时为nil）
+	executor        *ExecutionCoordinator    // 下单协调器（风控平仓优先）
+	riskGeneration  uint64                   // 本轮分析开始时的风控平仓计数
+	intent          *TradeIntent             // 本轮下单意图记录（无信号时为nil）
+	intentStore     store.Store              // 下单意图持久化存储（可选）
+	analysisArchive store.Store              // AI分析快照持久化存储（可选）
+	balance         float64                  // 本轮获取的计价币种可用余额（获取失败时为-1）
+	account         *models.AccountSummary   // 本轮获取的账户权益和保证金（未启用或获取失败时为nil）
+	topUp           marginTopUpState         // 保证金自动补充的当日划转统计
+	dailyLoss       *dailyLossState          // 每日亏损上限的当日状态（与风险管理器共用）
+	notifier        notify.Publisher         // 通知发布器（可选）
+	calendar        *calendar.Calendar       // 交易日历（可选）
+	cadence         time.Duration            // 当前执行间隔（自适应执行频率，未调整时为0）
+	onCadenceChange func(time.Duration)      // 执行频率变化回调（可选）
+	lease           *PairLease               // 交易对租约（可选，多进程分担交易对时使用）
+	failover        *Failover                // 主备切换（可选，备用实例不分析下单）
+	span            *tracing.Span            // 本轮交易周期的根span（未启用追踪时为nil）
+	gateSpan        *tracing.Span            // 下单前检查的span
+	pendingEntry    *pendingEntry            // 等待突破入场的开仓信号（可选）
+	sizeFraction    float64                  // 本轮AI建议的仓位比例（0表示使用满额交易金额）
+	lastOI          float64                  // 上一轮分析的全市场持仓量（计算持仓量变化）
+	lastSignal      *cachedSignal            // 最近一根K线的AI信号（同一根K线内复用）
+	signalStore     store.Store              // 最近信号的持久化存储（可选）
+	coach           coachState               // AI参数调优状态
+	portfolio       *PortfolioLimits         // 跨交易对的全局风险限制（可选，多交易对共用）
+	atrPercent      float64                  // 本轮ATR占价格的百分比（按ATR计算仓位时使用，未知时为0）
+	events          *eventRecorder           // 交易事件记录器（可选）
+	cycleMu         sync.Mutex               // 交易周期与突破入场检查互斥
+
+	hedge     bool                        // 双向持仓（多空仓位可同时存在，各由一个风险管理器监控）
+	legs      map[string]*RiskManager     // 双向持仓各方向的风险管理器（legs["long"] 即 riskManager）
+	positions map[string]*models.Position // 双向持仓本轮获取的各方向持仓
+
+	basis    *basisTracker      // 合约相对现货的溢价（可选，未启用基差监控时为nil）
+	sessions *calendar.Sessions // 交易时段（可选，时段外和禁止开仓时间段内不开仓）
+
+	limitEntry      bool   // 开仓使用限价单（启动时按配置和交易所能力确定）
+	entryFallbackID string // 限价开仓单部分成交后剩余数量的市价补单ID（verifyEntry 合并成交后清空）
+	entryUnfilled   bool   // 限价开仓单部分成交后撤单或撤单后状态未知（entrySize 按交易所持仓记录开仓后清空）
+
+	closeOnly string // 本轮反向信号只平仓不反手的原因（开仓检查未通过但允许平仓时设置）
+
+	reservedLeg string // 本轮预占全局持仓额度的交易对（未下单时撤销，见 releasePortfolio）
+
+	entryAttempt uint64 // 开仓尝试代数（持仓消失或放弃开仓后+1，写入意图订单ID，同一根K线内重新开仓时不复用旧订单ID）
 }
 
 // NewTradingBot 创建交易机器人 - 使用依赖注入
-func NewTradingBot(cfg *config.Config, exch exchange.Exchange, aiClient *ai.DeepSeekClient) *TradingBot {
+func NewTradingBot(cfg *config.Config, exch exchange.Exchange, aiClient ai.Provider) *TradingBot {
 	// 构建交易对标识
 	tradingPair := fmt.Sprintf("%s-%s", cfg.Trading.SymbolA, cfg.Trading.SymbolB)
 
+	// 所有下单操作经过下单通道：测试模式下仅允许模拟撮合交易所下单
+	_, simulated := exch.(*exchange.MockExchange)
+	gateway := exchange.NewExecutionGateway(exch, !cfg.Trading.TestMode || simulated)
+	exch = gateway
+
 	bot := &TradingBot{
 		config:      cfg,
 		exchange:    exch,
+		gateway:     gateway,
 		aiClient:    aiClient,
 		calculator:  indicator.NewCalculatorWithConfig(indicator.AggressiveConfig()), // indicator.NewCalculator(),
 		tradingPair: tradingPair,
+		executor:    NewExecutionCoordinator(),
+		dailyLoss:   &dailyLossState{},
 	}
 
 	// 创建风险管理器（仅在合约模式下）
 	if cfg.IsFuturesMode() &&
 		(cfg.Trading.RiskManagement.EnableStopLoss || cfg.Trading.RiskManagement.EnableTakeProfit) {
 		bot.riskManager = NewRiskManager(cfg, exch, tradingPair)
+		bot.riskManager.executor = bot.executor
+		bot.riskManager.aiClient = aiClient
+	}
+
+	// 双向持仓：多空仓位各由一个风险管理器独立监控止盈止损
+	bot.hedge = hedgeSupported(cfg, exch)
+	bot.limitEntry = limitSupported(cfg, exch)
+	if bot.hedge && bot.riskManager != nil {
+		short := NewRiskManager(cfg, exch, tradingPair)
+		short.executor = bot.executor
+		short.aiClient = aiClient
+		bot.riskManager.leg, short.leg = "long", "short"
+		bot.legs = map[string]*RiskManager{"long": bot.riskManager, "short": short}
+	}
+	for _, rm := range bot.riskManagers() {
+		rm.dailyLoss = bot.dailyLoss
 	}
 
 	return bot
 }
 
-// Run 执行交易流程
-func (bot *TradingBot) Run() error {
+// Run 执行交易流程（启用链路追踪时整轮记录为一个 trace）
+// ctx 取消时（进程退出、调度器重启）中止进行中的AI请求，并且不再下单
+func (bot *TradingBot) Run(ctx context.Context) error {
+	bot.cycleMu.Lock()
+	defer bot.cycleMu.Unlock()
+
+	bot.span = tracing.StartSpan("trading_cycle", nil)
+	bot.span.SetAttribute("trading_pair", bot.tradingPair)
+	if traceID := bot.span.TraceID(); traceID != "" {
+		logger.Debugf("[DEBUG] trace_id: %s", traceID)
+	}
+
+	err := bot.runCycle(ctx)
+	bot.span.RecordError(err)
+	bot.span.End()
+	bot.span = nil
+	return err
+}
+
+// runCycle 执行一轮交易流程
+func (bot *TradingBot) runCycle(ctx context.Context) error {
 	logger.Println("============================================================")
 	logger.Printf("执行时间: %s", time.Now().Format("2006-01-02 15:04:05"))
 	logger.Println("============================================================")
 
+	// 交易对由其他工作进程持有时跳过本轮
+	if bot.lease != nil && !bot.lease.Held() {
+		logger.Printf("[租约] %s 由其他工作进程处理，本轮跳过", bot.tradingPair)
+		return nil
+	}
+
+	// 备用实例不分析下单（接管期间风控由主备切换定期同步持仓）
+	if bot.failover != nil && bot.failover.IsStandby() {
+		logger.Printf("[主备] 备用实例不执行分析下单，本轮跳过")
+		return nil
+	}
+
+	// 记录风控平仓计数，下单前用于判断分析期间是否发生过风控平仓
+	bot.riskGeneration = bot.executor.RiskGeneration()
+
+	// 应用参数调优的新参数（在计算指标前）
+	bot.applyPendingTuning()
+
 	// 1. 获取市场数据
 	marketData, err := bot.fetchMarketData()
 	if err != nil {
@@ -61,10 +186,45 @@ func (bot *TradingBot) Run() error {
 	logger.Printf("数据周期: %s", bot.config.Trading.Timeframe)
 	logger.Printf("价格变化: %+.2f%%", marketData.PriceChange)
 
+	// 按最新波动率调整新开仓的止盈止损
+	bot.atrPercent = 0
+	if marketData.TechnicalData != nil {
+		bot.atrPercent = marketData.TechnicalData.ATRPercent
+	}
+	if marketData.TechnicalData != nil {
+		for _, rm := range bot.riskManagers() {
+			rm.UpdateVolatility(marketData.TechnicalData.ATRPercent)
+		}
+	}
+
+	// 按波动状态调整执行频率
+	if marketData.TechnicalData != nil {
+		bot.updateCadence(marketData.TechnicalData.ATRPercent)
+	}
+
+	// 定期请求AI评估近期表现并建议参数调整（后台进行）
+	bot.maybeCoach(ctx, marketData)
+
 	// 2. 获取当前持仓
-	bot.currentPosition, err = bot.exchange.FetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	held := bot.heldSides()
+	bot.currentPosition, err = bot.fetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	if err == nil {
+		bot.notePositionLoss(held)
+	}
 	if err != nil {
+		// 认证失败时后续下单必然失败，直接终止本轮
+		if exchange.IsKind(err, exchange.ErrorKindAuthFailed) {
+			return fmt.Errorf("交易所认证失败: %w", err)
+		}
 		logger.Printf("获取持仓失败: %v", err)
+	} else if bot.hedge {
+		// 双向持仓：多空仓位分别同步到对应的风险管理器，已在外部平掉的方向补记平仓
+		bot.syncLegs()
+		for _, side := range positionSides {
+			if bot.positions[side] == nil && bot.journal != nil && bot.journal.OpenLeg(bot.tradingPair, side) != nil {
+				bot.journalClose(side, marketData, marketData.Price, nil, "持仓已不存在")
+			}
+		}
 	} else if bot.currentPosition != nil {
 		// 调试：打印持仓详细信息
 		logger.Debugf("[DEBUG] 持仓详情 - 方向:%s, 数量:%.8f, 开仓价:%.2f, 未实现盈亏:%.2f USDT",
@@ -80,54 +240,115 @@ func (bot *TradingBot) Run() error {
 		if bot.riskManager != nil {
 			bot.riskManager.UpdatePosition(nil)
 		}
+		// 持仓已在外部平掉（交易所止损、手动平仓等），撤销剩余委托并补记平仓
+		if bot.riskManager != nil {
+			bot.riskManager.cancelBracket("持仓已不存在")
+		}
+		if bot.journal != nil && bot.journal.OpenEntry(bot.tradingPair) != nil {
+			bot.journalClose("", marketData, marketData.Price, nil, "持仓已不存在")
+		}
+	}
+	if err == nil {
+		bot.updatePortfolio(marketData.Price)
 	}
 
 	// 3. 获取账户USDT余额
 	usdtBalance := 0.0
+	bot.balance = -1
 	balance, err := bot.exchange.FetchBalance(bot.config.Trading.SymbolB)
 	if err != nil {
 		logger.Printf("[WARNING] 获取%s余额失败: %v", bot.config.Trading.SymbolB, err)
 	} else {
 		usdtBalance = balance
+		bot.balance = balance
 	}
+	bot.refreshAccount()
+	bot.topUpMargin()
 
-	// 4. AI分析生成交易信号 (使用交易对标识来隔离会话)
-	signal, err := bot.aiClient.AnalyzeMarket(bot.tradingPair, marketData, bot.currentPosition, bot.config.Trading.SymbolA, usdtBalance)
-	if err != nil {
-		return fmt.Errorf("AI分析失败: %w", err)
+	// 获取行情和账户期间已取消时不再请求AI
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("交易流程已取消: %w", err)
+	}
+
+	// 4. AI分析生成交易信号 (使用交易对标识来隔离会话)；同一根K线已分析过时复用当时的信号
+	aiSpan := tracing.StartSpan("ai_analysis", bot.span)
+	signal := bot.reusableSignal(marketData)
+	aiSpan.SetAttribute("reused", signal != nil)
+	if signal == nil {
+		signal, err = bot.aiClient.AnalyzeMarket(ctx, bot.tradingPair, marketData, bot.currentPosition, bot.config.Trading.SymbolA, usdtBalance)
+		if err != nil {
+			aiSpan.RecordError(err)
+			aiSpan.End()
+			return fmt.Errorf("AI分析失败: %w", err)
+		}
+		bot.rememberSignal(marketData, signal)
+		bot.archiveAnalysis(marketData, signal, usdtBalance)
 	}
+	aiSpan.SetAttribute("signal", signal.Signal)
+	aiSpan.SetAttribute("confidence", signal.Confidence)
+	aiSpan.SetAttribute("is_fallback", signal.IsFallback)
+	aiSpan.SetAttribute("provider", signal.Provider)
+	aiSpan.End()
+	bot.decidedAt = time.Now()
+	bot.recordEvent(TradeEvent{Type: EventSignal, Signal: signal.Signal, Confidence: signal.Confidence, Price: marketData.Price, Reason: signal.Reason})
 
 	// 注意: 信号历史现在由AI客户端内部管理，无需在Bot中维护
 
+	// 分析期间已取消（进程退出等）时不再下单
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("交易流程已取消: %w", err)
+	}
+
 	// 5. 执行交易
 	return bot.executeTrade(signal, marketData)
 }
 
 // fetchMarketData 获取市场数据并计算技术指标
 func (bot *TradingBot) fetchMarketData() (*models.MarketData, error) {
+	span := tracing.StartSpan("fetch_market_data", bot.span)
+	defer span.End()
+
 	// 获取K线数据
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
 	ohlcvList, err := bot.exchange.FetchOHLCV(
-		bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
+		symbol,
 		bot.config.Trading.Timeframe,
 		bot.config.Trading.DataPoints,
 	)
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
+	span.SetAttribute("candles", len(ohlcvList))
+	dataSource := exchange.OHLCVSourceOf(bot.exchange, symbol)
+	span.SetAttribute("data_source", dataSource)
+	isFallbackData := dataSource != exchange.PrimaryDataSourceOf(bot.exchange)
+	if isFallbackData {
+		logger.Warnf("[行情] ⚠️ 本轮K线数据来自备用数据源 %s", dataSource)
+	}
 
 	if len(ohlcvList) == 0 {
 		return nil, fmt.Errorf("未获取到K线数据")
 	}
 
 	// 计算技术指标
+	indicatorSpan := tracing.StartSpan("indicators", span)
 	techData := bot.calculator.Calculate(ohlcvList)
 	trendAnalysis := bot.calculator.CalculateTrendAnalysis(ohlcvList, techData)
 	levelsAnalysis := bot.calculator.CalculateLevelsAnalysis(ohlcvList, techData)
+	indicatorSpan.End()
 
 	// 获取最新和上一根K线
 	current := ohlcvList[len(ohlcvList)-1]
 	previous := ohlcvList[len(ohlcvList)-2]
 
+	// 获取盘口深度（失败不影响分析）
+	orderBook, err := bot.exchange.FetchOrderBook(symbol, orderBookDepth)
+	if err != nil {
+		logger.Debugf("[DEBUG] 获取盘口深度失败: %v", err)
+		orderBook = nil
+	}
+
 	// 构建市场数据
 	marketData := &models.MarketData{
 		Price:          current.Close,
@@ -141,7 +362,23 @@ func (bot *TradingBot) fetchMarketData() (*models.MarketData, error) {
 		TechnicalData:  techData,
 		TrendAnalysis:  trendAnalysis,
 		LevelsAnalysis: levelsAnalysis,
+		OrderBook:      orderBook,
+		DataSource:     dataSource,
+		IsFallbackData: isFallbackData,
 	}
+	if bot.news != nil {
+		marketData.News = bot.news.Recent(bot.config.Trading.SymbolA)
+	}
+	if bot.fearGreed != nil {
+		marketData.FearGreed = bot.fearGreed.Current()
+	}
+	if !bot.config.IsSpotMode() {
+		marketData.Derivatives = bot.fetchDerivativesStats(symbol)
+		if marketData.SpotBasis = bot.fetchSpotBasis(marketData.Price); marketData.SpotBasis != nil {
+			span.SetAttribute("spot_basis_percent", marketData.SpotBasis.Percent)
+		}
+	}
+	marketData.HigherTimeframes = bot.fetchHigherTimeframes(symbol)
 
 	return marketData, nil
 }
@@ -152,39 +389,111 @@ func (bot *TradingBot) executeTrade(signal *models.TradeSignal, marketData *mode
 	sessionInfo := bot.aiClient.GetSessionInfo(bot.tradingPair)
 	statsStr := ""
 	if sessionInfo != nil {
-		statsStr = " " + sessionInfo.Stats.FormatStats()
+		stats := sessionInfo.StatsSnapshot()
+		statsStr = " " + stats.FormatStats()
 	}
 
 	logger.Printf("交易信号: %s%s", signal.Signal, statsStr)
-	logger.Printf("信心程度: %s", signal.Confidence)
+	if signal.ConfidenceScore > 0 {
+		logger.Printf("信心程度: %s (%d分)", signal.Confidence, signal.ConfidenceScore)
+	} else {
+		logger.Printf("信心程度: %s", signal.Confidence)
+	}
 	logger.Printf("理由: %s", signal.Reason)
+	if signal.Provider != "" {
+		logger.Printf("信号来源: %s", signal.Provider)
+	}
+
+	// 反向信号取消等待中的突破入场
+	if bot.pendingEntry != nil && signal.Signal != "HOLD" && signal.Signal != bot.pendingEntry.signal.Signal {
+		bot.cancelStopEntry("信号反转为 " + signal.Signal)
+	}
+
+	// 双向持仓：按信号方向确定本次处理的仓位（同方向仓位，或 flip 时需先平掉的反向仓位）
+	if bot.hedge {
+		bot.currentPosition = bot.positionForSignal(signal)
+	}
+
+	// 按AI建议设置新开仓的止盈止损和仓位比例
+	bot.applySuggestion(signal, marketData)
+
+	// 记录本轮信号经过的全部下单前检查
+	bot.beginIntent(signal, marketData)
 
 	// 风险管理：低信心信号不执行
-	if signal.Confidence == "LOW" && !bot.config.Trading.TestMode {
+	if !bot.checkGate("confidence", signal.Confidence != "LOW" || bot.config.Trading.TestMode, signal.Confidence) {
 		logger.Println("⚠️ 低信心信号，跳过执行")
+		bot.skipIntent("低信心信号")
+		return nil
+	}
+	if passed, detail := bot.passConfidenceScore(signal); !bot.checkGate("confidence_score", passed || bot.config.Trading.TestMode, detail) {
+		logger.Printf("⚠️ %s，跳过执行", detail)
+		bot.skipIntent("信心分数过低")
 		return nil
 	}
 
-	if bot.config.Trading.TestMode {
+	// 测试模式下仅在使用模拟交易所时下单（订单在本地模拟成交）
+	if !bot.checkGate("test_mode", bot.gateway.TradingEnabled(), "") {
 		logger.Println("测试模式 - 仅模拟交易")
+		bot.skipIntent("测试模式")
 		return nil
 	}
 
 	// HOLD信号不执行
-	if signal.Signal == "HOLD" {
+	if !bot.checkGate("signal", signal.Signal != "HOLD", signal.Signal) {
 		logger.Println("建议观望，不执行交易")
+		bot.skipIntent("观望信号")
+		return nil
+	}
+
+	// 未经连续多轮确认的开仓信号不执行（突破入场触发时信号已确认，不再检查）
+	if passed, detail := bot.passSignalConfirmationGate(signal); !bot.checkGate("signal_confirmation", passed, detail) {
+		bot.skipIntent("等待信号确认")
+		return nil
+	}
+
+	// 禁止交易、交易时段、盈亏比、波动率、期望值、亏损冷却、保证金率、每日亏损、仓位、流动性和全局持仓检查
+	if !bot.passEntryGates(signal, marketData) {
+		return nil
+	}
+
+	// 突破入场：新开仓等待价格突破阻力/支撑位后再下单
+	if bot.armStopEntry(signal, marketData) {
 		return nil
 	}
 
 	// 检查保证金并执行交易
-	return bot.placeOrder(signal, marketData)
+	err := bot.placeOrder(signal, marketData)
+	bot.finishIntent(err)
+	return err
 }
 
 // placeOrder 下单
 func (bot *TradingBot) placeOrder(signal *models.TradeSignal, marketData *models.MarketData) error {
-	// amount配置现在是以symbolB为单位（如USDT），需要转换为symbolA数量（如BTC）
+	// 获取下单权（风控平仓优先）
+	release := bot.executor.Acquire(PriorityStrategy)
+	defer release()
+
+	// 分析期间若发生风控平仓，重新校验后再下单
+	if closed, closedSide := bot.executor.RiskClosedSince(bot.riskGeneration); closed {
+		if !bot.checkGate("risk_close", bot.revalidateAfterRiskClose(signal, closedSide), "分析期间风控已平仓"+closedSide) {
+			bot.skipIntent("分析期间风控已平仓")
+			return nil
+		}
+	}
+
+	// 反向信号只平仓时，反向仓位已不存在（如分析期间已被风控平仓）则不再下单
+	if bot.closeOnly != "" && !bot.reversesPosition(signal) {
+		bot.skipIntent(bot.closeOnly)
+		return nil
+	}
+
+	// 交易金额以symbolB为单位（如USDT），需要转换为symbolA数量（如BTC）
 	// 例如: amount=1000 USDT, price=50000 USDT/BTC => amountInBase=1000/50000=0.02 BTC
-	amountInBase := bot.config.Trading.Amount / marketData.Price
+	bot.logOrderSizing()
+	amountInBase := bot.orderAmount() / marketData.Price
+
+	bot.logSlippageEstimate(signal.Signal, amountInBase)
 
 	// 根据交易模式选择不同的执行逻辑
 	if bot.config.IsSpotMode() {
@@ -196,10 +505,34 @@ func (bot *TradingBot) placeOrder(signal *models.TradeSignal, marketData *models
 	}
 }
 
+// revalidateAfterRiskClose 风控平仓后重新校验信号，返回是否继续下单
+func (bot *TradingBot) revalidateAfterRiskClose(signal *models.TradeSignal, closedSide string) bool {
+	logger.Printf("[INFO] 分析期间风控已平仓(%s)，重新校验持仓", closedSide)
+
+	// 信号方向与刚被风控平掉的方向相同，不立即重新入场
+	if (signal.Signal == "BUY" && closedSide == "long") || (signal.Signal == "SELL" && closedSide == "short") {
+		logger.Printf("⚠️ 信号 %s 与刚被风控平仓的方向相同，跳过本次开仓", signal.Signal)
+		return false
+	}
+
+	// 刷新持仓，避免对已平仓位重复平仓
+	pos, err := bot.fetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	if err != nil {
+		logger.Printf("[WARNING] 重新获取持仓失败: %v，跳过本次交易", err)
+		return false
+	}
+	bot.currentPosition = pos
+	if bot.hedge {
+		bot.currentPosition = bot.positionForSignal(signal)
+	}
+
+	return true
+}
+
 // executeSpotTrade 执行现货交易
 func (bot *TradingBot) executeSpotTrade(signal *models.TradeSignal, amountInBase float64, marketData *models.MarketData) error {
 	logger.Printf("现货交易 - 金额: %.2f %s (约%.8f %s)",
-		bot.config.Trading.Amount, bot.config.Trading.SymbolB,
+		bot.orderAmount(), bot.config.Trading.SymbolB,
 		amountInBase, bot.config.Trading.SymbolA)
 
 	if signal.Signal == "BUY" {
@@ -209,24 +542,29 @@ func (bot *TradingBot) executeSpotTrade(signal *models.TradeSignal, amountInBase
 			logger.Printf("[WARNING] 获取%s余额失败: %v，继续尝试下单", bot.config.Trading.SymbolB, err)
 		} else {
 			logger.Printf("[INFO] 当前%s可用余额: %.2f", bot.config.Trading.SymbolB, usdtBalance)
-			if usdtBalance < bot.config.Trading.Amount {
+			detail := fmt.Sprintf("需要%.2f，可用%.2f", bot.orderAmount(), usdtBalance)
+			if !bot.checkGate("balance", usdtBalance >= bot.orderAmount(), detail) {
+				bot.skipIntent("余额不足")
 				return fmt.Errorf("余额不足: 需要%.2f %s，但只有%.2f %s",
-					bot.config.Trading.Amount, bot.config.Trading.SymbolB,
+					bot.orderAmount(), bot.config.Trading.SymbolB,
 					usdtBalance, bot.config.Trading.SymbolB)
 			}
 		}
 
+		bot.submitIntent()
 		logger.Println("执行买入...")
-		err = bot.exchange.PlaceOrder(
+		orderID, err := bot.submitEntry(
 			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
 			"buy",
 			amountInBase,
-			map[string]interface{}{},
+			bot.withIntent(map[string]interface{}{}, "spot-buy", marketData),
+			nil,
 		)
 		if err != nil {
 			return fmt.Errorf("买入失败: %w", err)
 		}
 		logger.Println("✅ 买入订单执行成功")
+		bot.verifyEntry(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB), orderID)
 
 		// 等待订单成交并更新余额信息
 		time.Sleep(2 * time.Second)
@@ -245,6 +583,7 @@ func (bot *TradingBot) executeSpotTrade(signal *models.TradeSignal, amountInBase
 		logger.Printf("[INFO] 当前%s可用余额: %.8f", bot.config.Trading.SymbolA, btcBalance)
 
 		// 检查是否有足够的币可以卖出
+		bot.checkGate("balance", btcBalance > 0, fmt.Sprintf("需要%.8f，可用%.8f", amountInBase, btcBalance))
 		if btcBalance < amountInBase {
 			// 如果余额不足但有余额，尝试卖出全部
 			if btcBalance > 0 {
@@ -253,21 +592,24 @@ func (bot *TradingBot) executeSpotTrade(signal *models.TradeSignal, amountInBase
 				amountInBase = btcBalance
 			} else {
 				logger.Printf("[ERROR] 没有%s可卖出，跳过本次交易", bot.config.Trading.SymbolA)
+				bot.skipIntent("没有可卖出的余额")
 				return fmt.Errorf("没有%s可卖出，余额为0", bot.config.Trading.SymbolA)
 			}
 		}
 
+		bot.submitIntent()
 		logger.Printf("执行卖出 %.8f %s...", amountInBase, bot.config.Trading.SymbolA)
-		err = bot.exchange.PlaceOrder(
+		orderID, err := bot.submitOrder(
 			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
 			"sell",
 			amountInBase,
-			map[string]interface{}{},
+			bot.withIntent(map[string]interface{}{}, "spot-sell", marketData),
 		)
 		if err != nil {
 			return fmt.Errorf("卖出失败: %w", err)
 		}
 		logger.Println("✅ 卖出订单执行成功")
+		bot.verifyOrder(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB), orderID)
 
 		// 等待订单成交并更新余额信息
 		time.Sleep(2 * time.Second)
@@ -289,10 +631,10 @@ func (bot *TradingBot) executeFuturesTrade(signal *models.TradeSignal, amountInB
 	if signal.Signal == "BUY" {
 		if bot.currentPosition != nil && bot.currentPosition.Side == "short" {
 			operationType = "平空开多"
-			requiredMargin = bot.config.Trading.Amount / float64(bot.config.Trading.Leverage)
+			requiredMargin = bot.orderAmount() / float64(bot.config.Trading.Leverage)
 		} else if bot.currentPosition == nil {
 			operationType = "开多仓"
-			requiredMargin = bot.config.Trading.Amount / float64(bot.config.Trading.Leverage)
+			requiredMargin = bot.orderAmount() / float64(bot.config.Trading.Leverage)
 		} else {
 			operationType = "保持多仓"
 			requiredMargin = 0
@@ -300,10 +642,10 @@ func (bot *TradingBot) executeFuturesTrade(signal *models.TradeSignal, amountInB
 	} else if signal.Signal == "SELL" {
 		if bot.currentPosition != nil && bot.currentPosition.Side == "long" {
 			operationType = "平多开空"
-			requiredMargin = bot.config.Trading.Amount / float64(bot.config.Trading.Leverage)
+			requiredMargin = bot.orderAmount() / float64(bot.config.Trading.Leverage)
 		} else if bot.currentPosition == nil {
 			operationType = "开空仓"
-			requiredMargin = bot.config.Trading.Amount / float64(bot.config.Trading.Leverage)
+			requiredMargin = bot.orderAmount() / float64(bot.config.Trading.Leverage)
 		} else {
 			operationType = "保持空仓"
 			requiredMargin = 0
@@ -311,95 +653,139 @@ func (bot *TradingBot) executeFuturesTrade(signal *models.TradeSignal, amountInB
 	}
 
 	logger.Printf("操作类型: %s, 交易金额: %.2f %s (约%.8f %s), 需要保证金: %.2f %s",
-		operationType, bot.config.Trading.Amount, bot.config.Trading.SymbolB,
+		operationType, bot.orderAmount(), bot.config.Trading.SymbolB,
 		amountInBase, bot.config.Trading.SymbolA,
 		requiredMargin, bot.config.Trading.SymbolB)
 
+	// 已持有同方向仓位时不下单，只同步持仓给风控
+	if requiredMargin == 0 {
+		bot.checkGate("position", false, operationType)
+		bot.skipIntent("已持有同方向仓位")
+		bot.holdPosition()
+		return nil
+	}
+
+	// 新开仓时检查可用保证金（反手时平仓会释放保证金，余额未知时交由交易所校验）
+	if bot.currentPosition == nil && requiredMargin > 0 && bot.balance >= 0 {
+		detail := fmt.Sprintf("需要%.2f，可用%.2f", requiredMargin, bot.balance)
+		if !bot.checkGate("margin", bot.balance >= requiredMargin, detail) {
+			logger.Printf("⚠️ 可用保证金不足 (%s)，跳过开仓", detail)
+			bot.skipIntent("保证金不足")
+			return nil
+		}
+	}
+	bot.submitIntent()
+
 	// 执行交易逻辑
 	if signal.Signal == "BUY" {
-		return bot.executeBuy(signal, amountInBase)
+		return bot.executeBuy(signal, amountInBase, marketData)
 	} else if signal.Signal == "SELL" {
-		return bot.executeSell(signal, amountInBase)
+		return bot.executeSell(signal, amountInBase, marketData)
 	}
 
 	return nil
 }
 
+// holdPosition 已持有同方向仓位时保持现状，确保风险管理器知道当前持仓
+func (bot *TradingBot) holdPosition() {
+	pos := bot.currentPosition
+	side := "多头"
+	if pos.Side == "short" {
+		side = "空头"
+	}
+	logger.Printf("已有%s持仓，保持现状", side)
+	logger.Printf("[INFO] 当前持仓: %.8f %s @ $%.2f, 未实现盈亏: %.2f USDT",
+		pos.Size, bot.config.Trading.SymbolA, pos.EntryPrice, pos.UnrealizedPnL)
+	logger.Println("[提示] 如需追加仓位，可考虑增加单次交易金额或使用独立的加仓策略")
+
+	if rm := bot.legRisk(pos.Side); rm != nil {
+		rm.UpdatePosition(pos)
+	}
+}
+
 // executeBuy 执行买入
-func (bot *TradingBot) executeBuy(signal *models.TradeSignal, amountInBase float64) error {
+func (bot *TradingBot) executeBuy(signal *models.TradeSignal, amountInBase float64, marketData *models.MarketData) error {
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	var orderID string
+	var openBracket *bracket
+	var err error
+
 	if bot.currentPosition != nil && bot.currentPosition.Side == "short" {
 		// 平空仓
 		logger.Println("平空仓...")
-		err := bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
+		closeOrderID, err := bot.submitOrder(
+			symbol,
 			"buy",
 			bot.currentPosition.Size,
-			map[string]interface{}{
+			bot.withIntent(map[string]interface{}{
 				"reduceOnly": true,
 				"posSide":    "short", // 平空仓需要指定 posSide
-			},
+			}, "close-short", marketData),
 		)
 		if err != nil {
 			return fmt.Errorf("平空仓失败: %w", err)
 		}
+		closeOrder := bot.verifyOrder(symbol, closeOrderID)
+		if rm := bot.legRisk("short"); rm != nil {
+			rm.cancelBracket("信号反转")
+			if bot.hedge {
+				rm.UpdatePosition(nil)
+			}
+		}
+		bot.journalClose("short", marketData, marketData.Price, closeOrder, "信号反转")
+		if bot.closeOnly != "" {
+			logger.Printf("[INFO] 已平空仓，%s，不开多仓", bot.closeOnly)
+			if rm := bot.legRisk("short"); rm != nil {
+				rm.UpdatePosition(nil)
+			}
+			bot.currentPosition = nil
+			return nil
+		}
 		time.Sleep(1 * time.Second)
 
 		// 开多仓
 		logger.Println("开多仓...")
-		err = bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
-			"buy",
-			amountInBase,
-			map[string]interface{}{
-				"posSide": "long", // 开多仓需要指定 posSide
-			},
-		)
+		params, br := bot.openParams("long", marketData)
+		openBracket = br
+		orderID, err = bot.submitEntry(symbol, "buy", amountInBase, params, br)
 		if err != nil {
-			return fmt.Errorf("开多仓失败: %w", err)
+			return bot.wrapOpenError("开多仓", err)
 		}
-	} else if bot.currentPosition != nil && bot.currentPosition.Side == "long" {
-		logger.Println("已有多头持仓，保持现状")
-		logger.Printf("[INFO] 当前持仓: %.8f %s @ $%.2f, 未实现盈亏: %.2f USDT",
-			bot.currentPosition.Size, bot.config.Trading.SymbolA,
-			bot.currentPosition.EntryPrice, bot.currentPosition.UnrealizedPnL)
-		logger.Println("[提示] 如需追加仓位，可考虑增加单次交易金额或使用独立的加仓策略")
-
-		// 【修复】确保风险管理器知道当前持仓
-		if bot.riskManager != nil {
-			bot.riskManager.UpdatePosition(bot.currentPosition)
-		}
-		return nil
 	} else {
 		// 开多仓
 		logger.Println("开多仓...")
-		err := bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
-			"buy",
-			amountInBase,
-			map[string]interface{}{
-				"posSide": "long", // 开多仓需要指定 posSide
-			},
-		)
+		params, br := bot.openParams("long", marketData)
+		openBracket = br
+		orderID, err = bot.submitEntry(symbol, "buy", amountInBase, params, br)
 		if err != nil {
-			return fmt.Errorf("开多仓失败: %w", err)
+			return bot.wrapOpenError("开多仓", err)
 		}
 	}
 
 	logger.Println("订单执行成功")
+	order := bot.verifyEntry(symbol, orderID)
+	if rm := bot.legRisk("long"); rm != nil {
+		rm.setBracket(openBracket, orderID)
+	}
 	time.Sleep(2 * time.Second)
 
 	// 更新持仓
-	pos, err := bot.exchange.FetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	pos, err := bot.fetchLeg(symbol, "long")
 	if err == nil {
 		bot.currentPosition = pos
 		logger.Printf("更新后持仓: %+v", pos)
 
 		// 通知风险管理器更新持仓
-		if bot.riskManager != nil {
-			bot.riskManager.UpdatePosition(pos)
+		if rm := bot.legRisk("long"); rm != nil {
+			rm.UpdatePosition(pos)
 		}
 	}
 
+	// 记录开仓（限价单未按下单数量成交时以交易所持仓为准）
+	if pos, size, opened := bot.entrySize(symbol, "long", amountInBase, pos); opened {
+		bot.journalOpen("long", signal, marketData, order, pos, size)
+	}
+
 	// 获取并显示当前USDT余额
 	usdtBalance, err := bot.exchange.FetchBalance(bot.config.Trading.SymbolB)
 	if err == nil {
@@ -412,80 +798,88 @@ func (bot *TradingBot) executeBuy(signal *models.TradeSignal, amountInBase float
 }
 
 // executeSell 执行卖出
-func (bot *TradingBot) executeSell(signal *models.TradeSignal, amountInBase float64) error {
+func (bot *TradingBot) executeSell(signal *models.TradeSignal, amountInBase float64, marketData *models.MarketData) error {
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	var orderID string
+	var openBracket *bracket
+	var err error
+
 	if bot.currentPosition != nil && bot.currentPosition.Side == "long" {
 		// 平多仓
 		logger.Println("平多仓...")
-		err := bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
+		closeOrderID, err := bot.submitOrder(
+			symbol,
 			"sell",
 			bot.currentPosition.Size,
-			map[string]interface{}{
+			bot.withIntent(map[string]interface{}{
 				"reduceOnly": true,
 				"posSide":    "long", // 平多仓需要指定 posSide
-			},
+			}, "close-long", marketData),
 		)
 		if err != nil {
 			return fmt.Errorf("平多仓失败: %w", err)
 		}
+		closeOrder := bot.verifyOrder(symbol, closeOrderID)
+		if rm := bot.legRisk("long"); rm != nil {
+			rm.cancelBracket("信号反转")
+			if bot.hedge {
+				rm.UpdatePosition(nil)
+			}
+		}
+		bot.journalClose("long", marketData, marketData.Price, closeOrder, "信号反转")
+		if bot.closeOnly != "" {
+			logger.Printf("[INFO] 已平多仓，%s，不开空仓", bot.closeOnly)
+			if rm := bot.legRisk("long"); rm != nil {
+				rm.UpdatePosition(nil)
+			}
+			bot.currentPosition = nil
+			return nil
+		}
 		time.Sleep(1 * time.Second)
 
 		// 开空仓
 		logger.Println("开空仓...")
-		err = bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
-			"sell",
-			amountInBase,
-			map[string]interface{}{
-				"posSide": "short", // 开空仓需要指定 posSide
-			},
-		)
+		params, br := bot.openParams("short", marketData)
+		openBracket = br
+		orderID, err = bot.submitEntry(symbol, "sell", amountInBase, params, br)
 		if err != nil {
-			return fmt.Errorf("开空仓失败: %w", err)
+			return bot.wrapOpenError("开空仓", err)
 		}
-	} else if bot.currentPosition != nil && bot.currentPosition.Side == "short" {
-		logger.Println("已有空头持仓，保持现状")
-		logger.Printf("[INFO] 当前持仓: %.8f %s @ $%.2f, 未实现盈亏: %.2f USDT",
-			bot.currentPosition.Size, bot.config.Trading.SymbolA,
-			bot.currentPosition.EntryPrice, bot.currentPosition.UnrealizedPnL)
-		logger.Println("[提示] 如需追加仓位，可考虑增加单次交易金额或使用独立的加仓策略")
-
-		// 【修复】确保风险管理器知道当前持仓
-		if bot.riskManager != nil {
-			bot.riskManager.UpdatePosition(bot.currentPosition)
-		}
-		return nil
 	} else {
 		// 开空仓
 		logger.Println("开空仓...")
-		err := bot.exchange.PlaceOrder(
-			bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB),
-			"sell",
-			amountInBase,
-			map[string]interface{}{
-				"posSide": "short", // 开空仓需要指定 posSide
-			},
-		)
+		params, br := bot.openParams("short", marketData)
+		openBracket = br
+		orderID, err = bot.submitEntry(symbol, "sell", amountInBase, params, br)
 		if err != nil {
-			return fmt.Errorf("开空仓失败: %w", err)
+			return bot.wrapOpenError("开空仓", err)
 		}
 	}
 
 	logger.Println("订单执行成功")
+	order := bot.verifyEntry(symbol, orderID)
+	if rm := bot.legRisk("short"); rm != nil {
+		rm.setBracket(openBracket, orderID)
+	}
 	time.Sleep(2 * time.Second)
 
 	// 更新持仓
-	pos, err := bot.exchange.FetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	pos, err := bot.fetchLeg(symbol, "short")
 	if err == nil {
 		bot.currentPosition = pos
 		logger.Printf("更新后持仓: %+v", pos)
 
 		// 通知风险管理器更新持仓
-		if bot.riskManager != nil {
-			bot.riskManager.UpdatePosition(pos)
+		if rm := bot.legRisk("short"); rm != nil {
+			rm.UpdatePosition(pos)
 		}
 	}
 
+	// 记录开仓（限价单未按下单数量成交时以交易所持仓为准）
+	if pos, size, opened := bot.entrySize(symbol, "short", amountInBase, pos); opened {
+		bot.journalOpen("short", signal, marketData, order, pos, size)
+	}
+
 	// 获取并显示当前USDT余额
 	usdtBalance, err := bot.exchange.FetchBalance(bot.config.Trading.SymbolB)
 	if err == nil {
@@ -497,10 +891,186 @@ func (bot *TradingBot) executeSell(signal *models.TradeSignal, amountInBase floa
 	return nil
 }
 
+// openParams 构建开仓参数（启用括号单或交易所端移动止损时附带交易所端委托）
+func (bot *TradingBot) openParams(posSide string, marketData *models.MarketData) (map[string]interface{}, *bracket) {
+	params := bot.withIntent(map[string]interface{}{
+		"posSide": posSide, // 合约开仓需要指定 posSide
+	}, "open-"+posSide, marketData)
+	rm := bot.legRisk(posSide)
+	if rm == nil {
+		return params, nil
+	}
+	b := rm.newBracket(posSide, marketData.Price)
+	if b != nil {
+		b.apply(params)
+	}
+	return params, b
+}
+
+// intentOrderID 按下单意图（交易对、操作、信号K线时间、下单前持仓、风控平仓计数、开仓尝试代数）生成确定性的自定义订单ID
+// 同一意图重复执行时（如下单超时后重启）交易所会识别为同一订单，避免重复开仓；
+// 持仓在外部平掉或放弃开仓后开仓尝试代数+1，同一根K线内重新开仓使用新的订单ID
+// 缺少K线数据时返回空字符串（由交易所客户端自动生成）
+func (bot *TradingBot) intentOrderID(action string, marketData *models.MarketData) string {
+	n := len(marketData.KlineData)
+	if n == 0 {
+		return ""
+	}
+	position := "none"
+	if bot.currentPosition != nil {
+		position = fmt.Sprintf("%s@%g", bot.currentPosition.Side, bot.currentPosition.EntryPrice)
+	}
+	intent := fmt.Sprintf("%s|%s|%d|%s|%d|%d", bot.tradingPair, action,
+		marketData.KlineData[n-1].Timestamp.Unix(), position, bot.riskGeneration, bot.entryAttempt)
+	return exchange.IntentClientOrderID(intent)
+}
+
+// heldSides 上次获取或开仓后已知持有的方向
+func (bot *TradingBot) heldSides() map[string]bool {
+	held := make(map[string]bool, len(positionSides))
+	for side, pos := range bot.positions {
+		if pos != nil {
+			held[side] = true
+		}
+	}
+	if bot.currentPosition != nil {
+		held[bot.currentPosition.Side] = true
+	}
+	return held
+}
+
+// notePositionLoss 上次持有的方向在本轮获取的持仓中已不存在时（交易所括号单、手动平仓等）递增开仓尝试代数
+func (bot *TradingBot) notePositionLoss(held map[string]bool) {
+	now := bot.heldSides()
+	for side := range held {
+		if !now[side] {
+			bot.abandonEntry(side + "持仓已不存在")
+			return
+		}
+	}
+}
+
+// abandonEntry 递增开仓尝试代数，之后的开仓使用新的意图订单ID
+func (bot *TradingBot) abandonEntry(reason string) {
+	bot.entryAttempt++
+	logger.Debugf("[DEBUG] %s，开仓尝试代数更新为 %d", reason, bot.entryAttempt)
+}
+
+// withIntent 为下单参数附带意图订单ID
+func (bot *TradingBot) withIntent(params map[string]interface{}, action string, marketData *models.MarketData) map[string]interface{} {
+	id := bot.intentOrderID(action, marketData)
+	if id != "" {
+		params[exchange.ParamClientOrderID] = id
+	}
+	bot.intentOrder(action, id)
+	return params
+}
+
+// wrapOpenError 根据错误分类包装开仓错误
+func (bot *TradingBot) wrapOpenError(operation string, err error) error {
+	switch exchange.ErrorKindOf(err) {
+	case exchange.ErrorKindInsufficientBalance:
+		return fmt.Errorf("%s失败: 保证金不足，请检查账户余额或降低交易金额(%.2f %s): %w",
+			operation, bot.orderAmount(), bot.config.Trading.SymbolB, err)
+	case exchange.ErrorKindInvalidOrder:
+		return fmt.Errorf("%s失败: 订单参数无效，请检查交易金额和交易对配置: %w", operation, err)
+	default:
+		return fmt.Errorf("%s失败: %w", operation, err)
+	}
+}
+
+// logSlippageEstimate 按最新盘口估算市价单滑点（仅记录日志）
+func (bot *TradingBot) logSlippageEstimate(signal string, amountInBase float64) {
+	side := "buy"
+	if signal == "SELL" {
+		side = "sell"
+	}
+
+	book, err := bot.exchange.FetchOrderBook(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB), orderBookDepth)
+	if err != nil {
+		logger.Debugf("[DEBUG] 获取盘口深度失败，跳过滑点估算: %v", err)
+		return
+	}
+
+	mid := book.MidPrice()
+	avgPrice, filled := book.EstimateFill(side, amountInBase)
+	if mid == 0 || filled == 0 {
+		logger.Printf("[WARNING] 盘口为空，无法估算滑点")
+		return
+	}
+
+	slippage := (avgPrice - mid) / mid * 10000
+	if side == "sell" {
+		slippage = -slippage
+	}
+	logger.Printf("[INFO] 盘口估算 - 价差: %.2f bps, 预计成交均价: %.2f, 滑点: %.2f bps", book.SpreadBps(), avgPrice, slippage)
+	if filled < amountInBase {
+		logger.Printf("[WARNING] 盘口流动性不足 - 前%d档仅可成交 %.8f / %.8f %s",
+			orderBookDepth, filled, amountInBase, bot.config.Trading.SymbolA)
+	}
+}
+
+// submitOrder 提交订单（启用链路追踪时记录下单耗时）
+func (bot *TradingBot) submitOrder(symbol, side string, amount float64, params map[string]interface{}) (string, error) {
+	span := tracing.StartSpan("order_submission", bot.span)
+	defer span.End()
+	span.SetAttribute("side", side)
+	span.SetAttribute("amount", amount)
+	if clientOrderID, ok := params[exchange.ParamClientOrderID].(string); ok {
+		span.SetAttribute("client_order_id", clientOrderID)
+	}
+
+	orderID, err := bot.exchange.PlaceOrder(symbol, side, amount, params)
+	span.RecordError(err)
+	span.SetAttribute("order_id", orderID)
+	if !errors.Is(err, exchange.ErrTradingDisabled) {
+		bot.recordEvent(orderEvent(side, amount, orderID, err))
+	}
+	return orderID, err
+}
+
+// verifyOrder 查询订单成交情况（仅记录日志，不影响交易流程），查询失败且成交记录中也没有该订单时返回nil
+func (bot *TradingBot) verifyOrder(symbol, orderID string) *models.Order {
+	if orderID == "" {
+		return nil
+	}
+	bot.linkIntentOrder(orderID)
+
+	span := tracing.StartSpan("fill_confirmation", bot.span)
+	defer span.End()
+	span.SetAttribute("order_id", orderID)
+
+	order, err := bot.exchange.FetchOrder(symbol, orderID)
+	if err != nil {
+		span.RecordError(err)
+		logger.Printf("[WARNING] 查询订单 %s 失败: %v", orderID, err)
+		order = nil
+	}
+	// 查询失败或无成交均价时按成交记录补全
+	if order = fillFromTrades(bot.exchange, symbol, orderID, order); order == nil {
+		return nil
+	}
+	span.SetAttribute("state", order.State)
+	span.SetAttribute("filled_size", order.FilledSize)
+	span.SetAttribute("avg_price", order.AvgPrice)
+	bot.recordEvent(fillEvent(order))
+
+	logger.Printf("[INFO] 订单 %s 状态: %s, 成交: %.8f/%.8f, 均价: %.2f",
+		order.OrderID, order.State, order.FilledSize, order.Size, order.AvgPrice)
+	return order
+}
+
 // SetupExchange 设置交易所参数
 func (bot *TradingBot) SetupExchange() error {
+	bot.loadFeeRate()
+	bot.loadTickSize()
+
 	// 设置杠杆
 	err := bot.exchange.SetLeverage(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB), bot.config.Trading.Leverage)
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("测试模式 - 跳过设置杠杆 (%dx)", bot.config.Trading.Leverage)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("设置杠杆失败: %w", err)
 	}
@@ -509,17 +1079,140 @@ func (bot *TradingBot) SetupExchange() error {
 	return nil
 }
 
-// StartRiskManager 启动风险管理器
-func (bot *TradingBot) StartRiskManager() error {
-	if bot.riskManager != nil {
-		return bot.riskManager.Start()
+// loadTickSize 获取交易对的最小变动价位，供风控按交易所价格精度取整止盈止损价
+func (bot *TradingBot) loadTickSize() {
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	info, err := bot.exchange.GetInstrumentInfo(symbol)
+	if err != nil {
+		logger.Printf("[WARNING] 获取价格精度失败: %v", err)
+		return
+	}
+	for _, rm := range bot.riskManagers() {
+		rm.mu.Lock()
+		rm.tickSize = info.TickSize
+		rm.mu.Unlock()
+	}
+	logger.Debugf("[DEBUG] 价格精度: %g", info.TickSize)
+}
+
+// SetNotifier 设置通知发布器（风控平仓等事件会发送通知）
+func (bot *TradingBot) SetNotifier(notifier notify.Publisher) {
+	bot.notifier = notifier
+	for _, rm := range bot.riskManagers() {
+		rm.notifier = notifier
 	}
-	return nil
 }
 
-// StopRiskManager 停止风险管理器
-func (bot *TradingBot) StopRiskManager() {
-	if bot.riskManager != nil {
-		bot.riskManager.Stop()
+// SetCalendar 设置交易日历（每日统计以此为日界线）
+func (bot *TradingBot) SetCalendar(cal *calendar.Calendar) {
+	bot.calendar = cal
+	for _, rm := range bot.riskManagers() {
+		rm.calendar = cal
+	}
+}
+
+// SetJournal 设置交易日志（记录开平仓，用于期望值过滤等统计）
+func (bot *TradingBot) SetJournal(j *journal.Journal) {
+	bot.journal = j
+	for _, rm := range bot.riskManagers() {
+		rm.journal = j
+		rm.restoreBracket(j.OpenLeg(bot.tradingPair, rm.leg))
+	}
+}
+
+// fetchDerivativesStats 获取资金费率、持仓量和基差（失败不影响分析），并计算持仓量相对上一轮的变化
+func (bot *TradingBot) fetchDerivativesStats(symbol string) *models.DerivativesStats {
+	stats, err := bot.exchange.FetchDerivativesStats(symbol)
+	if err != nil {
+		logger.Debugf("[DEBUG] 获取合约市场数据失败: %v", err)
+		return nil
+	}
+	if stats == nil {
+		return nil
+	}
+	if bot.lastOI > 0 && stats.OpenInterest > 0 {
+		stats.OpenInterestChange = (stats.OpenInterest - bot.lastOI) / bot.lastOI * 100
 	}
+	if stats.OpenInterest > 0 {
+		bot.lastOI = stats.OpenInterest
+	}
+	return stats
+}
+
+// SetNewsFeed 设置新闻标题源（近期新闻加入AI分析提示词）
+func (bot *TradingBot) SetNewsFeed(feed *datafeeds.NewsFeed) {
+	bot.news = feed
+}
+
+// SetFearGreedFeed 设置恐惧贪婪指数数据源（当前指数加入AI分析提示词）
+func (bot *TradingBot) SetFearGreedFeed(feed *datafeeds.FearGreedFeed) {
+	bot.fearGreed = feed
+}
+
+// SetSessions 设置交易时段（允许的时段外和禁止开仓时间段内不开仓）
+func (bot *TradingBot) SetSessions(sessions *calendar.Sessions) {
+	bot.sessions = sessions
+}
+
+// SetEmbargo 设置禁止交易名单（名单内的交易对不开仓）
+func (bot *TradingBot) SetEmbargo(list *embargo.List) {
+	bot.embargo = list
+	if blocked, reason := list.Check(bot.tradingPair); blocked {
+		logger.Warnf("[禁止交易] ⚠️ 配置的交易对 %s，不会执行任何交易信号", reason)
+	}
+}
+
+// SetIntentStore 设置下单意图记录的持久化存储
+func (bot *TradingBot) SetIntentStore(s store.Store) {
+	bot.intentStore = s
+}
+
+// SetLease 设置交易对租约（未持有租约时跳过分析下单和风控检查）
+func (bot *TradingBot) SetLease(lease *PairLease) {
+	bot.lease = lease
+	for _, rm := range bot.riskManagers() {
+		rm.lease = lease
+	}
+}
+
+// SetFailover 设置主备切换（备用实例跳过分析下单，仅在主实例失联期间同步持仓并执行风控）
+func (bot *TradingBot) SetFailover(f *Failover) {
+	bot.failover = f
+	for _, rm := range bot.riskManagers() {
+		rm.failover = f
+	}
+	if f.IsStandby() {
+		f.OnActive(bot.SyncPosition)
+	}
+}
+
+// SyncPosition 从交易所同步持仓给风控（备用实例接管期间调用）
+func (bot *TradingBot) SyncPosition() {
+	if bot.riskManager == nil {
+		return
+	}
+	pos, err := bot.fetchPosition(bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB))
+	if err != nil {
+		logger.Printf("[主备] %s 同步持仓失败: %v", bot.tradingPair, err)
+		return
+	}
+	bot.currentPosition = pos
+	if bot.hedge {
+		bot.syncLegs()
+		return
+	}
+	bot.riskManager.UpdatePosition(pos)
+	if pos == nil {
+		bot.riskManager.cancelBracket("持仓已不存在")
+	}
+}
+
+// GetRiskManager 获取风险管理器（未启用时返回nil，双向持仓时为多仓的风险管理器）
+func (bot *TradingBot) GetRiskManager() *RiskManager {
+	return bot.riskManager
+}
+
+// GetRiskManagers 获取全部风险管理器（双向持仓时多空各一个，未启用时为空）
+func (bot *TradingBot) GetRiskManagers() []*RiskManager {
+	return bot.riskManagers()
 }
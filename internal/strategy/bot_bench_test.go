@@ -0,0 +1,120 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dsbot/internal/ai"
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/models"
+)
+
+// benchExchange 基准测试用交易所，仅实现一轮决策流程用到的接口（其余方法未实现）
+type benchExchange struct {
+	exchange.Exchange
+	ohlcv []models.OHLCV
+}
+
+func newBenchExchange(points int) *benchExchange {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ohlcvList := make([]models.OHLCV, points)
+	price := 50000.0
+	for i := range ohlcvList {
+		open := price
+		price = open * (1 + 0.002*math.Sin(float64(i)/7))
+		ohlcvList[i] = models.OHLCV{
+			Timestamp: start.Add(time.Duration(i) * 15 * time.Minute),
+			Open:      open,
+			High:      math.Max(open, price) * 1.001,
+			Low:       math.Min(open, price) * 0.999,
+			Close:     price,
+			Volume:    100,
+		}
+	}
+	return &benchExchange{ohlcv: ohlcvList}
+}
+
+func (e *benchExchange) FetchOHLCV(symbol, timeframe string, limit int) ([]models.OHLCV, error) {
+	return e.ohlcv, nil
+}
+
+func (e *benchExchange) FetchOrderBook(symbol string, depth int) (*models.OrderBook, error) {
+	price := e.ohlcv[len(e.ohlcv)-1].Close
+	return &models.OrderBook{
+		Symbol: symbol,
+		Bids:   []models.OrderBookLevel{{Price: price - 0.5, Size: 10}},
+		Asks:   []models.OrderBookLevel{{Price: price + 0.5, Size: 10}},
+	}, nil
+}
+
+func (e *benchExchange) FetchPosition(symbol string) (*models.Position, error) {
+	return nil, nil
+}
+
+func (e *benchExchange) FetchPositions(symbol string) ([]models.Position, error) {
+	return nil, nil
+}
+
+func (e *benchExchange) FetchBalance(currency string) (float64, error) {
+	return 10000, nil
+}
+
+func (e *benchExchange) ParseSymbols(symbolA, symbolB string) string {
+	return fmt.Sprintf("%s/%s:%s", symbolA, symbolB, symbolB)
+}
+
+func (e *benchExchange) GetExchangeName() string {
+	return "bench"
+}
+
+// newBenchAIServer 模拟 DeepSeek 接口，固定返回 HOLD 信号
+func newBenchAIServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := `{"signal": "HOLD", "reason": "基准测试", "confidence": "MEDIUM"}`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": content}},
+			},
+		})
+	}))
+}
+
+// BenchmarkDecisionPipeline 一轮完整决策流程（获取数据、计算指标、构建提示词、调用本地模拟AI、解析信号）
+// 包含本地HTTP往返开销，不包含真实网络和AI响应延迟
+func BenchmarkDecisionPipeline(b *testing.B) {
+	server := newBenchAIServer()
+	defer server.Close()
+
+	for _, n := range []int{100, 1000, 5000} {
+		cfg := &config.Config{
+			Trading: config.TradingConfig{
+				SymbolA:     "BTC",
+				SymbolB:     "USDT",
+				Amount:      100,
+				Leverage:    10,
+				Timeframe:   "15m",
+				DataPoints:  n,
+				TestMode:    true,
+				TradingMode: string(config.TradingModeFutures),
+			},
+			API: config.APIConfig{DeepSeekAPIKey: "bench", DeepSeekBaseURL: server.URL},
+		}
+		bot := NewTradingBot(cfg, newBenchExchange(n), ai.NewDeepSeekClient(&cfg.API))
+
+		b.Run(fmt.Sprintf("points=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bot.Run(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package strategy
+
+import (
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+)
+
+// 移动止损执行方式
+const (
+	TrailingStopModeLocal    = "local"    // 本地风控跟踪最高/最低价，触发后市价平仓（默认）
+	TrailingStopModeExchange = "exchange" // 开仓后提交交易所端移动止损委托，机器人停机时仍有效
+)
+
+// bracket 括号单 - 开仓单附带交易所端止损、止盈、移动止损策略委托
+// 任一腿触发或持仓以其他方式平掉后，撤销剩余的委托
+type bracket struct {
+	symbol       string
+	entryOrderID string
+	stopLoss     float64 // 止损触发价（0表示未设置）
+	stopLossID   string  // 止损委托自定义ID
+	takeProfit   float64 // 止盈触发价（0表示未设置）
+	takeProfitID string  // 止盈委托自定义ID
+	trailing     float64 // 移动止损回调幅度（%，0表示未设置）
+	trailingID   string  // 移动止损委托自定义ID
+}
+
+// apply 将止损止盈写入下单参数
+func (b *bracket) apply(params map[string]interface{}) {
+	if b.stopLoss > 0 {
+		params[exchange.ParamStopLossPrice] = b.stopLoss
+		params[exchange.ParamStopLossClientID] = b.stopLossID
+	}
+	if b.takeProfit > 0 {
+		params[exchange.ParamTakeProfitPrice] = b.takeProfit
+		params[exchange.ParamTakeProfitClientID] = b.takeProfitID
+	}
+	if b.trailing > 0 {
+		params[exchange.ParamTrailingCallbackRatio] = b.trailing
+		params[exchange.ParamTrailingClientID] = b.trailingID
+	}
+}
+
+// renew 重新生成各委托的自定义ID（附带的委托已随未成交的开仓单撤销、需随新开仓单重新提交时调用）
+func (b *bracket) renew() {
+	if b.stopLossID != "" {
+		b.stopLossID = exchange.NewClientOrderID()
+	}
+	if b.takeProfitID != "" {
+		b.takeProfitID = exchange.NewClientOrderID() + "t"
+	}
+	if b.trailingID != "" {
+		b.trailingID = exchange.NewClientOrderID() + "m"
+	}
+}
+
+// exchangeBracket 是否提交交易所端止损止盈（交易所不支持时由本地风控执行）
+func (rm *RiskManager) exchangeBracket() bool {
+	return rm.config.Trading.RiskManagement.ExchangeBracket && rm.exchange.Capabilities().AlgoOrders
+}
+
+// exchangeTrailingStop 是否由交易所端移动止损委托执行移动止损（本地不再跟踪；交易所不支持时由本地风控执行）
+func (rm *RiskManager) exchangeTrailingStop() bool {
+	cfg := rm.config.Trading.RiskManagement
+	return cfg.EnableTrailingStop && cfg.TrailingStopMode == TrailingStopModeExchange && !rm.config.IsSpotMode() &&
+		rm.exchange.Capabilities().TrailingStop
+}
+
+// localTrailingStop 是否由本地风控跟踪移动止损
+func (rm *RiskManager) localTrailingStop() bool {
+	return rm.config.Trading.RiskManagement.EnableTrailingStop && !rm.exchangeTrailingStop()
+}
+
+// newBracket 按风控参数生成括号单（未启用括号单和交易所端移动止损，或均未配置时返回nil）
+// side: 开仓方向 ("long" or "short"), price: 参考开仓价
+func (rm *RiskManager) newBracket(side string, price float64) *bracket {
+	cfg := rm.config.Trading.RiskManagement
+	if rm.config.IsSpotMode() || price <= 0 {
+		return nil
+	}
+
+	b := &bracket{symbol: rm.exchange.ParseSymbols(rm.config.Trading.SymbolA, rm.config.Trading.SymbolB)}
+	if rm.exchangeBracket() {
+		direction := 1.0
+		if side == "short" {
+			direction = -1.0
+		}
+		rm.mu.Lock()
+		stopLossPercent, takeProfitPercent := rm.stopLossTakeProfitPercentLocked()
+		tickSize := rm.tickSize
+		rm.mu.Unlock()
+		if cfg.EnableStopLoss && stopLossPercent > 0 {
+			b.stopLoss = exchange.RoundPrice(price*(1-direction*stopLossPercent/100), tickSize)
+			b.stopLossID = exchange.NewClientOrderID()
+		}
+		if cfg.EnableTakeProfit && takeProfitPercent > 0 {
+			b.takeProfit = exchange.RoundPrice(price*(1+direction*takeProfitPercent/100), tickSize)
+			b.takeProfitID = exchange.NewClientOrderID() + "t"
+		}
+	}
+	if rm.exchangeTrailingStop() && cfg.TrailingStopDistance > 0 {
+		b.trailing = cfg.TrailingStopDistance
+		b.trailingID = exchange.NewClientOrderID() + "m"
+	}
+	if b.stopLoss == 0 && b.takeProfit == 0 && b.trailing == 0 {
+		return nil
+	}
+	return b
+}
+
+// setBracket 记录已提交的括号单
+func (rm *RiskManager) setBracket(b *bracket, entryOrderID string) {
+	if b == nil {
+		return
+	}
+	b.entryOrderID = entryOrderID
+
+	rm.mu.Lock()
+	rm.bracket = b
+	rm.mu.Unlock()
+
+	logger.Printf("[风险管理] 括号单已提交 - 开仓单:%s, 止损:%.2f(%s), 止盈:%.2f(%s), 移动止损:%.2f%%(%s)",
+		b.entryOrderID, b.stopLoss, b.stopLossID, b.takeProfit, b.takeProfitID, b.trailing, b.trailingID)
+}
+
+// restoreBracket 从交易日志的未平仓记录恢复括号单（重启后仍能清理剩余委托）
+func (rm *RiskManager) restoreBracket(entry *journal.Entry) {
+	if entry == nil || (entry.StopLossOrderID == "" && entry.TakeProfitOrderID == "" && entry.TrailingOrderID == "") {
+		return
+	}
+
+	rm.mu.Lock()
+	rm.bracket = &bracket{
+		symbol:       rm.exchange.ParseSymbols(rm.config.Trading.SymbolA, rm.config.Trading.SymbolB),
+		entryOrderID: entry.EntryOrderID,
+		stopLossID:   entry.StopLossOrderID,
+		takeProfitID: entry.TakeProfitOrderID,
+		trailingID:   entry.TrailingOrderID,
+	}
+	rm.mu.Unlock()
+
+	logger.Printf("[风险管理] 已从交易日志恢复括号单 - 开仓单:%s, 止损委托:%s, 止盈委托:%s, 移动止损委托:%s",
+		entry.EntryOrderID, entry.StopLossOrderID, entry.TakeProfitOrderID, entry.TrailingOrderID)
+}
+
+// currentBracket 获取当前括号单
+func (rm *RiskManager) currentBracket() *bracket {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	return rm.bracket
+}
+
+// cancelBracket 撤销括号单剩余的交易所端委托（持仓已平或即将平仓时调用）
+func (rm *RiskManager) cancelBracket(reason string) {
+	rm.mu.Lock()
+	b := rm.bracket
+	rm.bracket = nil
+	rm.mu.Unlock()
+
+	if b == nil {
+		return
+	}
+
+	logger.Printf("[风险管理] 撤销括号单剩余委托 (开仓单:%s, 原因:%s)", b.entryOrderID, reason)
+	for _, id := range []string{b.stopLossID, b.takeProfitID, b.trailingID} {
+		if id == "" {
+			continue
+		}
+		if err := rm.exchange.CancelAlgoOrder(b.symbol, id); err != nil {
+			logger.Warnf("[风险管理] 撤销委托 %s 失败，请人工检查: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package strategy
+
+import (
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+)
+
+// 波动状态
+const (
+	VolatilityRegimeHigh   = "high"   // 高波动
+	VolatilityRegimeNormal = "normal" // 正常
+	VolatilityRegimeLow    = "low"    // 低波动
+)
+
+// ClassifyVolatilityRegime 按ATR%划分波动状态
+func ClassifyVolatilityRegime(atrPercent float64, cfg config.AdaptiveCadenceConfig) string {
+	high := cfg.HighVolatilityATR
+	if high <= 0 {
+		high = 1.5
+	}
+	low := cfg.LowVolatilityATR
+	if low <= 0 {
+		low = 0.3
+	}
+
+	switch {
+	case atrPercent >= high:
+		return VolatilityRegimeHigh
+	case atrPercent > 0 && atrPercent <= low:
+		return VolatilityRegimeLow
+	default:
+		return VolatilityRegimeNormal
+	}
+}
+
+// CadenceBounds 自适应执行间隔的上下限
+func CadenceBounds(cfg config.AdaptiveCadenceConfig) (minInterval, maxInterval time.Duration) {
+	minMinutes := cfg.MinIntervalMinutes
+	if minMinutes <= 0 {
+		minMinutes = 5
+	}
+	maxMinutes := cfg.MaxIntervalMinutes
+	if maxMinutes <= 0 {
+		maxMinutes = 60
+	}
+	if maxMinutes < minMinutes {
+		maxMinutes = minMinutes
+	}
+	return time.Duration(minMinutes) * time.Minute, time.Duration(maxMinutes) * time.Minute
+}
+
+// cadenceInterval 波动状态对应的执行间隔（限制在上下限内）
+func cadenceInterval(regime string, baseMinutes int, cfg config.AdaptiveCadenceConfig) time.Duration {
+	minutes := baseMinutes
+	switch regime {
+	case VolatilityRegimeHigh:
+		minutes = cfg.HighVolatilityInterval
+		if minutes <= 0 {
+			minutes = 5
+		}
+	case VolatilityRegimeLow:
+		minutes = cfg.LowVolatilityInterval
+		if minutes <= 0 {
+			minutes = 30
+		}
+	}
+
+	interval := time.Duration(minutes) * time.Minute
+	minInterval, maxInterval := CadenceBounds(cfg)
+	if interval < minInterval {
+		interval = minInterval
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+// updateCadence 按最新波动状态调整执行频率（仅在间隔变化时通知调度器并记录日志）
+func (bot *TradingBot) updateCadence(atrPercent float64) {
+	cfg := bot.config.Trading.AdaptiveCadence
+	if !cfg.Enable || bot.onCadenceChange == nil || atrPercent <= 0 {
+		return
+	}
+
+	regime := ClassifyVolatilityRegime(atrPercent, cfg)
+	interval := cadenceInterval(regime, bot.config.Trading.ScheduleIntervalMinutes, cfg)
+	if interval == bot.cadence {
+		return
+	}
+
+	previous := bot.cadence
+	if previous == 0 {
+		previous = time.Duration(bot.config.Trading.ScheduleIntervalMinutes) * time.Minute
+	}
+	bot.cadence = interval
+	if interval == previous {
+		return
+	}
+
+	logger.Printf("[执行频率] 波动状态 %s (ATR: %.2f%%)，执行间隔 %v -> %v", regime, atrPercent, previous, interval)
+	bot.onCadenceChange(interval)
+}
+
+// SetCadenceHandler 设置执行频率变化回调（启用自适应执行频率时由调度器注册）
+func (bot *TradingBot) SetCadenceHandler(handler func(interval time.Duration)) {
+	bot.onCadenceChange = handler
+}
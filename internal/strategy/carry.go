@@ -0,0 +1,556 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
+)
+
+// 现货多头 + 合约空头组合的开平仓依据
+const (
+	CarryFunding = "funding" // 资金费率套利：年化资金费率达到阈值时开仓，回落后平仓
+	CarryBasis   = "basis"   // 基差交易：合约相对现货溢价达到阈值时开仓，收敛后平仓
+)
+
+// 默认参数
+const (
+	defaultFundingArbEntryPercent = 30.0             // 资金费率套利开仓的年化资金费率（%）
+	defaultFundingArbInterval     = 15 * time.Minute // 资金费率套利检查间隔
+	defaultFundingIntervalHours   = 8.0              // 交易所未返回结算周期时按8小时折算年化
+	defaultBasisEntryPercent      = 0.5              // 基差交易开仓的合约溢价（%）
+	defaultBasisTradeInterval     = time.Minute      // 基差交易检查间隔
+)
+
+// carryPosition 现货多头 + 永续合约空头的组合持仓
+// 两边分别平仓，某一边平仓失败时保留剩余一边，下次检查继续平仓
+type carryPosition struct {
+	SpotSize           float64   `json:"spot_size"`            // 现货持有数量（基础币种，0表示已卖出）
+	PerpSize           float64   `json:"perp_size"`            // 合约空仓数量（基础币种，0表示已平仓）
+	Size               float64   `json:"size"`                 // 开仓数量（基础币种）
+	SpotEntryPrice     float64   `json:"spot_entry_price"`     // 现货买入均价
+	PerpEntryPrice     float64   `json:"perp_entry_price"`     // 合约开空均价
+	SpotExitPrice      float64   `json:"spot_exit_price"`      // 现货卖出均价（未卖出时为0）
+	PerpExitPrice      float64   `json:"perp_exit_price"`      // 合约平空均价（未平仓时为0）
+	Fees               float64   `json:"fees"`                 // 已支付的开平仓手续费合计（计价币种）
+	EntryAnnualPercent float64   `json:"entry_annual_percent"` // 开仓时的年化资金费率（%）
+	EntryBasisPercent  float64   `json:"entry_basis_percent"`  // 开仓时的合约溢价（%）
+	OpenedAt           time.Time `json:"opened_at"`
+}
+
+// carryQuote 一次检查获取的资金费率和现货/合约价格
+type carryQuote struct {
+	annual    float64 // 年化资金费率（%）
+	spotPrice float64
+	perpPrice float64
+	basis     float64 // 合约相对现货的溢价（%）
+}
+
+// CarryTrade 现货多头 + 永续合约空头的 Delta 中性组合：
+// 资金费率套利在资金费率大幅为正（多头支付空头）时开仓收取资金费，基差交易在合约溢价过高时开仓赚取溢价收敛；
+// 开平仓依据回落到平仓阈值以下时平掉两边
+type CarryTrade struct {
+	config      *config.Config
+	mode        string            // 开平仓依据（CarryFunding / CarryBasis）
+	label       string            // 日志和通知中的名称
+	spot        exchange.Exchange // 现货下单（经过下单通道）
+	perp        exchange.Exchange // 永续合约下单（经过下单通道）
+	tradingPair string
+	symbolA     string
+	symbolB     string
+	spotSymbol  string
+	perpSymbol  string
+	notifier    notify.Publisher // 通知发布器（可选）
+	events      *eventRecorder   // 交易事件记录器（可选）
+	stateStore  store.Store      // 组合持仓的持久化存储（可选）
+	failover    *Failover        // 主备切换（可选，备用实例不下单）
+	position    *carryPosition
+}
+
+// NewFundingArbitrage 创建交易对的资金费率套利（spot、perp 分别为现货和合约模式的交易所客户端）
+func NewFundingArbitrage(cfg *config.Config, spot, perp exchange.Exchange, tradingPair string) *CarryTrade {
+	return newCarryTrade(cfg, CarryFunding, "资金费率套利", spot, perp, tradingPair)
+}
+
+// NewBasisTrade 创建交易对的基差交易（spot、perp 分别为现货和合约模式的交易所客户端）
+func NewBasisTrade(cfg *config.Config, spot, perp exchange.Exchange, tradingPair string) *CarryTrade {
+	return newCarryTrade(cfg, CarryBasis, "基差交易", spot, perp, tradingPair)
+}
+
+func newCarryTrade(cfg *config.Config, mode, label string, spot, perp exchange.Exchange, tradingPair string) *CarryTrade {
+	parts := strings.SplitN(tradingPair, "-", 2)
+	symbolA, symbolB := parts[0], parts[len(parts)-1]
+
+	// 所有下单操作经过下单通道：测试模式下仅允许模拟撮合交易所下单
+	gateway := func(exch exchange.Exchange) exchange.Exchange {
+		_, simulated := exch.(*exchange.MockExchange)
+		return exchange.NewExecutionGateway(exch, !cfg.Trading.TestMode || simulated)
+	}
+	return &CarryTrade{
+		config:      cfg,
+		mode:        mode,
+		label:       label,
+		spot:        gateway(spot),
+		perp:        gateway(perp),
+		tradingPair: tradingPair,
+		symbolA:     symbolA,
+		symbolB:     symbolB,
+		spotSymbol:  spot.ParseSymbols(symbolA, symbolB),
+		perpSymbol:  perp.ParseSymbols(symbolA, symbolB),
+	}
+}
+
+// FundingArbCheckInterval 资金费率套利的检查间隔
+func FundingArbCheckInterval(cfg config.FundingArbConfig) time.Duration {
+	if cfg.CheckIntervalMinutes <= 0 {
+		return defaultFundingArbInterval
+	}
+	return time.Duration(cfg.CheckIntervalMinutes) * time.Minute
+}
+
+// BasisTradeCheckInterval 基差交易的检查间隔
+func BasisTradeCheckInterval(cfg config.BasisConfig) time.Duration {
+	if cfg.CheckIntervalSeconds <= 0 {
+		return defaultBasisTradeInterval
+	}
+	return time.Duration(cfg.CheckIntervalSeconds) * time.Second
+}
+
+// TradingPair 组合的交易对
+func (ct *CarryTrade) TradingPair() string {
+	return ct.tradingPair
+}
+
+// Label 组合的名称（资金费率套利 / 基差交易）
+func (ct *CarryTrade) Label() string {
+	return ct.label
+}
+
+// CheckInterval 检查间隔
+func (ct *CarryTrade) CheckInterval() time.Duration {
+	if ct.mode == CarryBasis {
+		return BasisTradeCheckInterval(ct.config.Trading.Basis)
+	}
+	return FundingArbCheckInterval(ct.config.Trading.FundingArb)
+}
+
+// SetNotifier 设置通知发布器（开平仓和下单失败时发送通知）
+func (ct *CarryTrade) SetNotifier(notifier notify.Publisher) {
+	ct.notifier = notifier
+}
+
+// SetEventStore 设置交易事件的持久化存储
+func (ct *CarryTrade) SetEventStore(s store.Store) {
+	ct.events = &eventRecorder{store: s, tradingPair: ct.tradingPair}
+}
+
+// SetFailover 设置主备切换（备用实例跳过检查，由主实例负责开平仓）
+func (ct *CarryTrade) SetFailover(f *Failover) {
+	ct.failover = f
+}
+
+// SetStateStore 设置组合持仓的持久化存储，并读取上次保存的持仓（重启后继续等待平仓条件）
+func (ct *CarryTrade) SetStateStore(s store.Store) {
+	ct.stateStore = s
+	ct.position = nil
+	if s == nil {
+		return
+	}
+	data, err := s.Get(ct.stateKey())
+	if err != nil {
+		logger.Warnf("[%s] 读取 %s 组合持仓失败: %v", ct.label, ct.tradingPair, err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	var pos carryPosition
+	if err := json.Unmarshal(data, &pos); err != nil {
+		logger.Warnf("[%s] 解析 %s 组合持仓失败: %v", ct.label, ct.tradingPair, err)
+		return
+	}
+	if pos.SpotSize <= 0 && pos.PerpSize <= 0 {
+		return
+	}
+	ct.position = &pos
+	logger.Printf("[%s] 恢复 %s 组合持仓（开仓于 %s）- 现货:%.8f, 合约空仓:%.8f, 开仓时年化资金费率:%.2f%%, 合约溢价:%.3f%%",
+		ct.label, ct.tradingPair, pos.OpenedAt.Local().Format("01-02 15:04:05"), pos.SpotSize, pos.PerpSize, pos.EntryAnnualPercent, pos.EntryBasisPercent)
+}
+
+// Setup 设置合约杠杆（测试模式下跳过）
+func (ct *CarryTrade) Setup() error {
+	leverage := ct.leverage()
+	err := ct.perp.SetLeverage(ct.perpSymbol, leverage)
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("[%s] 测试模式 - 跳过设置 %s 杠杆 (%dx)", ct.label, ct.tradingPair, leverage)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("设置杠杆失败: %w", err)
+	}
+	return nil
+}
+
+// Run 检查开平仓依据：无持仓且达到开仓阈值时开仓，持仓期间回落到平仓阈值以下时平仓
+func (ct *CarryTrade) Run(ctx context.Context) error {
+	if ct.failover != nil && ct.failover.IsStandby() {
+		return nil
+	}
+
+	quote, err := ct.fetchQuote()
+	if err != nil {
+		return err
+	}
+	value, unit := quote.annual, "年化资金费率"
+	if ct.mode == CarryBasis {
+		value, unit = quote.basis, "合约溢价"
+	}
+	entry, exit := ct.thresholds()
+	logger.Printf("[%s] %s 年化资金费率 %.2f%%，合约溢价 %+.3f%% (现货 %.4f, 合约 %.4f)，%s开仓阈值 %.3f%%，平仓阈值 %.3f%%",
+		ct.label, ct.tradingPair, quote.annual, quote.basis, quote.spotPrice, quote.perpPrice, unit, entry, exit)
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s检查已取消: %w", ct.label, err)
+	}
+
+	switch {
+	case ct.position == nil && value >= entry:
+		return ct.open(quote)
+	case ct.position == nil:
+		return nil
+	case value <= exit:
+		return ct.close(fmt.Sprintf("%s回落至 %.3f%%", unit, value))
+	case ct.position.SpotSize <= 0 || ct.position.PerpSize <= 0:
+		return ct.close("继续平掉上次未平的一边")
+	}
+	logger.Printf("[%s] %s 持有组合 - 数量:%.8f %s, 开仓时年化资金费率:%.2f%%, 合约溢价:%.3f%%",
+		ct.label, ct.tradingPair, ct.position.Size, ct.symbolA, ct.position.EntryAnnualPercent, ct.position.EntryBasisPercent)
+	return nil
+}
+
+// fetchQuote 获取资金费率和现货/合约最新价格
+func (ct *CarryTrade) fetchQuote() (*carryQuote, error) {
+	stats, err := ct.perp.FetchDerivativesStats(ct.perpSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("交易所未提供 %s 的资金费率", ct.tradingPair)
+	}
+	spotTicker, err := ct.spot.FetchTicker(ct.spotSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取现货行情失败: %w", err)
+	}
+	perpTicker, err := ct.perp.FetchTicker(ct.perpSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取合约行情失败: %w", err)
+	}
+	if spotTicker.Last <= 0 || perpTicker.Last <= 0 {
+		return nil, fmt.Errorf("价格无效: 现货 %.8f, 合约 %.8f", spotTicker.Last, perpTicker.Last)
+	}
+	return &carryQuote{
+		annual:    fundingAnnualPercent(stats),
+		spotPrice: spotTicker.Last,
+		perpPrice: perpTicker.Last,
+		basis:     basisPercent(spotTicker.Last, perpTicker.Last),
+	}, nil
+}
+
+// open 买入现货并做空等量永续合约；合约开空失败时卖出已买入的现货，避免留下单边敞口
+func (ct *CarryTrade) open(quote *carryQuote) error {
+	amount := ct.amount()
+	if balance, err := ct.spot.FetchBalance(ct.symbolB); err == nil && balance < amount {
+		logger.Printf("[%s] ⚠️ %s 现货可用余额不足 (需要%.2f，可用%.2f %s)，跳过开仓", ct.label, ct.tradingPair, amount, balance, ct.symbolB)
+		return nil
+	}
+
+	size := amount / quote.spotPrice
+	logger.Printf("[%s] %s 买入现货 %.8f %s (约%.2f %s) 并做空等量永续合约",
+		ct.label, ct.tradingPair, size, ct.symbolA, amount, ct.symbolB)
+
+	spotID, err := ct.spot.PlaceOrder(ct.spotSymbol, "buy", size, map[string]interface{}{})
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("[%s] 测试模式 - 仅模拟开仓，未向交易所下单", ct.label)
+		return nil
+	}
+	ct.recordEvent(orderEvent("buy", size, spotID, err))
+	if err != nil {
+		return fmt.Errorf("买入现货失败: %w", err)
+	}
+	spotOrder := ct.fetchFill(ct.spot, ct.spotSymbol, spotID)
+	pos := &carryPosition{
+		Size:               size,
+		SpotEntryPrice:     quote.spotPrice,
+		EntryAnnualPercent: quote.annual,
+		EntryBasisPercent:  quote.basis,
+		OpenedAt:           time.Now(),
+	}
+	if spotOrder != nil && spotOrder.FilledSize > 0 {
+		pos.Size = spotOrder.FilledSize
+	}
+	if spotOrder != nil && spotOrder.AvgPrice > 0 {
+		pos.SpotEntryPrice = spotOrder.AvgPrice
+	}
+	pos.SpotSize = pos.Size
+	pos.Fees = feeCost(spotOrder, nil, pos.SpotEntryPrice, pos.Size, ct.symbolA)
+	ct.position = pos
+	ct.saveState()
+
+	perpID, err := ct.perp.PlaceOrder(ct.perpSymbol, "sell", pos.Size, map[string]interface{}{
+		"posSide": "short",
+	})
+	ct.recordEvent(orderEvent("sell", pos.Size, perpID, err))
+	if err != nil {
+		logger.Printf("[%s] ❌ %s 做空永续合约失败: %v，卖出已买入的现货", ct.label, ct.tradingPair, err)
+		ct.publish(notify.LevelError, ct.label+"开仓失败",
+			fmt.Sprintf("%s 现货已买入 %.8f %s，做空永续合约失败: %v，正在卖出现货", ct.tradingPair, pos.Size, ct.symbolA, err))
+		if unwindErr := ct.closeSpot("合约开空失败"); unwindErr != nil {
+			return fmt.Errorf("做空永续合约失败: %w（卖出现货也失败: %v，请人工处理）", err, unwindErr)
+		}
+		ct.position = nil
+		ct.saveState()
+		return fmt.Errorf("做空永续合约失败: %w", err)
+	}
+	perpOrder := ct.fetchFill(ct.perp, ct.perpSymbol, perpID)
+	pos.PerpSize = pos.Size
+	pos.PerpEntryPrice = quote.perpPrice
+	if perpOrder != nil && perpOrder.AvgPrice > 0 {
+		pos.PerpEntryPrice = perpOrder.AvgPrice
+	}
+	pos.Fees += feeCost(perpOrder, nil, pos.PerpEntryPrice, pos.Size, ct.symbolA)
+	ct.saveState()
+
+	ct.recordEvent(TradeEvent{Type: EventPositionOpen, Side: "long", Price: pos.SpotEntryPrice, Size: pos.Size, OrderID: spotID, Reason: ct.label + "现货多头"})
+	ct.recordEvent(TradeEvent{Type: EventPositionOpen, Side: "short", Price: pos.PerpEntryPrice, Size: pos.Size, OrderID: perpID, Reason: ct.label + "合约空头"})
+	logger.Printf("[%s] ✅ %s 开仓成功 - 数量:%.8f, 现货均价:%.4f, 合约均价:%.4f, 成交溢价:%+.3f%%",
+		ct.label, ct.tradingPair, pos.Size, pos.SpotEntryPrice, pos.PerpEntryPrice, basisPercent(pos.SpotEntryPrice, pos.PerpEntryPrice))
+	ct.publish(notify.LevelInfo, ct.label+"开仓",
+		fmt.Sprintf("%s 年化资金费率 %.2f%%，合约溢价 %+.3f%%，现货多头 + 合约空头 %.8f %s，现货均价:%.4f, 合约均价:%.4f",
+			ct.tradingPair, quote.annual, quote.basis, pos.Size, ct.symbolA, pos.SpotEntryPrice, pos.PerpEntryPrice))
+	return nil
+}
+
+// close 先平合约空仓再卖出现货，两边都平掉后统计收益（价差盈亏 + 资金费 - 手续费）
+func (ct *CarryTrade) close(reason string) error {
+	pos := ct.position
+	logger.Printf("[%s] %s %s，平掉组合", ct.label, ct.tradingPair, reason)
+
+	if pos.PerpSize > 0 {
+		orderID, err := ct.perp.PlaceOrder(ct.perpSymbol, "buy", pos.PerpSize, map[string]interface{}{
+			"reduceOnly": true,
+			"posSide":    "short",
+		})
+		if errors.Is(err, exchange.ErrTradingDisabled) {
+			logger.Printf("[%s] 测试模式 - 仅模拟平仓，未向交易所下单", ct.label)
+			return nil
+		}
+		ct.recordEvent(orderEvent("buy", pos.PerpSize, orderID, err))
+		if err != nil {
+			ct.publish(notify.LevelError, ct.label+"平仓失败", fmt.Sprintf("%s 合约平空失败: %v", ct.tradingPair, err))
+			return fmt.Errorf("合约平空失败: %w", err)
+		}
+		order := ct.fetchFill(ct.perp, ct.perpSymbol, orderID)
+		pos.PerpExitPrice = pos.PerpEntryPrice
+		if order != nil && order.AvgPrice > 0 {
+			pos.PerpExitPrice = order.AvgPrice
+		}
+		pos.Fees += feeCost(order, nil, pos.PerpExitPrice, pos.PerpSize, ct.symbolA)
+		pos.PerpSize = 0
+		ct.saveState()
+	}
+
+	if err := ct.closeSpot(reason); err != nil {
+		ct.publish(notify.LevelError, ct.label+"平仓失败", fmt.Sprintf("%s 合约空仓已平，卖出现货失败: %v", ct.tradingPair, err))
+		return err
+	}
+
+	var funding float64
+	fees, err := ct.perp.FetchFundingFees(ct.perpSymbol, pos.OpenedAt)
+	if err != nil {
+		logger.Printf("[%s] 获取 %s 资金费用记录失败: %v", ct.label, ct.tradingPair, err)
+	}
+	for _, fee := range fees {
+		funding += fee.Amount
+	}
+	spread := (pos.SpotExitPrice-pos.SpotEntryPrice)*pos.Size + (pos.PerpEntryPrice-pos.PerpExitPrice)*pos.Size
+	pnl := spread + funding - pos.Fees
+	held := time.Since(pos.OpenedAt).Round(time.Minute)
+
+	ct.recordEvent(TradeEvent{Type: EventPositionClose, Side: "long", Price: pos.SpotExitPrice, Size: pos.Size, Fee: pos.Fees, PnL: pnl, Reason: ct.label + "平仓: " + reason})
+	logger.Printf("[%s] ✅ %s 平仓成功 - 持有 %s，价差盈亏:%.4f, 资金费:%.4f, 手续费:%.4f, 净盈亏:%.4f %s",
+		ct.label, ct.tradingPair, held, spread, funding, pos.Fees, pnl, ct.symbolB)
+	ct.publish(notify.LevelInfo, ct.label+"平仓",
+		fmt.Sprintf("%s %s，持有 %s，价差盈亏 %.4f，资金费 %.4f，净盈亏 %.4f %s", ct.tradingPair, reason, held, spread, funding, pnl, ct.symbolB))
+
+	ct.position = nil
+	ct.saveState()
+	return nil
+}
+
+// closeSpot 卖出组合持有的现货（按可用余额取较小值，现货买入手续费可能以基础币种扣除）
+func (ct *CarryTrade) closeSpot(reason string) error {
+	pos := ct.position
+	if pos.SpotSize <= 0 {
+		return nil
+	}
+	size := pos.SpotSize
+	if balance, err := ct.spot.FetchBalance(ct.symbolA); err == nil && balance < size {
+		size = balance
+	}
+	if size <= 0 {
+		logger.Warnf("[%s] ⚠️ %s 没有可卖出的%s，视为现货已卖出", ct.label, ct.tradingPair, ct.symbolA)
+		pos.SpotSize = 0
+		ct.saveState()
+		return nil
+	}
+
+	orderID, err := ct.spot.PlaceOrder(ct.spotSymbol, "sell", size, map[string]interface{}{})
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("[%s] 测试模式 - 仅模拟卖出现货，未向交易所下单", ct.label)
+		return nil
+	}
+	ct.recordEvent(orderEvent("sell", size, orderID, err))
+	if err != nil {
+		return fmt.Errorf("卖出现货失败（%s）: %w", reason, err)
+	}
+	order := ct.fetchFill(ct.spot, ct.spotSymbol, orderID)
+	pos.SpotExitPrice = pos.SpotEntryPrice
+	if order != nil && order.AvgPrice > 0 {
+		pos.SpotExitPrice = order.AvgPrice
+	}
+	pos.Fees += feeCost(order, nil, pos.SpotExitPrice, size, ct.symbolA)
+	pos.SpotSize = 0
+	ct.saveState()
+	return nil
+}
+
+// fetchFill 查询订单成交情况（查询失败或无成交均价时按成交记录补全，仍失败时返回nil）
+func (ct *CarryTrade) fetchFill(exch exchange.Exchange, symbol, orderID string) *models.Order {
+	order, err := exch.FetchOrder(symbol, orderID)
+	if err != nil {
+		logger.Printf("[%s] 查询订单 %s 失败: %v", ct.label, orderID, err)
+		order = nil
+	}
+	order = fillFromTrades(exch, symbol, orderID, order)
+	if order != nil {
+		ct.recordEvent(fillEvent(order))
+	}
+	return order
+}
+
+// thresholds 开仓和平仓阈值（资金费率套利为年化资金费率，基差交易为合约溢价，%）
+func (ct *CarryTrade) thresholds() (entry, exit float64) {
+	if ct.mode == CarryBasis {
+		cfg := ct.config.Trading.Basis
+		entry = cfg.EntryPercent
+		if entry <= 0 {
+			entry = defaultBasisEntryPercent
+		}
+		exit = cfg.ExitPercent
+		if exit <= 0 {
+			exit = entry / 5
+		}
+		return entry, exit
+	}
+
+	cfg := ct.config.Trading.FundingArb
+	entry = cfg.EntryAnnualPercent
+	if entry <= 0 {
+		entry = defaultFundingArbEntryPercent
+	}
+	exit = cfg.ExitAnnualPercent
+	if exit <= 0 {
+		exit = entry / 3
+	}
+	return entry, exit
+}
+
+// amount 每次开仓的现货买入金额（计价币种）
+func (ct *CarryTrade) amount() float64 {
+	amount := ct.config.Trading.FundingArb.Amount
+	if ct.mode == CarryBasis {
+		amount = ct.config.Trading.Basis.Amount
+	}
+	if amount > 0 {
+		return amount
+	}
+	return ct.config.Trading.Amount
+}
+
+// leverage 永续合约杠杆
+func (ct *CarryTrade) leverage() int {
+	leverage := ct.config.Trading.FundingArb.Leverage
+	if ct.mode == CarryBasis {
+		leverage = ct.config.Trading.Basis.Leverage
+	}
+	if leverage > 0 {
+		return leverage
+	}
+	return 1
+}
+
+// fundingAnnualPercent 当前资金费率折算的年化费率（%，结算周期未知时按8小时折算）
+func fundingAnnualPercent(stats *models.DerivativesStats) float64 {
+	if stats.FundingIntervalHours > 0 {
+		return stats.AnnualizedFundingPercent()
+	}
+	return stats.FundingRate * 100 * 365 * 24 / defaultFundingIntervalHours
+}
+
+// basisPercent 合约相对现货的溢价（%，正数表示合约升水）
+func basisPercent(spotPrice, perpPrice float64) float64 {
+	if spotPrice <= 0 {
+		return 0
+	}
+	return (perpPrice - spotPrice) / spotPrice * 100
+}
+
+// saveState 保存组合持仓，无持仓时清除
+func (ct *CarryTrade) saveState() {
+	if ct.stateStore == nil {
+		return
+	}
+	data := []byte("{}")
+	if ct.position != nil {
+		var err error
+		if data, err = json.Marshal(ct.position); err != nil {
+			logger.Warnf("[%s] 序列化组合持仓失败: %v", ct.label, err)
+			return
+		}
+	}
+	if err := ct.stateStore.Put(ct.stateKey(), data); err != nil {
+		logger.Warnf("[%s] 保存组合持仓失败: %v", ct.label, err)
+	}
+}
+
+// stateKey 组合持仓在持久化存储中的键（funding_arb:<交易对> / basis_trade:<交易对>）
+func (ct *CarryTrade) stateKey() string {
+	if ct.mode == CarryBasis {
+		return "basis_trade:" + ct.tradingPair
+	}
+	return "funding_arb:" + ct.tradingPair
+}
+
+// recordEvent 记录组合产生的交易事件（来源为 funding_arb / basis_trade）
+func (ct *CarryTrade) recordEvent(event TradeEvent) {
+	source := "funding_arb"
+	if ct.mode == CarryBasis {
+		source = "basis_trade"
+	}
+	ct.events.record(source, event)
+}
+
+func (ct *CarryTrade) publish(level notify.Level, title, message string) {
+	if ct.notifier != nil {
+		ct.notifier.Publish(level, title, message)
+	}
+}
@@ -0,0 +1,241 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"dsbot/internal/indicator"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// 参数调优默认配置
+const (
+	defaultCoachIntervalHours    = 24
+	defaultCoachLookbackTrades   = 20
+	defaultCoachMinTrades        = 5
+	defaultCoachMaxChangePercent = 25.0
+)
+
+// tunableParam 允许AI调整的技术指标参数及其允许范围
+type tunableParam struct {
+	name     string
+	min, max float64
+	integer  bool
+	get      func(*indicator.IndicatorConfig) float64
+	set      func(*indicator.IndicatorConfig, float64)
+}
+
+// intParam 整数参数
+func intParam(name string, min, max float64, field func(*indicator.IndicatorConfig) *int) tunableParam {
+	return tunableParam{
+		name: name, min: min, max: max, integer: true,
+		get: func(c *indicator.IndicatorConfig) float64 { return float64(*field(c)) },
+		set: func(c *indicator.IndicatorConfig, v float64) { *field(c) = int(math.Round(v)) },
+	}
+}
+
+// floatParam 小数参数
+func floatParam(name string, min, max float64, field func(*indicator.IndicatorConfig) *float64) tunableParam {
+	return tunableParam{
+		name: name, min: min, max: max,
+		get: func(c *indicator.IndicatorConfig) float64 { return *field(c) },
+		set: func(c *indicator.IndicatorConfig, v float64) { *field(c) = v },
+	}
+}
+
+// tunableParams 允许调整的参数（均线和MACD周期与提示词中的描述绑定，不参与调整）
+var tunableParams = []tunableParam{
+	intParam("rsi_period", 7, 28, func(c *indicator.IndicatorConfig) *int { return &c.RSIPeriod }),
+	intParam("bb_period", 10, 40, func(c *indicator.IndicatorConfig) *int { return &c.BBPeriod }),
+	floatParam("bb_std_dev", 1.5, 3, func(c *indicator.IndicatorConfig) *float64 { return &c.BBStdDev }),
+	intParam("atr_period", 7, 28, func(c *indicator.IndicatorConfig) *int { return &c.ATRPeriod }),
+	intParam("volume_ma_period", 10, 40, func(c *indicator.IndicatorConfig) *int { return &c.VolumeMAPeriod }),
+	intParam("support_resistance_lookback", 10, 60, func(c *indicator.IndicatorConfig) *int { return &c.SupportResistanceLookback }),
+}
+
+// coachState 参数调优状态（评估在后台进行，新参数在下一轮交易周期开始时生效）
+type coachState struct {
+	mu      sync.Mutex
+	lastRun time.Time
+	running bool
+	pending *indicator.IndicatorConfig // 待应用的参数
+}
+
+// applyPendingTuning 应用后台评估得出的新参数（在交易周期内调用，与指标计算不会同时发生）
+func (bot *TradingBot) applyPendingTuning() {
+	bot.coach.mu.Lock()
+	pending := bot.coach.pending
+	bot.coach.pending = nil
+	bot.coach.mu.Unlock()
+
+	if pending != nil {
+		bot.calculator = indicator.NewCalculatorWithConfig(pending)
+		logger.Printf("[参数调优] 新的技术指标参数已生效")
+	}
+}
+
+// maybeCoach 启用参数调优时，距上次评估超过间隔且近期已平仓交易足够时，在后台请求AI评估并建议参数调整
+func (bot *TradingBot) maybeCoach(ctx context.Context, marketData *models.MarketData) {
+	cfg := bot.config.Trading.Coach
+	if !cfg.Enable || bot.journal == nil || bot.aiClient == nil {
+		return
+	}
+	interval := time.Duration(cfg.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = defaultCoachIntervalHours * time.Hour
+	}
+
+	bot.coach.mu.Lock()
+	defer bot.coach.mu.Unlock()
+	if bot.coach.running || time.Since(bot.coach.lastRun) < interval {
+		return
+	}
+
+	current := bot.calculator.Config()
+	review := bot.tuningReview(marketData, &current)
+	if review == nil {
+		return
+	}
+	bot.coach.running = true
+	bot.coach.lastRun = time.Now()
+
+	go func() {
+		defer func() {
+			bot.coach.mu.Lock()
+			bot.coach.running = false
+			bot.coach.mu.Unlock()
+		}()
+
+		advice, err := bot.aiClient.SuggestTuning(ctx, review)
+		if err != nil {
+			logger.Warnf("[参数调优] [%s] 评估失败: %v", bot.tradingPair, err)
+			return
+		}
+		logger.Printf("[参数调优] [%s] 评估: %s", bot.tradingPair, advice.Assessment)
+		if len(advice.Suggestions) == 0 {
+			logger.Printf("[参数调优] [%s] 无需调整参数", bot.tradingPair)
+			return
+		}
+		for _, s := range advice.Suggestions {
+			logger.Printf("[参数调优] [%s] 建议 %s → %g: %s", bot.tradingPair, s.Param, s.Value, s.Reason)
+		}
+		if !cfg.AutoApply {
+			return
+		}
+
+		maxChange := cfg.MaxChangePercent
+		if maxChange <= 0 {
+			maxChange = defaultCoachMaxChangePercent
+		}
+		tuned, changes := applyTuning(current, advice.Suggestions, maxChange)
+		if len(changes) == 0 {
+			return
+		}
+		bot.coach.mu.Lock()
+		bot.coach.pending = &tuned
+		bot.coach.mu.Unlock()
+		logger.Printf("[参数调优] [%s] 自动应用（下一轮生效）: %s", bot.tradingPair, strings.Join(changes, ", "))
+		bot.publish(notify.LevelInfo, "参数调优", fmt.Sprintf("%s 技术指标参数调整: %s", bot.tradingPair, strings.Join(changes, ", ")))
+	}()
+}
+
+// tuningReview 汇总最近已平仓交易的表现和当前市场状态（已平仓交易不足时返回nil）
+func (bot *TradingBot) tuningReview(marketData *models.MarketData, current *indicator.IndicatorConfig) *models.TuningReview {
+	cfg := bot.config.Trading.Coach
+	lookback := cfg.LookbackTrades
+	if lookback <= 0 {
+		lookback = defaultCoachLookbackTrades
+	}
+	minTrades := cfg.MinTrades
+	if minTrades <= 0 {
+		minTrades = defaultCoachMinTrades
+	}
+
+	var closed []journal.Entry
+	for _, e := range bot.journal.Entries() {
+		if e.Closed && e.TradingPair == bot.tradingPair {
+			closed = append(closed, e)
+		}
+	}
+	if len(closed) < minTrades {
+		return nil
+	}
+	if len(closed) > lookback {
+		closed = closed[len(closed)-lookback:]
+	}
+
+	review := &models.TuningReview{
+		TradingPair: bot.tradingPair,
+		Timeframe:   bot.config.Trading.Timeframe,
+		Trades:      len(closed),
+	}
+	var wins int
+	var totalReturn, totalHours float64
+	for _, e := range closed {
+		if e.ReturnPct > 0 {
+			wins++
+		}
+		if e.ExitReason == "风控平仓" {
+			review.StopLossExits++
+		}
+		totalReturn += e.ReturnPct
+		totalHours += e.ClosedAt.Sub(e.OpenedAt).Hours()
+		review.NetPnL += e.NetPnL
+	}
+	review.WinRate = float64(wins) / float64(len(closed)) * 100
+	review.AvgReturnPct = totalReturn / float64(len(closed))
+	review.AvgHoldHours = totalHours / float64(len(closed))
+
+	if marketData.TrendAnalysis != nil {
+		review.Regime = marketData.TrendAnalysis.Overall
+	}
+	if tech := marketData.TechnicalData; tech != nil {
+		review.ATRPercent = tech.ATRPercent
+		review.RSI = tech.RSI
+	}
+	for _, p := range tunableParams {
+		review.Params = append(review.Params, models.TuningParam{Name: p.name, Value: p.get(current), Min: p.min, Max: p.max})
+	}
+	return review
+}
+
+// applyTuning 在允许范围内应用参数建议，单次调整幅度不超过当前值的 maxChangePercent%（整数参数至少可调整1），返回新参数和调整说明
+func applyTuning(current indicator.IndicatorConfig, suggestions []models.TuningSuggestion, maxChangePercent float64) (indicator.IndicatorConfig, []string) {
+	tuned := current
+	var changes []string
+	for _, s := range suggestions {
+		for _, p := range tunableParams {
+			if p.name != s.Param {
+				continue
+			}
+			from := p.get(&tuned)
+			maxDelta := math.Abs(from) * maxChangePercent / 100
+			if p.integer {
+				maxDelta = math.Max(1, math.Floor(maxDelta))
+			}
+			to := math.Max(p.min, math.Min(p.max, s.Value))
+			to = math.Max(from-maxDelta, math.Min(from+maxDelta, to))
+			if p.integer {
+				to = math.Round(to)
+			}
+			if to == from {
+				break
+			}
+			p.set(&tuned, to)
+			change := fmt.Sprintf("%s %g → %g", p.name, from, to)
+			if to != s.Value {
+				change += fmt.Sprintf("（建议 %g，受范围和幅度限制）", s.Value)
+			}
+			changes = append(changes, change)
+			break
+		}
+	}
+	return tuned, changes
+}
@@ -0,0 +1,115 @@
+package strategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/calendar"
+	"dsbot/internal/config"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// dailyLossState 每日亏损上限的当日状态（策略和各方向的风险管理器共用，达到上限后当日保持暂停，下一个交易日恢复）
+type dailyLossState struct {
+	mu       sync.Mutex
+	day      string // 交易日
+	breached bool   // 当日是否已达到亏损上限
+}
+
+// check 统计交易对当日盈亏（交易日志中当日平仓的净盈亏 + 当前持仓的未实现盈亏）并更新当日状态，
+// 返回当日是否已达到上限、是否为首次达到、当日盈亏及说明
+func (s *dailyLossState) check(cfg *config.Config, j *journal.Journal, cal *calendar.Calendar, tradingPair string, unrealized float64) (breached, first bool, total float64, detail string) {
+	limit := cfg.Trading.RiskManagement.DailyLossLimit
+	now := time.Now()
+	day := now.UTC().Format("2006-01-02")
+	if cal != nil {
+		day = cal.TradingDay(now)
+	}
+
+	var realized float64
+	if j != nil {
+		realized = j.Realized(tradingPair, tradingDayStart(cal, now)).NetPnL
+	}
+	total = realized + unrealized
+	detail = fmt.Sprintf("当日盈亏 %.2f %s (已实现 %.2f, 未实现 %.2f)，亏损上限 %.2f",
+		total, cfg.Trading.SymbolB, realized, unrealized, limit.MaxLoss)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.day != day {
+		s.day, s.breached = day, false
+	}
+	if -total >= limit.MaxLoss && !s.breached {
+		s.breached = true
+		return true, true, total, detail
+	}
+	return s.breached, false, total, detail
+}
+
+// dailyLossAction 达到每日亏损上限后的处理说明
+func dailyLossAction(cfg *config.Config) string {
+	if cfg.Trading.RiskManagement.DailyLossLimit.Action == "close" {
+		return "平仓并暂停开仓至下一个交易日"
+	}
+	return "暂停开仓至下一个交易日，已有持仓仍由风控管理"
+}
+
+// checkDailyLoss 统计交易对当日盈亏，亏损达到上限时当日暂停开仓（首次达到时记录日志并发送告警），
+// 返回当日是否已达到上限及说明
+func (rm *RiskManager) checkDailyLoss(unrealized float64) (bool, string) {
+	if rm.config.Trading.RiskManagement.DailyLossLimit.MaxLoss <= 0 {
+		return false, ""
+	}
+	breached, first, total, detail := rm.dailyLoss.check(rm.config, rm.journal, rm.calendar, rm.tradingPair, unrealized)
+	if first {
+		action := dailyLossAction(rm.config)
+		logger.Warnf("[风险管理] ⚠️ %s %s，%s", rm.tradingPair, detail, action)
+		rm.recordEvent(TradeEvent{Type: EventRiskAction, PnL: total, Reason: "达到每日亏损上限: " + action})
+		rm.publish(notify.LevelWarning, "达到每日亏损上限", fmt.Sprintf("%s %s，%s", rm.tradingPair, detail, action))
+	}
+	return breached, detail
+}
+
+// shouldCloseForDailyLoss 每日亏损上限配置为平仓（close）且当日已达到上限时平掉持仓
+func (rm *RiskManager) shouldCloseForDailyLoss(unrealized float64) bool {
+	if rm.config.Trading.RiskManagement.DailyLossLimit.Action != "close" {
+		return false
+	}
+	breached, detail := rm.checkDailyLoss(unrealized)
+	if breached {
+		logger.Printf("[风险管理] 已达到每日亏损上限（%s），平仓", detail)
+	}
+	return breached
+}
+
+// passDailyLossGate 交易对当日亏损达到上限后暂停开仓和加仓至下一个交易日
+// 按策略的交易日志和交易日历统计（未创建风险管理器时同样生效），平仓和反手信号的平仓部分不受影响
+func (bot *TradingBot) passDailyLossGate(signal *models.TradeSignal) (bool, string) {
+	if bot.config.Trading.RiskManagement.DailyLossLimit.MaxLoss <= 0 || !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	var unrealized float64
+	if bot.hedge {
+		for _, pos := range bot.positions {
+			unrealized += pos.UnrealizedPnL
+		}
+	} else if bot.currentPosition != nil {
+		unrealized = bot.currentPosition.UnrealizedPnL
+	}
+	breached, first, total, detail := bot.dailyLoss.check(bot.config, bot.journal, bot.calendar, bot.tradingPair, unrealized)
+	if first {
+		action := dailyLossAction(bot.config)
+		logger.Warnf("[风险管理] ⚠️ %s %s，%s", bot.tradingPair, detail, action)
+		bot.recordEvent(TradeEvent{Type: EventRiskAction, PnL: total, Reason: "达到每日亏损上限: " + action})
+		bot.publish(notify.LevelWarning, "达到每日亏损上限", fmt.Sprintf("%s %s，%s", bot.tradingPair, detail, action))
+	}
+	if breached {
+		logger.Warnf("[风险管理] ⚠️ %s，暂停开仓", detail)
+	}
+	return !breached, detail
+}
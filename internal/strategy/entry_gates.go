@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// entryGateFunc 下单前检查，返回是否通过及说明
+type entryGateFunc func(bot *TradingBot, signal *models.TradeSignal, marketData *models.MarketData) (bool, string)
+
+// entryGate 信号确认后的下单前检查
+type entryGate struct {
+	name       string        // 检查名（写入下单意图和链路追踪）
+	check      entryGateFunc // 检查函数
+	skipReason string        // 未通过时的跳过原因（为空时使用检查说明）
+	closeLeg   bool          // 未通过时反向信号仍平掉反向仓位，只跳过反手开仓
+}
+
+// entryGates 信号确认后按顺序执行的下单前检查，交易周期和突破入场触发时共用，
+// 突破入场触发时按触发价和最新持仓、余额重新检查
+var entryGates = []entryGate{
+	// 禁止交易的交易对不执行（持仓仍由风控管理）
+	{name: "embargo", check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passEmbargoGate()
+	}},
+	// 交易时段外和禁止开仓时间段内不开仓（反向信号仍平仓）
+	{name: "session", skipReason: "不在交易时段内", closeLeg: true, check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passSessionGate(signal)
+	}},
+	// 止盈距离相对止损距离过小时不开仓
+	{name: "risk_reward", skipReason: "盈亏比过低", check: (*TradingBot).passRiskRewardGate},
+	// 波动过低或过高的行情中不开仓
+	{name: "volatility", skipReason: "波动率不在允许范围内", check: (*TradingBot).passVolatilityFilter},
+	// 历史期望值为负的相似信号不开仓
+	{name: "expectancy", skipReason: "相似信号历史期望值过低", check: (*TradingBot).passExpectancyGate},
+	// 连续亏损后的冷却期内不开仓
+	{name: "loss_cooldown", skipReason: "连续亏损冷却中", check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passLossCooldownGate(signal)
+	}},
+	// 维持保证金率过高时不开仓
+	{name: "margin_ratio", skipReason: "维持保证金率过高", check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passMarginRatioGate(signal)
+	}},
+	// 当日亏损达到上限后暂停开仓（反向信号仍平仓）
+	{name: "daily_loss", skipReason: "达到每日亏损上限", closeLeg: true, check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passDailyLossGate(signal)
+	}},
+	// 按账户权益计算的交易金额为0时不开仓
+	{name: "sizing", skipReason: "仓位计算结果为0", check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passSizingGate(signal)
+	}},
+	// 流动性不足（成交额过低、价差过大或盘口深度不足）时不开仓
+	{name: "liquidity", skipReason: "流动性不足", check: (*TradingBot).passLiquidityGate},
+	// 全部交易对的持仓数或名义价值合计超过上限时不开仓（最后检查，通过时预占额度，未下单时撤销）
+	{name: "portfolio", skipReason: "超过全局持仓限制", check: func(bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) (bool, string) {
+		return bot.passPortfolioGate(signal)
+	}},
+}
+
+// passEntryGates 按顺序执行 entryGates，未通过时以跳过原因结束下单意图并返回 false；
+// 允许平仓的检查未通过且信号需要先平掉反向仓位时返回 true，并设置 closeOnly 使下单只平仓不反手
+func (bot *TradingBot) passEntryGates(signal *models.TradeSignal, marketData *models.MarketData) bool {
+	bot.closeOnly = ""
+	for _, gate := range entryGates {
+		passed, detail := gate.check(bot, signal, marketData)
+		if bot.checkGate(gate.name, passed, detail) {
+			continue
+		}
+		reason := gate.skipReason
+		if reason == "" {
+			reason = detail
+		}
+		if gate.closeLeg && bot.reversesPosition(signal) {
+			logger.Printf("[INFO] %s，%s 信号只平掉 %s 仓位，不反手开仓", reason, signal.Signal, bot.currentPosition.Side)
+			bot.closeOnly = reason
+			return true
+		}
+		bot.skipIntent(reason)
+		return false
+	}
+	return true
+}
+
+// passEmbargoGate 交易对在禁止交易名单中时不执行信号（平仓信号同样不执行，持仓仍由风控管理）
+func (bot *TradingBot) passEmbargoGate() (bool, string) {
+	if bot.embargo == nil {
+		return true, ""
+	}
+	blocked, reason := bot.embargo.Check(bot.tradingPair)
+	if blocked {
+		logger.Warnf("[禁止交易] ⚠️ %s，跳过执行", reason)
+	}
+	return !blocked, reason
+}
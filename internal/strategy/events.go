@@ -0,0 +1,127 @@
+package strategy
+
+import (
+	"encoding/json"
+	"time"
+
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+)
+
+// EventCollection 交易事件在持久化存储中的集合名
+const EventCollection = "trade_events"
+
+// 交易事件类型
+const (
+	EventSignal        = "signal"         // AI交易信号
+	EventOrder         = "order"          // 提交订单
+	EventFill          = "fill"           // 订单成交（查询到的成交数量和均价）
+	EventPositionOpen  = "position_open"  // 开仓
+	EventPositionClose = "position_close" // 平仓
+	EventRiskAction    = "risk_action"    // 风控操作（止盈止损平仓、达到每日亏损上限等）
+)
+
+// TradeEvent 交易事件 - 按时间顺序记录信号、订单、成交、开平仓和风控操作，供统计和报表使用
+type TradeEvent struct {
+	Type        string    `json:"type"`
+	TradingPair string    `json:"trading_pair"`
+	Source      string    `json:"source"`                 // 记录来源: strategy / risk / funding_arb
+	Signal      string    `json:"signal,omitempty"`       // 交易信号（BUY/SELL/HOLD）
+	Confidence  string    `json:"confidence,omitempty"`   // 信号信心
+	Side        string    `json:"side,omitempty"`         // 订单为 buy/sell，持仓为 long/short
+	Price       float64   `json:"price,omitempty"`        // 价格（成交事件为成交均价）
+	Size        float64   `json:"size,omitempty"`         // 数量（基础币种）
+	OrderID     string    `json:"order_id,omitempty"`     // 交易所订单ID
+	Fee         float64   `json:"fee,omitempty"`          // 手续费（成交事件为订单手续费，单位见 fee_currency；平仓事件为开平仓手续费合计）
+	FeeCurrency string    `json:"fee_currency,omitempty"` // 手续费币种
+	PnL         float64   `json:"pnl,omitempty"`          // 平仓净盈亏（计价币种）
+	Reason      string    `json:"reason,omitempty"`       // 原因（信号理由、平仓原因、风控操作）
+	Error       string    `json:"error,omitempty"`        // 下单失败的错误
+	Time        time.Time `json:"time"`
+}
+
+// eventRecorder 交易事件记录器（同一交易对的策略和风险管理器共用，未设置存储时不记录）
+type eventRecorder struct {
+	store       store.Store
+	tradingPair string
+}
+
+// record 写入一条交易事件（写入存储失败不影响交易流程）
+func (r *eventRecorder) record(source string, event TradeEvent) {
+	if r == nil || r.store == nil {
+		return
+	}
+	event.TradingPair = r.tradingPair
+	event.Source = source
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Warnf("[交易事件] 序列化失败: %v", err)
+		return
+	}
+	if err := r.store.Append(EventCollection, data); err != nil {
+		logger.Warnf("[交易事件] 写入存储失败: %v", err)
+	}
+}
+
+// orderEvent 下单结果事件
+func orderEvent(side string, size float64, orderID string, err error) TradeEvent {
+	event := TradeEvent{Type: EventOrder, Side: side, Size: size, OrderID: orderID}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// fillEvent 订单成交事件（手续费为正数表示支出）
+func fillEvent(order *models.Order) TradeEvent {
+	event := TradeEvent{
+		Type:    EventFill,
+		Side:    order.Side,
+		Price:   order.AvgPrice,
+		Size:    order.FilledSize,
+		OrderID: order.OrderID,
+		Fee:     -order.Fee,
+		Reason:  order.State,
+	}
+	event.FeeCurrency = order.FeeCurrency
+	if !order.UpdatedAt.IsZero() {
+		event.Time = order.UpdatedAt
+	}
+	return event
+}
+
+// closeEvent 平仓事件（按交易日志中已平仓的条目，未启用交易日志时为nil，只记录平仓价和原因）
+func closeEvent(entry *journal.Entry, exitPrice float64, reason string) TradeEvent {
+	event := TradeEvent{Type: EventPositionClose, Price: exitPrice, Reason: reason}
+	if entry != nil {
+		event.Side = entry.Side
+		event.Price = entry.ExitPrice
+		event.Size = entry.Size
+		event.Fee = entry.EntryFee + entry.ExitFee
+		event.PnL = entry.NetPnL
+	}
+	return event
+}
+
+// SetEventStore 设置交易事件的持久化存储（策略和风险管理器写入同一集合）
+func (bot *TradingBot) SetEventStore(s store.Store) {
+	bot.events = &eventRecorder{store: s, tradingPair: bot.tradingPair}
+	for _, rm := range bot.riskManagers() {
+		rm.events = bot.events
+	}
+}
+
+// recordEvent 记录策略产生的交易事件
+func (bot *TradingBot) recordEvent(event TradeEvent) {
+	bot.events.record("strategy", event)
+}
+
+// recordEvent 记录风险管理器产生的交易事件
+func (rm *RiskManager) recordEvent(event TradeEvent) {
+	rm.events.record("risk", event)
+}
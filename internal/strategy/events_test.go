@@ -0,0 +1,71 @@
+package strategy
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+
+	_ "modernc.org/sqlite"
+)
+
+// 一轮交易的信号、订单、成交和开仓事件写入 SQLite 的 records 表
+func TestTradeEventsInSQLite(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "dsbot.db")
+	s, err := store.Open(&config.StorageConfig{Backend: store.BackendSQLite, DSN: dsn})
+	if err != nil {
+		t.Fatalf("打开 SQLite 存储失败: %v", err)
+	}
+	defer s.Close()
+	if s.Backend() != store.BackendSQLite {
+		t.Fatalf("后端 = %s, 期望 sqlite", s.Backend())
+	}
+
+	cfg := newTestConfig(config.TradingModeFutures)
+	m, symbol := newTestExchange(cfg)
+	setTestCandles(cfg, m, symbol, 60, 100)
+
+	bot := NewTradingBot(cfg, m, &stubProvider{signal: models.TradeSignal{Signal: "BUY", Confidence: "HIGH", Reason: "测试"}})
+	bot.SetJournal(newTestJournal(t))
+	bot.SetEventStore(s)
+	if err := bot.runCycle(context.Background()); err != nil {
+		t.Fatalf("交易流程失败: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT data FROM records WHERE collection = ? ORDER BY id", EventCollection)
+	if err != nil {
+		t.Fatalf("查询 records 表失败: %v", err)
+	}
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			t.Fatal(err)
+		}
+		var event TradeEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("事件格式错误: %v", err)
+		}
+		if event.TradingPair != testPair || event.Time.IsZero() {
+			t.Fatalf("事件 = %+v, 期望带交易对和时间", event)
+		}
+		types = append(types, event.Type)
+	}
+	want := []string{EventSignal, EventOrder, EventFill, EventPositionOpen}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("事件 = %v, 期望 %v", types, want)
+	}
+}
@@ -0,0 +1,262 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 开仓下单执行方式
+const (
+	ExecutionModeMarket = "market" // 市价单（默认）
+	ExecutionModeLimit  = "limit"  // 以卖一/买一价加最大滑点提交限价单，超时后撤单
+
+	ExecutionFallbackMarket = "market" // 限价单超时未全部成交时，剩余数量改为市价单（默认）
+	ExecutionFallbackCancel = "cancel" // 限价单超时未全部成交时撤单，只保留已成交部分
+)
+
+// limitPollInterval 限价单成交状态的查询间隔
+const limitPollInterval = time.Second
+
+// limitSupported 开仓是否使用限价单（启动时确定，交易所不支持限价单时记录警告并使用市价单）
+func limitSupported(cfg *config.Config, exch exchange.Exchange) bool {
+	if cfg.Trading.Execution.Mode != ExecutionModeLimit {
+		return false
+	}
+	if !exch.Capabilities().LimitOrders {
+		logger.Warnf("[WARNING] %s 不支持限价单，开仓使用市价单", exch.GetExchangeName())
+		return false
+	}
+	return true
+}
+
+// entryLimitPrice 按卖一（买入）或买一（卖出）价加最大滑点计算开仓限价
+func (bot *TradingBot) entryLimitPrice(symbol, side string) (float64, error) {
+	bps := bot.config.Trading.Execution.MaxSlippageBps
+	if bps <= 0 {
+		bps = 10
+	}
+
+	ticker, err := bot.exchange.FetchTicker(symbol)
+	if err != nil {
+		return 0, err
+	}
+	price, direction := ticker.Ask, 1.0
+	if side == "sell" {
+		price, direction = ticker.Bid, -1.0
+	}
+	if price <= 0 {
+		price = ticker.Last
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("%s 价格无效", symbol)
+	}
+	return price * (1 + direction*bps/10000), nil
+}
+
+// submitEntry 提交开仓单
+// limit 模式下以限价单开仓并等待成交，超时后撤单，未成交部分按 fallback 改为市价单或放弃；
+// br 为附带在开仓单上的括号单（可为nil）。部分成交后补单时记录补单ID，由 verifyEntry 合并两笔成交
+func (bot *TradingBot) submitEntry(symbol, side string, amount float64, params map[string]interface{}, br *bracket) (string, error) {
+	bot.entryFallbackID = ""
+	bot.entryUnfilled = false
+	if !bot.limitEntry {
+		return bot.submitOrder(symbol, side, amount, params)
+	}
+
+	cfg := bot.config.Trading.Execution
+	limit, err := bot.entryLimitPrice(symbol, side)
+	if err != nil {
+		logger.Printf("[WARNING] 计算开仓限价失败: %v，改用市价单", err)
+		return bot.submitOrder(symbol, side, amount, params)
+	}
+
+	params[exchange.ParamLimitPrice] = limit
+	orderID, err := bot.submitOrder(symbol, side, amount, params)
+	delete(params, exchange.ParamLimitPrice)
+	if err != nil {
+		return "", err
+	}
+
+	wait := time.Duration(cfg.WaitSeconds) * time.Second
+	if wait <= 0 {
+		wait = 5 * time.Second
+	}
+	logger.Printf("[INFO] 限价开仓单 %s 已提交 - 方向:%s, 数量:%.8f, 限价:%.2f, 最长等待%s",
+		orderID, side, amount, limit, wait)
+
+	filled, known := bot.awaitLimitOrder(symbol, orderID, wait)
+	if !known {
+		// 成交数量未知：不补单，记录开仓时以交易所持仓为准
+		bot.entryUnfilled = true
+		return orderID, nil
+	}
+	if filled >= 1 {
+		return orderID, nil
+	}
+
+	remaining := amount * (1 - filled)
+	if cfg.Fallback == ExecutionFallbackCancel {
+		if filled > 0 {
+			logger.Printf("[INFO] 限价开仓单 %s 部分成交 %.0f%%，剩余 %.8f 已撤单", orderID, filled*100, remaining)
+			bot.entryUnfilled = true
+			return orderID, nil
+		}
+		bot.linkIntentOrder(orderID)
+		bot.cancelBracketOrders(br)
+		return "", fmt.Errorf("限价单 %s 在%s内未成交，已撤单", orderID, wait)
+	}
+
+	// 剩余数量改为市价单：已部分成交时括号单保留在限价单上，补单不再附带；
+	// 完全未成交时附带的委托已随限价单撤销（或需撤销），换新ID随补单重新提交
+	fallback := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		switch k {
+		case exchange.ParamStopLossPrice, exchange.ParamStopLossClientID, exchange.ParamTakeProfitPrice,
+			exchange.ParamTakeProfitClientID, exchange.ParamTrailingCallbackRatio, exchange.ParamTrailingClientID:
+			continue
+		}
+		fallback[k] = v
+	}
+	if filled == 0 && br != nil {
+		bot.cancelBracketOrders(br)
+		br.renew()
+		br.apply(fallback)
+	}
+	clientOrderID := exchange.NewClientOrderID()
+	if id, _ := params[exchange.ParamClientOrderID].(string); id != "" {
+		clientOrderID = exchange.IntentClientOrderID(id + "|fallback")
+	}
+	fallback[exchange.ParamClientOrderID] = clientOrderID
+	bot.intentOrder("fallback-"+side, clientOrderID)
+
+	logger.Printf("[INFO] 限价开仓单 %s 成交 %.0f%%，剩余 %.8f 改为市价单", orderID, filled*100, remaining)
+	if filled == 0 {
+		bot.linkIntentOrder(orderID)
+		return bot.submitOrder(symbol, side, remaining, fallback)
+	}
+	fallbackID, err := bot.submitOrder(symbol, side, remaining, fallback)
+	if err != nil {
+		logger.Warnf("[WARNING] 剩余数量市价补单失败，仅保留限价单已成交部分: %v", err)
+		return orderID, nil
+	}
+	bot.entryFallbackID = fallbackID
+	return orderID, nil
+}
+
+// awaitLimitOrder 等待限价单成交，超时后撤单，返回成交比例（0~1）
+// 撤单后无法确认订单状态时 known 为 false，调用方不应补单，避免仓位超过预期
+func (bot *TradingBot) awaitLimitOrder(symbol, orderID string, wait time.Duration) (filled float64, known bool) {
+	deadline := time.Now().Add(wait)
+	for {
+		order, err := bot.exchange.FetchOrder(symbol, orderID)
+		if err == nil && order.IsFinal() {
+			return filledRatio(order), true
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(limitPollInterval)
+	}
+
+	if err := bot.exchange.CancelOrder(symbol, orderID); err != nil {
+		logger.Printf("[WARNING] 撤销限价单 %s 失败: %v", orderID, err)
+	}
+	order, err := bot.exchange.FetchOrder(symbol, orderID)
+	if err != nil {
+		logger.Warnf("[WARNING] 撤单后查询限价单 %s 失败，不再补单，请人工检查: %v", orderID, err)
+		return 0, false
+	}
+	if !order.IsFinal() {
+		logger.Warnf("[WARNING] 限价单 %s 撤单后仍为%s状态，不再补单，请人工检查", orderID, order.State)
+		return 0, false
+	}
+	return filledRatio(order), true
+}
+
+// filledRatio 订单成交比例（与交易所数量单位无关）
+func filledRatio(order *models.Order) float64 {
+	if order.Size <= 0 {
+		return 0
+	}
+	return math.Min(order.FilledSize/order.Size, 1)
+}
+
+// cancelBracketOrders 撤销随未成交开仓单提交的交易所端委托
+// 附带在开仓单上的委托通常已随开仓单撤销，撤销失败只记录调试日志
+func (bot *TradingBot) cancelBracketOrders(br *bracket) {
+	if br == nil {
+		return
+	}
+	for _, id := range []string{br.stopLossID, br.takeProfitID, br.trailingID} {
+		if id == "" {
+			continue
+		}
+		if err := bot.exchange.CancelAlgoOrder(br.symbol, id); err != nil {
+			logger.Debugf("[DEBUG] 撤销委托 %s 失败（可能已随开仓单撤销）: %v", id, err)
+		}
+	}
+}
+
+// verifyEntry 查询开仓单成交情况，限价单部分成交后有市价补单时合并两笔成交（数量相加、均价按数量加权）
+func (bot *TradingBot) verifyEntry(symbol, orderID string) *models.Order {
+	order := bot.verifyOrder(symbol, orderID)
+	fallbackID := bot.entryFallbackID
+	bot.entryFallbackID = ""
+	if fallbackID == "" {
+		return order
+	}
+
+	fallback := bot.verifyOrder(symbol, fallbackID)
+	if order == nil {
+		return fallback
+	}
+	if fallback == nil {
+		return order
+	}
+
+	// 补单数量为限价单未成交部分，合并后的委托数量仍为限价单数量
+	merged := *order
+	merged.FilledSize = order.FilledSize + fallback.FilledSize
+	if merged.FilledSize > 0 {
+		merged.AvgPrice = (order.AvgPrice*order.FilledSize + fallback.AvgPrice*fallback.FilledSize) / merged.FilledSize
+	}
+	if fallback.FeeCurrency == order.FeeCurrency || order.FeeCurrency == "" {
+		merged.Fee += fallback.Fee
+		merged.FeeCurrency = fallback.FeeCurrency
+	}
+	merged.State = fallback.State
+	if fallback.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = fallback.UpdatedAt
+	}
+	return &merged
+}
+
+// entrySize 开仓记录的数量：限价开仓单部分成交后撤单或撤单后状态未知时，重新查询持仓并以交易所持仓数量为准；
+// 交易所没有该方向持仓时返回 false，不记录开仓
+func (bot *TradingBot) entrySize(symbol, side string, requested float64, pos *models.Position) (*models.Position, float64, bool) {
+	if !bot.entryUnfilled {
+		return pos, requested, true
+	}
+	bot.entryUnfilled = false
+
+	fetched, err := bot.fetchLeg(symbol, side)
+	if err != nil {
+		logger.Warnf("[WARNING] 重新查询持仓失败，按下单数量记录开仓，请人工检查: %v", err)
+		return pos, requested, true
+	}
+	if fetched == nil {
+		logger.Warnf("[WARNING] 限价开仓单未确认成交且交易所没有%s持仓，不记录开仓", side)
+		bot.abandonEntry("限价开仓单未成交")
+		return nil, 0, false
+	}
+	if fetched.Size != requested {
+		logger.Printf("[INFO] 限价开仓单未按下单数量成交，按交易所持仓 %.8f 记录开仓（下单 %.8f）", fetched.Size, requested)
+	}
+	return fetched, fetched.Size, true
+}
@@ -0,0 +1,178 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/models"
+)
+
+func TestLimitExecution(t *testing.T) {
+	tests := []struct {
+		name       string
+		fill       float64 // 限价单立即成交的比例
+		fallback   string
+		cancelFail bool    // 撤单失败（撤单后订单状态未知）
+		wantErr    bool    // 期望放弃开仓
+		wantFilled float64 // 期望持仓和开仓记录数量占下单数量的比例
+		wantOrders int     // 期望提交的订单数
+	}{
+		{name: "全部成交", fill: 1, wantFilled: 1, wantOrders: 1},
+		{name: "部分成交后市价补单", fill: 0.4, fallback: ExecutionFallbackMarket, wantFilled: 1, wantOrders: 2},
+		{name: "部分成交后撤单", fill: 0.4, fallback: ExecutionFallbackCancel, wantFilled: 0.4, wantOrders: 1},
+		{name: "未成交改为市价单", fill: 0, fallback: ExecutionFallbackMarket, wantFilled: 1, wantOrders: 2},
+		{name: "未成交撤单", fill: 0, fallback: ExecutionFallbackCancel, wantErr: true, wantOrders: 1},
+		{name: "撤单后状态未知不补单", fill: 0.4, fallback: ExecutionFallbackMarket, cancelFail: true, wantFilled: 0.4, wantOrders: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.Execution = config.ExecutionConfig{Mode: ExecutionModeLimit, WaitSeconds: 1, Fallback: tt.fallback}
+			bot, m, symbol := newGateBot(t, cfg)
+			if !bot.limitEntry {
+				t.Fatal("启动时未启用限价开仓")
+			}
+			m.SetLimitFill(tt.fill)
+			if tt.cancelFail {
+				m.SetError("CancelOrder", errors.New("网络超时"))
+			}
+
+			signal := &models.TradeSignal{Signal: "BUY", Confidence: "HIGH"}
+			err := bot.executeTrade(signal, testMarketData(100))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("执行交易错误 = %v, 期望放弃开仓 %v", err, tt.wantErr)
+			}
+
+			orders := m.Orders()
+			if len(orders) != tt.wantOrders {
+				t.Fatalf("订单数 = %d, 期望 %d: %+v", len(orders), tt.wantOrders, orders)
+			}
+			requested := orders[0].Size
+			if orders[0].Type != "limit" {
+				t.Fatalf("开仓单类型 = %s, 期望限价单", orders[0].Type)
+			}
+			if tt.wantOrders == 2 {
+				if fallback := orders[1]; fallback.Type != "market" || !approxEqual(fallback.Size, requested*(1-tt.fill)) {
+					t.Fatalf("补单 = %+v, 期望剩余 %.8f 的市价单", fallback, requested*(1-tt.fill))
+				}
+			}
+
+			pos, _ := m.FetchPosition(symbol)
+			entry := bot.journal.OpenEntry(testPair)
+			if tt.wantFilled == 0 {
+				if pos != nil || entry != nil {
+					t.Fatalf("持仓 = %+v, 开仓记录 = %+v, 期望均无", pos, entry)
+				}
+				return
+			}
+			want := requested * tt.wantFilled
+			if pos == nil || !approxEqual(pos.Size, want) {
+				t.Fatalf("持仓 = %+v, 期望数量 %.8f", pos, want)
+			}
+			if entry == nil || !approxEqual(entry.Size, want) {
+				t.Fatalf("开仓记录 = %+v, 期望数量 %.8f", entry, want)
+			}
+		})
+	}
+}
+
+// 限价单部分成交后有市价补单时合并两笔成交：成交数量相加、均价按数量加权，委托数量仍为限价单数量
+func TestVerifyEntryMerge(t *testing.T) {
+	cfg := newTestConfig(config.TradingModeFutures)
+	m, symbol := newTestExchange(cfg)
+	bot := NewTradingBot(cfg, m, nil)
+	m.SetLimitFill(0.4)
+
+	limitID, err := m.PlaceOrder(symbol, "buy", 1, map[string]interface{}{exchange.ParamLimitPrice: 100.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CancelOrder(symbol, limitID); err != nil {
+		t.Fatal(err)
+	}
+	m.SetPrice(symbol, 110)
+	fallbackID, err := m.PlaceOrder(symbol, "buy", 0.6, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot.entryFallbackID = fallbackID
+	order := bot.verifyEntry(symbol, limitID)
+	if order == nil || !approxEqual(order.Size, 1) || !approxEqual(order.FilledSize, 1) || !approxEqual(order.AvgPrice, 106) {
+		t.Fatalf("合并成交 = %+v, 期望数量 1、成交 1、均价 106", order)
+	}
+	if bot.entryFallbackID != "" {
+		t.Fatal("合并后未清空补单ID")
+	}
+}
+
+// 同一根K线内持仓在外部平掉或放弃开仓后重新开仓，使用新的意图订单ID，不复用已成交或已撤销的旧订单
+func TestReentryWithinCandle(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(cfg *config.Config)
+		first func(t *testing.T, m *exchange.MockExchange, symbol string) // 第一轮后的操作
+	}{
+		{name: "持仓被交易所止损平掉", first: func(t *testing.T, m *exchange.MockExchange, symbol string) {
+			if _, err := m.PlaceOrder(symbol, "sell", 0.5, map[string]interface{}{"reduceOnly": true, "posSide": "long"}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "限价开仓单未成交撤单", setup: func(cfg *config.Config) {
+			cfg.Trading.Execution = config.ExecutionConfig{Mode: ExecutionModeLimit, WaitSeconds: 1, Fallback: ExecutionFallbackCancel}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			if tt.setup != nil {
+				tt.setup(cfg)
+			}
+			m, symbol := newTestExchange(cfg)
+			setTestCandles(cfg, m, symbol, 60, 100)
+			bot := NewTradingBot(cfg, m, &stubProvider{signal: models.TradeSignal{Signal: "BUY", Confidence: "HIGH"}})
+			bot.SetJournal(newTestJournal(t))
+
+			if bot.limitEntry {
+				m.SetLimitFill(0)
+				if err := bot.runCycle(context.Background()); err == nil {
+					t.Fatal("限价单未成交, 期望放弃开仓")
+				}
+				m.SetLimitFill(1)
+			} else {
+				if err := bot.runCycle(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				tt.first(t, m, symbol)
+			}
+			if pos, _ := m.FetchPosition(symbol); pos != nil {
+				t.Fatalf("第一轮后持仓 = %+v, 期望无持仓", pos)
+			}
+
+			if err := bot.runCycle(context.Background()); err != nil {
+				t.Fatalf("重新开仓失败: %v", err)
+			}
+			pos, _ := m.FetchPosition(symbol)
+			if pos == nil || pos.Side != "long" {
+				t.Fatalf("持仓 = %+v, 期望重新开多仓", pos)
+			}
+			ids := make(map[string]bool)
+			for _, order := range m.Orders() {
+				if !order.ReduceOnly && ids[order.ClientOrderID] {
+					t.Fatalf("开仓单复用了自定义订单ID %s", order.ClientOrderID)
+				}
+				ids[order.ClientOrderID] = true
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"sync"
+)
+
+// ExecutionPriority 下单优先级
+type ExecutionPriority int
+
+const (
+	// PriorityRisk 风控平仓（最高优先级）
+	PriorityRisk ExecutionPriority = iota
+	// PriorityStrategy 策略开仓/反手
+	PriorityStrategy
+)
+
+// ExecutionCoordinator 下单协调器 - 保证同一交易对同一时刻只有一个组件在下单，
+// 风控平仓优先于策略开仓；策略在等待期间若发生风控平仓，需要重新校验后再下单
+type ExecutionCoordinator struct {
+	mu                sync.Mutex
+	cond              *sync.Cond
+	busy              bool   // 是否有组件正在下单
+	waitingRisk       int    // 等待中的风控平仓数量
+	riskGeneration    uint64 // 风控平仓计数（每完成一次平仓+1）
+	lastRiskCloseSide string // 最近一次风控平仓的持仓方向
+}
+
+// NewExecutionCoordinator 创建下单协调器
+func NewExecutionCoordinator() *ExecutionCoordinator {
+	c := &ExecutionCoordinator{}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Acquire 获取下单权，返回释放函数
+// 风控平仓只需等待当前下单完成；策略下单还需等待所有排队中的风控平仓
+func (c *ExecutionCoordinator) Acquire(priority ExecutionPriority) func() {
+	c.mu.Lock()
+	if priority == PriorityRisk {
+		c.waitingRisk++
+		for c.busy {
+			c.cond.Wait()
+		}
+		c.waitingRisk--
+	} else {
+		for c.busy || c.waitingRisk > 0 {
+			c.cond.Wait()
+		}
+	}
+	c.busy = true
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			c.busy = false
+			c.mu.Unlock()
+			c.cond.Broadcast()
+		})
+	}
+}
+
+// RiskGeneration 获取当前风控平仓计数（策略生成信号前记录，下单前比较）
+func (c *ExecutionCoordinator) RiskGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.riskGeneration
+}
+
+// RecordRiskClose 记录一次风控平仓
+func (c *ExecutionCoordinator) RecordRiskClose(side string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.riskGeneration++
+	c.lastRiskCloseSide = side
+}
+
+// RiskClosedSince 判断自 generation 之后是否发生过风控平仓，并返回最近平仓的方向
+func (c *ExecutionCoordinator) RiskClosedSince(generation uint64) (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.riskGeneration != generation, c.lastRiskCloseSide
+}
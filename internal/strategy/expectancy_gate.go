@@ -0,0 +1,163 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// passExpectancyGate 按交易日志中相似信号（同方向、同信心、同市场状态）的历史期望值过滤开仓
+func (bot *TradingBot) passExpectancyGate(signal *models.TradeSignal, marketData *models.MarketData) (bool, string) {
+	cfg := bot.config.Trading.ExpectancyGate
+	if !cfg.Enable || bot.journal == nil {
+		return true, ""
+	}
+
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+	side := signalSide(signal.Signal)
+
+	minSamples := cfg.MinSamples
+	if minSamples <= 0 {
+		minSamples = 20
+	}
+
+	filter := journal.Filter{
+		TradingPair: bot.tradingPair,
+		Side:        side,
+		Confidence:  signal.Confidence,
+		Regime:      marketData.TrendAnalysis.Overall,
+	}
+	stats := bot.journal.Stats(filter)
+
+	if stats.Count < minSamples {
+		detail := fmt.Sprintf("%s/%s/%s 样本不足 (%d < %d)", side, filter.Confidence, filter.Regime, stats.Count, minSamples)
+		logger.Printf("[期望值过滤] %s，放行", detail)
+		return true, detail
+	}
+
+	detail := fmt.Sprintf("%s/%s/%s 历史期望 %.2f%% (下限 %.2f%%, 样本:%d, 胜率:%.1f%%)",
+		side, filter.Confidence, filter.Regime, stats.Expectancy, cfg.MinExpectancy, stats.Count, stats.WinRate)
+	if stats.Expectancy < cfg.MinExpectancy {
+		logger.Printf("[期望值过滤] ⚠️ %s，跳过开仓", detail)
+		return false, detail
+	}
+
+	logger.Printf("[期望值过滤] %s，放行", detail)
+	return true, detail
+}
+
+// signalSide 交易信号对应的开仓方向
+func signalSide(signal string) string {
+	switch signal {
+	case "BUY":
+		return "long"
+	case "SELL":
+		return "short"
+	default:
+		return ""
+	}
+}
+
+// opensNewPosition 信号是否会开新仓（含反手后的开仓），供下单前检查判断是否需要检查
+// HOLD、现货卖出和已持有同方向仓位（双向持仓时为该方向的仓位）时不会开仓
+func (bot *TradingBot) opensNewPosition(signal *models.TradeSignal) bool {
+	side := signalSide(signal.Signal)
+	if side == "" || (bot.config.IsSpotMode() && signal.Signal == "SELL") {
+		return false
+	}
+	pos := bot.currentPosition
+	if bot.hedge {
+		pos = bot.positions[side]
+	}
+	return pos == nil || pos.Side != side
+}
+
+// reversesPosition 信号是否需要先平掉反向仓位再开仓（单向持仓反手，或双向持仓 flip 模式下本轮处理的反向仓位）
+func (bot *TradingBot) reversesPosition(signal *models.TradeSignal) bool {
+	side := signalSide(signal.Signal)
+	return side != "" && bot.config.IsFuturesMode() && bot.currentPosition != nil && bot.currentPosition.Side != side
+}
+
+// journalOpen 记录开仓到交易日志（成交价/时间优先取订单，其次取持仓，最后取行情）
+func (bot *TradingBot) journalOpen(side string, signal *models.TradeSignal, marketData *models.MarketData, order *models.Order, pos *models.Position, size float64) {
+	entry := journal.Entry{
+		TradingPair:   bot.tradingPair,
+		Side:          side,
+		Confidence:    signal.Confidence,
+		Regime:        marketData.TrendAnalysis.Overall,
+		Reason:        signal.Reason,
+		EntryPrice:    marketData.Price,
+		Size:          size,
+		DecidedAt:     bot.decidedAt,
+		PromptVariant: signal.PromptVariant,
+	}
+	if pos != nil && pos.EntryPrice > 0 {
+		entry.EntryPrice = pos.EntryPrice
+	}
+	if rm := bot.legRisk(side); rm != nil {
+		if b := rm.currentBracket(); b != nil {
+			entry.StopLossOrderID = b.stopLossID
+			entry.TakeProfitOrderID = b.takeProfitID
+			entry.TrailingOrderID = b.trailingID
+		}
+	}
+	if order != nil {
+		entry.EntryOrderID = order.OrderID
+		if order.AvgPrice > 0 {
+			entry.EntryPrice = order.AvgPrice
+		}
+		if !order.UpdatedAt.IsZero() {
+			entry.OpenedAt = order.UpdatedAt
+		}
+	}
+	entry.EntryFee = feeCost(order, bot.feeRate, entry.EntryPrice, size, bot.config.Trading.SymbolA)
+	bot.recordEvent(TradeEvent{
+		Type: EventPositionOpen, Side: side, Price: entry.EntryPrice, Size: size, OrderID: entry.EntryOrderID,
+		Fee: entry.EntryFee, FeeCurrency: bot.config.Trading.SymbolB, Signal: signal.Signal, Confidence: signal.Confidence, Reason: signal.Reason,
+	})
+	if bot.journal == nil {
+		return
+	}
+
+	// 最后一根为未收盘K线，其开盘时间/开盘价即上一根K线的收盘时间/收盘价
+	if n := len(marketData.KlineData); n > 0 {
+		entry.SignalTime = marketData.KlineData[n-1].Timestamp
+		entry.SignalPrice = marketData.KlineData[n-1].Open
+	}
+
+	bot.journal.Open(entry)
+}
+
+// journalClose 记录平仓到交易日志（启用复盘时随后请求AI撰写复盘）
+// side: 平掉的持仓方向（仅双向持仓时用于查找对应的未平仓条目，为空时不区分方向）
+// order: 平仓订单（为nil时按吃单费率估算手续费）
+func (bot *TradingBot) journalClose(side string, marketData *models.MarketData, exitPrice float64, order *models.Order, reason string) {
+	if order != nil && order.AvgPrice > 0 {
+		exitPrice = order.AvgPrice
+	}
+	if bot.journal == nil {
+		bot.recordEvent(closeEvent(nil, exitPrice, reason))
+		return
+	}
+	side = bot.journalSide(side)
+	entry := bot.journal.OpenLeg(bot.tradingPair, side)
+	if entry == nil {
+		return
+	}
+	fee := feeCost(order, bot.feeRate, exitPrice, entry.Size, bot.config.Trading.SymbolA)
+	closed := bot.journal.CloseLeg(bot.tradingPair, side, exitPrice, fee, reason)
+	bot.recordEvent(closeEvent(closed, exitPrice, reason))
+	logRealizedTotals(bot.journal, bot.calendar, bot.tradingPair, bot.config.Trading.SymbolB)
+	recordVariantTrade(bot.aiClient, closed)
+
+	var exitTrend string
+	if marketData.TrendAnalysis != nil {
+		exitTrend = marketData.TrendAnalysis.Overall
+	}
+	requestPostMortem(context.Background(), bot.config, bot.exchange, bot.aiClient, bot.journal, closed, exitTrend)
+}
@@ -0,0 +1,261 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
+)
+
+// 主备角色
+const (
+	FailoverRolePrimary = "primary" // 主实例：正常分析下单，定期写入心跳
+	FailoverRoleStandby = "standby" // 备用实例：不开仓，主实例失联时接管风控
+)
+
+// failoverHeartbeat 主实例心跳记录
+type failoverHeartbeat struct {
+	Owner   string    `json:"owner"`
+	Time    time.Time `json:"time"`
+	Stopped bool      `json:"stopped"` // 主实例正常退出（备用实例无需等待超时即接管）
+}
+
+// Failover 主备切换 - 主实例定期在共享存储中写入心跳；备用实例监控心跳，
+// 主实例失联超过 timeout 或正常退出后接管风控（止盈止损平仓，不开新仓），主实例恢复心跳后交还
+type Failover struct {
+	store    store.Store
+	key      string
+	owner    string
+	role     string
+	interval time.Duration
+	timeout  time.Duration
+	sameHost bool
+	notifier notify.Publisher
+
+	mu       sync.Mutex
+	active   bool      // 备用实例是否已接管
+	lastBeat time.Time // 最近一次主实例心跳中的时间（主实例时钟）
+	lastSeen time.Time // 最近一次看到心跳更新的时间（本机时钟，避免两台主机时钟偏差）
+	noBeat   bool      // 已提示从未收到主实例心跳
+	onActive []func()  // 接管期间每次检查时调用（如同步持仓给风控）
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewFailover 创建主备切换
+func NewFailover(st store.Store, cfg config.FailoverConfig, owner string) *Failover {
+	role := cfg.Role
+	if role == "" {
+		role = FailoverRolePrimary
+	}
+	group := cfg.Group
+	if group == "" {
+		group = "default"
+	}
+	interval := time.Duration(cfg.HeartbeatSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &Failover{
+		store:    st,
+		key:      "failover_heartbeat_" + group,
+		owner:    owner,
+		role:     role,
+		interval: interval,
+		timeout:  timeout,
+		sameHost: cfg.SameHost,
+	}
+}
+
+// SetNotifier 设置通知发布器（接管和交还时发送通知）
+func (f *Failover) SetNotifier(notifier notify.Publisher) {
+	f.notifier = notifier
+}
+
+// OnActive 注册接管期间的回调（每个检查周期调用一次）
+func (f *Failover) OnActive(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onActive = append(f.onActive, fn)
+}
+
+// IsStandby 是否为备用实例
+func (f *Failover) IsStandby() bool {
+	return f.role == FailoverRoleStandby
+}
+
+// Active 是否执行风控（主实例始终执行，备用实例仅在接管期间执行）
+func (f *Failover) Active() bool {
+	if !f.IsStandby() {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+// Start 启动心跳写入（主实例）或心跳监控（备用实例）
+// 主备实例必须共享同一存储：postgres 可跨主机，sqlite/jsonl 只在同一主机上可见，需显式开启 same_host
+func (f *Failover) Start() error {
+	if backend := f.store.Backend(); backend != store.BackendPostgres && !f.sameHost {
+		return fmt.Errorf("主备切换需要主备实例共享存储，当前 %s 存储仅本机可见: 跨主机部署请使用 postgres，"+
+			"同一主机共享数据目录时请设置 failover.same_host", backend)
+	}
+
+	f.mu.Lock()
+	if f.cancel != nil {
+		f.mu.Unlock()
+		return fmt.Errorf("主备切换已在运行中")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	f.mu.Unlock()
+
+	if f.IsStandby() {
+		logger.Printf("[主备] 本实例 (%s) 为备用实例，主实例失联超过 %s 后接管风控", f.owner, f.timeout)
+	} else {
+		logger.Printf("[主备] 本实例 (%s) 为主实例，每 %s 写入心跳", f.owner, f.interval)
+	}
+	f.tick()
+
+	f.wg.Add(1)
+	go f.loop(ctx)
+	return nil
+}
+
+// Stop 停止心跳；主实例写入退出标记，备用实例立即接管
+func (f *Failover) Stop() {
+	f.mu.Lock()
+	cancel := f.cancel
+	f.cancel = nil
+	f.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	f.wg.Wait()
+
+	if !f.IsStandby() {
+		f.writeHeartbeat(true)
+	}
+}
+
+func (f *Failover) loop(ctx context.Context) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.tick()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tick 主实例写入心跳，备用实例检查主实例心跳
+func (f *Failover) tick() {
+	if !f.IsStandby() {
+		f.writeHeartbeat(false)
+		return
+	}
+
+	alive, detail := f.primaryAlive()
+	f.mu.Lock()
+	changed := alive == f.active
+	f.active = !alive
+	callbacks := append([]func(){}, f.onActive...)
+	f.mu.Unlock()
+
+	if changed {
+		if alive {
+			logger.Printf("[主备] 主实例已恢复 (%s)，交还风控", detail)
+			f.publish(notify.LevelInfo, "主实例已恢复", fmt.Sprintf("备用实例 %s 交还风控: %s", f.owner, detail))
+		} else {
+			logger.Warnf("[主备] ⚠️ 主实例失联 (%s)，备用实例 (%s) 接管风控，不开新仓", detail, f.owner)
+			f.publish(notify.LevelWarning, "备用实例接管风控", fmt.Sprintf("%s，备用实例 %s 接管止盈止损", detail, f.owner))
+		}
+	}
+	if !alive {
+		for _, fn := range callbacks {
+			fn()
+		}
+	}
+}
+
+// primaryAlive 判断主实例是否存活（存储读取失败或从未收到心跳时按存活处理，避免误接管）
+func (f *Failover) primaryAlive() (bool, string) {
+	data, err := f.store.Get(f.key)
+	if err != nil {
+		logger.Warnf("[主备] 读取主实例心跳失败: %v", err)
+		return true, "心跳读取失败"
+	}
+
+	var hb failoverHeartbeat
+	if data != nil {
+		if err := json.Unmarshal(data, &hb); err != nil {
+			logger.Warnf("[主备] 解析主实例心跳失败: %v", err)
+			return true, "心跳解析失败"
+		}
+	}
+
+	f.mu.Lock()
+	if hb.Time.After(f.lastBeat) {
+		f.lastBeat = hb.Time
+		f.lastSeen = time.Now()
+	}
+	lastSeen := f.lastSeen
+	warn := lastSeen.IsZero() && !f.noBeat
+	if warn {
+		f.noBeat = true
+	}
+	f.mu.Unlock()
+
+	// 从未收到心跳：主实例可能尚未启动，也可能与本实例使用了不同的存储或主备组，不接管
+	if lastSeen.IsZero() {
+		if warn {
+			logger.Warnf("[主备] ⚠️ 存储 %s 中没有主实例心跳 (%s)，在收到心跳前不会接管风控，请检查主实例是否启动且共享同一存储和主备组",
+				f.store.Backend(), f.key)
+		}
+		return true, "尚未收到主实例心跳"
+	}
+
+	if hb.Stopped {
+		return false, fmt.Sprintf("主实例 %s 已于 %s 退出", hb.Owner, hb.Time.Format("15:04:05"))
+	}
+	if elapsed := time.Since(lastSeen); elapsed > f.timeout {
+		return false, fmt.Sprintf("主实例 %s 已 %s 无心跳", hb.Owner, elapsed.Round(time.Second))
+	}
+	return true, fmt.Sprintf("主实例 %s 心跳 %s", hb.Owner, hb.Time.Format("15:04:05"))
+}
+
+// writeHeartbeat 写入主实例心跳
+func (f *Failover) writeHeartbeat(stopped bool) {
+	data, err := json.Marshal(failoverHeartbeat{Owner: f.owner, Time: time.Now(), Stopped: stopped})
+	if err != nil {
+		return
+	}
+	if err := f.store.Put(f.key, data); err != nil {
+		logger.Warnf("[主备] 写入心跳失败: %v", err)
+	}
+}
+
+func (f *Failover) publish(level notify.Level, title, message string) {
+	if f.notifier != nil {
+		f.notifier.Publish(level, title, message)
+	}
+}
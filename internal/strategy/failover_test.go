@@ -0,0 +1,87 @@
+package strategy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/store"
+)
+
+func TestFailoverRequiresSharedStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		sameHost bool
+		wantErr  bool
+	}{
+		{name: "主实例未声明同一主机", role: FailoverRolePrimary, wantErr: true},
+		{name: "备用实例未声明同一主机", role: FailoverRoleStandby, wantErr: true},
+		{name: "同一主机共享数据目录", role: FailoverRoleStandby, sameHost: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := store.NewJSONLStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := NewFailover(st, config.FailoverConfig{Role: tt.role, SameHost: tt.sameHost}, "test")
+			err = f.Start()
+			f.Stop()
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "postgres")) {
+				t.Fatalf("启动错误 = %v, 期望提示使用共享存储", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("启动失败: %v", err)
+			}
+		})
+	}
+}
+
+func TestFailoverTakeover(t *testing.T) {
+	st, err := store.NewJSONLStore(filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.FailoverConfig{Group: "test", SameHost: true}
+	primary := NewFailover(st, cfg, "primary")
+	cfg.Role = FailoverRoleStandby
+	standby := NewFailover(st, cfg, "standby")
+	standby.timeout = 50 * time.Millisecond
+	synced := 0
+	standby.OnActive(func() { synced++ })
+
+	// 从未收到心跳：超时后仍不接管
+	standby.tick()
+	time.Sleep(100 * time.Millisecond)
+	standby.tick()
+	if standby.Active() {
+		t.Fatal("从未收到主实例心跳时备用实例接管了风控")
+	}
+
+	steps := []struct {
+		name       string
+		run        func()
+		wantActive bool
+	}{
+		{name: "主实例心跳正常", run: func() { primary.tick() }},
+		{name: "心跳超时后接管", run: func() { time.Sleep(100 * time.Millisecond) }, wantActive: true},
+		{name: "主实例恢复后交还", run: func() { primary.tick() }},
+		{name: "主实例正常退出后立即接管", run: func() { primary.writeHeartbeat(true) }, wantActive: true},
+	}
+	for _, step := range steps {
+		step.run()
+		standby.tick()
+		if standby.Active() != step.wantActive {
+			t.Fatalf("%s: 接管 = %v, 期望 %v", step.name, standby.Active(), step.wantActive)
+		}
+	}
+	if synced != 2 {
+		t.Fatalf("接管期间同步持仓 %d 次, 期望 2 次", synced)
+	}
+	if !primary.Active() {
+		t.Fatal("主实例应始终执行风控")
+	}
+}
@@ -0,0 +1,41 @@
+package strategy
+
+import (
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// feeCost 计算订单手续费（计价币种，正数表示支出）
+// 优先使用订单实际手续费，订单无手续费信息时按吃单费率估算
+// price: 成交价, size: 成交数量（基础币种）, baseCurrency: 基础币种（现货买入手续费以基础币种收取）
+func feeCost(order *models.Order, rate *models.FeeRate, price, size float64, baseCurrency string) float64 {
+	if order != nil && order.Fee != 0 {
+		fee := -order.Fee
+		if order.FeeCurrency == baseCurrency {
+			fee *= price
+		}
+		return fee
+	}
+	if rate != nil {
+		return rate.Taker * price * size
+	}
+	return 0
+}
+
+// loadFeeRate 获取手续费率（失败时记录日志，盈亏统计按实际订单手续费计算）
+func (bot *TradingBot) loadFeeRate() {
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	rate, err := bot.exchange.FetchTradingFees(symbol)
+	if err != nil {
+		logger.Printf("[WARNING] 获取手续费率失败: %v", err)
+		return
+	}
+
+	bot.feeRate = rate
+	for _, rm := range bot.riskManagers() {
+		rm.mu.Lock()
+		rm.feeRate = rate
+		rm.mu.Unlock()
+	}
+	logger.Printf("手续费率: 挂单 %.4f%%, 吃单 %.4f%%", rate.Maker*100, rate.Taker*100)
+}
@@ -0,0 +1,461 @@
+package strategy
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dsbot/internal/calendar"
+	"dsbot/internal/config"
+	"dsbot/internal/embargo"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/models"
+)
+
+func TestOpensNewPosition(t *testing.T) {
+	long := &models.Position{Side: "long", Size: 0.5, EntryPrice: 100}
+	short := &models.Position{Side: "short", Size: 0.5, EntryPrice: 100}
+	tests := []struct {
+		name      string
+		mode      config.TradingMode
+		hedge     bool
+		signal    string
+		current   *models.Position
+		positions map[string]*models.Position
+		want      bool
+	}{
+		{name: "观望", mode: config.TradingModeFutures, signal: "HOLD"},
+		{name: "无持仓开多", mode: config.TradingModeFutures, signal: "BUY", want: true},
+		{name: "持有多仓", mode: config.TradingModeFutures, signal: "BUY", current: long},
+		{name: "持有空仓反手开多", mode: config.TradingModeFutures, signal: "BUY", current: short, want: true},
+		{name: "现货卖出", mode: config.TradingModeSpot, signal: "SELL"},
+		{name: "现货买入", mode: config.TradingModeSpot, signal: "BUY", want: true},
+		// 双向持仓以该方向的仓位为准，与本轮处理的仓位无关
+		{name: "双向持仓已有多仓", mode: config.TradingModeFutures, hedge: true, signal: "BUY", current: short,
+			positions: map[string]*models.Position{"long": long, "short": short}},
+		{name: "双向持仓只有空仓", mode: config.TradingModeFutures, hedge: true, signal: "BUY", current: short,
+			positions: map[string]*models.Position{"short": short}, want: true},
+		{name: "双向持仓只有多仓开空", mode: config.TradingModeFutures, hedge: true, signal: "SELL",
+			positions: map[string]*models.Position{"long": long}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(tt.mode)
+			m, _ := newTestExchange(cfg)
+			bot := NewTradingBot(cfg, m, nil)
+			bot.hedge = tt.hedge
+			bot.currentPosition = tt.current
+			bot.positions = tt.positions
+
+			if got := bot.opensNewPosition(&models.TradeSignal{Signal: tt.signal}); got != tt.want {
+				t.Fatalf("开新仓 = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newGateBot 创建使用模拟交易所和交易日志的机器人，按 held 开仓后同步持仓（双向持仓需 cfg 启用 hedge_mode）
+func newGateBot(t *testing.T, cfg *config.Config, held ...string) (*TradingBot, *exchange.MockExchange, string) {
+	t.Helper()
+	m, symbol := newTestExchange(cfg)
+	m.SetHedgeMode(cfg.Trading.HedgeMode.Enable)
+	for _, side := range held {
+		orderSide := "buy"
+		if side == "short" {
+			orderSide = "sell"
+		}
+		if _, err := m.PlaceOrder(symbol, orderSide, 0.5, map[string]interface{}{"posSide": side}); err != nil {
+			t.Fatalf("开仓失败: %v", err)
+		}
+	}
+
+	bot := NewTradingBot(cfg, m, &stubProvider{})
+	bot.SetJournal(newTestJournal(t))
+	bot.balance = 10000
+	pos, err := bot.fetchPosition(symbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot.currentPosition = pos
+	return bot, m, symbol
+}
+
+// testMarketData 价格为 price 的市场数据
+func testMarketData(price float64) *models.MarketData {
+	return &models.MarketData{Price: price, TrendAnalysis: &models.TrendAnalysis{}}
+}
+
+// heldSides 交易所当前持仓方向（按 long、short 顺序）
+func heldSides(t *testing.T, m *exchange.MockExchange, symbol string) string {
+	t.Helper()
+	list, err := m.FetchPositions(symbol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sides []string
+	for _, side := range positionSides {
+		for _, pos := range list {
+			if pos.Side == side {
+				sides = append(sides, side)
+			}
+		}
+	}
+	return strings.Join(sides, ",")
+}
+
+func TestDailyLossGate(t *testing.T) {
+	tests := []struct {
+		name      string
+		loss      float64 // 当日已实现亏损
+		withRisk  bool    // 启用止损（创建风险管理器）
+		hedge     string  // 双向持仓的反向信号处理方式（为空表示单向持仓）
+		held      []string
+		signal    string
+		wantHeld  string
+		wantGate  bool   // 期望 daily_loss 检查通过
+		wantOrder string // 期望下单的操作（为空表示未下单）
+	}{
+		{name: "未达上限开多", loss: 10, signal: "BUY", wantHeld: "long", wantGate: true, wantOrder: "open-long"},
+		{name: "无风险管理器达到上限", loss: 30, signal: "BUY"},
+		{name: "有风险管理器达到上限", loss: 30, withRisk: true, signal: "BUY"},
+		{name: "持有同方向仓位不检查", loss: 30, held: []string{"long"}, signal: "BUY", wantHeld: "long", wantGate: true},
+		{name: "反手只平仓不开仓", loss: 30, held: []string{"short"}, signal: "BUY", wantOrder: "close-short"},
+		{name: "反手空头只平仓", loss: 30, held: []string{"long"}, signal: "SELL", wantOrder: "close-long"},
+		{name: "双向持仓flip只平反向仓位", loss: 30, hedge: OppositeSignalFlip, held: []string{"short"}, signal: "BUY", wantOrder: "close-short"},
+		{name: "双向持仓hedge保留反向仓位", loss: 30, hedge: OppositeSignalHedge, held: []string{"short"}, signal: "BUY", wantHeld: "short"},
+		{name: "双向持仓已有多仓", loss: 30, hedge: OppositeSignalFlip, held: []string{"long", "short"}, signal: "BUY", wantHeld: "long,short", wantGate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.RiskManagement.DailyLossLimit.MaxLoss = 20
+			cfg.Trading.RiskManagement.EnableStopLoss = tt.withRisk
+			cfg.Trading.HedgeMode.Enable = tt.hedge != ""
+			cfg.Trading.HedgeMode.OppositeSignal = tt.hedge
+			bot, m, symbol := newGateBot(t, cfg, tt.held...)
+			if (bot.riskManager != nil) != tt.withRisk {
+				t.Fatalf("风险管理器 = %v, 期望 %v", bot.riskManager != nil, tt.withRisk)
+			}
+
+			// 当日已平仓一笔亏损 loss 的交易
+			bot.journal.Open(journal.Entry{TradingPair: testPair, Side: "long", EntryPrice: 100, Size: 1})
+			bot.journal.Close(testPair, 100-tt.loss, 0, "测试")
+
+			intents := newMemStore()
+			bot.SetIntentStore(intents)
+			signal := &models.TradeSignal{Signal: tt.signal, Confidence: "HIGH"}
+			if err := bot.executeTrade(signal, testMarketData(100)); err != nil {
+				t.Fatalf("执行交易失败: %v", err)
+			}
+
+			if got := heldSides(t, m, symbol); got != tt.wantHeld {
+				t.Fatalf("持仓 = %q, 期望 %q", got, tt.wantHeld)
+			}
+			intent := lastIntent(t, intents)
+			if gate, ok := intent.gate("daily_loss"); !ok || gate.Passed != tt.wantGate {
+				t.Fatalf("daily_loss 检查 = %+v (%v), 期望通过 %v", gate, ok, tt.wantGate)
+			}
+			if got := intent.actions(); got != tt.wantOrder {
+				t.Fatalf("下单 = %q, 期望 %q (意图: %+v)", got, tt.wantOrder, intent)
+			}
+		})
+	}
+}
+
+func TestSessionGate(t *testing.T) {
+	tests := []struct {
+		name      string
+		blackout  bool // 当前处于禁止开仓时间段
+		hedge     string
+		held      []string
+		signal    string
+		wantHeld  string
+		wantGate  bool
+		wantOrder string
+	}{
+		{name: "时段内开多", signal: "BUY", wantHeld: "long", wantGate: true, wantOrder: "open-long"},
+		{name: "禁止开仓时间段不开仓", blackout: true, signal: "SELL"},
+		{name: "持有同方向仓位", blackout: true, held: []string{"short"}, signal: "SELL", wantHeld: "short", wantGate: true},
+		{name: "反手只平仓不开仓", blackout: true, held: []string{"long"}, signal: "SELL", wantOrder: "close-long"},
+		{name: "双向持仓flip只平反向仓位", blackout: true, hedge: OppositeSignalFlip, held: []string{"long"}, signal: "SELL", wantOrder: "close-long"},
+		{name: "双向持仓hedge保留反向仓位", blackout: true, hedge: OppositeSignalHedge, held: []string{"long"}, signal: "SELL", wantHeld: "long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.HedgeMode.Enable = tt.hedge != ""
+			cfg.Trading.HedgeMode.OppositeSignal = tt.hedge
+			bot, m, symbol := newGateBot(t, cfg, tt.held...)
+
+			sessionCfg := &config.TradingSessionsConfig{Enable: true}
+			if tt.blackout {
+				sessionCfg.Blackouts = []config.BlackoutConfig{{
+					Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour), Reason: "议息会议",
+				}}
+			}
+			sessions, err := calendar.NewSessions(sessionCfg, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+			bot.SetSessions(sessions)
+
+			intents := newMemStore()
+			bot.SetIntentStore(intents)
+			signal := &models.TradeSignal{Signal: tt.signal, Confidence: "HIGH"}
+			if err := bot.executeTrade(signal, testMarketData(100)); err != nil {
+				t.Fatalf("执行交易失败: %v", err)
+			}
+
+			if got := heldSides(t, m, symbol); got != tt.wantHeld {
+				t.Fatalf("持仓 = %q, 期望 %q", got, tt.wantHeld)
+			}
+			intent := lastIntent(t, intents)
+			if gate, ok := intent.gate("session"); !ok || gate.Passed != tt.wantGate {
+				t.Fatalf("session 检查 = %+v (%v), 期望通过 %v", gate, ok, tt.wantGate)
+			}
+			if got := intent.actions(); got != tt.wantOrder {
+				t.Fatalf("下单 = %q, 期望 %q (意图: %+v)", got, tt.wantOrder, intent)
+			}
+		})
+	}
+}
+
+// 已持有同方向仓位时只同步持仓给风控，意图记录为跳过而不再写入已提交
+func TestHoldPosition(t *testing.T) {
+	for _, side := range positionSides {
+		t.Run(side, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.RiskManagement.EnableStopLoss = true
+			bot, m, symbol := newGateBot(t, cfg, side)
+			intents := newMemStore()
+			bot.SetIntentStore(intents)
+
+			signal := &models.TradeSignal{Signal: "BUY", Confidence: "HIGH"}
+			if side == "short" {
+				signal.Signal = "SELL"
+			}
+			if err := bot.executeTrade(signal, testMarketData(100)); err != nil {
+				t.Fatalf("执行交易失败: %v", err)
+			}
+
+			var statuses []string
+			intents.Scan(IntentCollection, func(record []byte) error {
+				var intent TradeIntent
+				if err := json.Unmarshal(record, &intent); err != nil {
+					return err
+				}
+				statuses = append(statuses, intent.Status)
+				return nil
+			})
+			if got := strings.Join(statuses, ","); got != IntentStatusSkipped {
+				t.Fatalf("意图状态 = %q, 期望只记录 %q", got, IntentStatusSkipped)
+			}
+			if intent := lastIntent(t, intents); intent.SkipReason != "已持有同方向仓位" || len(intent.Orders) != 0 {
+				t.Fatalf("意图 = %+v, 期望因已持仓跳过且未下单", intent)
+			}
+			if got := heldSides(t, m, symbol); got != side {
+				t.Fatalf("持仓 = %q, 期望 %q", got, side)
+			}
+			bot.riskManager.mu.Lock()
+			pos := bot.riskManager.currentPosition
+			bot.riskManager.mu.Unlock()
+			if pos == nil || pos.Side != side {
+				t.Fatalf("风控持仓 = %+v, 期望同步 %s 仓位", pos, side)
+			}
+		})
+	}
+}
+
+// addClosedTrade 交易日志记录一笔 100 开仓、exit 平仓的已平仓交易
+func addClosedTrade(j *journal.Journal, side, confidence string, exit float64) {
+	j.Open(journal.Entry{TradingPair: testPair, Side: side, Confidence: confidence, EntryPrice: 100, Size: 1})
+	j.CloseLeg(testPair, side, exit, 0, "测试")
+}
+
+// testKlines 以 price 收盘、每小时成交 volume 的 K 线（最后一根为未收盘K线）
+func testKlines(price, volume float64) []models.OHLCV {
+	start := time.Now().Add(-25 * time.Hour).Truncate(time.Hour)
+	klines := make([]models.OHLCV, 26)
+	for i := range klines {
+		klines[i] = models.OHLCV{Timestamp: start.Add(time.Duration(i) * time.Hour),
+			Open: price, High: price, Low: price, Close: price, Volume: volume}
+	}
+	return klines
+}
+
+// 信号确认后的各项下单前检查：未启用、通过、拦截，以及持有同方向仓位（含双向持仓）时不检查
+func TestEntryGates(t *testing.T) {
+	tests := []struct {
+		name   string
+		gate   string
+		hedge  string
+		held   []string
+		signal string
+		setup  func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData)
+		want   bool
+	}{
+		{name: "未禁止交易", gate: "embargo", signal: "BUY", want: true},
+		{name: "交易对在黑名单中", gate: "embargo", signal: "BUY",
+			setup: func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+				list, err := embargo.NewList([]string{"BTC"}, filepath.Join(t.TempDir(), "embargo.json"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				bot.SetEmbargo(list)
+			}},
+
+		{name: "盈亏比达标", gate: "risk_reward", signal: "BUY", want: true,
+			setup: riskRewardSetup(95, 110)},
+		{name: "盈亏比不足", gate: "risk_reward", signal: "BUY",
+			setup: riskRewardSetup(95, 105)},
+		{name: "盈亏比不足但已持有同方向仓位", gate: "risk_reward", held: []string{"long"}, signal: "BUY", want: true,
+			setup: riskRewardSetup(95, 105)},
+		{name: "空头盈亏比不足反手", gate: "risk_reward", held: []string{"long"}, signal: "SELL",
+			setup: riskRewardSetup(105, 95)},
+
+		{name: "波动率在范围内", gate: "volatility", signal: "BUY", want: true, setup: volatilitySetup(1)},
+		{name: "波动率过低", gate: "volatility", signal: "BUY", setup: volatilitySetup(0.1)},
+		{name: "波动率过高", gate: "volatility", signal: "SELL", setup: volatilitySetup(5)},
+		{name: "双向持仓已有多仓", gate: "volatility", hedge: OppositeSignalFlip, held: []string{"long", "short"}, signal: "BUY", want: true,
+			setup: volatilitySetup(5)},
+		{name: "双向持仓hedge开反向仓位", gate: "volatility", hedge: OppositeSignalHedge, held: []string{"long"}, signal: "SELL",
+			setup: volatilitySetup(5)},
+
+		{name: "相似信号期望值为负", gate: "expectancy", signal: "BUY",
+			setup: expectancySetup},
+		{name: "其他方向样本不足", gate: "expectancy", signal: "SELL", want: true,
+			setup: expectancySetup},
+
+		{name: "连续亏损冷却中", gate: "loss_cooldown", signal: "BUY",
+			setup: lossCooldownSetup(2, "")},
+		{name: "亏损笔数未达到", gate: "loss_cooldown", signal: "BUY", want: true,
+			setup: lossCooldownSetup(1, "")},
+		{name: "冷却期内高信心信号放行", gate: "loss_cooldown", signal: "BUY", want: true,
+			setup: lossCooldownSetup(2, "high_confidence")},
+		{name: "冷却期内已持有同方向仓位", gate: "loss_cooldown", held: []string{"long"}, signal: "BUY", want: true,
+			setup: lossCooldownSetup(2, "")},
+		{name: "冷却期内反手", gate: "loss_cooldown", held: []string{"short"}, signal: "BUY",
+			setup: lossCooldownSetup(2, "")},
+
+		{name: "保证金率正常", gate: "margin_ratio", signal: "BUY", want: true, setup: marginRatioSetup(100)},
+		{name: "保证金率过高", gate: "margin_ratio", signal: "BUY", setup: marginRatioSetup(600)},
+
+		{name: "凯利比例为正", gate: "sizing", signal: "BUY", want: true, setup: kellySetup(104, 104, 99)},
+		{name: "凯利比例为负", gate: "sizing", signal: "BUY", setup: kellySetup(101, 98, 98)},
+
+		{name: "全局持仓数未超限", gate: "portfolio", signal: "BUY", want: true, setup: portfolioSetup(false)},
+		{name: "全局持仓数超限", gate: "portfolio", signal: "BUY", setup: portfolioSetup(true)},
+		{name: "双向持仓另一方向计为新持仓", gate: "portfolio", hedge: OppositeSignalHedge, held: []string{"long"}, signal: "SELL",
+			setup: portfolioSetup(false)},
+		{name: "双向持仓加仓同方向", gate: "portfolio", hedge: OppositeSignalHedge, held: []string{"long"}, signal: "BUY", want: true,
+			setup: portfolioSetup(false)},
+
+		{name: "成交额充足", gate: "liquidity", signal: "BUY", want: true, setup: liquiditySetup(1e6)},
+		{name: "成交额过低", gate: "liquidity", signal: "BUY", setup: liquiditySetup(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gate+"/"+tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.HedgeMode.Enable = tt.hedge != ""
+			cfg.Trading.HedgeMode.OppositeSignal = tt.hedge
+			bot, _, _ := newGateBot(t, cfg, tt.held...)
+			signal := &models.TradeSignal{Signal: tt.signal, Confidence: "HIGH"}
+			if bot.hedge {
+				bot.currentPosition = bot.positionForSignal(signal)
+			}
+			md := testMarketData(100)
+			if tt.setup != nil {
+				tt.setup(t, bot, signal, md)
+			}
+
+			var gate *entryGate
+			for i := range entryGates {
+				if entryGates[i].name == tt.gate {
+					gate = &entryGates[i]
+				}
+			}
+			if gate == nil {
+				t.Fatalf("未找到检查 %s", tt.gate)
+			}
+			if passed, detail := gate.check(bot, signal, md); passed != tt.want {
+				t.Fatalf("%s 检查 = %v (%s), 期望 %v", tt.gate, passed, detail, tt.want)
+			}
+		})
+	}
+}
+
+func riskRewardSetup(stopLoss, takeProfit float64) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.RiskManagement.MinRiskReward = 1.5
+		signal.StopLoss, signal.TakeProfit = stopLoss, takeProfit
+	}
+}
+
+func volatilitySetup(atr float64) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.VolatilityFilter = config.VolatilityFilterConfig{Enable: true, MinATRPercent: 0.5, MaxATRPercent: 3}
+		md.TechnicalData = &models.TechnicalData{ATRPercent: atr}
+	}
+}
+
+func expectancySetup(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+	bot.config.Trading.ExpectancyGate = config.ExpectancyGateConfig{Enable: true, MinSamples: 2}
+	addClosedTrade(bot.journal, "long", "HIGH", 98)
+	addClosedTrade(bot.journal, "long", "HIGH", 99)
+}
+
+func lossCooldownSetup(losses int, mode string) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.LossCooldown = config.LossCooldownConfig{Enable: true, ConsecutiveLosses: 2, CooldownHours: 1, Mode: mode}
+		addClosedTrade(bot.journal, "long", "HIGH", 101)
+		for i := 0; i < losses; i++ {
+			addClosedTrade(bot.journal, "long", "HIGH", 98)
+		}
+		if mode == "" {
+			signal.Confidence = "MEDIUM"
+		}
+	}
+}
+
+func marginRatioSetup(maintenance float64) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.RiskManagement.MaxMarginRatio = 50
+		bot.account = &models.AccountSummary{TotalEquity: 1000, MaintenanceMargin: maintenance}
+	}
+}
+
+func kellySetup(exits ...float64) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.PositionSizing = config.PositionSizingConfig{Mode: "kelly", MinTrades: len(exits)}
+		bot.account = &models.AccountSummary{TotalEquity: 1000}
+		for _, exit := range exits {
+			addClosedTrade(bot.journal, "long", "HIGH", exit)
+		}
+	}
+}
+
+// portfolioSetup 全局最多1个持仓，full 时其他交易对已占用
+func portfolioSetup(full bool) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.RiskManagement.MaxOpenPositions = 1
+		bot.SetPortfolioLimits(NewPortfolioLimits(bot.config.Trading.RiskManagement))
+		bot.updatePortfolio(md.Price)
+		if full {
+			bot.portfolio.Update("ETH-USDT", &models.Position{Side: "long", Size: 1}, 100)
+		}
+	}
+}
+
+func liquiditySetup(volume float64) func(*testing.T, *TradingBot, *models.TradeSignal, *models.MarketData) {
+	return func(t *testing.T, bot *TradingBot, signal *models.TradeSignal, md *models.MarketData) {
+		bot.config.Trading.LiquidityGate = config.LiquidityGateConfig{Enable: true, MinVolume24h: 1e6, MaxSpreadBps: 10}
+		md.KlineData = testKlines(md.Price, volume)
+	}
+}
@@ -0,0 +1,149 @@
+package strategy
+
+import (
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 双向持仓下持有反向仓位时的开仓信号处理方式
+const (
+	OppositeSignalFlip  = "flip"  // 平掉反向仓位后开仓（默认，与单向持仓相同）
+	OppositeSignalHedge = "hedge" // 保留反向仓位，同时开仓
+)
+
+// positionSides 双向持仓的持仓方向
+var positionSides = []string{"long", "short"}
+
+// hedgeSupported 是否按双向持仓交易（已启用且交易所支持，不支持时记录警告并按单向持仓交易）
+func hedgeSupported(cfg *config.Config, exch exchange.Exchange) bool {
+	if !cfg.Trading.HedgeMode.Enable || !cfg.IsFuturesMode() {
+		return false
+	}
+	if !exch.Capabilities().HedgeMode {
+		logger.Warnf("[双向持仓] ⚠️ 交易所 %s 不支持双向持仓，按单向持仓交易", exch.GetExchangeName())
+		return false
+	}
+	return true
+}
+
+// oppositeSignal 持有反向仓位时的开仓信号处理方式
+func (bot *TradingBot) oppositeSignal() string {
+	if bot.config.Trading.HedgeMode.OppositeSignal == OppositeSignalHedge {
+		return OppositeSignalHedge
+	}
+	return OppositeSignalFlip
+}
+
+// legRisk 持仓方向对应的风险管理器（单向持仓时多空共用 riskManager，未创建时为nil）
+func (bot *TradingBot) legRisk(side string) *RiskManager {
+	if bot.legs != nil {
+		return bot.legs[side]
+	}
+	return bot.riskManager
+}
+
+// riskManagers 全部风险管理器（双向持仓时多空各一个）
+func (bot *TradingBot) riskManagers() []*RiskManager {
+	if bot.legs != nil {
+		return []*RiskManager{bot.legs["long"], bot.legs["short"]}
+	}
+	if bot.riskManager != nil {
+		return []*RiskManager{bot.riskManager}
+	}
+	return nil
+}
+
+// legKey 持仓在全局风险限制中的键（双向持仓时多空分别计为一个持仓）
+func (bot *TradingBot) legKey(side string) string {
+	if bot.hedge {
+		return bot.tradingPair + ":" + side
+	}
+	return bot.tradingPair
+}
+
+// legKey 风险管理器负责的持仓键（双向持仓时为 交易对:方向）
+func (rm *RiskManager) legKey() string {
+	if rm.leg != "" {
+		return rm.tradingPair + ":" + rm.leg
+	}
+	return rm.tradingPair
+}
+
+// journalSide 交易日志按方向查找未平仓条目（仅双向持仓区分，单向持仓为空）
+func (bot *TradingBot) journalSide(side string) string {
+	if bot.hedge {
+		return side
+	}
+	return ""
+}
+
+// fetchPosition 获取当前持仓；双向持仓时记录多空各方向的持仓，返回名义价值较大的一个（供AI分析参考）
+func (bot *TradingBot) fetchPosition(symbol string) (*models.Position, error) {
+	if !bot.hedge {
+		return bot.exchange.FetchPosition(symbol)
+	}
+	list, err := bot.exchange.FetchPositions(symbol)
+	if err != nil {
+		return nil, err
+	}
+	bot.positions = make(map[string]*models.Position, len(list))
+	var primary *models.Position
+	for i := range list {
+		pos := &list[i]
+		bot.positions[pos.Side] = pos
+		if primary == nil || pos.Size*pos.EntryPrice > primary.Size*primary.EntryPrice {
+			primary = pos
+		}
+	}
+	return primary, nil
+}
+
+// fetchLeg 获取指定方向的持仓（单向持仓时为当前持仓）
+func (bot *TradingBot) fetchLeg(symbol, side string) (*models.Position, error) {
+	pos, err := bot.fetchPosition(symbol)
+	if err != nil || !bot.hedge {
+		return pos, err
+	}
+	return bot.positions[side], nil
+}
+
+// positionForSignal 双向持仓时按信号方向选择本次要处理的仓位：
+// 已有同方向仓位时返回该仓位（保持现状）；否则 flip 返回反向仓位（平仓后开仓），hedge 返回nil（保留反向仓位直接开仓）
+func (bot *TradingBot) positionForSignal(signal *models.TradeSignal) *models.Position {
+	side := signalSide(signal.Signal)
+	if side == "" {
+		return bot.currentPosition
+	}
+	if pos := bot.positions[side]; pos != nil {
+		return pos
+	}
+	if bot.oppositeSignal() == OppositeSignalHedge {
+		return nil
+	}
+	opposite := "short"
+	if side == "short" {
+		opposite = "long"
+	}
+	return bot.positions[opposite]
+}
+
+// syncLegs 将双向持仓的多空仓位分别同步给对应方向的风险管理器，已不存在的方向撤销剩余的交易所端委托
+func (bot *TradingBot) syncLegs() {
+	for _, side := range positionSides {
+		pos := bot.positions[side]
+		if pos != nil {
+			logger.Debugf("[DEBUG] 双向持仓 %s - 数量:%.8f, 开仓价:%.2f, 未实现盈亏:%.2f",
+				side, pos.Size, pos.EntryPrice, pos.UnrealizedPnL)
+		}
+		rm := bot.legRisk(side)
+		if rm == nil {
+			continue
+		}
+		rm.UpdatePosition(pos)
+		if pos == nil {
+			rm.cancelBracket("持仓已不存在")
+		}
+	}
+}
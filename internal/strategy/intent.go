@@ -0,0 +1,174 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/tracing"
+)
+
+// IntentCollection 下单意图记录在持久化存储中的集合名
+const IntentCollection = "trade_intents"
+
+// 下单意图状态
+const (
+	IntentStatusSkipped   = "skipped"   // 未通过检查，未下单
+	IntentStatusSubmitted = "submitted" // 全部检查通过，即将下单
+	IntentStatusPlaced    = "placed"    // 下单完成
+	IntentStatusFailed    = "failed"    // 下单失败
+)
+
+// IntentGate 单项下单前检查结果
+type IntentGate struct {
+	Name   string `json:"name"`             // 检查项（如 confidence, embargo, balance）
+	Passed bool   `json:"passed"`           // 是否通过
+	Detail string `json:"detail,omitempty"` // 检查详情
+}
+
+// IntentOrder 意图关联的订单
+type IntentOrder struct {
+	Action        string `json:"action"`                    // 操作（如 open-long, close-short）
+	ClientOrderID string `json:"client_order_id,omitempty"` // 自定义订单ID
+	OrderID       string `json:"order_id,omitempty"`        // 交易所订单ID（下单成功后填写）
+}
+
+// TradeIntent 下单意图记录 - 记录一次交易信号经过的全部下单前检查及结果，
+// 下单前写入一次（submitted），完成后以相同ID再写入一次最终状态，读取时以最后一条为准
+type TradeIntent struct {
+	ID          string        `json:"id"`
+	TradingPair string        `json:"trading_pair"`
+	Signal      string        `json:"signal"`
+	Confidence  string        `json:"confidence"`
+	Price       float64       `json:"price"`
+	Status      string        `json:"status"`
+	Gates       []IntentGate  `json:"gates"`
+	Orders      []IntentOrder `json:"orders,omitempty"`
+	SkipReason  string        `json:"skip_reason,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// beginIntent 为本轮信号创建下单意图记录
+func (bot *TradingBot) beginIntent(signal *models.TradeSignal, marketData *models.MarketData) {
+	now := time.Now()
+	bot.intent = &TradeIntent{
+		ID:          fmt.Sprintf("%s-%d", bot.tradingPair, now.UnixNano()),
+		TradingPair: bot.tradingPair,
+		Signal:      signal.Signal,
+		Confidence:  signal.Confidence,
+		Price:       marketData.Price,
+		CreatedAt:   now,
+	}
+	bot.gateSpan = tracing.StartSpan("gates", bot.span)
+}
+
+// checkGate 记录一项下单前检查，返回 passed
+func (bot *TradingBot) checkGate(name string, passed bool, detail string) bool {
+	if bot.intent != nil {
+		bot.intent.Gates = append(bot.intent.Gates, IntentGate{Name: name, Passed: passed, Detail: detail})
+	}
+	bot.gateSpan.AddEvent("gate", "name", name, "passed", passed, "detail", detail)
+	return passed
+}
+
+// skipIntent 以跳过原因结束下单意图
+func (bot *TradingBot) skipIntent(reason string) {
+	bot.releasePortfolio(false)
+	bot.gateSpan.SetAttribute("skip_reason", reason)
+	bot.endGateSpan()
+	if bot.intent == nil {
+		return
+	}
+	bot.intent.SkipReason = reason
+	bot.saveIntent(IntentStatusSkipped)
+	bot.intent = nil
+}
+
+// submitIntent 全部检查通过，下单前写入意图记录
+func (bot *TradingBot) submitIntent() {
+	bot.endGateSpan()
+	if bot.intent == nil {
+		return
+	}
+	bot.saveIntent(IntentStatusSubmitted)
+}
+
+// finishIntent 下单完成后写入最终状态（err 非nil 表示下单失败）
+func (bot *TradingBot) finishIntent(err error) {
+	bot.releasePortfolio(err == nil)
+	if err != nil {
+		bot.abandonEntry("下单失败")
+	}
+	bot.endGateSpan()
+	if bot.intent == nil {
+		return
+	}
+	status := IntentStatusPlaced
+	if err != nil {
+		status = IntentStatusFailed
+		bot.intent.Error = err.Error()
+	}
+	bot.saveIntent(status)
+	bot.intent = nil
+}
+
+// endGateSpan 结束下单前检查的 span（全部检查通过或被跳过时调用）
+func (bot *TradingBot) endGateSpan() {
+	bot.gateSpan.End()
+	bot.gateSpan = nil
+}
+
+// intentOrder 记录意图将要提交的订单
+func (bot *TradingBot) intentOrder(action, clientOrderID string) {
+	if bot.intent != nil {
+		bot.intent.Orders = append(bot.intent.Orders, IntentOrder{Action: action, ClientOrderID: clientOrderID})
+	}
+}
+
+// linkIntentOrder 将交易所订单ID关联到最近一笔尚未关联的意图订单（订单按提交顺序依次下单）
+func (bot *TradingBot) linkIntentOrder(orderID string) {
+	if bot.intent == nil {
+		return
+	}
+	for i := range bot.intent.Orders {
+		if bot.intent.Orders[i].OrderID == "" {
+			bot.intent.Orders[i].OrderID = orderID
+			return
+		}
+	}
+}
+
+// saveIntent 写入意图记录（写入存储失败不影响交易流程）
+func (bot *TradingBot) saveIntent(status string) {
+	intent := bot.intent
+	intent.Status = status
+	intent.UpdatedAt = time.Now()
+
+	gates := make([]string, 0, len(intent.Gates))
+	for _, g := range intent.Gates {
+		mark := "✓"
+		if !g.Passed {
+			mark = "✗"
+		}
+		gates = append(gates, mark+g.Name)
+	}
+	logger.Debugf("[下单意图] %s %s/%s 状态:%s 检查:[%s] %s",
+		intent.ID, intent.Signal, intent.Confidence, status, strings.Join(gates, " "), intent.SkipReason)
+
+	if bot.intentStore == nil {
+		return
+	}
+	record, err := json.Marshal(intent)
+	if err != nil {
+		logger.Warnf("[下单意图] 序列化失败: %v", err)
+		return
+	}
+	if err := bot.intentStore.Append(IntentCollection, record); err != nil {
+		logger.Warnf("[下单意图] 写入存储失败: %v", err)
+	}
+}
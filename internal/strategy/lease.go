@@ -0,0 +1,155 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/store"
+)
+
+// PairLease 交易对租约 - 多个工作进程共享同一持久化存储时，
+// 只有持有交易对租约的进程执行分析下单和风控，其余进程待命并在租约过期后接手
+type PairLease struct {
+	leaser store.Leaser
+	name   string
+	owner  string
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	held   bool
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPairLease 创建交易对租约
+func NewPairLease(leaser store.Leaser, tradingPair, owner string, ttl time.Duration) *PairLease {
+	return &PairLease{
+		leaser: leaser,
+		name:   leaseName(tradingPair),
+		owner:  owner,
+		ttl:    ttl,
+	}
+}
+
+// Start 立即尝试获取租约，并在后台按 ttl/3 续期或重新争取
+func (l *PairLease) Start() error {
+	l.mu.Lock()
+	if l.cancel != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("租约续期已在运行中")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.mu.Unlock()
+
+	l.renew()
+	if !l.Held() {
+		logger.Printf("[租约] %s 由其他进程持有，本进程 (%s) 待命", l.name, l.owner)
+	}
+
+	l.wg.Add(1)
+	go l.loop(ctx)
+	return nil
+}
+
+// Stop 停止续期并释放租约（其他进程无需等待过期即可接手）
+func (l *PairLease) Stop() {
+	l.mu.Lock()
+	cancel := l.cancel
+	l.cancel = nil
+	held := l.held
+	l.held = false
+	l.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	l.wg.Wait()
+
+	if held {
+		if err := l.leaser.ReleaseLease(l.name, l.owner); err != nil {
+			logger.Warnf("[租约] 释放 %s 失败: %v", l.name, err)
+		}
+	}
+}
+
+// Held 当前进程是否持有租约
+func (l *PairLease) Held() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held
+}
+
+// Owner 当前进程的工作进程标识
+func (l *PairLease) Owner() string {
+	return l.owner
+}
+
+func (l *PairLease) loop(ctx context.Context) {
+	defer l.wg.Done()
+
+	interval := l.ttl / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.renew()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// renew 获取或续期租约，并记录持有状态变化
+func (l *PairLease) renew() {
+	acquired, err := l.leaser.AcquireLease(l.name, l.owner, l.ttl)
+	if err != nil {
+		// 存储故障时无法确认租约，按未持有处理，避免与其他进程重复交易
+		logger.Warnf("[租约] 续期 %s 失败: %v", l.name, err)
+		acquired = false
+	}
+
+	l.mu.Lock()
+	changed := acquired != l.held
+	l.held = acquired
+	l.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if acquired {
+		logger.Printf("[租约] %s 已由本进程 (%s) 持有，开始处理该交易对", l.name, l.owner)
+	} else {
+		logger.Warnf("[租约] %s 未持有，本进程 (%s) 待命", l.name, l.owner)
+	}
+}
+
+// ClaimPair 按顺序尝试认领交易对池中第一个空闲的交易对，全部被占用时返回空字符串
+func ClaimPair(leaser store.Leaser, pairs []string, owner string, ttl time.Duration) (string, error) {
+	var lastErr error
+	for _, pair := range pairs {
+		acquired, err := leaser.AcquireLease(leaseName(pair), owner, ttl)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if acquired {
+			return pair, nil
+		}
+	}
+	return "", lastErr
+}
+
+// leaseName 交易对租约名
+func leaseName(tradingPair string) string {
+	return "pair_" + tradingPair
+}
@@ -0,0 +1,112 @@
+package strategy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// passLiquidityGate 开仓前检查交易对流动性（24小时成交额、买卖价差、按下单数量吃单的滑点和盘口深度）
+// 返回是否放行及检查详情；行情获取失败时放行，平仓不受影响
+func (bot *TradingBot) passLiquidityGate(signal *models.TradeSignal, marketData *models.MarketData) (bool, string) {
+	cfg := bot.config.Trading.LiquidityGate
+	if !cfg.Enable {
+		return true, ""
+	}
+
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	var details, failures []string
+
+	if cfg.MinVolume24h > 0 {
+		volume, err := quoteVolume24h(marketData.KlineData)
+		if err != nil {
+			logger.Printf("[流动性检查] 获取24小时成交额失败: %v，跳过该项检查", err)
+		} else {
+			detail := fmt.Sprintf("24h成交额 %.0f %s", volume, bot.config.Trading.SymbolB)
+			details = append(details, detail)
+			if volume < cfg.MinVolume24h {
+				failures = append(failures, fmt.Sprintf("%s < %.0f", detail, cfg.MinVolume24h))
+			}
+		}
+	}
+
+	if cfg.MaxSpreadBps > 0 || cfg.MaxSlippageBps > 0 {
+		book, err := bot.exchange.FetchOrderBook(symbol, orderBookDepth)
+		if err != nil {
+			logger.Printf("[流动性检查] 获取盘口深度失败: %v，跳过盘口检查", err)
+		} else if book.MidPrice() == 0 {
+			failures = append(failures, "盘口为空")
+		} else {
+			spread := book.SpreadBps()
+			details = append(details, fmt.Sprintf("价差 %.2f bps", spread))
+			if cfg.MaxSpreadBps > 0 && spread > cfg.MaxSpreadBps {
+				failures = append(failures, fmt.Sprintf("价差 %.2f bps > %.2f bps", spread, cfg.MaxSpreadBps))
+			}
+
+			if cfg.MaxSlippageBps > 0 && marketData.Price > 0 {
+				amountInBase := bot.orderAmount() / marketData.Price
+				bookSide := "buy"
+				if signal.Signal == "SELL" {
+					bookSide = "sell"
+				}
+				mid := book.MidPrice()
+				avgPrice, filled := book.EstimateFill(bookSide, amountInBase)
+				slippage := (avgPrice - mid) / mid * 10000
+				if bookSide == "sell" {
+					slippage = -slippage
+				}
+				switch {
+				case filled < amountInBase:
+					failures = append(failures, fmt.Sprintf("盘口深度不足 (前%d档可成交 %.8f / %.8f %s)",
+						orderBookDepth, filled, amountInBase, bot.config.Trading.SymbolA))
+				case slippage > cfg.MaxSlippageBps:
+					failures = append(failures, fmt.Sprintf("预计滑点 %.2f bps > %.2f bps", slippage, cfg.MaxSlippageBps))
+				default:
+					details = append(details, fmt.Sprintf("预计滑点 %.2f bps", slippage))
+				}
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		detail := strings.Join(failures, ", ")
+		logger.Warnf("[流动性检查] ⚠️ %s 流动性不足: %s，跳过开仓", bot.tradingPair, detail)
+		return false, detail
+	}
+	detail := strings.Join(details, ", ")
+	logger.Printf("[流动性检查] %s 通过 (%s)", bot.tradingPair, detail)
+	return true, detail
+}
+
+// quoteVolume24h 按本轮K线估算最近24小时成交额（计价币种）
+// 最后一根K线尚未收盘，不参与统计；K线覆盖不足24小时时按已覆盖时长等比例折算
+func quoteVolume24h(klines []models.OHLCV) (float64, error) {
+	n := len(klines)
+	if n < 2 {
+		return 0, fmt.Errorf("K线数据不足")
+	}
+	step := klines[n-1].Timestamp.Sub(klines[n-2].Timestamp)
+	if step <= 0 {
+		return 0, fmt.Errorf("K线间隔异常")
+	}
+
+	since := klines[n-1].Timestamp.Add(-24 * time.Hour)
+	var volume float64
+	var covered time.Duration
+	for i := n - 2; i >= 0 && !klines[i].Timestamp.Before(since); i-- {
+		k := klines[i]
+		volume += k.Volume * (k.High + k.Low + k.Close) / 3
+		covered += step
+	}
+	if covered < 24*time.Hour {
+		volume *= float64(24*time.Hour) / float64(covered)
+	}
+	return volume, nil
+}
@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"fmt"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 连续亏损冷却默认配置
+const (
+	defaultCooldownLosses = 3
+	defaultCooldownHours  = 12.0
+)
+
+// lossStreak 交易对最近连续亏损的交易笔数及最后一笔的平仓时间（按扣除手续费后的净盈亏判断）
+func (bot *TradingBot) lossStreak() (int, time.Time) {
+	entries := bot.journal.Entries()
+	var streak int
+	var lastLoss time.Time
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !e.Closed || e.TradingPair != bot.tradingPair {
+			continue
+		}
+		if e.NetPnL >= 0 {
+			break
+		}
+		if streak == 0 {
+			lastLoss = e.ClosedAt
+		}
+		streak++
+	}
+	return streak, lastLoss
+}
+
+// passLossCooldownGate 连续亏损达到 consecutive_losses 笔后，最后一笔亏损平仓起 cooldown_hours 小时内不开仓（high_confidence 模式下只执行高信心信号）
+func (bot *TradingBot) passLossCooldownGate(signal *models.TradeSignal) (bool, string) {
+	cfg := bot.config.Trading.LossCooldown
+	if !cfg.Enable || bot.journal == nil {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	minLosses := cfg.ConsecutiveLosses
+	if minLosses <= 0 {
+		minLosses = defaultCooldownLosses
+	}
+	hours := cfg.CooldownHours
+	if hours <= 0 {
+		hours = defaultCooldownHours
+	}
+
+	streak, lastLoss := bot.lossStreak()
+	if streak < minLosses {
+		return true, ""
+	}
+	until := lastLoss.Add(time.Duration(hours * float64(time.Hour)))
+	if !time.Now().Before(until) {
+		return true, ""
+	}
+
+	detail := fmt.Sprintf("连续亏损 %d 笔，冷却至 %s", streak, until.Format("2006-01-02 15:04"))
+	if cfg.Mode == "high_confidence" {
+		if signal.Confidence == "HIGH" {
+			logger.Printf("[亏损冷却] %s，高信心信号放行", detail)
+			return true, detail
+		}
+		logger.Warnf("[亏损冷却] ⚠️ %s，冷却期内只执行高信心信号，跳过开仓", detail)
+		return false, detail
+	}
+	logger.Warnf("[亏损冷却] ⚠️ %s，跳过开仓", detail)
+	return false, detail
+}
@@ -0,0 +1,172 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dsbot/internal/ai"
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// testPair 测试用交易对
+const testPair = "BTC-USDT"
+
+// newTestConfig 创建测试配置（BTC-USDT，5倍杠杆，每笔50 USDT）
+func newTestConfig(mode config.TradingMode) *config.Config {
+	cfg := &config.Config{}
+	cfg.Trading.SymbolA, cfg.Trading.SymbolB = "BTC", "USDT"
+	cfg.Trading.Leverage = 5
+	cfg.Trading.Amount = 50
+	cfg.Trading.TradingMode = string(mode)
+	return cfg
+}
+
+// newTestExchange 创建模拟交易所（价格100，余额10000 USDT，手续费为0）
+func newTestExchange(cfg *config.Config) (*exchange.MockExchange, string) {
+	m := exchange.NewMockExchange(cfg.GetTradingMode())
+	m.SetFeeRate(0, 0)
+	symbol := m.ParseSymbols(cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+	m.SetPrice(symbol, 100)
+	m.SetBalance(cfg.Trading.SymbolB, 10000)
+	m.SetLeverage(symbol, cfg.Trading.Leverage)
+	return m, symbol
+}
+
+// newTestJournal 在临时目录创建交易日志
+func newTestJournal(t *testing.T) *journal.Journal {
+	t.Helper()
+	j, err := journal.NewJournal(filepath.Join(t.TempDir(), "journal.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+// capturePublisher 记录发布的通知
+type capturePublisher struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (p *capturePublisher) Publish(level notify.Level, title, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, title+": "+message)
+}
+
+// stubProvider 返回固定信号的AI服务（未实现的方法调用时 panic）
+type stubProvider struct {
+	ai.Provider
+	signal models.TradeSignal
+}
+
+func (p *stubProvider) AnalyzeMarket(ctx context.Context, tradingPair string, marketData *models.MarketData, currentPosition *models.Position, symbolA string, usdtBalance float64) (*models.TradeSignal, error) {
+	signal := p.signal
+	return &signal, nil
+}
+
+func (p *stubProvider) GetSessionInfo(tradingPair string) *models.SessionContext {
+	return nil
+}
+
+// setTestCandles 预设 n 根收盘价在 price 附近小幅波动的15分钟K线
+func setTestCandles(cfg *config.Config, m *exchange.MockExchange, symbol string, n int, price float64) {
+	cfg.Trading.Timeframe = "15m"
+	start := time.Now().Add(-time.Duration(n) * 15 * time.Minute)
+	candles := make([]models.OHLCV, n)
+	for i := range candles {
+		c := price * (1 + 0.002*float64(i%5-2))
+		candles[i] = models.OHLCV{Timestamp: start.Add(time.Duration(i) * 15 * time.Minute), Open: c, High: c * 1.003, Low: c * 0.997, Close: c, Volume: 1000}
+	}
+	candles[n-1].Close = price
+	m.SetCandles(symbol, cfg.Trading.Timeframe, candles)
+}
+
+// memStore 内存存储（检查写入的下单意图等记录）
+type memStore struct {
+	mu      sync.Mutex
+	records map[string][][]byte
+	kv      map[string][]byte
+}
+
+func newMemStore() *memStore {
+	return &memStore{records: make(map[string][][]byte), kv: make(map[string][]byte)}
+}
+
+func (s *memStore) Append(collection string, record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[collection] = append(s.records[collection], append([]byte(nil), record...))
+	return nil
+}
+
+func (s *memStore) Scan(collection string, fn func(record []byte) error) error {
+	s.mu.Lock()
+	records := append([][]byte(nil), s.records[collection]...)
+	s.mu.Unlock()
+	for _, r := range records {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Put(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kv[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (s *memStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.kv[key], nil
+}
+
+func (s *memStore) Backend() string { return "memory" }
+
+func (s *memStore) Close() error { return nil }
+
+// lastIntent 最近写入的下单意图
+func lastIntent(t *testing.T, s *memStore) *TradeIntent {
+	t.Helper()
+	var intent *TradeIntent
+	s.Scan(IntentCollection, func(record []byte) error {
+		intent = &TradeIntent{}
+		return json.Unmarshal(record, intent)
+	})
+	if intent == nil {
+		t.Fatal("未写入下单意图")
+	}
+	return intent
+}
+
+// gate 按名称查找下单前检查结果
+func (intent *TradeIntent) gate(name string) (IntentGate, bool) {
+	for _, g := range intent.Gates {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return IntentGate{}, false
+}
+
+// actions 意图提交的订单操作（逗号分隔）
+func (intent *TradeIntent) actions() string {
+	actions := make([]string, 0, len(intent.Orders))
+	for _, o := range intent.Orders {
+		actions = append(actions, o.Action)
+	}
+	return strings.Join(actions, ",")
+}
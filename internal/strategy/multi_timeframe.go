@@ -0,0 +1,48 @@
+package strategy
+
+import (
+	"dsbot/internal/exchange"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 多周期分析默认配置
+var defaultHigherTimeframes = []string{"1h", "4h"}
+
+const defaultHigherTimeframeDataPoints = 100
+
+// fetchHigherTimeframes 获取大周期K线并计算趋势和支撑阻力摘要（未启用时返回nil，部分周期失败时跳过该周期）
+func (bot *TradingBot) fetchHigherTimeframes(symbol string) []models.TimeframeContext {
+	cfg := bot.config.Trading.MultiTimeframe
+	if !cfg.Enable {
+		return nil
+	}
+
+	candidates := cfg.Timeframes
+	if len(candidates) == 0 {
+		candidates = defaultHigherTimeframes
+	}
+	var timeframes []string
+	for _, tf := range candidates {
+		if tf != bot.config.Trading.Timeframe {
+			timeframes = append(timeframes, tf)
+		}
+	}
+	dataPoints := cfg.DataPoints
+	if dataPoints <= 0 {
+		dataPoints = defaultHigherTimeframeDataPoints
+	}
+
+	candles, err := exchange.FetchOHLCVMulti(bot.exchange, symbol, timeframes, dataPoints)
+	if err != nil {
+		logger.Warnf("[多周期] 部分大周期K线获取失败: %v", err)
+	}
+
+	var contexts []models.TimeframeContext
+	for _, tf := range timeframes {
+		if ctx := bot.calculator.CalculateTimeframeContext(tf, candles[tf]); ctx != nil {
+			contexts = append(contexts, *ctx)
+		}
+	}
+	return contexts
+}
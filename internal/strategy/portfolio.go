@@ -0,0 +1,167 @@
+package strategy
+
+import (
+	"fmt"
+	"sync"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// PortfolioLimits 跨交易对的全局风险限制（同一进程的全部交易对共用一个实例）
+// 各交易对每轮同步持仓时更新其名义价值，新开仓前检查同时持仓数和名义价值合计；
+// 检查通过时预占额度，避免多个交易对同时开仓超限，下一轮同步持仓时按实际持仓更正，
+// 未下单（跳过或下单失败）时撤销预占
+type PortfolioLimits struct {
+	maxPositions int     // 同时持仓的交易对数上限（0不限制）
+	maxExposure  float64 // 持仓名义价值合计上限（计价币种，0不限制）
+
+	mu        sync.Mutex
+	exposures map[string]float64     // 各交易对持仓名义价值（无持仓的交易对不记录）
+	reserved  map[string]reservation // 已预占、尚未同步实际持仓的交易对
+}
+
+// reservation 预占额度前交易对的持仓名义价值（撤销预占时恢复）
+type reservation struct {
+	exposure float64
+	held     bool
+}
+
+// NewPortfolioLimits 创建全局风险限制（未配置任何限制时返回nil）
+func NewPortfolioLimits(cfg config.RiskManagementConfig) *PortfolioLimits {
+	if cfg.MaxOpenPositions <= 0 && cfg.MaxTotalExposure <= 0 {
+		return nil
+	}
+	return &PortfolioLimits{
+		maxPositions: cfg.MaxOpenPositions,
+		maxExposure:  cfg.MaxTotalExposure,
+		exposures:    make(map[string]float64),
+		reserved:     make(map[string]reservation),
+	}
+}
+
+// Update 同步交易对的持仓（pos 为nil表示无持仓），名义价值按持仓数量（基础币种）乘以 price 计算
+func (p *PortfolioLimits) Update(tradingPair string, pos *models.Position, price float64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.reserved, tradingPair)
+	if pos == nil || pos.Size <= 0 {
+		delete(p.exposures, tradingPair)
+		return
+	}
+	p.exposures[tradingPair] = pos.Size * price
+}
+
+// Reserve 检查交易对新开仓 amount（计价币种名义价值）后是否超过全局限制，通过时预占额度
+// 交易对已有的持仓按平仓后重新开仓处理（反向开仓不增加持仓数）
+func (p *PortfolioLimits) Reserve(tradingPair string, amount float64) (bool, string) {
+	if p == nil {
+		return true, ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	positions := len(p.exposures)
+	if _, held := p.exposures[tradingPair]; !held {
+		positions++
+	}
+	var exposure float64
+	for pair, value := range p.exposures {
+		if pair != tradingPair {
+			exposure += value
+		}
+	}
+	exposure += amount
+
+	detail := fmt.Sprintf("全部交易对持仓数 %d", positions)
+	if p.maxPositions > 0 {
+		detail += fmt.Sprintf(" (上限 %d)", p.maxPositions)
+	}
+	detail += fmt.Sprintf("，名义价值合计 %.2f", exposure)
+	if p.maxExposure > 0 {
+		detail += fmt.Sprintf(" (上限 %.2f)", p.maxExposure)
+	}
+	if (p.maxPositions > 0 && positions > p.maxPositions) || (p.maxExposure > 0 && exposure > p.maxExposure) {
+		return false, detail
+	}
+
+	if _, reserved := p.reserved[tradingPair]; !reserved {
+		prev, held := p.exposures[tradingPair]
+		p.reserved[tradingPair] = reservation{exposure: prev, held: held}
+	}
+	p.exposures[tradingPair] = amount
+	return true, detail
+}
+
+// Release 撤销交易对尚未同步实际持仓的预占额度，恢复预占前的持仓名义价值
+func (p *PortfolioLimits) Release(tradingPair string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r, ok := p.reserved[tradingPair]
+	if !ok {
+		return
+	}
+	delete(p.reserved, tradingPair)
+	if r.held {
+		p.exposures[tradingPair] = r.exposure
+	} else {
+		delete(p.exposures, tradingPair)
+	}
+}
+
+// passPortfolioGate 新开仓超过全局持仓数或名义价值合计上限时不开仓（加仓方向相同的持仓和现货卖出不检查）
+func (bot *TradingBot) passPortfolioGate(signal *models.TradeSignal) (bool, string) {
+	if bot.portfolio == nil {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+	side := signalSide(signal.Signal)
+
+	key := bot.legKey(side)
+	passed, detail := bot.portfolio.Reserve(key, bot.orderAmount())
+	if !passed {
+		logger.Warnf("[风险管理] ⚠️ %s，暂停开仓", detail)
+		return false, detail
+	}
+	bot.reservedLeg = key
+	return true, detail
+}
+
+// releasePortfolio 本轮预占的全局持仓额度未下单时撤销（跳过或下单失败），下单后保留到下一轮同步持仓
+func (bot *TradingBot) releasePortfolio(placed bool) {
+	if bot.reservedLeg == "" {
+		return
+	}
+	if !placed {
+		bot.portfolio.Release(bot.reservedLeg)
+	}
+	bot.reservedLeg = ""
+}
+
+// updatePortfolio 同步本轮获取的持仓到全局风险限制（双向持仓时多空分别计为一个持仓）
+func (bot *TradingBot) updatePortfolio(price float64) {
+	if !bot.hedge {
+		bot.portfolio.Update(bot.tradingPair, bot.currentPosition, price)
+		return
+	}
+	for _, side := range positionSides {
+		bot.portfolio.Update(bot.legKey(side), bot.positions[side], price)
+	}
+}
+
+// SetPortfolioLimits 设置跨交易对的全局风险限制（多交易对共用同一实例）
+func (bot *TradingBot) SetPortfolioLimits(p *PortfolioLimits) {
+	bot.portfolio = p
+	for _, rm := range bot.riskManagers() {
+		rm.portfolio = p
+	}
+}
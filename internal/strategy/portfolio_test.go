@@ -0,0 +1,80 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/models"
+)
+
+// 按张计价的交易所持仓按基础币种数量计入名义价值（0.5 BTC @ 100 = 50）
+func TestPortfolioExposureWithContractValue(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   bool
+	}{
+		{amount: 40, want: true},
+		{amount: 60, want: false},
+	}
+
+	for _, tt := range tests {
+		cfg := newTestConfig(config.TradingModeFutures)
+		cfg.Trading.RiskManagement.MaxTotalExposure = 100
+		m, symbol := newTestExchange(cfg)
+		m.SetContractValue(symbol, 0.01)
+		if _, err := m.PlaceOrder(symbol, "buy", 0.5, map[string]interface{}{"posSide": "long"}); err != nil {
+			t.Fatalf("开仓失败: %v", err)
+		}
+
+		bot := NewTradingBot(cfg, m, nil)
+		bot.SetPortfolioLimits(NewPortfolioLimits(cfg.Trading.RiskManagement))
+		pos, err := bot.fetchPosition(symbol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bot.currentPosition = pos
+		bot.updatePortfolio(100)
+
+		if passed, detail := bot.portfolio.Reserve("ETH-USDT", tt.amount); passed != tt.want {
+			t.Fatalf("新开仓 %.0f: 放行 = %v (%s), 期望 %v", tt.amount, passed, detail, tt.want)
+		}
+	}
+}
+
+// 全局持仓检查通过后未下单（跳过或下单失败）时撤销预占额度，不阻塞其他交易对开仓；下单后保留到下一轮同步持仓
+func TestPortfolioReleaseWithoutOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(bot *TradingBot, m *exchange.MockExchange)
+		wantBlocked bool // 期望其他交易对开仓被阻塞
+	}{
+		{name: "下单成功", setup: func(bot *TradingBot, m *exchange.MockExchange) {}, wantBlocked: true},
+		{name: "保证金不足", setup: func(bot *TradingBot, m *exchange.MockExchange) { bot.balance = 1 }},
+		{name: "下单失败", setup: func(bot *TradingBot, m *exchange.MockExchange) {
+			m.SetError("PlaceOrder", errors.New("交易所拒绝"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.RiskManagement.MaxOpenPositions = 1
+			bot, m, _ := newGateBot(t, cfg)
+			portfolio := NewPortfolioLimits(cfg.Trading.RiskManagement)
+			bot.SetPortfolioLimits(portfolio)
+			intents := newMemStore()
+			bot.SetIntentStore(intents)
+			tt.setup(bot, m)
+
+			bot.executeTrade(&models.TradeSignal{Signal: "BUY", Confidence: "HIGH"}, testMarketData(100))
+			if gate, ok := lastIntent(t, intents).gate("portfolio"); !ok || !gate.Passed {
+				t.Fatalf("全局持仓检查 = %+v, 期望通过", gate)
+			}
+			if passed, detail := portfolio.Reserve("ETH-USDT", 10); passed == tt.wantBlocked {
+				t.Fatalf("其他交易对开仓放行 = %v (%s), 期望阻塞 %v", passed, detail, tt.wantBlocked)
+			}
+		})
+	}
+}
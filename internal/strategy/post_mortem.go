@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"context"
+	"math"
+
+	"dsbot/internal/ai"
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// requestPostMortem 启用复盘时在后台请求AI撰写已平仓交易的复盘并写入交易日志（失败只记录日志，不影响交易）
+// exitTrend: 平仓时的整体趋势（风控平仓时为空）
+func requestPostMortem(ctx context.Context, cfg *config.Config, exch exchange.Exchange, provider ai.Provider, j *journal.Journal, entry *journal.Entry, exitTrend string) {
+	if !cfg.Trading.Journal.PostMortem || provider == nil || j == nil || entry == nil {
+		return
+	}
+
+	review := &models.TradeReview{
+		TradingPair: entry.TradingPair,
+		Side:        entry.Side,
+		Confidence:  entry.Confidence,
+		Regime:      entry.Regime,
+		EntryReason: entry.Reason,
+		ExitReason:  entry.ExitReason,
+		EntryPrice:  entry.EntryPrice,
+		ExitPrice:   entry.ExitPrice,
+		Leverage:    cfg.Trading.Leverage,
+		OpenedAt:    entry.OpenedAt,
+		ClosedAt:    entry.ClosedAt,
+		ReturnPct:   entry.ReturnPct,
+		NetPnL:      entry.NetPnL,
+		ExitTrend:   exitTrend,
+	}
+
+	go func() {
+		symbol := exch.ParseSymbols(cfg.Trading.SymbolA, cfg.Trading.SymbolB)
+		if candles, err := exch.FetchOHLCV(symbol, cfg.Trading.Timeframe, cfg.Trading.DataPoints); err != nil {
+			logger.Debugf("[复盘] 获取持仓期间K线失败: %v", err)
+		} else {
+			review.MaxFavorable, review.MaxAdverse = excursions(review, candles)
+		}
+
+		postMortem, err := provider.WritePostMortem(ctx, review)
+		if err != nil {
+			logger.Warnf("[复盘] [%s] 交易 #%d 复盘失败: %v", entry.TradingPair, entry.ID, err)
+			return
+		}
+		j.SetPostMortem(entry.ID, postMortem)
+		logger.Printf("[复盘] [%s] 交易 #%d: %s | 问题: %s | 经验: %s",
+			entry.TradingPair, entry.ID, postMortem.Summary, postMortem.Mistakes, postMortem.Lesson)
+	}()
+}
+
+// excursions 持仓期间的最大有利/不利波动（%，相对开仓价；K线开盘时间不晚于平仓、且下一根K线晚于开仓的计入）
+func excursions(review *models.TradeReview, candles []models.OHLCV) (favorable, adverse float64) {
+	if review.EntryPrice <= 0 {
+		return 0, 0
+	}
+	high, low := 0.0, math.MaxFloat64
+	for i, candle := range candles {
+		if candle.Timestamp.After(review.ClosedAt) {
+			break
+		}
+		if i+1 < len(candles) && !candles[i+1].Timestamp.After(review.OpenedAt) {
+			continue
+		}
+		high = math.Max(high, candle.High)
+		low = math.Min(low, candle.Low)
+	}
+	if high == 0 {
+		return 0, 0
+	}
+
+	up := (high - review.EntryPrice) / review.EntryPrice * 100
+	down := (review.EntryPrice - low) / review.EntryPrice * 100
+	if review.Side == "short" {
+		up, down = down, up
+	}
+	return math.Max(up, 0), math.Max(down, 0)
+}
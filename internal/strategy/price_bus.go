@@ -0,0 +1,64 @@
+package strategy
+
+import (
+	"sync"
+	"time"
+
+	"dsbot/internal/exchange"
+	"dsbot/internal/models"
+)
+
+// priceEntry 缓存的行情
+type priceEntry struct {
+	ticker    *models.Ticker
+	updatedAt time.Time
+}
+
+// PriceBus 共享行情总线 - 多个组件（多交易对风控、策略等）共享同一份最新价格，
+// 同一交易对在 maxAge 内只请求一次交易所，减少请求量
+type PriceBus struct {
+	exchange exchange.Exchange
+	maxAge   time.Duration
+	prices   map[string]*priceEntry
+	mu       sync.Mutex
+}
+
+// NewPriceBus 创建行情总线
+// maxAge: 缓存有效期，超过后重新请求交易所
+func NewPriceBus(exch exchange.Exchange, maxAge time.Duration) *PriceBus {
+	return &PriceBus{
+		exchange: exch,
+		maxAge:   maxAge,
+		prices:   make(map[string]*priceEntry),
+	}
+}
+
+// Ticker 获取交易对最新行情（缓存有效时直接返回缓存）
+func (b *PriceBus) Ticker(symbol string) (*models.Ticker, error) {
+	b.mu.Lock()
+	entry, ok := b.prices[symbol]
+	b.mu.Unlock()
+
+	if ok && time.Since(entry.updatedAt) < b.maxAge {
+		return entry.ticker, nil
+	}
+
+	ticker, err := b.exchange.FetchTicker(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Publish(ticker)
+	return ticker, nil
+}
+
+// Publish 发布最新行情（可由其他行情源推送）
+func (b *PriceBus) Publish(ticker *models.Ticker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.prices[ticker.Symbol] = &priceEntry{
+		ticker:    ticker,
+		updatedAt: time.Now(),
+	}
+}
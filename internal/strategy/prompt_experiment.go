@@ -0,0 +1,26 @@
+package strategy
+
+import (
+	"dsbot/internal/ai"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+)
+
+// recordVariantTrade 启用提示词A/B实验时，把已平仓交易的结果计入开仓信号所用变体的统计，并输出各变体对比
+func recordVariantTrade(provider ai.Provider, entry *journal.Entry) {
+	if provider == nil || entry == nil || entry.PromptVariant == "" {
+		return
+	}
+	session := provider.GetSessionInfo(entry.TradingPair)
+	if session == nil {
+		return
+	}
+
+	session.RecordVariantTrade(entry.PromptVariant, entry.ReturnPct, entry.NetPnL)
+	logger.Printf("[A/B实验] [%s] 交易 #%d (变体 %s) 净收益率: %+.2f%%, 净盈亏: %.4f",
+		entry.TradingPair, entry.ID, entry.PromptVariant, entry.ReturnPct, entry.NetPnL)
+	logger.Printf("[A/B实验] [%s] %s", entry.TradingPair, session.FormatVariantStats())
+	if best := session.BestVariant(); best != "" {
+		logger.Printf("[A/B实验] [%s] 当前表现较好的提示词: %s（按平均净收益率）", entry.TradingPair, best)
+	}
+}
@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"time"
+
+	"dsbot/internal/calendar"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// fillLookback 按成交记录补全订单成交时查询的时间范围
+const fillLookback = 10 * time.Minute
+
+// fillFromTrades 订单查询失败或未返回成交均价时，按交易所成交记录汇总该订单的成交均价和手续费
+// 成交数量为交易所原始单位（合约为张数）；查询失败或没有该订单的成交时返回 order 本身
+func fillFromTrades(exch exchange.Exchange, symbol, orderID string, order *models.Order) *models.Order {
+	if orderID == "" || (order != nil && order.AvgPrice > 0) {
+		return order
+	}
+	trades, err := exch.FetchMyTrades(symbol, time.Now().Add(-fillLookback))
+	if err != nil {
+		logger.Printf("[WARNING] 查询订单 %s 的成交记录失败: %v", orderID, err)
+		return order
+	}
+
+	filled := &models.Order{OrderID: orderID, Symbol: symbol, Type: "market", State: models.OrderStateFilled}
+	var notional float64
+	for _, t := range trades {
+		if t.OrderID != orderID {
+			continue
+		}
+		filled.Side = t.Side
+		filled.FilledSize += t.Size
+		notional += t.Price * t.Size
+		filled.Fee += t.Fee
+		filled.FeeCurrency = t.FeeCurrency
+		filled.UpdatedAt = t.Timestamp
+	}
+	if filled.FilledSize <= 0 {
+		return order
+	}
+	filled.Size = filled.FilledSize
+	filled.AvgPrice = notional / filled.FilledSize
+	logger.Printf("[INFO] 按成交记录补全订单 %s - 成交均价: %.2f, 手续费: %.4f %s",
+		orderID, filled.AvgPrice, -filled.Fee, filled.FeeCurrency)
+	return filled
+}
+
+// tradingDayStart 当前交易日的开始时间（未配置交易日历时按UTC日界线）
+func tradingDayStart(cal *calendar.Calendar, now time.Time) time.Time {
+	if cal != nil {
+		return cal.DayStart(now)
+	}
+	return now.UTC().Truncate(24 * time.Hour)
+}
+
+// logRealizedTotals 平仓后输出交易对当日和累计的已实现盈亏（按实际成交价格和手续费计算）
+func logRealizedTotals(j *journal.Journal, cal *calendar.Calendar, tradingPair, currency string) {
+	if j == nil {
+		return
+	}
+	today := j.Realized(tradingPair, tradingDayStart(cal, time.Now()))
+	total := j.Realized(tradingPair, time.Time{})
+	logger.Printf("[已实现盈亏] %s 当日: %+.4f %s (%d 笔，手续费 %.4f)，累计: %+.4f %s (%d 笔，手续费 %.4f)",
+		tradingPair, today.NetPnL, currency, today.Trades, today.Fees, total.NetPnL, currency, total.Trades, total.Fees)
+}
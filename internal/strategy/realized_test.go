@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/journal"
+)
+
+// 合约面值不为1时（如OKX BTC-USDT-SWAP 每张0.01 BTC），风控平仓按基础币种数量平仓并计算已实现盈亏
+func TestRiskClosePnLWithContractValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		side        string
+		exitPrice   float64
+		withJournal bool
+		wantPnL     float64
+		wantNotify  string
+	}{
+		{name: "多头亏损", side: "long", exitPrice: 90, withJournal: true, wantPnL: -5},
+		{name: "空头盈利", side: "short", exitPrice: 90, withJournal: true, wantPnL: 5},
+		{name: "无交易日志", side: "long", exitPrice: 110, wantNotify: "盈亏: 5.0000 USDT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			m, symbol := newTestExchange(cfg)
+			m.SetContractValue(symbol, 0.01)
+
+			orderSide := "buy"
+			if tt.side == "short" {
+				orderSide = "sell"
+			}
+			orderID, err := m.PlaceOrder(symbol, orderSide, 0.5, map[string]interface{}{"posSide": tt.side})
+			if err != nil {
+				t.Fatalf("开仓失败: %v", err)
+			}
+			if order, _ := m.FetchOrder(symbol, orderID); order.FilledSize != 50 {
+				t.Fatalf("开仓成交 = %.4f 张, 期望 50 张", order.FilledSize)
+			}
+
+			pos, err := m.FetchPosition(symbol)
+			if err != nil || pos == nil || pos.Size != 0.5 {
+				t.Fatalf("持仓 = %+v (%v), 期望 0.5 BTC", pos, err)
+			}
+
+			rm := NewRiskManager(cfg, m, testPair)
+			var j *journal.Journal
+			if tt.withJournal {
+				j = newTestJournal(t)
+				j.Open(journal.Entry{TradingPair: testPair, Side: tt.side, EntryPrice: 100, Size: 0.5})
+				rm.journal = j
+			}
+			publisher := &capturePublisher{}
+			rm.notifier = publisher
+			rm.UpdatePosition(pos)
+
+			m.SetPrice(symbol, tt.exitPrice)
+			rm.closePosition(context.Background(), pos, tt.exitPrice)
+
+			if left, _ := m.FetchPosition(symbol); left != nil {
+				t.Fatalf("平仓后仍有持仓: %+v", left)
+			}
+			if j != nil {
+				entries := j.Entries()
+				if len(entries) != 1 || !entries[0].Closed {
+					t.Fatalf("交易日志 = %+v, 期望一条已平仓记录", entries)
+				}
+				if math.Abs(entries[0].NetPnL-tt.wantPnL) > 1e-9 {
+					t.Fatalf("已实现盈亏 = %.4f, 期望 %.4f", entries[0].NetPnL, tt.wantPnL)
+				}
+			}
+			if tt.wantNotify != "" {
+				if len(publisher.messages) == 0 || !strings.Contains(publisher.messages[0], tt.wantNotify) {
+					t.Fatalf("通知 = %v, 期望包含 %q", publisher.messages, tt.wantNotify)
+				}
+			}
+		})
+	}
+}
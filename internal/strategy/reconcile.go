@@ -0,0 +1,157 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/notify"
+)
+
+// reconcileSizeTolerance 持仓数量与交易日志记录的允许相对误差（交易所数量精度取整）
+const reconcileSizeTolerance = 0.01
+
+// Reconcile 启动时核对交易所的持仓和未成交委托与本地记录（交易日志、持仓风控状态）并记录差异
+// 交易所持有的仓位（包括本地未记录的手动开仓）交由风险管理器接管，启用 cancel_orphan_orders 时撤销未被跟踪的委托
+func (bot *TradingBot) Reconcile() {
+	// 交易对由其他工作进程持有或当前为备用实例时，由对方负责持仓和委托
+	if (bot.lease != nil && !bot.lease.Held()) || (bot.failover != nil && bot.failover.IsStandby()) {
+		logger.Printf("[启动核对] %s 由其他实例处理，跳过核对", bot.tradingPair)
+		return
+	}
+
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+
+	var issues []string
+	if bot.config.IsFuturesMode() {
+		issues = append(issues, bot.reconcilePosition(symbol)...)
+	}
+	issues = append(issues, bot.reconcileOrders(symbol)...)
+
+	if len(issues) == 0 {
+		logger.Printf("[启动核对] %s 交易所持仓和委托与本地记录一致", bot.tradingPair)
+		return
+	}
+	for _, issue := range issues {
+		logger.Warnf("[启动核对] ⚠️ %s %s", bot.tradingPair, issue)
+	}
+	bot.publish(notify.LevelWarning, "启动核对发现差异", bot.tradingPair+"\n"+strings.Join(issues, "\n"))
+}
+
+// reconcilePosition 核对交易所持仓与交易日志和保存的风控状态，并将持仓交由风险管理器接管，返回差异说明
+func (bot *TradingBot) reconcilePosition(symbol string) []string {
+	pos, err := bot.fetchPosition(symbol)
+	if err != nil {
+		logger.Printf("[启动核对] %s 获取持仓失败: %v", bot.tradingPair, err)
+		return nil
+	}
+	if !bot.hedge {
+		return bot.reconcileLeg("", pos)
+	}
+
+	// 双向持仓：多空仓位分别核对并交由对应方向的风险管理器接管
+	var issues []string
+	for _, side := range positionSides {
+		issues = append(issues, bot.reconcileLeg(side, bot.positions[side])...)
+	}
+	bot.currentPosition = pos
+	return issues
+}
+
+// reconcileLeg 核对一个方向的持仓（单向持仓时 side 为空，不区分方向），返回差异说明
+func (bot *TradingBot) reconcileLeg(side string, pos *models.Position) []string {
+	var issues []string
+	if bot.journal != nil {
+		entry := bot.journal.OpenLeg(bot.tradingPair, side)
+		switch {
+		case pos == nil && entry != nil:
+			issues = append(issues, fmt.Sprintf("交易日志中有未平仓的%s仓（开仓价 %.2f），交易所已无持仓，首轮执行时补记平仓",
+				entry.Side, entry.EntryPrice))
+		case pos != nil && entry == nil:
+			issues = append(issues, fmt.Sprintf("交易所持有交易日志未记录的%s仓（数量 %.8f，开仓价 %.2f），可能为手动开仓或崩溃前未写入日志",
+				pos.Side, pos.Size, pos.EntryPrice))
+		case pos != nil && entry.Side != pos.Side:
+			issues = append(issues, fmt.Sprintf("交易日志记录为%s仓，交易所持仓为%s仓（数量 %.8f，开仓价 %.2f）",
+				entry.Side, pos.Side, pos.Size, pos.EntryPrice))
+		case pos != nil && entry.Size > 0 && math.Abs(pos.Size-entry.Size) > entry.Size*reconcileSizeTolerance:
+			issues = append(issues, fmt.Sprintf("%s仓数量 %.8f 与交易日志记录的 %.8f 不一致，可能有手动加减仓",
+				pos.Side, pos.Size, entry.Size))
+		}
+	}
+
+	rm := bot.legRisk(side)
+	if rm == nil {
+		return issues
+	}
+	rm.mu.Lock()
+	restored := rm.restored
+	rm.mu.Unlock()
+	if restored != nil && !restored.matches(pos) {
+		issues = append(issues, fmt.Sprintf("保存的风控状态（%s仓，开仓价 %.2f）与交易所持仓不一致，已丢弃", restored.Side, restored.EntryPrice))
+	}
+
+	bot.currentPosition = pos
+	rm.UpdatePosition(pos)
+	if pos != nil {
+		bot.portfolio.Update(bot.legKey(pos.Side), pos, pos.EntryPrice)
+		logger.Printf("[启动核对] %s 风险管理器已接管%s仓 - 数量:%.8f, 开仓价:%.2f",
+			bot.tradingPair, pos.Side, pos.Size, pos.EntryPrice)
+		if len(issues) > 0 {
+			bot.recordEvent(TradeEvent{Type: EventRiskAction, Side: pos.Side, Price: pos.EntryPrice, Size: pos.Size, Reason: "启动核对接管持仓"})
+		}
+	}
+	return issues
+}
+
+// reconcileOrders 核对交易对上的未成交委托，返回差异说明
+// 机器人的开仓限价单在等待成交后即撤单，其余订单均为市价单，启动时仍挂着的委托（括号单除外）均未被跟踪，
+// 如手动委托或崩溃前未撤销的限价开仓单
+func (bot *TradingBot) reconcileOrders(symbol string) []string {
+	orders, err := bot.exchange.FetchOpenOrders(symbol)
+	if err != nil {
+		logger.Printf("[启动核对] %s 获取未成交委托失败: %v", bot.tradingPair, err)
+		return nil
+	}
+
+	// 括号单的止损止盈委托由风险管理器跟踪
+	tracked := make(map[string]bool)
+	for _, rm := range bot.riskManagers() {
+		if b := rm.currentBracket(); b != nil {
+			for _, id := range []string{b.stopLossID, b.takeProfitID, b.trailingID} {
+				if id != "" {
+					tracked[id] = true
+				}
+			}
+		}
+	}
+
+	cancel := bot.config.Trading.Reconcile.CancelOrphanOrders
+	var issues []string
+	for _, o := range orders {
+		if o.ClientOrderID != "" && tracked[o.ClientOrderID] {
+			continue
+		}
+		issue := fmt.Sprintf("未被跟踪的%s委托 %s（%s %s，数量 %g，价格 %.2f）", orderOrigin(o), o.OrderID, o.Side, o.Type, o.Size, o.Price)
+		if !cancel {
+			issues = append(issues, issue)
+			continue
+		}
+		if err := bot.exchange.CancelOrder(symbol, o.OrderID); err != nil {
+			issues = append(issues, fmt.Sprintf("%s，撤销失败: %v", issue, err))
+			continue
+		}
+		issues = append(issues, issue+"，已撤销")
+		bot.recordEvent(TradeEvent{Type: EventRiskAction, Side: o.Side, Price: o.Price, Size: o.Size, OrderID: o.OrderID, Reason: "启动核对撤销未被跟踪的委托"})
+	}
+	return issues
+}
+
+// orderOrigin 按自定义订单ID判断委托来源（机器人生成的ID以 ds 开头）
+func orderOrigin(o models.Order) string {
+	if strings.HasPrefix(o.ClientOrderID, "ds") {
+		return "机器人"
+	}
+	return "手动"
+}
@@ -0,0 +1,71 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"dsbot/internal/config"
+	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
+)
+
+func TestReconcilePositionSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctVal     float64
+		entrySize float64
+		wantIssue string // 为空表示没有差异
+	}{
+		{name: "面值1数量一致", ctVal: 1, entrySize: 0.5},
+		{name: "按张计价数量一致", ctVal: 0.01, entrySize: 0.5},
+		{name: "按张计价手动加仓", ctVal: 0.01, entrySize: 0.3, wantIssue: "可能有手动加减仓"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			m, symbol := newTestExchange(cfg)
+			m.SetContractValue(symbol, tt.ctVal)
+			if _, err := m.PlaceOrder(symbol, "buy", 0.5, map[string]interface{}{"posSide": "long"}); err != nil {
+				t.Fatalf("开仓失败: %v", err)
+			}
+
+			bot := NewTradingBot(cfg, m, nil)
+			j := newTestJournal(t)
+			j.Open(journal.Entry{TradingPair: testPair, Side: "long", EntryPrice: 100, Size: tt.entrySize})
+			bot.journal = j
+
+			issues := strings.Join(bot.reconcilePosition(symbol), "\n")
+			if tt.wantIssue == "" && issues != "" {
+				t.Fatalf("差异 = %q, 期望一致", issues)
+			}
+			if !strings.Contains(issues, tt.wantIssue) {
+				t.Fatalf("差异 = %q, 期望包含 %q", issues, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func TestReconcileOrphanLimitOrder(t *testing.T) {
+	cfg := newTestConfig(config.TradingModeFutures)
+	cfg.Trading.Reconcile.CancelOrphanOrders = true
+	m, symbol := newTestExchange(cfg)
+
+	// 崩溃前未撤销的限价开仓单
+	orderID, err := m.PlaceOrder(symbol, "buy", 0.5, map[string]interface{}{
+		exchange.ParamClientOrderID: exchange.IntentClientOrderID("crashed"),
+		exchange.ParamLimitPrice:    90.0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := NewTradingBot(cfg, m, nil)
+	issues := bot.reconcileOrders(symbol)
+	if len(issues) != 1 || !strings.Contains(issues[0], "未被跟踪的机器人委托") || !strings.Contains(issues[0], "已撤销") {
+		t.Fatalf("差异 = %v, 期望撤销一笔机器人限价单", issues)
+	}
+	if order, _ := m.FetchOrder(symbol, orderID); order.State != "canceled" {
+		t.Fatalf("限价单状态 = %s, 期望已撤销", order.State)
+	}
+}
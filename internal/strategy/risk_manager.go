@@ -2,98 +2,139 @@ package strategy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"dsbot/internal/ai"
+	"dsbot/internal/calendar"
 	"dsbot/internal/config"
 	"dsbot/internal/exchange"
+	"dsbot/internal/journal"
 	"dsbot/internal/logger"
 	"dsbot/internal/models"
+	"dsbot/internal/notify"
+	"dsbot/internal/store"
 )
 
 // RiskManager 风险管理器（负责止盈止损监控）
 type RiskManager struct {
-	config          *config.Config
-	exchange        exchange.Exchange
-	tradingPair     string
-	ctx             context.Context
-	cancel          context.CancelFunc
-	wg              sync.WaitGroup
-	running         bool
-	mu              sync.Mutex
-	currentPosition *models.Position
+	config              *config.Config
+	exchange            exchange.Exchange
+	tradingPair         string
+	priceBus            *PriceBus             // 共享行情总线（可选，为nil时直接请求交易所）
+	executor            *ExecutionCoordinator // 下单协调器（可选，与策略共享）
+	notifier            notify.Publisher      // 通知发布器（可选）
+	aiClient            ai.Provider           // AI客户端（可选，用于提前离场询问）
+	calendar            *calendar.Calendar    // 交易日历（可选，用于每日询问预算）
+	journal             *journal.Journal      // 交易日志（可选）
+	events              *eventRecorder        // 交易事件记录器（可选，与策略共用）
+	portfolio           *PortfolioLimits      // 跨交易对的全局风险限制（可选）
+	mu                  sync.Mutex
+	currentPosition     *models.Position
+	aiExit              aiExitBudget    // AI离场询问预算
+	dailyLoss           *dailyLossState // 每日亏损上限的当日状态（与策略共用）
+	bracket             *bracket        // 当前持仓的括号单（交易所端止损止盈）
+	feeRate             *models.FeeRate // 手续费率（可选，订单无手续费信息时用于估算）
+	tickSize            float64         // 最小变动价位（止盈止损价按此取整，0表示不取整）
+	volatilityScale     float64         // 止盈止损波动率缩放倍数（0表示未计算）
+	suggestedStopLoss   float64         // AI建议的止损百分比（0表示使用固定百分比）
+	suggestedTakeProfit float64         // AI建议的止盈百分比（0表示使用固定百分比）
+	lease               *PairLease      // 交易对租约（可选，未持有时跳过检查）
+	failover            *Failover       // 主备切换（可选，备用实例仅在接管期间检查）
+
+	stateStore store.Store // 持仓风控状态的持久化存储（可选）
+	restored   *riskState  // 上次保存、尚未恢复到持仓的风控状态
+	leg        string      // 双向持仓时负责的持仓方向（long/short，单向持仓为空）
 }
 
 // NewRiskManager 创建风险管理器
 func NewRiskManager(cfg *config.Config, exch exchange.Exchange, tradingPair string) *RiskManager {
-	ctx, cancel := context.WithCancel(context.Background())
 	return &RiskManager{
 		config:      cfg,
 		exchange:    exch,
 		tradingPair: tradingPair,
-		ctx:         ctx,
-		cancel:      cancel,
+		dailyLoss:   &dailyLossState{},
 	}
 }
 
-// Start 启动风险管理监控
-func (rm *RiskManager) Start() error {
-	rm.mu.Lock()
-	if rm.running {
-		rm.mu.Unlock()
-		return fmt.Errorf("风险管理器已在运行中")
-	}
-	rm.running = true
-	rm.mu.Unlock()
-
-	logger.Println("[风险管理] 启动止盈止损监控...")
-	logger.Printf("[风险管理] 止损: %.2f%%, 止盈: %.2f%%",
+// logSettings 打印风控参数
+func (rm *RiskManager) logSettings() {
+	logger.Printf("[风险管理] [%s] 启动止盈止损监控...", rm.tradingPair)
+	logger.Printf("[风险管理] [%s] 止损: %.2f%%, 止盈: %.2f%%, 价格来源: %s",
+		rm.tradingPair,
 		rm.config.Trading.RiskManagement.StopLossPercent,
-		rm.config.Trading.RiskManagement.TakeProfitPercent)
+		rm.config.Trading.RiskManagement.TakeProfitPercent,
+		rm.priceSource())
 
-	if rm.config.Trading.RiskManagement.EnableTrailingStop {
-		logger.Printf("[风险管理] 移动止损: 启用, 距离: %.2f%%",
-			rm.config.Trading.RiskManagement.TrailingStopDistance)
+	if cfg := rm.config.Trading.RiskManagement.VolatilityScaling; cfg.Enable {
+		logger.Printf("[风险管理] [%s] 波动率缩放: 启用, 参考ATR: %.2f%%", rm.tradingPair, cfg.ReferenceATRPercent)
 	}
 
-	// 【修复】启动时立即检查一次现有持仓
-	go func() {
-		time.Sleep(2 * time.Second) // 等待2秒确保系统完全启动
-		rm.checkPosition()
-	}()
+	if rm.exchangeBracket() {
+		logger.Printf("[风险管理] [%s] 交易所端括号单: 启用", rm.tradingPair)
+	} else if rm.config.Trading.RiskManagement.ExchangeBracket {
+		logger.Warnf("[风险管理] [%s] %s 不支持交易所端止损止盈委托，改由本地风控执行止盈止损",
+			rm.tradingPair, rm.exchange.GetExchangeName())
+	}
 
-	rm.wg.Add(1)
-	go rm.monitorLoop()
+	if rm.config.Trading.RiskManagement.EnableTrailingStop {
+		venue := "本地"
+		if rm.exchangeTrailingStop() {
+			venue = "交易所端"
+		} else if rm.config.Trading.RiskManagement.TrailingStopMode == TrailingStopModeExchange {
+			logger.Warnf("[风险管理] [%s] %s 不支持交易所端移动止损委托，改由本地风控跟踪移动止损",
+				rm.tradingPair, rm.exchange.GetExchangeName())
+		}
+		logger.Printf("[风险管理] [%s] 移动止损: 启用, 距离: %.2f%%, 执行方式: %s",
+			rm.tradingPair,
+			rm.config.Trading.RiskManagement.TrailingStopDistance,
+			venue)
+	}
 
-	return nil
+	if cfg := rm.aiExitSettings(); cfg.Enable {
+		logger.Printf("[风险管理] [%s] AI提前离场检查: 启用, 触发比例: %.0f%%, 每仓%d次/每日%d次",
+			rm.tradingPair, cfg.TriggerRatio*100, cfg.MaxCallsPerPosition, cfg.MaxCallsPerDay)
+	}
 }
 
-// Stop 停止风险管理监控
-func (rm *RiskManager) Stop() {
+// SetPriceBus 设置共享行情总线
+func (rm *RiskManager) SetPriceBus(bus *PriceBus) {
 	rm.mu.Lock()
-	if !rm.running {
-		rm.mu.Unlock()
-		return
-	}
-	rm.mu.Unlock()
-
-	logger.Println("[风险管理] 正在停止监控...")
-	rm.cancel()
-	rm.wg.Wait()
+	defer rm.mu.Unlock()
+	rm.priceBus = bus
+}
 
+// fetchTicker 获取最新行情（优先使用共享行情总线）
+func (rm *RiskManager) fetchTicker(symbol string) (*models.Ticker, error) {
 	rm.mu.Lock()
-	rm.running = false
+	bus := rm.priceBus
 	rm.mu.Unlock()
 
-	logger.Println("[风险管理] 监控已停止")
+	if bus != nil {
+		return bus.Ticker(symbol)
+	}
+	return rm.exchange.FetchTicker(symbol)
 }
 
-// IsRunning 检查是否正在运行
-func (rm *RiskManager) IsRunning() bool {
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-	return rm.running
+// priceSource 获取触发止盈止损的价格来源（默认最新成交价）
+func (rm *RiskManager) priceSource() string {
+	switch source := rm.config.Trading.RiskManagement.PriceSource; source {
+	case models.PriceSourceMark, models.PriceSourceIndex:
+		return source
+	default:
+		return models.PriceSourceLast
+	}
+}
+
+// CheckInterval 获取检查间隔
+func (rm *RiskManager) CheckInterval() time.Duration {
+	checkInterval := time.Duration(rm.config.Trading.RiskManagement.CheckIntervalSeconds) * time.Second
+	if checkInterval == 0 {
+		checkInterval = 10 * time.Second // 默认10秒
+	}
+	return checkInterval
 }
 
 // UpdatePosition 更新当前持仓信息
@@ -102,51 +143,90 @@ func (rm *RiskManager) UpdatePosition(pos *models.Position) {
 	defer rm.mu.Unlock()
 
 	if pos == nil {
+		if rm.currentPosition != nil || rm.restored != nil {
+			rm.restored = nil
+			rm.saveStateLocked(nil)
+		}
 		rm.currentPosition = nil
 		logger.Debugf("[风险管理] 持仓已清空")
 		return
 	}
 
-	// 如果是新开仓，计算止盈止损价格
+	// 如果是新开仓，计算止盈止损价格（重启后同一持仓恢复上次保存的状态）
 	if rm.currentPosition == nil ||
 		rm.currentPosition.EntryPrice != pos.EntryPrice ||
 		rm.currentPosition.Side != pos.Side {
-		rm.calculateStopLossTakeProfit(pos)
-		logger.Printf("[风险管理] 新持仓监控开始 - 方向:%s, 开仓价:%.2f, 止损:%.2f, 止盈:%.2f",
-			pos.Side, pos.EntryPrice, pos.StopLoss, pos.TakeProfit)
+		if !rm.restoreStateLocked(pos) {
+			rm.calculateStopLossTakeProfit(pos)
+			stopLossPercent, takeProfitPercent := rm.stopLossTakeProfitPercentLocked()
+			logger.Printf("[风险管理] 新持仓监控开始 - 方向:%s, 开仓价:%.2f, 止损:%.2f(%.2f%%), 止盈:%.2f(%.2f%%)",
+				pos.Side, pos.EntryPrice, pos.StopLoss, stopLossPercent, pos.TakeProfit, takeProfitPercent)
+		}
+		rm.aiExit.positionCalls = 0
+		rm.aiExit.verdict = nil
+		rm.checkLiquidationLocked(pos)
+		rm.saveStateLocked(pos)
+	} else if pos != rm.currentPosition {
+		// 同一持仓重新从交易所获取时沿用已计算的止盈止损和移动止损
+		newRiskState(rm.currentPosition).applyTo(pos)
 	}
 
 	rm.currentPosition = pos
 }
 
+// checkLiquidationLocked 检查止损价是否在强平价之前触发（调用方需持有 rm.mu）
+// 止损价越过强平价时止损不会生效，仓位会先被交易所强平
+func (rm *RiskManager) checkLiquidationLocked(pos *models.Position) {
+	if pos.LiquidationPrice <= 0 {
+		return
+	}
+	stop := pos.StopLoss
+	if stop <= 0 {
+		stop = pos.TrailingStop
+	}
+	logger.Printf("[风险管理] 强平价:%.2f (距开仓价 %.2f%%)", pos.LiquidationPrice, pos.LiquidationDistance(pos.EntryPrice))
+	if stop <= 0 {
+		return
+	}
+	if (pos.Side == "long" && stop <= pos.LiquidationPrice) || (pos.Side == "short" && stop >= pos.LiquidationPrice) {
+		logger.Warnf("[风险管理] ⚠️ 止损价 %.2f 越过强平价 %.2f，止损触发前仓位将被强平，请降低杠杆或收紧止损",
+			stop, pos.LiquidationPrice)
+		rm.publish(notify.LevelWarning, "止损价越过强平价",
+			fmt.Sprintf("%s %s仓 止损价:%.2f, 强平价:%.2f, 杠杆:%dx", rm.tradingPair, pos.Side, stop, pos.LiquidationPrice, pos.Leverage))
+	}
+}
+
 // calculateStopLossTakeProfit 计算止盈止损价格
 func (rm *RiskManager) calculateStopLossTakeProfit(pos *models.Position) {
 	cfg := rm.config.Trading.RiskManagement
+	stopLossPercent, takeProfitPercent := rm.stopLossTakeProfitPercentLocked()
 
 	if pos.Side == "long" {
 		// 多仓
 		if cfg.EnableStopLoss {
-			pos.StopLoss = pos.EntryPrice * (1 - cfg.StopLossPercent/100)
+			pos.StopLoss = pos.EntryPrice * (1 - stopLossPercent/100)
 		}
 		if cfg.EnableTakeProfit {
-			pos.TakeProfit = pos.EntryPrice * (1 + cfg.TakeProfitPercent/100)
+			pos.TakeProfit = pos.EntryPrice * (1 + takeProfitPercent/100)
 		}
 		pos.HighestPrice = pos.EntryPrice
 		pos.LowestPrice = pos.EntryPrice
 	} else if pos.Side == "short" {
 		// 空仓
 		if cfg.EnableStopLoss {
-			pos.StopLoss = pos.EntryPrice * (1 + cfg.StopLossPercent/100)
+			pos.StopLoss = pos.EntryPrice * (1 + stopLossPercent/100)
 		}
 		if cfg.EnableTakeProfit {
-			pos.TakeProfit = pos.EntryPrice * (1 - cfg.TakeProfitPercent/100)
+			pos.TakeProfit = pos.EntryPrice * (1 - takeProfitPercent/100)
 		}
 		pos.HighestPrice = pos.EntryPrice
 		pos.LowestPrice = pos.EntryPrice
 	}
+	pos.StopLoss = exchange.RoundPrice(pos.StopLoss, rm.tickSize)
+	pos.TakeProfit = exchange.RoundPrice(pos.TakeProfit, rm.tickSize)
 
-	// 初始化移动止损价格
-	if cfg.EnableTrailingStop {
+	// 初始化移动止损价格（交易所端移动止损由交易所跟踪，本地不计算）
+	if rm.localTrailingStop() {
 		// 【修复】如果固定止损未启用或为0，独立计算移动止损初始值
 		if !cfg.EnableStopLoss || pos.StopLoss == 0 {
 			if pos.Side == "long" {
@@ -168,30 +248,23 @@ func (rm *RiskManager) calculateStopLossTakeProfit(pos *models.Position) {
 	}
 }
 
-// monitorLoop 监控循环
-func (rm *RiskManager) monitorLoop() {
-	defer rm.wg.Done()
+// CheckNow 立即执行一次止盈止损检查
+func (rm *RiskManager) CheckNow() {
+	rm.checkPosition(context.Background())
+}
 
-	checkInterval := time.Duration(rm.config.Trading.RiskManagement.CheckIntervalSeconds) * time.Second
-	if checkInterval == 0 {
-		checkInterval = 10 * time.Second // 默认10秒
+// checkPosition 检查持仓并执行止盈止损
+// ctx 由统一风控循环持有，循环停止或重启时取消其中发起的AI询问和复盘
+func (rm *RiskManager) checkPosition(ctx context.Context) {
+	// 交易对由其他工作进程持有时不检查，避免重复平仓
+	if rm.lease != nil && !rm.lease.Held() {
+		return
 	}
-
-	ticker := time.NewTicker(checkInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			rm.checkPosition()
-		case <-rm.ctx.Done():
-			return
-		}
+	// 备用实例在主实例存活期间不检查
+	if rm.failover != nil && !rm.failover.Active() {
+		return
 	}
-}
 
-// checkPosition 检查持仓并执行止盈止损
-func (rm *RiskManager) checkPosition() {
 	rm.mu.Lock()
 	pos := rm.currentPosition
 	rm.mu.Unlock()
@@ -203,27 +276,22 @@ func (rm *RiskManager) checkPosition() {
 
 	// 获取当前价格
 	symbol := rm.exchange.ParseSymbols(rm.config.Trading.SymbolA, rm.config.Trading.SymbolB)
-	ticker, err := rm.exchange.FetchTicker(symbol)
+	ticker, err := rm.fetchTicker(symbol)
 	if err != nil {
 		logger.Debugf("[风险管理] 获取价格失败: %v", err)
 		return
 	}
 
-	currentPrice := ticker.Last
+	currentPrice := ticker.Price(rm.priceSource())
 
 	// 【修复】增强调试日志 - 显示详细的止损状态
 	rm.mu.Lock()
-	logger.Debugf("[风险管理] 监控中 - 方向:%s, 当前价:%.2f, 开仓价:%.2f, 止损:%.2f, 止盈:%.2f, 移动止损:%.2f",
-		pos.Side, currentPrice, pos.EntryPrice, pos.StopLoss, pos.TakeProfit, pos.TrailingStop)
+	logger.Debugf("[风险管理] 监控中 - 方向:%s, 当前价(%s):%.2f, 开仓价:%.2f, 止损:%.2f, 止盈:%.2f, 移动止损:%.2f",
+		pos.Side, rm.priceSource(), currentPrice, pos.EntryPrice, pos.StopLoss, pos.TakeProfit, pos.TrailingStop)
 
 	// 计算当前盈亏百分比（基于保证金）
-	var currentPnL float64
 	var pnlPercent float64
-	if pos.Side == "long" {
-		currentPnL = (currentPrice - pos.EntryPrice) * pos.Size
-	} else {
-		currentPnL = (pos.EntryPrice - currentPrice) * pos.Size
-	}
+	currentPnL := unrealizedPnL(pos, currentPrice)
 
 	positionValue := pos.EntryPrice * pos.Size
 	margin := positionValue / float64(pos.Leverage)
@@ -232,18 +300,20 @@ func (rm *RiskManager) checkPosition() {
 	}
 
 	// 计算止损阈值（负数表示亏损）
-	stopLossThreshold := -rm.config.Trading.RiskManagement.StopLossPercent
+	stopLossPercent, _ := rm.stopLossTakeProfitPercentLocked()
+	stopLossThreshold := -stopLossPercent
 	stopLossUSDT := margin * (stopLossThreshold / 100)
 
 	// 计算距离止损还有多少空间
 	distanceToStopLoss := pnlPercent - stopLossThreshold
 
-	logger.Debugf("[风险管理] 当前浮动盈亏: %.2f USDT (%.2f%%), 止损阈值: %.2f%% (%.2f USDT), 距离止损: %.2f%%",
-		currentPnL/100, pnlPercent, stopLossThreshold, stopLossUSDT/100, distanceToStopLoss)
+	logger.Debugf("[风险管理] 当前浮动盈亏: %.4f %s (%.2f%%), 止损阈值: %.2f%% (%.4f %s), 距离止损: %.2f%%, 距离强平: %.2f%%",
+		currentPnL, rm.config.Trading.SymbolB, pnlPercent, stopLossThreshold, stopLossUSDT, rm.config.Trading.SymbolB, distanceToStopLoss, pos.LiquidationDistance(currentPrice))
 	rm.mu.Unlock()
 
 	// 更新最高价和最低价
 	rm.mu.Lock()
+	before := newRiskState(pos)
 	if currentPrice > pos.HighestPrice {
 		pos.HighestPrice = currentPrice
 	}
@@ -253,13 +323,20 @@ func (rm *RiskManager) checkPosition() {
 	rm.mu.Unlock()
 
 	// 更新移动止损
-	if rm.config.Trading.RiskManagement.EnableTrailingStop {
+	if rm.localTrailingStop() {
 		rm.updateTrailingStop(pos, currentPrice)
 	}
 
+	// 最高/最低价或移动止损变化时保存风控状态（重启后恢复）
+	rm.mu.Lock()
+	if pos == rm.currentPosition && (pos.HighestPrice != before.HighestPrice || pos.LowestPrice != before.LowestPrice || pos.TrailingStop != before.TrailingStop) {
+		rm.saveStateLocked(pos)
+	}
+	rm.mu.Unlock()
+
 	// 检查是否触发止盈止损
-	if rm.shouldClosePosition(pos, currentPrice) {
-		rm.closePosition(pos, currentPrice)
+	if rm.shouldClosePosition(pos, currentPrice) || rm.shouldCloseForDailyLoss(currentPnL) || rm.shouldExitEarly(ctx, pos, currentPrice) {
+		rm.closePosition(ctx, pos, currentPrice)
 	}
 }
 
@@ -300,7 +377,7 @@ func (rm *RiskManager) shouldClosePosition(pos *models.Position, currentPrice fl
 		// 多仓止损：价格跌破止损线
 		if cfg.EnableStopLoss {
 			// 优先检查移动止损（必须 > 0 才有效）
-			if cfg.EnableTrailingStop && pos.TrailingStop > 0 && currentPrice <= pos.TrailingStop {
+			if rm.localTrailingStop() && pos.TrailingStop > 0 && currentPrice <= pos.TrailingStop {
 				logger.Printf("[风险管理] ⚠️ 触发移动止损 - 当前价:%.2f <= 移动止损:%.2f",
 					currentPrice, pos.TrailingStop)
 				return true
@@ -324,7 +401,7 @@ func (rm *RiskManager) shouldClosePosition(pos *models.Position, currentPrice fl
 		// 空仓止损：价格涨破止损线
 		if cfg.EnableStopLoss {
 			// 优先检查移动止损（必须 > 0 才有效）
-			if cfg.EnableTrailingStop && pos.TrailingStop > 0 && currentPrice >= pos.TrailingStop {
+			if rm.localTrailingStop() && pos.TrailingStop > 0 && currentPrice >= pos.TrailingStop {
 				logger.Printf("[风险管理] ⚠️ 触发移动止损 - 当前价:%.2f >= 移动止损:%.2f",
 					currentPrice, pos.TrailingStop)
 				return true
@@ -349,25 +426,47 @@ func (rm *RiskManager) shouldClosePosition(pos *models.Position, currentPrice fl
 }
 
 // closePosition 平仓
-func (rm *RiskManager) closePosition(pos *models.Position, currentPrice float64) {
+func (rm *RiskManager) closePosition(ctx context.Context, pos *models.Position, currentPrice float64) {
 	logger.Printf("[风险管理] 正在平仓 - 方向:%s, 数量:%.8f, 开仓价:%.2f, 当前价:%.2f",
 		pos.Side, pos.Size, pos.EntryPrice, currentPrice)
+	rm.recordEvent(TradeEvent{Type: EventRiskAction, Side: pos.Side, Price: currentPrice, Size: pos.Size, Reason: "风控平仓"})
 
 	symbol := rm.exchange.ParseSymbols(rm.config.Trading.SymbolA, rm.config.Trading.SymbolB)
 
-	var side string
-	var posSide string
+	// 获取下单权（风控平仓优先于策略开仓）
+	if rm.executor != nil {
+		release := rm.executor.Acquire(PriorityRisk)
+		defer release()
 
+		// 等待期间持仓可能已被刷新或被策略变更：刷新只替换对象，按新对象平仓；
+		// 方向、开仓价或数量变化时按新持仓重新判断是否触发
+		rm.mu.Lock()
+		current := rm.currentPosition
+		rm.mu.Unlock()
+		if current == nil {
+			logger.Println("[风险管理] 等待下单期间持仓已平，取消本次平仓")
+			return
+		}
+		if samePosition(current, pos) {
+			pos = current
+		} else {
+			logger.Printf("[风险管理] 等待下单期间持仓已变化 - 方向:%s, 数量:%.8f, 开仓价:%.2f，按新持仓重新判断",
+				current.Side, current.Size, current.EntryPrice)
+			if !rm.shouldClosePosition(current, currentPrice) && !rm.shouldCloseForDailyLoss(unrealizedPnL(current, currentPrice)) {
+				logger.Println("[风险管理] 新持仓未触发止盈止损，取消本次平仓")
+				return
+			}
+			pos = current
+		}
+	}
+
+	side, posSide := "buy", "short"
 	if pos.Side == "long" {
-		side = "sell"
-		posSide = "long"
-	} else {
-		side = "buy"
-		posSide = "short"
+		side, posSide = "sell", "long"
 	}
 
 	// 执行平仓
-	err := rm.exchange.PlaceOrder(
+	orderID, err := rm.exchange.PlaceOrder(
 		symbol,
 		side,
 		pos.Size,
@@ -377,29 +476,82 @@ func (rm *RiskManager) closePosition(pos *models.Position, currentPrice float64)
 		},
 	)
 
+	if errors.Is(err, exchange.ErrTradingDisabled) {
+		logger.Printf("[风险管理] 测试模式 - 仅模拟平仓，未向交易所下单")
+		return
+	}
+	rm.recordEvent(orderEvent(side, pos.Size, orderID, err))
 	if err != nil {
-		logger.Printf("[风险管理] ❌ 平仓失败: %v", err)
+		if exchange.IsRetryable(err) {
+			logger.Printf("[风险管理] ❌ 平仓失败(临时性错误，下次检查将重试): %v", err)
+		} else {
+			logger.Printf("[风险管理] ❌ 平仓被拒绝(%s)，请人工检查: %v", exchange.ErrorKindOf(err), err)
+		}
+		rm.publish(notify.LevelError, "风控平仓失败",
+			fmt.Sprintf("%s %s仓 触发价:%.2f, 错误: %v", rm.tradingPair, pos.Side, currentPrice, err))
 		return
 	}
 
-	// 计算盈亏
-	var pnl float64
-	var pnlPercent float64
+	if rm.executor != nil {
+		rm.executor.RecordRiskClose(pos.Side)
+	}
 
-	if pos.Side == "long" {
-		pnl = (currentPrice - pos.EntryPrice) * pos.Size
+	// 确认平仓订单成交情况（查询失败或无成交均价时按成交记录补全）
+	order, err := rm.exchange.FetchOrder(symbol, orderID)
+	if err != nil {
+		logger.Printf("[风险管理] 查询平仓订单 %s 失败: %v", orderID, err)
+		order = nil
 	} else {
-		pnl = (pos.EntryPrice - currentPrice) * pos.Size
+		logger.Printf("[风险管理] 平仓订单 %s 状态: %s, 成交: %.8f/%.8f, 均价: %.2f",
+			order.OrderID, order.State, order.FilledSize, order.Size, order.AvgPrice)
+	}
+	order = fillFromTrades(rm.exchange, symbol, orderID, order)
+	if order != nil {
+		rm.recordEvent(fillEvent(order))
+	}
+
+	// 按实际成交均价计算盈亏（无成交信息时使用触发价格），记入交易日志时扣除开平仓手续费
+	exitPrice := currentPrice
+	if order != nil && order.AvgPrice > 0 {
+		exitPrice = order.AvgPrice
+	}
+	pnl := (exitPrice - pos.EntryPrice) * pos.Size
+	if pos.Side == "short" {
+		pnl = -pnl
+	}
+
+	rm.cancelBracket("风控平仓")
+	if rm.journal == nil {
+		rm.recordEvent(closeEvent(nil, exitPrice, "风控平仓"))
+	} else if entry := rm.journal.OpenLeg(rm.tradingPair, rm.leg); entry != nil {
+		rm.mu.Lock()
+		feeRate := rm.feeRate
+		rm.mu.Unlock()
+		fee := feeCost(order, feeRate, exitPrice, entry.Size, rm.config.Trading.SymbolA)
+		logger.Printf("[风险管理] 平仓手续费: %.4f %s", fee, rm.config.Trading.SymbolB)
+		closed := rm.journal.CloseLeg(rm.tradingPair, rm.leg, exitPrice, fee, "风控平仓")
+		if closed != nil {
+			pnl = closed.NetPnL
+		}
+		rm.recordEvent(closeEvent(closed, exitPrice, "风控平仓"))
+		logRealizedTotals(rm.journal, rm.calendar, rm.tradingPair, rm.config.Trading.SymbolB)
+		recordVariantTrade(rm.aiClient, closed)
+		requestPostMortem(ctx, rm.config, rm.exchange, rm.aiClient, rm.journal, closed, "")
 	}
 
 	// 计算保证金收益率
+	var pnlPercent float64
 	positionValue := pos.EntryPrice * pos.Size
 	margin := positionValue / float64(pos.Leverage)
 	if margin > 0 {
 		pnlPercent = (pnl / margin) * 100
 	}
 
-	logger.Printf("[风险管理] ✅ 平仓成功 - 盈亏: %.2f USDT (%.2f%%)", pnl/100, pnlPercent)
+	logger.Printf("[风险管理] ✅ 平仓成功 - 平仓价: %.2f, 盈亏: %.4f %s (%.2f%%)", exitPrice, pnl, rm.config.Trading.SymbolB, pnlPercent)
+	rm.portfolio.Update(rm.legKey(), nil, 0)
+	rm.publish(notify.LevelInfo, "风控平仓",
+		fmt.Sprintf("%s %s仓 开仓价:%.2f, 平仓价:%.2f, 盈亏: %.4f %s (%.2f%%)",
+			rm.tradingPair, pos.Side, pos.EntryPrice, exitPrice, pnl, rm.config.Trading.SymbolB, pnlPercent))
 
 	// 获取最新余额
 	time.Sleep(1 * time.Second)
@@ -411,5 +563,26 @@ func (rm *RiskManager) closePosition(pos *models.Position, currentPrice float64)
 	// 清空持仓
 	rm.mu.Lock()
 	rm.currentPosition = nil
+	rm.saveStateLocked(nil)
 	rm.mu.Unlock()
 }
+
+// samePosition 判断两次获取的持仓是否为同一持仓（方向、开仓价和数量均未变化）
+func samePosition(a, b *models.Position) bool {
+	return a.Side == b.Side && a.EntryPrice == b.EntryPrice && a.Size == b.Size
+}
+
+// unrealizedPnL 按当前价格计算持仓浮动盈亏
+func unrealizedPnL(pos *models.Position, currentPrice float64) float64 {
+	if pos.Side == "long" {
+		return (currentPrice - pos.EntryPrice) * pos.Size
+	}
+	return (pos.EntryPrice - currentPrice) * pos.Size
+}
+
+// publish 发送通知（未配置通知时忽略）
+func (rm *RiskManager) publish(level notify.Level, title, message string) {
+	if rm.notifier != nil {
+		rm.notifier.Publish(level, title, message)
+	}
+}
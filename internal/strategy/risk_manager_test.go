@@ -0,0 +1,85 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// 风控平仓等待下单权期间持仓被刷新或变更：刷新不取消平仓，持仓变化时按新持仓重新判断
+func TestRiskCloseAfterPositionChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		update    func(pos *models.Position) *models.Position // 等待期间更新的持仓
+		wantClose float64                                     // 期望平仓数量（0表示取消平仓）
+	}{
+		{name: "刷新持仓对象", update: func(pos *models.Position) *models.Position {
+			refreshed := *pos
+			return &refreshed
+		}, wantClose: 1},
+		{name: "持仓已平", update: func(pos *models.Position) *models.Position { return nil }},
+		{name: "新持仓未触发止损", update: func(pos *models.Position) *models.Position {
+			return &models.Position{Side: "long", Size: 1, EntryPrice: 85, Leverage: pos.Leverage}
+		}},
+		{name: "新持仓仍触发止损", update: func(pos *models.Position) *models.Position {
+			changed := *pos
+			changed.Size = 0.5
+			return &changed
+		}, wantClose: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.RiskManagement.EnableStopLoss = true
+			cfg.Trading.RiskManagement.StopLossPercent = 10
+			m, symbol := newTestExchange(cfg)
+			if _, err := m.PlaceOrder(symbol, "buy", 1, map[string]interface{}{"posSide": "long"}); err != nil {
+				t.Fatal(err)
+			}
+			pos, _ := m.FetchPosition(symbol)
+			rm := NewRiskManager(cfg, m, testPair)
+			rm.executor = NewExecutionCoordinator()
+			rm.UpdatePosition(pos)
+			m.SetPrice(symbol, 89)
+
+			// 策略持有下单权，风控平仓排队等待
+			release := rm.executor.Acquire(PriorityStrategy)
+			done := make(chan struct{})
+			go func() {
+				rm.closePosition(context.Background(), pos, 89)
+				close(done)
+			}()
+			deadline := time.Now().Add(time.Second)
+			for !riskWaiting(rm.executor) {
+				if time.Now().After(deadline) {
+					t.Fatal("风控平仓未等待下单权")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			rm.UpdatePosition(tt.update(pos))
+			release()
+			<-done
+
+			orders := m.Orders()[1:]
+			if tt.wantClose == 0 {
+				if len(orders) != 0 {
+					t.Fatalf("平仓订单 = %+v, 期望取消平仓", orders)
+				}
+				return
+			}
+			if len(orders) != 1 || !approxEqual(orders[0].Size, tt.wantClose) {
+				t.Fatalf("平仓订单 = %+v, 期望平仓 %.2f", orders, tt.wantClose)
+			}
+		})
+	}
+}
+
+func riskWaiting(c *ExecutionCoordinator) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.waitingRisk > 0
+}
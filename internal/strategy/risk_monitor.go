@@ -0,0 +1,243 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+// monitoredRisk 被统一监控的风险管理器
+type monitoredRisk struct {
+	manager   *RiskManager
+	nextCheck time.Time // 下次检查时间（由 RiskMonitor.mu 保护）
+	checking  bool      // 检查进行中（由 RiskMonitor.mu 保护），重启后新循环跳过旧循环中尚未返回的检查
+}
+
+// restartWait 重启时等待旧循环退出的最长时间，超时后旧循环中卡住的检查返回前新循环跳过该交易对
+const restartWait = 5 * time.Second
+
+// RiskMonitor 统一风控循环 - 用单个goroutine按tick遍历所有交易对的风险管理器，
+// 每个交易对仍按各自配置的检查间隔执行，价格通过共享行情总线获取
+type RiskMonitor struct {
+	priceBus     *PriceBus
+	managers     []*monitoredRisk
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	done         chan struct{} // 当前循环退出时关闭
+	running      bool
+	lastActivity time.Time
+	mu           sync.Mutex
+}
+
+// NewRiskMonitor 创建统一风控循环
+func NewRiskMonitor(priceBus *PriceBus) *RiskMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RiskMonitor{
+		priceBus: priceBus,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Add 添加风险管理器（使用默认行情总线）
+func (m *RiskMonitor) Add(rm *RiskManager) {
+	m.AddWithPriceBus(rm, m.priceBus)
+}
+
+// AddWithPriceBus 添加风险管理器并指定行情总线（交易对的行情来自不同交易所时使用）
+func (m *RiskMonitor) AddWithPriceBus(rm *RiskManager, bus *PriceBus) {
+	rm.SetPriceBus(bus)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.managers = append(m.managers, &monitoredRisk{
+		manager:   rm,
+		nextCheck: time.Now(),
+	})
+	rm.logSettings()
+}
+
+// Len 获取监控的交易对数量
+func (m *RiskMonitor) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.managers)
+}
+
+// Start 启动统一风控循环
+func (m *RiskMonitor) Start() error {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return fmt.Errorf("统一风控循环已在运行中")
+	}
+	if len(m.managers) == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = true
+	m.lastActivity = time.Now()
+	ctx := m.ctx
+	m.done = make(chan struct{})
+	done := m.done
+	m.mu.Unlock()
+
+	logger.Printf("[风险管理] 统一风控循环启动 - 交易对数量: %d, tick: %s", m.Len(), m.tickInterval())
+
+	m.wg.Add(1)
+	go m.loop(ctx, done)
+
+	return nil
+}
+
+// Stop 停止统一风控循环
+func (m *RiskMonitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	// 先标记停止，重启中的调用不再启动新循环
+	m.running = false
+	cancel := m.cancel
+	m.mu.Unlock()
+
+	cancel()
+	m.wg.Wait()
+
+	logger.Println("[风险管理] 统一风控循环已停止")
+}
+
+// Restart 重启统一风控循环（用于循环卡死时恢复）
+// 取消旧循环并最多等待 restartWait 让其退出；旧循环中卡住的检查返回前，新循环跳过该交易对，不会并发检查
+func (m *RiskMonitor) Restart() error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return fmt.Errorf("统一风控循环未运行")
+	}
+	m.cancel()
+	old := m.done
+	m.mu.Unlock()
+
+	logger.Println("[风险管理] 重启统一风控循环...")
+	select {
+	case <-old:
+	case <-time.After(restartWait):
+		logger.Warnf("[风险管理] ⚠️ 旧循环 %s 内未退出，检查中的交易对在检查返回前跳过", restartWait)
+	}
+
+	m.mu.Lock()
+	if !m.running {
+		// 等待期间已停止
+		m.mu.Unlock()
+		return nil
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.lastActivity = time.Now()
+	ctx := m.ctx
+	m.done = make(chan struct{})
+	done := m.done
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.loop(ctx, done)
+
+	return nil
+}
+
+// LastActivity 获取最近一次完成tick的时间
+func (m *RiskMonitor) LastActivity() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastActivity
+}
+
+// tickInterval 取所有交易对中最短的检查间隔作为tick
+func (m *RiskMonitor) tickInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var tick time.Duration
+	for _, mr := range m.managers {
+		interval := mr.manager.CheckInterval()
+		if tick == 0 || interval < tick {
+			tick = interval
+		}
+	}
+	if tick == 0 {
+		tick = 10 * time.Second
+	}
+	return tick
+}
+
+// loop 统一风控主循环
+func (m *RiskMonitor) loop(ctx context.Context, done chan struct{}) {
+	defer m.wg.Done()
+	defer close(done)
+
+	// 启动后立即检查一次现有持仓，不等待第一个tick
+	m.tick(ctx)
+
+	ticker := time.NewTicker(m.tickInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.tick(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// tick 遍历到期的交易对执行检查
+func (m *RiskMonitor) tick(ctx context.Context) {
+	m.mu.Lock()
+	managers := append([]*monitoredRisk(nil), m.managers...)
+	m.mu.Unlock()
+
+	now := time.Now()
+	for _, mr := range managers {
+		if ctx.Err() != nil {
+			return
+		}
+		if !m.claim(mr, now) {
+			continue
+		}
+		m.check(ctx, mr)
+	}
+
+	m.mu.Lock()
+	m.lastActivity = time.Now()
+	m.mu.Unlock()
+}
+
+// claim 交易对到期且没有进行中的检查时标记为检查中并安排下次检查
+func (m *RiskMonitor) claim(mr *monitoredRisk, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mr.checking || now.Before(mr.nextCheck) {
+		return false
+	}
+	mr.checking = true
+	mr.nextCheck = now.Add(mr.manager.CheckInterval())
+	return true
+}
+
+// check 执行检查，返回后清除检查中标记
+// 检查中发起的AI询问和复盘使用循环的 ctx，停止或重启循环时一并取消
+func (m *RiskMonitor) check(ctx context.Context, mr *monitoredRisk) {
+	defer func() {
+		m.mu.Lock()
+		mr.checking = false
+		m.mu.Unlock()
+	}()
+	mr.manager.checkPosition(ctx)
+}
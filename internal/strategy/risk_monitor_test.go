@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+)
+
+// 检查卡住时（如重启后旧循环仍在检查）其他循环跳过该交易对，不并发检查
+func TestRiskMonitorSkipsInFlightCheck(t *testing.T) {
+	cfg := newTestConfig(config.TradingModeFutures)
+	m, _ := newTestExchange(cfg)
+	rm := NewRiskManager(cfg, m, testPair)
+	// 备用实例的检查在读取接管状态时阻塞，模拟卡住的检查
+	rm.failover = NewFailover(nil, config.FailoverConfig{Role: FailoverRoleStandby}, "test")
+	monitor := NewRiskMonitor(nil)
+	monitor.Add(rm)
+	mr := monitor.managers[0]
+
+	rm.failover.mu.Lock()
+	stuck := make(chan struct{})
+	go func() {
+		monitor.tick(context.Background())
+		close(stuck)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for !monitorChecking(monitor, mr) {
+		if time.Now().After(deadline) {
+			t.Fatal("第一个循环未开始检查")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 已到检查时间，但上次检查尚未返回：跳过且不改动下次检查时间
+	monitor.mu.Lock()
+	mr.nextCheck = time.Time{}
+	monitor.mu.Unlock()
+	monitor.tick(context.Background())
+	monitor.mu.Lock()
+	next := mr.nextCheck
+	monitor.mu.Unlock()
+	if !next.IsZero() {
+		t.Fatalf("下次检查时间 = %s, 期望检查中的交易对被跳过", next)
+	}
+
+	rm.failover.mu.Unlock()
+	<-stuck
+	if monitorChecking(monitor, mr) {
+		t.Fatal("检查返回后仍标记为检查中")
+	}
+	monitor.tick(context.Background())
+	monitor.mu.Lock()
+	next = mr.nextCheck
+	monitor.mu.Unlock()
+	if next.IsZero() {
+		t.Fatal("检查返回后未再次检查")
+	}
+}
+
+func TestRiskMonitorRestart(t *testing.T) {
+	cfg := newTestConfig(config.TradingModeFutures)
+	m, _ := newTestExchange(cfg)
+	monitor := NewRiskMonitor(nil)
+	monitor.Add(NewRiskManager(cfg, m, testPair))
+
+	if err := monitor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// 空闲的旧循环立即退出，重启无需等待 restartWait
+	start := time.Now()
+	if err := monitor.Restart(); err != nil {
+		t.Fatalf("重启失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= restartWait {
+		t.Fatalf("重启耗时 %s, 期望旧循环退出后立即启动", elapsed)
+	}
+	monitor.Stop()
+	if err := monitor.Restart(); err == nil {
+		t.Fatal("停止后重启成功, 期望报错")
+	}
+}
+
+// 启动后立即检查现有持仓，不等待第一个tick
+func TestRiskMonitorChecksOnStart(t *testing.T) {
+	cfg := newTestConfig(config.TradingModeFutures)
+	cfg.Trading.RiskManagement.EnableStopLoss = true
+	cfg.Trading.RiskManagement.StopLossPercent = 5
+	cfg.Trading.RiskManagement.CheckIntervalSeconds = 3600
+	m, symbol := newTestExchange(cfg)
+	if _, err := m.PlaceOrder(symbol, "buy", 1, map[string]interface{}{"posSide": "long"}); err != nil {
+		t.Fatal(err)
+	}
+	pos, _ := m.FetchPosition(symbol)
+	rm := NewRiskManager(cfg, m, testPair)
+	rm.UpdatePosition(pos)
+	m.SetPrice(symbol, 90)
+
+	monitor := NewRiskMonitor(nil)
+	monitor.Add(rm)
+	if err := monitor.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer monitor.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if left, _ := m.FetchPosition(symbol); left == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("启动后未立即检查触发止损的持仓")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func monitorChecking(m *RiskMonitor, mr *monitoredRisk) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return mr.checking
+}
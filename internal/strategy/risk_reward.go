@@ -0,0 +1,55 @@
+package strategy
+
+import (
+	"fmt"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// riskReward 本次开仓的止损、止盈距离（%）：合约模式取风险管理器将要使用的百分比（含AI建议和波动率缩放），
+// 没有风险管理器时取AI信号给出的止损价、止盈价；任一项缺失或价位于开仓价错误一侧时返回0
+func (bot *TradingBot) riskReward(signal *models.TradeSignal, price float64) (stopLoss, takeProfit float64, source string) {
+	side := signalSide(signal.Signal)
+	if rm := bot.legRisk(side); rm != nil {
+		stopLoss, takeProfit = rm.StopLossTakeProfitPercent()
+		return stopLoss, takeProfit, "止盈止损设置"
+	}
+	if signal.StopLoss <= 0 || signal.TakeProfit <= 0 || price <= 0 {
+		return 0, 0, ""
+	}
+	direction := 1.0
+	if side == "short" {
+		direction = -1.0
+	}
+	stopLoss = direction * (price - signal.StopLoss) / price * 100
+	takeProfit = direction * (signal.TakeProfit - price) / price * 100
+	if stopLoss <= 0 || takeProfit <= 0 {
+		return 0, 0, ""
+	}
+	return stopLoss, takeProfit, "AI建议价位"
+}
+
+// passRiskRewardGate 开仓前按止盈距离/止损距离计算盈亏比，低于 min_risk_reward 时不开仓（缺少止盈或止损时放行）
+func (bot *TradingBot) passRiskRewardGate(signal *models.TradeSignal, marketData *models.MarketData) (bool, string) {
+	minRatio := bot.config.Trading.RiskManagement.MinRiskReward
+	if minRatio <= 0 {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	stopLoss, takeProfit, source := bot.riskReward(signal, marketData.Price)
+	if stopLoss <= 0 || takeProfit <= 0 {
+		logger.Debugf("[DEBUG] [盈亏比] 缺少止盈或止损距离，跳过检查")
+		return true, ""
+	}
+	ratio := takeProfit / stopLoss
+	detail := fmt.Sprintf("盈亏比 %.2f (止盈 %.2f%% / 止损 %.2f%%，来自%s，下限 %.2f)", ratio, takeProfit, stopLoss, source, minRatio)
+	if ratio >= minRatio {
+		return true, detail
+	}
+	logger.Warnf("[盈亏比] ⚠️ %s，跳过开仓", detail)
+	return false, detail
+}
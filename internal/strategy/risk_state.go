@@ -0,0 +1,129 @@
+package strategy
+
+import (
+	"encoding/json"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+)
+
+// riskStateKeyPrefix 持仓风控状态在持久化存储中的键前缀（后接交易对，双向持仓时为 交易对:方向）
+const riskStateKeyPrefix = "risk_state:"
+
+// riskState 持仓的风控状态（止盈止损价、移动止损及持仓期间的最高/最低价）
+type riskState struct {
+	Side         string    `json:"side"`
+	EntryPrice   float64   `json:"entry_price"`
+	StopLoss     float64   `json:"stop_loss"`
+	TakeProfit   float64   `json:"take_profit"`
+	TrailingStop float64   `json:"trailing_stop"`
+	HighestPrice float64   `json:"highest_price"`
+	LowestPrice  float64   `json:"lowest_price"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// newRiskState 记录持仓当前的风控状态
+func newRiskState(pos *models.Position) *riskState {
+	return &riskState{
+		Side:         pos.Side,
+		EntryPrice:   pos.EntryPrice,
+		StopLoss:     pos.StopLoss,
+		TakeProfit:   pos.TakeProfit,
+		TrailingStop: pos.TrailingStop,
+		HighestPrice: pos.HighestPrice,
+		LowestPrice:  pos.LowestPrice,
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// matches 风控状态是否属于该持仓（方向和开仓价相同）
+func (s *riskState) matches(pos *models.Position) bool {
+	return s != nil && pos != nil && s.Side == pos.Side && s.EntryPrice == pos.EntryPrice
+}
+
+// applyTo 将风控状态写回持仓
+func (s *riskState) applyTo(pos *models.Position) {
+	pos.StopLoss = s.StopLoss
+	pos.TakeProfit = s.TakeProfit
+	pos.TrailingStop = s.TrailingStop
+	pos.HighestPrice = s.HighestPrice
+	pos.LowestPrice = s.LowestPrice
+}
+
+// SetRiskStateStore 设置持仓风控状态的持久化存储，并读取上次保存的状态
+// 重启后同一持仓恢复止盈止损价和移动止损，而不是按开仓价重新计算
+func (bot *TradingBot) SetRiskStateStore(s store.Store) {
+	for _, rm := range bot.riskManagers() {
+		rm.setStateStore(s)
+	}
+}
+
+// setStateStore 设置持久化存储并读取上次保存的风控状态（持仓已知时立即恢复）
+func (rm *RiskManager) setStateStore(s store.Store) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.stateStore = s
+	rm.restored = nil
+	if s == nil {
+		return
+	}
+	data, err := s.Get(rm.riskStateKey())
+	if err != nil {
+		logger.Warnf("[风险管理] 读取持仓风控状态失败: %v", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	var state riskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		logger.Warnf("[风险管理] 解析持仓风控状态失败: %v", err)
+		return
+	}
+	if state.Side == "" {
+		return
+	}
+	rm.restored = &state
+	if rm.restoreStateLocked(rm.currentPosition) {
+		rm.checkLiquidationLocked(rm.currentPosition)
+	}
+}
+
+// restoreStateLocked 持仓与上次保存的风控状态一致时恢复该状态，返回是否已恢复（调用方需持有 rm.mu）
+func (rm *RiskManager) restoreStateLocked(pos *models.Position) bool {
+	state := rm.restored
+	if !state.matches(pos) {
+		return false
+	}
+	rm.restored = nil
+	state.applyTo(pos)
+	logger.Printf("[风险管理] 恢复持仓风控状态（保存于 %s）- 方向:%s, 开仓价:%.2f, 止损:%.2f, 止盈:%.2f, 移动止损:%.2f, 最高价:%.2f, 最低价:%.2f",
+		state.UpdatedAt.Local().Format("01-02 15:04:05"), pos.Side, pos.EntryPrice, pos.StopLoss, pos.TakeProfit, pos.TrailingStop, pos.HighestPrice, pos.LowestPrice)
+	return true
+}
+
+// saveStateLocked 保存持仓的风控状态，持仓为nil时清除（调用方需持有 rm.mu）
+func (rm *RiskManager) saveStateLocked(pos *models.Position) {
+	if rm.stateStore == nil {
+		return
+	}
+	data := []byte("{}")
+	if pos != nil {
+		var err error
+		if data, err = json.Marshal(newRiskState(pos)); err != nil {
+			logger.Warnf("[风险管理] 序列化持仓风控状态失败: %v", err)
+			return
+		}
+	}
+	if err := rm.stateStore.Put(rm.riskStateKey(), data); err != nil {
+		logger.Warnf("[风险管理] 保存持仓风控状态失败: %v", err)
+	}
+}
+
+// riskStateKey 风险管理器负责的持仓的风控状态键
+func (rm *RiskManager) riskStateKey() string {
+	return riskStateKeyPrefix + rm.legKey()
+}
@@ -0,0 +1,25 @@
+package strategy
+
+import (
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// passSessionGate 允许的交易时段外或禁止开仓时间段内不开新仓（现货卖出和已持有同方向仓位时放行；
+// 反向信号由 passEntryGates 只平掉反向仓位）
+func (bot *TradingBot) passSessionGate(signal *models.TradeSignal) (bool, string) {
+	if bot.sessions == nil {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	allowed, reason := bot.sessions.Check(time.Now())
+	if !allowed {
+		logger.Warnf("[交易时段] ⚠️ %s，跳过开仓", reason)
+	}
+	return allowed, reason
+}
@@ -0,0 +1,104 @@
+package strategy
+
+import (
+	"encoding/json"
+	"time"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/store"
+)
+
+// signalCacheKeyPrefix 同一根K线的AI信号在持久化存储中的键前缀（后接交易对和周期）
+const signalCacheKeyPrefix = "signal_cache:"
+
+// cachedSignal 最近一根K线的AI信号
+type cachedSignal struct {
+	Timeframe  string              `json:"timeframe"`
+	CandleTime time.Time           `json:"candle_time"` // 信号对应的K线收盘时间
+	Signal     *models.TradeSignal `json:"signal"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// SetSignalCache 设置同一根K线信号复用的持久化存储（启用 reuse_candle_signal 时重启后仍可复用），并恢复最近一次的信号
+func (bot *TradingBot) SetSignalCache(s store.Store) {
+	bot.signalStore = s
+	if s == nil || !bot.config.Trading.ReuseCandleSignal {
+		return
+	}
+
+	data, err := s.Get(bot.signalCacheKey())
+	if err != nil {
+		logger.Warnf("[信号复用] 读取最近信号失败: %v", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	var cached cachedSignal
+	if err := json.Unmarshal(data, &cached); err != nil {
+		logger.Warnf("[信号复用] 解析最近信号失败: %v", err)
+		return
+	}
+	bot.lastSignal = &cached
+}
+
+// reusableSignal 本轮K线已分析过时返回当时的信号（未启用或K线已更新时返回nil）
+func (bot *TradingBot) reusableSignal(marketData *models.MarketData) *models.TradeSignal {
+	if !bot.config.Trading.ReuseCandleSignal || bot.lastSignal == nil {
+		return nil
+	}
+	candleTime, ok := signalCandleTime(marketData)
+	if !ok || bot.lastSignal.Timeframe != bot.config.Trading.Timeframe || !bot.lastSignal.CandleTime.Equal(candleTime) {
+		return nil
+	}
+
+	signal := *bot.lastSignal.Signal
+	logger.Printf("[信号复用] K线 %s 已于 %s 分析过，复用信号 %s (%s)，不再请求AI",
+		candleTime.Local().Format("01-02 15:04"), bot.lastSignal.CreatedAt.Local().Format("15:04:05"), signal.Signal, signal.Confidence)
+	return &signal
+}
+
+// rememberSignal 记录本轮K线的信号（备用信号不记录，下次仍请求AI）
+func (bot *TradingBot) rememberSignal(marketData *models.MarketData, signal *models.TradeSignal) {
+	if !bot.config.Trading.ReuseCandleSignal || signal.IsFallback {
+		return
+	}
+	candleTime, ok := signalCandleTime(marketData)
+	if !ok {
+		return
+	}
+
+	copied := *signal
+	bot.lastSignal = &cachedSignal{
+		Timeframe:  bot.config.Trading.Timeframe,
+		CandleTime: candleTime,
+		Signal:     &copied,
+		CreatedAt:  time.Now(),
+	}
+	if bot.signalStore == nil {
+		return
+	}
+	data, err := json.Marshal(bot.lastSignal)
+	if err != nil {
+		logger.Warnf("[信号复用] 序列化信号失败: %v", err)
+		return
+	}
+	if err := bot.signalStore.Put(bot.signalCacheKey(), data); err != nil {
+		logger.Warnf("[信号复用] 保存信号失败: %v", err)
+	}
+}
+
+// signalCacheKey 交易对和周期的信号缓存键
+func (bot *TradingBot) signalCacheKey() string {
+	return signalCacheKeyPrefix + bot.tradingPair + ":" + bot.config.Trading.Timeframe
+}
+
+// signalCandleTime 信号对应的K线收盘时间（最后一根为未收盘K线，其开盘时间即上一根K线的收盘时间）
+func signalCandleTime(marketData *models.MarketData) (time.Time, bool) {
+	n := len(marketData.KlineData)
+	if n == 0 {
+		return time.Time{}, false
+	}
+	return marketData.KlineData[n-1].Timestamp, true
+}
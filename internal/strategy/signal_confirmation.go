@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"fmt"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// defaultConfirmationCount 默认需要连续相同信号的轮数（含本轮）
+const defaultConfirmationCount = 2
+
+// signalStreak 信号历史末尾与本轮信号连续相同的轮数（本轮信号未计入历史时补计一轮）
+func (bot *TradingBot) signalStreak(signal *models.TradeSignal) int {
+	var history []models.TradeSignal
+	if session := bot.aiClient.GetSessionInfo(bot.tradingPair); session != nil {
+		history = session.History()
+	}
+	var streak int
+	for i := len(history) - 1; i >= 0 && history[i].Signal == signal.Signal; i-- {
+		streak++
+	}
+	if n := len(history); n == 0 || history[n-1].Timestamp != signal.Timestamp {
+		streak++
+	}
+	return streak
+}
+
+// passSignalConfirmationGate 开新仓（含反手）前要求AI连续 count 轮给出相同的信号，持有同方向仓位时不检查
+func (bot *TradingBot) passSignalConfirmationGate(signal *models.TradeSignal) (bool, string) {
+	cfg := bot.config.Trading.SignalConfirmation
+	if !cfg.Enable {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	count := cfg.Count
+	if count <= 0 {
+		count = defaultConfirmationCount
+	}
+	streak := bot.signalStreak(signal)
+	detail := fmt.Sprintf("连续 %d/%d 轮 %s 信号", streak, count, signal.Signal)
+	if streak >= count {
+		return true, detail
+	}
+	logger.Printf("[信号确认] %s，等待下一轮确认后开仓", detail)
+	return false, detail
+}
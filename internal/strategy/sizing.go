@@ -0,0 +1,186 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 仓位计算默认配置
+const (
+	defaultSizingRiskPercent    = 1.0
+	defaultSizingATRMultiple    = 2.0
+	defaultSizingKellyFraction  = 0.5
+	defaultSizingLookbackTrades = 50
+	defaultSizingMinTrades      = 20
+)
+
+// sizingEnabled 是否按 position_sizing 计算交易金额
+func (bot *TradingBot) sizingEnabled() bool {
+	mode := bot.config.Trading.PositionSizing.Mode
+	return mode != "" && mode != "fixed"
+}
+
+// sizingAmount 按 position_sizing.mode 计算交易金额（名义价值，计价币种）及计算过程
+// 账户权益未知或所需数据（止损距离、ATR、历史交易）不足时 ok 为false，使用 amount / amount_equity_percent
+func (bot *TradingBot) sizingAmount() (amount float64, detail string, ok bool) {
+	if !bot.sizingEnabled() {
+		return 0, "", false
+	}
+	if bot.account == nil || bot.account.TotalEquity <= 0 {
+		return 0, "账户权益未知", false
+	}
+	cfg := bot.config.Trading.PositionSizing
+	equity := bot.account.TotalEquity
+	riskPercent := cfg.RiskPercent
+	if riskPercent <= 0 {
+		riskPercent = defaultSizingRiskPercent
+	}
+
+	switch cfg.Mode {
+	case "risk":
+		// 止损触发时亏损 = 交易金额 × 止损百分比，使其等于权益 × 风险百分比
+		var stopLossPercent float64
+		if bot.riskManager != nil {
+			stopLossPercent = bot.riskManager.StopLossPercent()
+		}
+		if stopLossPercent <= 0 {
+			return 0, "未启用止损", false
+		}
+		amount = equity * riskPercent / stopLossPercent
+		detail = fmt.Sprintf("权益 %.2f × 风险 %.2f%% / 止损距离 %.2f%%", equity, riskPercent, stopLossPercent)
+	case "atr":
+		// 以 N 倍ATR作为风险距离，波动越大交易金额越小
+		if bot.atrPercent <= 0 {
+			return 0, "ATR不可用", false
+		}
+		multiple := cfg.ATRMultiple
+		if multiple <= 0 {
+			multiple = defaultSizingATRMultiple
+		}
+		amount = equity * riskPercent / (bot.atrPercent * multiple)
+		detail = fmt.Sprintf("权益 %.2f × 风险 %.2f%% / (%.1f × ATR %.2f%%)", equity, riskPercent, multiple, bot.atrPercent)
+	case "kelly":
+		// 凯利比例为每笔承担的风险占权益的比例，以历史平均亏损幅度作为风险距离
+		kelly, avgLoss, stats, ok := bot.kellyStats()
+		if !ok {
+			return 0, stats, false
+		}
+		fraction := cfg.KellyFraction
+		if fraction <= 0 {
+			fraction = defaultSizingKellyFraction
+		}
+		amount = math.Max(0, equity*kelly*fraction/(avgLoss/100))
+		detail = fmt.Sprintf("%s，凯利 %.2f%% × %.2f，权益 %.2f", stats, kelly*100, fraction, equity)
+	default:
+		return 0, fmt.Sprintf("不支持的仓位计算方式 %s", cfg.Mode), false
+	}
+
+	maxPercent := cfg.MaxEquityPercent
+	if maxPercent <= 0 {
+		maxPercent = 100
+		if bot.config.IsFuturesMode() && bot.config.Trading.Leverage > 1 {
+			maxPercent *= float64(bot.config.Trading.Leverage)
+		}
+	}
+	if maxAmount := equity * maxPercent / 100; amount > maxAmount {
+		amount = maxAmount
+		detail += fmt.Sprintf("，受上限 %.0f%% 权益限制", maxPercent)
+	}
+	return amount, detail, true
+}
+
+// kellyStats 按交易对最近已平仓交易计算凯利比例 W - (1-W)/R（W 胜率，R 平均盈利/平均亏损）和平均亏损幅度（%）
+// 交易日志未启用、已平仓交易不足或没有亏损交易时 ok 为false
+func (bot *TradingBot) kellyStats() (kelly, avgLoss float64, stats string, ok bool) {
+	if bot.journal == nil {
+		return 0, 0, "未启用交易日志", false
+	}
+	cfg := bot.config.Trading.PositionSizing
+	lookback := cfg.LookbackTrades
+	if lookback <= 0 {
+		lookback = defaultSizingLookbackTrades
+	}
+	minTrades := cfg.MinTrades
+	if minTrades <= 0 {
+		minTrades = defaultSizingMinTrades
+	}
+
+	var returns []float64
+	for _, e := range bot.journal.Entries() {
+		if e.Closed && e.TradingPair == bot.tradingPair {
+			returns = append(returns, e.ReturnPct)
+		}
+	}
+	if len(returns) < minTrades {
+		return 0, 0, fmt.Sprintf("已平仓交易 %d 笔，少于 %d 笔", len(returns), minTrades), false
+	}
+	if len(returns) > lookback {
+		returns = returns[len(returns)-lookback:]
+	}
+
+	var wins, losses int
+	var totalWin, totalLoss float64
+	for _, r := range returns {
+		if r > 0 {
+			wins++
+			totalWin += r
+		} else if r < 0 {
+			losses++
+			totalLoss -= r
+		}
+	}
+	if losses == 0 {
+		return 0, 0, fmt.Sprintf("最近 %d 笔交易没有亏损，无法估计风险", len(returns)), false
+	}
+	winRate := float64(wins) / float64(len(returns))
+	avgLoss = totalLoss / float64(losses)
+	var payoff float64
+	if wins > 0 {
+		payoff = (totalWin / float64(wins)) / avgLoss
+	}
+	kelly = winRate
+	if payoff > 0 {
+		kelly -= (1 - winRate) / payoff
+	} else {
+		kelly = 0
+	}
+	stats = fmt.Sprintf("最近 %d 笔交易胜率 %.1f%%、盈亏比 %.2f、平均亏损 %.2f%%", len(returns), winRate*100, payoff, avgLoss)
+	return kelly, avgLoss, stats, true
+}
+
+// logPositionSizing 记录仓位计算过程
+func (bot *TradingBot) logPositionSizing() {
+	if !bot.sizingEnabled() {
+		return
+	}
+	mode := bot.config.Trading.PositionSizing.Mode
+	symbolB := bot.config.Trading.SymbolB
+	amount, detail, ok := bot.sizingAmount()
+	if !ok {
+		logger.Printf("[仓位计算] %s 模式: %s，使用固定交易金额 %.2f %s", mode, detail, bot.baseOrderAmount(), symbolB)
+		return
+	}
+	logger.Printf("[仓位计算] %s 模式: %s = 交易金额 %.2f %s", mode, detail, amount, symbolB)
+}
+
+// passSizingGate 仓位计算结果为0（如凯利比例为负，历史期望不利）时不开仓
+func (bot *TradingBot) passSizingGate(signal *models.TradeSignal) (bool, string) {
+	if !bot.sizingEnabled() {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+
+	bot.logPositionSizing()
+	amount := bot.orderAmount()
+	detail := fmt.Sprintf("交易金额 %.2f %s", amount, bot.config.Trading.SymbolB)
+	if amount <= 0 {
+		logger.Warnf("[仓位计算] ⚠️ %s，跳过开仓", detail)
+		return false, detail
+	}
+	return true, detail
+}
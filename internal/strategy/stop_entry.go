@@ -0,0 +1,245 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+	"dsbot/internal/tracing"
+)
+
+// 突破入场模式
+const (
+	StopEntryModeStop    = "stop"    // 价格触及触发价即入场
+	StopEntryModeConfirm = "confirm" // K线收盘突破触发价后入场
+)
+
+// pendingEntry 等待突破入场的开仓信号
+type pendingEntry struct {
+	signal      *models.TradeSignal
+	marketData  *models.MarketData // 信号所在轮次的市场数据
+	level       float64            // 触发价
+	armedCandle time.Time          // 信号所在K线的开盘时间（confirm 模式只看之后收盘的K线）
+	expiresAt   time.Time
+}
+
+// StopEntryCheckInterval 突破入场触发检查间隔（带默认值）
+func StopEntryCheckInterval(cfg config.StopEntryConfig) time.Duration {
+	if cfg.CheckIntervalSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(cfg.CheckIntervalSeconds) * time.Second
+}
+
+// stopEntrySettings 突破入场参数（带默认值）
+func (bot *TradingBot) stopEntrySettings() (mode string, offsetPercent float64, expiryCandles int) {
+	cfg := bot.config.Trading.StopEntry
+	mode = cfg.Mode
+	if mode == "" {
+		mode = StopEntryModeStop
+	}
+	offsetPercent = cfg.OffsetPercent
+	if offsetPercent <= 0 {
+		offsetPercent = 0.1
+	}
+	expiryCandles = cfg.ExpiryCandles
+	if expiryCandles <= 0 {
+		expiryCandles = 3
+	}
+	return mode, offsetPercent, expiryCandles
+}
+
+// armStopEntry 开仓信号改为等待突破入场，返回 true 表示本轮不下单
+// 仅对新开仓生效（合约无持仓、现货买入），平仓和反手仍立即执行；缺少支撑阻力数据时直接入场
+func (bot *TradingBot) armStopEntry(signal *models.TradeSignal, marketData *models.MarketData) bool {
+	if !bot.config.Trading.StopEntry.Enable {
+		return false
+	}
+	opening := signal.Signal == "BUY"
+	if bot.config.IsFuturesMode() {
+		opening = bot.currentPosition == nil
+	}
+	if !opening {
+		return false
+	}
+
+	if bot.pendingEntry != nil && bot.pendingEntry.signal.Signal == signal.Signal {
+		detail := fmt.Sprintf("已在等待突破 %.2f", bot.pendingEntry.level)
+		bot.checkGate("stop_entry", false, detail)
+		logger.Printf("[突破入场] %s，保持原挂单至 %s", detail, bot.pendingEntry.expiresAt.Format("15:04:05"))
+		bot.skipIntent("等待突破入场")
+		return true
+	}
+
+	n := len(marketData.KlineData)
+	td := marketData.TechnicalData
+	if td == nil || n < 2 {
+		bot.checkGate("stop_entry", true, "缺少支撑阻力数据，直接入场")
+		return false
+	}
+	step := marketData.KlineData[n-1].Timestamp.Sub(marketData.KlineData[n-2].Timestamp)
+	if step <= 0 {
+		bot.checkGate("stop_entry", true, "K线间隔异常，直接入场")
+		return false
+	}
+
+	// 做多挂在阻力位之上，做空挂在支撑位之下；价格已越过该位置时以当前价格为基准
+	mode, offsetPercent, expiryCandles := bot.stopEntrySettings()
+	var level float64
+	if signal.Signal == "BUY" {
+		base := td.Resistance
+		if base < marketData.Price {
+			base = marketData.Price
+		}
+		level = base * (1 + offsetPercent/100)
+	} else {
+		base := td.Support
+		if base <= 0 || base > marketData.Price {
+			base = marketData.Price
+		}
+		level = base * (1 - offsetPercent/100)
+	}
+
+	armedCandle := marketData.KlineData[n-1].Timestamp
+	bot.pendingEntry = &pendingEntry{
+		signal:      signal,
+		marketData:  marketData,
+		level:       level,
+		armedCandle: armedCandle,
+		expiresAt:   armedCandle.Add(time.Duration(expiryCandles+1) * step),
+	}
+
+	detail := fmt.Sprintf("%s 触发价 %.2f", mode, level)
+	bot.checkGate("stop_entry", false, detail)
+	logger.Printf("[突破入场] %s 信号等待%s (当前 %.2f, 触发价 %.2f, 有效至 %s)",
+		signal.Signal, stopEntryDirection(signal.Signal), marketData.Price, level,
+		bot.pendingEntry.expiresAt.Format("2006-01-02 15:04"))
+	bot.skipIntent("等待突破入场")
+	return true
+}
+
+// cancelStopEntry 放弃等待中的突破入场
+func (bot *TradingBot) cancelStopEntry(reason string) {
+	if bot.pendingEntry == nil {
+		return
+	}
+	logger.Printf("[突破入场] 放弃 %s 突破入场 (触发价 %.2f): %s",
+		bot.pendingEntry.signal.Signal, bot.pendingEntry.level, reason)
+	bot.pendingEntry = nil
+}
+
+// CheckStopEntry 检查等待中的突破入场是否触发或过期（由调度器定期调用，交易周期执行期间跳过）
+func (bot *TradingBot) CheckStopEntry(ctx context.Context) error {
+	if !bot.cycleMu.TryLock() {
+		return nil
+	}
+	defer bot.cycleMu.Unlock()
+
+	pending := bot.pendingEntry
+	if pending == nil || ctx.Err() != nil {
+		return nil
+	}
+	if bot.lease != nil && !bot.lease.Held() {
+		bot.cancelStopEntry("交易对由其他工作进程处理")
+		return nil
+	}
+	if time.Now().After(pending.expiresAt) {
+		bot.cancelStopEntry("超过有效期未触发")
+		return nil
+	}
+
+	symbol := bot.exchange.ParseSymbols(bot.config.Trading.SymbolA, bot.config.Trading.SymbolB)
+	ticker, err := bot.exchange.FetchTicker(symbol)
+	if err != nil {
+		return fmt.Errorf("获取行情失败: %w", err)
+	}
+	triggered, detail, err := bot.stopEntryTriggered(pending, symbol, ticker.Last)
+	if err != nil || !triggered {
+		return err
+	}
+
+	// 触发后按最新持仓和余额重新校验
+	if bot.config.IsFuturesMode() {
+		pos, err := bot.fetchPosition(symbol)
+		if err != nil {
+			return fmt.Errorf("获取持仓失败: %w", err)
+		}
+		if bot.hedge {
+			pos = bot.positionForSignal(pending.signal)
+		}
+		if pos != nil {
+			bot.cancelStopEntry("已有持仓")
+			return nil
+		}
+		bot.currentPosition = nil
+	}
+	bot.balance = -1
+	if balance, err := bot.exchange.FetchBalance(bot.config.Trading.SymbolB); err == nil {
+		bot.balance = balance
+	}
+	bot.refreshAccount()
+	bot.pendingEntry = nil
+
+	marketData := *pending.marketData
+	marketData.Price = ticker.Last
+	logger.Printf("[突破入场] ✅ %s 已触发 (%s)，按 %.2f 入场", pending.signal.Signal, detail, ticker.Last)
+
+	bot.span = tracing.StartSpan("stop_entry", nil)
+	bot.span.SetAttribute("trading_pair", bot.tradingPair)
+	bot.riskGeneration = bot.executor.RiskGeneration()
+	bot.applySuggestion(pending.signal, &marketData) // 建议止盈止损价按触发价重新换算距离
+	bot.beginIntent(pending.signal, &marketData)
+	bot.checkGate("stop_entry", true, detail)
+	if !bot.passEntryGates(pending.signal, &marketData) {
+		logger.Printf("[突破入场] 触发时未通过下单前检查，放弃突破入场")
+		bot.span.End()
+		bot.span = nil
+		return nil
+	}
+	err = bot.placeOrder(pending.signal, &marketData)
+	bot.finishIntent(err)
+	bot.span.RecordError(err)
+	bot.span.End()
+	bot.span = nil
+	return err
+}
+
+// stopEntryTriggered 判断突破入场是否触发
+// stop 模式按最新价判断；confirm 模式要求信号K线之后有已收盘的K线收在触发价之外
+func (bot *TradingBot) stopEntryTriggered(pending *pendingEntry, symbol string, last float64) (bool, string, error) {
+	buy := pending.signal.Signal == "BUY"
+	mode, _, _ := bot.stopEntrySettings()
+	if mode != StopEntryModeConfirm {
+		if (buy && last >= pending.level) || (!buy && last > 0 && last <= pending.level) {
+			return true, fmt.Sprintf("最新价 %.2f 突破 %.2f", last, pending.level), nil
+		}
+		return false, "", nil
+	}
+
+	klines, err := bot.exchange.FetchOHLCV(symbol, bot.config.Trading.Timeframe, 3)
+	if err != nil {
+		return false, "", fmt.Errorf("获取K线失败: %w", err)
+	}
+	// 最后一根K线尚未收盘，不参与判断
+	for i := len(klines) - 2; i >= 0; i-- {
+		k := klines[i]
+		if !k.Timestamp.After(pending.armedCandle) {
+			break
+		}
+		if (buy && k.Close >= pending.level) || (!buy && k.Close <= pending.level) {
+			return true, fmt.Sprintf("K线 %s 收盘 %.2f 突破 %.2f", k.Timestamp.Format("15:04"), k.Close, pending.level), nil
+		}
+	}
+	return false, "", nil
+}
+
+// stopEntryDirection 突破方向描述
+func stopEntryDirection(signal string) string {
+	if signal == "BUY" {
+		return "向上突破阻力位"
+	}
+	return "向下跌破支撑位"
+}
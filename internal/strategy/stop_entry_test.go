@@ -0,0 +1,51 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/models"
+)
+
+// 突破入场触发时按触发价重新执行下单前检查（如AI建议的止盈止损价按触发价换算后盈亏比不足）
+func TestCheckStopEntryRechecksGates(t *testing.T) {
+	tests := []struct {
+		name     string
+		trigger  float64
+		wantOpen bool
+	}{
+		{name: "触发价盈亏比达标", trigger: 100.8, wantOpen: true},
+		{name: "触发价盈亏比不足", trigger: 104},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(config.TradingModeFutures)
+			cfg.Trading.RiskManagement.MinRiskReward = 1.5
+			m, symbol := newTestExchange(cfg)
+			bot := NewTradingBot(cfg, m, &stubProvider{})
+
+			signal := &models.TradeSignal{Signal: "BUY", Confidence: "HIGH", StopLoss: 95, TakeProfit: 110}
+			bot.pendingEntry = &pendingEntry{
+				signal:     signal,
+				marketData: &models.MarketData{Price: 100, TrendAnalysis: &models.TrendAnalysis{}},
+				level:      100.5,
+				expiresAt:  time.Now().Add(time.Hour),
+			}
+			m.SetPrice(symbol, tt.trigger)
+
+			if err := bot.CheckStopEntry(context.Background()); err != nil {
+				t.Fatalf("突破入场失败: %v", err)
+			}
+			if bot.pendingEntry != nil {
+				t.Fatal("触发后仍在等待突破入场")
+			}
+			pos, _ := m.FetchPosition(symbol)
+			if (pos != nil) != tt.wantOpen {
+				t.Fatalf("持仓 = %+v, 期望开仓 %v", pos, tt.wantOpen)
+			}
+		})
+	}
+}
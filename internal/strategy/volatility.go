@@ -0,0 +1,80 @@
+package strategy
+
+import (
+	"math"
+
+	"dsbot/internal/logger"
+)
+
+// UpdateVolatility 按最新ATR%更新止盈止损缩放倍数（新开仓时生效）
+func (rm *RiskManager) UpdateVolatility(atrPercent float64) {
+	cfg := rm.config.Trading.RiskManagement.VolatilityScaling
+	if !cfg.Enable || atrPercent <= 0 {
+		return
+	}
+
+	reference := cfg.ReferenceATRPercent
+	if reference <= 0 {
+		reference = 1.0
+	}
+	minScale := cfg.MinScale
+	if minScale <= 0 {
+		minScale = 0.5
+	}
+	maxScale := cfg.MaxScale
+	if maxScale <= 0 {
+		maxScale = 3.0
+	}
+
+	scale := math.Min(math.Max(atrPercent/reference, minScale), maxScale)
+
+	rm.mu.Lock()
+	rm.volatilityScale = scale
+	rm.mu.Unlock()
+
+	logger.Debugf("[风险管理] [%s] 波动率缩放 - ATR: %.2f%%, 参考: %.2f%%, 倍数: %.2f",
+		rm.tradingPair, atrPercent, reference, scale)
+}
+
+// StopLossPercent 新开仓的止损百分比（经波动率缩放，未启用止损时返回0）
+func (rm *RiskManager) StopLossPercent() float64 {
+	if !rm.config.Trading.RiskManagement.EnableStopLoss {
+		return 0
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	stopLoss, _ := rm.stopLossTakeProfitPercentLocked()
+	return stopLoss
+}
+
+// StopLossTakeProfitPercent 新开仓的止损、止盈百分比（经波动率缩放，有AI建议时使用建议值，未启用的一项为0）
+func (rm *RiskManager) StopLossTakeProfitPercent() (stopLoss, takeProfit float64) {
+	cfg := rm.config.Trading.RiskManagement
+	rm.mu.Lock()
+	stopLoss, takeProfit = rm.stopLossTakeProfitPercentLocked()
+	rm.mu.Unlock()
+	if !cfg.EnableStopLoss {
+		stopLoss = 0
+	}
+	if !cfg.EnableTakeProfit {
+		takeProfit = 0
+	}
+	return stopLoss, takeProfit
+}
+
+// stopLossTakeProfitPercentLocked 获取经波动率缩放后的止损、止盈百分比，有AI建议时使用建议值（调用方需持有锁）
+func (rm *RiskManager) stopLossTakeProfitPercentLocked() (stopLoss, takeProfit float64) {
+	cfg := rm.config.Trading.RiskManagement
+	scale := 1.0
+	if cfg.VolatilityScaling.Enable && rm.volatilityScale > 0 {
+		scale = rm.volatilityScale
+	}
+	stopLoss, takeProfit = cfg.StopLossPercent*scale, cfg.TakeProfitPercent*scale
+	if rm.suggestedStopLoss > 0 {
+		stopLoss = rm.suggestedStopLoss
+	}
+	if rm.suggestedTakeProfit > 0 {
+		takeProfit = rm.suggestedTakeProfit
+	}
+	return stopLoss, takeProfit
+}
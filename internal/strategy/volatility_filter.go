@@ -0,0 +1,54 @@
+package strategy
+
+import (
+	"fmt"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/models"
+)
+
+// 波动率过滤默认阈值（ATR%）
+const (
+	defaultMinATRPercent = 0.1
+	defaultMaxATRPercent = 3.0
+)
+
+// VolatilityFilterBounds 允许开仓的ATR%范围
+func VolatilityFilterBounds(cfg config.VolatilityFilterConfig) (minATR, maxATR float64) {
+	minATR, maxATR = cfg.MinATRPercent, cfg.MaxATRPercent
+	if minATR <= 0 {
+		minATR = defaultMinATRPercent
+	}
+	if maxATR <= 0 {
+		maxATR = defaultMaxATRPercent
+	}
+	return minATR, maxATR
+}
+
+// passVolatilityFilter ATR%低于 min_atr_percent（横盘）或高于 max_atr_percent（剧烈波动）时不开仓（ATR未知时放行，平仓不受影响）
+func (bot *TradingBot) passVolatilityFilter(signal *models.TradeSignal, marketData *models.MarketData) (bool, string) {
+	cfg := bot.config.Trading.VolatilityFilter
+	if !cfg.Enable {
+		return true, ""
+	}
+	if !bot.opensNewPosition(signal) {
+		return true, ""
+	}
+	if marketData.TechnicalData == nil || marketData.TechnicalData.ATRPercent <= 0 {
+		return true, ""
+	}
+
+	atr := marketData.TechnicalData.ATRPercent
+	minATR, maxATR := VolatilityFilterBounds(cfg)
+	detail := fmt.Sprintf("ATR %.2f%% (允许范围 %.2f%% ~ %.2f%%)", atr, minATR, maxATR)
+	switch {
+	case atr < minATR:
+		logger.Warnf("[波动率过滤] ⚠️ %s，行情过于平淡，跳过开仓", detail)
+		return false, detail
+	case atr > maxATR:
+		logger.Warnf("[波动率过滤] ⚠️ %s，行情波动过于剧烈，跳过开仓", detail)
+		return false, detail
+	}
+	return true, detail
+}
@@ -7,8 +7,8 @@ import (
 	"time"
 )
 
-// TaskFunc 任务执行函数类型
-type TaskFunc func() error
+// TaskFunc 任务执行函数类型（ctx 在调度器停止或重启时取消，任务应据此中止进行中的请求）
+type TaskFunc func(ctx context.Context) error
 
 // ScheduleMode 调度模式
 type ScheduleMode int
@@ -35,6 +35,7 @@ type Scheduler struct {
 	mu             sync.Mutex         // 互斥锁
 	onError        func(error)        // 错误处理函数
 	onComplete     func()             // 任务完成回调
+	lastActivity   time.Time          // 最近一次活动时间（启动或任务执行结束）
 }
 
 // SchedulerOption 调度器选项
@@ -145,10 +146,32 @@ func (s *Scheduler) Start() error {
 		return fmt.Errorf("调度器已在运行中")
 	}
 	s.running = true
+	s.lastActivity = time.Now()
+	ctx := s.ctx
 	s.mu.Unlock()
 
 	s.wg.Add(1)
-	go s.run()
+	go s.run(ctx)
+
+	return nil
+}
+
+// Restart 重启调度器（用于任务卡死时恢复）
+// 旧的调度循环会被取消，不等待其中卡住的任务返回
+func (s *Scheduler) Restart() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("调度器未运行")
+	}
+	s.cancel()
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.lastActivity = time.Now()
+	ctx := s.ctx
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.run(ctx)
 
 	return nil
 }
@@ -160,9 +183,10 @@ func (s *Scheduler) Stop() {
 		s.mu.Unlock()
 		return
 	}
+	cancel := s.cancel
 	s.mu.Unlock()
 
-	s.cancel()
+	cancel()
 	s.wg.Wait()
 
 	s.mu.Lock()
@@ -170,6 +194,13 @@ func (s *Scheduler) Stop() {
 	s.mu.Unlock()
 }
 
+// LastActivity 获取最近一次活动时间（用于看门狗检测）
+func (s *Scheduler) LastActivity() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastActivity
+}
+
 // IsRunning 检查调度器是否正在运行
 func (s *Scheduler) IsRunning() bool {
 	s.mu.Lock()
@@ -178,40 +209,39 @@ func (s *Scheduler) IsRunning() bool {
 }
 
 // run 运行调度器主循环
-func (s *Scheduler) run() {
+func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
 	// 立即执行一次
 	if s.runImmediately {
-		s.executeTask()
+		s.executeTask(ctx)
 	}
 
 	// 根据模式运行
 	switch s.mode {
 	case ModeInterval:
-		s.runIntervalMode()
+		s.runIntervalMode(ctx)
 	case ModeAlignedWithDelay:
-		s.runAlignedMode()
+		s.runAlignedMode(ctx)
 	}
 }
 
-// runIntervalMode 固定间隔模式
-func (s *Scheduler) runIntervalMode() {
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
-
+// runIntervalMode 固定间隔模式（每轮读取当前间隔，SetInterval 修改后下一轮生效）
+func (s *Scheduler) runIntervalMode(ctx context.Context) {
 	for {
+		timer := time.NewTimer(s.GetInterval())
 		select {
-		case <-ticker.C:
-			s.executeTask()
-		case <-s.ctx.Done():
+		case <-timer.C:
+			s.executeTask(ctx)
+		case <-ctx.Done():
+			timer.Stop()
 			return
 		}
 	}
 }
 
 // runAlignedMode 对齐时间模式
-func (s *Scheduler) runAlignedMode() {
+func (s *Scheduler) runAlignedMode(ctx context.Context) {
 	for {
 		// 计算下次执行时间
 		nextRun := s.calculateNextAlignedTime()
@@ -221,8 +251,8 @@ func (s *Scheduler) runAlignedMode() {
 
 		select {
 		case <-time.After(waitDuration):
-			s.executeTask()
-		case <-s.ctx.Done():
+			s.executeTask(ctx)
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -232,6 +262,11 @@ func (s *Scheduler) runAlignedMode() {
 func (s *Scheduler) calculateNextAlignedTime() time.Time {
 	now := time.Now()
 
+	s.mu.Lock()
+	alignMinutes := s.alignMinutes
+	delay := s.delay
+	s.mu.Unlock()
+
 	// 找到下一个对齐的分钟数
 	currentMinute := now.Minute()
 	var nextMinute int
@@ -239,7 +274,7 @@ func (s *Scheduler) calculateNextAlignedTime() time.Time {
 
 	// 查找大于当前分钟的下一个对齐点
 	found := false
-	for _, min := range s.alignMinutes {
+	for _, min := range alignMinutes {
 		if min > currentMinute {
 			nextMinute = min
 			found = true
@@ -249,7 +284,7 @@ func (s *Scheduler) calculateNextAlignedTime() time.Time {
 
 	// 如果没找到，使用第一个对齐点，并加1小时
 	if !found {
-		nextMinute = s.alignMinutes[0]
+		nextMinute = alignMinutes[0]
 		addHour = true
 	}
 
@@ -271,13 +306,18 @@ func (s *Scheduler) calculateNextAlignedTime() time.Time {
 	}
 
 	// 加上延迟
-	nextTime = nextTime.Add(s.delay)
+	nextTime = nextTime.Add(delay)
 
 	return nextTime
 }
 
 // executeTask 执行任务
-func (s *Scheduler) executeTask() {
+func (s *Scheduler) executeTask(ctx context.Context) {
+	defer func() {
+		s.mu.Lock()
+		s.lastActivity = time.Now()
+		s.mu.Unlock()
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			err := fmt.Errorf("任务执行panic: %v", r)
@@ -285,7 +325,7 @@ func (s *Scheduler) executeTask() {
 		}
 	}()
 
-	if err := s.task(); err != nil {
+	if err := s.task(ctx); err != nil {
 		s.handleError(err)
 	} else {
 		if s.onComplete != nil {
@@ -305,7 +345,7 @@ func (s *Scheduler) handleError(err error) {
 func (s *Scheduler) GetNextRunTime() time.Time {
 	switch s.mode {
 	case ModeInterval:
-		return time.Now().Add(s.interval)
+		return time.Now().Add(s.GetInterval())
 	case ModeAlignedWithDelay:
 		return s.calculateNextAlignedTime()
 	default:
@@ -318,13 +358,31 @@ func (s *Scheduler) GetMode() ScheduleMode {
 	return s.mode
 }
 
-// GetInterval 获取间隔时间（仅ModeInterval模式有效）
+// GetInterval 获取间隔时间
 func (s *Scheduler) GetInterval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.interval
 }
 
+// SetInterval 运行中修改执行间隔（下一次计算执行时间时生效）
+// 对齐时间模式下按新间隔重新计算对齐点
+func (s *Scheduler) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = interval
+	if s.mode == ModeAlignedWithDelay {
+		s.alignMinutes = calculateAlignMinutes(interval)
+	}
+}
+
 // GetAlignMinutes 获取对齐分钟数（仅ModeAlignedWithDelay模式有效）
 func (s *Scheduler) GetAlignMinutes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.alignMinutes
 }
 
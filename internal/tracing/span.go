@@ -0,0 +1,215 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Span 一次操作的计时记录
+type Span struct {
+	tracer   *Tracer
+	traceID  string
+	spanID   string
+	parentID string
+	name     string
+	start    time.Time
+
+	mu     sync.Mutex
+	end    time.Time
+	attrs  []attribute
+	events []event
+	err    error
+	ended  bool
+}
+
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+type event struct {
+	name  string
+	time  time.Time
+	attrs []attribute
+}
+
+// SetAttribute 设置属性（支持 string、bool、整数和浮点数，其余类型按字符串记录）
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attribute{key: key, value: value})
+}
+
+// AddEvent 添加事件（kv 为交替的键和值）
+func (s *Span) AddEvent(name string, kv ...interface{}) {
+	if s == nil {
+		return
+	}
+	e := event{name: name, time: time.Now()}
+	for i := 0; i+1 < len(kv); i += 2 {
+		e.attrs = append(e.attrs, attribute{key: fmt.Sprint(kv[i]), value: kv[i+1]})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+}
+
+// RecordError 记录错误（span 状态标记为错误）
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End 结束 span 并加入导出队列（重复调用只生效一次）
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	s.tracer.enqueue(s)
+}
+
+// TraceID trace ID（用于日志关联，未启用追踪时为空）
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.traceID
+}
+
+// OTLP JSON 结构（字段名遵循 OTLP/HTTP JSON 编码）
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"` // 0 未设置, 1 成功, 2 错误
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"` // 1 内部操作
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+// encodeOTLP 将一批 span 编码为 OTLP ExportTraceServiceRequest JSON
+func encodeOTLP(serviceName string, spans []*Span) ([]byte, error) {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		o := otlpSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			ParentSpanID:      s.parentID,
+			Name:              s.name,
+			Kind:              1,
+			StartTimeUnixNano: unixNano(s.start),
+			EndTimeUnixNano:   unixNano(s.end),
+			Attributes:        encodeAttributes(s.attrs),
+			Status:            otlpStatus{Code: 1},
+		}
+		for _, e := range s.events {
+			o.Events = append(o.Events, otlpEvent{
+				TimeUnixNano: unixNano(e.time),
+				Name:         e.name,
+				Attributes:   encodeAttributes(e.attrs),
+			})
+		}
+		if s.err != nil {
+			o.Status = otlpStatus{Code: 2, Message: s.err.Error()}
+		}
+		s.mu.Unlock()
+		out = append(out, o)
+	}
+
+	request := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": encodeAttributes([]attribute{{key: "service.name", value: serviceName}}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]string{"name": "dsbot/tracing"},
+						"spans": out,
+					},
+				},
+			},
+		},
+	}
+	return json.Marshal(request)
+}
+
+// encodeAttributes 将属性转换为 OTLP KeyValue
+func encodeAttributes(attrs []attribute) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpAnyValue
+		switch value := a.value.(type) {
+		case string:
+			v.StringValue = &value
+		case bool:
+			v.BoolValue = &value
+		case int:
+			s := strconv.Itoa(value)
+			v.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &value
+		default:
+			s := fmt.Sprint(value)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: a.key, Value: v})
+	}
+	return out
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
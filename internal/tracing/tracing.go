@@ -0,0 +1,208 @@
+// Package tracing 交易周期链路追踪
+// 按 OpenTelemetry 数据模型记录 span，并通过 OTLP/HTTP (JSON) 批量导出，
+// 可直接发送到 Jaeger（1.35+）、Grafana Tempo 或 OpenTelemetry Collector 的 /v1/traces 接口
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/config"
+	"dsbot/internal/logger"
+	"dsbot/internal/nets"
+)
+
+// 默认配置
+const (
+	DefaultEndpoint    = "http://localhost:4318/v1/traces"
+	DefaultServiceName = "dsbot"
+)
+
+const (
+	exportBatchSize = 256             // 单次导出的最大 span 数
+	exportInterval  = 5 * time.Second // 导出间隔
+	queueSize       = 2048            // 待导出队列容量（队列满时丢弃新 span）
+)
+
+// tracer 全局追踪器（未初始化时所有 span 操作为空操作）
+var (
+	tracer   *Tracer
+	tracerMu sync.RWMutex
+)
+
+// Tracer span 收集与导出
+type Tracer struct {
+	endpoint    string
+	serviceName string
+	headers     map[string]string
+	httpClient  *nets.HttpClient
+
+	queue chan *Span
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Init 按配置初始化全局追踪器（未启用时不做任何事）
+func Init(cfg *config.TracingConfig) error {
+	if !cfg.Enable {
+		return nil
+	}
+
+	httpClient, err := nets.NewHttpClient(10*time.Second, nets.DefaultProxyURL)
+	if err != nil {
+		return err
+	}
+
+	t := &Tracer{
+		endpoint:    cfg.Endpoint,
+		serviceName: cfg.ServiceName,
+		headers:     map[string]string{"Content-Type": "application/json"},
+		httpClient:  httpClient,
+		queue:       make(chan *Span, queueSize),
+		done:        make(chan struct{}),
+	}
+	if t.endpoint == "" {
+		t.endpoint = DefaultEndpoint
+	}
+	if t.serviceName == "" {
+		t.serviceName = DefaultServiceName
+	}
+	for k, v := range cfg.Headers {
+		t.headers[k] = v
+	}
+
+	t.wg.Add(1)
+	go t.loop()
+
+	tracerMu.Lock()
+	tracer = t
+	tracerMu.Unlock()
+
+	logger.Printf("链路追踪: 已启用 (OTLP %s, 服务名 %s)", t.endpoint, t.serviceName)
+	return nil
+}
+
+// Shutdown 停止全局追踪器并导出剩余 span
+func Shutdown() {
+	tracerMu.Lock()
+	t := tracer
+	tracer = nil
+	tracerMu.Unlock()
+
+	if t == nil {
+		return
+	}
+	close(t.done)
+	t.wg.Wait()
+}
+
+// Enabled 是否已启用链路追踪
+func Enabled() bool {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return tracer != nil
+}
+
+// StartSpan 开始一个 span（parent 为 nil 时开始新的 trace；未启用追踪时返回 nil，
+// Span 的所有方法都可以在 nil 上安全调用）
+func StartSpan(name string, parent *Span) *Span {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+	if t == nil {
+		return nil
+	}
+
+	s := &Span{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+		spanID: randomHex(8),
+	}
+	if parent != nil {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		s.traceID = randomHex(16)
+	}
+	return s
+}
+
+// enqueue 结束的 span 加入导出队列（队列满时丢弃，避免阻塞交易流程）
+func (t *Tracer) enqueue(s *Span) {
+	select {
+	case t.queue <- s:
+	default:
+		logger.Debugf("[DEBUG] 链路追踪队列已满，丢弃 span %s", s.name)
+	}
+}
+
+// loop 按批次或间隔导出 span
+func (t *Tracer) loop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.export(batch); err != nil {
+			logger.Warnf("[追踪] 导出 %d 个 span 失败: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s := <-t.queue:
+			batch = append(batch, s)
+			if len(batch) >= exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.done:
+			for {
+				select {
+				case s := <-t.queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// export 以 OTLP/HTTP JSON 格式发送一批 span
+func (t *Tracer) export(spans []*Span) error {
+	body, err := encodeOTLP(t.serviceName, spans)
+	if err != nil {
+		return err
+	}
+	resp, err := t.httpClient.QueryRaw("POST", t.endpoint, t.headers, body)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(resp.Body))
+	}
+	return nil
+}
+
+// randomHex 生成 n 字节随机ID的十六进制表示
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// 极少发生，退化为时间戳，保证ID非零
+		return fmt.Sprintf("%0*x", n*2, time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,199 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"dsbot/internal/logger"
+)
+
+// ProgressFunc 返回组件最近一次取得进展的时间
+type ProgressFunc func() time.Time
+
+// RestartFunc 重启组件
+type RestartFunc func() error
+
+// AlertFunc 重启失败时的告警处理函数
+type AlertFunc func(component string, err error)
+
+// component 被监控的组件
+type component struct {
+	name        string
+	timeout     time.Duration // 允许的最长无进展时间
+	progress    ProgressFunc
+	restart     RestartFunc
+	restarts    int       // 连续重启次数
+	lastRestart time.Time // 最近一次重启时间
+}
+
+// Watchdog 看门狗 - 检测长时间无进展的组件（交易任务、风控循环、行情读取等），
+// 尝试重启，重启失败或超过最大重启次数时升级告警
+type Watchdog struct {
+	checkInterval time.Duration
+	maxRestarts   int
+	components    map[string]*component
+	onAlert       AlertFunc
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	running       bool
+	mu            sync.Mutex
+}
+
+// Option 看门狗选项
+type Option func(*Watchdog)
+
+// WithMaxRestarts 设置连续重启次数上限（超过后只告警不再重启）
+func WithMaxRestarts(n int) Option {
+	return func(w *Watchdog) {
+		w.maxRestarts = n
+	}
+}
+
+// WithAlertHandler 设置告警处理函数
+func WithAlertHandler(handler AlertFunc) Option {
+	return func(w *Watchdog) {
+		w.onAlert = handler
+	}
+}
+
+// NewWatchdog 创建看门狗
+func NewWatchdog(checkInterval time.Duration, options ...Option) *Watchdog {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w := &Watchdog{
+		checkInterval: checkInterval,
+		maxRestarts:   3,
+		components:    make(map[string]*component),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+
+	for _, opt := range options {
+		opt(w)
+	}
+
+	return w
+}
+
+// Register 注册被监控组件
+// timeout: 超过该时长没有进展即视为卡死
+func (w *Watchdog) Register(name string, timeout time.Duration, progress ProgressFunc, restart RestartFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.components[name] = &component{
+		name:     name,
+		timeout:  timeout,
+		progress: progress,
+		restart:  restart,
+	}
+	logger.Printf("[看门狗] 注册组件: %s (超时: %s)", name, timeout)
+}
+
+// Start 启动看门狗
+func (w *Watchdog) Start() error {
+	w.mu.Lock()
+	if w.running {
+		w.mu.Unlock()
+		return fmt.Errorf("看门狗已在运行中")
+	}
+	w.running = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.run()
+
+	return nil
+}
+
+// Stop 停止看门狗
+func (w *Watchdog) Stop() {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return
+	}
+	w.mu.Unlock()
+
+	w.cancel()
+	w.wg.Wait()
+
+	w.mu.Lock()
+	w.running = false
+	w.mu.Unlock()
+}
+
+// run 看门狗主循环
+func (w *Watchdog) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// check 检查所有组件
+func (w *Watchdog) check() {
+	w.mu.Lock()
+	components := make([]*component, 0, len(w.components))
+	for _, c := range w.components {
+		components = append(components, c)
+	}
+	w.mu.Unlock()
+
+	now := time.Now()
+	for _, c := range components {
+		last := c.progress()
+		// 重启后给组件一个完整的超时周期恢复
+		if c.lastRestart.After(last) {
+			last = c.lastRestart
+		}
+
+		if now.Sub(last) <= c.timeout {
+			c.restarts = 0
+			continue
+		}
+
+		w.handleStuck(c, now.Sub(last))
+	}
+}
+
+// handleStuck 处理卡死组件
+func (w *Watchdog) handleStuck(c *component, stalled time.Duration) {
+	logger.Warnf("[看门狗] 组件 %s 已 %s 无进展，超过阈值 %s", c.name, stalled.Round(time.Second), c.timeout)
+
+	if c.restarts >= w.maxRestarts {
+		w.alert(c.name, fmt.Errorf("连续重启 %d 次后仍无进展", c.restarts))
+		return
+	}
+
+	c.restarts++
+	c.lastRestart = time.Now()
+	logger.Printf("[看门狗] 正在重启组件 %s (第 %d 次)", c.name, c.restarts)
+
+	if err := c.restart(); err != nil {
+		w.alert(c.name, fmt.Errorf("重启失败: %w", err))
+		return
+	}
+
+	logger.Printf("[看门狗] 组件 %s 已重启", c.name)
+}
+
+// alert 升级告警
+func (w *Watchdog) alert(name string, err error) {
+	logger.Errorf("[看门狗] ❌ 组件 %s 异常: %v", name, err)
+	if w.onAlert != nil {
+		w.onAlert(name, err)
+	}
+}